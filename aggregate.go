@@ -0,0 +1,354 @@
+package oanda
+
+import (
+	"cmp"
+	"fmt"
+	"iter"
+	"math/big"
+	"slices"
+	"time"
+)
+
+// CandleAlignment describes how the API aligns candlesticks to time boundaries: candlesticks of
+// an hour or less start at multiples of their length after the start of the hour, longer ones at
+// multiples of their length after the start of the trading day, which begins at DailyAlignment
+// in Location. Weekly candlesticks start on WeeklyAlignment and monthly ones on the first day of
+// the month. [DefaultCandleAlignment] returns the alignment the API uses when none is requested.
+type CandleAlignment struct {
+	// DailyAlignment is the hour of the day, from 0 to 23, at which daily candlesticks start.
+	DailyAlignment int
+	// Location is the time zone of DailyAlignment. A nil Location is UTC.
+	Location *time.Location
+	// WeeklyAlignment is the day of the week on which weekly candlesticks start. An empty
+	// WeeklyAlignment is Friday.
+	WeeklyAlignment WeeklyAlignment
+}
+
+// DefaultCandleAlignment returns the default alignment of the API: trading days start at 17:00
+// in America/New_York and weeks on Friday. It fails if the time zone database is unavailable.
+func DefaultCandleAlignment() (CandleAlignment, error) {
+	location, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		return CandleAlignment{}, fmt.Errorf("failed to load alignment timezone: %w", err)
+	}
+	return CandleAlignment{DailyAlignment: 17, Location: location, WeeklyAlignment: WeeklyAlignmentFriday}, nil
+}
+
+// Start returns the start time of the candlestick of granularity g that contains t.
+func (a CandleAlignment) Start(g CandlestickGranularity, t time.Time) (time.Time, error) {
+	if err := a.validate(); err != nil {
+		return time.Time{}, err
+	}
+	if g == M {
+		local := t.In(a.location())
+		start := time.Date(local.Year(), local.Month(), 1, a.DailyAlignment, 0, 0, 0, a.location())
+		if start.After(t) {
+			start = start.AddDate(0, -1, 0)
+		}
+		return start, nil
+	}
+	d, err := g.Duration()
+	if err != nil {
+		return time.Time{}, err
+	}
+	if d <= time.Hour {
+		hour := t.Truncate(time.Hour)
+		return hour.Add(t.Sub(hour) / d * d), nil
+	}
+	day := a.dayStart(t)
+	switch {
+	case g == W:
+		weekday, err := a.weekday()
+		if err != nil {
+			return time.Time{}, err
+		}
+		for day.Weekday() != weekday {
+			day = a.dayStart(day.Add(-time.Nanosecond))
+		}
+		return day, nil
+	case d <= 24*time.Hour:
+		return day.Add(t.Sub(day) / d * d), nil
+	}
+	return time.Time{}, fmt.Errorf("unsupported granularity %q", g)
+}
+
+// End returns the end time of the candlestick of granularity g that contains t, which is the
+// start time of the next one.
+func (a CandleAlignment) End(g CandlestickGranularity, t time.Time) (time.Time, error) {
+	start, err := a.Start(g, t)
+	if err != nil {
+		return time.Time{}, err
+	}
+	switch g {
+	case M:
+		return start.AddDate(0, 1, 0), nil
+	case W:
+		return start.AddDate(0, 0, 7), nil
+	case D:
+		return start.AddDate(0, 0, 1), nil
+	}
+	d, _ := g.Duration()
+	end := start.Add(d)
+	if d > time.Hour {
+		// A trading day shortened by a daylight saving change cuts its last candlestick short.
+		if next := a.dayStart(start).AddDate(0, 0, 1); next.Before(end) {
+			end = next
+		}
+	}
+	return end, nil
+}
+
+func (a CandleAlignment) validate() error {
+	if a.DailyAlignment < 0 || a.DailyAlignment > 23 {
+		return fmt.Errorf("daily alignment must be between 0 and 23, got %d", a.DailyAlignment)
+	}
+	_, err := a.weekday()
+	return err
+}
+
+func (a CandleAlignment) location() *time.Location {
+	if a.Location == nil {
+		return time.UTC
+	}
+	return a.Location
+}
+
+// dayStart returns the start of the trading day that contains t, in the alignment's location.
+func (a CandleAlignment) dayStart(t time.Time) time.Time {
+	local := t.In(a.location())
+	start := time.Date(local.Year(), local.Month(), local.Day(), a.DailyAlignment, 0, 0, 0, a.location())
+	if start.After(t) {
+		start = time.Date(local.Year(), local.Month(), local.Day()-1, a.DailyAlignment, 0, 0, 0, a.location())
+	}
+	return start
+}
+
+func (a CandleAlignment) weekday() (time.Weekday, error) {
+	if a.WeeklyAlignment == "" {
+		return time.Friday, nil
+	}
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		if weekday.String() == string(a.WeeklyAlignment) {
+			return weekday, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid weekly alignment %q", a.WeeklyAlignment)
+}
+
+// LiveCandle is a candlestick built by a [CandleAggregator]. Its Volume is the number of prices
+// received during the candlestick, and it is Complete once a later price or heartbeat shows that
+// its time range has ended.
+type LiveCandle struct {
+	// Instrument is the instrument of the candlestick.
+	Instrument InstrumentName
+	// Granularity is the granularity of the candlestick.
+	Granularity CandlestickGranularity
+	Candlestick
+}
+
+// CandleAggregator builds bid, ask and midpoint candlesticks of one or more granularities from
+// the prices of a pricing stream, aligned like the candlesticks of the API. Granularities
+// shorter than the API's S5, such as "S1", are supported; see [CandlestickGranularity.Duration].
+// Bid and ask candlesticks are built from the best bid and ask of each price. Create one with
+// [NewCandleAggregator]. A CandleAggregator is not safe for concurrent use.
+type CandleAggregator struct {
+	granularities []CandlestickGranularity
+	alignment     *CandleAlignment
+	candles       map[liveCandleKey]*liveCandle
+}
+
+type liveCandleKey struct {
+	instrument  InstrumentName
+	granularity CandlestickGranularity
+}
+
+// liveCandle is a LiveCandle in progress, with the parsed high and low prices it is compared
+// against.
+type liveCandle struct {
+	LiveCandle
+	end           time.Time
+	bid, ask, mid [2]float64
+}
+
+// snapshot returns a copy of the candlestick in progress that later prices do not change.
+func (c *liveCandle) snapshot() LiveCandle {
+	candle := c.LiveCandle
+	bid, ask, mid := *c.Bid, *c.Ask, *c.Mid
+	candle.Bid, candle.Ask, candle.Mid = &bid, &ask, &mid
+	return candle
+}
+
+// NewCandleAggregator creates a new CandleAggregator for the given granularities, aligned with
+// [DefaultCandleAlignment] unless [CandleAggregator.SetAlignment] is called.
+func NewCandleAggregator(granularities ...CandlestickGranularity) *CandleAggregator {
+	return &CandleAggregator{
+		granularities: granularities,
+		candles:       make(map[liveCandleKey]*liveCandle),
+	}
+}
+
+// SetAlignment sets the alignment of the candlesticks, which must match the dailyAlignment,
+// alignmentTimezone and weeklyAlignment of the candlesticks they are combined with.
+func (a *CandleAggregator) SetAlignment(alignment CandleAlignment) *CandleAggregator {
+	a.alignment = &alignment
+	return a
+}
+
+// Add adds a price and returns the candlesticks it changed: for each granularity, the
+// candlestick completed by the price, if any, followed by the candlestick in progress that now
+// includes it. Prices without a time, bid or ask, and prices older than the candlestick in
+// progress, are ignored.
+func (a *CandleAggregator) Add(price ClientPrice) ([]LiveCandle, error) {
+	if price.Time.Time == nil || len(price.Bids) == 0 || len(price.Asks) == 0 {
+		return nil, nil
+	}
+	if err := a.init(); err != nil {
+		return nil, err
+	}
+	bid, ask := price.Bids[0].Price, price.Asks[0].Price
+	mid, err := midPrice(bid, ask)
+	if err != nil {
+		return nil, err
+	}
+	var values [3]float64
+	for i, p := range []PriceValue{bid, ask, mid} {
+		if values[i], err = p.Float64(); err != nil {
+			return nil, err
+		}
+	}
+	t := *price.Time.Time
+	var changed []LiveCandle
+	for _, g := range a.granularities {
+		key := liveCandleKey{price.Instrument, g}
+		c := a.candles[key]
+		if c != nil && t.Before(*c.Time.Time) {
+			continue
+		}
+		if c != nil && !t.Before(c.end) {
+			c.Complete = true
+			changed = append(changed, c.LiveCandle)
+			c = nil
+		}
+		if c == nil {
+			start, err := a.alignment.Start(g, t)
+			if err != nil {
+				return nil, err
+			}
+			end, err := a.alignment.End(g, t)
+			if err != nil {
+				return nil, err
+			}
+			c = &liveCandle{
+				LiveCandle: LiveCandle{Instrument: price.Instrument, Granularity: g, Candlestick: Candlestick{
+					Time: DateTime{&start}, Bid: &CandlestickData{}, Ask: &CandlestickData{}, Mid: &CandlestickData{},
+				}},
+				end: end,
+			}
+			a.candles[key] = c
+		}
+		first := c.Volume == 0
+		updateCandleData(c.Bid, &c.bid, bid, values[0], first)
+		updateCandleData(c.Ask, &c.ask, ask, values[1], first)
+		updateCandleData(c.Mid, &c.mid, mid, values[2], first)
+		c.Volume++
+		changed = append(changed, c.snapshot())
+	}
+	return changed, nil
+}
+
+// Flush completes and returns the candlesticks in progress whose time range ended at or before
+// now, such as the time of a heartbeat, in order of their end time. A later price then starts a
+// new candlestick.
+func (a *CandleAggregator) Flush(now time.Time) []LiveCandle {
+	var ended []*liveCandle
+	for key, c := range a.candles {
+		if !now.Before(c.end) {
+			c.Complete = true
+			ended = append(ended, c)
+			delete(a.candles, key)
+		}
+	}
+	slices.SortFunc(ended, func(x, y *liveCandle) int {
+		return cmp.Or(
+			x.end.Compare(y.end),
+			cmp.Compare(x.Instrument, y.Instrument),
+			cmp.Compare(slices.Index(a.granularities, x.Granularity), slices.Index(a.granularities, y.Granularity)),
+		)
+	})
+	candles := make([]LiveCandle, len(ended))
+	for i, c := range ended {
+		candles[i] = c.LiveCandle
+	}
+	return candles
+}
+
+// Candles returns an iterator over the candlesticks changed by the items received on a pricing
+// stream channel, such as the one returned by [PriceStream.Updates]: every update of a
+// candlestick in progress, and every completed candlestick. Heartbeats complete the candlesticks
+// that ended before them, so that a quiet market does not hold them back. The iterator ends when
+// the channel is closed; errors for invalid prices are yielded without ending it.
+func (a *CandleAggregator) Candles(items <-chan PriceStreamItem) iter.Seq2[LiveCandle, error] {
+	return func(yield func(LiveCandle, error) bool) {
+		for item := range items {
+			var changed []LiveCandle
+			switch item := item.(type) {
+			case ClientPrice:
+				var err error
+				if changed, err = a.Add(item); err != nil && !yield(LiveCandle{}, err) {
+					return
+				}
+			case PricingHeartbeat:
+				if item.Time.Time != nil {
+					changed = a.Flush(*item.Time.Time)
+				}
+			}
+			for _, c := range changed {
+				if !yield(c, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func (a *CandleAggregator) init() error {
+	if a.alignment != nil {
+		return nil
+	}
+	alignment, err := DefaultCandleAlignment()
+	if err != nil {
+		return err
+	}
+	a.alignment = &alignment
+	return nil
+}
+
+// updateCandleData adds price p, parsed as f, to data, whose high and low are tracked in hl.
+func updateCandleData(data *CandlestickData, hl *[2]float64, p PriceValue, f float64, first bool) {
+	if first {
+		data.O, data.H, data.L = p, p, p
+		hl[0], hl[1] = f, f
+	}
+	if f > hl[0] {
+		data.H, hl[0] = p, f
+	}
+	if f < hl[1] {
+		data.L, hl[1] = p, f
+	}
+	data.C = p
+}
+
+// midPrice returns the midpoint of bid and ask, rounded to their number of decimal places.
+func midPrice(bid, ask PriceValue) (PriceValue, error) {
+	b, err := bid.Rat()
+	if err != nil {
+		return "", err
+	}
+	a, err := ask.Rat()
+	if err != nil {
+		return "", err
+	}
+	mid := new(big.Rat).Add(b, a)
+	mid.Quo(mid, big.NewRat(2, 1))
+	return PriceValue(mid.FloatString(max(fractionDigits(string(bid)), fractionDigits(string(ask))))), nil
+}
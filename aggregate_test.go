@@ -0,0 +1,96 @@
+package oanda
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCandleAlignment(t *testing.T) {
+	alignment, err := DefaultCandleAlignment()
+	if err != nil {
+		t.Fatalf("failed to load alignment: %v", err)
+	}
+	at := time.Date(2024, 7, 10, 3, 33, 20, 700_000_000, time.UTC)
+	for _, test := range []struct {
+		granularity CandlestickGranularity
+		start, end  string
+	}{
+		{"S1", "2024-07-10T03:33:20Z", "2024-07-10T03:33:21Z"},
+		{M5, "2024-07-10T03:30:00Z", "2024-07-10T03:35:00Z"},
+		{H4, "2024-07-10T01:00:00Z", "2024-07-10T05:00:00Z"},
+		{D, "2024-07-09T21:00:00Z", "2024-07-10T21:00:00Z"},
+		{W, "2024-07-05T21:00:00Z", "2024-07-12T21:00:00Z"},
+		{M, "2024-07-01T21:00:00Z", "2024-08-01T21:00:00Z"},
+	} {
+		start, err := alignment.Start(test.granularity, at)
+		if err != nil {
+			t.Fatalf("%s: failed to align: %v", test.granularity, err)
+		}
+		end, err := alignment.End(test.granularity, at)
+		if err != nil {
+			t.Fatalf("%s: failed to align: %v", test.granularity, err)
+		}
+		if got := start.UTC().Format(time.RFC3339); got != test.start {
+			t.Errorf("%s: got start %s, want %s", test.granularity, got, test.start)
+		}
+		if got := end.UTC().Format(time.RFC3339); got != test.end {
+			t.Errorf("%s: got end %s, want %s", test.granularity, got, test.end)
+		}
+	}
+	if d, err := CandlestickGranularity("S1").Duration(); err != nil || d != time.Second {
+		t.Errorf("got S1 duration %v (%v), want 1s", d, err)
+	}
+	for _, g := range []CandlestickGranularity{M, "X5", "S0", "H"} {
+		if _, err := g.Duration(); err == nil {
+			t.Errorf("%s: got no error", g)
+		}
+	}
+}
+
+func TestCandleAggregator(t *testing.T) {
+	base := time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)
+	price := func(offset time.Duration, bid, ask PriceValue) PriceStreamItem {
+		at := base.Add(offset)
+		return ClientPrice{
+			Type: "PRICE", Instrument: "EUR_USD", Time: DateTime{&at},
+			Bids: []PriceBucket{{Price: bid}}, Asks: []PriceBucket{{Price: ask}},
+		}
+	}
+	heartbeat := base.Add(2500 * time.Millisecond)
+	items := make(chan PriceStreamItem, 10)
+	items <- price(200*time.Millisecond, "1.10000", "1.10010")
+	items <- price(700*time.Millisecond, "1.10020", "1.10030")
+	items <- price(1100*time.Millisecond, "1.09990", "1.10001")
+	items <- PricingHeartbeat{Type: "HEARTBEAT", Time: DateTime{&heartbeat}}
+	items <- price(time.Minute, "1.10040", "1.10050")
+	close(items)
+
+	aggregator := NewCandleAggregator("S1", M1).SetAlignment(CandleAlignment{})
+	var got []string
+	for candle, err := range aggregator.Candles(items) {
+		if err != nil {
+			t.Fatalf("got error: %v", err)
+		}
+		got = append(got, fmt.Sprintf("%s %s %t %d bid %s-%s-%s-%s mid %s-%s",
+			candle.Granularity, candle.Time.Format("15:04:05"), candle.Complete, candle.Volume,
+			candle.Bid.O, candle.Bid.H, candle.Bid.L, candle.Bid.C, candle.Mid.O, candle.Mid.C))
+	}
+	want := []string{
+		"S1 10:00:00 false 1 bid 1.10000-1.10000-1.10000-1.10000 mid 1.10005-1.10005",
+		"M1 10:00:00 false 1 bid 1.10000-1.10000-1.10000-1.10000 mid 1.10005-1.10005",
+		"S1 10:00:00 false 2 bid 1.10000-1.10020-1.10000-1.10020 mid 1.10005-1.10025",
+		"M1 10:00:00 false 2 bid 1.10000-1.10020-1.10000-1.10020 mid 1.10005-1.10025",
+		"S1 10:00:00 true 2 bid 1.10000-1.10020-1.10000-1.10020 mid 1.10005-1.10025",
+		"S1 10:00:01 false 1 bid 1.09990-1.09990-1.09990-1.09990 mid 1.09996-1.09996",
+		"M1 10:00:00 false 3 bid 1.10000-1.10020-1.09990-1.09990 mid 1.10005-1.09996",
+		"S1 10:00:01 true 1 bid 1.09990-1.09990-1.09990-1.09990 mid 1.09996-1.09996",
+		"S1 10:01:00 false 1 bid 1.10040-1.10040-1.10040-1.10040 mid 1.10045-1.10045",
+		"M1 10:00:00 true 3 bid 1.10000-1.10020-1.09990-1.09990 mid 1.10005-1.09996",
+		"M1 10:01:00 false 1 bid 1.10040-1.10040-1.10040-1.10040 mid 1.10045-1.10045",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got candles\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
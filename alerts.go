@@ -0,0 +1,300 @@
+package oanda
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+	"time"
+)
+
+// AlertCondition reports whether a price of an Instrument triggers an alert. The conditions
+// built by [PriceAbove], [PriceBelow], [PriceCrosses], [SpreadAbove] and [PriceMoves] keep the
+// state of the prices they have seen, so each [Alert] needs its own.
+type AlertCondition func(ClientPrice) (bool, error)
+
+// AlertWhen returns an AlertCondition that triggers when condition becomes true: on the first
+// price for which it is true, and then again only after a price for which it is false.
+func AlertWhen(condition func(ClientPrice) (bool, error)) AlertCondition {
+	active := false
+	return func(price ClientPrice) (bool, error) {
+		ok, err := condition(price)
+		if err != nil {
+			return false, err
+		}
+		triggered := ok && !active
+		active = ok
+		return triggered, nil
+	}
+}
+
+// PriceAbove triggers when the midpoint of the best bid and ask rises above level.
+func PriceAbove(level PriceValue) AlertCondition {
+	return AlertWhen(func(price ClientPrice) (bool, error) {
+		c, err := compareMid(price, level)
+		return c > 0, err
+	})
+}
+
+// PriceBelow triggers when the midpoint of the best bid and ask falls below level.
+func PriceBelow(level PriceValue) AlertCondition {
+	return AlertWhen(func(price ClientPrice) (bool, error) {
+		c, err := compareMid(price, level)
+		return c < 0, err
+	})
+}
+
+// PriceCrosses triggers when the midpoint of the best bid and ask crosses level in either
+// direction: when it reaches or rises above level after a price below it, or falls below level
+// after a price at or above it. The first price only sets the side of level the price is on.
+func PriceCrosses(level PriceValue) AlertCondition {
+	var above *bool
+	return func(price ClientPrice) (bool, error) {
+		c, err := compareMid(price, level)
+		if err != nil {
+			return false, err
+		}
+		now := c >= 0
+		crossed := above != nil && *above != now
+		above = &now
+		return crossed, nil
+	}
+}
+
+// compareMid compares the midpoint of the best bid and ask of price with level.
+func compareMid(price ClientPrice, level PriceValue) (int, error) {
+	mid, err := price.Mid()
+	if err != nil {
+		return 0, err
+	}
+	m, err := mid.Rat()
+	if err != nil {
+		return 0, err
+	}
+	l, err := level.Rat()
+	if err != nil {
+		return 0, fmt.Errorf("invalid alert level: %w", err)
+	}
+	return m.Cmp(l), nil
+}
+
+// SpreadAbove triggers when the spread, the best ask minus the best bid, widens above spread,
+// in price units such as 0.0003 for 3 pips of EUR_USD.
+func SpreadAbove(spread float64) AlertCondition {
+	return AlertWhen(func(price ClientPrice) (bool, error) {
+		value, err := price.Spread()
+		if err != nil {
+			return false, err
+		}
+		s, err := value.Float64()
+		return s > spread, err
+	})
+}
+
+// PriceMoves triggers when the midpoint of the best bid and ask has moved by percent or more, up
+// or down, from the earliest price within window before it, such as 0.5 for a move of 0.5% in
+// 15 minutes. The window is measured in the time of the prices; prices without a time are
+// ignored.
+func PriceMoves(percent float64, window time.Duration) AlertCondition {
+	type sample struct {
+		time time.Time
+		mid  float64
+	}
+	var samples []sample
+	return AlertWhen(func(price ClientPrice) (bool, error) {
+		if price.Time.Time == nil {
+			return false, nil
+		}
+		value, err := price.Mid()
+		if err != nil {
+			return false, err
+		}
+		mid, err := value.Float64()
+		if err != nil {
+			return false, err
+		}
+		at := *price.Time.Time
+		samples = append(samples, sample{at, mid})
+		i := 0
+		for i < len(samples) && samples[i].time.Before(at.Add(-window)) {
+			i++
+		}
+		samples = slices.Delete(samples, 0, i)
+		first := samples[0].mid
+		return first != 0 && math.Abs(mid-first)/first*100 >= percent, nil
+	})
+}
+
+// AlertID identifies an Alert registered with an [AlertEngine].
+type AlertID int
+
+// Alert is a condition on the prices of an Instrument that an [AlertEngine] reports when it is
+// triggered. An Alert is one-shot: it is removed once triggered, unless [Alert.SetRepeat] is
+// called. Use [NewAlert] to create one, then chain setters.
+type Alert struct {
+	instrument InstrumentName
+	condition  AlertCondition
+	name       string
+	repeat     bool
+}
+
+// NewAlert creates a new one-shot Alert on the prices of instrument.
+func NewAlert(instrument InstrumentName, condition AlertCondition) *Alert {
+	return &Alert{instrument: instrument, condition: condition}
+}
+
+// SetName sets a name for the alert, reported in its events.
+func (a *Alert) SetName(name string) *Alert {
+	a.name = name
+	return a
+}
+
+// SetRepeat keeps the alert registered after it is triggered, so that it is reported every time
+// its condition triggers.
+func (a *Alert) SetRepeat() *Alert {
+	a.repeat = true
+	return a
+}
+
+// AlertEvent reports that an Alert was triggered.
+type AlertEvent struct {
+	// ID is the ID of the Alert returned by [AlertEngine.Add].
+	ID AlertID
+	// Name is the name of the Alert.
+	Name string
+	// Instrument is the Instrument of the Alert.
+	Instrument InstrumentName
+	// Price is the price that triggered the Alert.
+	Price ClientPrice
+	// Removed is true if the Alert was one-shot and has been removed.
+	Removed bool
+}
+
+// AlertEngine evaluates registered Alerts against the prices of a pricing stream and reports the
+// ones triggered to a callback set with [AlertEngine.OnAlert] and a channel set with
+// [AlertEngine.Notify]. An AlertEngine is safe for concurrent use, so Alerts can be added and
+// removed while it runs. Create one with [NewAlertEngine].
+type AlertEngine struct {
+	mu      sync.Mutex
+	nextID  AlertID
+	alerts  map[AlertID]*Alert
+	onAlert func(AlertEvent)
+	onError func(error)
+	events  chan<- AlertEvent
+}
+
+// NewAlertEngine creates a new AlertEngine without Alerts.
+func NewAlertEngine() *AlertEngine {
+	return &AlertEngine{alerts: make(map[AlertID]*Alert)}
+}
+
+// OnAlert sets a callback called with every triggered Alert, from the goroutine that processes
+// the price.
+func (e *AlertEngine) OnAlert(fn func(AlertEvent)) *AlertEngine {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.onAlert = fn
+	return e
+}
+
+// SetErrorHandler sets a function that is called with the errors of the Alerts' conditions
+// while [AlertEngine.Run] is running.
+func (e *AlertEngine) SetErrorHandler(handler func(error)) *AlertEngine {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.onError = handler
+	return e
+}
+
+// Notify sets a channel every triggered Alert is sent on. Sends block, so the channel must be
+// read or buffered.
+func (e *AlertEngine) Notify(events chan<- AlertEvent) *AlertEngine {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.events = events
+	return e
+}
+
+// Add registers alert and returns its ID.
+func (e *AlertEngine) Add(alert *Alert) AlertID {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.nextID++
+	e.alerts[e.nextID] = alert
+	return e.nextID
+}
+
+// Remove unregisters the Alert with the given ID. It returns false if there is none, such as
+// when a one-shot Alert has already been triggered.
+func (e *AlertEngine) Remove(id AlertID) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, ok := e.alerts[id]
+	delete(e.alerts, id)
+	return ok
+}
+
+// Process evaluates the Alerts of the Instrument of price, reports the ones it triggers, in the
+// order they were added, and returns them. Alerts whose condition fails, such as on a price
+// without bids, are skipped; the errors are returned joined.
+func (e *AlertEngine) Process(price ClientPrice) ([]AlertEvent, error) {
+	e.mu.Lock()
+	var ids []AlertID
+	for id, alert := range e.alerts {
+		if alert.instrument == price.Instrument {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	var events []AlertEvent
+	var errs []error
+	for _, id := range ids {
+		alert := e.alerts[id]
+		triggered, err := alert.condition(price)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("alert %d on %s: %w", id, price.Instrument, err))
+			continue
+		}
+		if !triggered {
+			continue
+		}
+		if !alert.repeat {
+			delete(e.alerts, id)
+		}
+		events = append(events, AlertEvent{ID: id, Name: alert.name, Instrument: alert.instrument, Price: price, Removed: !alert.repeat})
+	}
+	onAlert, ch := e.onAlert, e.events
+	e.mu.Unlock()
+	for _, event := range events {
+		if onAlert != nil {
+			onAlert(event)
+		}
+		if ch != nil {
+			ch <- event
+		}
+	}
+	return events, errors.Join(errs...)
+}
+
+// Run passes the prices received on a pricing stream channel, such as the one returned by
+// [PriceStream.Updates], to [AlertEngine.Process] until the channel is closed or ctx is
+// cancelled, in which case it returns the context's error. The errors of the Alerts'
+// conditions are passed to the handler set with SetErrorHandler as they occur.
+func (e *AlertEngine) Run(ctx context.Context, items <-chan PriceStreamItem) error {
+	return processPrices(ctx, items, func(price ClientPrice) error {
+		_, err := e.Process(price)
+		return err
+	}, e.handleError)
+}
+
+// handleError passes err to the handler set with SetErrorHandler, if any.
+func (e *AlertEngine) handleError(err error) {
+	e.mu.Lock()
+	onError := e.onError
+	e.mu.Unlock()
+	if onError != nil {
+		onError(err)
+	}
+}
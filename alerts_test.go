@@ -0,0 +1,72 @@
+package oanda
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAlertEngine(t *testing.T) {
+	base := time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)
+	price := func(minute int, bid, ask PriceValue) ClientPrice {
+		at := base.Add(time.Duration(minute) * time.Minute)
+		return ClientPrice{Instrument: "EUR_USD", Time: DateTime{&at}, Bids: []PriceBucket{{Price: bid}}, Asks: []PriceBucket{{Price: ask}}}
+	}
+	var names []string
+	events := make(chan AlertEvent, 10)
+	engine := NewAlertEngine().Notify(events).OnAlert(func(e AlertEvent) {
+		names = append(names, fmt.Sprintf("%s@%d", e.Name, int(e.Price.Time.Sub(base).Minutes())))
+	})
+	engine.Add(NewAlert("EUR_USD", PriceAbove("1.1010")).SetName("above"))
+	engine.Add(NewAlert("EUR_USD", PriceCrosses("1.1000")).SetName("cross").SetRepeat())
+	engine.Add(NewAlert("EUR_USD", SpreadAbove(0.0002)).SetName("spread").SetRepeat())
+	engine.Add(NewAlert("EUR_USD", PriceMoves(0.1, 10*time.Minute)).SetName("move"))
+	below := engine.Add(NewAlert("EUR_USD", PriceBelow("1.0900")).SetName("below"))
+	engine.Add(NewAlert("USD_JPY", PriceAbove("0")).SetName("other"))
+	if !engine.Remove(below) || engine.Remove(below) {
+		t.Error("got wrong result removing an alert")
+	}
+
+	items := make(chan PriceStreamItem, 10)
+	items <- price(0, "1.09990", "1.10000") // below 1.1000
+	items <- price(1, "1.10000", "1.10010") // crosses up
+	items <- price(2, "1.10000", "1.10050") // spread widens to 5 pips
+	items <- price(3, "1.09980", "1.09990") // crosses down, spread narrows
+	items <- price(4, "1.10100", "1.10140") // crosses up, above 1.1010, spread widens, moves 0.1%
+	items <- price(5, "1.10110", "1.10140") // above again, but the one-shot alert is gone
+	items <- PricingHeartbeat{Type: "HEARTBEAT"}
+	items <- ClientPrice{Instrument: "EUR_USD", Bids: []PriceBucket{{Price: "x"}}, Asks: []PriceBucket{{Price: "1"}}}
+	close(items)
+	var errs []error
+	engine.SetErrorHandler(func(err error) { errs = append(errs, err) })
+	if err := engine.Run(t.Context(), items); err != nil {
+		t.Errorf("got error %v after the channel was closed", err)
+	}
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "EUR_USD") {
+		t.Errorf("got errors %v, want one for the invalid price", errs)
+	}
+	want := []string{"cross@1", "spread@2", "cross@3", "above@4", "cross@4", "spread@4", "move@4"}
+	if !slices.Equal(names, want) {
+		t.Errorf("got alerts %v, want %v", names, want)
+	}
+	close(events)
+	var removed []string
+	for e := range events {
+		if e.Removed {
+			removed = append(removed, e.Name)
+		}
+	}
+	if !slices.Equal(removed, []string{"above", "move"}) {
+		t.Errorf("got removed alerts %v", removed)
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	if err := engine.Run(ctx, make(chan PriceStreamItem)); !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v, want context.Canceled", err)
+	}
+}
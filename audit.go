@@ -0,0 +1,171 @@
+package oanda
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// OrderAuditTrail is the complete lifecycle of an Order, returned by [orderService.AuditTrail].
+// Replacing an Order cancels it and creates a new Order with a new ID, so the lifecycle spans
+// the chain of Orders linked by their ReplacesOrderID and ReplacedByOrderID fields.
+type OrderAuditTrail struct {
+	// Orders is the chain of Orders, from the original Order to the latest replacement.
+	Orders []Order
+	// Events are the events of all Orders of the chain, in Transaction order.
+	Events []OrderEvent
+}
+
+// Current returns the latest Order of the chain, which holds the Order's current state.
+func (t *OrderAuditTrail) Current() Order {
+	if len(t.Orders) == 0 {
+		return nil
+	}
+	return t.Orders[len(t.Orders)-1]
+}
+
+// orderLinks holds the fields linking an Order to the Orders and Transactions of its lifecycle.
+type orderLinks struct {
+	ReplaceDetails
+	FillingDetails
+	CancellingDetails
+}
+
+func linksOf(order Order) (orderLinks, error) {
+	var links orderLinks
+	r, ok := order.(interface{ GetRawJSON() json.RawMessage })
+	if !ok || len(r.GetRawJSON()) == 0 {
+		return links, nil
+	}
+	if err := json.Unmarshal(r.GetRawJSON(), &links); err != nil {
+		return links, fmt.Errorf("failed to decode order %s: %w", order.GetID(), err)
+	}
+	return links, nil
+}
+
+// AuditTrail reconstructs the lifecycle of the specified Order: its creation, replacements,
+// client extension modifications, fills and cancellation. The Orders it replaced and the
+// Orders that replaced it are followed, so any Order of a replacement chain yields the same
+// trail. The Transactions are retrieved with [transactionService.GetByIDRange], from the
+// creation of the original Order to the end of the latest Order's lifecycle, or to the Account's
+// last Transaction if it is still pending.
+func (s *orderService) AuditTrail(ctx context.Context, specifier OrderSpecifier) (*OrderAuditTrail, error) {
+	details, err := s.Details(ctx, specifier)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order details: %w", err)
+	}
+	if details.Order == nil {
+		return nil, fmt.Errorf("order %s not found", specifier)
+	}
+	lastTransactionID := details.LastTransactionID
+	chain := []Order{details.Order}
+	seen := map[OrderID]bool{details.Order.GetID(): true}
+
+	// Walk back to the original Order, then forward to the latest replacement.
+	for {
+		links, err := linksOf(chain[0])
+		if err != nil {
+			return nil, err
+		}
+		if links.ReplacesOrderID == nil || seen[*links.ReplacesOrderID] {
+			break
+		}
+		resp, err := s.Details(ctx, *links.ReplacesOrderID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get replaced order %s: %w", *links.ReplacesOrderID, err)
+		}
+		if resp.Order == nil {
+			break
+		}
+		seen[resp.Order.GetID()] = true
+		chain = append([]Order{resp.Order}, chain...)
+	}
+	var last orderLinks
+	for {
+		links, err := linksOf(chain[len(chain)-1])
+		if err != nil {
+			return nil, err
+		}
+		last = links
+		if links.ReplacedByOrderID == nil || seen[*links.ReplacedByOrderID] {
+			break
+		}
+		resp, err := s.Details(ctx, *links.ReplacedByOrderID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get replacing order %s: %w", *links.ReplacedByOrderID, err)
+		}
+		if resp.Order == nil {
+			break
+		}
+		seen[resp.Order.GetID()] = true
+		lastTransactionID = resp.LastTransactionID
+		chain = append(chain, resp.Order)
+	}
+
+	to := lastTransactionID
+	switch {
+	case last.FillingTransactionID != nil:
+		to = *last.FillingTransactionID
+	case last.CancellingTransactionID != nil:
+		to = *last.CancellingTransactionID
+	}
+	events, err := s.orderEvents(ctx, chain[0].GetID(), to, seen)
+	if err != nil {
+		return nil, err
+	}
+	return &OrderAuditTrail{Orders: chain, Events: events}, nil
+}
+
+// orderEvents returns the events of the Orders in orderIDs recorded by the Transactions with IDs
+// from from to to (inclusive).
+func (s *orderService) orderEvents(ctx context.Context, from, to TransactionID, orderIDs map[OrderID]bool) ([]OrderEvent, error) {
+	first, err := ParseTransactionID(from)
+	if err != nil {
+		return nil, err
+	}
+	last, err := ParseTransactionID(to)
+	if err != nil {
+		return nil, err
+	}
+	var events []OrderEvent
+	for pageFrom := first; pageFrom <= last; pageFrom += TransactionBackfillPageSize {
+		pageTo := min(pageFrom+TransactionBackfillPageSize-1, last)
+		req := NewTransactionGetByIDRangeRequest(FormatTransactionID(pageFrom), FormatTransactionID(pageTo)).
+			SetFilters(TransactionFilterOrder)
+		resp, err := s.client.Transaction.GetByIDRange(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get transactions: %w", err)
+		}
+		for _, transaction := range resp.Transactions {
+			if event, ok := orderEventOf(transaction); ok && orderIDs[event.OrderID] {
+				events = append(events, event)
+			}
+		}
+	}
+	return events, nil
+}
+
+// orderEventOf returns the event recorded by transaction, if it is an Order lifecycle event.
+func orderEventOf(transaction Transaction) (OrderEvent, bool) {
+	event := OrderEvent{Transaction: transaction}
+	switch t := transaction.(type) {
+	case OrderRejectTransaction:
+		return event, false
+	case OrderTransaction:
+		event.Type, event.OrderID = OrderEventCreated, t.GetID()
+	case *OrderFillTransaction:
+		event.Type, event.OrderID = OrderEventFilled, t.OrderID
+	case *OrderCancelTransaction:
+		event.Type, event.OrderID = OrderEventCancelled, t.OrderID
+		if t.ReplacedByOrderID != nil {
+			event.Type, event.ReplacedByOrderID = OrderEventReplaced, t.ReplacedByOrderID
+		}
+	case *OrderCancelRejectTransaction:
+		event.Type, event.OrderID = OrderEventCancelRejected, t.OrderID
+	case *OrderClientExtensionsModifyTransaction:
+		event.Type, event.OrderID = OrderEventClientExtensionsModified, t.OrderID
+	default:
+		return event, false
+	}
+	return event, true
+}
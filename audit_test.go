@@ -0,0 +1,66 @@
+package oanda
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOrderService_AuditTrail(t *testing.T) {
+	orders := map[string]string{
+		"10": `{"type":"LIMIT","id":"10","state":"CANCELLED","replacedByOrderID":"13","cancellingTransactionID":"12"}`,
+		"13": `{"type":"LIMIT","id":"13","state":"FILLED","replacesOrderID":"10","fillingTransactionID":"15"}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v3/accounts/1/orders/10", "/v3/accounts/1/orders/13":
+			id := r.URL.Path[len("/v3/accounts/1/orders/"):]
+			_, _ = fmt.Fprintf(w, `{"order":%s,"lastTransactionID":"20"}`, orders[id])
+		case "/v3/accounts/1/transactions/idrange":
+			if q := r.URL.Query(); q.Get("from") != "10" || q.Get("to") != "15" || q.Get("type") != "ORDER" {
+				t.Errorf("got query %s, want from 10 to 15 of type ORDER", r.URL.RawQuery)
+			}
+			_, _ = fmt.Fprint(w, `{"transactions":[
+				{"type":"LIMIT_ORDER","id":"10"},
+				{"type":"ORDER_CLIENT_EXTENSIONS_MODIFY","id":"11","orderID":"10"},
+				{"type":"ORDER_CANCEL","id":"12","orderID":"10","reason":"CLIENT_REQUEST_REPLACED","replacedByOrderID":"13"},
+				{"type":"LIMIT_ORDER","id":"13","replacesOrderID":"10"},
+				{"type":"ORDER_CANCEL","id":"14","orderID":"99","reason":"CLIENT_REQUEST"},
+				{"type":"ORDER_FILL","id":"15","orderID":"13"}]}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client := NewClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+
+	for _, id := range []OrderID{"10", "13"} {
+		trail, err := client.Order.AuditTrail(t.Context(), id)
+		if err != nil {
+			t.Fatalf("failed to get audit trail of order %s: %v", id, err)
+		}
+		if len(trail.Orders) != 2 || trail.Orders[0].GetID() != "10" || trail.Current().GetID() != "13" {
+			t.Errorf("got order chain %v, want 10 and 13", trail.Orders)
+		}
+		want := []struct {
+			eventType OrderEventType
+			orderID   OrderID
+		}{
+			{OrderEventCreated, "10"},
+			{OrderEventClientExtensionsModified, "10"},
+			{OrderEventReplaced, "10"},
+			{OrderEventCreated, "13"},
+			{OrderEventFilled, "13"},
+		}
+		if len(trail.Events) != len(want) {
+			t.Fatalf("got %d events, want %d", len(trail.Events), len(want))
+		}
+		for i, w := range want {
+			if e := trail.Events[i]; e.Type != w.eventType || e.OrderID != w.orderID {
+				t.Errorf("event %d: got %s of order %s, want %s of order %s", i, e.Type, e.OrderID, w.eventType, w.orderID)
+			}
+		}
+	}
+}
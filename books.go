@@ -0,0 +1,136 @@
+package oanda
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// BookSnapshotTimes returns the times from from to to, both inclusive, every interval, such as
+// the times of the order or position book snapshots around an event for
+// [instrumentService.OrderBooks] and [instrumentService.PositionBooks]. OANDA takes book
+// snapshots every 20 minutes, so from and interval should be aligned to them. It returns nil if
+// interval is not positive or to is before from.
+func BookSnapshotTimes(from, to time.Time, interval time.Duration) []time.Time {
+	if interval <= 0 {
+		return nil
+	}
+	var times []time.Time
+	for t := from; !t.After(to); t = t.Add(interval) {
+		times = append(times, t)
+	}
+	return times
+}
+
+// OrderBooks fetches the order book snapshots of instrument at times, several at a time, and
+// returns them in the order of times. It returns the errors of the snapshots that could not be
+// fetched joined.
+//
+// This corresponds to the OANDA API endpoint: GET /v3/instruments/{instrument}/orderBook
+//
+// Reference: https://developer.oanda.com/rest-live-v20/instrument-ep/#collapse_endpoint_3
+func (s *instrumentService) OrderBooks(ctx context.Context, instrument InstrumentName, times ...time.Time) ([]*OrderBook, error) {
+	return fetchBooks(ctx, times, func(ctx context.Context, t time.Time) (*OrderBook, error) {
+		book, err := s.OrderBook(ctx, instrument, t)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get order book at %s: %w", t.Format(time.RFC3339), err)
+		}
+		return book, nil
+	})
+}
+
+// PositionBooks fetches the position book snapshots of instrument at times, several at a time,
+// and returns them in the order of times. It returns the errors of the snapshots that could not
+// be fetched joined.
+//
+// This corresponds to the OANDA API endpoint: GET /v3/instruments/{instrument}/positionBook
+//
+// Reference: https://developer.oanda.com/rest-live-v20/instrument-ep/#collapse_endpoint_4
+func (s *instrumentService) PositionBooks(ctx context.Context, instrument InstrumentName, times ...time.Time) ([]*PositionBook, error) {
+	return fetchBooks(ctx, times, func(ctx context.Context, t time.Time) (*PositionBook, error) {
+		book, err := s.PositionBook(ctx, instrument, t)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get position book at %s: %w", t.Format(time.RFC3339), err)
+		}
+		return book, nil
+	})
+}
+
+// fetchBooks calls fetch for every time of times, candlesBatchConcurrency at a time.
+func fetchBooks[T any](ctx context.Context, times []time.Time, fetch func(context.Context, time.Time) (*T, error)) ([]*T, error) {
+	books := make([]*T, len(times))
+	errs := make([]error, len(times))
+	sem := make(chan struct{}, candlesBatchConcurrency)
+	var wg sync.WaitGroup
+	for i, t := range times {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			books[i], errs[i] = fetch(ctx, t)
+		}()
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return books, nil
+}
+
+// OrderBookDelta is the change of an order book between two consecutive snapshots, returned by
+// [DiffOrderBookSeries].
+type OrderBookDelta struct {
+	// From and To are the times of the snapshots.
+	From, To time.Time
+	// Changes are the changes of the buckets, in ascending price order.
+	Changes []OrderBookChange
+}
+
+// DiffOrderBookSeries compares every snapshot of books, such as the results of
+// [instrumentService.OrderBooks], with the one before it with [DiffOrderBooks], and returns one
+// delta per pair of consecutive snapshots.
+func DiffOrderBookSeries(books []*OrderBook) ([]OrderBookDelta, error) {
+	var deltas []OrderBookDelta
+	for i := 1; i < len(books); i++ {
+		changes, err := DiffOrderBooks(books[i-1], books[i])
+		if err != nil {
+			return nil, err
+		}
+		deltas = append(deltas, OrderBookDelta{From: bookTime(books[i-1].Time), To: bookTime(books[i].Time), Changes: changes})
+	}
+	return deltas, nil
+}
+
+// PositionBookDelta is the change of a position book between two consecutive snapshots,
+// returned by [DiffPositionBookSeries].
+type PositionBookDelta struct {
+	// From and To are the times of the snapshots.
+	From, To time.Time
+	// Changes are the changes of the buckets, in ascending price order.
+	Changes []PositionBookChange
+}
+
+// DiffPositionBookSeries compares every snapshot of books, such as the results of
+// [instrumentService.PositionBooks], with the one before it with [DiffPositionBooks], and
+// returns one delta per pair of consecutive snapshots.
+func DiffPositionBookSeries(books []*PositionBook) ([]PositionBookDelta, error) {
+	var deltas []PositionBookDelta
+	for i := 1; i < len(books); i++ {
+		changes, err := DiffPositionBooks(books[i-1], books[i])
+		if err != nil {
+			return nil, err
+		}
+		deltas = append(deltas, PositionBookDelta{From: bookTime(books[i-1].Time), To: bookTime(books[i].Time), Changes: changes})
+	}
+	return deltas, nil
+}
+
+func bookTime(t DateTime) time.Time {
+	if t.Time == nil {
+		return time.Time{}
+	}
+	return *t.Time
+}
@@ -0,0 +1,85 @@
+package oanda
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBookSeries(t *testing.T) {
+	buckets := map[string]string{
+		"2025-01-01T12:00:00Z": `[{"price":"1.1000","longCountPercent":"0.3000","shortCountPercent":"0.4000"}]`,
+		"2025-01-01T12:20:00Z": `[{"price":"1.1000","longCountPercent":"0.5000","shortCountPercent":"0.1000"}]`,
+		"2025-01-01T12:40:00Z": `[
+			{"price":"1.0995","longCountPercent":"0.2000","shortCountPercent":"0.0000"},
+			{"price":"1.1000","longCountPercent":"0.4000","shortCountPercent":"0.1000"}]`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		snapshot := r.URL.Query().Get("time")
+		if buckets[snapshot] == "" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = fmt.Fprint(w, `{"errorMessage":"No book at time"}`)
+			return
+		}
+		switch r.URL.Path {
+		case "/v3/instruments/EUR_USD/orderBook":
+			_, _ = fmt.Fprintf(w, `{"orderBook":{"instrument":"EUR_USD","time":%q,"price":"1.1002","bucketWidth":"0.0005","buckets":%s}}`,
+				snapshot, buckets[snapshot])
+		case "/v3/instruments/EUR_USD/positionBook":
+			_, _ = fmt.Fprintf(w, `{"positionBook":{"instrument":"EUR_USD","time":%q,"price":"1.1002","bucketWidth":"0.0005","buckets":%s}}`,
+				snapshot, buckets[snapshot])
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+	client := NewClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	times := BookSnapshotTimes(start, start.Add(40*time.Minute), 20*time.Minute)
+	if len(times) != 3 || !times[2].Equal(start.Add(40*time.Minute)) {
+		t.Fatalf("got times %v", times)
+	}
+	orderBooks, err := client.Instrument.OrderBooks(t.Context(), "EUR_USD", times...)
+	if err != nil {
+		t.Fatalf("failed to get order books: %v", err)
+	}
+	for i, book := range orderBooks {
+		if !book.Time.Equal(times[i]) {
+			t.Errorf("got book %d at %v, want %v", i, book.Time, times[i])
+		}
+	}
+	deltas, err := DiffOrderBookSeries(orderBooks)
+	if err != nil {
+		t.Fatalf("failed to diff order books: %v", err)
+	}
+	var got []string
+	for _, delta := range deltas {
+		var changes []string
+		for _, c := range delta.Changes {
+			changes = append(changes, fmt.Sprintf("%s %+.2f", c.Price, c.NetCountPercent()))
+		}
+		got = append(got, fmt.Sprintf("%s-%s %s", delta.From.Format("15:04"), delta.To.Format("15:04"), strings.Join(changes, " ")))
+	}
+	want := "12:00-12:20 1.1000 +0.50,12:20-12:40 1.0995 +0.20 1.1000 -0.10"
+	if strings.Join(got, ",") != want {
+		t.Errorf("got deltas %s, want %s", strings.Join(got, ","), want)
+	}
+
+	positionBooks, err := client.Instrument.PositionBooks(t.Context(), "EUR_USD", times...)
+	if err != nil {
+		t.Fatalf("failed to get position books: %v", err)
+	}
+	if positionDeltas, err := DiffPositionBookSeries(positionBooks); err != nil || len(positionDeltas) != 2 || len(positionDeltas[1].Changes) != 2 {
+		t.Errorf("got position deltas %+v (%v)", positionDeltas, err)
+	}
+	if _, err := client.Instrument.PositionBooks(t.Context(), "EUR_USD", start, start.Add(time.Hour)); err == nil || !strings.Contains(err.Error(), "2025-01-01T13:00:00Z") {
+		t.Errorf("got error %v for a missing snapshot", err)
+	}
+	if _, err := DiffOrderBookSeries([]*OrderBook{orderBooks[0], {Instrument: "USD_JPY"}}); err == nil {
+		t.Error("got no error for different instruments")
+	}
+}
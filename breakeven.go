@@ -0,0 +1,442 @@
+package oanda
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// BreakEvenRule moves the stop loss of a Trade to its entry price, plus an optional buffer, once
+// the price has moved far enough in the Trade's favour. Rules are created by
+// [BreakEvenManager.Add] and persisted by a [BreakEvenStore].
+type BreakEvenRule struct {
+	// TradeID is the ID of the Trade.
+	TradeID TradeID `json:"tradeID"`
+	// Instrument is the Instrument of the Trade.
+	Instrument InstrumentName `json:"instrument"`
+	// Units are the current units of the Trade when the rule was added; their sign gives its side.
+	Units DecimalNumber `json:"units"`
+	// Entry is the price the Trade was opened at.
+	Entry PriceValue `json:"entry"`
+	// TriggerPrice is the closing price, the bid for a long Trade and the ask for a short one,
+	// at which the stop loss is moved.
+	TriggerPrice PriceValue `json:"triggerPrice"`
+	// Stop is the price the stop loss is moved to: Entry moved by the buffer in the Trade's
+	// favour.
+	Stop PriceValue `json:"stop"`
+	// Triggered is true once the price has reached TriggerPrice. A triggered rule whose stop
+	// loss could not be moved is retried when the price reaches TriggerPrice again, and is
+	// reset once the price falls back to Stop, where the stop loss can no longer be placed.
+	Triggered bool `json:"triggered"`
+}
+
+func (r BreakEvenRule) long() bool {
+	return !strings.HasPrefix(string(r.Units), "-")
+}
+
+// BreakEvenStore persists the rules of a [BreakEvenManager] so that they can be restored with
+// [BreakEvenManager.Restore] after a crash or restart.
+type BreakEvenStore interface {
+	// Save stores or updates a rule.
+	Save(ctx context.Context, rule BreakEvenRule) error
+	// Delete removes the rule of the Trade with the given ID. Deleting an unknown rule is not
+	// an error.
+	Delete(ctx context.Context, tradeID TradeID) error
+	// Load returns all stored rules.
+	Load(ctx context.Context) ([]BreakEvenRule, error)
+}
+
+// BreakEvenEvent reports that a [BreakEvenManager] moved the stop loss of a Trade to break-even.
+type BreakEvenEvent struct {
+	// Rule is the rule that was applied.
+	Rule BreakEvenRule
+	// Price is the price that triggered the rule.
+	Price ClientPrice
+	// Response is the response of [tradeService.UpdateOrders].
+	Response *TradeUpdateOrdersResponse
+}
+
+// BreakEvenManager moves the stop losses of Trades to break-even. It watches the prices of a
+// pricing stream and, once a rule triggers, replaces the Trade's Stop Loss Order through
+// [tradeService.UpdateOrders]. A rule is removed once applied, or when its Trade is closed or
+// already has a stop loss at or beyond break-even. Create one with [NewBreakEvenManager].
+type BreakEvenManager struct {
+	client  *Client
+	store   BreakEvenStore
+	onMove  func(BreakEvenEvent)
+	onError func(error)
+
+	mu    sync.Mutex
+	rules map[TradeID]BreakEvenRule
+}
+
+// NewBreakEvenManager creates a new BreakEvenManager that replaces stop losses with client.
+func NewBreakEvenManager(client *Client) *BreakEvenManager {
+	return &BreakEvenManager{client: client, rules: make(map[TradeID]BreakEvenRule)}
+}
+
+// SetStore sets the store used to persist the rules.
+func (m *BreakEvenManager) SetStore(store BreakEvenStore) *BreakEvenManager {
+	m.store = store
+	return m
+}
+
+// OnMove sets a callback called with every stop loss moved to break-even.
+func (m *BreakEvenManager) OnMove(fn func(BreakEvenEvent)) *BreakEvenManager {
+	m.onMove = fn
+	return m
+}
+
+// SetErrorHandler sets a function that is called with the errors of processing a price while
+// [BreakEvenManager.Run] is running.
+func (m *BreakEvenManager) SetErrorHandler(handler func(error)) *BreakEvenManager {
+	m.onError = handler
+	return m
+}
+
+// Rules returns the rules currently tracked by the manager, ordered by Trade ID.
+func (m *BreakEvenManager) Rules() []BreakEvenRule {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	rules := make([]BreakEvenRule, 0, len(m.rules))
+	for _, rule := range m.rules {
+		rules = append(rules, rule)
+	}
+	slices.SortFunc(rules, func(a, b BreakEvenRule) int { return strings.Compare(a.TradeID, b.TradeID) })
+	return rules
+}
+
+// Add fetches the open Trade identified by specifier and adds a rule that moves its stop loss
+// to its entry price moved by buffer in its favour, once the price has moved by trigger in its
+// favour. trigger and buffer are in price units, such as 0.0020 for 20 pips of EUR_USD; buffer
+// may be 0 and must be less than trigger. A rule added for a Trade replaces its previous rule.
+func (m *BreakEvenManager) Add(ctx context.Context, specifier TradeSpecifier, trigger, buffer DecimalNumber) (*BreakEvenRule, error) {
+	t, err := trigger.Rat()
+	if err != nil {
+		return nil, fmt.Errorf("invalid trigger: %w", err)
+	}
+	b, err := buffer.Rat()
+	if err != nil {
+		return nil, fmt.Errorf("invalid buffer: %w", err)
+	}
+	if t.Sign() <= 0 {
+		return nil, fmt.Errorf("trigger %s must be positive", trigger)
+	}
+	if b.Sign() < 0 || b.Cmp(t) >= 0 {
+		return nil, fmt.Errorf("buffer %s must be at least 0 and less than trigger %s", buffer, trigger)
+	}
+	resp, err := m.client.Trade.Details(ctx, specifier)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trade %s: %w", specifier, err)
+	}
+	trade := resp.Trade
+	if trade.State != TradeStateOpen {
+		return nil, fmt.Errorf("trade %s is %s", trade.ID, trade.State)
+	}
+	precision, err := m.client.Instruments().Precision(ctx, trade.Instrument)
+	if err != nil {
+		return nil, err
+	}
+	rule := BreakEvenRule{TradeID: trade.ID, Instrument: trade.Instrument, Units: trade.CurrentUnits, Entry: trade.Price}
+	entry, err := trade.Price.Rat()
+	if err != nil {
+		return nil, fmt.Errorf("invalid price of trade %s: %w", trade.ID, err)
+	}
+	favour := func(distance *big.Rat) PriceValue {
+		if !rule.long() {
+			distance = new(big.Rat).Neg(distance)
+		}
+		return PriceValue(new(big.Rat).Add(entry, distance).FloatString(precision.DisplayPrecision))
+	}
+	rule.TriggerPrice, rule.Stop = favour(t), favour(b)
+	if m.store != nil {
+		if err := m.store.Save(ctx, rule); err != nil {
+			return nil, fmt.Errorf("failed to save break-even rule: %w", err)
+		}
+	}
+	m.mu.Lock()
+	m.rules[rule.TradeID] = rule
+	m.mu.Unlock()
+	return &rule, nil
+}
+
+// Remove removes the rule of the Trade with the given ID. It returns false if there is none.
+func (m *BreakEvenManager) Remove(ctx context.Context, tradeID TradeID) (bool, error) {
+	m.mu.Lock()
+	rule, ok := m.rules[tradeID]
+	delete(m.rules, tradeID)
+	m.mu.Unlock()
+	if !ok {
+		return false, nil
+	}
+	return true, m.delete(ctx, rule)
+}
+
+// Restore loads the rules from the store and drops those whose Trade is no longer open.
+func (m *BreakEvenManager) Restore(ctx context.Context) error {
+	if m.store == nil {
+		return errors.New("no break-even store set")
+	}
+	rules, err := m.store.Load(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load break-even rules: %w", err)
+	}
+	for _, rule := range rules {
+		_, open, err := m.openTrade(ctx, rule.TradeID)
+		if err != nil {
+			return err
+		}
+		if !open {
+			if err := m.delete(ctx, rule); err != nil {
+				return err
+			}
+			continue
+		}
+		m.mu.Lock()
+		m.rules[rule.TradeID] = rule
+		m.mu.Unlock()
+	}
+	return nil
+}
+
+// openTrade fetches the Trade with the given ID and reports whether it is still open.
+func (m *BreakEvenManager) openTrade(ctx context.Context, tradeID TradeID) (*Trade, bool, error) {
+	resp, err := m.client.Trade.Details(ctx, tradeID)
+	var notFound NotFound
+	if errors.As(err, &notFound) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get trade %s: %w", tradeID, err)
+	}
+	return &resp.Trade, resp.Trade.State == TradeStateOpen, nil
+}
+
+// Process checks the rules of the Trades on the Instrument of price and moves the stop losses
+// of those it triggers. Before moving a stop loss, the Trade is fetched again, so that a stop
+// loss already at or beyond break-even, such as one moved by a [TrailingStopManager], is never
+// loosened. The errors are returned joined.
+func (m *BreakEvenManager) Process(ctx context.Context, price ClientPrice) ([]BreakEvenEvent, error) {
+	var triggered, reset []BreakEvenRule
+	var errs []error
+	m.mu.Lock()
+	for id, rule := range m.rules {
+		if rule.Instrument != price.Instrument {
+			continue
+		}
+		reached, err := rule.reached(price)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("trade %s: %w", id, err))
+			continue
+		}
+		if reached {
+			triggered = append(triggered, rule)
+			continue
+		}
+		if !rule.Triggered {
+			continue
+		}
+		placeable, err := rule.placeable(price)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("trade %s: %w", id, err))
+			continue
+		}
+		if !placeable {
+			rule.Triggered = false
+			m.rules[id] = rule
+			reset = append(reset, rule)
+		}
+	}
+	m.mu.Unlock()
+	slices.SortFunc(triggered, func(a, b BreakEvenRule) int { return strings.Compare(a.TradeID, b.TradeID) })
+
+	if m.store != nil {
+		for _, rule := range reset {
+			if err := m.store.Save(ctx, rule); err != nil {
+				errs = append(errs, fmt.Errorf("trade %s: failed to save break-even rule: %w", rule.TradeID, err))
+			}
+		}
+	}
+	var events []BreakEvenEvent
+	for _, rule := range triggered {
+		resp, err := m.apply(ctx, rule, price)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("trade %s: %w", rule.TradeID, err))
+			continue
+		}
+		if resp == nil {
+			continue
+		}
+		event := BreakEvenEvent{Rule: rule, Price: price, Response: resp}
+		event.Rule.Triggered = true
+		if m.onMove != nil {
+			m.onMove(event)
+		}
+		events = append(events, event)
+	}
+	return events, errors.Join(errs...)
+}
+
+// reached reports whether the closing price of price has reached the rule's TriggerPrice.
+func (r BreakEvenRule) reached(price ClientPrice) (bool, error) {
+	cmp, err := r.compareClosing(price, r.TriggerPrice)
+	if err != nil {
+		return false, err
+	}
+	return cmp >= 0, nil
+}
+
+// placeable reports whether the closing price of price is still beyond the rule's Stop in the
+// Trade's favour, so that a stop loss can be placed there.
+func (r BreakEvenRule) placeable(price ClientPrice) (bool, error) {
+	cmp, err := r.compareClosing(price, r.Stop)
+	if err != nil {
+		return false, err
+	}
+	return cmp > 0, nil
+}
+
+// compareClosing compares the closing price of price, the bid for a long Trade and the ask for
+// a short one, with target in the Trade's favour: the result is positive if the closing price
+// is beyond target.
+func (r BreakEvenRule) compareClosing(price ClientPrice, target PriceValue) (int, error) {
+	bucket, ok := price.BestBid()
+	if !r.long() {
+		bucket, ok = price.BestAsk()
+	}
+	if !ok {
+		return 0, errors.New("price has no closing side")
+	}
+	cmp, err := comparePrices(bucket.Price, target)
+	if err != nil {
+		return 0, err
+	}
+	if !r.long() {
+		cmp = -cmp
+	}
+	return cmp, nil
+}
+
+// apply moves the stop loss of the Trade of a triggered rule and removes the rule. It returns
+// a nil response if the rule was removed without moving the stop loss. If the stop loss cannot
+// be moved, the rule is kept, marked as triggered.
+func (m *BreakEvenManager) apply(ctx context.Context, rule BreakEvenRule, price ClientPrice) (*TradeUpdateOrdersResponse, error) {
+	if !rule.Triggered {
+		rule.Triggered = true
+		m.mu.Lock()
+		if _, ok := m.rules[rule.TradeID]; ok {
+			m.rules[rule.TradeID] = rule
+		}
+		m.mu.Unlock()
+		if m.store != nil {
+			if err := m.store.Save(ctx, rule); err != nil {
+				return nil, fmt.Errorf("failed to save break-even rule: %w", err)
+			}
+		}
+	}
+	trade, open, err := m.openTrade(ctx, rule.TradeID)
+	if err != nil {
+		return nil, err
+	}
+	if !open || trade.StopLossOrder != nil && !rule.tightens(trade.StopLossOrder.Price) {
+		return nil, m.forget(ctx, rule)
+	}
+	bucket, _ := price.BestBid()
+	if !rule.long() {
+		bucket, _ = price.BestAsk()
+	}
+	req := NewTradeUpdateOrdersRequest().
+		SetStopLoss(NewStopLossDetails().SetPrice(rule.Stop)).
+		SetReferencePrice(rule.Units, bucket.Price)
+	resp, err := m.client.Trade.UpdateOrders(ctx, rule.TradeID, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to move stop loss: %w", err)
+	}
+	return resp, m.forget(ctx, rule)
+}
+
+// tightens reports whether moving a stop loss at stop to the rule's Stop moves it towards the
+// price, that is whether stop is not yet at or beyond break-even.
+func (r BreakEvenRule) tightens(stop PriceValue) bool {
+	cmp, err := comparePrices(r.Stop, stop)
+	if err != nil {
+		return false
+	}
+	if r.long() {
+		return cmp > 0
+	}
+	return cmp < 0
+}
+
+// forget removes a rule that is done.
+func (m *BreakEvenManager) forget(ctx context.Context, rule BreakEvenRule) error {
+	m.mu.Lock()
+	delete(m.rules, rule.TradeID)
+	m.mu.Unlock()
+	return m.delete(ctx, rule)
+}
+
+func (m *BreakEvenManager) delete(ctx context.Context, rule BreakEvenRule) error {
+	if m.store == nil {
+		return nil
+	}
+	if err := m.store.Delete(ctx, rule.TradeID); err != nil {
+		return fmt.Errorf("failed to delete break-even rule: %w", err)
+	}
+	return nil
+}
+
+// Run passes the prices received on a pricing stream channel, such as the one returned by
+// [PriceStream.Updates], to [BreakEvenManager.Process] until the channel is closed or ctx is
+// cancelled, in which case it returns the context's error. Errors are passed to the handler set
+// with SetErrorHandler as they occur.
+func (m *BreakEvenManager) Run(ctx context.Context, items <-chan PriceStreamItem) error {
+	return processPrices(ctx, items, func(price ClientPrice) error {
+		_, err := m.Process(ctx, price)
+		return err
+	}, m.onError)
+}
+
+// comparePrices compares prices a and b.
+func comparePrices(a, b PriceValue) (int, error) {
+	x, err := a.Rat()
+	if err != nil {
+		return 0, err
+	}
+	y, err := b.Rat()
+	if err != nil {
+		return 0, err
+	}
+	return x.Cmp(y), nil
+}
+
+// FileBreakEvenStore is a [BreakEvenStore] that keeps rules in a JSON file. Create one with
+// [NewFileBreakEvenStore].
+type FileBreakEvenStore struct {
+	store *jsonFileStore[TradeID, BreakEvenRule]
+}
+
+// NewFileBreakEvenStore creates a new FileBreakEvenStore backed by the file at path. The file is
+// created on the first Save.
+func NewFileBreakEvenStore(path string) *FileBreakEvenStore {
+	return &FileBreakEvenStore{store: newJSONFileStore[TradeID, BreakEvenRule](path, "break-even store")}
+}
+
+// Save stores or updates a rule.
+func (s *FileBreakEvenStore) Save(_ context.Context, rule BreakEvenRule) error {
+	return s.store.save(rule.TradeID, rule)
+}
+
+// Delete removes the rule of the Trade with the given ID.
+func (s *FileBreakEvenStore) Delete(_ context.Context, tradeID TradeID) error {
+	return s.store.delete(tradeID)
+}
+
+// Load returns all stored rules.
+func (s *FileBreakEvenStore) Load(_ context.Context) ([]BreakEvenRule, error) {
+	return s.store.load()
+}
@@ -0,0 +1,147 @@
+package oanda
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestBreakEvenManager(t *testing.T) {
+	var mu sync.Mutex
+	var stops []string
+	puts, gets := 0, 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v3/accounts/1/trades/7", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		gets++
+		mu.Unlock()
+		_, _ = fmt.Fprint(w, `{"trade":{"id":"7","instrument":"EUR_USD","price":"1.10000","state":"OPEN",
+			"currentUnits":"1000","stopLossOrder":{"type":"STOP_LOSS","id":"9","price":"1.09500"}}}`)
+	})
+	mux.HandleFunc("GET /v3/accounts/1/trades/8", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `{"trade":{"id":"8","instrument":"EUR_USD","price":"1.10000","state":"OPEN",
+			"currentUnits":"-1000","stopLossOrder":{"type":"STOP_LOSS","id":"10","price":"1.09950"}}}`)
+	})
+	mux.HandleFunc("GET /v3/accounts/1/trades/11", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = fmt.Fprint(w, `{"errorMessage":"The Trade specified does not exist"}`)
+	})
+	mux.HandleFunc("GET /v3/accounts/1/instruments", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `{"instruments":[{"name":"EUR_USD","pipLocation":-4,"displayPrecision":5,"tradeUnitsPrecision":0}]}`)
+	})
+	mux.HandleFunc("PUT /v3/accounts/1/trades/7/orders", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if puts++; puts == 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = fmt.Fprint(w, `{"errorCode":"MARKET_HALTED","errorMessage":"market halted"}`)
+			return
+		}
+		var req TradeUpdateOrdersRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.StopLoss == nil || req.StopLoss.Price == nil {
+			t.Errorf("got stop loss %+v (%v)", req.StopLoss, err)
+			return
+		}
+		stops = append(stops, string(*req.StopLoss.Price))
+		_, _ = fmt.Fprint(w, `{"lastTransactionID":"20"}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client := NewClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+
+	store := NewFileBreakEvenStore(filepath.Join(t.TempDir(), "breakeven.json"))
+	var events []BreakEvenEvent
+	manager := NewBreakEvenManager(client).SetStore(store).OnMove(func(e BreakEvenEvent) { events = append(events, e) })
+	rule, err := manager.Add(t.Context(), "7", "0.0020", "0.0002")
+	if err != nil {
+		t.Fatalf("failed to add rule: %v", err)
+	}
+	if rule.TriggerPrice != "1.10200" || rule.Stop != "1.10020" {
+		t.Errorf("got rule %+v", rule)
+	}
+	if _, err := manager.Add(t.Context(), "8", "0.0020", "0"); err != nil {
+		t.Fatalf("failed to add rule: %v", err)
+	}
+	if _, err := manager.Add(t.Context(), "7", "0.0020", "0.0020"); err == nil {
+		t.Error("got no error for a buffer as large as the trigger")
+	}
+
+	price := func(bid, ask string) ClientPrice {
+		return ClientPrice{
+			Instrument: "EUR_USD",
+			Bids:       []PriceBucket{{Price: PriceValue(bid), Liquidity: 1000000}},
+			Asks:       []PriceBucket{{Price: PriceValue(ask), Liquidity: 1000000}},
+		}
+	}
+	// Neither rule is reached.
+	if events, err := manager.Process(t.Context(), price("1.10100", "1.10110")); err != nil || len(events) != 0 {
+		t.Errorf("got events %+v (%v)", events, err)
+	}
+	// The rule of short trade 8 is reached, but its stop loss is already beyond break-even.
+	if events, err := manager.Process(t.Context(), price("1.09780", "1.09790")); err != nil || len(events) != 0 {
+		t.Errorf("got events %+v (%v)", events, err)
+	}
+	// The rule of trade 7 is reached, but the stop loss cannot be moved.
+	var runErrs []error
+	manager.SetErrorHandler(func(err error) { runErrs = append(runErrs, err) })
+	items := make(chan PriceStreamItem, 2)
+	items <- PricingHeartbeat{Type: "HEARTBEAT"}
+	items <- price("1.10210", "1.10220")
+	close(items)
+	if err := manager.Run(t.Context(), items); err != nil || len(runErrs) != 1 {
+		t.Errorf("got error %v and errors %v, want one error for a rejected stop loss", err, runErrs)
+	}
+	stored, err := store.Load(t.Context())
+	if err != nil || len(stored) != 1 || stored[0].TradeID != "7" || !stored[0].Triggered {
+		t.Fatalf("got stored rules %+v (%v)", stored, err)
+	}
+
+	// A restarted manager keeps the triggered rule, but only retries it once the trigger is
+	// reached again.
+	stored = append(stored, BreakEvenRule{TradeID: "11", Instrument: "EUR_USD", Units: "1000"})
+	if err := store.Save(t.Context(), stored[1]); err != nil {
+		t.Fatal(err)
+	}
+	restored := NewBreakEvenManager(client).SetStore(store).OnMove(func(e BreakEvenEvent) { events = append(events, e) })
+	if err := restored.Restore(t.Context()); err != nil {
+		t.Fatalf("failed to restore: %v", err)
+	}
+	if rules := restored.Rules(); len(rules) != 1 || rules[0].TradeID != "7" {
+		t.Errorf("got restored rules %+v", rules)
+	}
+	before := gets
+	if events, err := restored.Process(t.Context(), price("1.10150", "1.10160")); err != nil || len(events) != 0 {
+		t.Fatalf("got events %+v (%v) below the trigger", events, err)
+	}
+	if gets != before || len(stops) != 0 {
+		t.Errorf("got %d trade requests and stops %v below the trigger", gets-before, stops)
+	}
+	// Once the price falls back to the stop, the rule is reset.
+	if _, err := restored.Process(t.Context(), price("1.10020", "1.10030")); err != nil {
+		t.Fatalf("failed to process: %v", err)
+	}
+	if stored, err := store.Load(t.Context()); err != nil || len(stored) != 1 || stored[0].Triggered {
+		t.Errorf("got stored rules %+v (%v), want the rule reset", stored, err)
+	}
+	if _, err := restored.Process(t.Context(), price("1.10200", "1.10210")); err != nil {
+		t.Fatalf("failed to process: %v", err)
+	}
+	if len(stops) != 1 || stops[0] != "1.10020" || len(events) != 1 || events[0].Rule.TradeID != "7" {
+		t.Errorf("got stops %v and events %+v", stops, events)
+	}
+	if stored, err := store.Load(t.Context()); err != nil || len(stored) != 0 || len(restored.Rules()) != 0 {
+		t.Errorf("got stored rules %+v (%v) after the move", stored, err)
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	if err := restored.Run(ctx, make(chan PriceStreamItem)); !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,325 @@
+package oanda
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CandleCacheKey identifies a series of candlesticks in a [CandleCache].
+type CandleCacheKey struct {
+	Instrument  InstrumentName
+	Granularity CandlestickGranularity
+	// Price is the price components of the candlesticks. The default is midpoint candlesticks.
+	Price PriceComponents
+}
+
+// CandleRange is a time range [From, To) whose candlesticks were fetched at FetchedAt.
+type CandleRange struct {
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+	FetchedAt time.Time `json:"fetchedAt"`
+}
+
+// CandleCacheEntry is a cached series of candlesticks: the candlesticks in time order, and the
+// time ranges known to be complete, so that a range without candlesticks, such as a weekend, is
+// not fetched again.
+type CandleCacheEntry struct {
+	Ranges  []CandleRange `json:"ranges"`
+	Candles []Candlestick `json:"candles"`
+}
+
+// CandleStore persists the entries of a [CandleCache]. Implementations must be safe for
+// concurrent use.
+//
+// The package provides [MemoryCandleStore] and [FileCandleStore]; a database such as SQLite is
+// supported by implementing the interface on top of a table keyed by the CandleCacheKey.
+type CandleStore interface {
+	// Load returns the entry stored for key, or an empty entry if there is none.
+	Load(ctx context.Context, key CandleCacheKey) (CandleCacheEntry, error)
+	// Save stores entry for key, replacing the previous one.
+	Save(ctx context.Context, key CandleCacheKey, entry CandleCacheEntry) error
+}
+
+// CandleCache serves candlesticks from a [CandleStore] and fetches only the parts of a requested
+// range that are missing from it, so that repeated and overlapping requests, such as those of
+// backtests run again and again, are served locally. Incomplete candlesticks are returned but
+// their range is not recorded as cached, so they are fetched again until they are complete, and
+// neither is any range from the start of the current candlestick period on.
+// Create one with [NewCandleCache].
+type CandleCache struct {
+	client *Client
+	store  CandleStore
+	ttl    time.Duration
+	mu     sync.Mutex
+}
+
+// NewCandleCache creates a new CandleCache that fetches candlesticks with client and keeps them
+// in store. Cached ranges do not expire unless [CandleCache.SetTTL] is called.
+func NewCandleCache(client *Client, store CandleStore) *CandleCache {
+	return &CandleCache{client: client, store: store}
+}
+
+// SetTTL makes cached ranges expire ttl after they were fetched, so that candlesticks revised by
+// OANDA after the fact are eventually fetched again. A zero ttl disables expiry.
+func (c *CandleCache) SetTTL(ttl time.Duration) *CandleCache {
+	c.ttl = ttl
+	return c
+}
+
+// Candles returns the candlesticks of key that start within [from, to), in time order. A zero
+// to is the current time. The missing ranges are fetched with [instrumentService.CandlesRange]
+// semantics and added to the store before Candles returns.
+//
+// This corresponds to the OANDA API endpoint: GET /v3/instruments/{instrument}/candles
+//
+// Reference: https://developer.oanda.com/rest-live-v20/instrument-ep/#collapse_endpoint_1
+func (c *CandleCache) Candles(ctx context.Context, key CandleCacheKey, from, to time.Time) ([]Candlestick, error) {
+	now := time.Now()
+	if to.IsZero() {
+		to = now
+	}
+	if !from.Before(to) {
+		return nil, errors.New("from must be before to")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, err := c.store.Load(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load cached candlesticks: %w", err)
+	}
+	fresh := slices.DeleteFunc(slices.Clone(entry.Ranges), func(r CandleRange) bool {
+		return c.ttl > 0 && now.Sub(r.FetchedAt) > c.ttl
+	})
+	missing := missingRanges(fresh, from, to)
+	// Candlesticks that start from the current period on may still be created, so the ranges
+	// from then on are never recorded as cached, even if no candlestick was returned for them.
+	current := currentCandleStart(key.Granularity, now)
+	for _, gap := range missing {
+		req := NewCandlesticksRequest(key.Instrument, key.Granularity).SetFrom(gap.From)
+		req.Price = key.Price
+		candles, err := c.client.Instrument.candlesRange(ctx, req, gap.To)
+		if err != nil {
+			return nil, err
+		}
+		covered := CandleRange{From: gap.From, To: minTime(gap.To, current), FetchedAt: now}
+		for _, candle := range candles {
+			if !candle.Complete && candle.Time.Time != nil {
+				covered.To = minTime(covered.To, *candle.Time.Time)
+				break
+			}
+		}
+		entry.Candles = mergeCandles(entry.Candles, candles)
+		entry.Ranges = addCandleRange(entry.Ranges, covered)
+	}
+	if len(missing) != 0 {
+		if err := c.store.Save(ctx, key, entry); err != nil {
+			return nil, fmt.Errorf("failed to save cached candlesticks: %w", err)
+		}
+	}
+	var candles []Candlestick
+	for _, candle := range entry.Candles {
+		if candle.Time.Time != nil && !candle.Time.Before(from) && candle.Time.Before(to) {
+			candles = append(candles, candle)
+		}
+	}
+	return candles, nil
+}
+
+// currentCandleStart returns the start of the candlestick of granularity g that contains now,
+// with the default alignment of the API. Without the time zone database, it returns a time one
+// candlestick length before now, which is no later than that start.
+func currentCandleStart(g CandlestickGranularity, now time.Time) time.Time {
+	if alignment, err := DefaultCandleAlignment(); err == nil {
+		if start, err := alignment.Start(g, now); err == nil {
+			return start
+		}
+	}
+	if d, err := g.Duration(); err == nil {
+		return now.Add(-d)
+	}
+	return now.AddDate(0, -1, 0)
+}
+
+// missingRanges returns the parts of [from, to) not covered by ranges.
+func missingRanges(ranges []CandleRange, from, to time.Time) []CandleRange {
+	ranges = slices.Clone(ranges)
+	slices.SortFunc(ranges, func(a, b CandleRange) int { return a.From.Compare(b.From) })
+	var missing []CandleRange
+	cursor := from
+	for _, r := range ranges {
+		if !cursor.Before(to) {
+			break
+		}
+		if !r.To.After(cursor) {
+			continue
+		}
+		if r.From.After(cursor) {
+			missing = append(missing, CandleRange{From: cursor, To: minTime(r.From, to)})
+		}
+		cursor = r.To
+	}
+	if cursor.Before(to) {
+		missing = append(missing, CandleRange{From: cursor, To: to})
+	}
+	return missing
+}
+
+// addCandleRange adds r to ranges, replacing the parts of other ranges it overlaps.
+func addCandleRange(ranges []CandleRange, r CandleRange) []CandleRange {
+	if !r.From.Before(r.To) {
+		return ranges
+	}
+	var result []CandleRange
+	for _, old := range ranges {
+		if old.From.Before(r.From) {
+			result = append(result, CandleRange{From: old.From, To: minTime(old.To, r.From), FetchedAt: old.FetchedAt})
+		}
+		if old.To.After(r.To) {
+			result = append(result, CandleRange{From: maxTime(old.From, r.To), To: old.To, FetchedAt: old.FetchedAt})
+		}
+	}
+	result = append(result, r)
+	slices.SortFunc(result, func(a, b CandleRange) int { return a.From.Compare(b.From) })
+	return result
+}
+
+// mergeCandles merges the candlesticks of fetched into candles, both in time order, replacing
+// the candlesticks with the same time.
+func mergeCandles(candles, fetched []Candlestick) []Candlestick {
+	byTime := make(map[time.Time]Candlestick, len(candles)+len(fetched))
+	for _, candle := range slices.Concat(candles, fetched) {
+		if candle.Time.Time != nil {
+			byTime[candle.Time.UTC()] = candle
+		}
+	}
+	merged := make([]Candlestick, 0, len(byTime))
+	for _, candle := range byTime {
+		merged = append(merged, candle)
+	}
+	slices.SortFunc(merged, func(a, b Candlestick) int { return a.Time.Compare(*b.Time.Time) })
+	return merged
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+// MemoryCandleStore is a [CandleStore] that keeps entries in memory. Create one with
+// [NewMemoryCandleStore].
+type MemoryCandleStore struct {
+	mu      sync.Mutex
+	entries map[CandleCacheKey]CandleCacheEntry
+}
+
+// NewMemoryCandleStore creates a new, empty MemoryCandleStore.
+func NewMemoryCandleStore() *MemoryCandleStore {
+	return &MemoryCandleStore{entries: make(map[CandleCacheKey]CandleCacheEntry)}
+}
+
+// Load returns the entry stored for key.
+func (s *MemoryCandleStore) Load(_ context.Context, key CandleCacheKey) (CandleCacheEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry := s.entries[key]
+	return CandleCacheEntry{Ranges: slices.Clone(entry.Ranges), Candles: slices.Clone(entry.Candles)}, nil
+}
+
+// Save stores entry for key.
+func (s *MemoryCandleStore) Save(_ context.Context, key CandleCacheKey, entry CandleCacheEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[key] = entry
+	return nil
+}
+
+// FileCandleStore is a [CandleStore] that keeps each entry in a JSON file in a directory, named
+// after the instrument, granularity and price components. Create one with
+// [NewFileCandleStore].
+type FileCandleStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileCandleStore creates a new FileCandleStore backed by the directory dir, which is created
+// on the first Save.
+func NewFileCandleStore(dir string) *FileCandleStore {
+	return &FileCandleStore{dir: dir}
+}
+
+// Load reads the entry stored for key. A missing file is an empty entry.
+func (s *FileCandleStore) Load(_ context.Context, key CandleCacheKey) (CandleCacheEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var entry CandleCacheEntry
+	b, err := os.ReadFile(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return entry, nil
+	}
+	if err != nil {
+		return entry, err
+	}
+	if err := json.Unmarshal(b, &entry); err != nil {
+		return CandleCacheEntry{}, fmt.Errorf("failed to decode %s: %w", s.path(key), err)
+	}
+	return entry, nil
+}
+
+// Save writes entry to a temporary file that then replaces the entry's file, so that a crash
+// during Save leaves either the previous or the new entry.
+func (s *FileCandleStore) Save(_ context.Context, key CandleCacheKey, entry CandleCacheEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, err := json.Marshal(&entry)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return err
+	}
+	path := s.path(key)
+	f, err := os.CreateTemp(s.dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
+
+func (s *FileCandleStore) path(key CandleCacheKey) string {
+	price := key.Price.String()
+	if price == "" {
+		price = "M"
+	}
+	name := strings.Join([]string{key.Instrument, string(key.Granularity), price}, "-") + ".json"
+	return filepath.Join(s.dir, filepath.Base(name))
+}
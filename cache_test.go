@@ -0,0 +1,140 @@
+package oanda
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCandleCache(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	latest := base.Add(20 * time.Minute)
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/v3/instruments/EUR_USD/candles" || q.Get("granularity") != "M1" || q.Get("price") != "BA" {
+			t.Errorf("unexpected request %s?%s", r.URL.Path, r.URL.RawQuery)
+		}
+		from, _ := time.Parse(time.RFC3339, q.Get("from"))
+		requests = append(requests, from.Format("15:04"))
+		var candles []string
+		for at := from; at.Before(latest); at = at.Add(time.Minute) {
+			candles = append(candles, fmt.Sprintf(`{"time":%q,"bid":{"c":"1.1"},"ask":{"c":"1.2"},"complete":%t}`,
+				at.Format(time.RFC3339), at.Add(time.Minute).Before(latest)))
+		}
+		_, _ = fmt.Fprintf(w, `{"instrument":"EUR_USD","granularity":"M1","candles":[%s]}`, strings.Join(candles, ","))
+	}))
+	defer server.Close()
+	client := NewClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+	dir := filepath.Join(t.TempDir(), "candles")
+	key := CandleCacheKey{Instrument: "EUR_USD", Granularity: M1, Price: PriceComponentBid | PriceComponentAsk}
+
+	get := func(cache *CandleCache, from, to time.Duration) string {
+		t.Helper()
+		candles, err := cache.Candles(t.Context(), key, base.Add(from), base.Add(to))
+		if err != nil {
+			t.Fatalf("failed to get candles: %v", err)
+		}
+		if len(candles) == 0 || candles[0].Ask.C != "1.2" {
+			t.Fatalf("got candles %+v", candles)
+		}
+		return fmt.Sprintf("%s-%s", candles[0].Time.Format("15:04"), candles[len(candles)-1].Time.Format("15:04"))
+	}
+	cache := NewCandleCache(client, NewFileCandleStore(dir))
+	for _, test := range []struct {
+		from, to time.Duration
+		want     string
+		requests string
+	}{
+		{0, 10 * time.Minute, "00:00-00:09", "00:00"},
+		{5 * time.Minute, 15 * time.Minute, "00:05-00:14", "00:00,00:10"},
+		{0, 15 * time.Minute, "00:00-00:14", "00:00,00:10"},
+		// The incomplete candlestick at 00:19 is fetched again until it is complete.
+		{15 * time.Minute, 30 * time.Minute, "00:15-00:19", "00:00,00:10,00:15"},
+		{12 * time.Minute, 30 * time.Minute, "00:12-00:19", "00:00,00:10,00:15,00:19"},
+	} {
+		if got := get(cache, test.from, test.to); got != test.want {
+			t.Errorf("%v-%v: got candles %s, want %s", test.from, test.to, got, test.want)
+		}
+		if got := strings.Join(requests, ","); got != test.requests {
+			t.Errorf("%v-%v: got requests from %s, want %s", test.from, test.to, got, test.requests)
+		}
+	}
+
+	// A new cache on the same directory serves the stored candlesticks.
+	requests = nil
+	if got := get(NewCandleCache(client, NewFileCandleStore(dir)), 0, 19*time.Minute); got != "00:00-00:18" || len(requests) != 0 {
+		t.Errorf("got candles %s with requests %v, want 00:00-00:18 without requests", got, requests)
+	}
+	// Expired ranges are fetched again.
+	if get(NewCandleCache(client, NewFileCandleStore(dir)).SetTTL(time.Nanosecond), 0, 5*time.Minute); len(requests) != 1 {
+		t.Errorf("got requests %v, want 1 after expiry", requests)
+	}
+
+	if _, err := cache.Candles(t.Context(), key, base, base); err == nil {
+		t.Error("got no error for an empty range")
+	}
+}
+
+func TestMissingRanges(t *testing.T) {
+	at := func(minute int) time.Time { return time.Date(2025, 1, 1, 0, minute, 0, 0, time.UTC) }
+	ranges := []CandleRange{{From: at(20), To: at(30)}, {From: at(0), To: at(10)}}
+	var got []string
+	for _, r := range missingRanges(ranges, at(5), at(40)) {
+		got = append(got, r.From.Format("04")+"-"+r.To.Format("04"))
+	}
+	if strings.Join(got, ",") != "10-20,30-40" {
+		t.Errorf("got missing ranges %v, want 10-20 and 30-40", got)
+	}
+	merged := addCandleRange(ranges, CandleRange{From: at(5), To: at(25)})
+	got = nil
+	for _, r := range merged {
+		got = append(got, r.From.Format("04")+"-"+r.To.Format("04"))
+	}
+	if strings.Join(got, ",") != "00-05,05-25,25-30" {
+		t.Errorf("got ranges %v, want 00-05, 05-25 and 25-30", got)
+	}
+}
+
+func TestCandleCache_Future(t *testing.T) {
+	start := time.Now().UTC().Truncate(time.Minute)
+	latest := start
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		from, _ := time.Parse(time.RFC3339, r.URL.Query().Get("from"))
+		// A quiet market returns complete candlesticks only.
+		var candles []string
+		for at := from; at.Before(latest); at = at.Add(time.Minute) {
+			candles = append(candles, fmt.Sprintf(`{"time":%q,"mid":{"c":"1.1"},"complete":true}`, at.Format(time.RFC3339)))
+		}
+		_, _ = fmt.Fprintf(w, `{"instrument":"EUR_USD","granularity":"M1","candles":[%s]}`, strings.Join(candles, ","))
+	}))
+	defer server.Close()
+	client := NewClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+	cache := NewCandleCache(client, NewMemoryCandleStore())
+	key := CandleCacheKey{Instrument: "EUR_USD", Granularity: M1}
+
+	from, to := start.Add(-10*time.Minute), start.Add(50*time.Minute)
+	candles, err := cache.Candles(t.Context(), key, from, to)
+	if err != nil || len(candles) != 10 {
+		t.Fatalf("got %d candles (%v), want 10", len(candles), err)
+	}
+	// The range from the current minute on is fetched again, and returns the new candlesticks.
+	latest = start.Add(10 * time.Minute)
+	candles, err = cache.Candles(t.Context(), key, from, to)
+	if err != nil || len(candles) != 20 {
+		t.Fatalf("got %d candles (%v), want 20", len(candles), err)
+	}
+	if requests != 2 {
+		t.Errorf("got %d requests, want 2", requests)
+	}
+	// The past range stays cached.
+	if _, err := cache.Candles(t.Context(), key, from, start.Add(-time.Minute)); err != nil || requests != 2 {
+		t.Errorf("got %d requests (%v), want the past range served from the cache", requests, err)
+	}
+}
@@ -0,0 +1,220 @@
+package oanda
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// MarketHours are the weekly trading hours of an Instrument, in the time of America/New_York
+// that OANDA's trading day follows. The market is closed from CloseDay at Close until OpenDay at
+// Open, and every day for DailyBreak from Close.
+type MarketHours struct {
+	// OpenDay is the day the market opens for the week.
+	OpenDay time.Weekday
+	// Open is the time of day the market opens for the week, as the time since midnight.
+	Open time.Duration
+	// CloseDay is the day the market closes for the week.
+	CloseDay time.Weekday
+	// Close is the time of day the market closes for the week, and the start of the daily
+	// break, as the time since midnight.
+	Close time.Duration
+	// DailyBreak is the length of the daily break, or 0 if the market trades through the day
+	// change.
+	DailyBreak time.Duration
+}
+
+// MarketHoursFor returns OANDA's usual trading hours for Instruments of instrumentType. Currency
+// pairs trade from Sunday 17:00 to Friday 17:00; metals and CFDs trade the same week with a daily
+// break from 17:00 to 18:00. The hours of individual CFDs vary; set them with
+// [MarketCalendar.SetHours] where they matter.
+func MarketHoursFor(instrumentType InstrumentType) MarketHours {
+	hours := MarketHours{OpenDay: time.Sunday, Open: 17 * time.Hour, CloseDay: time.Friday, Close: 17 * time.Hour}
+	if instrumentType != InstrumentTypeCurrency {
+		hours.Open += time.Hour
+		hours.DailyBreak = time.Hour
+	}
+	return hours
+}
+
+// Session is a regional trading session.
+type Session string
+
+const (
+	// SessionAsia is the Asian session, from 09:00 to 18:00 in Asia/Tokyo.
+	SessionAsia Session = "ASIA"
+	// SessionLondon is the London session, from 08:00 to 17:00 in Europe/London.
+	SessionLondon Session = "LONDON"
+	// SessionNewYork is the New York session, from 08:00 to 17:00 in America/New_York.
+	SessionNewYork Session = "NEW_YORK"
+)
+
+type sessionHours struct {
+	session     Session
+	location    *time.Location
+	open, close time.Duration
+}
+
+// MarketCalendar answers whether the market of an Instrument is open and when it opens or closes
+// next, such as to schedule jobs or to compute the expiry of a good-for-day Order. Instruments
+// follow the currency pair hours of [MarketHoursFor] unless other hours are set. Holidays are
+// added with [MarketCalendar.AddClosure]. Create one with [NewMarketCalendar].
+type MarketCalendar struct {
+	location *time.Location
+	sessions []sessionHours
+	hours    map[InstrumentName]MarketHours
+	closures []marketClosure
+}
+
+type marketClosure struct {
+	from, to time.Time
+}
+
+// NewMarketCalendar creates a new MarketCalendar. It returns an error if the time zones of the
+// market and the sessions cannot be loaded.
+func NewMarketCalendar() (*MarketCalendar, error) {
+	c := &MarketCalendar{hours: make(map[InstrumentName]MarketHours)}
+	locations := make(map[string]*time.Location)
+	for _, name := range []string{"America/New_York", "Asia/Tokyo", "Europe/London"} {
+		location, err := time.LoadLocation(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load timezone %s: %w", name, err)
+		}
+		locations[name] = location
+	}
+	c.location = locations["America/New_York"]
+	c.sessions = []sessionHours{
+		{SessionAsia, locations["Asia/Tokyo"], 9 * time.Hour, 18 * time.Hour},
+		{SessionLondon, locations["Europe/London"], 8 * time.Hour, 17 * time.Hour},
+		{SessionNewYork, locations["America/New_York"], 8 * time.Hour, 17 * time.Hour},
+	}
+	return c, nil
+}
+
+// SetHours sets the trading hours of instrument.
+func (c *MarketCalendar) SetHours(instrument InstrumentName, hours MarketHours) *MarketCalendar {
+	c.hours[instrument] = hours
+	return c
+}
+
+// SetInstruments sets the trading hours of instruments to those of their type, as returned by
+// [MarketHoursFor].
+func (c *MarketCalendar) SetInstruments(instruments ...Instrument) *MarketCalendar {
+	for _, instrument := range instruments {
+		c.hours[instrument.Name] = MarketHoursFor(instrument.Type)
+	}
+	return c
+}
+
+// AddClosure closes the market of all Instruments within [from, to), such as for a holiday.
+func (c *MarketCalendar) AddClosure(from, to time.Time) *MarketCalendar {
+	c.closures = append(c.closures, marketClosure{from, to})
+	slices.SortFunc(c.closures, func(a, b marketClosure) int { return a.from.Compare(b.from) })
+	return c
+}
+
+// IsMarketOpen reports whether the market of instrument is open at t.
+func (c *MarketCalendar) IsMarketOpen(instrument InstrumentName, t time.Time) bool {
+	for _, closure := range c.closures {
+		if !t.Before(closure.from) && t.Before(closure.to) {
+			return false
+		}
+	}
+	hours := c.hoursOf(instrument)
+	local := t.In(c.location)
+	clock := sinceMidnight(local)
+	if hours.DailyBreak > 0 && clock >= hours.Close && clock < hours.Close+hours.DailyBreak {
+		return false
+	}
+	const day = 24 * time.Hour
+	at := time.Duration(local.Weekday())*day + clock
+	closed := time.Duration(hours.CloseDay)*day + hours.Close
+	open := time.Duration(hours.OpenDay)*day + hours.Open
+	if closed <= open {
+		return at < closed || at >= open
+	}
+	return at >= open && at < closed
+}
+
+// NextOpen returns t if the market of instrument is open at t, and otherwise the time it opens
+// next. It returns the zero time if the market does not open within a week of t, or of the end
+// of the closures that follow it.
+func (c *MarketCalendar) NextOpen(instrument InstrumentName, t time.Time) time.Time {
+	return c.next(instrument, t, true)
+}
+
+// NextClose returns t if the market of instrument is closed at t, and otherwise the time it
+// closes next. It returns the zero time if the market does not close within a week of t.
+func (c *MarketCalendar) NextClose(instrument InstrumentName, t time.Time) time.Time {
+	return c.next(instrument, t, false)
+}
+
+// Sessions returns the regional sessions in progress at t, from Monday to Friday in the time
+// zone of each session, in the order Asia, London and New York.
+func (c *MarketCalendar) Sessions(t time.Time) []Session {
+	var sessions []Session
+	for _, s := range c.sessions {
+		local := t.In(s.location)
+		if local.Weekday() == time.Saturday || local.Weekday() == time.Sunday {
+			continue
+		}
+		if clock := sinceMidnight(local); clock >= s.open && clock < s.close {
+			sessions = append(sessions, s.session)
+		}
+	}
+	return sessions
+}
+
+func (c *MarketCalendar) hoursOf(instrument InstrumentName) MarketHours {
+	if hours, ok := c.hours[instrument]; ok {
+		return hours
+	}
+	return MarketHoursFor(InstrumentTypeCurrency)
+}
+
+// next returns the first time at or after t at which the market of instrument is open, or closed
+// if open is false. The state only changes at the daily open and close times and at the bounds of
+// the closures, so only those times are checked.
+func (c *MarketCalendar) next(instrument InstrumentName, t time.Time, open bool) time.Time {
+	if c.IsMarketOpen(instrument, t) == open {
+		return t
+	}
+	hours := c.hoursOf(instrument)
+	local := t.In(c.location)
+	// Closures that overlap the week scanned extend it to the week after their end.
+	last := local.AddDate(0, 0, 8)
+	var candidates []time.Time
+	for _, closure := range c.closures {
+		if closure.to.After(t) && closure.from.Before(last) {
+			candidates = append(candidates, closure.from, closure.to)
+			last = maxTime(last, closure.to.In(c.location).AddDate(0, 0, 8))
+		}
+	}
+	for day := local; !day.After(last); day = day.AddDate(0, 0, 1) {
+		for _, clock := range []time.Duration{hours.Open, hours.Close, hours.Close + hours.DailyBreak} {
+			candidates = append(candidates, atClock(day, clock))
+		}
+	}
+	slices.SortFunc(candidates, func(a, b time.Time) int { return a.Compare(b) })
+	for _, candidate := range candidates {
+		if candidate.After(t) && c.IsMarketOpen(instrument, candidate) == open {
+			return candidate
+		}
+	}
+	return time.Time{}
+}
+
+// sinceMidnight returns the wall clock time of t as the time since midnight.
+func sinceMidnight(t time.Time) time.Duration {
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second + time.Duration(t.Nanosecond())
+}
+
+// atClock returns the time on the day of t, in its location, at the wall clock time clock since
+// midnight, which may be 24 hours or more to refer to a following day.
+func atClock(t time.Time, clock time.Duration) time.Time {
+	days := int(clock / (24 * time.Hour))
+	clock %= 24 * time.Hour
+	return time.Date(t.Year(), t.Month(), t.Day()+days, int(clock/time.Hour), int(clock%time.Hour/time.Minute),
+		int(clock%time.Minute/time.Second), int(clock%time.Second), t.Location())
+}
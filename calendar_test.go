@@ -0,0 +1,81 @@
+package oanda
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestMarketCalendar(t *testing.T) {
+	calendar, err := NewMarketCalendar()
+	if err != nil {
+		t.Fatal(err)
+	}
+	newYork, _ := time.LoadLocation("America/New_York")
+	at := func(day, hour, minute int) time.Time { return time.Date(2024, 3, day, hour, minute, 0, 0, newYork) }
+	calendar.SetInstruments(Instrument{Name: "XAU_USD", Type: InstrumentTypeMetal})
+
+	// 2024-03-08 is a Friday and 2024-03-10, when New York switches to daylight saving time, a Sunday.
+	for _, tt := range []struct {
+		instrument InstrumentName
+		t          time.Time
+		want       bool
+	}{
+		{"EUR_USD", at(8, 16, 59), true},
+		{"EUR_USD", at(8, 17, 0), false},
+		{"EUR_USD", at(9, 12, 0), false},
+		{"EUR_USD", at(10, 17, 0), true},
+		{"EUR_USD", at(12, 17, 30), true},
+		{"XAU_USD", at(10, 17, 30), false},
+		{"XAU_USD", at(10, 18, 0), true},
+		{"XAU_USD", at(12, 17, 30), false},
+		{"XAU_USD", at(12, 18, 30), true},
+	} {
+		if got := calendar.IsMarketOpen(tt.instrument, tt.t); got != tt.want {
+			t.Errorf("IsMarketOpen(%s, %s) = %t, want %t", tt.instrument, tt.t, got, tt.want)
+		}
+	}
+
+	if got := calendar.NextOpen("EUR_USD", at(9, 12, 0)); !got.Equal(at(10, 17, 0)) {
+		t.Errorf("got next open %s", got)
+	}
+	if got := calendar.NextOpen("EUR_USD", at(11, 3, 0)); !got.Equal(at(11, 3, 0)) {
+		t.Errorf("got next open %s while open", got)
+	}
+	if got := calendar.NextClose("EUR_USD", at(11, 3, 0)); !got.Equal(at(15, 17, 0)) {
+		t.Errorf("got next close %s", got)
+	}
+	if got := calendar.NextClose("XAU_USD", at(11, 3, 0)); !got.Equal(at(11, 17, 0)) {
+		t.Errorf("got next close %s, want the daily break", got)
+	}
+	if got := calendar.NextOpen("XAU_USD", at(11, 17, 15)); !got.Equal(at(11, 18, 0)) {
+		t.Errorf("got next open %s, want the end of the daily break", got)
+	}
+
+	// A closure for a holiday from Monday to Wednesday 17:00.
+	calendar.AddClosure(at(11, 17, 0), at(13, 17, 0))
+	if calendar.IsMarketOpen("EUR_USD", at(12, 12, 0)) {
+		t.Error("got open market during a closure")
+	}
+	if got := calendar.NextClose("EUR_USD", at(11, 3, 0)); !got.Equal(at(11, 17, 0)) {
+		t.Errorf("got next close %s, want the start of the closure", got)
+	}
+	if got := calendar.NextOpen("EUR_USD", at(12, 12, 0)); !got.Equal(at(13, 17, 0)) {
+		t.Errorf("got next open %s, want the end of the closure", got)
+	}
+
+	for _, tt := range []struct {
+		t    time.Time
+		want []Session
+	}{
+		{time.Date(2024, 3, 12, 1, 0, 0, 0, time.UTC), []Session{SessionAsia}},
+		{time.Date(2024, 3, 12, 8, 30, 0, 0, time.UTC), []Session{SessionAsia, SessionLondon}},
+		{time.Date(2024, 3, 12, 14, 0, 0, 0, time.UTC), []Session{SessionLondon, SessionNewYork}},
+		{time.Date(2024, 3, 12, 22, 0, 0, 0, time.UTC), nil},
+		{time.Date(2024, 3, 9, 12, 0, 0, 0, time.UTC), nil},
+	} {
+		if got := calendar.Sessions(tt.t); !slices.Equal(got, tt.want) {
+			t.Errorf("Sessions(%s) = %v, want %v", tt.t, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package oanda
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// CandleColumn is a column of a candlestick export, such as the CSV written by a
+// [CandleCSVWriter].
+type CandleColumn struct {
+	// Header is the name of the column.
+	Header string
+	// Value returns the value of the column for a candlestick.
+	Value func(Candlestick) string
+}
+
+// Columns for candlestick exports. Times are written in RFC 3339 format in UTC.
+var (
+	CandleColumnTime = CandleColumn{"time", func(c Candlestick) string {
+		if c.Time.Time == nil {
+			return ""
+		}
+		return c.Time.UTC().Format(time.RFC3339Nano)
+	}}
+	CandleColumnVolume = CandleColumn{"volume", func(c Candlestick) string {
+		return strconv.Itoa(c.Volume)
+	}}
+	CandleColumnComplete = CandleColumn{"complete", func(c Candlestick) string {
+		return strconv.FormatBool(c.Complete)
+	}}
+)
+
+// candleComponents are the price components of a candlestick with the names of their columns,
+// in the order bid, ask and mid.
+var candleComponents = []struct {
+	component PriceComponents
+	name      string
+}{
+	{PriceComponentBid, "bid"},
+	{PriceComponentAsk, "ask"},
+	{PriceComponentMid, "mid"},
+}
+
+// CandlePriceColumns returns the open, high, low and close columns of the price components of
+// price, in the order bid, ask and mid. The columns are named after the component and the price,
+// such as "bidOpen" and "midClose", and written exactly as OANDA reports them, or empty if a
+// candlestick does not have the component.
+func CandlePriceColumns(price PriceComponents) []CandleColumn {
+	var columns []CandleColumn
+	for _, component := range candleComponents {
+		if !price.Has(component.component) {
+			continue
+		}
+		for _, p := range []struct {
+			name  string
+			value func(CandlestickData) PriceValue
+		}{
+			{"Open", func(d CandlestickData) PriceValue { return d.O }},
+			{"High", func(d CandlestickData) PriceValue { return d.H }},
+			{"Low", func(d CandlestickData) PriceValue { return d.L }},
+			{"Close", func(d CandlestickData) PriceValue { return d.C }},
+		} {
+			value := p.value
+			columns = append(columns, CandleColumn{component.name + p.name, func(c Candlestick) string {
+				data := c.Data(component.component)
+				if data == nil {
+					return ""
+				}
+				return string(value(*data))
+			}})
+		}
+	}
+	return columns
+}
+
+// DefaultCandleColumns returns the columns a [CandleCSVWriter] writes when none are given: the
+// time, the prices of the components of price, the volume and the complete flag.
+func DefaultCandleColumns(price PriceComponents) []CandleColumn {
+	columns := []CandleColumn{CandleColumnTime}
+	columns = append(columns, CandlePriceColumns(price)...)
+	return append(columns, CandleColumnVolume, CandleColumnComplete)
+}
+
+// CandleCSVWriter writes candlesticks as CSV, one row per candlestick below a header row. Write
+// the Candlestick of each [LiveCandle] to export aggregated candlesticks. Create one with
+// [NewCandleCSVWriter].
+type CandleCSVWriter struct {
+	w             *csv.Writer
+	columns       []CandleColumn
+	headerWritten bool
+}
+
+// NewCandleCSVWriter creates a new CandleCSVWriter writing the given columns to w, or the
+// [DefaultCandleColumns] of mid prices if none are given.
+func NewCandleCSVWriter(w io.Writer, columns ...CandleColumn) *CandleCSVWriter {
+	if len(columns) == 0 {
+		columns = DefaultCandleColumns(PriceComponentMid)
+	}
+	return &CandleCSVWriter{w: csv.NewWriter(w), columns: columns}
+}
+
+func (w *CandleCSVWriter) writeHeader() error {
+	if w.headerWritten {
+		return nil
+	}
+	w.headerWritten = true
+	header := make([]string, len(w.columns))
+	for i, column := range w.columns {
+		header[i] = column.Header
+	}
+	return w.w.Write(header)
+}
+
+// Write writes a row for candle, preceded by the header row if it is the first one. Rows are
+// buffered; call Flush when done.
+func (w *CandleCSVWriter) Write(candle Candlestick) error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	row := make([]string, len(w.columns))
+	for i, column := range w.columns {
+		row[i] = column.Value(candle)
+	}
+	return w.w.Write(row)
+}
+
+// WriteAll writes a row for every candlestick of candles and flushes the writer.
+func (w *CandleCSVWriter) WriteAll(candles []Candlestick) error {
+	for _, candle := range candles {
+		if err := w.Write(candle); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// Flush writes any buffered rows, and the header row if no candlestick has been written.
+func (w *CandleCSVWriter) Flush() error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	w.w.Flush()
+	return w.w.Error()
+}
+
+// CandleRecord is a flat, typed row of a candlestick, suitable for columnar formats. Prices of
+// components that were not requested are NaN, which pandas treats as missing. The parquet tags
+// give the column names of [CandleParquetSchema] and of the files written by a
+// [CandleParquetWriter].
+type CandleRecord struct {
+	Time     time.Time `parquet:"time"`
+	BidOpen  float64   `parquet:"bidOpen"`
+	BidHigh  float64   `parquet:"bidHigh"`
+	BidLow   float64   `parquet:"bidLow"`
+	BidClose float64   `parquet:"bidClose"`
+	AskOpen  float64   `parquet:"askOpen"`
+	AskHigh  float64   `parquet:"askHigh"`
+	AskLow   float64   `parquet:"askLow"`
+	AskClose float64   `parquet:"askClose"`
+	MidOpen  float64   `parquet:"midOpen"`
+	MidHigh  float64   `parquet:"midHigh"`
+	MidLow   float64   `parquet:"midLow"`
+	MidClose float64   `parquet:"midClose"`
+	Volume   int64     `parquet:"volume"`
+	Complete bool      `parquet:"complete"`
+}
+
+// CandleRecords converts candles into CandleRecords. It returns an error if a price cannot be
+// parsed.
+func CandleRecords(candles []Candlestick) ([]CandleRecord, error) {
+	records := make([]CandleRecord, len(candles))
+	for i, candle := range candles {
+		record := &records[i]
+		if candle.Time.Time != nil {
+			record.Time = candle.Time.UTC()
+		}
+		record.Volume = int64(candle.Volume)
+		record.Complete = candle.Complete
+		for _, v := range []struct {
+			dst  [4]*float64
+			data *CandlestickData
+		}{
+			{[4]*float64{&record.BidOpen, &record.BidHigh, &record.BidLow, &record.BidClose}, candle.Bid},
+			{[4]*float64{&record.AskOpen, &record.AskHigh, &record.AskLow, &record.AskClose}, candle.Ask},
+			{[4]*float64{&record.MidOpen, &record.MidHigh, &record.MidLow, &record.MidClose}, candle.Mid},
+		} {
+			if v.data == nil {
+				for _, dst := range v.dst {
+					*dst = math.NaN()
+				}
+				continue
+			}
+			for j, price := range []PriceValue{v.data.O, v.data.H, v.data.L, v.data.C} {
+				if price == "" {
+					*v.dst[j] = math.NaN()
+					continue
+				}
+				f, err := price.Float64()
+				if err != nil {
+					return nil, fmt.Errorf("failed to convert candlestick %d: %w", i, err)
+				}
+				*v.dst[j] = f
+			}
+		}
+	}
+	return records, nil
+}
+
+// CandleParquetSchema returns the Parquet schema of [CandleRecord] in the message type notation
+// accepted by Parquet tooling, like [OrderFillParquetSchema], as written by a
+// [CandleParquetWriter].
+func CandleParquetSchema() string {
+	return parquetSchema(reflect.TypeFor[CandleRecord]())
+}
+
+// CandleParquetWriter writes candlesticks as a Parquet file with the schema of
+// [CandleParquetSchema], one row per candlestick converted as by [CandleRecords], like
+// [OrderFillParquetWriter]. Create one with [NewCandleParquetWriter].
+type CandleParquetWriter struct {
+	w *parquetWriter[CandleRecord]
+}
+
+// NewCandleParquetWriter creates a new CandleParquetWriter writing to w.
+func NewCandleParquetWriter(w io.Writer) *CandleParquetWriter {
+	return &CandleParquetWriter{w: newParquetWriter[CandleRecord](w)}
+}
+
+// Write writes a row for candle. Rows are buffered; call Close when done.
+func (w *CandleParquetWriter) Write(candle Candlestick) error {
+	records, err := CandleRecords([]Candlestick{candle})
+	if err != nil {
+		return err
+	}
+	return w.w.write(records[0])
+}
+
+// WriteAll writes a row for every candlestick of candles and closes the writer.
+func (w *CandleParquetWriter) WriteAll(candles []Candlestick) error {
+	for _, candle := range candles {
+		if err := w.Write(candle); err != nil {
+			return err
+		}
+	}
+	return w.Close()
+}
+
+// Close writes the buffered rows and the file footer. It does not close the underlying writer.
+// The file is not valid until Close has returned.
+func (w *CandleParquetWriter) Close() error {
+	return w.w.close()
+}
@@ -0,0 +1,79 @@
+package oanda
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestCandleCSVWriter(t *testing.T) {
+	var candles []Candlestick
+	err := json.Unmarshal([]byte(`[
+		{"time":"2024-01-02T03:00:00.000000000Z","volume":12,"complete":true,
+			"bid":{"o":"1.10000","h":"1.10050","l":"1.09990","c":"1.10020"},
+			"ask":{"o":"1.10010","h":"1.10060","l":"1.10000","c":"1.10030"}},
+		{"time":"2024-01-02T04:00:00.000000000Z","volume":3,"complete":false,
+			"bid":{"o":"1.10020","h":"1.10020","l":"1.10010","c":"1.10010"},
+			"ask":{"o":"1.10030","h":"1.10030","l":"1.10020","c":"1.10020"}}]`), &candles)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf strings.Builder
+	if err := NewCandleCSVWriter(&buf, DefaultCandleColumns(PriceComponentBid|PriceComponentAsk)...).WriteAll(candles); err != nil {
+		t.Fatalf("failed to write candlesticks: %v", err)
+	}
+	want := `time,bidOpen,bidHigh,bidLow,bidClose,askOpen,askHigh,askLow,askClose,volume,complete
+2024-01-02T03:00:00Z,1.10000,1.10050,1.09990,1.10020,1.10010,1.10060,1.10000,1.10030,12,true
+2024-01-02T04:00:00Z,1.10020,1.10020,1.10010,1.10010,1.10030,1.10030,1.10020,1.10020,3,false
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got CSV\n%s\nwant\n%s", got, want)
+	}
+
+	buf.Reset()
+	w := NewCandleCSVWriter(&buf, CandleColumnTime, CandleColumnComplete)
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "time,complete\n"; got != want {
+		t.Errorf("got CSV %q without candlesticks, want %q", got, want)
+	}
+	if columns := NewCandleCSVWriter(&buf).columns; len(columns) != 7 || columns[1].Header != "midOpen" {
+		t.Errorf("got %d default columns", len(columns))
+	}
+
+	records, err := CandleRecords(candles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := records[0]
+	if r.BidHigh != 1.1005 || r.AskClose != 1.1003 || !math.IsNaN(r.MidOpen) || r.Volume != 12 || !r.Complete ||
+		r.Time != *candles[0].Time.Time {
+		t.Errorf("got record %+v", r)
+	}
+	candles[1].Bid.O = "x"
+	if _, err := CandleRecords(candles); err == nil {
+		t.Error("got no error for an invalid price")
+	}
+	schema := CandleParquetSchema()
+	for _, line := range []string{"message CandleRecord {", "required double midClose;", "required int64 volume;", "required boolean complete;"} {
+		if !strings.Contains(schema, line) {
+			t.Errorf("schema %s does not contain %q", schema, line)
+		}
+	}
+
+	var file bytes.Buffer
+	if err := NewCandleParquetWriter(&file).WriteAll(candles[:1]); err != nil {
+		t.Fatalf("failed to write parquet file: %v", err)
+	}
+	if !bytes.HasPrefix(file.Bytes(), []byte("PAR1")) || !bytes.HasSuffix(file.Bytes(), []byte("PAR1")) ||
+		!bytes.Contains(file.Bytes(), []byte("midClose")) {
+		t.Errorf("got parquet file %q", file.Bytes())
+	}
+	if err := NewCandleParquetWriter(&file).WriteAll(candles); err == nil {
+		t.Error("got no error for an invalid price")
+	}
+}
@@ -0,0 +1,82 @@
+package oanda
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint")
+	for _, checkpoint := range []Checkpoint{&MemoryCheckpoint{}, NewFileCheckpoint(path)} {
+		if position, err := checkpoint.Load(t.Context()); err != nil || position != "" {
+			t.Fatalf("%T: got position %q (%v), want none", checkpoint, position, err)
+		}
+		for _, position := range []string{"10", "12"} {
+			if err := checkpoint.Save(t.Context(), position); err != nil {
+				t.Fatalf("%T: failed to save: %v", checkpoint, err)
+			}
+		}
+		if position, err := checkpoint.Load(t.Context()); err != nil || position != "12" {
+			t.Errorf("%T: got position %q (%v), want 12", checkpoint, position, err)
+		}
+	}
+	if position, err := NewFileCheckpoint(path).Load(t.Context()); err != nil || position != "12" {
+		t.Errorf("got reopened position %q (%v), want 12", position, err)
+	}
+
+	want := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+	if got, err := ParseCheckpointTime(FormatCheckpointTime(want.In(time.FixedZone("JST", 9*60*60)))); err != nil || !got.Equal(want) {
+		t.Errorf("got time %v (%v), want %v", got, err, want)
+	}
+	if got, err := ParseCheckpointTime(""); err != nil || !got.IsZero() {
+		t.Errorf("got time %v (%v), want zero", got, err)
+	}
+}
+
+func TestTransactionService_Resume(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		since, _ := strconv.Atoi(r.URL.Query().Get("id"))
+		// Return at most two Transactions per request, like a server-side cap.
+		var transactions []string
+		for id := since + 1; id <= min(since+2, 5); id++ {
+			transactions = append(transactions, fmt.Sprintf(`{"type":"ORDER_FILL","id":"%d"}`, id))
+		}
+		_, _ = fmt.Fprintf(w, `{"transactions":[%s],"lastTransactionID":"5"}`, strings.Join(transactions, ","))
+	}))
+	defer server.Close()
+	client := NewClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+	checkpoint := NewFileCheckpoint(filepath.Join(t.TempDir(), "checkpoint"))
+
+	var ids []TransactionID
+	crash := errors.New("crash")
+	n, err := client.Transaction.Resume(t.Context(), checkpoint, func(transaction Transaction) error {
+		if transaction.GetID() == "4" {
+			return crash
+		}
+		ids = append(ids, transaction.GetID())
+		return nil
+	})
+	if !errors.Is(err, crash) || n != 3 {
+		t.Fatalf("got %d handled (%v), want 3 and the handler's error", n, err)
+	}
+	n, err = client.Transaction.Resume(t.Context(), checkpoint, func(transaction Transaction) error {
+		ids = append(ids, transaction.GetID())
+		return nil
+	})
+	if err != nil || n != 2 {
+		t.Fatalf("got %d handled (%v), want 2", n, err)
+	}
+	if strings.Join(ids, ",") != "1,2,3,4,5" {
+		t.Errorf("got transactions %v, want 1 to 5 once each", ids)
+	}
+	if position, _ := checkpoint.Load(t.Context()); position != "5" {
+		t.Errorf("got checkpoint %q, want 5", position)
+	}
+}
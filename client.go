@@ -11,6 +11,8 @@ import (
 	"net/http"
 	"net/url"
 	"runtime"
+	"sync"
+	"time"
 )
 
 const (
@@ -48,6 +50,15 @@ type clientConfig struct {
 	userAgent  string
 	accountID  AccountID
 	httpClient HTTPClient
+	// cancelledOrderError makes orderService.Create return an OrderCancelledError for Orders
+	// that were cancelled without being filled.
+	cancelledOrderError bool
+	// orderPolicy is applied to the Orders created and replaced by orderService.
+	orderPolicy *OrderPolicy
+	// instrumentCacheTTL is the TTL of the InstrumentCache returned by Client.Instruments.
+	instrumentCacheTTL time.Duration
+	// limiter paces the requests of a Client.
+	limiter *rateLimiter
 }
 
 // Client is the OANDA v20 REST API client. Create one with [NewClient] (live)
@@ -62,6 +73,19 @@ type Client struct {
 	Position    *positionService
 	Transaction *transactionService
 	Price       *priceService
+
+	instrumentsOnce sync.Once
+	instruments     *InstrumentCache
+}
+
+// Instruments returns the client's shared [InstrumentCache], created on first use with the TTL
+// set by [WithInstrumentCacheTTL]. Pass it to the helpers that need Instrument metadata, such as
+// [NewOrderValidator] and [NewMarginChecker], so that they share a single copy of it.
+func (c *Client) Instruments() *InstrumentCache {
+	c.instrumentsOnce.Do(func() {
+		c.instruments = NewInstrumentCache(c).SetTTL(c.instrumentCacheTTL)
+	})
+	return c.instruments
 }
 
 // Option configures a [Client] or [StreamClient]. Pass options to
@@ -96,6 +120,26 @@ func WithHTTPClient(client *http.Client) Option {
 	}
 }
 
+// WithCancelledOrderError makes [orderService.Create] return an [OrderCancelledError] together
+// with the response when OANDA accepts an Order but cancels it immediately without filling it,
+// e.g. because of TAKE_PROFIT_ON_FILL_LOSS. By default such Orders are returned without an error
+// and can be detected with [OrderCreateResponse.WasCancelled]. It has no effect on a
+// [StreamClient].
+func WithCancelledOrderError() Option {
+	return func(c *clientConfig) {
+		c.cancelledOrderError = true
+	}
+}
+
+// WithInstrumentCacheTTL sets how long the Instrument metadata cached by [Client.Instruments] is
+// used before it is fetched again. By default it does not expire. It has no effect on a
+// [StreamClient].
+func WithInstrumentCacheTTL(ttl time.Duration) Option {
+	return func(c *clientConfig) {
+		c.instrumentCacheTTL = ttl
+	}
+}
+
 func defaultConfig(baseURL, apiKey string) clientConfig {
 	return clientConfig{
 		baseURL:    baseURL,
@@ -103,6 +147,7 @@ func defaultConfig(baseURL, apiKey string) clientConfig {
 		userAgent:  defaultUserAgent(),
 		accountID:  "",
 		httpClient: http.DefaultClient,
+		limiter:    newRateLimiter(DefaultRateLimit, DefaultRateLimitBurst),
 	}
 }
 
@@ -161,17 +206,40 @@ type Request interface {
 	body() (*bytes.Buffer, error)
 }
 
+// sendRequest sends a request once the rate limiter allows it, and sends it again while it is
+// answered with status 429, up to rateLimitRetries times.
 func (c *Client) sendRequest(ctx context.Context, method, path string, query url.Values, body io.Reader) (*http.Response, error) {
 	u, err := joinURL(c.baseURL, path, query)
 	if err != nil {
 		return nil, err
 	}
-	req, err := http.NewRequestWithContext(ctx, method, u, body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+	var payload []byte
+	if body != nil {
+		// The body is read once so that it can be sent again.
+		if payload, err = io.ReadAll(body); err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+	}
+	for retry := 0; ; retry++ {
+		if err := c.limiter.wait(ctx); err != nil {
+			return nil, err
+		}
+		var reqBody io.Reader
+		if body != nil {
+			reqBody = bytes.NewReader(payload)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, u, reqBody)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		c.setHeaders(req)
+		resp, err := c.httpClient.Do(req)
+		if err != nil || resp.StatusCode != http.StatusTooManyRequests || retry == rateLimitRetries {
+			return resp, err
+		}
+		c.limiter.block(retryDelay(resp, retry))
+		closeBody(resp)
 	}
-	c.setHeaders(req)
-	return c.httpClient.Do(req)
 }
 
 func (c *Client) sendGetRequest(ctx context.Context, path string, values url.Values) (*http.Response, error) {
@@ -294,10 +362,31 @@ func decodeErrorResponse(resp *http.Response) error {
 	return wrapHTTPError(resp.StatusCode, errors.New(errResp.Message))
 }
 
+// errStreamDone is returned internally when the done channel of a stream fires while an item
+// is being delivered. Stream methods translate it into a nil error via ignoreStreamDone.
+var errStreamDone = errors.New("stream done")
+
+func ignoreStreamDone(err error) error {
+	if errors.Is(err, errStreamDone) {
+		return nil
+	}
+	return err
+}
+
+// streamBufferPool holds the buffers streamLoop decodes raw messages into, so that
+// reconnecting or concurrently running streams do not each grow a new buffer.
+var streamBufferPool = sync.Pool{
+	New: func() any {
+		buf := make(json.RawMessage, 0, 1024)
+		return &buf
+	},
+}
+
 // streamLoop opens a streaming GET connection and decodes newline-delimited
 // JSON objects until done is closed, the context is cancelled, or the server
 // ends the stream. Each object is passed to parse; items it accepts are sent
-// to ch.
+// to ch. The raw message passed to parse is reused for the next object, so
+// parse must not retain it.
 func streamLoop[T any](
 	ctx context.Context,
 	c *StreamClient,
@@ -321,6 +410,12 @@ func streamLoop[T any](
 		return fmt.Errorf("failed to send GET request: %w", err)
 	}
 	defer closeBody(httpResp)
+	buf := streamBufferPool.Get().(*json.RawMessage)
+	raw := (*buf)[:0]
+	defer func() {
+		*buf = raw[:0]
+		streamBufferPool.Put(buf)
+	}()
 	dec := json.NewDecoder(httpResp.Body)
 	for {
 		select {
@@ -330,7 +425,7 @@ func streamLoop[T any](
 			return ctx.Err()
 		default:
 		}
-		var raw json.RawMessage
+		raw = raw[:0]
 		if err := dec.Decode(&raw); err != nil {
 			if errors.Is(err, io.EOF) {
 				return nil
@@ -0,0 +1,210 @@
+package oanda
+
+import (
+	"fmt"
+	"time"
+)
+
+// CandleAnomalyKind is the kind of a [CandleAnomaly].
+type CandleAnomalyKind string
+
+const (
+	// CandleAnomalyGap means candlesticks are missing for periods in which the market was open.
+	CandleAnomalyGap CandleAnomalyKind = "GAP"
+	// CandleAnomalyDuplicate means a candlestick has the same time as the previous one.
+	CandleAnomalyDuplicate CandleAnomalyKind = "DUPLICATE"
+	// CandleAnomalyOutOfOrder means a candlestick is earlier than the previous one.
+	CandleAnomalyOutOfOrder CandleAnomalyKind = "OUT_OF_ORDER"
+	// CandleAnomalyMisaligned means a candlestick does not start on a boundary of the
+	// granularity.
+	CandleAnomalyMisaligned CandleAnomalyKind = "MISALIGNED"
+	// CandleAnomalyIncomplete means a candlestick other than the last one is incomplete.
+	CandleAnomalyIncomplete CandleAnomalyKind = "INCOMPLETE"
+	// CandleAnomalyInvalidPrice means a candlestick has a price that cannot be parsed, or a high
+	// below its low or an open or close outside of them.
+	CandleAnomalyInvalidPrice CandleAnomalyKind = "INVALID_PRICE"
+)
+
+// CandleAnomaly is a problem found in a series of candlesticks by a [CandleValidator].
+type CandleAnomaly struct {
+	// Kind is the kind of the anomaly.
+	Kind CandleAnomalyKind
+	// Index is the index of the candlestick with the anomaly. For a gap, it is the index of the
+	// candlestick after the gap.
+	Index int
+	// Time is the time of the candlestick with the anomaly. For a gap, it is the start of the
+	// first missing period.
+	Time time.Time
+	// Missing is the number of missing periods of a gap.
+	Missing int
+	// Detail describes the anomaly.
+	Detail string
+}
+
+// CandleValidator checks a series of candlesticks for the anomalies listed by
+// [CandleAnomalyKind] before it is used, for example by a backtest. Periods in which the market
+// is closed are not reported as gaps; by default the market is taken to be closed from Friday
+// 17:00 to Sunday 17:00 in America/New_York, the weekly closure of the currency markets, which
+// can be changed with [CandleValidator.SetMarketClosed] for instruments with other hours. Create
+// one with [NewCandleValidator].
+type CandleValidator struct {
+	granularity CandlestickGranularity
+	alignment   *CandleAlignment
+	closed      func(time.Time) bool
+}
+
+// NewCandleValidator creates a new CandleValidator for candlesticks of granularity, aligned with
+// [DefaultCandleAlignment] unless [CandleValidator.SetAlignment] is called.
+func NewCandleValidator(granularity CandlestickGranularity) *CandleValidator {
+	return &CandleValidator{granularity: granularity}
+}
+
+// SetAlignment sets the alignment the candlesticks were requested with.
+func (v *CandleValidator) SetAlignment(alignment CandleAlignment) *CandleValidator {
+	v.alignment = &alignment
+	return v
+}
+
+// SetMarketClosed sets the function that reports whether the market is closed at a time. A
+// period is expected to have a candlestick unless the market is closed at both its start and
+// its end.
+func (v *CandleValidator) SetMarketClosed(closed func(time.Time) bool) *CandleValidator {
+	v.closed = closed
+	return v
+}
+
+// Validate returns the anomalies of candles, which should be in time order, in the order they
+// are found. Periods without prices during market hours are reported as gaps too; for short
+// granularities of quiet instruments, such gaps are expected.
+func (v *CandleValidator) Validate(candles []Candlestick) ([]CandleAnomaly, error) {
+	alignment := v.alignment
+	if alignment == nil {
+		a, err := DefaultCandleAlignment()
+		if err != nil {
+			return nil, err
+		}
+		alignment = &a
+	}
+	closed := v.closed
+	if closed == nil {
+		location, err := time.LoadLocation("America/New_York")
+		if err != nil {
+			return nil, fmt.Errorf("failed to load market timezone: %w", err)
+		}
+		closed = func(t time.Time) bool { return forexWeekend(t.In(location)) }
+	}
+	var anomalies []CandleAnomaly
+	var prev *time.Time
+	for i, candle := range candles {
+		if candle.Time.Time == nil {
+			continue
+		}
+		t := *candle.Time.Time
+		report := func(kind CandleAnomalyKind, format string, args ...any) {
+			anomalies = append(anomalies, CandleAnomaly{Kind: kind, Index: i, Time: t, Detail: fmt.Sprintf(format, args...)})
+		}
+		start, err := alignment.Start(v.granularity, t)
+		if err != nil {
+			return nil, err
+		}
+		if !start.Equal(t) {
+			report(CandleAnomalyMisaligned, "candlestick starts at %s, not at %s", t.Format(time.RFC3339), start.Format(time.RFC3339))
+		}
+		if !candle.Complete && i != len(candles)-1 {
+			report(CandleAnomalyIncomplete, "incomplete candlestick before the last one")
+		}
+		if err := checkCandlePrices(candle); err != nil {
+			report(CandleAnomalyInvalidPrice, "%v", err)
+		}
+		switch {
+		case prev == nil:
+		case t.Equal(*prev):
+			report(CandleAnomalyDuplicate, "candlestick repeats %s", t.Format(time.RFC3339))
+		case t.Before(*prev):
+			report(CandleAnomalyOutOfOrder, "candlestick at %s follows %s", t.Format(time.RFC3339), prev.Format(time.RFC3339))
+		default:
+			gap, err := v.missingPeriods(alignment, closed, *prev, t)
+			if err != nil {
+				return nil, err
+			}
+			if gap.Missing > 0 {
+				gap.Index = i
+				anomalies = append(anomalies, gap)
+			}
+		}
+		if prev == nil || t.After(*prev) {
+			prev = &t
+		}
+	}
+	return anomalies, nil
+}
+
+// missingPeriods returns a gap anomaly for the periods between the candlesticks starting at prev
+// and next in which the market was open, which has no missing periods if there are none.
+func (v *CandleValidator) missingPeriods(alignment *CandleAlignment, closed func(time.Time) bool, prev, next time.Time) (CandleAnomaly, error) {
+	gap := CandleAnomaly{Kind: CandleAnomalyGap}
+	period, err := alignment.End(v.granularity, prev)
+	if err != nil {
+		return gap, err
+	}
+	for period.Before(next) {
+		end, err := alignment.End(v.granularity, period)
+		if err != nil {
+			return gap, err
+		}
+		if !closed(period) || !closed(end.Add(-time.Nanosecond)) {
+			if gap.Missing == 0 {
+				gap.Time = period
+			}
+			gap.Missing++
+		}
+		period = end
+	}
+	if gap.Missing > 0 {
+		gap.Detail = fmt.Sprintf("%d missing candlesticks from %s before %s", gap.Missing, gap.Time.Format(time.RFC3339), next.Format(time.RFC3339))
+	}
+	return gap, nil
+}
+
+// forexWeekend reports whether t, in America/New_York, is within the weekly closure of the
+// currency markets from Friday 17:00 to Sunday 17:00.
+func forexWeekend(t time.Time) bool {
+	switch t.Weekday() {
+	case time.Friday:
+		return t.Hour() >= 17
+	case time.Saturday:
+		return true
+	case time.Sunday:
+		return t.Hour() < 17
+	}
+	return false
+}
+
+// checkCandlePrices returns an error if a price of candle cannot be parsed or is inconsistent
+// with the others of its component.
+func checkCandlePrices(candle Candlestick) error {
+	for _, component := range []struct {
+		name string
+		data *CandlestickData
+	}{{"bid", candle.Bid}, {"ask", candle.Ask}, {"mid", candle.Mid}} {
+		data := component.data
+		if data == nil {
+			continue
+		}
+		var o, h, l, c float64
+		for _, p := range []struct {
+			value PriceValue
+			dst   *float64
+		}{{data.O, &o}, {data.H, &h}, {data.L, &l}, {data.C, &c}} {
+			f, err := p.value.Float64()
+			if err != nil {
+				return fmt.Errorf("%s: %w", component.name, err)
+			}
+			*p.dst = f
+		}
+		if h < l || o < l || o > h || c < l || c > h {
+			return fmt.Errorf("%s: open %s and close %s must be between low %s and high %s", component.name, data.O, data.C, data.L, data.H)
+		}
+	}
+	return nil
+}
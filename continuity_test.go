@@ -0,0 +1,69 @@
+package oanda
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCandleValidator(t *testing.T) {
+	candle := func(at string, complete bool, high PriceValue) Candlestick {
+		tm, err := time.Parse(time.RFC3339, at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return Candlestick{
+			Time:     DateTime{&tm},
+			Mid:      &CandlestickData{O: "1.1000", H: high, L: "1.0990", C: "1.1005"},
+			Complete: complete,
+		}
+	}
+	candles := []Candlestick{
+		candle("2024-01-05T20:00:00Z", true, "1.1010"),
+		candle("2024-01-05T21:00:00Z", true, "1.1010"),
+		// The market is closed from 22:00 on Friday to 22:00 on Sunday.
+		candle("2024-01-07T22:00:00Z", true, "1.1010"),
+		candle("2024-01-07T23:00:00Z", true, "1.1010"),
+		candle("2024-01-08T02:00:00Z", true, "1.1010"),
+		candle("2024-01-08T02:00:00Z", true, "1.1010"),
+		candle("2024-01-08T01:00:00Z", true, "1.1010"),
+		candle("2024-01-08T03:30:00Z", true, "1.1010"),
+		candle("2024-01-08T04:00:00Z", false, "1.1010"),
+		candle("2024-01-08T05:00:00Z", false, "1.0980"),
+	}
+	anomalies, err := NewCandleValidator(H1).SetAlignment(CandleAlignment{}).Validate(candles)
+	if err != nil {
+		t.Fatalf("failed to validate: %v", err)
+	}
+	var got []string
+	for _, a := range anomalies {
+		got = append(got, fmt.Sprintf("%s %d %s %d", a.Kind, a.Index, a.Time.Format("02T15:04"), a.Missing))
+	}
+	want := []string{
+		"GAP 4 08T00:00 2",
+		"DUPLICATE 5 08T02:00 0",
+		"OUT_OF_ORDER 6 08T01:00 0",
+		"MISALIGNED 7 08T03:30 0",
+		"GAP 7 08T03:00 1",
+		"INCOMPLETE 8 08T04:00 0",
+		"INVALID_PRICE 9 08T05:00 0",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got anomalies\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	// Daily candlesticks with the default alignment skip the weekend.
+	daily := []Candlestick{
+		candle("2024-01-04T22:00:00Z", true, "1.1010"),
+		candle("2024-01-07T22:00:00Z", true, "1.1010"),
+		candle("2024-01-09T22:00:00Z", true, "1.1010"),
+	}
+	anomalies, err = NewCandleValidator(D).Validate(daily)
+	if err != nil {
+		t.Fatalf("failed to validate: %v", err)
+	}
+	if len(anomalies) != 1 || anomalies[0].Kind != CandleAnomalyGap || anomalies[0].Missing != 1 || anomalies[0].Index != 2 {
+		t.Errorf("got %+v, want a gap of 1 day before the last candlestick", anomalies)
+	}
+}
@@ -0,0 +1,309 @@
+package oanda
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HomeConverter converts amounts of any currency into the Account's home currency with the home
+// conversion factors of the pricing endpoint. The factors of a currency are fetched on first use,
+// by pricing an Instrument of the Account that has the currency as its base or quote currency,
+// and fetched again once they are older than the converter's max age. Create one with
+// [NewHomeConverter].
+type HomeConverter struct {
+	client      *Client
+	instruments *InstrumentCache
+	maxAge      time.Duration
+	mu          sync.Mutex
+	pairs       map[Currency]InstrumentName
+	factors     map[Currency]homeFactors
+}
+
+type homeFactors struct {
+	conversions HomeConversions
+	fetchedAt   time.Time
+}
+
+// NewHomeConverter creates a new HomeConverter that fetches prices with client and finds the
+// Instruments to price in instruments. Factors are kept for a minute unless
+// [HomeConverter.SetMaxAge] is called.
+func NewHomeConverter(client *Client, instruments *InstrumentCache) *HomeConverter {
+	return &HomeConverter{
+		client:      client,
+		instruments: instruments,
+		maxAge:      time.Minute,
+		pairs:       make(map[Currency]InstrumentName),
+		factors:     make(map[Currency]homeFactors),
+	}
+}
+
+// SetMaxAge sets how long factors are used before they are fetched again. A zero maxAge fetches
+// them on every conversion.
+func (c *HomeConverter) SetMaxAge(maxAge time.Duration) *HomeConverter {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.maxAge = maxAge
+	return c
+}
+
+// Update stores conversions fetched elsewhere, such as those of a [PriceInformationResponse]
+// requested with [PriceInformationRequest.SetIncludeHomeConversions], as fetched at time at.
+func (c *HomeConverter) Update(conversions []HomeConversions, at time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.update(conversions, at)
+}
+
+// Factors returns the home conversion factors of currency, fetching them if they are missing or
+// older than the max age.
+func (c *HomeConverter) Factors(ctx context.Context, currency Currency) (HomeConversions, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if f, ok := c.factors[currency]; ok && time.Since(f.fetchedAt) < c.maxAge {
+		return f.conversions, nil
+	}
+	if _, ok := c.pairs[currency]; !ok {
+		instrument, err := c.instruments.currencyPair(ctx, currency)
+		if err != nil {
+			return HomeConversions{}, err
+		}
+		c.pairs[currency] = instrument
+	}
+	if err := c.refresh(ctx); err != nil {
+		return HomeConversions{}, err
+	}
+	f, ok := c.factors[currency]
+	if !ok {
+		return HomeConversions{}, fmt.Errorf("no home conversion factors for %s", currency)
+	}
+	return f.conversions, nil
+}
+
+// Refresh fetches the factors of every currency converted so far.
+func (c *HomeConverter) Refresh(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.refresh(ctx)
+}
+
+// Convert converts amount of currency, such as a profit or loss, into the home currency. A
+// positive amount is converted with the AccountGain factor and a negative one with the
+// AccountLoss factor.
+func (c *HomeConverter) Convert(ctx context.Context, amount float64, currency Currency) (float64, error) {
+	factors, err := c.Factors(ctx, currency)
+	if err != nil {
+		return 0, err
+	}
+	factor := factors.AccountGain
+	if amount < 0 {
+		factor = factors.AccountLoss
+	}
+	f, err := factor.Float64()
+	if err != nil {
+		return 0, fmt.Errorf("invalid home conversion factor for %s: %w", currency, err)
+	}
+	return amount * f, nil
+}
+
+// ConvertPositionValue converts a Position or Trade value in currency into the home currency with
+// the PositionValue factor.
+func (c *HomeConverter) ConvertPositionValue(ctx context.Context, value float64, currency Currency) (float64, error) {
+	factors, err := c.Factors(ctx, currency)
+	if err != nil {
+		return 0, err
+	}
+	f, err := factors.PositionValue.Float64()
+	if err != nil {
+		return 0, fmt.Errorf("invalid home conversion factor for %s: %w", currency, err)
+	}
+	return value * f, nil
+}
+
+func (c *HomeConverter) refresh(ctx context.Context) error {
+	instruments := slices.Sorted(maps.Values(c.pairs))
+	if len(instruments) == 0 {
+		return nil
+	}
+	instruments = slices.Compact(instruments)
+	resp, err := c.client.Price.Information(ctx,
+		NewPriceInformationRequest().AddInstruments(instruments...).SetIncludeHomeConversions())
+	if err != nil {
+		return fmt.Errorf("failed to get home conversions: %w", err)
+	}
+	c.update(resp.HomeConversions, time.Now())
+	return nil
+}
+
+func (c *HomeConverter) update(conversions []HomeConversions, at time.Time) {
+	for _, conversion := range conversions {
+		c.factors[conversion.Currency] = homeFactors{conversions: conversion, fetchedAt: at}
+	}
+}
+
+// currencyPair returns the first Instrument, by name, of the currency pairs tradeable by the
+// Account that has currency as its base or quote currency.
+func (c *InstrumentCache) currencyPair(ctx context.Context, currency Currency) (InstrumentName, error) {
+	pairs, err := c.currencyPairs(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, name := range pairs {
+		base, quote, _ := strings.Cut(name, "_")
+		if Currency(base) == currency || Currency(quote) == currency {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("no instrument for currency %s", currency)
+}
+
+// currencyPairs returns the names of the currency pairs tradeable by the Account, sorted.
+func (c *InstrumentCache) currencyPairs(ctx context.Context) ([]InstrumentName, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.ensure(ctx); err != nil {
+		return nil, err
+	}
+	var pairs []InstrumentName
+	for _, name := range slices.Sorted(maps.Keys(c.instruments)) {
+		if c.instruments[name].Type == InstrumentTypeCurrency {
+			pairs = append(pairs, name)
+		}
+	}
+	return pairs, nil
+}
+
+// CurrencyConversion is the result of [CurrencyConverter.Convert].
+type CurrencyConversion struct {
+	// From is the currency converted from.
+	From Currency
+	// To is the currency converted to.
+	To Currency
+	// Amount is the converted amount, in To.
+	Amount float64
+	// Rate is the number of units of To per unit of From the amount was converted at.
+	Rate float64
+	// Instruments are the currency pairs priced for the conversion: one for a direct pair, and
+	// two for a conversion crossed through a third currency.
+	Instruments []InstrumentName
+	// Time is the time of the oldest of the prices used.
+	Time time.Time
+}
+
+// CurrencyConverter converts amounts between any two currencies at the current prices of the
+// currency pairs tradeable by the Account, such as for reporting and funding operations. Create
+// one with [NewCurrencyConverter].
+type CurrencyConverter struct {
+	client      *Client
+	instruments *InstrumentCache
+}
+
+// NewCurrencyConverter creates a new CurrencyConverter that fetches prices with client and
+// finds the currency pairs in instruments.
+func NewCurrencyConverter(client *Client, instruments *InstrumentCache) *CurrencyConverter {
+	return &CurrencyConverter{client: client, instruments: instruments}
+}
+
+// Convert converts amount of from into to, at the prices a conversion would trade at: the bid
+// when selling the base currency of a pair and the ask when buying it. A direct pair of the two
+// currencies is used if the Account has one; otherwise the conversion is crossed through a third
+// currency, USD if possible. Converting a currency into itself uses a rate of 1 and prices
+// nothing.
+//
+// This corresponds to the OANDA API endpoint: GET /v3/accounts/{accountID}/pricing
+//
+// Reference: https://developer.oanda.com/rest-live-v20/pricing-ep/#collapse_endpoint_2
+func (c *CurrencyConverter) Convert(ctx context.Context, amount float64, from, to Currency) (*CurrencyConversion, error) {
+	conversion := &CurrencyConversion{From: from, To: to, Amount: amount, Rate: 1, Time: time.Now()}
+	if from == to {
+		return conversion, nil
+	}
+	pairs, err := c.instruments.currencyPairs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	path := conversionPath(pairs, from, to)
+	if path == nil {
+		return nil, fmt.Errorf("no currency pairs to convert %s to %s", from, to)
+	}
+	resp, err := c.client.Price.Information(ctx, NewPriceInformationRequest().AddInstruments(path...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get prices: %w", err)
+	}
+	conversion.Instruments, conversion.Time = path, time.Time{}
+	currency := from
+	for _, instrument := range path {
+		price, ok := resp.PriceFor(instrument)
+		if !ok {
+			return nil, fmt.Errorf("no price for %s", instrument)
+		}
+		base, quote, _ := strings.Cut(instrument, "_")
+		var rate float64
+		if Currency(base) == currency {
+			bid, ok := price.BestBid()
+			if !ok {
+				return nil, fmt.Errorf("no bid for %s", instrument)
+			}
+			if rate, err = bid.Price.Float64(); err != nil {
+				return nil, err
+			}
+			currency = Currency(quote)
+		} else {
+			ask, ok := price.BestAsk()
+			if !ok {
+				return nil, fmt.Errorf("no ask for %s", instrument)
+			}
+			a, err := ask.Price.Float64()
+			if err != nil {
+				return nil, err
+			}
+			rate = 1 / a
+			currency = Currency(base)
+		}
+		conversion.Rate *= rate
+		if price.Time.Time != nil && (conversion.Time.IsZero() || price.Time.Before(conversion.Time)) {
+			conversion.Time = *price.Time.Time
+		}
+	}
+	conversion.Amount = amount * conversion.Rate
+	return conversion, nil
+}
+
+// conversionPath returns the pairs that convert from into to: a direct pair, or two pairs
+// crossed through USD or else the first other currency, by name, that both have a pair with. It
+// returns nil if there is none.
+func conversionPath(pairs []InstrumentName, from, to Currency) []InstrumentName {
+	pairOf := func(a, b Currency) (InstrumentName, bool) {
+		for _, name := range []InstrumentName{string(a) + "_" + string(b), string(b) + "_" + string(a)} {
+			if slices.Contains(pairs, name) {
+				return name, true
+			}
+		}
+		return "", false
+	}
+	if pair, ok := pairOf(from, to); ok {
+		return []InstrumentName{pair}
+	}
+	var currencies []Currency
+	for _, name := range pairs {
+		base, quote, _ := strings.Cut(name, "_")
+		currencies = append(currencies, Currency(base), Currency(quote))
+	}
+	slices.Sort(currencies)
+	currencies = slices.Insert(slices.Compact(currencies), 0, "USD")
+	for _, via := range currencies {
+		if via == from || via == to {
+			continue
+		}
+		first, ok1 := pairOf(from, via)
+		second, ok2 := pairOf(via, to)
+		if ok1 && ok2 {
+			return []InstrumentName{first, second}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package oanda
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHomeConverter(t *testing.T) {
+	var requests []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v3/accounts/1/instruments", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `{"instruments":[{"name":"XAU_JPY","type":"METAL"},
+			{"name":"USD_JPY","type":"CURRENCY"},{"name":"EUR_USD","type":"CURRENCY"}]}`)
+	})
+	mux.HandleFunc("/v3/accounts/1/pricing", func(w http.ResponseWriter, r *http.Request) {
+		instruments := r.URL.Query().Get("instruments")
+		requests = append(requests, instruments)
+		var conversions []string
+		if strings.Contains(instruments, "USD") {
+			conversions = append(conversions, `{"currency":"USD","accountGain":"1","accountLoss":"1","positionValue":"1"}`)
+		}
+		if strings.Contains(instruments, "EUR") {
+			conversions = append(conversions, `{"currency":"EUR","accountGain":"1.09","accountLoss":"1.11","positionValue":"1.1"}`)
+		}
+		if strings.Contains(instruments, "JPY") {
+			conversions = append(conversions, `{"currency":"JPY","accountGain":"0.0066","accountLoss":"0.0067","positionValue":"0.00665"}`)
+		}
+		_, _ = fmt.Fprintf(w, `{"prices":[],"homeConversions":[%s]}`, strings.Join(conversions, ","))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client := NewClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+	converter := NewHomeConverter(client, NewInstrumentCache(client))
+
+	for _, tt := range []struct {
+		amount   float64
+		currency Currency
+		want     float64
+	}{
+		{10000, "JPY", 66},
+		{-10000, "JPY", -67},
+		{100, "EUR", 109},
+		{-100, "EUR", -111},
+		{5, "USD", 5},
+	} {
+		got, err := converter.Convert(t.Context(), tt.amount, tt.currency)
+		if err != nil || math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Convert(%v, %s) = %v, %v, want %v", tt.amount, tt.currency, got, err, tt.want)
+		}
+	}
+	if got, err := converter.ConvertPositionValue(t.Context(), 1000, "EUR"); err != nil || math.Abs(got-1100) > 1e-9 {
+		t.Errorf("got position value %v, %v", got, err)
+	}
+	// JPY is priced with USD_JPY, the first currency pair with JPY, which also yields USD. EUR is
+	// then priced with EUR_USD, refreshing USD_JPY with it.
+	if want := []string{"USD_JPY", "EUR_USD,USD_JPY"}; !slices.Equal(requests, want) {
+		t.Errorf("got requests %v, want %s", requests, want)
+	}
+
+	converter.SetMaxAge(0)
+	if _, err := converter.Convert(t.Context(), 1, "JPY"); err != nil {
+		t.Fatal(err)
+	}
+	if last := requests[len(requests)-1]; last != "EUR_USD,USD_JPY" {
+		t.Errorf("got refresh of %s, want all known instruments", last)
+	}
+	if _, err := converter.Convert(t.Context(), 1, "GBP"); err == nil {
+		t.Error("got no error for a currency without an instrument")
+	}
+
+	converter.SetMaxAge(time.Hour)
+	converter.Update([]HomeConversions{{Currency: "EUR", AccountGain: "2"}}, time.Now())
+	n := len(requests)
+	if got, err := converter.Convert(t.Context(), 1, "EUR"); err != nil || got != 2 || len(requests) != n {
+		t.Errorf("got %v, %v after Update, with %d requests", got, err, len(requests)-n)
+	}
+}
+
+func TestCurrencyConverter(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v3/accounts/1/instruments", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `{"instruments":[{"name":"EUR_USD","type":"CURRENCY"},{"name":"USD_JPY","type":"CURRENCY"},
+			{"name":"AUD_CAD","type":"CURRENCY"},{"name":"XAU_USD","type":"METAL"}]}`)
+	})
+	mux.HandleFunc("/v3/accounts/1/pricing", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `{"prices":[
+			{"instrument":"EUR_USD","time":"2024-01-02T10:00:01Z","bids":[{"price":"1.10000"}],"asks":[{"price":"1.10020"}]},
+			{"instrument":"USD_JPY","time":"2024-01-02T10:00:00Z","bids":[{"price":"150.000"}],"asks":[{"price":"160.000"}]}]}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client := NewClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+	converter := NewCurrencyConverter(client, NewInstrumentCache(client))
+
+	// Selling EUR for USD at the bid, then USD for JPY at the bid.
+	conversion, err := converter.Convert(t.Context(), 100, "EUR", "JPY")
+	if err != nil {
+		t.Fatalf("failed to convert: %v", err)
+	}
+	if math.Abs(conversion.Amount-16500) > 1e-6 || math.Abs(conversion.Rate-165) > 1e-9 ||
+		!slices.Equal(conversion.Instruments, []InstrumentName{"EUR_USD", "USD_JPY"}) ||
+		!conversion.Time.Equal(time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("got %+v", conversion)
+	}
+	// Buying USD with JPY at the ask.
+	conversion, err = converter.Convert(t.Context(), 16000, "JPY", "USD")
+	if err != nil || math.Abs(conversion.Amount-100) > 1e-9 || len(conversion.Instruments) != 1 {
+		t.Errorf("got %+v (%v)", conversion, err)
+	}
+	if conversion, err := converter.Convert(t.Context(), 5, "EUR", "EUR"); err != nil || conversion.Amount != 5 {
+		t.Errorf("got %+v (%v)", conversion, err)
+	}
+	if _, err := converter.Convert(t.Context(), 5, "EUR", "CAD"); err == nil {
+		t.Error("got no error without currency pairs")
+	}
+}
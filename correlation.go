@@ -0,0 +1,148 @@
+package oanda
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"time"
+)
+
+// CorrelationMatrix holds the correlations of the returns of several Instruments over the same
+// candlesticks, built by [CorrelationMatrixOf].
+type CorrelationMatrix struct {
+	// Instruments are the Instruments of the rows and columns, sorted by name.
+	Instruments []InstrumentName
+	// Values are the Pearson correlations of the returns, indexed like Instruments. A
+	// correlation is NaN if the price of either Instrument did not change.
+	Values [][]float64
+	// Samples is the number of returns the correlations were computed from.
+	Samples int
+	// From and To are the times of the first and last candlesticks the returns were computed
+	// from.
+	From, To time.Time
+}
+
+// CorrelationMatrixOf computes the correlations between the log returns of the closing prices of
+// component, one of PriceComponentBid, PriceComponentAsk and PriceComponentMid, of the candles of
+// each Instrument, such as the results of [instrumentService.CandlesBatch] for one granularity.
+// Only the complete candlesticks that every Instrument has are used, so that returns cover the
+// same periods; of those, the last window returns are used, or all of them if window is 0. It
+// returns an error if fewer than two Instruments are given, fewer than two returns are in common,
+// or a candlestick does not have the prices of component.
+func CorrelationMatrixOf(candles map[InstrumentName][]Candlestick, component PriceComponents, window int) (*CorrelationMatrix, error) {
+	if len(candles) < 2 {
+		return nil, errors.New("at least two instruments are required")
+	}
+	instruments := slices.Sorted(maps.Keys(candles))
+	closes := make([]map[time.Time]float64, len(instruments))
+	for i, instrument := range instruments {
+		closes[i] = make(map[time.Time]float64)
+		for _, candle := range candles[instrument] {
+			if !candle.Complete || candle.Time.Time == nil {
+				continue
+			}
+			data := candle.Data(component)
+			if data == nil {
+				return nil, fmt.Errorf("%s candlestick at %v has no %s prices", instrument, candle.Time, component)
+			}
+			c, err := data.C.Float64()
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s candlestick at %v: %w", instrument, candle.Time, err)
+			}
+			closes[i][candle.Time.UTC()] = c
+		}
+	}
+	var times []time.Time
+	for t := range closes[0] {
+		if !slices.ContainsFunc(closes[1:], func(c map[time.Time]float64) bool { _, ok := c[t]; return !ok }) {
+			times = append(times, t)
+		}
+	}
+	slices.SortFunc(times, func(a, b time.Time) int { return a.Compare(b) })
+	if window > 0 && len(times) > window+1 {
+		times = times[len(times)-window-1:]
+	}
+	if len(times) < 3 {
+		return nil, fmt.Errorf("got %d common candlesticks, need at least 3", len(times))
+	}
+	returns := make([][]float64, len(instruments))
+	for i := range instruments {
+		returns[i] = make([]float64, len(times)-1)
+		for j := 1; j < len(times); j++ {
+			returns[i][j-1] = math.Log(closes[i][times[j]] / closes[i][times[j-1]])
+		}
+	}
+	m := &CorrelationMatrix{
+		Instruments: instruments,
+		Values:      make([][]float64, len(instruments)),
+		Samples:     len(times) - 1,
+		From:        times[0],
+		To:          times[len(times)-1],
+	}
+	for i := range instruments {
+		m.Values[i] = make([]float64, len(instruments))
+		for j := range i + 1 {
+			r := pearson(returns[i], returns[j])
+			m.Values[i][j], m.Values[j][i] = r, r
+		}
+	}
+	return m, nil
+}
+
+// pearson returns the Pearson correlation of x and y, or NaN if either does not vary.
+func pearson(x, y []float64) float64 {
+	var mx, my float64
+	for i := range x {
+		mx += x[i]
+		my += y[i]
+	}
+	mx /= float64(len(x))
+	my /= float64(len(y))
+	var sxy, sxx, syy float64
+	for i := range x {
+		dx, dy := x[i]-mx, y[i]-my
+		sxy += dx * dy
+		sxx += dx * dx
+		syy += dy * dy
+	}
+	if sxx == 0 || syy == 0 {
+		return math.NaN()
+	}
+	return max(-1, min(1, sxy/math.Sqrt(sxx*syy)))
+}
+
+// Get returns the correlation of a and b, and false if either is not in the matrix.
+func (m *CorrelationMatrix) Get(a, b InstrumentName) (float64, bool) {
+	i, j := slices.Index(m.Instruments, a), slices.Index(m.Instruments, b)
+	if i < 0 || j < 0 {
+		return 0, false
+	}
+	return m.Values[i][j], true
+}
+
+// CorrelatedPair is a pair of Instruments and the correlation of their returns.
+type CorrelatedPair struct {
+	A, B        InstrumentName
+	Correlation float64
+}
+
+// Pairs returns the pairs of different Instruments whose correlation is threshold or more in
+// absolute value, from the most to the least correlated, such as to find positions that add to
+// the same exposure. Pairs with a NaN correlation are left out.
+func (m *CorrelationMatrix) Pairs(threshold float64) []CorrelatedPair {
+	var pairs []CorrelatedPair
+	for i := range m.Instruments {
+		for j := i + 1; j < len(m.Instruments); j++ {
+			if r := m.Values[i][j]; math.Abs(r) >= threshold {
+				pairs = append(pairs, CorrelatedPair{m.Instruments[i], m.Instruments[j], r})
+			}
+		}
+	}
+	slices.SortStableFunc(pairs, func(a, b CorrelatedPair) int {
+		return cmp.Compare(math.Abs(b.Correlation), math.Abs(a.Correlation))
+	})
+	return pairs
+}
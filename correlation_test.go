@@ -0,0 +1,67 @@
+package oanda
+
+import (
+	"math"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestCorrelationMatrixOf(t *testing.T) {
+	base := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	closes := []float64{1.10, 1.12, 1.11, 1.15, 1.13, 1.16}
+	series := func(f func(float64) float64, skip int) []Candlestick {
+		var candles []Candlestick
+		for i, c := range closes {
+			if i == skip {
+				continue
+			}
+			at := base.Add(time.Duration(i) * time.Hour)
+			price := PriceValue(strconv.FormatFloat(f(c), 'f', -1, 64))
+			candles = append(candles, Candlestick{Time: DateTime{&at}, Mid: &CandlestickData{C: price}, Complete: true})
+		}
+		return candles
+	}
+	candles := map[InstrumentName][]Candlestick{
+		"EUR_USD": series(func(c float64) float64 { return c }, -1),
+		"GBP_USD": series(func(c float64) float64 { return 2 * c }, -1),
+		"USD_CHF": series(func(c float64) float64 { return 1 / c }, -1),
+		"XAU_USD": series(func(float64) float64 { return 2000 }, -1),
+	}
+	m, err := CorrelationMatrixOf(candles, PriceComponentMid, 0)
+	if err != nil {
+		t.Fatalf("failed to compute correlations: %v", err)
+	}
+	if m.Samples != 5 || !m.From.Equal(base) || m.Instruments[0] != "EUR_USD" {
+		t.Errorf("got matrix %+v", m)
+	}
+	for _, tt := range []struct {
+		a, b InstrumentName
+		want float64
+	}{
+		{"EUR_USD", "EUR_USD", 1},
+		{"EUR_USD", "GBP_USD", 1},
+		{"USD_CHF", "EUR_USD", -1},
+		{"XAU_USD", "EUR_USD", math.NaN()},
+	} {
+		got, ok := m.Get(tt.a, tt.b)
+		if !ok || (math.IsNaN(tt.want) != math.IsNaN(got)) || (!math.IsNaN(tt.want) && math.Abs(got-tt.want) > 1e-9) {
+			t.Errorf("got correlation %v of %s and %s, want %v", got, tt.a, tt.b, tt.want)
+		}
+	}
+	if pairs := m.Pairs(0.9); len(pairs) != 3 || pairs[0].A != "EUR_USD" {
+		t.Errorf("got pairs %+v", pairs)
+	}
+
+	candles["GBP_USD"] = series(func(c float64) float64 { return 2 * c }, 5)
+	m, err = CorrelationMatrixOf(candles, PriceComponentMid, 2)
+	if err != nil || m.Samples != 2 || !m.To.Equal(base.Add(4*time.Hour)) {
+		t.Errorf("got matrix %+v (%v) over the last two common returns", m, err)
+	}
+	if _, err := CorrelationMatrixOf(candles, PriceComponentBid, 0); err == nil {
+		t.Error("got no error for missing bid prices")
+	}
+	if _, err := CorrelationMatrixOf(map[InstrumentName][]Candlestick{"EUR_USD": candles["EUR_USD"]}, PriceComponentMid, 0); err == nil {
+		t.Error("got no error for a single instrument")
+	}
+}
@@ -0,0 +1,159 @@
+package oanda
+
+import (
+	"iter"
+	"math/big"
+	"time"
+)
+
+// ExecutionCostTotals is the cost of executing a set of Order fills, in the Account's home
+// currency, set against the profit and loss they realized.
+type ExecutionCostTotals struct {
+	// Fills is the number of fills.
+	Fills int
+	// Commission is the commission charged.
+	Commission AccountUnits
+	// HalfSpreadCost is the cost of crossing half of the spread. It is already reflected in the
+	// fill prices, and therefore in GrossPL.
+	HalfSpreadCost AccountUnits
+	// GuaranteedExecutionFee is the fee charged for guaranteed Stop Losses.
+	GuaranteedExecutionFee AccountUnits
+	// Total is the sum of Commission, HalfSpreadCost and GuaranteedExecutionFee.
+	Total AccountUnits
+	// GrossPL is the profit or loss realized by the fills, before commission and fees.
+	GrossPL AccountUnits
+	// NetPL is GrossPL less Commission and GuaranteedExecutionFee.
+	NetPL AccountUnits
+}
+
+// CostRatio returns Total as a fraction of the absolute value of GrossPL, such as 0.25 when a
+// quarter of the gross profit was spent on execution. ok is false if GrossPL is zero or an
+// amount is not a valid decimal number.
+func (t ExecutionCostTotals) CostRatio() (ratio float64, ok bool) {
+	total, err := t.Total.Rat()
+	if err != nil {
+		return 0, false
+	}
+	gross, err := t.GrossPL.Rat()
+	if err != nil || gross.Sign() == 0 {
+		return 0, false
+	}
+	ratio, _ = total.Quo(total, gross.Abs(gross)).Float64()
+	return ratio, true
+}
+
+// ExecutionCostReport is the execution cost of Order fills, in total and broken down by
+// Instrument and by day. Create one with [SummarizeExecutionCosts].
+type ExecutionCostReport struct {
+	// Total is the cost of all fills.
+	Total ExecutionCostTotals
+	// ByInstrument is the cost of the fills of each Instrument.
+	ByInstrument map[InstrumentName]ExecutionCostTotals
+	// ByDay is the cost of the fills of each day, keyed by date in YYYY-MM-DD format.
+	ByDay map[string]ExecutionCostTotals
+}
+
+// SummarizeExecutionCosts builds an [ExecutionCostReport] from the OrderFillTransactions among
+// transactions, such as the iterator returned by [transactionService.Iterate]. Days are split
+// in location, or in UTC if location is nil. Other Transactions are ignored.
+func SummarizeExecutionCosts(transactions iter.Seq2[Transaction, error], location *time.Location) (*ExecutionCostReport, error) {
+	if location == nil {
+		location = time.UTC
+	}
+	var total costSums
+	byInstrument := make(map[InstrumentName]*costSums)
+	byDay := make(map[string]*costSums)
+	for transaction, err := range transactions {
+		if err != nil {
+			return nil, err
+		}
+		var fill *OrderFillTransaction
+		switch t := transaction.(type) {
+		case OrderFillTransaction:
+			fill = &t
+		case *OrderFillTransaction:
+			fill = t
+		default:
+			continue
+		}
+		var sums costSums
+		if err := sums.add(fill); err != nil {
+			return nil, err
+		}
+		day := ""
+		if fill.Time.Time != nil {
+			day = fill.Time.In(location).Format(time.DateOnly)
+		}
+		for _, s := range []*costSums{&total, entry(byInstrument, fill.Instrument), entry(byDay, day)} {
+			s.merge(&sums)
+		}
+	}
+	report := &ExecutionCostReport{
+		Total:        total.totals(),
+		ByInstrument: make(map[InstrumentName]ExecutionCostTotals, len(byInstrument)),
+		ByDay:        make(map[string]ExecutionCostTotals, len(byDay)),
+	}
+	for instrument, s := range byInstrument {
+		report.ByInstrument[instrument] = s.totals()
+	}
+	for day, s := range byDay {
+		report.ByDay[day] = s.totals()
+	}
+	return report, nil
+}
+
+// costSums sums the costs and P/L of fills exactly.
+type costSums struct {
+	fills                                          int
+	commission, halfSpread, guaranteedFee, grossPL decimalSum
+}
+
+// add adds the amounts of fill. It fails, leaving the sums partially updated, if an amount is not
+// a valid decimal number.
+func (s *costSums) add(fill *OrderFillTransaction) error {
+	s.fills++
+	for _, a := range []struct {
+		sum    *decimalSum
+		amount AccountUnits
+	}{
+		{&s.commission, fill.Commission},
+		{&s.halfSpread, fill.HalfSpreadCost},
+		{&s.guaranteedFee, fill.GuaranteedExecutionFee},
+		{&s.grossPL, fill.PL},
+	} {
+		if err := a.sum.add(string(a.amount)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *costSums) merge(other *costSums) {
+	s.fills += other.fills
+	for _, pair := range [][2]*decimalSum{
+		{&s.commission, &other.commission},
+		{&s.halfSpread, &other.halfSpread},
+		{&s.guaranteedFee, &other.guaranteedFee},
+		{&s.grossPL, &other.grossPL},
+	} {
+		pair[0].sum.Add(&pair[0].sum, &pair[1].sum)
+		pair[0].places = max(pair[0].places, pair[1].places)
+	}
+}
+
+func (s *costSums) totals() ExecutionCostTotals {
+	places := max(s.commission.places, s.halfSpread.places, s.guaranteedFee.places, s.grossPL.places)
+	total := new(big.Rat).Add(&s.commission.sum, &s.halfSpread.sum)
+	total.Add(total, &s.guaranteedFee.sum)
+	net := new(big.Rat).Sub(&s.grossPL.sum, &s.commission.sum)
+	net.Sub(net, &s.guaranteedFee.sum)
+	return ExecutionCostTotals{
+		Fills:                  s.fills,
+		Commission:             AccountUnits(s.commission.sum.FloatString(places)),
+		HalfSpreadCost:         AccountUnits(s.halfSpread.sum.FloatString(places)),
+		GuaranteedExecutionFee: AccountUnits(s.guaranteedFee.sum.FloatString(places)),
+		Total:                  AccountUnits(total.FloatString(places)),
+		GrossPL:                AccountUnits(s.grossPL.sum.FloatString(places)),
+		NetPL:                  AccountUnits(net.FloatString(places)),
+	}
+}
@@ -0,0 +1,52 @@
+package oanda
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSummarizeExecutionCosts(t *testing.T) {
+	store := NewMemoryTransactionStore()
+	var transactions []Transaction
+	for _, line := range []string{
+		`{"type":"ORDER_FILL","id":"1","time":"2024-01-02T10:00:00Z","instrument":"EUR_USD","units":"1000","pl":"0.0000","commission":"0.0500","halfSpreadCost":"0.0700","guaranteedExecutionFee":"0.1000"}`,
+		`{"type":"ORDER_FILL","id":"2","time":"2024-01-02T20:00:00Z","instrument":"EUR_USD","units":"-1000","pl":"2.0000","commission":"0.0500","halfSpreadCost":"0.0700","guaranteedExecutionFee":"0.0000"}`,
+		`{"type":"DAILY_FINANCING","id":"3","time":"2024-01-02T21:00:00Z","financing":"-1.0000"}`,
+		`{"type":"ORDER_FILL","id":"4","time":"2024-01-03T10:00:00Z","instrument":"USD_JPY","units":"100","pl":"-0.50","commission":"0","halfSpreadCost":"0.01","guaranteedExecutionFee":"0"}`,
+	} {
+		transaction, err := unmarshalTransaction([]byte(line))
+		if err != nil {
+			t.Fatalf("failed to decode transaction: %v", err)
+		}
+		transactions = append(transactions, transaction)
+	}
+	if err := store.Append(t.Context(), transactions); err != nil {
+		t.Fatalf("failed to append transactions: %v", err)
+	}
+
+	report, err := SummarizeExecutionCosts(store.All(t.Context()), time.FixedZone("JST", 9*60*60))
+	if err != nil {
+		t.Fatalf("failed to summarize: %v", err)
+	}
+	want := ExecutionCostTotals{Fills: 3, Commission: "0.1000", HalfSpreadCost: "0.1500", GuaranteedExecutionFee: "0.1000",
+		Total: "0.3500", GrossPL: "1.5000", NetPL: "1.3000"}
+	if report.Total != want {
+		t.Errorf("got total %+v, want %+v", report.Total, want)
+	}
+	if ratio, ok := report.Total.CostRatio(); !ok || ratio < 0.2333 || ratio > 0.2334 {
+		t.Errorf("got cost ratio %v (%v), want 0.2333", ratio, ok)
+	}
+	if got := report.ByInstrument["USD_JPY"]; got.Fills != 1 || got.Total != "0.01" || got.NetPL != "-0.50" {
+		t.Errorf("got USD_JPY %+v, want 1 fill costing 0.01 and netting -0.50", got)
+	}
+	// The second fill is on the next day in Tokyo.
+	if got := report.ByDay["2024-01-03"]; got.Fills != 2 || got.Total != "0.1300" {
+		t.Errorf("got 2024-01-03 %+v, want 2 fills costing 0.1300", got)
+	}
+	if _, ok := report.ByInstrument["USD_JPY"].CostRatio(); !ok {
+		t.Error("got no cost ratio for a loss")
+	}
+	if _, ok := (ExecutionCostTotals{Total: "1", GrossPL: "0"}).CostRatio(); ok {
+		t.Error("got a cost ratio for zero gross P/L")
+	}
+}
@@ -0,0 +1,210 @@
+package oanda
+
+import (
+	"iter"
+	"math/big"
+	"time"
+)
+
+// TradeDividendAdjustment is the dividend adjustment of one Trade by one
+// DividendAdjustmentTransaction.
+type TradeDividendAdjustment struct {
+	// TransactionID is the ID of the DividendAdjustmentTransaction.
+	TransactionID TransactionID
+	// Time is the time of the DividendAdjustmentTransaction.
+	Time time.Time
+	// Instrument is the Instrument of the Trade.
+	Instrument InstrumentName
+	// TradeID is the ID of the Trade, or empty for the part of the Transaction's adjustment that
+	// is not attributed to any open Trade.
+	TradeID TradeID
+	// DividendAdjustment is the adjustment in the Account's home currency.
+	DividendAdjustment AccountUnits
+	// QuoteDividendAdjustment is the adjustment in the Instrument's quote currency.
+	QuoteDividendAdjustment DecimalNumber
+}
+
+// DividendTotals is the sum of dividend adjustments of one Instrument or Trade.
+type DividendTotals struct {
+	// Adjustments is the number of adjustments.
+	Adjustments int
+	// DividendAdjustment is the sum in the Account's home currency.
+	DividendAdjustment AccountUnits
+	// QuoteDividendAdjustment is the sum in the Instrument's quote currency.
+	QuoteDividendAdjustment DecimalNumber
+}
+
+// DividendReport attributes the dividend adjustments of index and equity CFDs to Instruments and
+// Trades, so that the balance changes they cause can be reconciled. Create one with
+// [SummarizeDividendAdjustments].
+type DividendReport struct {
+	// Total is the sum of all adjustments in the Account's home currency.
+	Total AccountUnits
+	// ByInstrument is the sum of the adjustments of each Instrument.
+	ByInstrument map[InstrumentName]DividendTotals
+	// ByTrade is the sum of the adjustments of each Trade.
+	ByTrade map[TradeID]DividendTotals
+	// Adjustments are the adjustments of each Trade in Transaction order. When the Trades'
+	// adjustments do not add up to the Transaction's, the difference is included with an empty
+	// TradeID.
+	Adjustments []TradeDividendAdjustment
+}
+
+// SummarizeDividendAdjustments builds a [DividendReport] from the DividendAdjustmentTransactions
+// among transactions, such as the iterator returned by [transactionService.Iterate]. Other
+// Transactions are ignored.
+func SummarizeDividendAdjustments(transactions iter.Seq2[Transaction, error]) (*DividendReport, error) {
+	var (
+		total        decimalSum
+		byInstrument = make(map[InstrumentName]*dividendSums)
+		byTrade      = make(map[TradeID]*dividendSums)
+		adjustments  []TradeDividendAdjustment
+	)
+	for transaction, err := range transactions {
+		if err != nil {
+			return nil, err
+		}
+		var t *DividendAdjustmentTransaction
+		switch transaction := transaction.(type) {
+		case DividendAdjustmentTransaction:
+			t = &transaction
+		case *DividendAdjustmentTransaction:
+			t = transaction
+		default:
+			continue
+		}
+		adjusted, err := tradeDividendAdjustments(t)
+		if err != nil {
+			return nil, err
+		}
+		if err := total.add(string(t.DividendAdjustment)); err != nil {
+			return nil, err
+		}
+		instrument := entry(byInstrument, t.Instrument)
+		for _, a := range adjusted {
+			if err := instrument.add(a); err != nil {
+				return nil, err
+			}
+			if a.TradeID != "" {
+				if err := entry(byTrade, a.TradeID).add(a); err != nil {
+					return nil, err
+				}
+			}
+		}
+		adjustments = append(adjustments, adjusted...)
+	}
+	report := &DividendReport{
+		Total:        AccountUnits(total.String()),
+		ByInstrument: make(map[InstrumentName]DividendTotals, len(byInstrument)),
+		ByTrade:      make(map[TradeID]DividendTotals, len(byTrade)),
+		Adjustments:  adjustments,
+	}
+	for instrument, sums := range byInstrument {
+		report.ByInstrument[instrument] = sums.totals()
+	}
+	for tradeID, sums := range byTrade {
+		report.ByTrade[tradeID] = sums.totals()
+	}
+	return report, nil
+}
+
+// tradeDividendAdjustments returns the adjustments of each Trade by t, followed by the
+// unattributed remainder of its adjustment, if any.
+func tradeDividendAdjustments(t *DividendAdjustmentTransaction) ([]TradeDividendAdjustment, error) {
+	adjustment := TradeDividendAdjustment{TransactionID: t.ID, Instrument: t.Instrument}
+	if t.Time.Time != nil {
+		adjustment.Time = *t.Time.Time
+	}
+	var home, quote decimalSum
+	adjustments := make([]TradeDividendAdjustment, 0, len(t.OpenTradeDividendAdjustments)+1)
+	for _, trade := range t.OpenTradeDividendAdjustments {
+		if err := home.add(string(trade.DividendAdjustment)); err != nil {
+			return nil, err
+		}
+		if err := quote.add(string(trade.QuoteDividendAdjustment)); err != nil {
+			return nil, err
+		}
+		adjustment.TradeID = trade.TradeID
+		adjustment.DividendAdjustment = trade.DividendAdjustment
+		adjustment.QuoteDividendAdjustment = trade.QuoteDividendAdjustment
+		adjustments = append(adjustments, adjustment)
+	}
+	homeRest, err := home.remainder(string(t.DividendAdjustment))
+	if err != nil {
+		return nil, err
+	}
+	quoteRest, err := quote.remainder(string(t.QuoteDividendAdjustment))
+	if err != nil {
+		return nil, err
+	}
+	if homeRest != "" || quoteRest != "" {
+		adjustment.TradeID = ""
+		adjustment.DividendAdjustment = AccountUnits(homeRest)
+		adjustment.QuoteDividendAdjustment = DecimalNumber(quoteRest)
+		adjustments = append(adjustments, adjustment)
+	}
+	return adjustments, nil
+}
+
+// dividendSums sums the dividend adjustments of an Instrument or Trade.
+type dividendSums struct {
+	adjustments int
+	home, quote decimalSum
+}
+
+func (s *dividendSums) add(a TradeDividendAdjustment) error {
+	s.adjustments++
+	if err := s.home.add(string(a.DividendAdjustment)); err != nil {
+		return err
+	}
+	return s.quote.add(string(a.QuoteDividendAdjustment))
+}
+
+func (s *dividendSums) totals() DividendTotals {
+	return DividendTotals{
+		Adjustments:             s.adjustments,
+		DividendAdjustment:      AccountUnits(s.home.String()),
+		QuoteDividendAdjustment: DecimalNumber(s.quote.String()),
+	}
+}
+
+// decimalSum sums decimal numbers exactly, keeping the largest number of decimal places seen.
+type decimalSum struct {
+	sum    big.Rat
+	places int
+}
+
+// add adds the decimal number s. An empty s is zero.
+func (d *decimalSum) add(s string) error {
+	if s == "" {
+		return nil
+	}
+	r, err := parseRat(s)
+	if err != nil {
+		return err
+	}
+	d.sum.Add(&d.sum, r)
+	d.places = max(d.places, fractionDigits(s))
+	return nil
+}
+
+// remainder returns total less the sum, or an empty string if total is empty or equal to the
+// sum.
+func (d *decimalSum) remainder(total string) (string, error) {
+	if total == "" {
+		return "", nil
+	}
+	t, err := parseRat(total)
+	if err != nil {
+		return "", err
+	}
+	rest := t.Sub(t, &d.sum)
+	if rest.Sign() == 0 {
+		return "", nil
+	}
+	return rest.FloatString(max(d.places, fractionDigits(total))), nil
+}
+
+func (d *decimalSum) String() string {
+	return d.sum.FloatString(d.places)
+}
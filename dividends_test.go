@@ -0,0 +1,51 @@
+package oanda
+
+import "testing"
+
+func TestSummarizeDividendAdjustments(t *testing.T) {
+	store := NewMemoryTransactionStore()
+	for _, line := range []string{
+		`{"type":"DIVIDEND_ADJUSTMENT","id":"1","time":"2024-03-01T00:00:00Z","instrument":"US30_USD","dividendAdjustment":"-1.5000","quoteDividendAdjustment":"-1.50",
+			"openTradeDividendAdjustments":[{"tradeID":"10","dividendAdjustment":"-1.0000","quoteDividendAdjustment":"-1.00"},{"tradeID":"11","dividendAdjustment":"-0.5000","quoteDividendAdjustment":"-0.50"}]}`,
+		`{"type":"ORDER_FILL","id":"2","instrument":"US30_USD","units":"1"}`,
+		`{"type":"DIVIDEND_ADJUSTMENT","id":"3","time":"2024-03-02T00:00:00Z","instrument":"US30_USD","dividendAdjustment":"-1.2000","quoteDividendAdjustment":"-1.20",
+			"openTradeDividendAdjustments":[{"tradeID":"10","dividendAdjustment":"-1.0000","quoteDividendAdjustment":"-1.00"}]}`,
+		`{"type":"DIVIDEND_ADJUSTMENT","id":"4","time":"2024-03-02T00:00:00Z","instrument":"DE30_EUR","dividendAdjustment":"2.2000","quoteDividendAdjustment":"2.00"}`,
+	} {
+		transaction, err := unmarshalTransaction([]byte(line))
+		if err != nil {
+			t.Fatalf("failed to decode transaction: %v", err)
+		}
+		if err := store.Append(t.Context(), []Transaction{transaction}); err != nil {
+			t.Fatalf("failed to store transaction: %v", err)
+		}
+	}
+
+	report, err := SummarizeDividendAdjustments(store.All(t.Context()))
+	if err != nil {
+		t.Fatalf("failed to summarize dividend adjustments: %v", err)
+	}
+	if report.Total != "-0.5000" {
+		t.Errorf("got total %s, want -0.5000", report.Total)
+	}
+	for instrument, want := range map[InstrumentName]DividendTotals{
+		"US30_USD": {Adjustments: 4, DividendAdjustment: "-2.7000", QuoteDividendAdjustment: "-2.70"},
+		"DE30_EUR": {Adjustments: 1, DividendAdjustment: "2.2000", QuoteDividendAdjustment: "2.00"},
+	} {
+		if got := report.ByInstrument[instrument]; got != want {
+			t.Errorf("got %s %+v, want %+v", instrument, got, want)
+		}
+	}
+	if want := (DividendTotals{Adjustments: 2, DividendAdjustment: "-2.0000", QuoteDividendAdjustment: "-2.00"}); report.ByTrade["10"] != want {
+		t.Errorf("got trade 10 %+v, want %+v", report.ByTrade["10"], want)
+	}
+	if len(report.ByTrade) != 2 {
+		t.Errorf("got %d trades, want 2", len(report.ByTrade))
+	}
+	if len(report.Adjustments) != 5 {
+		t.Fatalf("got %d adjustments, want 5", len(report.Adjustments))
+	}
+	if rest := report.Adjustments[3]; rest.TransactionID != "3" || rest.TradeID != "" || rest.DividendAdjustment != "-0.2000" || rest.QuoteDividendAdjustment != "-0.20" {
+		t.Errorf("got %+v, want the unattributed -0.2000 of transaction 3", rest)
+	}
+}
@@ -0,0 +1,437 @@
+package oanda
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"iter"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// transactionFields holds the fields of a Transaction most used in accounting and analysis.
+// Fields that do not apply to the Transaction's type are empty.
+type transactionFields struct {
+	ID                     TransactionID
+	Time                   time.Time
+	Type                   TransactionType
+	OrderID                OrderID
+	Instrument             InstrumentName
+	Units                  DecimalNumber
+	Price                  PriceValue
+	PL                     AccountUnits
+	Commission             AccountUnits
+	Financing              AccountUnits
+	GuaranteedExecutionFee AccountUnits
+	Amount                 AccountUnits
+	AccountBalance         AccountUnits
+	Reason                 string
+}
+
+// fieldsOf extracts the fields of transaction. Both the pointers returned by the REST endpoints
+// and the values delivered by the Transaction stream are supported.
+func fieldsOf(transaction Transaction) transactionFields {
+	f := transactionFields{ID: transaction.GetID(), Type: transaction.GetType()}
+	if t := transaction.GetTime(); t.Time != nil {
+		f.Time = *t.Time
+	}
+	switch t := transaction.(type) {
+	case OrderFillTransaction:
+		f.fill(&t)
+	case *OrderFillTransaction:
+		f.fill(t)
+	case DailyFinancingTransaction:
+		f.dailyFinancing(&t)
+	case *DailyFinancingTransaction:
+		f.dailyFinancing(t)
+	case TransferFundsTransaction:
+		f.transferFunds(&t)
+	case *TransferFundsTransaction:
+		f.transferFunds(t)
+	case DividendAdjustmentTransaction:
+		f.dividendAdjustment(&t)
+	case *DividendAdjustmentTransaction:
+		f.dividendAdjustment(t)
+	}
+	return f
+}
+
+func (f *transactionFields) fill(t *OrderFillTransaction) {
+	f.OrderID = t.OrderID
+	f.Instrument = t.Instrument
+	f.Units = t.Units
+	f.Price = fillPrice(t)
+	f.PL = t.PL
+	f.Commission = t.Commission
+	f.Financing = t.Financing
+	f.GuaranteedExecutionFee = t.GuaranteedExecutionFee
+	f.AccountBalance = t.AccountBalance
+	f.Reason = string(t.Reason)
+}
+
+func (f *transactionFields) dailyFinancing(t *DailyFinancingTransaction) {
+	f.Financing = t.Financing
+	f.AccountBalance = t.AccountBalance
+}
+
+func (f *transactionFields) transferFunds(t *TransferFundsTransaction) {
+	f.Amount = t.Amount
+	f.AccountBalance = t.AccountBalance
+	f.Reason = string(t.FundingReason)
+}
+
+func (f *transactionFields) dividendAdjustment(t *DividendAdjustmentTransaction) {
+	f.Instrument = t.Instrument
+	f.Amount = t.DividendAdjustment
+	f.AccountBalance = t.AccountBalance
+}
+
+// fillPrice returns the volume-weighted average price of a fill, falling back to the deprecated
+// Price field for fills that do not report it.
+func fillPrice(fill *OrderFillTransaction) PriceValue {
+	if fill.FullVWAP != "" {
+		return fill.FullVWAP
+	}
+	return fill.Price
+}
+
+// TransactionColumn is a column of a Transaction export, such as the CSV written by a
+// [TransactionCSVWriter].
+type TransactionColumn struct {
+	// Header is the name of the column.
+	Header string
+	// Value returns the value of the column for a Transaction, or an empty string if the column
+	// does not apply to it.
+	Value func(Transaction) string
+}
+
+// Columns for Transaction exports. Decimal values are written exactly as OANDA reports them,
+// without a round trip through floating point, and times in RFC 3339 format in UTC.
+var (
+	TransactionColumnID = TransactionColumn{"id", func(t Transaction) string {
+		return t.GetID()
+	}}
+	TransactionColumnTime = TransactionColumn{"time", func(t Transaction) string {
+		if tm := fieldsOf(t).Time; !tm.IsZero() {
+			return tm.UTC().Format(time.RFC3339Nano)
+		}
+		return ""
+	}}
+	TransactionColumnType = TransactionColumn{"type", func(t Transaction) string {
+		return string(t.GetType())
+	}}
+	TransactionColumnOrderID = TransactionColumn{"orderID", func(t Transaction) string {
+		return fieldsOf(t).OrderID
+	}}
+	TransactionColumnInstrument = TransactionColumn{"instrument", func(t Transaction) string {
+		return fieldsOf(t).Instrument
+	}}
+	TransactionColumnUnits = TransactionColumn{"units", func(t Transaction) string {
+		return string(fieldsOf(t).Units)
+	}}
+	TransactionColumnPrice = TransactionColumn{"price", func(t Transaction) string {
+		return string(fieldsOf(t).Price)
+	}}
+	TransactionColumnPL = TransactionColumn{"pl", func(t Transaction) string {
+		return string(fieldsOf(t).PL)
+	}}
+	TransactionColumnCommission = TransactionColumn{"commission", func(t Transaction) string {
+		return string(fieldsOf(t).Commission)
+	}}
+	TransactionColumnFinancing = TransactionColumn{"financing", func(t Transaction) string {
+		return string(fieldsOf(t).Financing)
+	}}
+	TransactionColumnGuaranteedExecutionFee = TransactionColumn{"guaranteedExecutionFee", func(t Transaction) string {
+		return string(fieldsOf(t).GuaranteedExecutionFee)
+	}}
+	TransactionColumnAmount = TransactionColumn{"amount", func(t Transaction) string {
+		return string(fieldsOf(t).Amount)
+	}}
+	TransactionColumnAccountBalance = TransactionColumn{"accountBalance", func(t Transaction) string {
+		return string(fieldsOf(t).AccountBalance)
+	}}
+	TransactionColumnReason = TransactionColumn{"reason", func(t Transaction) string {
+		return fieldsOf(t).Reason
+	}}
+)
+
+// DefaultTransactionColumns returns the columns a [TransactionCSVWriter] writes when none are
+// given: every predefined column.
+func DefaultTransactionColumns() []TransactionColumn {
+	return []TransactionColumn{
+		TransactionColumnID,
+		TransactionColumnTime,
+		TransactionColumnType,
+		TransactionColumnOrderID,
+		TransactionColumnInstrument,
+		TransactionColumnUnits,
+		TransactionColumnPrice,
+		TransactionColumnPL,
+		TransactionColumnCommission,
+		TransactionColumnFinancing,
+		TransactionColumnGuaranteedExecutionFee,
+		TransactionColumnAmount,
+		TransactionColumnAccountBalance,
+		TransactionColumnReason,
+	}
+}
+
+// TransactionCSVWriter writes Transactions as CSV, one row per Transaction below a header row.
+// It covers order fills, daily financing, fund transfers and dividend adjustments; other
+// Transactions only fill the columns common to all Transactions. Create one with
+// [NewTransactionCSVWriter].
+type TransactionCSVWriter struct {
+	w             *csv.Writer
+	columns       []TransactionColumn
+	headerWritten bool
+}
+
+// NewTransactionCSVWriter creates a new TransactionCSVWriter writing the given columns to w,
+// or [DefaultTransactionColumns] if none are given.
+func NewTransactionCSVWriter(w io.Writer, columns ...TransactionColumn) *TransactionCSVWriter {
+	if len(columns) == 0 {
+		columns = DefaultTransactionColumns()
+	}
+	return &TransactionCSVWriter{w: csv.NewWriter(w), columns: columns}
+}
+
+func (w *TransactionCSVWriter) writeHeader() error {
+	if w.headerWritten {
+		return nil
+	}
+	w.headerWritten = true
+	header := make([]string, len(w.columns))
+	for i, column := range w.columns {
+		header[i] = column.Header
+	}
+	return w.w.Write(header)
+}
+
+// Write writes a row for transaction, preceded by the header row if it is the first one.
+// Rows are buffered; call Flush when done.
+func (w *TransactionCSVWriter) Write(transaction Transaction) error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	row := make([]string, len(w.columns))
+	for i, column := range w.columns {
+		row[i] = column.Value(transaction)
+	}
+	return w.w.Write(row)
+}
+
+// WriteAll writes a row for every Transaction of transactions, such as the iterator returned by
+// [transactionService.Iterate], and flushes the writer. It stops at the first error.
+func (w *TransactionCSVWriter) WriteAll(transactions iter.Seq2[Transaction, error]) error {
+	for transaction, err := range transactions {
+		if err != nil {
+			return err
+		}
+		if err := w.Write(transaction); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// Flush writes any buffered rows, and the header row if no Transaction has been written.
+func (w *TransactionCSVWriter) Flush() error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	w.w.Flush()
+	return w.w.Error()
+}
+
+// TransactionJSONLWriter writes Transactions as JSON Lines, one Transaction per line exactly as
+// received from OANDA, for ingestion into data lakes and log pipelines. Create one with
+// [NewTransactionJSONLWriter].
+type TransactionJSONLWriter struct {
+	w *bufio.Writer
+}
+
+// NewTransactionJSONLWriter creates a new TransactionJSONLWriter writing to w.
+func NewTransactionJSONLWriter(w io.Writer) *TransactionJSONLWriter {
+	return &TransactionJSONLWriter{w: bufio.NewWriter(w)}
+}
+
+// Write writes a line for transaction. Lines are buffered; call Flush when done.
+func (w *TransactionJSONLWriter) Write(transaction Transaction) error {
+	line, err := transactionJSON(transaction)
+	if err != nil {
+		return fmt.Errorf("failed to encode transaction %s: %w", transaction.GetID(), err)
+	}
+	if _, err := w.w.Write(line); err != nil {
+		return err
+	}
+	return w.w.WriteByte('\n')
+}
+
+// WriteAll writes a line for every Transaction of transactions and flushes the writer. It stops
+// at the first error.
+func (w *TransactionJSONLWriter) WriteAll(transactions iter.Seq2[Transaction, error]) error {
+	for transaction, err := range transactions {
+		if err != nil {
+			return err
+		}
+		if err := w.Write(transaction); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// Flush writes any buffered lines.
+func (w *TransactionJSONLWriter) Flush() error {
+	return w.w.Flush()
+}
+
+// OrderFillRecord is a flat, typed row of the OrderFillTransaction fields most used in analysis,
+// suitable for columnar formats. The json tags give the field names for JSON Lines, and the
+// parquet tags the column names of [OrderFillParquetSchema] and of the files written by an
+// [OrderFillParquetWriter].
+type OrderFillRecord struct {
+	ID                     TransactionID  `json:"id" parquet:"id"`
+	Time                   time.Time      `json:"time" parquet:"time"`
+	OrderID                OrderID        `json:"orderID" parquet:"orderID"`
+	Instrument             InstrumentName `json:"instrument" parquet:"instrument"`
+	Units                  float64        `json:"units" parquet:"units"`
+	Price                  float64        `json:"price" parquet:"price"`
+	PL                     float64        `json:"pl" parquet:"pl"`
+	Commission             float64        `json:"commission" parquet:"commission"`
+	Financing              float64        `json:"financing" parquet:"financing"`
+	GuaranteedExecutionFee float64        `json:"guaranteedExecutionFee" parquet:"guaranteedExecutionFee"`
+	AccountBalance         float64        `json:"accountBalance" parquet:"accountBalance"`
+	Reason                 string         `json:"reason" parquet:"reason"`
+}
+
+// OrderFillRecords returns an iterator over the OrderFillRecords of the fills among
+// transactions, such as the iterator returned by [transactionService.Iterate]. Other
+// Transactions are skipped.
+func OrderFillRecords(transactions iter.Seq2[Transaction, error]) iter.Seq2[OrderFillRecord, error] {
+	return func(yield func(OrderFillRecord, error) bool) {
+		for transaction, err := range transactions {
+			if err != nil {
+				yield(OrderFillRecord{}, err)
+				return
+			}
+			if transaction.GetType() != TransactionTypeOrderFill {
+				continue
+			}
+			record, err := orderFillRecord(fieldsOf(transaction))
+			if err != nil {
+				yield(OrderFillRecord{}, fmt.Errorf("failed to convert transaction %s: %w", transaction.GetID(), err))
+				return
+			}
+			if !yield(record, nil) {
+				return
+			}
+		}
+	}
+}
+
+func orderFillRecord(f transactionFields) (OrderFillRecord, error) {
+	record := OrderFillRecord{
+		ID:         f.ID,
+		Time:       f.Time,
+		OrderID:    f.OrderID,
+		Instrument: f.Instrument,
+		Reason:     f.Reason,
+	}
+	for _, v := range []struct {
+		dst *float64
+		src string
+	}{
+		{&record.Units, string(f.Units)},
+		{&record.Price, string(f.Price)},
+		{&record.PL, string(f.PL)},
+		{&record.Commission, string(f.Commission)},
+		{&record.Financing, string(f.Financing)},
+		{&record.GuaranteedExecutionFee, string(f.GuaranteedExecutionFee)},
+		{&record.AccountBalance, string(f.AccountBalance)},
+	} {
+		if v.src == "" {
+			continue
+		}
+		n, err := parseFloat(v.src)
+		if err != nil {
+			return OrderFillRecord{}, err
+		}
+		*v.dst = n
+	}
+	return record, nil
+}
+
+// OrderFillParquetSchema returns the Parquet schema of [OrderFillRecord] in the message type
+// notation accepted by Parquet tooling, as written by an [OrderFillParquetWriter]. Strings are
+// UTF-8 byte arrays, numbers doubles and times UTC timestamps in microseconds.
+func OrderFillParquetSchema() string {
+	return parquetSchema(reflect.TypeFor[OrderFillRecord]())
+}
+
+// parquetSchema returns the Parquet message type of the struct type t, whose fields are named by
+// their parquet tags.
+func parquetSchema(t reflect.Type) string {
+	columns, err := parquetColumns(t)
+	if err != nil {
+		panic(err)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "message %s {\n", t.Name())
+	for _, column := range columns {
+		switch {
+		case column.converted == parquetConvertedTimestampMicros:
+			fmt.Fprintf(&b, "  required int64 %s (TIMESTAMP(MICROS,true));\n", column.name)
+		case column.physical == parquetDouble:
+			fmt.Fprintf(&b, "  required double %s;\n", column.name)
+		case column.physical == parquetInt64:
+			fmt.Fprintf(&b, "  required int64 %s;\n", column.name)
+		case column.physical == parquetBoolean:
+			fmt.Fprintf(&b, "  required boolean %s;\n", column.name)
+		case column.physical == parquetByteArray:
+			fmt.Fprintf(&b, "  required binary %s (STRING);\n", column.name)
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// OrderFillParquetWriter writes OrderFillRecords as a Parquet file with the schema of
+// [OrderFillParquetSchema]: uncompressed, PLAIN-encoded columns, readable by pandas, Spark and
+// other Parquet readers. Records are written in row groups of 65536, so memory use stays
+// bounded however long the history is. Create one with [NewOrderFillParquetWriter].
+type OrderFillParquetWriter struct {
+	w *parquetWriter[OrderFillRecord]
+}
+
+// NewOrderFillParquetWriter creates a new OrderFillParquetWriter writing to w.
+func NewOrderFillParquetWriter(w io.Writer) *OrderFillParquetWriter {
+	return &OrderFillParquetWriter{w: newParquetWriter[OrderFillRecord](w)}
+}
+
+// Write writes record. Records are buffered; call Close when done.
+func (w *OrderFillParquetWriter) Write(record OrderFillRecord) error {
+	return w.w.write(record)
+}
+
+// WriteAll writes every record of records, such as those returned by [OrderFillRecords], and
+// closes the writer. It stops at the first error.
+func (w *OrderFillParquetWriter) WriteAll(records iter.Seq2[OrderFillRecord, error]) error {
+	for record, err := range records {
+		if err != nil {
+			return err
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	return w.Close()
+}
+
+// Close writes the buffered records and the file footer. It does not close the underlying
+// writer. The file is not valid until Close has returned.
+func (w *OrderFillParquetWriter) Close() error {
+	return w.w.close()
+}
@@ -0,0 +1,142 @@
+package oanda
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTransactionCSVWriter(t *testing.T) {
+	var transactions []Transaction
+	for _, line := range []string{
+		`{"type":"ORDER_FILL","id":"10","time":"2024-01-02T03:04:05.123456789Z","orderID":"9","instrument":"EUR_USD","units":"100","price":"1.10010","fullVWAP":"1.10005","pl":"0.0000","commission":"0.0000","financing":"0.0000","guaranteedExecutionFee":"0.0000","accountBalance":"1000.1234","reason":"MARKET_ORDER"}`,
+		`{"type":"DAILY_FINANCING","id":"11","time":"2024-01-02T21:00:00.000000000Z","financing":"-0.0123","accountBalance":"1000.1111"}`,
+		`{"type":"TRANSFER_FUNDS","id":"12","time":"2024-01-03T00:00:00.000000000Z","amount":"500.0000","fundingReason":"CLIENT_FUNDING","comment":"deposit, January","accountBalance":"1500.1111"}`,
+		`{"type":"MARKET_ORDER","id":"13","time":"2024-01-03T01:00:00.000000000Z","instrument":"EUR_USD","units":"-100"}`,
+	} {
+		transaction, err := unmarshalTransaction([]byte(line))
+		if err != nil {
+			t.Fatalf("failed to decode transaction: %v", err)
+		}
+		transactions = append(transactions, transaction)
+	}
+
+	var buf strings.Builder
+	w := NewTransactionCSVWriter(&buf)
+	for _, transaction := range transactions {
+		if err := w.Write(transaction); err != nil {
+			t.Fatalf("failed to write transaction: %v", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("failed to flush: %v", err)
+	}
+	want := `id,time,type,orderID,instrument,units,price,pl,commission,financing,guaranteedExecutionFee,amount,accountBalance,reason
+10,2024-01-02T03:04:05.123456789Z,ORDER_FILL,9,EUR_USD,100,1.10005,0.0000,0.0000,0.0000,0.0000,,1000.1234,MARKET_ORDER
+11,2024-01-02T21:00:00Z,DAILY_FINANCING,,,,,,,-0.0123,,,1000.1111,
+12,2024-01-03T00:00:00Z,TRANSFER_FUNDS,,,,,,,,,500.0000,1500.1111,CLIENT_FUNDING
+13,2024-01-03T01:00:00Z,MARKET_ORDER,,,,,,,,,,,
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got CSV\n%s\nwant\n%s", got, want)
+	}
+
+	buf.Reset()
+	comment := TransactionColumn{"comment", func(t Transaction) string {
+		if transfer, ok := t.(*TransferFundsTransaction); ok {
+			return transfer.Comment
+		}
+		return ""
+	}}
+	w = NewTransactionCSVWriter(&buf, TransactionColumnID, TransactionColumnAmount, comment)
+	if err := w.WriteAll(NewMemoryTransactionStore().All(t.Context())); err != nil {
+		t.Fatalf("failed to write empty history: %v", err)
+	}
+	if got, want := buf.String(), "id,amount,comment\n"; got != want {
+		t.Errorf("got CSV %q for empty history, want %q", got, want)
+	}
+	buf.Reset()
+	w = NewTransactionCSVWriter(&buf, TransactionColumnID, TransactionColumnAmount, comment)
+	if err := w.Write(transactions[2]); err != nil {
+		t.Fatalf("failed to write transaction: %v", err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("failed to flush: %v", err)
+	}
+	if got, want := buf.String(), "id,amount,comment\n12,500.0000,\"deposit, January\"\n"; got != want {
+		t.Errorf("got CSV %q with custom columns, want %q", got, want)
+	}
+}
+
+func TestTransactionJSONLWriter(t *testing.T) {
+	store := NewMemoryTransactionStore()
+	for _, line := range []string{
+		`{"type":"ORDER_FILL", "id":"10","time":"2024-01-02T03:04:05.000000000Z","orderID":"9","instrument":"EUR_USD","units":"100","fullVWAP":"1.10005","pl":"1.5000","commission":"0.0100","financing":"-0.0200","accountBalance":"1000.1234","reason":"MARKET_ORDER","extra":true}`,
+		`{"type":"DAILY_FINANCING","id":"11","financing":"-0.0123"}`,
+	} {
+		transaction, err := unmarshalTransaction([]byte(line))
+		if err != nil {
+			t.Fatalf("failed to decode transaction: %v", err)
+		}
+		if err := store.Append(t.Context(), []Transaction{transaction}); err != nil {
+			t.Fatalf("failed to store transaction: %v", err)
+		}
+	}
+
+	var buf strings.Builder
+	if err := NewTransactionJSONLWriter(&buf).WriteAll(store.All(t.Context())); err != nil {
+		t.Fatalf("failed to write transactions: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], `{"type":"ORDER_FILL","id":"10"`) || !strings.Contains(lines[0], `"extra":true`) {
+		t.Errorf("got JSON Lines %q, want the transactions as received", buf.String())
+	}
+
+	var records []OrderFillRecord
+	for record, err := range OrderFillRecords(store.All(t.Context())) {
+		if err != nil {
+			t.Fatalf("failed to convert transactions: %v", err)
+		}
+		records = append(records, record)
+	}
+	want := OrderFillRecord{
+		ID:             "10",
+		Time:           time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		OrderID:        "9",
+		Instrument:     "EUR_USD",
+		Units:          100,
+		Price:          1.10005,
+		PL:             1.5,
+		Commission:     0.01,
+		Financing:      -0.02,
+		AccountBalance: 1000.1234,
+		Reason:         "MARKET_ORDER",
+	}
+	if len(records) != 1 || records[0] != want {
+		t.Errorf("got records %+v, want %+v", records, want)
+	}
+
+	schema := OrderFillParquetSchema()
+	for _, column := range []string{
+		"required binary instrument (STRING);",
+		"required double units;",
+		"required double pl;",
+		"required double commission;",
+		"required double financing;",
+		"required int64 time (TIMESTAMP(MICROS,true));",
+	} {
+		if !strings.Contains(schema, column) {
+			t.Errorf("schema %s is missing %q", schema, column)
+		}
+	}
+
+	var file bytes.Buffer
+	if err := NewOrderFillParquetWriter(&file).WriteAll(OrderFillRecords(store.All(t.Context()))); err != nil {
+		t.Fatalf("failed to write parquet file: %v", err)
+	}
+	if !bytes.HasPrefix(file.Bytes(), []byte("PAR1")) || !bytes.HasSuffix(file.Bytes(), []byte("PAR1")) ||
+		!bytes.Contains(file.Bytes(), []byte("EUR_USD")) {
+		t.Errorf("got parquet file %q", file.Bytes())
+	}
+}
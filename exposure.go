@@ -0,0 +1,187 @@
+package oanda
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+)
+
+// CurrencyExposure is the net exposure of an Account to one currency, summed over its open
+// Positions. For Instruments that are not currency pairs, such as CFDs, the base is the asset
+// traded, such as SPX500.
+type CurrencyExposure struct {
+	// Currency is the currency.
+	Currency Currency
+	// Net is the net amount of the currency held: positive when long and negative when short.
+	// A long EUR_USD Position of 1000 units adds 1000 EUR and the value of 1000 EUR in USD
+	// at the current price, negated.
+	Net float64
+	// Home is Net in the Account's home currency, converted with the PositionValue factor, or 0
+	// if the exposure was computed without home conversions. OANDA has no home conversion for
+	// the base of a CFD, so its Net is valued at the current price in its quote currency, which
+	// is then converted.
+	Home float64
+	// Instruments are the Instruments of the Positions that contribute to the exposure, sorted.
+	Instruments []InstrumentName
+}
+
+// CurrencyExposures maps currencies to the net exposure of an Account to them, returned by
+// [NetExposure] and [positionService.NetExposure].
+type CurrencyExposures map[Currency]CurrencyExposure
+
+// Sorted returns the exposures from the largest absolute home value to the smallest, then by
+// currency, such as to find the exposures worth hedging first.
+func (e CurrencyExposures) Sorted() []CurrencyExposure {
+	exposures := make([]CurrencyExposure, 0, len(e))
+	for _, exposure := range e {
+		exposures = append(exposures, exposure)
+	}
+	slices.SortFunc(exposures, func(a, b CurrencyExposure) int {
+		return cmp.Or(cmp.Compare(math.Abs(b.Home), math.Abs(a.Home)), strings.Compare(string(a.Currency), string(b.Currency)))
+	})
+	return exposures
+}
+
+// GrossHome returns the sum of the absolute home values of the exposures.
+func (e CurrencyExposures) GrossHome() float64 {
+	gross := 0.0
+	for _, exposure := range e {
+		gross += math.Abs(exposure.Home)
+	}
+	return gross
+}
+
+// NetExposure computes the net exposure to every currency of positions, such as those of
+// [positionService.ListOpen], combining the Positions that share a currency, such as long EUR
+// from both EUR_USD and EUR_JPY. The quote currency amounts are valued at the midpoint of the
+// prices of pricing. If pricing was requested with
+// [PriceInformationRequest.SetIncludeHomeConversions], the exposures are also converted into
+// the home currency. It returns an error if pricing has no price for an Instrument, or no home
+// conversion for a quote currency when it has home conversions.
+func NetExposure(positions []Position, pricing *PriceInformationResponse) (CurrencyExposures, error) {
+	exposures := make(CurrencyExposures)
+	// values holds the value of the base amounts in each quote currency, used to value the bases
+	// that have no home conversion, such as those of CFDs.
+	values := make(map[Currency]map[Currency]float64)
+	add := func(currency Currency, amount float64, instrument InstrumentName) {
+		exposure := exposures[currency]
+		exposure.Currency = currency
+		exposure.Net += amount
+		if !slices.Contains(exposure.Instruments, instrument) {
+			exposure.Instruments = append(exposure.Instruments, instrument)
+		}
+		exposures[currency] = exposure
+	}
+	for _, position := range positions {
+		units := 0.0
+		for _, side := range []DecimalNumber{position.Long.Units, position.Short.Units} {
+			if side == "" {
+				continue
+			}
+			u, err := side.Float64()
+			if err != nil {
+				return nil, fmt.Errorf("invalid units of position %s: %w", position.Instrument, err)
+			}
+			units += u
+		}
+		if units == 0 {
+			continue
+		}
+		base, quote, ok := strings.Cut(position.Instrument, "_")
+		if !ok {
+			return nil, fmt.Errorf("invalid instrument name %q", position.Instrument)
+		}
+		price, ok := pricing.PriceFor(position.Instrument)
+		if !ok {
+			return nil, fmt.Errorf("no price for %s", position.Instrument)
+		}
+		mid, err := price.Mid()
+		if err != nil {
+			return nil, fmt.Errorf("invalid price for %s: %w", position.Instrument, err)
+		}
+		m, err := mid.Float64()
+		if err != nil {
+			return nil, fmt.Errorf("invalid price for %s: %w", position.Instrument, err)
+		}
+		add(Currency(base), units, position.Instrument)
+		add(Currency(quote), -units*m, position.Instrument)
+		if values[Currency(base)] == nil {
+			values[Currency(base)] = make(map[Currency]float64)
+		}
+		values[Currency(base)][Currency(quote)] += units * m
+	}
+	if len(pricing.HomeConversions) == 0 {
+		for currency, exposure := range exposures {
+			slices.Sort(exposure.Instruments)
+			exposures[currency] = exposure
+		}
+		return exposures, nil
+	}
+	factor := func(currency Currency) (float64, bool, error) {
+		conversions, ok := pricing.HomeConversionsFor(currency)
+		if !ok {
+			return 0, false, nil
+		}
+		f, err := conversions.PositionValue.Float64()
+		if err != nil {
+			return 0, false, fmt.Errorf("invalid home conversion factor for %s: %w", currency, err)
+		}
+		return f, true, nil
+	}
+	for currency, exposure := range exposures {
+		slices.Sort(exposure.Instruments)
+		f, ok, err := factor(currency)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case ok:
+			exposure.Home = exposure.Net * f
+		case values[currency] != nil:
+			for quote, value := range values[currency] {
+				f, ok, err := factor(quote)
+				if err != nil {
+					return nil, err
+				}
+				if !ok {
+					return nil, fmt.Errorf("no home conversion factor for %s", quote)
+				}
+				exposure.Home += value * f
+			}
+		default:
+			return nil, fmt.Errorf("no home conversion factor for %s", currency)
+		}
+		exposures[currency] = exposure
+	}
+	return exposures, nil
+}
+
+// NetExposure computes the net exposure of the Account configured via WithAccountID to every
+// currency with [NetExposure], from its open Positions and their current prices and home
+// conversions.
+//
+// This corresponds to the OANDA API endpoints: GET /v3/accounts/{accountID}/openPositions and
+// GET /v3/accounts/{accountID}/pricing
+//
+// Reference: https://developer.oanda.com/rest-live-v20/position-ep/#collapse_endpoint_2
+func (s *positionService) NetExposure(ctx context.Context) (CurrencyExposures, error) {
+	positions, err := s.ListOpen(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list open positions: %w", err)
+	}
+	if len(positions.Positions) == 0 {
+		return make(CurrencyExposures), nil
+	}
+	req := NewPriceInformationRequest().SetIncludeHomeConversions()
+	for _, position := range positions.Positions {
+		req.AddInstruments(position.Instrument)
+	}
+	pricing, err := s.client.Price.Information(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get prices: %w", err)
+	}
+	return NetExposure(positions.Positions, pricing)
+}
@@ -0,0 +1,107 @@
+package oanda
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestPositionService_NetExposure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v3/accounts/1/openPositions":
+			_, _ = fmt.Fprint(w, `{"positions":[
+				{"instrument":"EUR_USD","long":{"units":"1000"},"short":{"units":"0"}},
+				{"instrument":"EUR_JPY","long":{"units":"2000"},"short":{"units":"-500"}},
+				{"instrument":"USD_JPY","long":{"units":"0"},"short":{"units":"-1000"}}]}`)
+		case "/v3/accounts/1/pricing":
+			if r.URL.Query().Get("instruments") != "EUR_USD,EUR_JPY,USD_JPY" || r.URL.Query().Get("includeHomeConversions") != "true" {
+				t.Errorf("unexpected query %s", r.URL.RawQuery)
+			}
+			_, _ = fmt.Fprint(w, `{"prices":[
+				{"instrument":"EUR_USD","bids":[{"price":"1.0999","liquidity":1000000}],"asks":[{"price":"1.1001","liquidity":1000000}]},
+				{"instrument":"EUR_JPY","bids":[{"price":"159.99","liquidity":1000000}],"asks":[{"price":"160.01","liquidity":1000000}]},
+				{"instrument":"USD_JPY","bids":[{"price":"149.99","liquidity":1000000}],"asks":[{"price":"150.01","liquidity":1000000}]}],
+				"homeConversions":[
+				{"currency":"EUR","accountGain":"1.1","accountLoss":"1.1","positionValue":"1.1"},
+				{"currency":"USD","accountGain":"1","accountLoss":"1","positionValue":"1"},
+				{"currency":"JPY","accountGain":"0.0067","accountLoss":"0.0067","positionValue":"0.0067"}]}`)
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+	client := NewClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+
+	exposures, err := client.Position.NetExposure(t.Context())
+	if err != nil {
+		t.Fatalf("failed to compute net exposure: %v", err)
+	}
+	want := []struct {
+		currency  Currency
+		net, home float64
+	}{
+		{"EUR", 2500, 2750},
+		{"USD", -2100, -2100},
+		{"JPY", -90000, -603},
+	}
+	sorted := exposures.Sorted()
+	if len(sorted) != len(want) {
+		t.Fatalf("got exposures %+v", sorted)
+	}
+	for i, w := range want {
+		got := sorted[i]
+		if got.Currency != w.currency || math.Abs(got.Net-w.net) > 1e-6 || math.Abs(got.Home-w.home) > 1e-6 {
+			t.Errorf("got exposure %+v, want %s %v (%v home)", got, w.currency, w.net, w.home)
+		}
+	}
+	if got := exposures["EUR"].Instruments; !slices.Equal(got, []InstrumentName{"EUR_JPY", "EUR_USD"}) {
+		t.Errorf("got EUR instruments %v", got)
+	}
+	if gross := exposures.GrossHome(); math.Abs(gross-5453) > 1e-6 {
+		t.Errorf("got gross %v, want 5453", gross)
+	}
+
+	if _, err := NetExposure([]Position{{Instrument: "GBP_USD", Long: PositionSide{Units: "10"}}}, &PriceInformationResponse{}); err == nil {
+		t.Error("got no error for a missing price")
+	}
+}
+
+func TestNetExposure_CFD(t *testing.T) {
+	positions := []Position{
+		{Instrument: "SPX500_USD", Long: PositionSide{Units: "2"}, Short: PositionSide{Units: "0"}},
+		{Instrument: "DE30_EUR", Long: PositionSide{Units: "0"}, Short: PositionSide{Units: "-1"}},
+	}
+	var pricing PriceInformationResponse
+	if err := json.Unmarshal([]byte(`{"prices":[
+		{"instrument":"SPX500_USD","bids":[{"price":"4999","liquidity":100}],"asks":[{"price":"5001","liquidity":100}]},
+		{"instrument":"DE30_EUR","bids":[{"price":"17999","liquidity":100}],"asks":[{"price":"18001","liquidity":100}]}],
+		"homeConversions":[
+		{"currency":"USD","accountGain":"1","accountLoss":"1","positionValue":"1"},
+		{"currency":"EUR","accountGain":"1.1","accountLoss":"1.1","positionValue":"1.1"}]}`), &pricing); err != nil {
+		t.Fatal(err)
+	}
+	exposures, err := NetExposure(positions, &pricing)
+	if err != nil {
+		t.Fatalf("failed to compute net exposure: %v", err)
+	}
+	want := map[Currency]struct{ net, home float64 }{
+		"SPX500": {2, 10000},
+		"USD":    {-10000, -10000},
+		"DE30":   {-1, -19800},
+		"EUR":    {18000, 19800},
+	}
+	if len(exposures) != len(want) {
+		t.Fatalf("got exposures %+v", exposures)
+	}
+	for currency, w := range want {
+		got := exposures[currency]
+		if math.Abs(got.Net-w.net) > 1e-6 || math.Abs(got.Home-w.home) > 1e-6 {
+			t.Errorf("got exposure %+v, want %s %v (%v home)", got, currency, w.net, w.home)
+		}
+	}
+}
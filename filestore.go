@@ -0,0 +1,115 @@
+package oanda
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// jsonFileStore keeps values of type V keyed by K as a JSON object in a file, for the file-backed
+// stores of the package such as [FileOCOStore]. Every change reads the file, applies the change
+// and writes the file again, so it suits the small sets of records the stores keep.
+type jsonFileStore[K comparable, V any] struct {
+	path string
+	// name describes the store in errors, e.g. "OCO store".
+	name string
+	mu   sync.Mutex
+}
+
+func newJSONFileStore[K comparable, V any](path, name string) *jsonFileStore[K, V] {
+	return &jsonFileStore[K, V]{path: path, name: name}
+}
+
+// save stores or updates the value of key.
+func (s *jsonFileStore[K, V]) save(key K, value V) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	values, err := s.read()
+	if err != nil {
+		return err
+	}
+	values[key] = value
+	return s.write(values)
+}
+
+// delete removes the value of key. Deleting an unknown key is not an error.
+func (s *jsonFileStore[K, V]) delete(key K) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	values, err := s.read()
+	if err != nil {
+		return err
+	}
+	delete(values, key)
+	return s.write(values)
+}
+
+// load returns all stored values, in no particular order.
+func (s *jsonFileStore[K, V]) load() ([]V, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	values, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]V, 0, len(values))
+	for _, value := range values {
+		result = append(result, value)
+	}
+	return result, nil
+}
+
+// read returns the stored values. A missing file is an empty store.
+func (s *jsonFileStore[K, V]) read() (map[K]V, error) {
+	values := make(map[K]V)
+	b, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return values, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &values); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", s.name, err)
+	}
+	return values, nil
+}
+
+// write writes values to a temporary file, syncs it to disk and renames it over the store's
+// file, so that a crash leaves either the previous or the new contents. The directory is then
+// synced so that the rename itself survives a crash, where the platform supports it.
+func (s *jsonFileStore[K, V]) write(values map[K]V) error {
+	b, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(s.path)
+	f, err := os.CreateTemp(dir, filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(b)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), s.path)
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return fmt.Errorf("failed to write %s: %w", s.name, err)
+	}
+	if d, err := os.Open(dir); err == nil {
+		// Syncing a directory is not supported on every platform, and the file is already
+		// written, so errors are ignored.
+		_ = d.Sync()
+		_ = d.Close()
+	}
+	return nil
+}
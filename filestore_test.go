@@ -0,0 +1,44 @@
+package oanda
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestJSONFileStore(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "store.json")
+	store := newJSONFileStore[string, int](path, "test store")
+
+	if values, err := store.load(); err != nil || len(values) != 0 {
+		t.Fatalf("got %v (%v) from a missing file, want an empty store", values, err)
+	}
+	for key, value := range map[string]int{"a": 1, "b": 2, "c": 3} {
+		if err := store.save(key, value); err != nil {
+			t.Fatalf("failed to save %s: %v", key, err)
+		}
+	}
+	if err := store.delete("b"); err != nil {
+		t.Fatalf("failed to delete: %v", err)
+	}
+	if err := store.delete("unknown"); err != nil {
+		t.Errorf("got error %v deleting an unknown key", err)
+	}
+	values, err := newJSONFileStore[string, int](path, "test store").load()
+	slices.Sort(values)
+	if err != nil || !slices.Equal(values, []int{1, 3}) {
+		t.Errorf("got %v (%v), want [1 3]", values, err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("got %d files, want the store only without temporary files", len(entries))
+	}
+
+	if err := os.WriteFile(path, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.load(); err == nil {
+		t.Error("got no error for a corrupt file")
+	}
+}
@@ -0,0 +1,261 @@
+package oanda
+
+import (
+	"iter"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// FinancingTotals is the financing paid (negative) or collected (positive) in the Account's home
+// currency.
+type FinancingTotals struct {
+	// Daily is the financing of DailyFinancingTransactions.
+	Daily AccountUnits
+	// OnClose is the financing of Order fills that closed or reduced Trades.
+	OnClose AccountUnits
+	// Total is the sum of Daily and OnClose.
+	Total AccountUnits
+}
+
+// FinancingKey identifies one side of the Position in an Instrument.
+type FinancingKey struct {
+	// Instrument is the Instrument of the Position.
+	Instrument InstrumentName
+	// Direction is the side of the Position, or empty if the financing is of Trades whose side is
+	// unknown because they were opened before the first Transaction summarized.
+	Direction Direction
+}
+
+// FinancingReport is the financing of an Account over a period, in total, by Instrument and by
+// side of each Position. Create one with [SummarizeFinancing].
+type FinancingReport struct {
+	// Total is the financing of all Positions.
+	Total FinancingTotals
+	// ByInstrument is the financing of the Position in each Instrument.
+	ByInstrument map[InstrumentName]FinancingTotals
+	// BySide is the financing of each side of the Position in each Instrument.
+	BySide map[FinancingKey]FinancingTotals
+}
+
+// SummarizeFinancing sums the financing of the DailyFinancingTransactions and Order fills among
+// transactions, such as the iterator returned by [transactionService.Iterate], that were created
+// within [from, to). A zero from or to leaves that end of the period open.
+//
+// The side of a Trade financed daily is learnt from the fill that opened it, so transactions
+// should start before the period where possible: Transactions created before from are used for
+// that purpose only. Daily financing of Trades opened before the first Transaction is reported
+// with an empty Direction.
+func SummarizeFinancing(transactions iter.Seq2[Transaction, error], from, to time.Time) (*FinancingReport, error) {
+	s := financingSummary{
+		directions:   make(map[TradeID]Direction),
+		byInstrument: make(map[InstrumentName]*financingSums),
+		bySide:       make(map[FinancingKey]*financingSums),
+	}
+	for transaction, err := range transactions {
+		if err != nil {
+			return nil, err
+		}
+		var created time.Time
+		if t := transaction.GetTime(); t.Time != nil {
+			created = *t.Time
+		}
+		inPeriod := (from.IsZero() || !created.Before(from)) && (to.IsZero() || created.Before(to))
+		if err := s.addTransaction(transaction, inPeriod); err != nil {
+			return nil, err
+		}
+	}
+	return s.report(), nil
+}
+
+// financingSummary accumulates the financing of a [FinancingReport].
+type financingSummary struct {
+	directions   map[TradeID]Direction
+	total        financingSums
+	byInstrument map[InstrumentName]*financingSums
+	bySide       map[FinancingKey]*financingSums
+}
+
+func (s *financingSummary) addTransaction(transaction Transaction, inPeriod bool) error {
+	switch t := transaction.(type) {
+	case OrderFillTransaction:
+		return s.addFill(&t, inPeriod)
+	case *OrderFillTransaction:
+		return s.addFill(t, inPeriod)
+	case DailyFinancingTransaction:
+		return s.addDaily(&t, inPeriod)
+	case *DailyFinancingTransaction:
+		return s.addDaily(t, inPeriod)
+	}
+	return nil
+}
+
+func (s *financingSummary) addFill(fill *OrderFillTransaction, inPeriod bool) error {
+	if opened := fill.TradeOpened; opened != nil {
+		s.directions[opened.TradeID] = directionOf(opened.Units)
+	}
+	if !inPeriod {
+		return nil
+	}
+	// A fill closes Trades in the direction opposite to its own.
+	closed := DirectionShort
+	if directionOf(fill.Units) == DirectionShort {
+		closed = DirectionLong
+	}
+	reduces := fill.TradesClosed
+	if fill.TradeReduced != nil {
+		reduces = append(reduces[:len(reduces):len(reduces)], *fill.TradeReduced)
+	}
+	for _, reduce := range reduces {
+		if err := s.add(FinancingKey{fill.Instrument, closed}, reduce.Financing, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *financingSummary) addDaily(daily *DailyFinancingTransaction, inPeriod bool) error {
+	if !inPeriod {
+		return nil
+	}
+	for _, position := range daily.PositionFinancings {
+		if len(position.OpenTradeFinancings) == 0 {
+			if err := s.add(FinancingKey{Instrument: position.Instrument}, position.Financing, true); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, trade := range position.OpenTradeFinancings {
+			key := FinancingKey{position.Instrument, s.directions[trade.TradeID]}
+			if err := s.add(key, trade.Financing, true); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *financingSummary) add(key FinancingKey, financing AccountUnits, daily bool) error {
+	if financing == "" {
+		return nil
+	}
+	amount, err := financing.Rat()
+	if err != nil {
+		return err
+	}
+	places := fractionDigits(string(financing))
+	for _, sums := range []*financingSums{&s.total, entry(s.byInstrument, key.Instrument), entry(s.bySide, key)} {
+		sums.add(amount, places, daily)
+	}
+	return nil
+}
+
+func (s *financingSummary) report() *FinancingReport {
+	report := &FinancingReport{
+		Total:        s.total.totals(),
+		ByInstrument: make(map[InstrumentName]FinancingTotals, len(s.byInstrument)),
+		BySide:       make(map[FinancingKey]FinancingTotals, len(s.bySide)),
+	}
+	for instrument, sums := range s.byInstrument {
+		report.ByInstrument[instrument] = sums.totals()
+	}
+	for key, sums := range s.bySide {
+		report.BySide[key] = sums.totals()
+	}
+	return report
+}
+
+// financingSums sums daily and on-close financing exactly.
+type financingSums struct {
+	daily   big.Rat
+	onClose big.Rat
+	places  int
+}
+
+func (s *financingSums) add(amount *big.Rat, places int, daily bool) {
+	if daily {
+		s.daily.Add(&s.daily, amount)
+	} else {
+		s.onClose.Add(&s.onClose, amount)
+	}
+	s.places = max(s.places, places)
+}
+
+func (s *financingSums) totals() FinancingTotals {
+	total := new(big.Rat).Add(&s.daily, &s.onClose)
+	return FinancingTotals{
+		Daily:   AccountUnits(s.daily.FloatString(s.places)),
+		OnClose: AccountUnits(s.onClose.FloatString(s.places)),
+		Total:   AccountUnits(total.FloatString(s.places)),
+	}
+}
+
+// directionOf returns the direction of a Trade or fill of units.
+func directionOf(units DecimalNumber) Direction {
+	if len(units) > 0 && units[0] == '-' {
+		return DirectionShort
+	}
+	return DirectionLong
+}
+
+// Weekday returns the time.Weekday of d, and false if d is not a valid day of the week.
+func (d DayOfWeek) Weekday() (time.Weekday, bool) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(day.String(), string(d)) {
+			return day, true
+		}
+	}
+	return 0, false
+}
+
+// DaysCharged returns the number of days of financing charged at the rollover on day, such as 3
+// on the day that covers the weekend. It is 0 for days not listed in FinancingDaysOfWeek.
+func (f InstrumentFinancing) DaysCharged(day time.Weekday) int {
+	for _, d := range f.FinancingDaysOfWeek {
+		if weekday, ok := d.DayOfWeek.Weekday(); ok && weekday == day {
+			return d.DaysCharged
+		}
+	}
+	return 0
+}
+
+// Rate returns the annual financing rate of a Position of units: LongRate for positive units and
+// ShortRate for negative ones. A negative rate is paid and a positive one is collected.
+func (f InstrumentFinancing) Rate(units DecimalNumber) (float64, error) {
+	u, err := units.Float64()
+	if err != nil {
+		return 0, err
+	}
+	if u < 0 {
+		return f.ShortRate.Float64()
+	}
+	return f.LongRate.Float64()
+}
+
+// Estimate returns the financing of a Position of units held for days at price, in the quote
+// currency of the Instrument: the Position's value times the annual rate times days / 365. It is
+// negative if the financing is paid. Convert it into the home currency with
+// [HomeConverter.Convert].
+func (f InstrumentFinancing) Estimate(units DecimalNumber, price PriceValue, days int) (float64, error) {
+	rate, err := f.Rate(units)
+	if err != nil {
+		return 0, err
+	}
+	u, err := units.Float64()
+	if err != nil {
+		return 0, err
+	}
+	p, err := price.Float64()
+	if err != nil {
+		return 0, err
+	}
+	return math.Abs(u) * p * rate * float64(days) / 365, nil
+}
+
+// EstimateOvernight returns the financing of a Position of units at price held over the rollover
+// on day, which is charged for [InstrumentFinancing.DaysCharged] days, in the quote currency of
+// the Instrument.
+func (f InstrumentFinancing) EstimateOvernight(units DecimalNumber, price PriceValue, day time.Weekday) (float64, error) {
+	return f.Estimate(units, price, f.DaysCharged(day))
+}
@@ -0,0 +1,101 @@
+package oanda
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSummarizeFinancing(t *testing.T) {
+	store := NewMemoryTransactionStore()
+	for _, line := range []string{
+		`{"type":"ORDER_FILL","id":"1","time":"2024-01-01T10:00:00Z","instrument":"EUR_USD","units":"100","tradeOpened":{"tradeID":"1","units":"100"}}`,
+		`{"type":"ORDER_FILL","id":"2","time":"2024-01-01T11:00:00Z","instrument":"EUR_USD","units":"-50","tradeOpened":{"tradeID":"2","units":"-50"}}`,
+		`{"type":"DAILY_FINANCING","id":"3","time":"2024-01-01T21:00:00Z","financing":"-0.5000","positionFinancings":[
+			{"instrument":"EUR_USD","financing":"-0.5000","openTradeFinancings":[{"tradeID":"1","financing":"-0.6000"},{"tradeID":"2","financing":"0.1000"}]}]}`,
+		`{"type":"DAILY_FINANCING","id":"4","time":"2024-01-02T21:00:00Z","financing":"-0.7000","positionFinancings":[
+			{"instrument":"EUR_USD","financing":"-0.5000","openTradeFinancings":[{"tradeID":"1","financing":"-0.6000"},{"tradeID":"2","financing":"0.1000"}]},
+			{"instrument":"USD_JPY","financing":"-0.2000","openTradeFinancings":[{"tradeID":"0","financing":"-0.2000"}]}]}`,
+		`{"type":"ORDER_FILL","id":"5","time":"2024-01-03T10:00:00Z","instrument":"EUR_USD","units":"-100","financing":"-0.0500",
+			"tradesClosed":[{"tradeID":"1","units":"-100","financing":"-0.0500"}]}`,
+	} {
+		transaction, err := unmarshalTransaction([]byte(line))
+		if err != nil {
+			t.Fatalf("failed to decode transaction: %v", err)
+		}
+		if err := store.Append(t.Context(), []Transaction{transaction}); err != nil {
+			t.Fatalf("failed to store transaction: %v", err)
+		}
+	}
+
+	from := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	report, err := SummarizeFinancing(store.All(t.Context()), from, time.Time{})
+	if err != nil {
+		t.Fatalf("failed to summarize financing: %v", err)
+	}
+	if want := (FinancingTotals{Daily: "-0.7000", OnClose: "-0.0500", Total: "-0.7500"}); report.Total != want {
+		t.Errorf("got total %+v, want %+v", report.Total, want)
+	}
+	if want := (FinancingTotals{Daily: "-0.5000", OnClose: "-0.0500", Total: "-0.5500"}); report.ByInstrument["EUR_USD"] != want {
+		t.Errorf("got EUR_USD %+v, want %+v", report.ByInstrument["EUR_USD"], want)
+	}
+	for key, want := range map[FinancingKey]FinancingTotals{
+		{"EUR_USD", DirectionLong}:  {Daily: "-0.6000", OnClose: "-0.0500", Total: "-0.6500"},
+		{"EUR_USD", DirectionShort}: {Daily: "0.1000", OnClose: "0.0000", Total: "0.1000"},
+		{"USD_JPY", ""}:             {Daily: "-0.2000", OnClose: "0.0000", Total: "-0.2000"},
+	} {
+		if got := report.BySide[key]; got != want {
+			t.Errorf("got %v %+v, want %+v", key, got, want)
+		}
+	}
+	if len(report.BySide) != 3 {
+		t.Errorf("got %d sides, want 3", len(report.BySide))
+	}
+}
+
+func TestInstrumentFinancing(t *testing.T) {
+	var instrument Instrument
+	err := json.Unmarshal([]byte(`{"name":"EUR_USD","type":"CURRENCY",
+		"financing":{"longRate":"-0.0365","shortRate":"0.0073","financingDaysOfWeek":[
+			{"dayOfWeek":"MONDAY","daysCharged":1},{"dayOfWeek":"WEDNESDAY","daysCharged":3},
+			{"dayOfWeek":"SATURDAY","daysCharged":0}]},
+		"tags":[{"type":"ASSET_CLASS","name":"CURRENCY"},{"type":"KID_ASSET_CLASS","name":"FX"}]}`), &instrument)
+	if err != nil {
+		t.Fatal(err)
+	}
+	financing := instrument.Financing
+	for day, want := range map[time.Weekday]int{time.Monday: 1, time.Wednesday: 3, time.Saturday: 0, time.Friday: 0} {
+		if got := financing.DaysCharged(day); got != want {
+			t.Errorf("got %d days charged on %s, want %d", got, day, want)
+		}
+	}
+	// 10000 units at 1.25 for a year at -3.65% pay 456.25, or 1.25 a day.
+	for _, tt := range []struct {
+		units DecimalNumber
+		day   time.Weekday
+		want  float64
+	}{
+		{"10000", time.Monday, -1.25},
+		{"10000", time.Wednesday, -3.75},
+		{"-10000", time.Wednesday, 0.75},
+		{"10000", time.Saturday, 0},
+	} {
+		got, err := financing.EstimateOvernight(tt.units, "1.25", tt.day)
+		if err != nil || math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("EstimateOvernight(%s, %s) = %v, %v, want %v", tt.units, tt.day, got, err, tt.want)
+		}
+	}
+	if _, err := financing.Estimate("x", "1.25", 1); err == nil {
+		t.Error("got no error for invalid units")
+	}
+	if names := instrument.TagNames("ASSET_CLASS"); len(names) != 1 || names[0] != "CURRENCY" {
+		t.Errorf("got asset classes %v", names)
+	}
+	if !instrument.HasTag("KID_ASSET_CLASS", "FX") || instrument.HasTag("ASSET_CLASS", "FX") {
+		t.Error("got wrong HasTag results")
+	}
+	if _, ok := DayOfWeek("FUNDAY").Weekday(); ok {
+		t.Error("got a weekday for an invalid day")
+	}
+}
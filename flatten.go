@@ -0,0 +1,125 @@
+package oanda
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// FlattenRequest configures [Client.FlattenAccount]. Use NewFlattenRequest to create a new
+// request and the builder methods to configure it.
+type FlattenRequest struct {
+	// DryRun reports what would be cancelled and closed without changing the Account.
+	DryRun bool
+}
+
+// NewFlattenRequest creates a new FlattenRequest that flattens the Account.
+func NewFlattenRequest() *FlattenRequest {
+	return &FlattenRequest{}
+}
+
+// SetDryRun makes FlattenAccount only report what it would cancel and close.
+func (r *FlattenRequest) SetDryRun() *FlattenRequest {
+	r.DryRun = true
+	return r
+}
+
+// PositionCloseResult is the outcome of closing a single Position with [Client.FlattenAccount].
+type PositionCloseResult struct {
+	// Instrument is the Instrument of the Position.
+	Instrument InstrumentName
+	// LongUnits and ShortUnits are the units of the long and short sides that were open.
+	LongUnits, ShortUnits DecimalNumber
+	// Response is the response of the close, or nil if it failed or in a dry run.
+	Response *PositionCloseResponse
+	// Err is the error that made the close fail, or nil if it succeeded.
+	Err error
+}
+
+// FlattenSummary reports what [Client.FlattenAccount] did, or would do in a dry run.
+type FlattenSummary struct {
+	// DryRun is true if nothing was changed.
+	DryRun bool
+	// Orders are the pending entry Orders cancelled, in the order they were listed.
+	Orders []OrderCancelResult
+	// Positions are the open Positions closed, in the order they were listed.
+	Positions []PositionCloseResult
+	// TransactionIDs are the IDs of every Transaction generated, in ID order: the cancellations,
+	// the closing Market Orders and their fills, and the dependent Orders cancelled with the
+	// closed Trades.
+	TransactionIDs []TransactionID
+}
+
+// entryOrderTypes are the types of the pending Orders that open or extend Trades, as opposed to
+// the Orders that depend on a Trade.
+var entryOrderTypes = []OrderType{OrderTypeLimit, OrderTypeStop, OrderTypeMarketIfTouched}
+
+// FlattenAccount cancels every pending entry Order of the Account configured via WithAccountID,
+// so that none fills while the Account is flattened, then closes every side of every open
+// Position. A nil req flattens the Account. The Take Profit and Stop Loss Orders of the Trades
+// are left to OANDA, which cancels them as the Trades are closed, so a Position that cannot be
+// closed keeps its protection. Cancellations and closes that fail are reported in the summary
+// and joined in the error; the summary is nil only if the Orders or Positions could not be
+// listed.
+func (c *Client) FlattenAccount(ctx context.Context, req *FlattenRequest) (*FlattenSummary, error) {
+	if req == nil {
+		req = NewFlattenRequest()
+	}
+	summary := &FlattenSummary{DryRun: req.DryRun}
+	filter := NewOrderFilter().AddTypes(entryOrderTypes...)
+	if req.DryRun {
+		orders, err := c.Order.pendingOrders(ctx, filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pending orders: %w", err)
+		}
+		for _, order := range orders {
+			summary.Orders = append(summary.Orders, OrderCancelResult{OrderID: order.GetID()})
+		}
+	} else {
+		results, err := c.Order.CancelAll(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		summary.Orders = results
+	}
+
+	positions, err := c.Position.ListOpen(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list open positions: %w", err)
+	}
+	for _, position := range positions.Positions {
+		result := PositionCloseResult{
+			Instrument: position.Instrument,
+			LongUnits:  position.Long.Units,
+			ShortUnits: position.Short.Units,
+		}
+		closeReq, err := newPositionCloseAllRequest(position, UnitsAll)
+		if err != nil {
+			result.Err = err
+		} else if !req.DryRun {
+			result.Response, result.Err = c.Position.Close(ctx, position.Instrument, closeReq)
+		}
+		summary.Positions = append(summary.Positions, result)
+	}
+
+	var errs []error
+	var ids []TransactionID
+	for _, result := range summary.Orders {
+		if result.Err != nil {
+			errs = append(errs, fmt.Errorf("failed to cancel order %s: %w", result.OrderID, result.Err))
+		} else if result.Response != nil {
+			ids = append(ids, result.Response.RelatedTransactionIDs...)
+		}
+	}
+	for _, result := range summary.Positions {
+		if result.Err != nil {
+			errs = append(errs, fmt.Errorf("failed to close position %s: %w", result.Instrument, result.Err))
+		} else if result.Response != nil {
+			ids = append(ids, result.Response.RelatedTransactionIDs...)
+		}
+	}
+	slices.SortFunc(ids, CompareTransactionIDs)
+	summary.TransactionIDs = slices.Compact(ids)
+	return summary, errors.Join(errs...)
+}
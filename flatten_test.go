@@ -0,0 +1,80 @@
+package oanda
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestClient_FlattenAccount(t *testing.T) {
+	var mu sync.Mutex
+	var puts []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v3/accounts/1/pendingOrders", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `{"orders":[{"type":"LIMIT","id":"10","state":"PENDING"},
+			{"type":"STOP_LOSS","id":"11","tradeID":"7","state":"PENDING"}],"lastTransactionID":"20"}`)
+	})
+	mux.HandleFunc("PUT /v3/accounts/1/orders/10/cancel", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		puts = append(puts, "cancel 10")
+		mu.Unlock()
+		_, _ = fmt.Fprint(w, `{"orderCancelTransaction":{"type":"ORDER_CANCEL","id":"21","orderID":"10"},"relatedTransactionIDs":["21"],"lastTransactionID":"21"}`)
+	})
+	mux.HandleFunc("GET /v3/accounts/1/openPositions", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `{"positions":[
+			{"instrument":"EUR_USD","long":{"units":"100"},"short":{"units":"0"}},
+			{"instrument":"USD_JPY","long":{"units":"0"},"short":{"units":"-50"}}]}`)
+	})
+	mux.HandleFunc("PUT /v3/accounts/1/positions/{instrument}/close", func(w http.ResponseWriter, r *http.Request) {
+		var req PositionCloseRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+		instrument := r.PathValue("instrument")
+		mu.Lock()
+		puts = append(puts, fmt.Sprintf("close %s %s/%s", instrument, *req.LongUnits, *req.ShortUnits))
+		mu.Unlock()
+		if instrument == "USD_JPY" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = fmt.Fprint(w, `{"errorCode":"MARKET_HALTED","errorMessage":"market halted"}`)
+			return
+		}
+		_, _ = fmt.Fprint(w, `{"longOrderCreateTransaction":{"type":"MARKET_ORDER","id":"100"},
+			"longOrderFillTransaction":{"type":"ORDER_FILL","id":"101","orderID":"100"},
+			"relatedTransactionIDs":["100","101","102"],"lastTransactionID":"102"}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client := NewClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+
+	dryRun, err := client.FlattenAccount(t.Context(), NewFlattenRequest().SetDryRun())
+	if err != nil {
+		t.Fatalf("failed to dry run: %v", err)
+	}
+	if !dryRun.DryRun || len(dryRun.Orders) != 1 || dryRun.Orders[0].OrderID != "10" ||
+		len(dryRun.Positions) != 2 || dryRun.Positions[1].ShortUnits != "-50" || len(dryRun.TransactionIDs) != 0 {
+		t.Errorf("got dry run %+v", dryRun)
+	}
+	if len(puts) != 0 {
+		t.Fatalf("dry run sent %v", puts)
+	}
+
+	summary, err := client.FlattenAccount(t.Context(), nil)
+	if err == nil {
+		t.Error("got no error for a position that could not be closed")
+	}
+	want := []string{"cancel 10", "close EUR_USD ALL/NONE", "close USD_JPY NONE/ALL"}
+	if !slices.Equal(puts, want) {
+		t.Errorf("got requests %v, want %v", puts, want)
+	}
+	if summary == nil || summary.Positions[0].Response.Long() == nil || summary.Positions[1].Err == nil {
+		t.Fatalf("got summary %+v", summary)
+	}
+	if got := summary.TransactionIDs; !slices.Equal(got, []TransactionID{"21", "100", "101", "102"}) {
+		t.Errorf("got transaction IDs %v", got)
+	}
+}
@@ -0,0 +1,160 @@
+package oanda
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// OrderGroup manages the Orders and Trades of one strategy as a unit. Membership is recorded in
+// the tag of the client extensions: every Order submitted through the group is tagged with the
+// group's ClientTag, and so are the Trades it opens. Several groups with different tags can
+// therefore trade on the same Account without touching each other's Orders and Trades. Create
+// one with [NewOrderGroup].
+type OrderGroup struct {
+	client *Client
+	tag    ClientTag
+}
+
+// NewOrderGroup creates a new OrderGroup for the Orders and Trades tagged with tag.
+func NewOrderGroup(client *Client, tag ClientTag) *OrderGroup {
+	return &OrderGroup{client: client, tag: tag}
+}
+
+// Tag returns the ClientTag identifying the group's Orders and Trades.
+func (g *OrderGroup) Tag() ClientTag {
+	return g.tag
+}
+
+// Submit tags req with the group's tag and creates the Order. For Orders that open Trades, the
+// Trade client extensions are tagged as well. Other client extension fields of req are kept.
+func (g *OrderGroup) Submit(ctx context.Context, req OrderRequest) (*OrderCreateResponse, error) {
+	if err := g.tagRequest(req); err != nil {
+		return nil, err
+	}
+	return g.client.Order.Create(ctx, req)
+}
+
+// Orders returns the group's pending Orders.
+func (g *OrderGroup) Orders(ctx context.Context) ([]Order, error) {
+	return g.client.Order.pendingOrders(ctx, NewOrderFilter().SetClientTag(g.tag))
+}
+
+// Trades returns the group's open Trades.
+func (g *OrderGroup) Trades(ctx context.Context) ([]Trade, error) {
+	resp, err := g.client.Trade.ListOpen(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var trades []Trade
+	for _, trade := range resp.Trades {
+		if g.owns(trade.ClientExtensions) {
+			trades = append(trades, trade)
+		}
+	}
+	return trades, nil
+}
+
+// Replace replaces one of the group's pending Orders with req, which is tagged with the group's
+// tag. It fails without replacing anything if the Order does not belong to the group.
+func (g *OrderGroup) Replace(ctx context.Context, specifier OrderSpecifier, req OrderRequest) (*OrderReplaceResponse, error) {
+	details, err := g.client.Order.Details(ctx, specifier)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order details: %w", err)
+	}
+	if !g.owns(details.Order.GetClientExtensions()) {
+		return nil, fmt.Errorf("order %s does not belong to group %s", specifier, g.tag)
+	}
+	if err := g.tagRequest(req); err != nil {
+		return nil, err
+	}
+	return g.client.Order.Replace(ctx, specifier, req)
+}
+
+// CancelAll cancels the group's pending Orders. It behaves like [orderService.CancelAll].
+func (g *OrderGroup) CancelAll(ctx context.Context) ([]OrderCancelResult, error) {
+	return g.client.Order.CancelAll(ctx, NewOrderFilter().SetClientTag(g.tag))
+}
+
+// TradeCloseResult is the outcome of closing a single Trade with [OrderGroup.CloseAll].
+type TradeCloseResult struct {
+	// TradeID is the ID of the Trade that was closed.
+	TradeID TradeID
+	// Response is the response of the close, or nil if it failed.
+	Response *TradeCloseResponse
+	// Err is the error that made the close fail, or nil if it succeeded.
+	Err error
+}
+
+// closeAllConcurrency is the maximum number of close requests CloseAll has in flight.
+const closeAllConcurrency = 8
+
+// CloseAll closes the group's open Trades in full. The Trades are closed concurrently; the
+// result of each close is reported in the returned slice, in the order the Trades were listed.
+// The error is non-nil only if the open Trades could not be listed.
+func (g *OrderGroup) CloseAll(ctx context.Context) ([]TradeCloseResult, error) {
+	trades, err := g.Trades(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list open trades: %w", err)
+	}
+	results := make([]TradeCloseResult, len(trades))
+	sem := make(chan struct{}, closeAllConcurrency)
+	var wg sync.WaitGroup
+	for i, trade := range trades {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			resp, err := g.client.Trade.Close(ctx, trade.ID, NewTradeCloseALLRequest())
+			results[i] = TradeCloseResult{TradeID: trade.ID, Response: resp, Err: err}
+		}()
+	}
+	wg.Wait()
+	return results, nil
+}
+
+func (g *OrderGroup) owns(extensions *ClientExtensions) bool {
+	return extensions != nil && extensions.Tag != nil && *extensions.Tag == g.tag
+}
+
+// tagRequest sets the group's tag on the client extensions of req, and on its Trade client
+// extensions for Orders that open Trades.
+func (g *OrderGroup) tagRequest(req OrderRequest) error {
+	switch r := req.(type) {
+	case *MarketOrderRequest:
+		r.ClientExtensions = g.tagged(r.ClientExtensions)
+		r.TradeClientExtensions = g.tagged(r.TradeClientExtensions)
+	case *LimitOrderRequest:
+		r.ClientExtensions = g.tagged(r.ClientExtensions)
+		r.TradeClientExtensions = g.tagged(r.TradeClientExtensions)
+	case *StopOrderRequest:
+		r.ClientExtensions = g.tagged(r.ClientExtensions)
+		r.TradeClientExtensions = g.tagged(r.TradeClientExtensions)
+	case *MarketIfTouchedOrderRequest:
+		r.ClientExtensions = g.tagged(r.ClientExtensions)
+		r.TradeClientExtensions = g.tagged(r.TradeClientExtensions)
+	case *TakeProfitOrderRequest:
+		r.ClientExtensions = g.tagged(r.ClientExtensions)
+	case *StopLossOrderRequest:
+		r.ClientExtensions = g.tagged(r.ClientExtensions)
+	case *GuaranteedStopLossOrderRequest:
+		r.ClientExtensions = g.tagged(r.ClientExtensions)
+	case *TrailingStopLossOrderRequest:
+		r.ClientExtensions = g.tagged(r.ClientExtensions)
+	default:
+		return fmt.Errorf("cannot tag order request of type %T", req)
+	}
+	return nil
+}
+
+// tagged returns a copy of extensions with the group's tag set.
+func (g *OrderGroup) tagged(extensions *ClientExtensions) *ClientExtensions {
+	var e ClientExtensions
+	if extensions != nil {
+		e = *extensions
+	}
+	tag := g.tag
+	e.Tag = &tag
+	return &e
+}
@@ -0,0 +1,94 @@
+package oanda
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestOrderGroup(t *testing.T) {
+	var mu sync.Mutex
+	var created map[string]map[string]any
+	var replaced, closed []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v3/accounts/1/orders":
+			if err := json.NewDecoder(r.Body).Decode(&created); err != nil {
+				t.Errorf("failed to decode body: %v", err)
+			}
+			w.WriteHeader(http.StatusCreated)
+			_, _ = fmt.Fprint(w, `{"orderCreateTransaction":{"type":"LIMIT_ORDER","id":"10"}}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/v3/accounts/1/pendingOrders":
+			_, _ = fmt.Fprint(w, `{"orders":[
+				{"type":"LIMIT","id":"10","state":"PENDING","clientExtensions":{"tag":"alpha"}},
+				{"type":"LIMIT","id":"11","state":"PENDING","clientExtensions":{"tag":"beta"}}]}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/v3/accounts/1/openTrades":
+			_, _ = fmt.Fprint(w, `{"trades":[
+				{"id":"1","state":"OPEN","clientExtensions":{"tag":"alpha"}},
+				{"id":"2","state":"OPEN"},
+				{"id":"3","state":"OPEN","clientExtensions":{"tag":"alpha"}}]}`)
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/v3/accounts/1/orders/"):
+			id := strings.TrimPrefix(r.URL.Path, "/v3/accounts/1/orders/")
+			tag := map[string]string{"10": "alpha", "11": "beta"}[id]
+			_, _ = fmt.Fprintf(w, `{"order":{"type":"LIMIT","id":%q,"clientExtensions":{"tag":%q}}}`, id, tag)
+		case r.Method == http.MethodPut && strings.HasPrefix(r.URL.Path, "/v3/accounts/1/orders/"):
+			replaced = append(replaced, strings.TrimPrefix(r.URL.Path, "/v3/accounts/1/orders/"))
+			w.WriteHeader(http.StatusCreated)
+			_, _ = fmt.Fprint(w, `{"orderCreateTransaction":{"type":"LIMIT_ORDER","id":"12"}}`)
+		case r.Method == http.MethodPut && strings.HasSuffix(r.URL.Path, "/close"):
+			closed = append(closed, strings.Split(r.URL.Path, "/")[5])
+			_, _ = fmt.Fprint(w, `{}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client := NewClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+	group := NewOrderGroup(client, "alpha")
+
+	req := NewLimitOrderRequest("EUR_USD", "100", "1.10000").SetClientExtensions(NewClientExtensions().SetID("entry"))
+	if _, err := group.Submit(t.Context(), req); err != nil {
+		t.Fatalf("failed to submit: %v", err)
+	}
+	order := created["order"]
+	if ext, _ := order["clientExtensions"].(map[string]any); ext["tag"] != "alpha" || ext["id"] != "entry" {
+		t.Errorf("got client extensions %v, want tag alpha and ID entry", order["clientExtensions"])
+	}
+	if ext, _ := order["tradeClientExtensions"].(map[string]any); ext["tag"] != "alpha" {
+		t.Errorf("got trade client extensions %v, want tag alpha", order["tradeClientExtensions"])
+	}
+
+	orders, err := group.Orders(t.Context())
+	if err != nil || len(orders) != 1 || orders[0].GetID() != "10" {
+		t.Errorf("got orders %v (%v), want order 10", orders, err)
+	}
+	trades, err := group.Trades(t.Context())
+	if err != nil || len(trades) != 2 || trades[0].ID != "1" || trades[1].ID != "3" {
+		t.Errorf("got trades %v (%v), want trades 1 and 3", trades, err)
+	}
+
+	if _, err := group.Replace(t.Context(), "11", NewLimitOrderRequest("EUR_USD", "100", "1.20000")); err == nil {
+		t.Error("got no error replacing an order of another group")
+	}
+	if _, err := group.Replace(t.Context(), "10", NewLimitOrderRequest("EUR_USD", "100", "1.20000")); err != nil {
+		t.Errorf("failed to replace: %v", err)
+	}
+	if len(replaced) != 1 || replaced[0] != "10" {
+		t.Errorf("got replaced orders %v, want [10]", replaced)
+	}
+
+	results, err := group.CloseAll(t.Context())
+	if err != nil || len(results) != 2 {
+		t.Fatalf("got results %+v (%v), want 2", results, err)
+	}
+	if len(closed) != 2 || strings.Contains(strings.Join(closed, ","), "2") {
+		t.Errorf("got closed trades %v, want 1 and 3", closed)
+	}
+}
@@ -0,0 +1,90 @@
+package oanda
+
+import (
+	"context"
+	"fmt"
+)
+
+// GuaranteedEntry builds entry Orders with a Guaranteed Stop Loss on fill that complies with
+// the Instrument's minimum distance, for Accounts whose GuaranteedStopLossOrderMode is REQUIRED
+// and which would otherwise have their Orders rejected or cancelled until a valid distance is
+// found. Create one with [NewGuaranteedEntry].
+type GuaranteedEntry struct {
+	client      *Client
+	instruments *InstrumentCache
+	distance    *DecimalNumber
+}
+
+// NewGuaranteedEntry creates a new GuaranteedEntry that reads the Account's guaranteed Stop Loss
+// mode with client and the Instruments' minimum distances from instruments. By default, the
+// Guaranteed Stop Loss is placed at the minimum distance.
+func NewGuaranteedEntry(client *Client, instruments *InstrumentCache) *GuaranteedEntry {
+	return &GuaranteedEntry{client: client, instruments: instruments}
+}
+
+// SetDistance sets the preferred distance of the Guaranteed Stop Loss from the fill price, in
+// price units. It is raised to the Instrument's minimum distance where that is larger.
+func (e *GuaranteedEntry) SetDistance(distance DecimalNumber) *GuaranteedEntry {
+	e.distance = &distance
+	return e
+}
+
+// Distance returns the distance at which the Guaranteed Stop Loss of an Order for instrument
+// is placed. It fails if the Account or the Instrument does not allow guaranteed Stop Losses.
+func (e *GuaranteedEntry) Distance(ctx context.Context, instrument InstrumentName) (DecimalNumber, error) {
+	summary, err := e.client.Account.Summary(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get account summary: %w", err)
+	}
+	if summary.Account.GuaranteedStopLossOrderMode == GuaranteedStopLossOrderModeDisabled {
+		return "", fmt.Errorf("guaranteed stop loss orders are disabled for account %s", summary.Account.ID)
+	}
+	meta, err := e.instruments.Get(ctx, instrument)
+	if err != nil {
+		return "", err
+	}
+	if meta.GuaranteedStopLossOrderMode == GuaranteedStopLossOrderModeForInstrumentDisabled {
+		return "", fmt.Errorf("guaranteed stop loss orders are disabled for %s", instrument)
+	}
+	minimum := meta.MinimumGuaranteedStopLossDistance
+	if minimum == "" {
+		return "", fmt.Errorf("no minimum guaranteed stop loss distance for %s", instrument)
+	}
+	if e.distance == nil {
+		return minimum, nil
+	}
+	preferred, err := e.distance.Rat()
+	if err != nil {
+		return "", err
+	}
+	m, err := minimum.Rat()
+	if err != nil {
+		return "", err
+	}
+	if preferred.Cmp(m) < 0 {
+		return minimum, nil
+	}
+	return *e.distance, nil
+}
+
+// MarketOrder returns a Market Order request for units of instrument with a compliant
+// Guaranteed Stop Loss on fill.
+func (e *GuaranteedEntry) MarketOrder(ctx context.Context, instrument InstrumentName, units DecimalNumber) (*MarketOrderRequest, error) {
+	distance, err := e.Distance(ctx, instrument)
+	if err != nil {
+		return nil, err
+	}
+	return NewMarketOrderRequest(instrument, units).
+		SetGuaranteedStopLossOnFill(NewGuaranteedStopLossDetails().SetDistance(distance)), nil
+}
+
+// LimitOrder returns a Limit Order request for units of instrument at price with a compliant
+// Guaranteed Stop Loss on fill.
+func (e *GuaranteedEntry) LimitOrder(ctx context.Context, instrument InstrumentName, units DecimalNumber, price PriceValue) (*LimitOrderRequest, error) {
+	distance, err := e.Distance(ctx, instrument)
+	if err != nil {
+		return nil, err
+	}
+	return NewLimitOrderRequest(instrument, units, price).
+		SetGuaranteedStopLossOnFill(NewGuaranteedStopLossDetails().SetDistance(distance)), nil
+}
@@ -0,0 +1,58 @@
+package oanda
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGuaranteedEntry(t *testing.T) {
+	accountMode := "REQUIRED"
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v3/accounts/1/summary", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, `{"account":{"id":"1","guaranteedStopLossOrderMode":%q}}`, accountMode)
+	})
+	mux.HandleFunc("/v3/accounts/1/instruments", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `{"instruments":[
+			{"name":"EUR_USD","minimumGuaranteedStopLossDistance":"0.0010","guaranteedStopLossOrderMode":"REQUIRED"},
+			{"name":"XAU_USD","minimumGuaranteedStopLossDistance":"5","guaranteedStopLossOrderMode":"DISABLED"}]}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client := NewClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+	entry := NewGuaranteedEntry(client, NewInstrumentCache(client))
+
+	req, err := entry.MarketOrder(t.Context(), "EUR_USD", "100")
+	if err != nil {
+		t.Fatalf("failed to build market order: %v", err)
+	}
+	if gslo := req.GuaranteedStopLossOnFill; gslo == nil || gslo.Distance == nil || *gslo.Distance != "0.0010" {
+		t.Errorf("got guaranteed stop loss %+v, want distance 0.0010", gslo)
+	}
+
+	tests := []struct {
+		distance DecimalNumber
+		want     DecimalNumber
+	}{
+		{"0.0005", "0.0010"},
+		{"0.0020", "0.0020"},
+	}
+	for _, tt := range tests {
+		limit, err := entry.SetDistance(tt.distance).LimitOrder(t.Context(), "EUR_USD", "100", "1.10000")
+		if err != nil {
+			t.Fatalf("failed to build limit order: %v", err)
+		}
+		if got := *limit.GuaranteedStopLossOnFill.Distance; got != tt.want {
+			t.Errorf("preferred distance %s: got %s, want %s", tt.distance, got, tt.want)
+		}
+	}
+
+	if _, err := entry.Distance(t.Context(), "XAU_USD"); err == nil {
+		t.Error("got no error for instrument without guaranteed stop losses")
+	}
+	accountMode = "DISABLED"
+	if _, err := entry.Distance(t.Context(), "EUR_USD"); err == nil {
+		t.Error("got no error for account without guaranteed stop losses")
+	}
+}
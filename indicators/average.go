@@ -0,0 +1,152 @@
+package indicators
+
+import "math"
+
+// SMA is a simple moving average: the mean of the last period values. Create one with [NewSMA].
+type SMA struct {
+	window *window
+	sum    float64
+}
+
+// NewSMA creates a new SMA over period values. It returns an error if period is not positive.
+func NewSMA(period int) (*SMA, error) {
+	if err := validatePeriod("period", period); err != nil {
+		return nil, err
+	}
+	return &SMA{window: newWindow(period)}, nil
+}
+
+// Update adds v and returns the average, which is ready once period values have been added.
+func (s *SMA) Update(v float64) (float64, bool) {
+	s.sum += v - s.window.add(v)
+	if !s.window.full {
+		return 0, false
+	}
+	return s.sum / float64(len(s.window.values)), true
+}
+
+// EMA is an exponential moving average with the smoothing factor 2/(period+1), seeded with the
+// simple average of the first period values. Create one with [NewEMA].
+type EMA struct {
+	seed  *SMA
+	alpha float64
+	value float64
+	ready bool
+}
+
+// NewEMA creates a new EMA over period values. It returns an error if period is not positive.
+func NewEMA(period int) (*EMA, error) {
+	seed, err := NewSMA(period)
+	if err != nil {
+		return nil, err
+	}
+	return &EMA{seed: seed, alpha: 2 / float64(period+1)}, nil
+}
+
+// Update adds v and returns the average, which is ready once period values have been added.
+func (e *EMA) Update(v float64) (float64, bool) {
+	if !e.ready {
+		e.value, e.ready = e.seed.Update(v)
+		return e.value, e.ready
+	}
+	e.value += e.alpha * (v - e.value)
+	return e.value, true
+}
+
+// MACDValue is a value of a [MACD].
+type MACDValue struct {
+	// MACD is the fast average minus the slow one.
+	MACD float64
+	// Signal is the average of MACD.
+	Signal float64
+	// Histogram is MACD minus Signal.
+	Histogram float64
+}
+
+// MACD is the moving average convergence divergence: the difference of a fast and a slow
+// [EMA], and a signal EMA of that difference. Create one with [NewMACD].
+type MACD struct {
+	fast, slow, signal *EMA
+}
+
+// NewMACD creates a new MACD with the given periods of the fast, slow and signal averages,
+// commonly 12, 26 and 9. It returns an error if a period is not positive.
+func NewMACD(fast, slow, signal int) (*MACD, error) {
+	for _, p := range []struct {
+		name   string
+		period int
+	}{{"fast period", fast}, {"slow period", slow}, {"signal period", signal}} {
+		if err := validatePeriod(p.name, p.period); err != nil {
+			return nil, err
+		}
+	}
+	m := &MACD{}
+	m.fast, _ = NewEMA(fast)
+	m.slow, _ = NewEMA(slow)
+	m.signal, _ = NewEMA(signal)
+	return m, nil
+}
+
+// Update adds v and returns the MACD, which is ready once the signal average is.
+func (m *MACD) Update(v float64) (MACDValue, bool) {
+	fast, fastOK := m.fast.Update(v)
+	slow, slowOK := m.slow.Update(v)
+	if !fastOK || !slowOK {
+		return MACDValue{}, false
+	}
+	macd := fast - slow
+	signal, ok := m.signal.Update(macd)
+	if !ok {
+		return MACDValue{}, false
+	}
+	return MACDValue{MACD: macd, Signal: signal, Histogram: macd - signal}, true
+}
+
+// BollingerValue is a value of [Bollinger] bands.
+type BollingerValue struct {
+	// Middle is the simple average.
+	Middle float64
+	// Upper is the average plus the configured multiple of the standard deviation.
+	Upper float64
+	// Lower is the average minus the configured multiple of the standard deviation.
+	Lower float64
+}
+
+// Bollinger computes Bollinger bands: the simple average of the last period values, and bands
+// a multiple of their population standard deviation above and below it. Create one with
+// [NewBollinger].
+type Bollinger struct {
+	window     *window
+	multiplier float64
+}
+
+// NewBollinger creates new Bollinger bands over period values, multiplier standard deviations
+// wide, commonly 20 and 2. It returns an error if period is not positive.
+func NewBollinger(period int, multiplier float64) (*Bollinger, error) {
+	if err := validatePeriod("period", period); err != nil {
+		return nil, err
+	}
+	return &Bollinger{window: newWindow(period), multiplier: multiplier}, nil
+}
+
+// Update adds v and returns the bands, which are ready once period values have been added.
+func (b *Bollinger) Update(v float64) (BollingerValue, bool) {
+	b.window.add(v)
+	if !b.window.full {
+		return BollingerValue{}, false
+	}
+	// The mean and deviation are recomputed from the window rather than from running sums,
+	// which lose precision over long series.
+	n := float64(len(b.window.values))
+	var sum float64
+	for _, x := range b.window.values {
+		sum += x
+	}
+	mean := sum / n
+	var squares float64
+	for _, x := range b.window.values {
+		squares += (x - mean) * (x - mean)
+	}
+	width := b.multiplier * math.Sqrt(squares/n)
+	return BollingerValue{Middle: mean, Upper: mean + width, Lower: mean - width}, true
+}
@@ -0,0 +1,46 @@
+package indicators
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEMA(t *testing.T) {
+	if got := Compute([]float64{1, 2, 3, 10}, must(NewEMA(3)).Update); !approxEqual(got, []float64{2, 6}) {
+		t.Errorf("got %v, want [2 6]", got)
+	}
+}
+
+func TestMACD(t *testing.T) {
+	got := Compute([]float64{1, 2, 3, 4, 5, 6}, must(NewMACD(2, 3, 2)).Update)
+	if len(got) != 3 {
+		t.Fatalf("got %d values, want 3", len(got))
+	}
+	for _, v := range got {
+		if math.Abs(v.MACD-0.5) > 1e-9 || math.Abs(v.Signal-0.5) > 1e-9 || math.Abs(v.Histogram) > 1e-9 {
+			t.Errorf("got %+v, want MACD and signal 0.5", v)
+		}
+	}
+}
+
+func TestBollinger(t *testing.T) {
+	got := Compute([]float64{5, 1, 2, 3}, must(NewBollinger(3, 2)).Update)
+	if len(got) != 2 {
+		t.Fatalf("got %d values, want 2", len(got))
+	}
+	width := 2 * math.Sqrt(2.0/3)
+	if v := got[1]; math.Abs(v.Middle-2) > 1e-9 || math.Abs(v.Upper-2-width) > 1e-9 || math.Abs(v.Lower-2+width) > 1e-9 {
+		t.Errorf("got %+v, want 2 ± %v", v, width)
+	}
+}
+
+func TestNewMACD_InvalidPeriod(t *testing.T) {
+	for _, periods := range [][3]int{{0, 26, 9}, {12, 0, 9}, {12, 26, -1}} {
+		if _, err := NewMACD(periods[0], periods[1], periods[2]); err == nil {
+			t.Errorf("got no error for periods %v", periods)
+		}
+	}
+	if _, err := NewBollinger(0, 2); err == nil {
+		t.Error("got no error for a zero Bollinger period")
+	}
+}
@@ -0,0 +1,162 @@
+// Package indicators computes technical indicators over the candlesticks of the oanda package.
+//
+// Every indicator is a type updated with one value at a time, such as the close of a completed
+// candlestick, so that it can follow a live series built by an [oanda.CandleAggregator]:
+//
+//	rsi, err := indicators.NewRSI(14)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	for candle, err := range aggregator.Candles(stream.Updates()) {
+//		if err != nil || !candle.Complete {
+//			continue
+//		}
+//		bar, err := indicators.BarOf(candle.Candlestick, indicators.Mid)
+//		if err != nil {
+//			log.Fatal(err)
+//		}
+//		if value, ok := rsi.Update(bar.Close); ok {
+//			fmt.Println(candle.Time, value)
+//		}
+//	}
+//
+// The same types compute an indicator over a whole series with [Compute]:
+//
+//	closes, err := indicators.Closes(resp.Candles, indicators.Mid)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	sma, err := indicators.NewSMA(20)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	averages := indicators.Compute(closes, sma.Update)
+//
+// Candlestick patterns such as engulfing bars and doji are recognized by a [PatternDetector],
+// or over a whole series with [DetectPatterns].
+//
+// Constructors return an error if given a period that is not positive.
+package indicators
+
+import (
+	"fmt"
+
+	"github.com/s-shiga/oanda-go"
+)
+
+// Bar is the open, high, low and close of one candlestick, as numbers.
+type Bar struct {
+	Open  float64
+	High  float64
+	Low   float64
+	Close float64
+}
+
+// Source selects the prices of a candlestick an indicator is computed from: [Mid], [Bid] or
+// [Ask]. It returns nil if the candlestick does not have them.
+type Source func(oanda.Candlestick) *oanda.CandlestickData
+
+// Mid selects the midpoint prices of a candlestick.
+func Mid(c oanda.Candlestick) *oanda.CandlestickData { return c.Mid }
+
+// Bid selects the bid prices of a candlestick.
+func Bid(c oanda.Candlestick) *oanda.CandlestickData { return c.Bid }
+
+// Ask selects the ask prices of a candlestick.
+func Ask(c oanda.Candlestick) *oanda.CandlestickData { return c.Ask }
+
+// BarOf returns the Bar of the prices of candle selected by source. It returns an error if
+// candle does not have the prices, such as when they were not requested.
+func BarOf(candle oanda.Candlestick, source Source) (Bar, error) {
+	data := source(candle)
+	if data == nil {
+		return Bar{}, fmt.Errorf("candlestick at %v has no such prices", candle.Time)
+	}
+	var bar Bar
+	for _, p := range []struct {
+		value oanda.PriceValue
+		dst   *float64
+	}{{data.O, &bar.Open}, {data.H, &bar.High}, {data.L, &bar.Low}, {data.C, &bar.Close}} {
+		f, err := p.value.Float64()
+		if err != nil {
+			return Bar{}, fmt.Errorf("invalid candlestick at %v: %w", candle.Time, err)
+		}
+		*p.dst = f
+	}
+	return bar, nil
+}
+
+// Bars returns the Bars of candles, with the prices selected by source.
+func Bars(candles []oanda.Candlestick, source Source) ([]Bar, error) {
+	bars := make([]Bar, len(candles))
+	for i, candle := range candles {
+		bar, err := BarOf(candle, source)
+		if err != nil {
+			return nil, err
+		}
+		bars[i] = bar
+	}
+	return bars, nil
+}
+
+// Closes returns the closing prices of candles, selected by source.
+func Closes(candles []oanda.Candlestick, source Source) ([]float64, error) {
+	bars, err := Bars(candles, source)
+	if err != nil {
+		return nil, err
+	}
+	closes := make([]float64, len(bars))
+	for i, bar := range bars {
+		closes[i] = bar.Close
+	}
+	return closes, nil
+}
+
+// Compute passes inputs in turn to update, the Update method of an indicator, and returns the
+// values it reports once it has warmed up. The values are aligned with the end of inputs: the
+// last value is the indicator at the last input, and the first one at input
+// len(inputs)-len(values).
+func Compute[T, V any](inputs []T, update func(T) (V, bool)) []V {
+	var values []V
+	for _, input := range inputs {
+		if v, ok := update(input); ok {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// window holds the last values added to it, up to its size.
+type window struct {
+	values []float64
+	next   int
+	full   bool
+}
+
+// validatePeriod returns an error if the period called name is not positive.
+func validatePeriod(name string, period int) error {
+	if period <= 0 {
+		return fmt.Errorf("%s must be positive, got %d", name, period)
+	}
+	return nil
+}
+
+// newWindow returns a window of size values, which must be positive.
+func newWindow(size int) *window {
+	return &window{values: make([]float64, size)}
+}
+
+// add adds v and returns the value it replaced, or 0 while the window is not full.
+func (w *window) add(v float64) float64 {
+	old := w.values[w.next]
+	if !w.full {
+		old = 0
+	}
+	w.values[w.next] = v
+	w.next++
+	if w.next == len(w.values) {
+		w.next = 0
+		w.full = true
+	}
+	return old
+}
@@ -0,0 +1,51 @@
+package indicators
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/s-shiga/oanda-go"
+)
+
+func approxEqual(got, want []float64) bool {
+	return slices.EqualFunc(got, want, func(a, b float64) bool { return math.Abs(a-b) < 1e-9 })
+}
+
+// must returns v, failing with a panic on err, for indicators created with valid periods.
+func must[T any](v T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+func TestBars(t *testing.T) {
+	candles := []oanda.Candlestick{
+		{Mid: &oanda.CandlestickData{O: "1.1000", H: "1.1020", L: "1.0990", C: "1.1010"}, Bid: &oanda.CandlestickData{C: "1.1009"}},
+		{Mid: &oanda.CandlestickData{O: "1.1010", H: "1.1015", L: "1.1000", C: "1.1005"}},
+	}
+	bars, err := Bars(candles, Mid)
+	if err != nil {
+		t.Fatalf("failed to get bars: %v", err)
+	}
+	if want := (Bar{Open: 1.1, High: 1.102, Low: 1.099, Close: 1.101}); bars[0] != want {
+		t.Errorf("got %+v, want %+v", bars[0], want)
+	}
+	closes, err := Closes(candles, Mid)
+	if err != nil || !approxEqual(closes, []float64{1.101, 1.1005}) {
+		t.Errorf("got closes %v (%v)", closes, err)
+	}
+	if _, err := Closes(candles, Bid); err == nil {
+		t.Error("got no error for missing bid prices")
+	}
+}
+
+func TestCompute(t *testing.T) {
+	if got := Compute([]float64{1, 2, 3, 4, 5}, must(NewSMA(3)).Update); !approxEqual(got, []float64{2, 3, 4}) {
+		t.Errorf("got %v, want [2 3 4]", got)
+	}
+	if got := Compute([]float64{1, 2}, must(NewSMA(3)).Update); len(got) != 0 {
+		t.Errorf("got %v before warm-up", got)
+	}
+}
@@ -0,0 +1,141 @@
+package indicators
+
+import "math"
+
+// wilder is an average with Wilder's smoothing: the simple average of the first period values,
+// then the previous average plus 1/period of the difference to each new value.
+type wilder struct {
+	period int
+	count  int
+	value  float64
+}
+
+// newWilder returns an average over period values, which must be positive.
+func newWilder(period int) *wilder {
+	return &wilder{period: period}
+}
+
+func (w *wilder) update(v float64) (float64, bool) {
+	if w.count < w.period {
+		w.count++
+		w.value += (v - w.value) / float64(w.count)
+		return w.value, w.count == w.period
+	}
+	w.value += (v - w.value) / float64(w.period)
+	return w.value, true
+}
+
+// RSI is the relative strength index with Wilder's smoothing, from 0 to 100. Create one with
+// [NewRSI].
+type RSI struct {
+	gain, loss *wilder
+	prev       float64
+	started    bool
+}
+
+// NewRSI creates a new RSI over period changes, commonly 14. It returns an error if period is
+// not positive.
+func NewRSI(period int) (*RSI, error) {
+	if err := validatePeriod("period", period); err != nil {
+		return nil, err
+	}
+	return &RSI{gain: newWilder(period), loss: newWilder(period)}, nil
+}
+
+// Update adds v and returns the index, which is ready once period changes, that is period+1
+// values, have been added. A series without losses has an index of 100.
+func (r *RSI) Update(v float64) (float64, bool) {
+	if !r.started {
+		r.prev, r.started = v, true
+		return 0, false
+	}
+	change := v - r.prev
+	r.prev = v
+	gain, ok := r.gain.update(max(change, 0))
+	loss, _ := r.loss.update(max(-change, 0))
+	if !ok {
+		return 0, false
+	}
+	if loss == 0 {
+		return 100, true
+	}
+	return 100 - 100/(1+gain/loss), true
+}
+
+// ATR is the average true range with Wilder's smoothing. The true range of a Bar is its range
+// extended to the previous close. Create one with [NewATR].
+type ATR struct {
+	average   *wilder
+	prevClose float64
+	started   bool
+}
+
+// NewATR creates a new ATR over period Bars, commonly 14. It returns an error if period is not
+// positive.
+func NewATR(period int) (*ATR, error) {
+	if err := validatePeriod("period", period); err != nil {
+		return nil, err
+	}
+	return &ATR{average: newWilder(period)}, nil
+}
+
+// Update adds bar and returns the average, which is ready once period Bars have been added.
+func (a *ATR) Update(bar Bar) (float64, bool) {
+	trueRange := bar.High - bar.Low
+	if a.started {
+		trueRange = max(trueRange, math.Abs(bar.High-a.prevClose), math.Abs(bar.Low-a.prevClose))
+	}
+	a.prevClose, a.started = bar.Close, true
+	return a.average.update(trueRange)
+}
+
+// StochasticValue is a value of a [Stochastic] oscillator.
+type StochasticValue struct {
+	// K is the position of the close within the range of the last Bars, from 0 to 100.
+	K float64
+	// D is the simple average of K.
+	D float64
+}
+
+// Stochastic is the stochastic oscillator. Create one with [NewStochastic].
+type Stochastic struct {
+	highs, lows *window
+	d           *SMA
+}
+
+// NewStochastic creates a new Stochastic oscillator with %K over kPeriod Bars and %D over
+// dPeriod values of %K, commonly 14 and 3. It returns an error if a period is not positive.
+func NewStochastic(kPeriod, dPeriod int) (*Stochastic, error) {
+	if err := validatePeriod("k period", kPeriod); err != nil {
+		return nil, err
+	}
+	d, err := NewSMA(dPeriod)
+	if err != nil {
+		return nil, err
+	}
+	return &Stochastic{highs: newWindow(kPeriod), lows: newWindow(kPeriod), d: d}, nil
+}
+
+// Update adds bar and returns the oscillator, which is ready once kPeriod+dPeriod-1 Bars have
+// been added. A flat range puts K at 50.
+func (s *Stochastic) Update(bar Bar) (StochasticValue, bool) {
+	s.highs.add(bar.High)
+	s.lows.add(bar.Low)
+	if !s.highs.full {
+		return StochasticValue{}, false
+	}
+	high, low := s.highs.values[0], s.lows.values[0]
+	for i := range s.highs.values {
+		high = max(high, s.highs.values[i])
+		low = min(low, s.lows.values[i])
+	}
+	k := 50.0
+	if high > low {
+		k = 100 * (bar.Close - low) / (high - low)
+	}
+	d, ok := s.d.Update(k)
+	if !ok {
+		return StochasticValue{}, false
+	}
+	return StochasticValue{K: k, D: d}, true
+}
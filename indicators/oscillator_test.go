@@ -0,0 +1,33 @@
+package indicators
+
+import "testing"
+
+func TestRSI(t *testing.T) {
+	if got := Compute([]float64{1, 2, 1, 3}, must(NewRSI(2)).Update); !approxEqual(got, []float64{50, 100 - 100.0/6}) {
+		t.Errorf("got %v, want [50 83.33]", got)
+	}
+	if got := Compute([]float64{1, 2, 3}, must(NewRSI(2)).Update); !approxEqual(got, []float64{100}) {
+		t.Errorf("got %v, want [100] without losses", got)
+	}
+}
+
+func TestATR(t *testing.T) {
+	bars := []Bar{{High: 2, Low: 1, Close: 1.5}, {High: 3, Low: 2, Close: 2.5}, {High: 2.6, Low: 2.4, Close: 2.5}}
+	if got := Compute(bars, must(NewATR(2)).Update); !approxEqual(got, []float64{1.25, 0.725}) {
+		t.Errorf("got %v, want [1.25 0.725]", got)
+	}
+}
+
+func TestStochastic(t *testing.T) {
+	bars := []Bar{{High: 2, Low: 1, Close: 1.5}, {High: 3, Low: 2, Close: 2.5}, {High: 2.6, Low: 2.4, Close: 2.5}}
+	got := Compute(bars, must(NewStochastic(2, 2)).Update)
+	if len(got) != 1 || got[0] != (StochasticValue{K: 50, D: 62.5}) {
+		t.Errorf("got %+v, want K 50 and D 62.5", got)
+	}
+	if _, err := NewStochastic(0, 3); err == nil {
+		t.Error("got no error for a zero k period")
+	}
+	if _, err := NewStochastic(14, -1); err == nil {
+		t.Error("got no error for a negative d period")
+	}
+}
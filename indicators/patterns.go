@@ -0,0 +1,138 @@
+package indicators
+
+import (
+	"math"
+	"time"
+
+	"github.com/s-shiga/oanda-go"
+)
+
+// Pattern is a candlestick pattern recognized by a [PatternDetector].
+type Pattern string
+
+const (
+	// PatternDoji is a Bar whose body is at most a tenth of its range: the open and close are
+	// nearly equal.
+	PatternDoji Pattern = "DOJI"
+	// PatternPinBar is a Bar with one wick of at least two thirds of its range and a body of at
+	// most a third of it. A long lower wick is bullish and a long upper wick bearish.
+	PatternPinBar Pattern = "PIN_BAR"
+	// PatternEngulfing is a Bar whose body covers the opposite body of the previous Bar and is
+	// larger than it. A rising Bar engulfing a falling one is bullish, and the reverse bearish.
+	PatternEngulfing Pattern = "ENGULFING"
+	// PatternInsideBar is a Bar whose high and low are strictly within the range of the previous
+	// Bar.
+	PatternInsideBar Pattern = "INSIDE_BAR"
+	// PatternThreeBarReversal is three Bars whose middle one has the lowest low, with the third
+	// closing above the high of the middle one, which is bullish, or the mirror image with the
+	// highest high, which is bearish.
+	PatternThreeBarReversal Pattern = "THREE_BAR_REVERSAL"
+)
+
+// Bias is the direction a pattern signals.
+type Bias int
+
+const (
+	// BiasNeutral signals indecision, such as a doji or an inside bar.
+	BiasNeutral Bias = 0
+	// BiasBullish signals a rise.
+	BiasBullish Bias = 1
+	// BiasBearish signals a fall.
+	BiasBearish Bias = -1
+)
+
+// PatternMatch is a pattern completed by a Bar.
+type PatternMatch struct {
+	Pattern Pattern
+	Bias    Bias
+}
+
+// PatternEvent is a pattern completed by a candlestick of a series, returned by
+// [DetectPatterns].
+type PatternEvent struct {
+	PatternMatch
+	// Index is the index of the candlestick that completed the pattern.
+	Index int
+	// Time is the start time of the candlestick that completed the pattern.
+	Time time.Time
+}
+
+// PatternDetector recognizes candlestick patterns as Bars are added, such as the completed
+// candlesticks of a live series. Create one with [NewPatternDetector].
+type PatternDetector struct {
+	bars  [3]Bar
+	count int
+}
+
+// NewPatternDetector creates a new PatternDetector.
+func NewPatternDetector() *PatternDetector {
+	return &PatternDetector{}
+}
+
+// Update adds bar and returns the patterns it completes, in the order doji, pin bar, engulfing,
+// inside bar and three-bar reversal, or nil if there are none. Patterns of several Bars need
+// the previous Bars to have been added.
+func (d *PatternDetector) Update(bar Bar) []PatternMatch {
+	d.bars[0], d.bars[1], d.bars[2] = d.bars[1], d.bars[2], bar
+	d.count = min(d.count+1, len(d.bars))
+	var matches []PatternMatch
+	bodyTop, bodyBottom := max(bar.Open, bar.Close), min(bar.Open, bar.Close)
+	body, span := bodyTop-bodyBottom, bar.High-bar.Low
+	if span > 0 {
+		if body <= span/10 {
+			matches = append(matches, PatternMatch{PatternDoji, BiasNeutral})
+		}
+		if body <= span/3 {
+			switch {
+			case bodyBottom-bar.Low >= span*2/3:
+				matches = append(matches, PatternMatch{PatternPinBar, BiasBullish})
+			case bar.High-bodyTop >= span*2/3:
+				matches = append(matches, PatternMatch{PatternPinBar, BiasBearish})
+			}
+		}
+	}
+	if d.count >= 2 {
+		prev := d.bars[1]
+		prevBody := math.Abs(prev.Close - prev.Open)
+		switch {
+		case prev.Close < prev.Open && bar.Close > bar.Open && bar.Open <= prev.Close && bar.Close >= prev.Open && body > prevBody:
+			matches = append(matches, PatternMatch{PatternEngulfing, BiasBullish})
+		case prev.Close > prev.Open && bar.Close < bar.Open && bar.Open >= prev.Close && bar.Close <= prev.Open && body > prevBody:
+			matches = append(matches, PatternMatch{PatternEngulfing, BiasBearish})
+		}
+		if bar.High < prev.High && bar.Low > prev.Low {
+			matches = append(matches, PatternMatch{PatternInsideBar, BiasNeutral})
+		}
+	}
+	if d.count == 3 {
+		first, middle := d.bars[0], d.bars[1]
+		switch {
+		case middle.Low < first.Low && middle.Low < bar.Low && bar.Close > middle.High:
+			matches = append(matches, PatternMatch{PatternThreeBarReversal, BiasBullish})
+		case middle.High > first.High && middle.High > bar.High && bar.Close < middle.Low:
+			matches = append(matches, PatternMatch{PatternThreeBarReversal, BiasBearish})
+		}
+	}
+	return matches
+}
+
+// DetectPatterns returns the patterns completed by the candlesticks of candles, with the prices
+// selected by source, in the order of the candlesticks.
+func DetectPatterns(candles []oanda.Candlestick, source Source) ([]PatternEvent, error) {
+	bars, err := Bars(candles, source)
+	if err != nil {
+		return nil, err
+	}
+	detector := NewPatternDetector()
+	var events []PatternEvent
+	for i, bar := range bars {
+		for _, match := range detector.Update(bar) {
+			event := PatternEvent{PatternMatch: match, Index: i}
+			if candles[i].Time.Time != nil {
+				event.Time = *candles[i].Time.Time
+			}
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
@@ -0,0 +1,70 @@
+package indicators
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/s-shiga/oanda-go"
+)
+
+func TestPatternDetector(t *testing.T) {
+	tests := []struct {
+		name string
+		bars []Bar
+		want []PatternMatch
+	}{
+		{"doji", []Bar{{Open: 1, High: 2, Low: 0, Close: 1.05}}, []PatternMatch{{PatternDoji, BiasNeutral}}},
+		{"bullish pin bar", []Bar{{Open: 1.6, High: 2, Low: 0, Close: 1.9}}, []PatternMatch{{PatternPinBar, BiasBullish}}},
+		{"bearish pin bar", []Bar{{Open: 0.4, High: 2, Low: 0, Close: 0.1}}, []PatternMatch{{PatternPinBar, BiasBearish}}},
+		{"bullish engulfing", []Bar{
+			{Open: 2, High: 2.1, Low: 0.9, Close: 1},
+			{Open: 0.9, High: 2.3, Low: 0.8, Close: 2.2},
+		}, []PatternMatch{{PatternEngulfing, BiasBullish}}},
+		{"bearish engulfing", []Bar{
+			{Open: 1, High: 2.1, Low: 0.9, Close: 2},
+			{Open: 2.1, High: 2.2, Low: 0.7, Close: 0.8},
+		}, []PatternMatch{{PatternEngulfing, BiasBearish}}},
+		{"inside bar", []Bar{
+			{Open: 0.9, High: 2.3, Low: 0.8, Close: 2.2},
+			{Open: 2, High: 2.2, Low: 1.9, Close: 2.15},
+		}, []PatternMatch{{PatternInsideBar, BiasNeutral}}},
+		{"bearish three-bar reversal", []Bar{
+			{Open: 1.1, High: 1.2, Low: 1.0, Close: 1.15},
+			{Open: 1.15, High: 1.3, Low: 1.1, Close: 1.25},
+			{Open: 1.24, High: 1.25, Low: 1.0, Close: 1.05},
+		}, []PatternMatch{{PatternThreeBarReversal, BiasBearish}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewPatternDetector()
+			var got []PatternMatch
+			for _, bar := range tt.bars {
+				got = d.Update(bar)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectPatterns(t *testing.T) {
+	at := time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)
+	candles := []oanda.Candlestick{
+		{Mid: &oanda.CandlestickData{O: "1.10", H: "1.20", L: "1.00", C: "1.05"}},
+		{Mid: &oanda.CandlestickData{O: "1.05", H: "1.10", L: "0.90", C: "0.95"}},
+		{Time: oanda.DateTime{Time: &at}, Mid: &oanda.CandlestickData{O: "0.96", H: "1.20", L: "0.95", C: "1.15"}},
+	}
+	events, err := DetectPatterns(candles, Mid)
+	if err != nil {
+		t.Fatalf("failed to detect patterns: %v", err)
+	}
+	want := []PatternEvent{{PatternMatch: PatternMatch{PatternThreeBarReversal, BiasBullish}, Index: 2, Time: at}}
+	if !slices.Equal(events, want) {
+		t.Errorf("got %+v, want %+v", events, want)
+	}
+	if _, err := DetectPatterns(candles, Bid); err == nil {
+		t.Error("got no error for missing bid prices")
+	}
+}
@@ -4,9 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
+	"math/big"
 	"net/url"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -64,6 +68,30 @@ const (
 	M CandlestickGranularity = "M"
 )
 
+// Duration returns the length of the granularity's candlesticks, and an error for M, whose
+// candlesticks vary in length. Besides the granularities supported by the API, any number of
+// seconds, minutes or hours in the same notation is accepted, such as "S1" for the 1 second
+// candlesticks built by a [CandleAggregator].
+func (g CandlestickGranularity) Duration() (time.Duration, error) {
+	switch g {
+	case D:
+		return 24 * time.Hour, nil
+	case W:
+		return 7 * 24 * time.Hour, nil
+	case M:
+		return 0, errors.New("monthly candlesticks have no fixed duration")
+	}
+	units := map[byte]time.Duration{'S': time.Second, 'M': time.Minute, 'H': time.Hour}
+	if len(g) < 2 || units[g[0]] == 0 {
+		return 0, fmt.Errorf("invalid granularity %q", g)
+	}
+	n, err := strconv.Atoi(string(g[1:]))
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid granularity %q", g)
+	}
+	return time.Duration(n) * units[g[0]], nil
+}
+
 // WeeklyAlignment specifies the day of the week used for granularity that has weekly alignment.
 type WeeklyAlignment string
 
@@ -84,19 +112,84 @@ const (
 	WeeklyAlignmentSunday WeeklyAlignment = "Sunday"
 )
 
-// Candlestick represents a candlestick for an instrument.
+// PriceComponents is a set of the price components of candlesticks: bid, ask and midpoint. The
+// zero value selects the API's default, midpoint candlesticks.
+type PriceComponents uint8
+
+const (
+	// PriceComponentBid selects bid-based candlesticks.
+	PriceComponentBid PriceComponents = 1 << iota
+	// PriceComponentAsk selects ask-based candlesticks.
+	PriceComponentAsk
+	// PriceComponentMid selects midpoint-based candlesticks.
+	PriceComponentMid
+)
+
+// ParsePriceComponents parses a PricingComponent such as "BA" into a PriceComponents. It returns
+// an error if price has characters other than B, A and M, or repeats one.
+func ParsePriceComponents(price PricingComponent) (PriceComponents, error) {
+	var components PriceComponents
+	for i := 0; i < len(price); i++ {
+		var c PriceComponents
+		switch price[i] {
+		case 'B':
+			c = PriceComponentBid
+		case 'A':
+			c = PriceComponentAsk
+		case 'M':
+			c = PriceComponentMid
+		}
+		if c == 0 || components.Has(c) {
+			return 0, fmt.Errorf("invalid price component %q", price)
+		}
+		components |= c
+	}
+	return components, nil
+}
+
+// Has reports whether p includes all of components.
+func (p PriceComponents) Has(components PriceComponents) bool {
+	return p&components == components
+}
+
+// String returns p as a PricingComponent, such as "BA", with the components in the order bid,
+// ask and mid, or "" if p is empty.
+func (p PriceComponents) String() string {
+	var b strings.Builder
+	if p.Has(PriceComponentBid) {
+		b.WriteByte('B')
+	}
+	if p.Has(PriceComponentAsk) {
+		b.WriteByte('A')
+	}
+	if p.Has(PriceComponentMid) {
+		b.WriteByte('M')
+	}
+	return b.String()
+}
+
+// validate returns an error if p has bits other than those of the price components.
+func (p PriceComponents) validate() error {
+	if p&^(PriceComponentBid|PriceComponentAsk|PriceComponentMid) != 0 {
+		return fmt.Errorf("invalid price components %#x", uint8(p))
+	}
+	return nil
+}
+
+// Candlestick represents a candlestick for an instrument. Only the price components that were
+// requested are decoded; the others are nil.
 type Candlestick struct {
 	// Time is the start time of the candlestick.
 	Time DateTime `json:"time"`
 	// Bid contains the candlestick data based on bid prices. Only provided if bid-based candles
 	// were requested.
-	Bid CandlestickData `json:"bid"`
+	Bid *CandlestickData `json:"bid,omitempty"`
 	// Ask contains the candlestick data based on ask prices. Only provided if ask-based candles
 	// were requested.
-	Ask CandlestickData `json:"ask"`
+	Ask *CandlestickData `json:"ask,omitempty"`
 	// Mid contains the candlestick data based on midpoint prices. Only provided if midpoint-based
 	// candles were requested.
-	Mid CandlestickData `json:"mid"`
+	Mid *CandlestickData `json:"mid,omitempty"`
 	// Volume is the number of prices created during the time-range represented by the candlestick.
 	Volume int `json:"volume"`
 	// Complete indicates whether or not the candlestick is complete. A complete candlestick is one
@@ -104,6 +197,35 @@ type Candlestick struct {
 	Complete bool `json:"complete"`
 }
 
+// Components returns the price components present in c.
+func (c Candlestick) Components() PriceComponents {
+	var components PriceComponents
+	if c.Bid != nil {
+		components |= PriceComponentBid
+	}
+	if c.Ask != nil {
+		components |= PriceComponentAsk
+	}
+	if c.Mid != nil {
+		components |= PriceComponentMid
+	}
+	return components
+}
+
+// Data returns the candlestick data of component, one of PriceComponentBid, PriceComponentAsk
+// and PriceComponentMid, or nil if c does not have it.
+func (c Candlestick) Data(component PriceComponents) *CandlestickData {
+	switch component {
+	case PriceComponentBid:
+		return c.Bid
+	case PriceComponentAsk:
+		return c.Ask
+	case PriceComponentMid:
+		return c.Mid
+	}
+	return nil
+}
+
 // CandlestickData contains the price data (open, high, low, close) for a candlestick.
 type CandlestickData struct {
 	// O is the first (open) price in the time-range represented by the candlestick.
@@ -126,6 +248,253 @@ type CandlestickResponse struct {
 	Candles []Candlestick `json:"candles"`
 }
 
+// OrderBook represents the order book of an instrument: the percentages of pending Orders in
+// price buckets around the price at the time of the snapshot.
+type OrderBook struct {
+	// Instrument is the order book's instrument.
+	Instrument InstrumentName `json:"instrument"`
+	// Time is the time when the order book snapshot was created.
+	Time DateTime `json:"time"`
+	// Price is the price (midpoint) for the order book's instrument at the time of the order book
+	// snapshot.
+	Price PriceValue `json:"price"`
+	// BucketWidth is the price width for each bucket. Each bucket covers the price range from the
+	// bucket's price to the bucket's price + bucketWidth.
+	BucketWidth PriceValue `json:"bucketWidth"`
+	// Buckets is the partitioned order book, divided into buckets using a default bucket width.
+	// These buckets are only provided for price ranges which actually contain order or position
+	// data.
+	Buckets []OrderBookBucket `json:"buckets"`
+}
+
+// OrderBookBucket represents a price range bucket of an [OrderBook].
+type OrderBookBucket struct {
+	// Price is the lowest price (inclusive) covered by the bucket. The bucket covers the price
+	// range from the price to price + the order book's bucketWidth.
+	Price PriceValue `json:"price"`
+	// LongCountPercent is the percentage of the total number of orders represented by the long
+	// orders found in this bucket.
+	LongCountPercent DecimalNumber `json:"longCountPercent"`
+	// ShortCountPercent is the percentage of the total number of orders represented by the short
+	// orders found in this bucket.
+	ShortCountPercent DecimalNumber `json:"shortCountPercent"`
+}
+
+// NetCountPercent returns the bucket's long percentage minus its short percentage.
+func (b OrderBookBucket) NetCountPercent() (float64, error) {
+	return netCountPercent(b.LongCountPercent, b.ShortCountPercent)
+}
+
+// BucketAt returns the bucket whose price range contains price, and false if no bucket does,
+// which means the order book holds no orders at that price.
+func (b *OrderBook) BucketAt(price PriceValue) (OrderBookBucket, bool, error) {
+	i, err := bucketIndex(b.BucketWidth, len(b.Buckets), func(i int) PriceValue { return b.Buckets[i].Price }, price)
+	if err != nil || i < 0 {
+		return OrderBookBucket{}, false, err
+	}
+	return b.Buckets[i], true, nil
+}
+
+// CountPercentages returns the total percentages of long and short orders across the buckets,
+// and the net percentage, long minus short. A positive net means more long than short orders.
+func (b *OrderBook) CountPercentages() (long, short, net float64, err error) {
+	for _, bucket := range b.Buckets {
+		l, err := bucket.LongCountPercent.Float64()
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		s, err := bucket.ShortCountPercent.Float64()
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		long += l
+		short += s
+	}
+	return long, short, long - short, nil
+}
+
+// PositionBook represents the position book of an instrument: the percentages of open
+// Positions in price buckets around the price at the time of the snapshot.
+type PositionBook struct {
+	// Instrument is the position book's instrument.
+	Instrument InstrumentName `json:"instrument"`
+	// Time is the time when the position book snapshot was created.
+	Time DateTime `json:"time"`
+	// Price is the price (midpoint) for the position book's instrument at the time of the
+	// position book snapshot.
+	Price PriceValue `json:"price"`
+	// BucketWidth is the price width for each bucket. Each bucket covers the price range from the
+	// bucket's price to the bucket's price + bucketWidth.
+	BucketWidth PriceValue `json:"bucketWidth"`
+	// Buckets is the partitioned position book, divided into buckets using a default bucket
+	// width. These buckets are only provided for price ranges which actually contain order or
+	// position data.
+	Buckets []PositionBookBucket `json:"buckets"`
+}
+
+// PositionBookBucket represents a price range bucket of a [PositionBook].
+type PositionBookBucket struct {
+	// Price is the lowest price (inclusive) covered by the bucket. The bucket covers the price
+	// range from the price to price + the position book's bucketWidth.
+	Price PriceValue `json:"price"`
+	// LongCountPercent is the percentage of the total number of positions represented by the
+	// long positions found in this bucket.
+	LongCountPercent DecimalNumber `json:"longCountPercent"`
+	// ShortCountPercent is the percentage of the total number of positions represented by the
+	// short positions found in this bucket.
+	ShortCountPercent DecimalNumber `json:"shortCountPercent"`
+}
+
+// NetCountPercent returns the bucket's long percentage minus its short percentage.
+func (b PositionBookBucket) NetCountPercent() (float64, error) {
+	return netCountPercent(b.LongCountPercent, b.ShortCountPercent)
+}
+
+// BucketAt returns the bucket whose price range contains price, and false if no bucket does,
+// which means the position book holds no positions at that price.
+func (b *PositionBook) BucketAt(price PriceValue) (PositionBookBucket, bool, error) {
+	i, err := bucketIndex(b.BucketWidth, len(b.Buckets), func(i int) PriceValue { return b.Buckets[i].Price }, price)
+	if err != nil || i < 0 {
+		return PositionBookBucket{}, false, err
+	}
+	return b.Buckets[i], true, nil
+}
+
+// PositionBookChange is the change of a [PositionBookBucket] between two snapshots, in
+// percentage points. It is returned by [DiffPositionBooks].
+type PositionBookChange struct {
+	// Price is the lowest price of the bucket.
+	Price PriceValue
+	// LongCountPercent is the change of the bucket's long percentage.
+	LongCountPercent float64
+	// ShortCountPercent is the change of the bucket's short percentage.
+	ShortCountPercent float64
+}
+
+// NetCountPercent returns the change of the bucket's net percentage, long minus short. A positive
+// value means retail positioning in the bucket shifted towards long.
+func (c PositionBookChange) NetCountPercent() float64 {
+	return c.LongCountPercent - c.ShortCountPercent
+}
+
+// DiffPositionBooks compares two snapshots of the same instrument's position book and returns the
+// change of every bucket present in either, in ascending price order. A bucket missing from a
+// snapshot counts as holding no positions. Buckets are matched by price, so the snapshots should
+// share a bucket width.
+func DiffPositionBooks(before, after *PositionBook) ([]PositionBookChange, error) {
+	if before.Instrument != after.Instrument {
+		return nil, fmt.Errorf("cannot compare position books of %s and %s", before.Instrument, after.Instrument)
+	}
+	return diffBookBuckets[PositionBookChange](before.Buckets, after.Buckets)
+}
+
+// OrderBookChange is the change of an [OrderBookBucket] between two snapshots, in percentage
+// points. It is returned by [DiffOrderBooks].
+type OrderBookChange struct {
+	// Price is the lowest price of the bucket.
+	Price PriceValue
+	// LongCountPercent is the change of the bucket's long percentage.
+	LongCountPercent float64
+	// ShortCountPercent is the change of the bucket's short percentage.
+	ShortCountPercent float64
+}
+
+// NetCountPercent returns the change of the bucket's net percentage, long minus short. A positive
+// value means pending orders in the bucket shifted towards long.
+func (c OrderBookChange) NetCountPercent() float64 {
+	return c.LongCountPercent - c.ShortCountPercent
+}
+
+// DiffOrderBooks compares two snapshots of the same instrument's order book and returns the
+// change of every bucket present in either, in ascending price order. A bucket missing from a
+// snapshot counts as holding no orders. Buckets are matched by price, so the snapshots should
+// share a bucket width.
+func DiffOrderBooks(before, after *OrderBook) ([]OrderBookChange, error) {
+	if before.Instrument != after.Instrument {
+		return nil, fmt.Errorf("cannot compare order books of %s and %s", before.Instrument, after.Instrument)
+	}
+	return diffBookBuckets[OrderBookChange](before.Buckets, after.Buckets)
+}
+
+// diffBookBuckets returns the change of every bucket present in before or after, in ascending
+// price order.
+func diffBookBuckets[C OrderBookChange | PositionBookChange, B OrderBookBucket | PositionBookBucket](before, after []B) ([]C, error) {
+	type change struct {
+		price *big.Rat
+		PositionBookChange
+	}
+	changes := map[string]*change{}
+	for _, b := range []struct {
+		buckets []B
+		sign    float64
+	}{{before, -1}, {after, 1}} {
+		for _, bucket := range b.buckets {
+			bucket := PositionBookBucket(bucket)
+			price, err := bucket.Price.Rat()
+			if err != nil {
+				return nil, fmt.Errorf("invalid bucket price: %w", err)
+			}
+			long, err := bucket.LongCountPercent.Float64()
+			if err != nil {
+				return nil, err
+			}
+			short, err := bucket.ShortCountPercent.Float64()
+			if err != nil {
+				return nil, err
+			}
+			c, ok := changes[price.RatString()]
+			if !ok {
+				c = &change{price: price, PositionBookChange: PositionBookChange{Price: bucket.Price}}
+				changes[price.RatString()] = c
+			}
+			c.LongCountPercent += b.sign * long
+			c.ShortCountPercent += b.sign * short
+		}
+	}
+	sorted := slices.SortedFunc(maps.Values(changes), func(a, b *change) int { return a.price.Cmp(b.price) })
+	result := make([]C, len(sorted))
+	for i, c := range sorted {
+		result[i] = C(c.PositionBookChange)
+	}
+	return result, nil
+}
+
+func netCountPercent(long, short DecimalNumber) (float64, error) {
+	l, err := long.Float64()
+	if err != nil {
+		return 0, err
+	}
+	s, err := short.Float64()
+	if err != nil {
+		return 0, err
+	}
+	return l - s, nil
+}
+
+// bucketIndex returns the index of the bucket, among n with the given width and prices, whose
+// price range [price, price+width) contains price, or -1 if none does.
+func bucketIndex(width PriceValue, n int, priceOf func(int) PriceValue, price PriceValue) (int, error) {
+	w, err := width.Rat()
+	if err != nil {
+		return -1, fmt.Errorf("invalid bucket width: %w", err)
+	}
+	p, err := price.Rat()
+	if err != nil {
+		return -1, err
+	}
+	for i := range n {
+		low, err := priceOf(i).Rat()
+		if err != nil {
+			return -1, fmt.Errorf("invalid bucket price: %w", err)
+		}
+		high := new(big.Rat).Add(low, w)
+		if p.Cmp(low) >= 0 && p.Cmp(high) < 0 {
+			return i, nil
+		}
+	}
+	return -1, nil
+}
+
 // ------------------------------------------------------------------
 // Endpoints https://developer.oanda.com/rest-live-v20/instrument-ep/
 // ------------------------------------------------------------------
@@ -166,8 +535,9 @@ func (s *instrumentService) List(ctx context.Context, instruments ...InstrumentN
 type CandlesticksRequest struct {
 	// Instrument is the name of the instrument to get candlestick data for.
 	Instrument InstrumentName
-	// Price is the price component(s) to get candlestick data for (M for mid, B for bid, A for ask).
-	Price PricingComponent
+	// Price is the price components to get candlestick data for. The default is midpoint
+	// candlesticks.
+	Price PriceComponents
 	// Granularity is the granularity of the candlesticks to fetch.
 	Granularity CandlestickGranularity
 	// Count is the number of candlesticks to return. Cannot be specified with both From and To.
@@ -196,7 +566,6 @@ type CandlesticksRequest struct {
 func NewCandlesticksRequest(instrument InstrumentName, granularity CandlestickGranularity) *CandlesticksRequest {
 	return &CandlesticksRequest{
 		Instrument:      instrument,
-		Price:           "",
 		Granularity:     granularity,
 		Smooth:          false,
 		IncludeFirst:    true,
@@ -206,25 +575,19 @@ func NewCandlesticksRequest(instrument InstrumentName, granularity CandlestickGr
 
 // Mid adds midpoint-based candlestick data to the request.
 func (req *CandlesticksRequest) Mid() *CandlesticksRequest {
-	if !strings.Contains(req.Price, "M") {
-		req.Price += "M"
-	}
+	req.Price |= PriceComponentMid
 	return req
 }
 
 // Bid adds bid-based candlestick data to the request.
 func (req *CandlesticksRequest) Bid() *CandlesticksRequest {
-	if !strings.Contains(req.Price, "B") {
-		req.Price += "B"
-	}
+	req.Price |= PriceComponentBid
 	return req
 }
 
 // Ask adds ask-based candlestick data to the request.
 func (req *CandlesticksRequest) Ask() *CandlesticksRequest {
-	if !strings.Contains(req.Price, "A") {
-		req.Price += "A"
-	}
+	req.Price |= PriceComponentAsk
 	return req
 }
 
@@ -276,8 +639,61 @@ func (req *CandlesticksRequest) SetWeeklyAlignment(weeklyAlignment WeeklyAlignme
 	return req
 }
 
-// validate checks that the request parameters are valid.
+// SetAlignment sets the daily alignment, alignment timezone and weekly alignment of the
+// candlesticks to those of alignment, so that the candlesticks line up with those of a
+// [CandleAggregator] or [CandleValidator] set to the same alignment.
+func (req *CandlesticksRequest) SetAlignment(alignment CandleAlignment) *CandlesticksRequest {
+	req.SetDailyAlignment(alignment.DailyAlignment)
+	req.SetAlignmentTimezone(alignment.location().String())
+	if alignment.WeeklyAlignment != "" {
+		req.SetWeeklyAlignment(alignment.WeeklyAlignment)
+	} else {
+		req.SetWeeklyAlignment(WeeklyAlignmentFriday)
+	}
+	return req
+}
+
+// Alignment returns the alignment of the candlesticks of the request, with the defaults of the
+// API for the parameters that are not set: 17:00 in America/New_York and weeks starting on
+// Friday. It returns an error if the timezone cannot be loaded.
+func (req *CandlesticksRequest) Alignment() (CandleAlignment, error) {
+	alignment := CandleAlignment{DailyAlignment: 17, WeeklyAlignment: req.WeeklyAlignment}
+	if req.DailyAlignment != nil {
+		alignment.DailyAlignment = *req.DailyAlignment
+	}
+	timezone := "America/New_York"
+	if req.AlignmentTimezone != nil {
+		timezone = *req.AlignmentTimezone
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return CandleAlignment{}, fmt.Errorf("failed to load alignment timezone: %w", err)
+	}
+	alignment.Location = location
+	if alignment.WeeklyAlignment == "" {
+		alignment.WeeklyAlignment = WeeklyAlignmentFriday
+	}
+	return alignment, nil
+}
+
+// validate checks that the request parameters are valid and consistent, so that requests the
+// API would reject with an opaque 400 fail with an error naming the parameter.
 func (req *CandlesticksRequest) validate() error {
+	if req.Instrument == "" {
+		return errors.New("missing instrument")
+	}
+	if err := req.Granularity.validate(); err != nil {
+		return err
+	}
+	if err := req.Price.validate(); err != nil {
+		return err
+	}
+	if req.From != nil && req.To != nil && !req.From.Before(*req.To) {
+		return errors.New("from must be before to")
+	}
+	if !req.IncludeFirst && req.From == nil {
+		return errors.New("includeFirst can only be disabled with from")
+	}
 	if req.Count != nil {
 		if req.From != nil && req.To != nil {
 			return errors.New("count cannot be set with both from and to")
@@ -299,9 +715,31 @@ func (req *CandlesticksRequest) validate() error {
 			return errors.New("daily alignment must be between 0 and 23")
 		}
 	}
+	if err := req.WeeklyAlignment.validate(); err != nil {
+		return err
+	}
 	return nil
 }
 
+// validate returns an error if g is not a granularity supported by the API.
+func (g CandlestickGranularity) validate() error {
+	switch g {
+	case S5, S10, S15, S30, M1, M2, M4, M5, M10, M15, M30, H1, H2, H3, H4, H6, H8, H12, D, W, M:
+		return nil
+	}
+	return fmt.Errorf("invalid granularity %q", g)
+}
+
+// validate returns an error if a is not a day of the week.
+func (a WeeklyAlignment) validate() error {
+	switch a {
+	case WeeklyAlignmentMonday, WeeklyAlignmentTuesday, WeeklyAlignmentWednesday, WeeklyAlignmentThursday,
+		WeeklyAlignmentFriday, WeeklyAlignmentSaturday, WeeklyAlignmentSunday:
+		return nil
+	}
+	return fmt.Errorf("invalid weekly alignment %q", a)
+}
+
 // values validates parameters and returns url.Values for the request.
 // Fields with default values are omitted from the result.
 func (req *CandlesticksRequest) values() (url.Values, error) {
@@ -309,8 +747,8 @@ func (req *CandlesticksRequest) values() (url.Values, error) {
 		return nil, err
 	}
 	v := url.Values{}
-	if req.Price != "" {
-		v.Set("price", req.Price)
+	if req.Price != 0 {
+		v.Set("price", req.Price.String())
 	}
 	if req.Granularity != S5 {
 		v.Set("granularity", string(req.Granularity))
@@ -352,3 +790,247 @@ func (s *instrumentService) Candlesticks(ctx context.Context, req *CandlesticksR
 	}
 	return doGet[CandlestickResponse](s.client, ctx, path, v)
 }
+
+// candlesMaxCount is the largest number of candlesticks returned by one request.
+const candlesMaxCount = 5000
+
+// CandlesRange fetches the midpoint candlesticks of instrument that start within [from, to),
+// however many there are. The range is fetched with [instrumentService.Candlesticks] in
+// successive requests of up to 5000 candlesticks, each starting at the last candlestick of the
+// previous one, which is excluded so that no candlestick is returned twice. The requests are sent
+// one at a time within the rate limit of the Client set with [WithRateLimit], and retried when
+// answered with status 429. A zero to fetches up to the latest candlestick, which may be
+// incomplete.
+//
+// This corresponds to the OANDA API endpoint: GET /v3/instruments/{instrument}/candles
+//
+// Reference: https://developer.oanda.com/rest-live-v20/instrument-ep/#collapse_endpoint_1
+func (s *instrumentService) CandlesRange(
+	ctx context.Context,
+	instrument InstrumentName,
+	granularity CandlestickGranularity,
+	from, to time.Time,
+) ([]Candlestick, error) {
+	if !to.IsZero() && !from.Before(to) {
+		return nil, errors.New("from must be before to")
+	}
+	return s.candlesRange(ctx, NewCandlesticksRequest(instrument, granularity).SetFrom(from), to)
+}
+
+// candlesRange fetches the candlesticks of req, which has From set, that start before to, in
+// requests of up to 5000 candlesticks as described for CandlesRange.
+func (s *instrumentService) candlesRange(ctx context.Context, req *CandlesticksRequest, to time.Time) ([]Candlestick, error) {
+	var candles []Candlestick
+	req.SetCount(candlesMaxCount)
+	for {
+		resp, err := s.Candlesticks(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get candlesticks from %s: %w", req.From.Format(time.RFC3339), err)
+		}
+		for _, candle := range resp.Candles {
+			if candle.Time.Time == nil {
+				continue
+			}
+			if !to.IsZero() && !candle.Time.Before(to) {
+				return candles, nil
+			}
+			candles = append(candles, candle)
+		}
+		if len(resp.Candles) < candlesMaxCount {
+			return candles, nil
+		}
+		last := resp.Candles[len(resp.Candles)-1].Time
+		if last.Time == nil || !last.After(*req.From) {
+			return candles, nil
+		}
+		req.SetFrom(*last.Time).SetExcludeFirst()
+	}
+}
+
+// candlesBatchConcurrency is the number of series CandlesBatch fetches at a time when no
+// parallelism is set.
+const candlesBatchConcurrency = 4
+
+// CandlesBatchRequest describes the candlesticks of several Instruments and granularities over a
+// time range, fetched by [instrumentService.CandlesBatch]. Use [NewCandlesBatchRequest] to create
+// one, then chain setters.
+type CandlesBatchRequest struct {
+	instruments   []InstrumentName
+	granularities []CandlestickGranularity
+	price         PriceComponents
+	smooth        bool
+	alignment     *CandleAlignment
+	from, to      time.Time
+	parallelism   int
+}
+
+// NewCandlesBatchRequest creates a new CandlesBatchRequest for the candlesticks that start within
+// [from, to). A zero to fetches up to the latest candlestick.
+func NewCandlesBatchRequest(from, to time.Time) *CandlesBatchRequest {
+	return &CandlesBatchRequest{from: from, to: to}
+}
+
+// AddInstruments adds Instruments to fetch.
+func (r *CandlesBatchRequest) AddInstruments(instruments ...InstrumentName) *CandlesBatchRequest {
+	r.instruments = append(r.instruments, instruments...)
+	return r
+}
+
+// AddGranularities adds granularities to fetch for every Instrument.
+func (r *CandlesBatchRequest) AddGranularities(granularities ...CandlestickGranularity) *CandlesBatchRequest {
+	r.granularities = append(r.granularities, granularities...)
+	return r
+}
+
+// SetPrice sets the price components to fetch, such as PriceComponentBid|PriceComponentAsk. The
+// default is mid prices.
+func (r *CandlesBatchRequest) SetPrice(price PriceComponents) *CandlesBatchRequest {
+	r.price = price
+	return r
+}
+
+// SetSmooth fetches smoothed candlesticks, as [CandlesticksRequest.SetSmooth].
+func (r *CandlesBatchRequest) SetSmooth() *CandlesBatchRequest {
+	r.smooth = true
+	return r
+}
+
+// SetAlignment sets the alignment of the candlesticks, as [CandlesticksRequest.SetAlignment]. The
+// default is the alignment of the API, see [DefaultCandleAlignment].
+func (r *CandlesBatchRequest) SetAlignment(alignment CandleAlignment) *CandlesBatchRequest {
+	r.alignment = &alignment
+	return r
+}
+
+// SetParallelism sets the number of series fetched at a time. The default is 4.
+func (r *CandlesBatchRequest) SetParallelism(parallelism int) *CandlesBatchRequest {
+	r.parallelism = parallelism
+	return r
+}
+
+func (r *CandlesBatchRequest) validate() error {
+	if len(r.instruments) == 0 {
+		return errors.New("missing instruments")
+	}
+	if len(r.granularities) == 0 {
+		return errors.New("missing granularities")
+	}
+	if !r.to.IsZero() && !r.from.Before(r.to) {
+		return errors.New("from must be before to")
+	}
+	if r.alignment != nil {
+		if err := r.alignment.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CandlesBatchResult is the result of fetching the candlesticks of one Instrument and
+// granularity with [instrumentService.CandlesBatch].
+type CandlesBatchResult struct {
+	Instrument  InstrumentName
+	Granularity CandlestickGranularity
+	// Candles are the candlesticks, in time order.
+	Candles []Candlestick
+	// Err is the error that prevented fetching the candlesticks, if any.
+	Err error
+}
+
+// CandlesBatch fetches the candlesticks of every Instrument and granularity of req with
+// [instrumentService.CandlesRange] semantics, several series at a time. The requests of all the
+// series share the rate limit of the Client set with [WithRateLimit], so a high parallelism
+// waits for it rather than exceeding it, and are retried when answered with status 429. The
+// results are keyed by Instrument, in the order of the granularities of req. A series that fails
+// reports its error in its result without affecting the others; the returned error is only set
+// if req is invalid.
+//
+// This corresponds to the OANDA API endpoint: GET /v3/instruments/{instrument}/candles
+//
+// Reference: https://developer.oanda.com/rest-live-v20/instrument-ep/#collapse_endpoint_1
+func (s *instrumentService) CandlesBatch(ctx context.Context, req *CandlesBatchRequest) (map[InstrumentName][]CandlesBatchResult, error) {
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+	parallelism := req.parallelism
+	if parallelism <= 0 {
+		parallelism = candlesBatchConcurrency
+	}
+	results := make(map[InstrumentName][]CandlesBatchResult, len(req.instruments))
+	for _, instrument := range req.instruments {
+		results[instrument] = make([]CandlesBatchResult, len(req.granularities))
+	}
+	sem := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+	for instrument, series := range results {
+		for i, granularity := range req.granularities {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func() {
+				defer wg.Done()
+				defer func() { <-sem }()
+				candlesReq := NewCandlesticksRequest(instrument, granularity).SetFrom(req.from)
+				candlesReq.Price = req.price
+				candlesReq.Smooth = req.smooth
+				if req.alignment != nil {
+					candlesReq.SetAlignment(*req.alignment)
+				}
+				candles, err := s.candlesRange(ctx, candlesReq, req.to)
+				if err != nil {
+					err = fmt.Errorf("failed to get %s %s candlesticks: %w", instrument, granularity, err)
+				}
+				series[i] = CandlesBatchResult{Instrument: instrument, Granularity: granularity, Candles: candles, Err: err}
+			}()
+		}
+	}
+	wg.Wait()
+	return results, nil
+}
+
+// OrderBookResponse is the response returned by [instrumentService.OrderBook].
+type OrderBookResponse struct {
+	OrderBook OrderBook `json:"orderBook"`
+}
+
+// OrderBook fetches the order book of instrument at the snapshot time t. A zero t fetches the
+// most recent snapshot.
+//
+// This corresponds to the OANDA API endpoint: GET /v3/instruments/{instrument}/orderBook
+//
+// Reference: https://developer.oanda.com/rest-live-v20/instrument-ep/#collapse_endpoint_3
+func (s *instrumentService) OrderBook(ctx context.Context, instrument InstrumentName, t time.Time) (*OrderBook, error) {
+	path := fmt.Sprintf("/v3/instruments/%s/orderBook", instrument)
+	v := url.Values{}
+	if !t.IsZero() {
+		v.Set("time", t.Format(time.RFC3339))
+	}
+	resp, err := doGet[OrderBookResponse](s.client, ctx, path, v)
+	if err != nil {
+		return nil, err
+	}
+	return &resp.OrderBook, nil
+}
+
+// PositionBookResponse is the response returned by [instrumentService.PositionBook].
+type PositionBookResponse struct {
+	PositionBook PositionBook `json:"positionBook"`
+}
+
+// PositionBook fetches the position book of instrument at the snapshot time t. A zero t fetches
+// the most recent snapshot.
+//
+// This corresponds to the OANDA API endpoint: GET /v3/instruments/{instrument}/positionBook
+//
+// Reference: https://developer.oanda.com/rest-live-v20/instrument-ep/#collapse_endpoint_4
+func (s *instrumentService) PositionBook(ctx context.Context, instrument InstrumentName, t time.Time) (*PositionBook, error) {
+	path := fmt.Sprintf("/v3/instruments/%s/positionBook", instrument)
+	v := url.Values{}
+	if !t.IsZero() {
+		v.Set("time", t.Format(time.RFC3339))
+	}
+	resp, err := doGet[PositionBookResponse](s.client, ctx, path, v)
+	if err != nil {
+		return nil, err
+	}
+	return &resp.PositionBook, nil
+}
@@ -1,6 +1,14 @@
 package oanda
 
 import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -39,3 +47,332 @@ func TestInstrumentService_Candlesticks(t *testing.T) {
 	}
 	debugResponse(resp)
 }
+
+func TestCandlesticksRequest_Validate(t *testing.T) {
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(time.Hour)
+	tests := []struct {
+		name    string
+		req     *CandlesticksRequest
+		wantErr string
+	}{
+		{"valid", NewCandlesticksRequest("EUR_USD", H4).SetFrom(from).SetCount(10).Bid().Ask().
+			SetExcludeFirst().SetDailyAlignment(0).SetAlignmentTimezone("Asia/Tokyo").SetWeeklyAlignment(WeeklyAlignmentMonday), ""},
+		{"missing instrument", NewCandlesticksRequest("", M1), "missing instrument"},
+		{"granularity", NewCandlesticksRequest("EUR_USD", "M3"), `invalid granularity "M3"`},
+		{"price", &CandlesticksRequest{Instrument: "EUR_USD", Granularity: M1, Price: 1 << 3, IncludeFirst: true, WeeklyAlignment: WeeklyAlignmentFriday}, "invalid price components 0x8"},
+		{"count with from and to", NewCandlesticksRequest("EUR_USD", M1).SetFrom(from).SetTo(to).SetCount(10), "count cannot be set with both from and to"},
+		{"from after to", NewCandlesticksRequest("EUR_USD", M1).SetFrom(to).SetTo(from), "from must be before to"},
+		{"exclude first without from", NewCandlesticksRequest("EUR_USD", M1).SetExcludeFirst(), "includeFirst can only be disabled with from"},
+		{"daily alignment", NewCandlesticksRequest("EUR_USD", D).SetDailyAlignment(24), "daily alignment must be between 0 and 23"},
+		{"timezone", NewCandlesticksRequest("EUR_USD", D).SetAlignmentTimezone("Mars/Olympus"), "invalid timezone"},
+		{"weekly alignment", NewCandlesticksRequest("EUR_USD", W).SetWeeklyAlignment("Funday"), `invalid weekly alignment "Funday"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("got error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+	latest := NewPriceLatestCandlesticksRequest().AddCandles("EUR_USD", "H5", PriceComponentMid)
+	if err := latest.validate(); err == nil || !strings.Contains(err.Error(), `invalid granularity "H5"`) {
+		t.Errorf("got error %v for an invalid latest candle specification", err)
+	}
+}
+
+func TestPriceComponents(t *testing.T) {
+	price, err := ParsePriceComponents("MB")
+	if err != nil || price != PriceComponentBid|PriceComponentMid || price.String() != "BM" {
+		t.Errorf("got %v (%v), want BM", price, err)
+	}
+	if !price.Has(PriceComponentMid) || price.Has(PriceComponentAsk|PriceComponentMid) {
+		t.Errorf("got wrong components in %v", price)
+	}
+	for _, s := range []string{"MX", "BB", "m"} {
+		if _, err := ParsePriceComponents(s); err == nil {
+			t.Errorf("got no error for %q", s)
+		}
+	}
+	if v, err := NewCandlesticksRequest("EUR_USD", M1).Ask().Bid().Ask().values(); err != nil || v.Get("price") != "BA" {
+		t.Errorf("got price %q (%v), want BA", v.Get("price"), err)
+	}
+
+	var candle Candlestick
+	if err := json.Unmarshal([]byte(`{"time":"2025-01-01T00:00:00Z","bid":{"o":"1.1","h":"1.2","l":"1.0","c":"1.1"},"volume":1}`), &candle); err != nil {
+		t.Fatal(err)
+	}
+	if candle.Components() != PriceComponentBid || candle.Ask != nil || candle.Mid != nil ||
+		candle.Data(PriceComponentBid).H != "1.2" || candle.Data(PriceComponentMid) != nil {
+		t.Errorf("got candlestick %+v", candle)
+	}
+	b, err := json.Marshal(candle)
+	if err != nil || strings.Contains(string(b), `"ask"`) || strings.Contains(string(b), `"mid"`) {
+		t.Errorf("got JSON %s (%v) with missing components", b, err)
+	}
+}
+
+func TestInstrumentService_CandlesRange(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	latest := start.Add(12000 * time.Minute)
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		q := r.URL.Query()
+		if r.URL.Path != "/v3/instruments/EUR_USD/candles" || q.Get("granularity") != "M1" || q.Has("to") {
+			t.Errorf("unexpected request %s?%s", r.URL.Path, r.URL.RawQuery)
+		}
+		from, _ := time.Parse(time.RFC3339, q.Get("from"))
+		count, _ := strconv.Atoi(q.Get("count"))
+		if q.Get("includeFirst") == "False" {
+			from = from.Add(time.Minute)
+		}
+		var candles []string
+		for at := from; len(candles) < count && !at.After(latest); at = at.Add(time.Minute) {
+			candles = append(candles, fmt.Sprintf(`{"time":"%s","mid":{"o":"1.1","h":"1.1","l":"1.1","c":"1.1"},"complete":true}`, at.Format(time.RFC3339)))
+		}
+		_, _ = fmt.Fprintf(w, `{"instrument":"EUR_USD","granularity":"M1","candles":[%s]}`, strings.Join(candles, ","))
+	}))
+	defer server.Close()
+	client := NewClient("api-key", WithBaseURL(server.URL))
+
+	candles, err := client.Instrument.CandlesRange(t.Context(), "EUR_USD", M1, start, start.Add(11000*time.Minute))
+	if err != nil {
+		t.Fatalf("failed to get candles: %v", err)
+	}
+	if len(candles) != 11000 || requests != 3 {
+		t.Fatalf("got %d candles in %d requests, want 11000 in 3", len(candles), requests)
+	}
+	for i, candle := range candles {
+		if want := start.Add(time.Duration(i) * time.Minute); !candle.Time.Equal(want) {
+			t.Fatalf("got candle %d at %v, want %v", i, candle.Time, want)
+		}
+	}
+
+	requests = 0
+	candles, err = client.Instrument.CandlesRange(t.Context(), "EUR_USD", M1, start, time.Time{})
+	if err != nil || len(candles) != 12001 || requests != 3 {
+		t.Errorf("got %d candles in %d requests (%v), want 12001 in 3", len(candles), requests, err)
+	}
+	if _, err := client.Instrument.CandlesRange(t.Context(), "EUR_USD", M1, start, start); err == nil {
+		t.Error("got no error for an empty range")
+	}
+}
+
+func TestInstrumentService_CandlesBatch(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var mu sync.Mutex
+	var inFlight, maxInFlight int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		defer func() {
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		}()
+		q := r.URL.Query()
+		instrument := strings.Split(r.URL.Path, "/")[3]
+		if instrument == "XXX_YYY" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = fmt.Fprint(w, `{"errorMessage":"Invalid value specified for 'instrument'"}`)
+			return
+		}
+		if q.Get("price") != "BA" {
+			t.Errorf("got price %q", q.Get("price"))
+		}
+		granularity := CandlestickGranularity(q.Get("granularity"))
+		step, _ := granularity.Duration()
+		var candles []string
+		for at := start; at.Before(start.Add(time.Hour)); at = at.Add(step) {
+			candles = append(candles, fmt.Sprintf(`{"time":"%s","complete":true}`, at.Format(time.RFC3339)))
+		}
+		_, _ = fmt.Fprintf(w, `{"instrument":%q,"granularity":%q,"candles":[%s]}`, instrument, granularity, strings.Join(candles, ","))
+	}))
+	defer server.Close()
+	client := NewClient("api-key", WithBaseURL(server.URL))
+
+	req := NewCandlesBatchRequest(start, start.Add(30*time.Minute)).
+		AddInstruments("EUR_USD", "USD_JPY", "XXX_YYY").AddGranularities(M5, M10).
+		SetPrice(PriceComponentBid | PriceComponentAsk).SetParallelism(2)
+	results, err := client.Instrument.CandlesBatch(t.Context(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("got results for %d instruments, want 3", len(results))
+	}
+	for _, instrument := range []InstrumentName{"EUR_USD", "USD_JPY"} {
+		series := results[instrument]
+		if len(series) != 2 || series[0].Granularity != M5 || series[1].Granularity != M10 {
+			t.Fatalf("got %+v for %s", series, instrument)
+		}
+		if series[0].Err != nil || len(series[0].Candles) != 6 || len(series[1].Candles) != 3 {
+			t.Errorf("got %d and %d candles for %s (%v)", len(series[0].Candles), len(series[1].Candles), instrument, series[0].Err)
+		}
+	}
+	for _, result := range results["XXX_YYY"] {
+		if result.Err == nil || result.Instrument != "XXX_YYY" {
+			t.Errorf("got result %+v, want an error", result)
+		}
+	}
+	if maxInFlight > 2 {
+		t.Errorf("got %d requests in flight, want at most 2", maxInFlight)
+	}
+	if _, err := client.Instrument.CandlesBatch(t.Context(), NewCandlesBatchRequest(start, time.Time{}).AddInstruments("EUR_USD")); err == nil {
+		t.Error("got no error without granularities")
+	}
+}
+
+func TestInstrumentService_OrderBook(t *testing.T) {
+	snapshot := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v3/instruments/EUR_USD/orderBook" || r.URL.Query().Get("time") != "2025-01-01T12:00:00Z" {
+			t.Errorf("unexpected request %s?%s", r.URL.Path, r.URL.RawQuery)
+		}
+		_, _ = fmt.Fprint(w, `{"orderBook":{"instrument":"EUR_USD","time":"2025-01-01T12:00:00Z","price":"1.10020",
+			"bucketWidth":"0.00050","buckets":[
+			{"price":"1.09950","longCountPercent":"0.5000","shortCountPercent":"0.2000"},
+			{"price":"1.10000","longCountPercent":"0.3000","shortCountPercent":"0.4000"},
+			{"price":"1.10100","longCountPercent":"0.1000","shortCountPercent":"0.6000"}]}}`)
+	}))
+	defer server.Close()
+	client := NewClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+
+	book, err := client.Instrument.OrderBook(t.Context(), "EUR_USD", snapshot)
+	if err != nil {
+		t.Fatalf("failed to get order book: %v", err)
+	}
+	if book.Instrument != "EUR_USD" || !book.Time.Equal(snapshot) || len(book.Buckets) != 3 {
+		t.Fatalf("got %+v", book)
+	}
+	for _, test := range []struct {
+		price PriceValue
+		want  PriceValue
+		ok    bool
+	}{
+		{"1.10020", "1.10000", true},
+		{"1.10000", "1.10000", true},
+		{"1.09999", "1.09950", true},
+		{"1.10050", "", false},
+		{"1.09900", "", false},
+	} {
+		bucket, ok, err := book.BucketAt(test.price)
+		if err != nil || ok != test.ok || bucket.Price != test.want {
+			t.Errorf("%s: got bucket %s, %t (%v), want %s, %t", test.price, bucket.Price, ok, err, test.want, test.ok)
+		}
+	}
+	if net, err := book.Buckets[0].NetCountPercent(); err != nil || math.Abs(net-0.3) > 1e-9 {
+		t.Errorf("got bucket net %v (%v), want 0.3", net, err)
+	}
+	long, short, net, err := book.CountPercentages()
+	if err != nil || math.Abs(long-0.9) > 1e-9 || math.Abs(short-1.2) > 1e-9 || math.Abs(net+0.3) > 1e-9 {
+		t.Errorf("got %v, %v, %v (%v), want 0.9, 1.2 and -0.3", long, short, net, err)
+	}
+}
+
+func TestInstrumentService_PositionBook(t *testing.T) {
+	books := map[string]string{
+		"2025-01-01T00:00:00Z": `[
+			{"price":"1.0990","longCountPercent":"0.5000","shortCountPercent":"0.2000"},
+			{"price":"1.1000","longCountPercent":"0.3000","shortCountPercent":"0.4000"}]`,
+		"2025-01-01T04:00:00Z": `[
+			{"price":"1.1000","longCountPercent":"0.3500","shortCountPercent":"0.2000"},
+			{"price":"1.1010","longCountPercent":"0.1000","shortCountPercent":"0.0500"}]`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		snapshot := r.URL.Query().Get("time")
+		if r.URL.Path != "/v3/instruments/EUR_USD/positionBook" || books[snapshot] == "" {
+			t.Errorf("unexpected request %s?%s", r.URL.Path, r.URL.RawQuery)
+		}
+		_, _ = fmt.Fprintf(w, `{"positionBook":{"instrument":"EUR_USD","time":%q,"price":"1.1003","bucketWidth":"0.0010","buckets":%s}}`,
+			snapshot, books[snapshot])
+	}))
+	defer server.Close()
+	client := NewClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	before, err := client.Instrument.PositionBook(t.Context(), "EUR_USD", start)
+	if err != nil {
+		t.Fatalf("failed to get position book: %v", err)
+	}
+	after, err := client.Instrument.PositionBook(t.Context(), "EUR_USD", start.Add(4*time.Hour))
+	if err != nil {
+		t.Fatalf("failed to get position book: %v", err)
+	}
+	if !after.Time.Equal(start.Add(4*time.Hour)) || len(after.Buckets) != 2 {
+		t.Fatalf("got %+v", after)
+	}
+	if bucket, ok, err := after.BucketAt(after.Price); err != nil || !ok || bucket.Price != "1.1000" {
+		t.Errorf("got bucket %+v, %t (%v), want 1.1000", bucket, ok, err)
+	}
+
+	changes, err := DiffPositionBooks(before, after)
+	if err != nil {
+		t.Fatalf("failed to diff: %v", err)
+	}
+	var got []string
+	for _, c := range changes {
+		got = append(got, fmt.Sprintf("%s %+.2f %+.2f %+.2f", c.Price, c.LongCountPercent, c.ShortCountPercent, c.NetCountPercent()))
+	}
+	want := "1.0990 -0.50 -0.20 -0.30,1.1000 +0.05 -0.20 +0.25,1.1010 +0.10 +0.05 +0.05"
+	if strings.Join(got, ",") != want {
+		t.Errorf("got changes %s, want %s", strings.Join(got, ","), want)
+	}
+	if _, err := DiffPositionBooks(before, &PositionBook{Instrument: "USD_JPY"}); err == nil {
+		t.Error("got no error for different instruments")
+	}
+}
+
+func TestCandlesticksRequest_Alignment(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip("time zone database unavailable")
+	}
+	alignment := CandleAlignment{DailyAlignment: 7, Location: tokyo, WeeklyAlignment: WeeklyAlignmentMonday}
+	req := NewCandlesticksRequest("EUR_USD", W).SetAlignment(alignment).SetSmooth()
+	v, err := req.values()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := v.Encode(); got != "alignmentTimezone=Asia%2FTokyo&dailyAlignment=7&granularity=W&smooth=True&weeklyAlignment=Monday" {
+		t.Errorf("got query %s", got)
+	}
+	if got, err := req.Alignment(); err != nil || got.DailyAlignment != 7 || got.Location.String() != "Asia/Tokyo" || got.WeeklyAlignment != WeeklyAlignmentMonday {
+		t.Errorf("got alignment %+v (%v)", got, err)
+	}
+	def, err := NewCandlesticksRequest("EUR_USD", D).Alignment()
+	if err != nil || def.DailyAlignment != 17 || def.Location.String() != "America/New_York" || def.WeeklyAlignment != WeeklyAlignmentFriday {
+		t.Errorf("got default alignment %+v (%v)", def, err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("smooth") != "True" || q.Get("dailyAlignment") != "7" || q.Get("alignmentTimezone") != "Asia/Tokyo" || q.Get("weeklyAlignment") != "Monday" {
+			t.Errorf("unexpected request %s", r.URL.RawQuery)
+		}
+		_, _ = fmt.Fprint(w, `{"instrument":"EUR_USD","granularity":"W","candles":[]}`)
+	}))
+	defer server.Close()
+	client := NewClient("api-key", WithBaseURL(server.URL))
+	start := time.Date(2025, 1, 6, 7, 0, 0, 0, tokyo)
+	batch := NewCandlesBatchRequest(start, start.AddDate(0, 0, 28)).AddInstruments("EUR_USD").AddGranularities(W).
+		SetSmooth().SetAlignment(alignment)
+	results, err := client.Instrument.CandlesBatch(t.Context(), batch)
+	if err != nil || results["EUR_USD"][0].Err != nil {
+		t.Fatalf("got %+v (%v)", results, err)
+	}
+	if _, err := client.Instrument.CandlesBatch(t.Context(), batch.SetAlignment(CandleAlignment{WeeklyAlignment: "Funday"})); err == nil {
+		t.Error("got no error for an invalid alignment")
+	}
+}
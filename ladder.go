@@ -0,0 +1,157 @@
+package oanda
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+)
+
+// LadderRequest describes a ladder (or grid) of Limit Orders spaced evenly across a price range,
+// submitted with [OrderGroup.SubmitLadder]. Create one with [NewLadderRequest].
+type LadderRequest struct {
+	// Instrument is the Instrument of the Orders.
+	Instrument InstrumentName
+	// From is the price of the first level.
+	From PriceValue
+	// To is the price of the last level.
+	To PriceValue
+	// Levels is the number of Orders. A ladder of one level has a single Order at From.
+	Levels int
+	// Precision is the number of decimal places of the level prices. If negative, the larger
+	// number of decimal places of From and To is used.
+	Precision int
+	// Units returns the units of the Order at the given level, numbered from 0 at From. Negative
+	// units denote short Orders.
+	Units func(level int, price PriceValue) DecimalNumber
+	// Customize, if set, is called with every Order request before it is submitted, e.g. to set
+	// its TimeInForce or attach a Stop Loss on fill.
+	Customize func(level int, req *LimitOrderRequest)
+}
+
+// NewLadderRequest creates a new LadderRequest for levels Limit Orders of the given units each,
+// spaced evenly from one price to another.
+func NewLadderRequest(instrument InstrumentName, from, to PriceValue, levels int, units DecimalNumber) *LadderRequest {
+	return &LadderRequest{
+		Instrument: instrument,
+		From:       from,
+		To:         to,
+		Levels:     levels,
+		Precision:  -1,
+		Units:      func(int, PriceValue) DecimalNumber { return units },
+	}
+}
+
+// SetPrecision sets the number of decimal places of the level prices, which should be the
+// Instrument's DisplayPrecision.
+func (r *LadderRequest) SetPrecision(precision int) *LadderRequest {
+	r.Precision = precision
+	return r
+}
+
+// SetUnitsFunc sets a function returning the units of each level, e.g. to size the Orders
+// further from the market larger.
+func (r *LadderRequest) SetUnitsFunc(units func(level int, price PriceValue) DecimalNumber) *LadderRequest {
+	r.Units = units
+	return r
+}
+
+// SetCustomize sets a function that is called with every Order request before it is submitted.
+func (r *LadderRequest) SetCustomize(customize func(level int, req *LimitOrderRequest)) *LadderRequest {
+	r.Customize = customize
+	return r
+}
+
+// Prices returns the prices of the levels, from From to To.
+func (r *LadderRequest) Prices() ([]PriceValue, error) {
+	if r.Levels < 1 {
+		return nil, fmt.Errorf("invalid number of levels %d", r.Levels)
+	}
+	from, err := r.From.Rat()
+	if err != nil {
+		return nil, fmt.Errorf("invalid from price: %w", err)
+	}
+	to, err := r.To.Rat()
+	if err != nil {
+		return nil, fmt.Errorf("invalid to price: %w", err)
+	}
+	precision := r.Precision
+	if precision < 0 {
+		precision = max(fractionDigits(string(r.From)), fractionDigits(string(r.To)))
+	}
+	step := new(big.Rat)
+	if r.Levels > 1 {
+		step.Sub(to, from)
+		step.Quo(step, big.NewRat(int64(r.Levels-1), 1))
+	}
+	prices := make([]PriceValue, r.Levels)
+	price := new(big.Rat).Set(from)
+	for i := range prices {
+		prices[i] = PriceValue(price.FloatString(precision))
+		price.Add(price, step)
+	}
+	return prices, nil
+}
+
+// Orders returns the Order requests of the levels, from From to To.
+func (r *LadderRequest) Orders() ([]*LimitOrderRequest, error) {
+	prices, err := r.Prices()
+	if err != nil {
+		return nil, err
+	}
+	orders := make([]*LimitOrderRequest, len(prices))
+	for i, price := range prices {
+		orders[i] = NewLimitOrderRequest(r.Instrument, r.Units(i, price), price)
+		if r.Customize != nil {
+			r.Customize(i, orders[i])
+		}
+	}
+	return orders, nil
+}
+
+// fractionDigits returns the number of digits after the decimal point of a decimal string,
+// including trailing zeros.
+func fractionDigits(s string) int {
+	_, frac, _ := strings.Cut(s, ".")
+	return len(frac)
+}
+
+// ladderConcurrency is the maximum number of create requests SubmitLadder has in flight.
+const ladderConcurrency = 8
+
+// SubmitLadder submits the Orders of req, tagged with the group's tag so that they can be
+// managed with the group's other methods, e.g. cancelled with [OrderGroup.CancelAll]. The
+// Orders are created concurrently. The returned slice holds the ID of the Order created for
+// each level, or an empty ID for levels that failed; the error joins the failures of all
+// levels. Nothing is submitted if req is invalid.
+func (g *OrderGroup) SubmitLadder(ctx context.Context, req *LadderRequest) ([]OrderID, error) {
+	orders, err := req.Orders()
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]OrderID, len(orders))
+	errs := make([]error, len(orders))
+	sem := make(chan struct{}, ladderConcurrency)
+	var wg sync.WaitGroup
+	for i, order := range orders {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			resp, err := g.Submit(ctx, order)
+			switch {
+			case err != nil:
+				errs[i] = fmt.Errorf("failed to submit level %d at %s: %w", i, order.Price, err)
+			case resp.OrderCreateTransaction == nil:
+				errs[i] = fmt.Errorf("failed to submit level %d at %s: response has no order create transaction", i, order.Price)
+			default:
+				ids[i] = resp.OrderCreateTransaction.GetID()
+			}
+		}()
+	}
+	wg.Wait()
+	return ids, errors.Join(errs...)
+}
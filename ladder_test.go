@@ -0,0 +1,92 @@
+package oanda
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestLadderRequest_Prices(t *testing.T) {
+	tests := []struct {
+		name string
+		req  *LadderRequest
+		want []PriceValue
+	}{
+		{
+			name: "ascending",
+			req:  NewLadderRequest("EUR_USD", "1.10000", "1.10200", 5, "100"),
+			want: []PriceValue{"1.10000", "1.10050", "1.10100", "1.10150", "1.10200"},
+		},
+		{
+			name: "descending with precision",
+			req:  NewLadderRequest("USD_JPY", "150", "149", 3, "100").SetPrecision(3),
+			want: []PriceValue{"150.000", "149.500", "149.000"},
+		},
+		{
+			name: "single level",
+			req:  NewLadderRequest("EUR_USD", "1.1", "1.2", 1, "100"),
+			want: []PriceValue{"1.1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.req.Prices()
+			if err != nil {
+				t.Fatalf("failed to compute prices: %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if _, err := NewLadderRequest("EUR_USD", "1.1", "1.2", 0, "100").Prices(); err == nil {
+		t.Error("got no error for zero levels")
+	}
+}
+
+func TestOrderGroup_SubmitLadder(t *testing.T) {
+	var mu sync.Mutex
+	orders := make(map[PriceValue]LimitOrderRequest)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Order LimitOrderRequest `json:"order"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("failed to decode body: %v", err)
+		}
+		if body.Order.Price == "1.10100" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = fmt.Fprint(w, `{"errorCode":"PRICE_INVALID","errorMessage":"invalid price"}`)
+			return
+		}
+		mu.Lock()
+		orders[body.Order.Price] = body.Order
+		mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+		_, _ = fmt.Fprintf(w, `{"orderCreateTransaction":{"type":"LIMIT_ORDER","id":"id-%s"}}`, body.Order.Price)
+	}))
+	defer server.Close()
+	client := NewClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+
+	req := NewLadderRequest("EUR_USD", "1.10000", "1.10200", 3, "").
+		SetUnitsFunc(func(level int, _ PriceValue) DecimalNumber { return Units(int64(100 * (level + 1))) }).
+		SetCustomize(func(_ int, r *LimitOrderRequest) { r.SetGFD() })
+	ids, err := NewOrderGroup(client, "grid").SubmitLadder(t.Context(), req)
+	if err == nil {
+		t.Error("got no error for failed level")
+	}
+	if want := []OrderID{"id-1.10000", "", "id-1.10200"}; !slices.Equal(ids, want) {
+		t.Errorf("got IDs %v, want %v", ids, want)
+	}
+	order := orders["1.10200"]
+	if order.Units != "300" || order.TimeInForce != TimeInForceGFD {
+		t.Errorf("got units %s and time in force %s, want 300 and GFD", order.Units, order.TimeInForce)
+	}
+	if order.ClientExtensions == nil || order.ClientExtensions.Tag == nil || *order.ClientExtensions.Tag != "grid" {
+		t.Errorf("got client extensions %+v, want tag grid", order.ClientExtensions)
+	}
+}
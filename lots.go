@@ -0,0 +1,236 @@
+package oanda
+
+import (
+	"cmp"
+	"fmt"
+	"iter"
+	"math/big"
+	"slices"
+	"time"
+)
+
+// LotMethod is the order in which a [LotMatcher] matches closing fills against open lots.
+type LotMethod string
+
+const (
+	// LotMethodFIFO closes the oldest open lot first.
+	LotMethodFIFO LotMethod = "FIFO"
+	// LotMethodLIFO closes the most recently opened lot first.
+	LotMethodLIFO LotMethod = "LIFO"
+)
+
+// Lot is a quantity of an Instrument opened by a single fill and not yet closed.
+type Lot struct {
+	// Instrument is the Instrument of the lot.
+	Instrument InstrumentName
+	// Units is the number of units still open, positive for a long lot and negative for a short
+	// one.
+	Units DecimalNumber
+	// OpenTransactionID is the ID of the fill that opened the lot.
+	OpenTransactionID TransactionID
+	// OpenTime is the time of the fill that opened the lot.
+	OpenTime time.Time
+	// OpenPrice is the price of the fill that opened the lot.
+	OpenPrice PriceValue
+}
+
+// ClosedLot is the realized gain or loss of closing all or part of a [Lot].
+type ClosedLot struct {
+	// Instrument is the Instrument of the lot.
+	Instrument InstrumentName
+	// Units is the number of units closed, positive for a long lot and negative for a short one.
+	Units DecimalNumber
+	// OpenTransactionID is the ID of the fill that opened the lot.
+	OpenTransactionID TransactionID
+	// CloseTransactionID is the ID of the fill that closed the units.
+	CloseTransactionID TransactionID
+	// OpenTime is the time of the fill that opened the lot.
+	OpenTime time.Time
+	// CloseTime is the time of the fill that closed the units.
+	CloseTime time.Time
+	// OpenPrice is the price of the fill that opened the lot.
+	OpenPrice PriceValue
+	// ClosePrice is the price of the fill that closed the units.
+	ClosePrice PriceValue
+	// QuotePL is the gain (positive) or loss (negative) in the Instrument's quote currency.
+	QuotePL DecimalNumber
+	// PL is the gain or loss in the Account's home currency, converted with the closing fill's
+	// quote home conversion factor and rounded to the precision of the fill's realized P/L.
+	PL AccountUnits
+}
+
+// LotMatcher pairs opening and closing Order fills into lots for tax reporting. The fills of each
+// Instrument are matched against the net position in that Instrument using the matcher's
+// [LotMethod], independently of how OANDA matched them against Trades; a fill that reverses the
+// position closes every open lot and opens a new one with the remaining units. Create one with
+// [NewLotMatcher] and add Transactions in ID order.
+type LotMatcher struct {
+	method LotMethod
+	open   map[InstrumentName][]openLot
+	closed []ClosedLot
+}
+
+// openLot is an open Lot with its units and price parsed.
+type openLot struct {
+	Lot
+	units *big.Rat
+	price *big.Rat
+}
+
+// NewLotMatcher creates a new LotMatcher with no open lots that matches lots with method.
+func NewLotMatcher(method LotMethod) *LotMatcher {
+	return &LotMatcher{method: method, open: make(map[InstrumentName][]openLot)}
+}
+
+// Add adds transaction. Transactions other than Order fills are ignored.
+func (m *LotMatcher) Add(transaction Transaction) error {
+	switch t := transaction.(type) {
+	case OrderFillTransaction:
+		return m.addFill(&t)
+	case *OrderFillTransaction:
+		return m.addFill(t)
+	}
+	return nil
+}
+
+// AddAll adds every Transaction of transactions, such as the iterator returned by
+// [transactionService.Iterate]. It stops at the first error.
+func (m *LotMatcher) AddAll(transactions iter.Seq2[Transaction, error]) error {
+	for transaction, err := range transactions {
+		if err != nil {
+			return err
+		}
+		if err := m.Add(transaction); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *LotMatcher) addFill(fill *OrderFillTransaction) error {
+	if m.method != LotMethodFIFO && m.method != LotMethodLIFO {
+		return fmt.Errorf("unknown lot method %q", m.method)
+	}
+	units, err := fill.Units.Rat()
+	if err != nil {
+		return fmt.Errorf("invalid units of fill %s: %w", fill.ID, err)
+	}
+	price, err := fillPrice(fill).Rat()
+	if err != nil {
+		return fmt.Errorf("invalid price of fill %s: %w", fill.ID, err)
+	}
+	var closeTime time.Time
+	if fill.Time.Time != nil {
+		closeTime = *fill.Time.Time
+	}
+
+	lots := slices.Clone(m.open[fill.Instrument])
+	remaining := new(big.Rat).Set(units)
+	var closed []ClosedLot
+	for len(lots) > 0 && remaining.Sign() != 0 && remaining.Sign() != lots[0].units.Sign() {
+		i := 0
+		if m.method == LotMethodLIFO {
+			i = len(lots) - 1
+		}
+		lot := lots[i]
+		// Close the whole lot, or as much of it as the fill has units left for.
+		closing := new(big.Rat).Set(lot.units)
+		if new(big.Rat).Abs(remaining).Cmp(new(big.Rat).Abs(lot.units)) < 0 {
+			closing.Neg(remaining)
+		}
+		c, err := closeLot(fill, lot, closing, price, closeTime)
+		if err != nil {
+			return err
+		}
+		closed = append(closed, c)
+		remaining.Add(remaining, closing)
+		if closing.Cmp(lot.units) == 0 {
+			lots = slices.Delete(lots, i, i+1)
+			continue
+		}
+		left := new(big.Rat).Sub(lot.units, closing)
+		lots[i].units = left
+		lots[i].Units = DecimalNumber(left.FloatString(fractionDigits(string(lot.Units))))
+	}
+	if remaining.Sign() != 0 {
+		lots = append(lots, openLot{
+			Lot: Lot{
+				Instrument:        fill.Instrument,
+				Units:             DecimalNumber(remaining.FloatString(fractionDigits(string(fill.Units)))),
+				OpenTransactionID: fill.ID,
+				OpenTime:          closeTime,
+				OpenPrice:         fillPrice(fill),
+			},
+			units: remaining,
+			price: price,
+		})
+	}
+	m.open[fill.Instrument] = lots
+	m.closed = append(m.closed, closed...)
+	return nil
+}
+
+// closeLot returns the ClosedLot of closing units of lot at price.
+func closeLot(fill *OrderFillTransaction, lot openLot, units, price *big.Rat, closeTime time.Time) (ClosedLot, error) {
+	closePrice := fillPrice(fill)
+	quotePL := new(big.Rat).Sub(price, lot.price)
+	quotePL.Mul(quotePL, units)
+	factor := fill.HomeConversionFactors.GainQuoteHome.Factor
+	if factor == "" {
+		factor = fill.GainQuoteHomeConversionFactor
+	}
+	if quotePL.Sign() < 0 {
+		factor = fill.HomeConversionFactors.LossQuoteHome.Factor
+		if factor == "" {
+			factor = fill.LossQuoteHomeConversionFactor
+		}
+	}
+	if factor == "" {
+		return ClosedLot{}, fmt.Errorf("no quote home conversion factor in fill %s", fill.ID)
+	}
+	f, err := factor.Rat()
+	if err != nil {
+		return ClosedLot{}, fmt.Errorf("invalid conversion factor of fill %s: %w", fill.ID, err)
+	}
+	pl := new(big.Rat).Mul(quotePL, f)
+	plPlaces := 4
+	if fill.PL != "" {
+		plPlaces = fractionDigits(string(fill.PL))
+	}
+	unitsPlaces := max(fractionDigits(string(lot.Units)), fractionDigits(string(fill.Units)))
+	pricePlaces := max(fractionDigits(string(lot.OpenPrice)), fractionDigits(string(closePrice)))
+	return ClosedLot{
+		Instrument:         lot.Instrument,
+		Units:              DecimalNumber(units.FloatString(unitsPlaces)),
+		OpenTransactionID:  lot.OpenTransactionID,
+		CloseTransactionID: fill.ID,
+		OpenTime:           lot.OpenTime,
+		CloseTime:          closeTime,
+		OpenPrice:          lot.OpenPrice,
+		ClosePrice:         closePrice,
+		QuotePL:            DecimalNumber(quotePL.FloatString(unitsPlaces + pricePlaces)),
+		PL:                 AccountUnits(pl.FloatString(plPlaces)),
+	}, nil
+}
+
+// Open returns the lots that are still open, ordered by Instrument and then by opening time.
+func (m *LotMatcher) Open() []Lot {
+	var lots []Lot
+	for _, open := range m.open {
+		for _, lot := range open {
+			lots = append(lots, lot.Lot)
+		}
+	}
+	slices.SortFunc(lots, func(a, b Lot) int {
+		if c := cmp.Compare(a.Instrument, b.Instrument); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.OpenTime.UnixNano(), b.OpenTime.UnixNano())
+	})
+	return lots
+}
+
+// Closed returns the closed lots in the order in which they were closed.
+func (m *LotMatcher) Closed() []ClosedLot {
+	return slices.Clone(m.closed)
+}
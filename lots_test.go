@@ -0,0 +1,67 @@
+package oanda
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestLotMatcher(t *testing.T) {
+	fill := func(id, units, price string) string {
+		return fmt.Sprintf(`{"type":"ORDER_FILL","id":%q,"time":"2024-01-0%sT00:00:00Z","instrument":"EUR_USD","units":%q,"fullVWAP":%q,"pl":"0.0000",
+			"homeConversionFactors":{"gainQuoteHome":{"factor":"1"},"lossQuoteHome":{"factor":"1.5"}}}`, id, id, units, price)
+	}
+	history := []string{
+		fill("1", "100", "1.10000"),
+		fill("2", "100", "1.20000"),
+		fill("3", "-150", "1.15000"),
+		fill("4", "-100", "1.30000"),
+	}
+	tests := []struct {
+		method LotMethod
+		closed []string
+		open   string
+	}{
+		{LotMethodFIFO, []string{
+			"1->3 100 QuotePL 5.00000 PL 5.0000",
+			"2->3 50 QuotePL -2.50000 PL -3.7500",
+			"2->4 50 QuotePL 5.00000 PL 5.0000",
+		}, "4 -50 @ 1.30000"},
+		{LotMethodLIFO, []string{
+			"2->3 100 QuotePL -5.00000 PL -7.5000",
+			"1->3 50 QuotePL 2.50000 PL 2.5000",
+			"1->4 50 QuotePL 10.00000 PL 10.0000",
+		}, "4 -50 @ 1.30000"},
+	}
+	for _, tt := range tests {
+		matcher := NewLotMatcher(tt.method)
+		for _, line := range history {
+			transaction, err := unmarshalTransaction([]byte(line))
+			if err != nil {
+				t.Fatalf("failed to decode transaction: %v", err)
+			}
+			if err := matcher.Add(transaction); err != nil {
+				t.Fatalf("%s: failed to add transaction %s: %v", tt.method, transaction.GetID(), err)
+			}
+		}
+		closed := matcher.Closed()
+		if len(closed) != len(tt.closed) {
+			t.Fatalf("%s: got %d closed lots, want %d", tt.method, len(closed), len(tt.closed))
+		}
+		for i, want := range tt.closed {
+			c := closed[i]
+			if got := fmt.Sprintf("%s->%s %s QuotePL %s PL %s", c.OpenTransactionID, c.CloseTransactionID, c.Units, c.QuotePL, c.PL); got != want {
+				t.Errorf("%s: closed lot %d: got %s, want %s", tt.method, i, got, want)
+			}
+		}
+		if closed[0].OpenTime.Day() == closed[0].CloseTime.Day() {
+			t.Errorf("%s: got the same open and close time %s", tt.method, closed[0].OpenTime)
+		}
+		open := matcher.Open()
+		if len(open) != 1 {
+			t.Fatalf("%s: got %d open lots, want 1", tt.method, len(open))
+		}
+		if got := fmt.Sprintf("%s %s @ %s", open[0].OpenTransactionID, open[0].Units, open[0].OpenPrice); got != tt.open {
+			t.Errorf("%s: got open lot %s, want %s", tt.method, got, tt.open)
+		}
+	}
+}
@@ -0,0 +1,323 @@
+package oanda
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// InsufficientMarginLocal is returned by [MarginChecker.Check] when the estimated margin an
+// Order requires clearly exceeds the margin available in the Account.
+type InsufficientMarginLocal struct {
+	// Instrument is the Order's Instrument.
+	Instrument InstrumentName
+	// Units is the Order's units.
+	Units DecimalNumber
+	// RequiredMargin is the estimated margin the Order requires, in the Account's home currency.
+	RequiredMargin float64
+	// MarginAvailable is the margin available in the Account, in the Account's home currency.
+	MarginAvailable float64
+}
+
+func (e InsufficientMarginLocal) Error() string {
+	return fmt.Sprintf(
+		"insufficient margin: order for %s units of %s requires an estimated %.2f, but only %.2f is available",
+		e.Units, e.Instrument, e.RequiredMargin, e.MarginAvailable,
+	)
+}
+
+// MarginUtilizationExceeded is returned by [MarginChecker.Check] when an Order would raise the
+// Account's margin utilization above the limit set with [MarginChecker.SetMaxUtilization].
+type MarginUtilizationExceeded struct {
+	// Instrument is the Order's Instrument.
+	Instrument InstrumentName
+	// Units is the Order's units.
+	Units DecimalNumber
+	// Utilization is the estimated margin utilization after the Order is filled.
+	Utilization float64
+	// Limit is the maximum margin utilization allowed.
+	Limit float64
+}
+
+func (e MarginUtilizationExceeded) Error() string {
+	return fmt.Sprintf(
+		"margin utilization exceeded: order for %s units of %s would raise it to an estimated %.1f%%, above the limit of %.1f%%",
+		e.Units, e.Instrument, e.Utilization*100, e.Limit*100,
+	)
+}
+
+// MarginEstimate is the estimated effect of an Order on the Account's margin, in the Account's
+// home currency. It is returned by [MarginChecker.Estimate].
+type MarginEstimate struct {
+	// Required is the additional margin the Order requires.
+	Required float64
+	// MarginUsed is the margin used by the Account before the Order.
+	MarginUsed float64
+	// MarginAvailable is the margin available in the Account before the Order.
+	MarginAvailable float64
+	// NAV is the net asset value of the Account.
+	NAV float64
+}
+
+// Utilization returns the fraction of the NAV used as margin before the Order, or 0 if the NAV
+// is not positive.
+func (e MarginEstimate) Utilization() float64 {
+	if e.NAV <= 0 {
+		return 0
+	}
+	return e.MarginUsed / e.NAV
+}
+
+// UtilizationAfter returns the fraction of the NAV used as margin once the Order is filled, or 0
+// if the NAV is not positive.
+func (e MarginEstimate) UtilizationAfter() float64 {
+	if e.NAV <= 0 {
+		return 0
+	}
+	return (e.MarginUsed + e.Required) / e.NAV
+}
+
+// MarginRequired returns the margin, in the Account's home currency, required by a Position of
+// units at price: the Position's value converted with positionValueFactor, the PositionValue
+// home conversion factor of the Instrument's quote currency, times the larger of the Account's
+// and the Instrument's margin rate. An empty instrumentRate is ignored.
+func MarginRequired(
+	units DecimalNumber, price PriceValue, positionValueFactor float64, accountRate, instrumentRate DecimalNumber,
+) (float64, error) {
+	u, err := units.Float64()
+	if err != nil {
+		return 0, err
+	}
+	p, err := price.Float64()
+	if err != nil {
+		return 0, err
+	}
+	rate, err := maxMarginRate(accountRate, instrumentRate)
+	if err != nil {
+		return 0, err
+	}
+	return math.Abs(u) * p * positionValueFactor * rate, nil
+}
+
+// MarginChecker estimates the margin an Order requires and rejects it locally when the Account
+// clearly cannot afford it, saving a round trip that OANDA would answer with
+// INSUFFICIENT_MARGIN. Create one with [NewMarginChecker].
+//
+// The estimate assumes a netting Account: only the part of the Order that increases the
+// absolute size of the Instrument's Position requires margin. It is the Order's additional
+// units, valued at the Order's price (or the current mid price for Market Orders) and converted
+// to the home currency, times the larger of the Account's and the Instrument's margin rate.
+type MarginChecker struct {
+	client         *Client
+	instruments    *InstrumentCache
+	tolerance      float64
+	maxUtilization float64
+}
+
+// NewMarginChecker creates a new MarginChecker that reads the Account, Positions and prices
+// with client and Instrument margin rates from instruments. The default tolerance is 5%.
+func NewMarginChecker(client *Client, instruments *InstrumentCache) *MarginChecker {
+	return &MarginChecker{client: client, instruments: instruments, tolerance: 0.05}
+}
+
+// SetTolerance sets the fraction by which the estimated margin may exceed the available margin
+// before an Order is rejected, absorbing price movements and rounding in the estimate.
+func (m *MarginChecker) SetTolerance(tolerance float64) *MarginChecker {
+	m.tolerance = tolerance
+	return m
+}
+
+// SetMaxUtilization makes [MarginChecker.Check] also reject Orders that would raise the
+// Account's margin utilization, the fraction of its NAV used as margin, above limit, such as
+// 0.5 for 50%. A zero limit disables the check.
+func (m *MarginChecker) SetMaxUtilization(limit float64) *MarginChecker {
+	m.maxUtilization = limit
+	return m
+}
+
+// Check estimates the margin required by req and returns an [InsufficientMarginLocal] error
+// if it exceeds the margin available by more than the tolerance, or a
+// [MarginUtilizationExceeded] error if it would raise the margin utilization above the limit set
+// with [MarginChecker.SetMaxUtilization]. Orders that do not specify an Instrument, such as
+// Orders attached to a Trade, are accepted without a check.
+func (m *MarginChecker) Check(ctx context.Context, req OrderRequest) error {
+	instrument, units, _, ok := orderTarget(req)
+	if !ok {
+		return nil
+	}
+	estimate, err := m.Estimate(ctx, req)
+	if err != nil {
+		return err
+	}
+	if estimate.Required == 0 {
+		return nil
+	}
+	if estimate.Required > estimate.MarginAvailable*(1+m.tolerance) {
+		return InsufficientMarginLocal{
+			Instrument:      instrument,
+			Units:           units,
+			RequiredMargin:  estimate.Required,
+			MarginAvailable: estimate.MarginAvailable,
+		}
+	}
+	if m.maxUtilization > 0 && estimate.UtilizationAfter() > m.maxUtilization {
+		return MarginUtilizationExceeded{
+			Instrument:  instrument,
+			Units:       units,
+			Utilization: estimate.UtilizationAfter(),
+			Limit:       m.maxUtilization,
+		}
+	}
+	return nil
+}
+
+// Estimate estimates the effect of req on the Account's margin. Orders that do not specify an
+// Instrument, such as Orders attached to a Trade, require no margin.
+func (m *MarginChecker) Estimate(ctx context.Context, req OrderRequest) (*MarginEstimate, error) {
+	summary, err := m.client.Account.Summary(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account summary: %w", err)
+	}
+	estimate := &MarginEstimate{}
+	for _, f := range []struct {
+		value AccountUnits
+		dst   *float64
+	}{
+		{summary.Account.MarginUsed, &estimate.MarginUsed},
+		{summary.Account.MarginAvailable, &estimate.MarginAvailable},
+		{summary.Account.NAV, &estimate.NAV},
+	} {
+		if f.value == "" {
+			continue
+		}
+		if *f.dst, err = f.value.Float64(); err != nil {
+			return nil, err
+		}
+	}
+	if instrument, units, price, ok := orderTarget(req); ok {
+		if estimate.Required, err = m.estimate(ctx, summary.Account, instrument, units, price); err != nil {
+			return nil, err
+		}
+	}
+	return estimate, nil
+}
+
+// EstimateMargin estimates the additional margin, in the Account's home currency, required to
+// trade units of instrument at price. If price is nil, the current mid price is used.
+func (m *MarginChecker) EstimateMargin(
+	ctx context.Context, instrument InstrumentName, units DecimalNumber, price *PriceValue,
+) (float64, error) {
+	summary, err := m.client.Account.Summary(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get account summary: %w", err)
+	}
+	return m.estimate(ctx, summary.Account, instrument, units, price)
+}
+
+func (m *MarginChecker) estimate(
+	ctx context.Context, account AccountSummary, instrument InstrumentName, units DecimalNumber, price *PriceValue,
+) (float64, error) {
+	u, err := units.Float64()
+	if err != nil {
+		return 0, err
+	}
+	position, err := m.client.Position.ListByInstrument(ctx, instrument)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get position: %w", err)
+	}
+	long, err := position.Position.Long.Units.Float64()
+	if err != nil {
+		return 0, err
+	}
+	short, err := position.Position.Short.Units.Float64()
+	if err != nil {
+		return 0, err
+	}
+	net := long + short
+	added := math.Abs(net+u) - math.Abs(net)
+	if added <= 0 {
+		return 0, nil
+	}
+
+	meta, err := m.instruments.Get(ctx, instrument)
+	if err != nil {
+		return 0, err
+	}
+
+	pricing, err := m.client.Price.Information(ctx,
+		NewPriceInformationRequest().AddInstruments(instrument).SetIncludeHomeConversions())
+	if err != nil {
+		return 0, fmt.Errorf("failed to get price: %w", err)
+	}
+	if len(pricing.Prices) == 0 {
+		return 0, fmt.Errorf("no price for %s", instrument)
+	}
+	p, err := orderPrice(pricing.Prices[0], price)
+	if err != nil {
+		return 0, err
+	}
+	factor, err := positionValueFactor(pricing.HomeConversions, instrument)
+	if err != nil {
+		return 0, err
+	}
+	return MarginRequired(UnitsFloat(added), Price(p, -1), factor, account.MarginRate, meta.MarginRate)
+}
+
+// orderTarget returns the Instrument, units and price of Orders that open or extend Positions.
+func orderTarget(req OrderRequest) (InstrumentName, DecimalNumber, *PriceValue, bool) {
+	switch r := req.(type) {
+	case *MarketOrderRequest:
+		return r.Instrument, r.Units, nil, true
+	case *LimitOrderRequest:
+		return r.Instrument, r.Units, &r.Price, true
+	case *StopOrderRequest:
+		return r.Instrument, r.Units, &r.Price, true
+	case *MarketIfTouchedOrderRequest:
+		return r.Instrument, r.Units, &r.Price, true
+	default:
+		return "", "", nil, false
+	}
+}
+
+func maxMarginRate(accountRate, instrumentRate DecimalNumber) (float64, error) {
+	a, err := accountRate.Float64()
+	if err != nil {
+		return 0, err
+	}
+	if instrumentRate == "" {
+		return a, nil
+	}
+	i, err := instrumentRate.Float64()
+	if err != nil {
+		return 0, err
+	}
+	return math.Max(a, i), nil
+}
+
+// orderPrice returns the Order's price if set, or the mid price of current otherwise.
+func orderPrice(current ClientPrice, price *PriceValue) (float64, error) {
+	if price != nil {
+		return price.Float64()
+	}
+	mid, err := current.Mid()
+	if err != nil {
+		return 0, err
+	}
+	return mid.Float64()
+}
+
+// positionValueFactor returns the factor converting a Position value in the quote currency of
+// instrument into the Account's home currency.
+func positionValueFactor(conversions []HomeConversions, instrument InstrumentName) (float64, error) {
+	_, quote, ok := strings.Cut(instrument, "_")
+	if !ok {
+		return 0, fmt.Errorf("invalid instrument name %q", instrument)
+	}
+	for _, c := range conversions {
+		if string(c.Currency) == quote {
+			return c.PositionValue.Float64()
+		}
+	}
+	return 0, fmt.Errorf("no home conversion factor for %s", quote)
+}
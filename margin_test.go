@@ -0,0 +1,131 @@
+package oanda
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newMarginServer(t *testing.T, marginAvailable, longUnits string) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v3/accounts/1/summary", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, `{"account":{"marginRate":"0.02","marginAvailable":%q}}`, marginAvailable)
+	})
+	mux.HandleFunc("/v3/accounts/1/instruments", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `{"instruments":[{"name":"EUR_USD","marginRate":"0.05"}]}`)
+	})
+	mux.HandleFunc("/v3/accounts/1/pricing", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("includeHomeConversions") != "true" {
+			t.Error("home conversions not requested")
+		}
+		_, _ = fmt.Fprint(w, `{"prices":[{"type":"PRICE","bids":[{"price":"1.09990","liquidity":1}],
+			"asks":[{"price":"1.10010","liquidity":1}]}],
+			"homeConversions":[{"currency":"USD","positionValue":"0.5"}]}`)
+	})
+	mux.HandleFunc("/v3/accounts/1/positions/EUR_USD", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, `{"position":{"instrument":"EUR_USD","long":{"units":%q},"short":{"units":"0"}}}`, longUnits)
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestMarginChecker_Check(t *testing.T) {
+	tests := []struct {
+		name            string
+		marginAvailable string
+		longUnits       string
+		req             OrderRequest
+		wantRequired    float64
+	}{
+		// 10000 units * 1.1 mid * 0.5 conversion * 0.05 instrument margin rate = 275
+		{name: "sufficient", marginAvailable: "300", longUnits: "0",
+			req: NewMarketOrderRequest("EUR_USD", "10000")},
+		{name: "insufficient", marginAvailable: "200", longUnits: "0",
+			req: NewMarketOrderRequest("EUR_USD", "10000"), wantRequired: 275},
+		{name: "within tolerance", marginAvailable: "270", longUnits: "0",
+			req: NewMarketOrderRequest("EUR_USD", "10000")},
+		// 275 exceeds 261.5 by more than 5% of the margin available.
+		{name: "beyond tolerance", marginAvailable: "261.5", longUnits: "0",
+			req: NewMarketOrderRequest("EUR_USD", "10000"), wantRequired: 275},
+		{name: "limit price", marginAvailable: "250", longUnits: "0",
+			req: NewLimitOrderRequest("EUR_USD", "10000", "1.20000"), wantRequired: 300},
+		{name: "reducing position", marginAvailable: "0", longUnits: "10000",
+			req: NewMarketOrderRequest("EUR_USD", "-10000")},
+		{name: "flipping position", marginAvailable: "100", longUnits: "5000",
+			req: NewMarketOrderRequest("EUR_USD", "-15000"), wantRequired: 137.5},
+		{name: "dependent order", marginAvailable: "0", longUnits: "0",
+			req: NewTakeProfitOrderRequest("1", "1.20000")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newMarginServer(t, tt.marginAvailable, tt.longUnits)
+			defer server.Close()
+			client := NewClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+			checker := NewMarginChecker(client, NewInstrumentCache(client))
+
+			err := checker.Check(t.Context(), tt.req)
+			if tt.wantRequired == 0 {
+				if err != nil {
+					t.Fatalf("got error: %v", err)
+				}
+				return
+			}
+			var marginErr InsufficientMarginLocal
+			if !errors.As(err, &marginErr) {
+				t.Fatalf("got error %v, want InsufficientMarginLocal", err)
+			}
+			if math.Abs(marginErr.RequiredMargin-tt.wantRequired) > 1e-6 {
+				t.Errorf("got required margin %v, want %v", marginErr.RequiredMargin, tt.wantRequired)
+			}
+		})
+	}
+}
+
+func TestMarginChecker_Estimate(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v3/accounts/1/summary", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `{"account":{"marginRate":"0.02","NAV":"1000","marginUsed":"200","marginAvailable":"800"}}`)
+	})
+	mux.HandleFunc("/v3/accounts/1/instruments", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `{"instruments":[{"name":"EUR_USD","marginRate":"0.05"}]}`)
+	})
+	mux.HandleFunc("/v3/accounts/1/pricing", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `{"prices":[{"type":"PRICE","bids":[{"price":"1.09990","liquidity":1}],
+			"asks":[{"price":"1.10010","liquidity":1}]}],
+			"homeConversions":[{"currency":"USD","positionValue":"0.5"}]}`)
+	})
+	mux.HandleFunc("/v3/accounts/1/positions/EUR_USD", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `{"position":{"instrument":"EUR_USD","long":{"units":"0"},"short":{"units":"0"}}}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client := NewClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+	checker := NewMarginChecker(client, NewInstrumentCache(client))
+
+	// 10000 units * 1.1 mid * 0.5 conversion * 0.05 instrument margin rate = 275
+	estimate, err := checker.Estimate(t.Context(), NewMarketOrderRequest("EUR_USD", "10000"))
+	if err != nil {
+		t.Fatalf("failed to estimate: %v", err)
+	}
+	if math.Abs(estimate.Required-275) > 1e-6 || estimate.Utilization() != 0.2 || math.Abs(estimate.UtilizationAfter()-0.475) > 1e-9 {
+		t.Errorf("got %+v, want 275 required and utilization from 20%% to 47.5%%", estimate)
+	}
+	if err := checker.Check(t.Context(), NewMarketOrderRequest("EUR_USD", "10000")); err != nil {
+		t.Errorf("got error without a utilization limit: %v", err)
+	}
+	var utilizationErr MarginUtilizationExceeded
+	err = checker.SetMaxUtilization(0.4).Check(t.Context(), NewMarketOrderRequest("EUR_USD", "10000"))
+	if !errors.As(err, &utilizationErr) || utilizationErr.Limit != 0.4 {
+		t.Errorf("got error %v, want MarginUtilizationExceeded", err)
+	}
+
+	if required, err := MarginRequired("-1000", "150.000", 0.0067, "0.02", "0.04"); err != nil || math.Abs(required-40.2) > 1e-9 {
+		t.Errorf("got required margin %v (%v), want 40.2", required, err)
+	}
+	if required, err := MarginRequired("1000", "1.1", 1, "0.02", ""); err != nil || math.Abs(required-22) > 1e-9 {
+		t.Errorf("got required margin %v (%v), want 22", required, err)
+	}
+}
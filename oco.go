@@ -0,0 +1,333 @@
+package oanda
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// OCOPair is a pair of pending Orders managed by an [OCOManager]: when one of them is filled,
+// the other one is cancelled.
+type OCOPair struct {
+	// ID is the caller-chosen identifier of the pair.
+	ID string `json:"id"`
+	// First is the ID of the first Order of the pair.
+	First OrderID `json:"first"`
+	// Second is the ID of the second Order of the pair.
+	Second OrderID `json:"second"`
+}
+
+// sibling returns the ID of the other Order of the pair.
+func (p OCOPair) sibling(orderID OrderID) OrderID {
+	if orderID == p.First {
+		return p.Second
+	}
+	return p.First
+}
+
+// OCOStore persists the pairs tracked by an [OCOManager] so that they can be restored with
+// [OCOManager.Restore] after a crash or restart.
+type OCOStore interface {
+	// Save stores or updates a pair.
+	Save(ctx context.Context, pair OCOPair) error
+	// Delete removes the pair with the given ID. Deleting an unknown pair is not an error.
+	Delete(ctx context.Context, id string) error
+	// Load returns all stored pairs.
+	Load(ctx context.Context) ([]OCOPair, error)
+}
+
+// OCOManager implements one-cancels-other Orders on the client side, as OANDA v20 has no native
+// OCO support. It places two pending Orders, watches the transaction stream for a fill of
+// either one, and cancels the other. Create one with [NewOCOManager].
+type OCOManager struct {
+	client  *Client
+	stream  *StreamClient
+	store   OCOStore
+	onError func(OCOPair, error)
+
+	mu      sync.Mutex
+	pairs   map[string]OCOPair
+	byOrder map[OrderID]string
+}
+
+// NewOCOManager creates a new OCOManager that places and cancels Orders with client and watches
+// fills with streamClient. Both must be configured for the same Account.
+func NewOCOManager(client *Client, streamClient *StreamClient) *OCOManager {
+	return &OCOManager{
+		client:  client,
+		stream:  streamClient,
+		pairs:   make(map[string]OCOPair),
+		byOrder: make(map[OrderID]string),
+	}
+}
+
+// SetStore sets the store used to persist tracked pairs.
+func (m *OCOManager) SetStore(store OCOStore) *OCOManager {
+	m.store = store
+	return m
+}
+
+// SetErrorHandler sets a function that is called when cancelling the sibling of a filled Order
+// or persisting a pair fails while [OCOManager.Run] is running, or when checking the Orders of a
+// pair just placed with [OCOManager.Place] fails.
+func (m *OCOManager) SetErrorHandler(handler func(OCOPair, error)) *OCOManager {
+	m.onError = handler
+	return m
+}
+
+// Pairs returns the pairs currently tracked by the manager.
+func (m *OCOManager) Pairs() []OCOPair {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	pairs := make([]OCOPair, 0, len(m.pairs))
+	for _, pair := range m.pairs {
+		pairs = append(pairs, pair)
+	}
+	return pairs
+}
+
+// Place submits first and second as pending Orders and tracks them as a pair identified by id.
+// If the second Order cannot be created, the first one is cancelled, and if the pair cannot be
+// saved to the store, both are. Both requests must create Orders that stay pending; if either
+// is filled or cancelled immediately, the other one is cancelled and an error is returned.
+// Once the pair is tracked, the state of its Orders is checked so that a fill that happened
+// before [OCOManager.Run] could match it still cancels the sibling; a failure of that check is
+// passed to the error handler.
+func (m *OCOManager) Place(ctx context.Context, id string, first, second OrderRequest) (*OCOPair, error) {
+	m.mu.Lock()
+	_, exists := m.pairs[id]
+	m.mu.Unlock()
+	if exists {
+		return nil, fmt.Errorf("OCO pair %q already exists", id)
+	}
+
+	firstID, err := m.createPending(ctx, first)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create first order: %w", err)
+	}
+	secondID, err := m.createPending(ctx, second)
+	if err != nil {
+		err = errors.Join(err, m.cancelOrders(ctx, firstID))
+		return nil, fmt.Errorf("failed to create second order: %w", err)
+	}
+
+	pair := OCOPair{ID: id, First: firstID, Second: secondID}
+	if m.store != nil {
+		if err := m.store.Save(ctx, pair); err != nil {
+			err = errors.Join(err, m.cancelOrders(ctx, firstID, secondID))
+			return nil, fmt.Errorf("failed to save OCO pair: %w", err)
+		}
+	}
+	m.track(pair)
+	if err := m.reconcilePair(ctx, pair); err != nil {
+		m.handleError(pair, err)
+	}
+	return &pair, nil
+}
+
+// cancelOrders cancels the Orders of a pair that could not be placed, and returns the errors
+// of the cancellations that failed.
+func (m *OCOManager) cancelOrders(ctx context.Context, orderIDs ...OrderID) error {
+	var errs []error
+	for _, orderID := range orderIDs {
+		if _, err := m.client.Order.Cancel(ctx, orderID); err != nil {
+			errs = append(errs, fmt.Errorf("failed to cancel order %s: %w", orderID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// createPending creates an Order and returns its ID, failing if the Order did not stay pending.
+func (m *OCOManager) createPending(ctx context.Context, req OrderRequest) (OrderID, error) {
+	resp, err := m.client.Order.Create(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	if resp.OrderCreateTransaction == nil {
+		return "", errors.New("response has no order create transaction")
+	}
+	orderID := resp.OrderCreateTransaction.GetID()
+	if resp.OrderFillTransaction != nil {
+		return "", fmt.Errorf("order %s was filled immediately", orderID)
+	}
+	if reason, ok := resp.WasCancelled(); ok {
+		return "", fmt.Errorf("order %s was cancelled immediately: %s", orderID, reason)
+	}
+	return orderID, nil
+}
+
+// Restore loads the pairs from the store and reconciles them with the Account: if one Order
+// of a pair has been filled while the manager was not running, the other one is cancelled.
+// Pairs whose Orders are no longer pending are dropped.
+func (m *OCOManager) Restore(ctx context.Context) error {
+	if m.store == nil {
+		return errors.New("no OCO store set")
+	}
+	pairs, err := m.store.Load(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load OCO pairs: %w", err)
+	}
+	for _, pair := range pairs {
+		m.track(pair)
+	}
+	return m.reconcile(ctx)
+}
+
+// reconcile checks the state of the Orders of every tracked pair and resolves the pairs of
+// which an Order is no longer pending.
+func (m *OCOManager) reconcile(ctx context.Context) error {
+	for _, pair := range m.Pairs() {
+		if err := m.reconcilePair(ctx, pair); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reconcilePair checks the state of the Orders of pair and resolves the pair if an Order is no
+// longer pending. A pair already resolved by [OCOManager.Run] is left alone.
+func (m *OCOManager) reconcilePair(ctx context.Context, pair OCOPair) error {
+	for _, orderID := range []OrderID{pair.First, pair.Second} {
+		resp, err := m.client.Order.Details(ctx, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to get order %s: %w", orderID, err)
+		}
+		if resp.Order == nil {
+			continue
+		}
+		switch resp.Order.GetState() {
+		case OrderStateFilled:
+			m.filled(ctx, orderID)
+			return nil
+		case OrderStateCancelled:
+			m.cancelled(ctx, orderID, nil)
+			return nil
+		}
+	}
+	return nil
+}
+
+// Run watches the transaction stream and cancels the sibling of every tracked Order that is
+// filled. Fills that happened before the stream was opened are detected by checking the
+// state of every tracked Order. Run blocks until ctx is cancelled or the stream ends.
+func (m *OCOManager) Run(ctx context.Context) error {
+	stream, err := m.stream.TransactionStream(ctx)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+	if err := m.reconcile(ctx); err != nil {
+		return err
+	}
+	for item := range stream.Updates() {
+		switch t := item.(type) {
+		case OrderFillTransaction:
+			m.filled(ctx, t.OrderID)
+		case OrderCancelTransaction:
+			m.cancelled(ctx, t.OrderID, t.ReplacedByOrderID)
+		}
+	}
+	return stream.Err()
+}
+
+func (m *OCOManager) track(pair OCOPair) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pairs[pair.ID] = pair
+	m.byOrder[pair.First] = pair.ID
+	m.byOrder[pair.Second] = pair.ID
+}
+
+// untrack stops tracking the pair that orderID belongs to and returns it.
+func (m *OCOManager) untrack(orderID OrderID) (OCOPair, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id, ok := m.byOrder[orderID]
+	if !ok {
+		return OCOPair{}, false
+	}
+	pair := m.pairs[id]
+	delete(m.pairs, id)
+	delete(m.byOrder, pair.First)
+	delete(m.byOrder, pair.Second)
+	return pair, true
+}
+
+// filled cancels the sibling of a filled Order and forgets the pair.
+func (m *OCOManager) filled(ctx context.Context, orderID OrderID) {
+	pair, ok := m.untrack(orderID)
+	if !ok {
+		return
+	}
+	if _, err := m.client.Order.Cancel(ctx, pair.sibling(orderID)); err != nil {
+		m.handleError(pair, fmt.Errorf("failed to cancel order %s: %w", pair.sibling(orderID), err))
+	}
+	m.delete(ctx, pair)
+}
+
+// cancelled handles the cancellation of a tracked Order. If the Order was replaced, the pair
+// follows the replacing Order; otherwise the pair is forgotten and the sibling is left pending.
+func (m *OCOManager) cancelled(ctx context.Context, orderID OrderID, replacedBy *OrderID) {
+	pair, ok := m.untrack(orderID)
+	if !ok {
+		return
+	}
+	if replacedBy == nil {
+		m.delete(ctx, pair)
+		return
+	}
+	if pair.First == orderID {
+		pair.First = *replacedBy
+	} else {
+		pair.Second = *replacedBy
+	}
+	m.track(pair)
+	if m.store != nil {
+		if err := m.store.Save(ctx, pair); err != nil {
+			m.handleError(pair, fmt.Errorf("failed to save OCO pair: %w", err))
+		}
+	}
+}
+
+func (m *OCOManager) delete(ctx context.Context, pair OCOPair) {
+	if m.store == nil {
+		return
+	}
+	if err := m.store.Delete(ctx, pair.ID); err != nil {
+		m.handleError(pair, fmt.Errorf("failed to delete OCO pair: %w", err))
+	}
+}
+
+func (m *OCOManager) handleError(pair OCOPair, err error) {
+	if m.onError != nil {
+		m.onError(pair, err)
+	}
+}
+
+// FileOCOStore is an [OCOStore] that keeps pairs in a JSON file. Create one with
+// [NewFileOCOStore].
+type FileOCOStore struct {
+	store *jsonFileStore[string, OCOPair]
+}
+
+// NewFileOCOStore creates a new FileOCOStore backed by the file at path. The file is created on
+// the first Save.
+func NewFileOCOStore(path string) *FileOCOStore {
+	return &FileOCOStore{store: newJSONFileStore[string, OCOPair](path, "OCO store")}
+}
+
+// Save stores or updates a pair.
+func (s *FileOCOStore) Save(_ context.Context, pair OCOPair) error {
+	return s.store.save(pair.ID, pair)
+}
+
+// Delete removes the pair with the given ID.
+func (s *FileOCOStore) Delete(_ context.Context, id string) error {
+	return s.store.delete(id)
+}
+
+// Load returns all stored pairs.
+func (s *FileOCOStore) Load(_ context.Context) ([]OCOPair, error) {
+	return s.store.load()
+}
@@ -0,0 +1,151 @@
+package oanda
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// newOCOServer serves order creation, details and cancellation for Account 1, and a
+// transaction stream that reports a fill of order 1. Orders are pending, except for the order
+// filled, if not empty, which is reported as filled.
+func newOCOServer(t *testing.T, filled string) (*httptest.Server, func() []string) {
+	t.Helper()
+	var mu sync.Mutex
+	var nextID int
+	var cancelled []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		path := strings.TrimPrefix(r.URL.Path, "/v3/accounts/1")
+		switch {
+		case r.Method == http.MethodPost && path == "/orders":
+			nextID++
+			w.WriteHeader(http.StatusCreated)
+			_, _ = fmt.Fprintf(w, `{"orderCreateTransaction":{"type":"LIMIT_ORDER","id":"%d"},"lastTransactionID":"%d"}`, nextID, nextID)
+		case r.Method == http.MethodPut && strings.HasSuffix(path, "/cancel"):
+			id := strings.TrimSuffix(strings.TrimPrefix(path, "/orders/"), "/cancel")
+			cancelled = append(cancelled, id)
+			_, _ = fmt.Fprintf(w, `{"orderCancelTransaction":{"type":"ORDER_CANCEL","orderID":"%s"}}`, id)
+		case r.Method == http.MethodGet && strings.HasPrefix(path, "/orders/"):
+			id := strings.TrimPrefix(path, "/orders/")
+			state := OrderStatePending
+			if id == filled {
+				state = OrderStateFilled
+			}
+			_, _ = fmt.Fprintf(w, `{"order":{"type":"LIMIT","id":"%s","state":"%s"}}`, id, state)
+		case path == "/transactions/stream":
+			_, _ = fmt.Fprintln(w, `{"type":"ORDER_FILL","id":"3","orderID":"1"}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), cancelled...)
+	}
+}
+
+func TestOCOManager(t *testing.T) {
+	server, cancelled := newOCOServer(t, "")
+	defer server.Close()
+
+	client := NewClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+	streamClient := NewStreamClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+	store := NewFileOCOStore(filepath.Join(t.TempDir(), "oco.json"))
+	manager := NewOCOManager(client, streamClient).
+		SetStore(store).
+		SetErrorHandler(func(pair OCOPair, err error) {
+			t.Errorf("pair %s: %v", pair.ID, err)
+		})
+
+	pair, err := manager.Place(t.Context(), "breakout",
+		NewStopOrderRequest("EUR_USD", "100", "1.2000"),
+		NewStopOrderRequest("EUR_USD", "-100", "1.1000"))
+	if err != nil {
+		t.Fatalf("failed to place OCO pair: %v", err)
+	}
+	if pair.First != "1" || pair.Second != "2" {
+		t.Fatalf("got orders %s and %s, want 1 and 2", pair.First, pair.Second)
+	}
+	stored, err := store.Load(t.Context())
+	if err != nil || len(stored) != 1 {
+		t.Fatalf("got stored pairs %v (%v), want 1 pair", stored, err)
+	}
+
+	restored := NewOCOManager(client, streamClient).SetStore(store)
+	if err := restored.Restore(t.Context()); err != nil {
+		t.Fatalf("failed to restore OCO pairs: %v", err)
+	}
+	if got := restored.Pairs(); len(got) != 1 || got[0] != *pair {
+		t.Fatalf("got restored pairs %v, want %v", got, *pair)
+	}
+
+	if err := restored.Run(t.Context()); err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	if got := cancelled(); len(got) != 1 || got[0] != "2" {
+		t.Errorf("got cancelled orders %v, want [2]", got)
+	}
+	if got := restored.Pairs(); len(got) != 0 {
+		t.Errorf("got pairs %v after fill, want none", got)
+	}
+	if stored, _ := store.Load(t.Context()); len(stored) != 0 {
+		t.Errorf("got stored pairs %v after fill, want none", stored)
+	}
+}
+
+// failingOCOStore is an OCOStore that fails to save pairs.
+type failingOCOStore struct{}
+
+func (failingOCOStore) Save(context.Context, OCOPair) error     { return errors.New("disk full") }
+func (failingOCOStore) Delete(context.Context, string) error    { return nil }
+func (failingOCOStore) Load(context.Context) ([]OCOPair, error) { return nil, nil }
+
+func TestOCOManager_Place_SaveError(t *testing.T) {
+	server, cancelled := newOCOServer(t, "")
+	defer server.Close()
+	client := NewClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+	manager := NewOCOManager(client, nil).SetStore(failingOCOStore{})
+
+	if _, err := manager.Place(t.Context(), "breakout",
+		NewStopOrderRequest("EUR_USD", "100", "1.2000"),
+		NewStopOrderRequest("EUR_USD", "-100", "1.1000")); err == nil {
+		t.Fatal("got no error for a failing store")
+	}
+	if got := cancelled(); len(got) != 2 || got[0] != "1" || got[1] != "2" {
+		t.Errorf("got cancelled orders %v, want [1 2]", got)
+	}
+	if got := manager.Pairs(); len(got) != 0 {
+		t.Errorf("got pairs %v, want none", got)
+	}
+}
+
+func TestOCOManager_Place_FilledBeforeTracked(t *testing.T) {
+	server, cancelled := newOCOServer(t, "1")
+	defer server.Close()
+	client := NewClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+	manager := NewOCOManager(client, nil).SetErrorHandler(func(pair OCOPair, err error) {
+		t.Errorf("pair %s: %v", pair.ID, err)
+	})
+
+	if _, err := manager.Place(t.Context(), "breakout",
+		NewStopOrderRequest("EUR_USD", "100", "1.2000"),
+		NewStopOrderRequest("EUR_USD", "-100", "1.1000")); err != nil {
+		t.Fatalf("failed to place OCO pair: %v", err)
+	}
+	if got := cancelled(); len(got) != 1 || got[0] != "2" {
+		t.Errorf("got cancelled orders %v, want [2]", got)
+	}
+	if got := manager.Pairs(); len(got) != 0 {
+		t.Errorf("got pairs %v after fill, want none", got)
+	}
+}
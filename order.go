@@ -6,10 +6,15 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"iter"
+	"math/big"
 	"net/http"
 	"net/url"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 // ---------------------------------------------------------------
@@ -42,55 +47,66 @@ func unmarshalOrder(rawOrder json.RawMessage) (Order, error) {
 		if err := json.Unmarshal(rawOrder, &marketOrder); err != nil {
 			return nil, fmt.Errorf("failed to unmarshal market order: %w", err)
 		}
+		marketOrder.setRawJSON(rawOrder)
 		order = marketOrder
 	case OrderTypeFixedPrice:
 		var fixedPriceOrder FixedPriceOrder
 		if err := json.Unmarshal(rawOrder, &fixedPriceOrder); err != nil {
 			return nil, fmt.Errorf("failed to unmarshal fixed price order: %w", err)
 		}
+		fixedPriceOrder.setRawJSON(rawOrder)
 		order = fixedPriceOrder
 	case OrderTypeLimit:
 		var limitOrder LimitOrder
 		if err := json.Unmarshal(rawOrder, &limitOrder); err != nil {
 			return nil, fmt.Errorf("failed to unmarshal limit order: %w", err)
 		}
+		limitOrder.setRawJSON(rawOrder)
 		order = limitOrder
 	case OrderTypeStop:
 		var stopOrder StopOrder
 		if err := json.Unmarshal(rawOrder, &stopOrder); err != nil {
 			return nil, fmt.Errorf("failed to unmarshal stop order: %w", err)
 		}
+		stopOrder.setRawJSON(rawOrder)
 		order = stopOrder
 	case OrderTypeMarketIfTouched:
 		var marketIfTouchedOrder MarketIfTouchedOrder
 		if err := json.Unmarshal(rawOrder, &marketIfTouchedOrder); err != nil {
 			return nil, fmt.Errorf("failed to unmarshal market if touched order: %w", err)
 		}
+		marketIfTouchedOrder.setRawJSON(rawOrder)
 		order = marketIfTouchedOrder
 	case OrderTypeTakeProfit:
 		var takeProfitOrder TakeProfitOrder
 		if err := json.Unmarshal(rawOrder, &takeProfitOrder); err != nil {
 			return nil, fmt.Errorf("failed to unmarshal take profit order: %w", err)
 		}
+		takeProfitOrder.setRawJSON(rawOrder)
 		order = takeProfitOrder
 	case OrderTypeStopLoss:
 		var stopLossOrder StopLossOrder
 		if err := json.Unmarshal(rawOrder, &stopLossOrder); err != nil {
 			return nil, fmt.Errorf("failed to unmarshal stop loss order: %w", err)
 		}
+		stopLossOrder.setRawJSON(rawOrder)
 		order = stopLossOrder
 	case OrderTypeGuaranteedStopLoss:
 		var guaranteedStopLossOrder GuaranteedStopLossOrder
 		if err := json.Unmarshal(rawOrder, &guaranteedStopLossOrder); err != nil {
 			return nil, fmt.Errorf("failed to unmarshal guaranteed stop loss order: %w", err)
 		}
+		guaranteedStopLossOrder.setRawJSON(rawOrder)
 		order = guaranteedStopLossOrder
 	case OrderTypeTrailingStopLoss:
 		var trailingStopLossOrder TrailingStopLossOrder
 		if err := json.Unmarshal(rawOrder, &trailingStopLossOrder); err != nil {
 			return nil, fmt.Errorf("failed to unmarshal trailing stop loss order: %w", err)
 		}
+		trailingStopLossOrder.setRawJSON(rawOrder)
 		order = trailingStopLossOrder
+	default:
+		return unmarshalUnknownOrder(typeOnly.Type, rawOrder)
 	}
 	return order, nil
 }
@@ -120,6 +136,7 @@ type OrderBase struct {
 	ClientExtensions *ClientExtensions `json:"clientExtensions,omitempty"`
 	// Type is the type of the Order.
 	Type OrderType `json:"type"`
+	Raw
 }
 
 // TradeClosingDetails contains the Trade ID and client Trade ID of the Trade to close.
@@ -361,6 +378,9 @@ type StopOrder struct {
 	OrderBase
 	// Instrument is the name of the instrument of the Order.
 	Instrument InstrumentName `json:"instrument"`
+	// Units is the quantity requested to be filled by the Stop Order. A positive number of units
+	// results in a long Order, and a negative number of units results in a short Order.
+	Units DecimalNumber `json:"units"`
 	// Price is the price threshold specified for the Stop Order. The Stop Order will only be filled
 	// by a market price that is equal to or worse than this price.
 	Price PriceValue `json:"price"`
@@ -714,9 +734,39 @@ type MarketOrderRequest struct {
 	// TradeClientExtensions are the client extensions to add to the Trade created when the Order is filled.
 	// Do not set, modify, or delete tradeClientExtensions if your account is associated with MT4.
 	TradeClientExtensions *ClientExtensions `json:"tradeClientExtensions,omitempty"`
+	// TradeClose specifies the Trade the Market Order closes. It cannot be combined with a
+	// Position closeout.
+	TradeClose *MarketOrderTradeClose `json:"tradeClose,omitempty"`
+	// LongPositionCloseout specifies the long Position the Market Order closes out.
+	LongPositionCloseout *MarketOrderPositionCloseout `json:"longPositionCloseout,omitempty"`
+	// ShortPositionCloseout specifies the short Position the Market Order closes out.
+	ShortPositionCloseout *MarketOrderPositionCloseout `json:"shortPositionCloseout,omitempty"`
+	// explicit records the fields set with setters, which an OrderPolicy leaves unchanged.
+	explicit orderFields
 }
 
 func (r *MarketOrderRequest) body() (*bytes.Buffer, error) {
+	if r.TradeClose != nil && (r.LongPositionCloseout != nil || r.ShortPositionCloseout != nil) {
+		return nil, errors.New("trade close and position closeout cannot be set at the same time")
+	}
+	if err := validateUnits("units", r.Units); err != nil {
+		return nil, err
+	}
+	if r.TradeClose != nil {
+		if err := validateUnits("tradeClose.units", r.TradeClose.Units, UnitsAll); err != nil {
+			return nil, err
+		}
+	}
+	if r.LongPositionCloseout != nil {
+		if err := validateUnits("longPositionCloseout.units", r.LongPositionCloseout.Units, UnitsAll); err != nil {
+			return nil, err
+		}
+	}
+	if r.ShortPositionCloseout != nil {
+		if err := validateUnits("shortPositionCloseout.units", r.ShortPositionCloseout.Units, UnitsAll); err != nil {
+			return nil, err
+		}
+	}
 	return orderRequestWrapper(r)
 }
 
@@ -731,9 +781,18 @@ func NewMarketOrderRequest(instrument InstrumentName, units DecimalNumber) *Mark
 	}
 }
 
+// SetFOK sets the TimeInForce to FOK (Fill or Kill), the default, so that an [OrderPolicy] does
+// not change it.
+func (r *MarketOrderRequest) SetFOK() *MarketOrderRequest {
+	r.TimeInForce = TimeInForceFOK
+	r.explicit |= orderFieldTimeInForce
+	return r
+}
+
 // SetIOC sets the TimeInForce to IOC (Immediate or Cancel).
 func (r *MarketOrderRequest) SetIOC() *MarketOrderRequest {
 	r.TimeInForce = TimeInForceIOC
+	r.explicit |= orderFieldTimeInForce
 	return r
 }
 
@@ -746,6 +805,7 @@ func (r *MarketOrderRequest) SetPriceBound(priceBound PriceValue) *MarketOrderRe
 // SetPositionFill sets how Positions in the Account are modified when the Order is filled.
 func (r *MarketOrderRequest) SetPositionFill(positionFill OrderPositionFill) *MarketOrderRequest {
 	r.PositionFill = positionFill
+	r.explicit |= orderFieldPositionFill
 	return r
 }
 
@@ -764,6 +824,7 @@ func (r *MarketOrderRequest) SetTakeProfitOnFill(details *TakeProfitDetails) *Ma
 // SetStopLossOnFill sets the Stop Loss Order details for when the Order is filled.
 func (r *MarketOrderRequest) SetStopLossOnFill(details *StopLossDetails) *MarketOrderRequest {
 	r.StopLossOnFill = details
+	r.explicit |= orderFieldStopLossOnFill
 	return r
 }
 
@@ -785,6 +846,29 @@ func (r *MarketOrderRequest) SetTradeClientExtensions(clientExtensions *ClientEx
 	return r
 }
 
+// CloseTrade makes the Market Order close the specified Trade. Units is the number of units to
+// close, or [UnitsAll] to close the Trade fully. The dedicated [tradeService.Close] endpoint is the
+// usual way to close a Trade; this is for flows that need the order endpoint's options, such as
+// a price bound.
+func (r *MarketOrderRequest) CloseTrade(tradeID TradeID, units DecimalNumber) *MarketOrderRequest {
+	r.TradeClose = &MarketOrderTradeClose{TradeID: tradeID, Units: units}
+	return r
+}
+
+// CloseoutLongPosition makes the Market Order close out the long Position for the specified
+// instrument. Units is the number of units to close, or [UnitsAll] to close the Position fully.
+func (r *MarketOrderRequest) CloseoutLongPosition(instrument InstrumentName, units DecimalNumber) *MarketOrderRequest {
+	r.LongPositionCloseout = &MarketOrderPositionCloseout{Instrument: instrument, Units: units}
+	return r
+}
+
+// CloseoutShortPosition makes the Market Order close out the short Position for the specified
+// instrument. Units is the number of units to close, or [UnitsAll] to close the Position fully.
+func (r *MarketOrderRequest) CloseoutShortPosition(instrument InstrumentName, units DecimalNumber) *MarketOrderRequest {
+	r.ShortPositionCloseout = &MarketOrderPositionCloseout{Instrument: instrument, Units: units}
+	return r
+}
+
 // LimitOrderRequest is used to create a Limit Order.
 type LimitOrderRequest struct {
 	// Type is the type of the Order to Create. Must be set to "LIMIT" when creating a Limit Order.
@@ -830,9 +914,21 @@ type LimitOrderRequest struct {
 	// TradeClientExtensions are the client extensions to add to the Trade created when the Order is filled.
 	// Do not set, modify, or delete tradeClientExtensions if your account is associated with MT4.
 	TradeClientExtensions *ClientExtensions `json:"tradeClientExtensions,omitempty"`
+	// explicit records the fields set with setters, which an OrderPolicy leaves unchanged.
+	explicit orderFields
 }
 
 func (r *LimitOrderRequest) body() (*bytes.Buffer, error) {
+	if err := validateUnits("units", r.Units); err != nil {
+		return nil, err
+	}
+	if err := validateGTD("gtdTime", r.TimeInForce, r.GtdTime); err != nil {
+		return nil, err
+	}
+	onFill := onFillDetails{r.TakeProfitOnFill, r.StopLossOnFill, r.GuaranteedStopLossOnFill, r.TrailingStopLossOnFill}
+	if err := onFill.validateGTD(); err != nil {
+		return nil, err
+	}
 	return orderRequestWrapper(r)
 }
 
@@ -849,28 +945,46 @@ func NewLimitOrderRequest(instrument InstrumentName, units DecimalNumber, price
 	}
 }
 
+// SetGTC sets the TimeInForce to GTC (Good Till Cancelled), the default, so that an
+// [OrderPolicy] does not change it.
+func (r *LimitOrderRequest) SetGTC() *LimitOrderRequest {
+	r.TimeInForce = TimeInForceGTC
+	r.explicit |= orderFieldTimeInForce
+	return r
+}
+
 // SetGTD sets the TimeInForce to GTD (Good Till Date) with the specified expiry time.
 func (r *LimitOrderRequest) SetGTD(date DateTime) *LimitOrderRequest {
 	r.TimeInForce = TimeInForceGTD
 	r.GtdTime = &date
+	r.explicit |= orderFieldTimeInForce
 	return r
 }
 
+// SetGTDTime is like SetGTD but takes a time.Time. The time must be in the future when the
+// request is sent.
+func (r *LimitOrderRequest) SetGTDTime(t time.Time) *LimitOrderRequest {
+	return r.SetGTD(DateTime{Time: &t})
+}
+
 // SetGFD sets the TimeInForce to GFD (Good For Day).
 func (r *LimitOrderRequest) SetGFD() *LimitOrderRequest {
 	r.TimeInForce = TimeInForceGFD
+	r.explicit |= orderFieldTimeInForce
 	return r
 }
 
 // SetPositionFill sets how Positions in the Account are modified when the Order is filled.
 func (r *LimitOrderRequest) SetPositionFill(positionFill OrderPositionFill) *LimitOrderRequest {
 	r.PositionFill = positionFill
+	r.explicit |= orderFieldPositionFill
 	return r
 }
 
 // SetTriggerCondition sets which price component is used to determine if the Order should be triggered.
 func (r *LimitOrderRequest) SetTriggerCondition(triggerCondition OrderTriggerCondition) *LimitOrderRequest {
 	r.TriggerCondition = triggerCondition
+	r.explicit |= orderFieldTriggerCondition
 	return r
 }
 
@@ -889,6 +1003,7 @@ func (r *LimitOrderRequest) SetTakeProfitOnFill(details *TakeProfitDetails) *Lim
 // SetStopLossOnFill sets the Stop Loss Order details for when the Order is filled.
 func (r *LimitOrderRequest) SetStopLossOnFill(details *StopLossDetails) *LimitOrderRequest {
 	r.StopLossOnFill = details
+	r.explicit |= orderFieldStopLossOnFill
 	return r
 }
 
@@ -959,9 +1074,21 @@ type StopOrderRequest struct {
 	// TradeClientExtensions are the client extensions to add to the Trade created when the Order is filled.
 	// Do not set, modify, or delete tradeClientExtensions if your account is associated with MT4.
 	TradeClientExtensions *ClientExtensions `json:"tradeClientExtensions,omitempty"`
+	// explicit records the fields set with setters, which an OrderPolicy leaves unchanged.
+	explicit orderFields
 }
 
 func (r *StopOrderRequest) body() (*bytes.Buffer, error) {
+	if err := validateUnits("units", r.Units); err != nil {
+		return nil, err
+	}
+	if err := validateGTD("gtdTime", r.TimeInForce, r.GtdTime); err != nil {
+		return nil, err
+	}
+	onFill := onFillDetails{r.TakeProfitOnFill, r.StopLossOnFill, r.GuaranteedStopLossOnFill, r.TrailingStopLossOnFill}
+	if err := onFill.validateGTD(); err != nil {
+		return nil, err
+	}
 	return orderRequestWrapper(r)
 }
 
@@ -984,28 +1111,46 @@ func (r *StopOrderRequest) SetPriceBound(priceBound PriceValue) *StopOrderReques
 	return r
 }
 
+// SetGTC sets the TimeInForce to GTC (Good Till Cancelled), the default, so that an
+// [OrderPolicy] does not change it.
+func (r *StopOrderRequest) SetGTC() *StopOrderRequest {
+	r.TimeInForce = TimeInForceGTC
+	r.explicit |= orderFieldTimeInForce
+	return r
+}
+
 // SetGTD sets the TimeInForce to GTD (Good Till Date) with the specified expiry time.
 func (r *StopOrderRequest) SetGTD(date DateTime) *StopOrderRequest {
 	r.TimeInForce = TimeInForceGTD
 	r.GtdTime = &date
+	r.explicit |= orderFieldTimeInForce
 	return r
 }
 
+// SetGTDTime is like SetGTD but takes a time.Time. The time must be in the future when the
+// request is sent.
+func (r *StopOrderRequest) SetGTDTime(t time.Time) *StopOrderRequest {
+	return r.SetGTD(DateTime{Time: &t})
+}
+
 // SetGFD sets the TimeInForce to GFD (Good For Day).
 func (r *StopOrderRequest) SetGFD() *StopOrderRequest {
 	r.TimeInForce = TimeInForceGFD
+	r.explicit |= orderFieldTimeInForce
 	return r
 }
 
 // SetPositionFill sets how Positions in the Account are modified when the Order is filled.
 func (r *StopOrderRequest) SetPositionFill(positionFill OrderPositionFill) *StopOrderRequest {
 	r.PositionFill = positionFill
+	r.explicit |= orderFieldPositionFill
 	return r
 }
 
 // SetTriggerCondition sets which price component is used to determine if the Order should be triggered.
 func (r *StopOrderRequest) SetTriggerCondition(triggerCondition OrderTriggerCondition) *StopOrderRequest {
 	r.TriggerCondition = triggerCondition
+	r.explicit |= orderFieldTriggerCondition
 	return r
 }
 
@@ -1024,6 +1169,7 @@ func (r *StopOrderRequest) SetTakeProfitOnFill(details *TakeProfitDetails) *Stop
 // SetStopLossOnFill sets the Stop Loss Order details for when the Order is filled.
 func (r *StopOrderRequest) SetStopLossOnFill(details *StopLossDetails) *StopOrderRequest {
 	r.StopLossOnFill = details
+	r.explicit |= orderFieldStopLossOnFill
 	return r
 }
 
@@ -1045,6 +1191,82 @@ func (r *StopOrderRequest) SetTradeClientExtensions(clientExtensions *ClientExte
 	return r
 }
 
+// NewBracketOrder creates a Limit Order that enters at entry and, once filled, is protected by a
+// Take Profit Order at takeProfit and a Stop Loss Order at stopLoss. For a long Order (positive
+// units) the Take Profit must be above and the Stop Loss below the entry price; for a short Order
+// it is the other way round. An error describing the violation is returned otherwise, so that
+// the request is rejected before it is sent.
+func NewBracketOrder(
+	instrument InstrumentName, units DecimalNumber, entry, takeProfit, stopLoss PriceValue,
+) (*LimitOrderRequest, error) {
+	if err := validateBracket(units, entry, takeProfit, stopLoss); err != nil {
+		return nil, err
+	}
+	return NewLimitOrderRequest(instrument, units, entry).
+		SetTakeProfitOnFill(NewTakeProfitDetails(takeProfit)).
+		SetStopLossOnFill(NewStopLossDetails().SetPrice(stopLoss)), nil
+}
+
+// NewStopBracketOrder is like [NewBracketOrder] but enters with a Stop Order, for entries at a
+// price worse than the current market price such as breakouts.
+func NewStopBracketOrder(
+	instrument InstrumentName, units DecimalNumber, entry, takeProfit, stopLoss PriceValue,
+) (*StopOrderRequest, error) {
+	if err := validateBracket(units, entry, takeProfit, stopLoss); err != nil {
+		return nil, err
+	}
+	return NewStopOrderRequest(instrument, units, entry).
+		SetTakeProfitOnFill(NewTakeProfitDetails(takeProfit)).
+		SetStopLossOnFill(NewStopLossDetails().SetPrice(stopLoss)), nil
+}
+
+// validateBracket checks that the Take Profit and Stop Loss prices of a bracket Order are on
+// the profitable and losing side of the entry price respectively.
+func validateBracket(units DecimalNumber, entry, takeProfit, stopLoss PriceValue) error {
+	u, ok := new(big.Rat).SetString(string(units))
+	if !ok {
+		return fmt.Errorf("invalid units %q", units)
+	}
+	if u.Sign() == 0 {
+		return errors.New("units must not be zero")
+	}
+	parse := func(name string, price PriceValue) (*big.Rat, error) {
+		p, ok := new(big.Rat).SetString(string(price))
+		if !ok || p.Sign() <= 0 {
+			return nil, fmt.Errorf("invalid %s price %q", name, price)
+		}
+		return p, nil
+	}
+	e, err := parse("entry", entry)
+	if err != nil {
+		return err
+	}
+	tp, err := parse("take profit", takeProfit)
+	if err != nil {
+		return err
+	}
+	sl, err := parse("stop loss", stopLoss)
+	if err != nil {
+		return err
+	}
+	if u.Sign() > 0 {
+		if tp.Cmp(e) <= 0 {
+			return fmt.Errorf("take profit price %s must be above entry price %s for a long order", takeProfit, entry)
+		}
+		if sl.Cmp(e) >= 0 {
+			return fmt.Errorf("stop loss price %s must be below entry price %s for a long order", stopLoss, entry)
+		}
+		return nil
+	}
+	if tp.Cmp(e) >= 0 {
+		return fmt.Errorf("take profit price %s must be below entry price %s for a short order", takeProfit, entry)
+	}
+	if sl.Cmp(e) <= 0 {
+		return fmt.Errorf("stop loss price %s must be above entry price %s for a short order", stopLoss, entry)
+	}
+	return nil
+}
+
 // MarketIfTouchedOrderRequest is used to create a Market If Touched Order.
 type MarketIfTouchedOrderRequest struct {
 	// Type is the type of the Order to Create. Must be set to "MARKET_IF_TOUCHED" when creating a
@@ -1096,9 +1318,21 @@ type MarketIfTouchedOrderRequest struct {
 	// TradeClientExtensions are the client extensions to add to the Trade created when the Order is filled.
 	// Do not set, modify, or delete tradeClientExtensions if your account is associated with MT4.
 	TradeClientExtensions *ClientExtensions `json:"tradeClientExtensions,omitempty"`
+	// explicit records the fields set with setters, which an OrderPolicy leaves unchanged.
+	explicit orderFields
 }
 
 func (r *MarketIfTouchedOrderRequest) body() (*bytes.Buffer, error) {
+	if err := validateUnits("units", r.Units); err != nil {
+		return nil, err
+	}
+	if err := validateGTD("gtdTime", r.TimeInForce, r.GtdTime); err != nil {
+		return nil, err
+	}
+	onFill := onFillDetails{r.TakeProfitOnFill, r.StopLossOnFill, r.GuaranteedStopLossOnFill, r.TrailingStopLossOnFill}
+	if err := onFill.validateGTD(); err != nil {
+		return nil, err
+	}
 	return orderRequestWrapper(r)
 }
 
@@ -1121,40 +1355,67 @@ func (r *MarketIfTouchedOrderRequest) SetPriceBound(priceBound PriceValue) *Mark
 	return r
 }
 
+// SetGTC sets the TimeInForce to GTC (Good Till Cancelled), the default, so that an
+// [OrderPolicy] does not change it.
+func (r *MarketIfTouchedOrderRequest) SetGTC() *MarketIfTouchedOrderRequest {
+	r.TimeInForce = TimeInForceGTC
+	r.explicit |= orderFieldTimeInForce
+	return r
+}
+
 // SetGTD sets the TimeInForce to GTD (Good Till Date) with the specified expiry time.
 func (r *MarketIfTouchedOrderRequest) SetGTD(date DateTime) *MarketIfTouchedOrderRequest {
 	r.TimeInForce = TimeInForceGTD
 	r.GtdTime = &date
+	r.explicit |= orderFieldTimeInForce
 	return r
 }
 
+// SetGTDTime is like SetGTD but takes a time.Time. The time must be in the future when the
+// request is sent.
+func (r *MarketIfTouchedOrderRequest) SetGTDTime(t time.Time) *MarketIfTouchedOrderRequest {
+	return r.SetGTD(DateTime{Time: &t})
+}
+
 // SetGFD sets the TimeInForce to GFD (Good For Day).
 func (r *MarketIfTouchedOrderRequest) SetGFD() *MarketIfTouchedOrderRequest {
 	r.TimeInForce = TimeInForceGFD
+	r.explicit |= orderFieldTimeInForce
+	return r
+}
+
+// SetPositionFill sets how Positions in the Account are modified when the Order is filled.
+func (r *MarketIfTouchedOrderRequest) SetPositionFill(positionFill OrderPositionFill) *MarketIfTouchedOrderRequest {
+	r.PositionFill = positionFill
+	r.explicit |= orderFieldPositionFill
 	return r
 }
 
 // SetOpenOnly sets the PositionFill to OPEN_ONLY so the Order can only open new Positions.
 func (r *MarketIfTouchedOrderRequest) SetOpenOnly() *MarketIfTouchedOrderRequest {
 	r.PositionFill = OrderPositionFillOpenOnly
+	r.explicit |= orderFieldPositionFill
 	return r
 }
 
 // SetReduceFirst sets the PositionFill to REDUCE_FIRST so existing Positions are reduced before opening new ones.
 func (r *MarketIfTouchedOrderRequest) SetReduceFirst() *MarketIfTouchedOrderRequest {
 	r.PositionFill = OrderPositionFillReduceFirst
+	r.explicit |= orderFieldPositionFill
 	return r
 }
 
 // SetReduceOnly sets the PositionFill to REDUCE_ONLY so the Order can only reduce existing Positions.
 func (r *MarketIfTouchedOrderRequest) SetReduceOnly() *MarketIfTouchedOrderRequest {
 	r.PositionFill = OrderPositionFillReduceOnly
+	r.explicit |= orderFieldPositionFill
 	return r
 }
 
 // SetTriggerCondition sets which price component is used to determine if the Order should be triggered.
 func (r *MarketIfTouchedOrderRequest) SetTriggerCondition(triggerCondition OrderTriggerCondition) *MarketIfTouchedOrderRequest {
 	r.TriggerCondition = triggerCondition
+	r.explicit |= orderFieldTriggerCondition
 	return r
 }
 
@@ -1173,6 +1434,7 @@ func (r *MarketIfTouchedOrderRequest) SetTakeProfitOnFill(details *TakeProfitDet
 // SetStopLossOnFill sets the Stop Loss Order details for when the Order is filled.
 func (r *MarketIfTouchedOrderRequest) SetStopLossOnFill(details *StopLossDetails) *MarketIfTouchedOrderRequest {
 	r.StopLossOnFill = details
+	r.explicit |= orderFieldStopLossOnFill
 	return r
 }
 
@@ -1221,6 +1483,9 @@ type TakeProfitOrderRequest struct {
 }
 
 func (r *TakeProfitOrderRequest) body() (*bytes.Buffer, error) {
+	if err := validateGTD("gtdTime", r.TimeInForce, r.GtdTime); err != nil {
+		return nil, err
+	}
 	return orderRequestWrapper(r)
 }
 
@@ -1248,6 +1513,12 @@ func (r *TakeProfitOrderRequest) SetGTD(date DateTime) *TakeProfitOrderRequest {
 	return r
 }
 
+// SetGTDTime is like SetGTD but takes a time.Time. The time must be in the future when the
+// request is sent.
+func (r *TakeProfitOrderRequest) SetGTDTime(t time.Time) *TakeProfitOrderRequest {
+	return r.SetGTD(DateTime{Time: &t})
+}
+
 // SetGFD sets the TimeInForce to GFD (Good For Day).
 func (r *TakeProfitOrderRequest) SetGFD() *TakeProfitOrderRequest {
 	r.TimeInForce = TimeInForceGFD
@@ -1305,6 +1576,9 @@ func (r *StopLossOrderRequest) body() (*bytes.Buffer, error) {
 	if r.Price != nil && r.Distance != nil {
 		return nil, errors.New("price and distance cannot be set at the same time")
 	}
+	if err := validateGTD("gtdTime", r.TimeInForce, r.GtdTime); err != nil {
+		return nil, err
+	}
 	return orderRequestWrapper(r)
 }
 
@@ -1347,6 +1621,12 @@ func (r *StopLossOrderRequest) SetGTD(date DateTime) *StopLossOrderRequest {
 	return r
 }
 
+// SetGTDTime is like SetGTD but takes a time.Time. The time must be in the future when the
+// request is sent.
+func (r *StopLossOrderRequest) SetGTDTime(t time.Time) *StopLossOrderRequest {
+	return r.SetGTD(DateTime{Time: &t})
+}
+
 // SetGFD sets the TimeInForce to GFD (Good For Day).
 func (r *StopLossOrderRequest) SetGFD() *StopLossOrderRequest {
 	r.TimeInForce = TimeInForceGFD
@@ -1398,6 +1678,9 @@ type GuaranteedStopLossOrderRequest struct {
 }
 
 func (r *GuaranteedStopLossOrderRequest) body() (*bytes.Buffer, error) {
+	if err := validateGTD("gtdTime", r.TimeInForce, r.GtdTime); err != nil {
+		return nil, err
+	}
 	return orderRequestWrapper(r)
 }
 
@@ -1434,6 +1717,12 @@ func (r *GuaranteedStopLossOrderRequest) SetGTD(date DateTime) *GuaranteedStopLo
 	return r
 }
 
+// SetGTDTime is like SetGTD but takes a time.Time. The time must be in the future when the
+// request is sent.
+func (r *GuaranteedStopLossOrderRequest) SetGTDTime(t time.Time) *GuaranteedStopLossOrderRequest {
+	return r.SetGTD(DateTime{Time: &t})
+}
+
 // SetGFD sets the TimeInForce to GFD (Good For Day).
 func (r *GuaranteedStopLossOrderRequest) SetGFD() *GuaranteedStopLossOrderRequest {
 	r.TimeInForce = TimeInForceGFD
@@ -1479,6 +1768,9 @@ type TrailingStopLossOrderRequest struct {
 }
 
 func (r *TrailingStopLossOrderRequest) body() (*bytes.Buffer, error) {
+	if err := validateGTD("gtdTime", r.TimeInForce, r.GtdTime); err != nil {
+		return nil, err
+	}
 	return orderRequestWrapper(r)
 }
 
@@ -1506,6 +1798,12 @@ func (r *TrailingStopLossOrderRequest) SetGTD(date DateTime) *TrailingStopLossOr
 	return r
 }
 
+// SetGTDTime is like SetGTD but takes a time.Time. The time must be in the future when the
+// request is sent.
+func (r *TrailingStopLossOrderRequest) SetGTDTime(t time.Time) *TrailingStopLossOrderRequest {
+	return r.SetGTD(DateTime{Time: &t})
+}
+
 // SetGFD sets the TimeInForce to GFD (Good For Day).
 func (r *TrailingStopLossOrderRequest) SetGFD() *TrailingStopLossOrderRequest {
 	r.TimeInForce = TimeInForceGFD
@@ -1524,6 +1822,233 @@ func (r *TrailingStopLossOrderRequest) SetClientExtensions(clientExtensions *Cli
 	return r
 }
 
+// NewReplaceOrderRequest creates the OrderRequest that recreates order as it currently is, so
+// that a pending Order retrieved with [orderService.Details] or [orderService.List] can be
+// modified with [orderService.Replace] by changing only the fields of interest. The concrete
+// type of the returned request matches the type of order; use the ReplaceRequest method of the
+// Order types to get the concrete request directly. The request is a deep copy, so modifying
+// it leaves order unchanged. Market and Fixed Price Orders cannot be replaced and result in an
+// error. An [OrderPolicy] leaves the fields of the returned request unchanged, as if they were set
+// with the request setters, so that replacing an Order keeps its settings.
+func NewReplaceOrderRequest(order Order) (OrderRequest, error) {
+	switch o := order.(type) {
+	case LimitOrder:
+		return o.ReplaceRequest(), nil
+	case StopOrder:
+		return o.ReplaceRequest(), nil
+	case MarketIfTouchedOrder:
+		return o.ReplaceRequest(), nil
+	case TakeProfitOrder:
+		return o.ReplaceRequest(), nil
+	case StopLossOrder:
+		return o.ReplaceRequest(), nil
+	case GuaranteedStopLossOrder:
+		return o.ReplaceRequest(), nil
+	case TrailingStopLossOrder:
+		return o.ReplaceRequest(), nil
+	case nil:
+		return nil, errors.New("order is nil")
+	default:
+		return nil, fmt.Errorf("%s orders cannot be replaced", order.GetType())
+	}
+}
+
+// ReplaceRequest returns a LimitOrderRequest populated with the current fields of the Order.
+func (o LimitOrder) ReplaceRequest() *LimitOrderRequest {
+	return &LimitOrderRequest{
+		Type:                     OrderTypeLimit,
+		Instrument:               o.Instrument,
+		Units:                    o.Units,
+		Price:                    o.Price,
+		TimeInForce:              o.TimeInForce,
+		GtdTime:                  o.GtdTime.clone(),
+		PositionFill:             o.PositionFill,
+		TriggerCondition:         o.TriggerCondition,
+		ClientExtensions:         o.ClientExtensions.clone(),
+		TakeProfitOnFill:         o.TakeProfitOnFill.clone(),
+		StopLossOnFill:           o.StopLossOnFill.clone(),
+		GuaranteedStopLossOnFill: o.GuaranteedStopLossOnFill.clone(),
+		TrailingStopLossOnFill:   o.TrailingStopLossOnFill.clone(),
+		TradeClientExtensions:    o.TradeClientExtensions.clone(),
+		explicit:                 orderFieldsAll,
+	}
+}
+
+// ReplaceRequest returns a StopOrderRequest populated with the current fields of the Order.
+func (o StopOrder) ReplaceRequest() *StopOrderRequest {
+	return &StopOrderRequest{
+		Type:                     OrderTypeStop,
+		Instrument:               o.Instrument,
+		Units:                    o.Units,
+		Price:                    o.Price,
+		PriceBound:               clonePtr(o.PriceBound),
+		TimeInForce:              o.TimeInForce,
+		GtdTime:                  o.GtdTime.clone(),
+		PositionFill:             o.PositionFill,
+		TriggerCondition:         o.TriggerCondition,
+		ClientExtensions:         o.ClientExtensions.clone(),
+		TakeProfitOnFill:         o.TakeProfitOnFill.clone(),
+		StopLossOnFill:           o.StopLossOnFill.clone(),
+		GuaranteedStopLossOnFill: o.GuaranteedStopLossOnFill.clone(),
+		TrailingStopLossOnFill:   o.TrailingStopLossOnFill.clone(),
+		TradeClientExtensions:    o.TradeClientExtensions.clone(),
+		explicit:                 orderFieldsAll,
+	}
+}
+
+// ReplaceRequest returns a MarketIfTouchedOrderRequest populated with the current fields of the
+// Order.
+func (o MarketIfTouchedOrder) ReplaceRequest() *MarketIfTouchedOrderRequest {
+	return &MarketIfTouchedOrderRequest{
+		Type:                     OrderTypeMarketIfTouched,
+		Instrument:               o.Instrument,
+		Units:                    o.Units,
+		Price:                    o.Price,
+		PriceBound:               clonePtr(o.PriceBound),
+		TimeInForce:              o.TimeInForce,
+		GtdTime:                  o.GtdTime.clone(),
+		PositionFill:             o.PositionFill,
+		TriggerCondition:         o.TriggerCondition,
+		ClientExtensions:         o.ClientExtensions.clone(),
+		TakeProfitOnFill:         o.TakeProfitOnFill.clone(),
+		StopLossOnFill:           o.StopLossOnFill.clone(),
+		GuaranteedStopLossOnFill: o.GuaranteedStopLossOnFill.clone(),
+		TrailingStopLossOnFill:   o.TrailingStopLossOnFill.clone(),
+		TradeClientExtensions:    o.TradeClientExtensions.clone(),
+		explicit:                 orderFieldsAll,
+	}
+}
+
+// ReplaceRequest returns a TakeProfitOrderRequest populated with the current fields of the Order.
+func (o TakeProfitOrder) ReplaceRequest() *TakeProfitOrderRequest {
+	return &TakeProfitOrderRequest{
+		Type:             OrderTypeTakeProfit,
+		TradeID:          o.TradeID,
+		ClientTradeID:    clonePtr(o.ClientTradeID),
+		Price:            o.Price,
+		TimeInForce:      o.TimeInForce,
+		GtdTime:          o.GtdTime.clone(),
+		TriggerCondition: o.TriggerCondition,
+		ClientExtensions: o.ClientExtensions.clone(),
+	}
+}
+
+// ReplaceRequest returns a StopLossOrderRequest populated with the current fields of the Order.
+// The request specifies the Order's price only, as price and distance cannot both be set; to
+// specify a distance instead, set Price to nil and call SetDistance.
+func (o StopLossOrder) ReplaceRequest() *StopLossOrderRequest {
+	price := o.Price
+	return &StopLossOrderRequest{
+		Type:             OrderTypeStopLoss,
+		TradeID:          o.TradeID,
+		ClientTradeID:    clonePtr(o.ClientTradeID),
+		Price:            &price,
+		TimeInForce:      o.TimeInForce,
+		GtdTime:          o.GtdTime.clone(),
+		TriggerCondition: o.TriggerCondition,
+		ClientExtensions: o.ClientExtensions.clone(),
+	}
+}
+
+// ReplaceRequest returns a GuaranteedStopLossOrderRequest populated with the current fields of
+// the Order. The request specifies the Order's price only, as price and distance cannot both be
+// set.
+func (o GuaranteedStopLossOrder) ReplaceRequest() *GuaranteedStopLossOrderRequest {
+	price := o.Price
+	return &GuaranteedStopLossOrderRequest{
+		Type:             OrderTypeGuaranteedStopLoss,
+		TradeID:          o.TradeID,
+		ClientTradeID:    clonePtr(o.ClientTradeID),
+		Price:            &price,
+		TimeInForce:      o.TimeInForce,
+		GtdTime:          o.GtdTime.clone(),
+		TriggerCondition: o.TriggerCondition,
+		ClientExtensions: o.ClientExtensions.clone(),
+	}
+}
+
+// ReplaceRequest returns a TrailingStopLossOrderRequest populated with the current fields of the
+// Order.
+func (o TrailingStopLossOrder) ReplaceRequest() *TrailingStopLossOrderRequest {
+	return &TrailingStopLossOrderRequest{
+		Type:             OrderTypeTrailingStopLoss,
+		TradeID:          o.TradeID,
+		ClientTradeID:    clonePtr(o.ClientTradeID),
+		Distance:         o.Distance,
+		TimeInForce:      o.TimeInForce,
+		GtdTime:          o.GtdTime.clone(),
+		TriggerCondition: o.TriggerCondition,
+		ClientExtensions: o.ClientExtensions.clone(),
+	}
+}
+
+// clonePtr returns a pointer to a copy of the value p points to, or nil if p is nil.
+func clonePtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
+
+// clone returns a copy of dt that does not share its time with dt.
+func (dt *DateTime) clone() *DateTime {
+	if dt == nil {
+		return nil
+	}
+	return &DateTime{clonePtr(dt.Time)}
+}
+
+// clone returns a deep copy of e.
+func (e *ClientExtensions) clone() *ClientExtensions {
+	if e == nil {
+		return nil
+	}
+	return &ClientExtensions{ID: clonePtr(e.ID), Tag: clonePtr(e.Tag), Comment: clonePtr(e.Comment)}
+}
+
+// clone returns a deep copy of d.
+func (d *TakeProfitDetails) clone() *TakeProfitDetails {
+	if d == nil {
+		return nil
+	}
+	c := *d
+	c.GtdTime, c.ClientExtensions = d.GtdTime.clone(), d.ClientExtensions.clone()
+	return &c
+}
+
+// clone returns a deep copy of d.
+func (d *StopLossDetails) clone() *StopLossDetails {
+	if d == nil {
+		return nil
+	}
+	c := *d
+	c.Price, c.Distance = clonePtr(d.Price), clonePtr(d.Distance)
+	c.GtdTime, c.ClientExtensions = d.GtdTime.clone(), d.ClientExtensions.clone()
+	return &c
+}
+
+// clone returns a deep copy of d.
+func (d *GuaranteedStopLossDetails) clone() *GuaranteedStopLossDetails {
+	if d == nil {
+		return nil
+	}
+	c := *d
+	c.Price, c.Distance = clonePtr(d.Price), clonePtr(d.Distance)
+	c.GtdTime, c.ClientExtensions = d.GtdTime.clone(), d.ClientExtensions.clone()
+	return &c
+}
+
+// clone returns a deep copy of d.
+func (d *TrailingStopLossDetails) clone() *TrailingStopLossDetails {
+	if d == nil {
+		return nil
+	}
+	c := *d
+	c.GtdTime, c.ClientExtensions = d.GtdTime.clone(), d.ClientExtensions.clone()
+	return &c
+}
+
 // Order-related Definitions
 
 // OrderID is the unique identifier for an Order within an Account.
@@ -1597,6 +2122,22 @@ type OrderIdentifier struct {
 // OrderSpecifier is either an Order's OANDA-assigned OrderID or the client-provided ClientID prefixed with "@".
 type OrderSpecifier = string
 
+// ByOrderID returns the OrderSpecifier that refers to an Order by its OANDA-assigned OrderID.
+func ByOrderID(id OrderID) OrderSpecifier {
+	return id
+}
+
+// ByClientOrderID returns the OrderSpecifier that refers to an Order by its client-provided
+// ClientID. The "@" prefix is added unless id already has it.
+func ByClientOrderID(id ClientID) OrderSpecifier {
+	return clientSpecifier(id)
+}
+
+// clientSpecifier formats a ClientID as an Order or Trade specifier.
+func clientSpecifier(id ClientID) string {
+	return "@" + strings.TrimPrefix(string(id), "@")
+}
+
 // TimeInForce specifies how long an Order should remain pending before being automatically
 // cancelled by the execution system.
 type TimeInForce string
@@ -1619,6 +2160,18 @@ const (
 	TimeInForceGFD TimeInForce = "GFD"
 )
 
+// validateGTD checks that a GTD expiry time lies in the future, so that requests OANDA would
+// reject are not sent.
+func validateGTD(field string, timeInForce TimeInForce, gtdTime *DateTime) error {
+	if timeInForce != TimeInForceGTD || gtdTime == nil || gtdTime.Time == nil {
+		return nil
+	}
+	if !gtdTime.After(time.Now()) {
+		return fmt.Errorf("%s %s is not in the future", field, gtdTime.Format(time.RFC3339))
+	}
+	return nil
+}
+
 // OrderPositionFill specifies how Positions in the Account are modified when an Order is filled.
 type OrderPositionFill string
 
@@ -1665,15 +2218,21 @@ func newOrderService(client *Client) *orderService {
 	return &orderService{client}
 }
 
-// OrderCreateResponse is the successful response returned by [orderService.Create].
+// OrderCreateResponse is the successful response returned by [orderService.Create]. The order
+// transactions are decoded into their concrete types (e.g. *[MarketOrderTransaction]) and can
+// be inspected with a type switch.
 type OrderCreateResponse struct {
-	OrderCreateTransaction        Transaction             `json:"orderCreateTransaction"`
+	OrderCreateTransaction        OrderTransaction        `json:"orderCreateTransaction"`
 	OrderFillTransaction          *OrderFillTransaction   `json:"orderFillTransaction,omitempty"`
 	OrderCancelTransaction        *OrderCancelTransaction `json:"orderCancelTransaction,omitempty"`
-	OrderReissueTransaction       Transaction             `json:"orderReissueTransaction,omitempty"`
-	OrderReissueRejectTransaction Transaction             `json:"orderReissueRejectTransaction,omitempty"`
+	OrderReissueTransaction       OrderTransaction        `json:"orderReissueTransaction,omitempty"`
+	OrderReissueRejectTransaction OrderRejectTransaction  `json:"orderReissueRejectTransaction,omitempty"`
 	RelatedTransactionIDs         []TransactionID         `json:"relatedTransactionIDs"`
 	LastTransactionID             TransactionID           `json:"lastTransactionID"`
+	// UnknownTransactions holds the order Transactions of the response of a type the library
+	// does not know, keyed by the JSON name of their field (e.g. "orderCreateTransaction"),
+	// which is left nil.
+	UnknownTransactions map[string]*UnknownTransaction `json:"-"`
 }
 
 func (r *OrderCreateResponse) UnmarshalJSON(b []byte) error {
@@ -1681,9 +2240,9 @@ func (r *OrderCreateResponse) UnmarshalJSON(b []byte) error {
 
 	aux := &struct {
 		*Alias
-		OrderCreateTransaction        json.RawMessage  `json:"orderCreateTransaction"`
-		OrderReissueTransaction       *json.RawMessage `json:"orderReissueTransaction"`
-		OrderReissueRejectTransaction *json.RawMessage `json:"orderReissueRejectTransaction"`
+		OrderCreateTransaction        json.RawMessage `json:"orderCreateTransaction"`
+		OrderReissueTransaction       json.RawMessage `json:"orderReissueTransaction"`
+		OrderReissueRejectTransaction json.RawMessage `json:"orderReissueRejectTransaction"`
 	}{
 		Alias: (*Alias)(r),
 	}
@@ -1692,39 +2251,60 @@ func (r *OrderCreateResponse) UnmarshalJSON(b []byte) error {
 		return err
 	}
 
-	orderCreateTransaction, err := unmarshalTransaction(aux.OrderCreateTransaction)
-	if err != nil {
+	var err error
+	var unknown *UnknownTransaction
+	if r.OrderCreateTransaction, unknown, err = unmarshalTransactionAs[OrderTransaction](aux.OrderCreateTransaction); err != nil {
 		return err
 	}
-	r.OrderCreateTransaction = orderCreateTransaction
-	if aux.OrderReissueTransaction != nil {
-		orderReissueTransaction, err := unmarshalTransaction(*aux.OrderReissueTransaction)
-		if err != nil {
-			return err
-		}
-		r.OrderReissueTransaction = orderReissueTransaction
-	} else {
-		r.OrderReissueTransaction = nil
+	addUnknownTransaction(&r.UnknownTransactions, "orderCreateTransaction", unknown)
+	if r.OrderReissueTransaction, unknown, err = unmarshalTransactionAs[OrderTransaction](aux.OrderReissueTransaction); err != nil {
+		return err
 	}
-	if aux.OrderReissueRejectTransaction != nil {
-		orderReissueRejectTransaction, err := unmarshalTransaction(*aux.OrderReissueRejectTransaction)
-		if err != nil {
-			return err
-		}
-		r.OrderReissueRejectTransaction = orderReissueRejectTransaction
-	} else {
-		r.OrderReissueRejectTransaction = nil
+	addUnknownTransaction(&r.UnknownTransactions, "orderReissueTransaction", unknown)
+	if r.OrderReissueRejectTransaction, unknown, err = unmarshalTransactionAs[OrderRejectTransaction](aux.OrderReissueRejectTransaction); err != nil {
+		return err
 	}
+	addUnknownTransaction(&r.UnknownTransactions, "orderReissueRejectTransaction", unknown)
 	return nil
 }
 
+// WasCancelled reports whether the Order was cancelled as part of its creation, and the reason it
+// was cancelled. OANDA responds with 201 Created to such Orders even though they never fill, for
+// example when a Market Order's take profit would be on the wrong side of the fill price. An Order
+// that was partially filled before the rest was cancelled is also reported.
+func (r *OrderCreateResponse) WasCancelled() (OrderCancelReason, bool) {
+	if r.OrderCancelTransaction == nil {
+		return "", false
+	}
+	return r.OrderCancelTransaction.Reason, true
+}
+
+// OrderCancelledError is returned by [orderService.Create] with the response when the client was
+// created with [WithCancelledOrderError] and the Order was cancelled without being filled.
+type OrderCancelledError struct {
+	// OrderID is the ID of the cancelled Order.
+	OrderID OrderID
+	// Reason is the reason the Order was cancelled.
+	Reason OrderCancelReason
+	// Transaction is the Transaction that cancelled the Order.
+	Transaction *OrderCancelTransaction
+}
+
+// Error implements the error interface.
+func (e OrderCancelledError) Error() string {
+	return fmt.Sprintf("order %s was cancelled: %s", e.OrderID, e.Reason)
+}
+
 // OrderErrorResponse is the error response returned by order endpoints when a request is rejected.
 type OrderErrorResponse struct {
-	OrderRejectTransaction Transaction     `json:"orderRejectTransaction"`
-	RelatedTransactionIDs  []TransactionID `json:"relatedTransactionIDs"`
-	LastTransactionID      TransactionID   `json:"lastTransactionID"`
-	ErrorCode              string          `json:"errorCode"`
-	ErrorMessage           string          `json:"errorMessage"`
+	OrderRejectTransaction OrderRejectTransaction `json:"orderRejectTransaction"`
+	RelatedTransactionIDs  []TransactionID        `json:"relatedTransactionIDs"`
+	LastTransactionID      TransactionID          `json:"lastTransactionID"`
+	ErrorCode              string                 `json:"errorCode"`
+	ErrorMessage           string                 `json:"errorMessage"`
+	// UnknownTransactions holds the order reject Transaction of the response if it is of a type
+	// the library does not know, keyed by "orderRejectTransaction", which is left nil.
+	UnknownTransactions map[string]*UnknownTransaction `json:"-"`
 }
 
 func (r *OrderErrorResponse) UnmarshalJSON(b []byte) error {
@@ -1740,13 +2320,12 @@ func (r *OrderErrorResponse) UnmarshalJSON(b []byte) error {
 	}
 	*r = OrderErrorResponse(aux.Alias)
 
-	if len(aux.OrderRejectTransaction) > 0 {
-		orderRejectTransaction, err := unmarshalTransaction(aux.OrderRejectTransaction)
-		if err != nil {
-			return err
-		}
-		r.OrderRejectTransaction = orderRejectTransaction
+	orderRejectTransaction, unknown, err := unmarshalTransactionAs[OrderRejectTransaction](aux.OrderRejectTransaction)
+	if err != nil {
+		return err
 	}
+	r.OrderRejectTransaction = orderRejectTransaction
+	addUnknownTransaction(&r.UnknownTransactions, "orderRejectTransaction", unknown)
 	return nil
 }
 
@@ -1766,13 +2345,19 @@ func orderRequestWrapper(req OrderRequest) (*bytes.Buffer, error) {
 	return bytes.NewBuffer(body), nil
 }
 
-// Create submits a new Order for the Account configured via WithAccountID.
+// Create submits a new Order for the Account configured via WithAccountID. The client's
+// [OrderPolicy], if any, is applied to req first. If the client was created with
+// [WithCancelledOrderError], an Order that was cancelled without being filled is returned
+// together with an [OrderCancelledError].
 //
 // This corresponds to the OANDA API endpoint: POST /v3/accounts/{accountID}/orders
 //
 // Reference: https://developer.oanda.com/rest-live-v20/order-ep/#collapse_endpoint_1
 func (s *orderService) Create(ctx context.Context, req OrderRequest) (*OrderCreateResponse, error) {
 	path := fmt.Sprintf("/v3/accounts/%v/orders", s.client.accountID)
+	if err := s.client.orderPolicy.apply(req); err != nil {
+		return nil, err
+	}
 	body, err := req.body()
 	if err != nil {
 		return nil, err
@@ -1784,7 +2369,18 @@ func (s *orderService) Create(ctx context.Context, req OrderRequest) (*OrderCrea
 	defer closeBody(httpResp)
 	switch httpResp.StatusCode {
 	case http.StatusCreated:
-		return decodeJSON[OrderCreateResponse](httpResp)
+		resp, err := decodeJSON[OrderCreateResponse](httpResp)
+		if err != nil {
+			return nil, err
+		}
+		if s.client.cancelledOrderError && resp.OrderCancelTransaction != nil && resp.OrderFillTransaction == nil {
+			return resp, OrderCancelledError{
+				OrderID:     resp.OrderCancelTransaction.OrderID,
+				Reason:      resp.OrderCancelTransaction.Reason,
+				Transaction: resp.OrderCancelTransaction,
+			}
+		}
+		return resp, nil
 	case http.StatusBadRequest, http.StatusNotFound:
 		return nil, decodeTypedError[OrderErrorResponse](httpResp)
 	default:
@@ -1916,6 +2512,48 @@ func (s *orderService) List(ctx context.Context, req *OrderListRequest) (*OrderL
 	return doGet[OrderListResponse](s.client, ctx, path, v)
 }
 
+// orderPageSize is the page size Iterate uses when the request does not specify a count.
+const orderPageSize = 500
+
+// Iterate returns an iterator over all Orders matching req, from the most recent to the oldest.
+// It calls [orderService.List] repeatedly, moving BeforeID past the oldest Order of each page,
+// until a page comes back short. req.Count is used as the page size and defaults to 500; req
+// itself is not modified. Iteration stops after the first error, which is yielded with a nil
+// Order.
+func (s *orderService) Iterate(ctx context.Context, req *OrderListRequest) iter.Seq2[Order, error] {
+	return func(yield func(Order, error) bool) {
+		page := *req
+		page.IDs = slices.Clone(req.IDs)
+		if page.Count == nil {
+			page.SetCount(orderPageSize)
+		}
+		for {
+			resp, err := s.List(ctx, &page)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for _, order := range resp.Orders {
+				// Skip the boundary Order in case the server treats beforeID as inclusive.
+				if page.BeforeID != nil && order.GetID() == *page.BeforeID {
+					continue
+				}
+				if !yield(order, nil) {
+					return
+				}
+			}
+			if len(resp.Orders) < *page.Count || len(resp.Orders) == 0 {
+				return
+			}
+			last := resp.Orders[len(resp.Orders)-1].GetID()
+			if page.BeforeID != nil && last == *page.BeforeID {
+				return
+			}
+			page.SetBeforeID(last)
+		}
+	}
+}
+
 // ListPending retrieves all pending Orders for the Account configured via WithAccountID.
 //
 // This corresponds to the OANDA API endpoint: GET /v3/accounts/{accountID}/pendingOrders
@@ -1961,16 +2599,22 @@ func (s *orderService) Details(ctx context.Context, specifier OrderSpecifier) (*
 	return doGet[OrderDetailsResponse](s.client, ctx, path, nil)
 }
 
-// OrderReplaceResponse is the successful response returned by [orderService.Replace].
+// OrderReplaceResponse is the successful response returned by [orderService.Replace]. As with
+// [OrderCreateResponse], the order transactions are decoded into their concrete types (e.g.
+// *[LimitOrderTransaction]) and can be inspected with a type switch.
 type OrderReplaceResponse struct {
-	OrderCancelTransaction          OrderCancelTransaction `json:"orderCancelTransaction"`
-	OrderCreateTransaction          Transaction            `json:"orderCreateTransaction"`
-	OrderFillTransaction            *OrderFillTransaction  `json:"orderFillTransaction,omitempty"`
-	OrderReissueTransaction         Transaction            `json:"orderReissueTransaction,omitempty"`
-	OrderReissueRejectTransaction   Transaction            `json:"orderReissueRejectTransaction,omitempty"`
-	ReplacingOrderCancelTransaction OrderCancelTransaction `json:"replacingOrderCancelTransaction,omitempty"`
-	RelatedTransactionIDs           []TransactionID        `json:"relatedTransactionIDs"`
-	LastTransactionID               TransactionID          `json:"lastTransactionID"`
+	OrderCancelTransaction          OrderCancelTransaction  `json:"orderCancelTransaction"`
+	OrderCreateTransaction          OrderTransaction        `json:"orderCreateTransaction"`
+	OrderFillTransaction            *OrderFillTransaction   `json:"orderFillTransaction,omitempty"`
+	OrderReissueTransaction         OrderTransaction        `json:"orderReissueTransaction,omitempty"`
+	OrderReissueRejectTransaction   OrderRejectTransaction  `json:"orderReissueRejectTransaction,omitempty"`
+	ReplacingOrderCancelTransaction *OrderCancelTransaction `json:"replacingOrderCancelTransaction,omitempty"`
+	RelatedTransactionIDs           []TransactionID         `json:"relatedTransactionIDs"`
+	LastTransactionID               TransactionID           `json:"lastTransactionID"`
+	// UnknownTransactions holds the order Transactions of the response of a type the library
+	// does not know, keyed by the JSON name of their field (e.g. "orderCreateTransaction"),
+	// which is left nil.
+	UnknownTransactions map[string]*UnknownTransaction `json:"-"`
 }
 
 func (r *OrderReplaceResponse) UnmarshalJSON(b []byte) error {
@@ -1985,43 +2629,38 @@ func (r *OrderReplaceResponse) UnmarshalJSON(b []byte) error {
 		Alias: (*Alias)(r),
 	}
 
-	if err := json.Unmarshal(b, &aux); err != nil {
+	if err := json.Unmarshal(b, aux); err != nil {
 		return err
 	}
 
-	orderCreateTransaction, err := unmarshalTransaction(aux.OrderCreateTransaction)
-	if err != nil {
+	var err error
+	var unknown *UnknownTransaction
+	if r.OrderCreateTransaction, unknown, err = unmarshalTransactionAs[OrderTransaction](aux.OrderCreateTransaction); err != nil {
 		return err
 	}
-	r.OrderCreateTransaction = orderCreateTransaction
-	if aux.OrderReissueTransaction != nil {
-		orderReissueTransaction, err := unmarshalTransaction(aux.OrderReissueTransaction)
-		if err != nil {
-			return err
-		}
-		r.OrderReissueTransaction = orderReissueTransaction
-	} else {
-		r.OrderReissueTransaction = nil
+	addUnknownTransaction(&r.UnknownTransactions, "orderCreateTransaction", unknown)
+	if r.OrderReissueTransaction, unknown, err = unmarshalTransactionAs[OrderTransaction](aux.OrderReissueTransaction); err != nil {
+		return err
 	}
-	if aux.OrderReissueRejectTransaction != nil {
-		orderReissueRejectTransaction, err := unmarshalTransaction(aux.OrderReissueRejectTransaction)
-		if err != nil {
-			return err
-		}
-		r.OrderReissueRejectTransaction = orderReissueRejectTransaction
-	} else {
-		r.OrderReissueRejectTransaction = nil
+	addUnknownTransaction(&r.UnknownTransactions, "orderReissueTransaction", unknown)
+	if r.OrderReissueRejectTransaction, unknown, err = unmarshalTransactionAs[OrderRejectTransaction](aux.OrderReissueRejectTransaction); err != nil {
+		return err
 	}
+	addUnknownTransaction(&r.UnknownTransactions, "orderReissueRejectTransaction", unknown)
 	return nil
 }
 
-// Replace cancels an existing Order and replaces it with a new one.
+// Replace cancels an existing Order and replaces it with a new one. The client's [OrderPolicy],
+// if any, is applied to req first.
 //
 // This corresponds to the OANDA API endpoint: PUT /v3/accounts/{accountID}/orders/{orderSpecifier}
 //
 // Reference: https://developer.oanda.com/rest-live-v20/order-ep/#collapse_endpoint_5
 func (s *orderService) Replace(ctx context.Context, specifier OrderSpecifier, req OrderRequest) (*OrderReplaceResponse, error) {
 	path := fmt.Sprintf("/v3/accounts/%v/orders/%v", s.client.accountID, specifier)
+	if err := s.client.orderPolicy.apply(req); err != nil {
+		return nil, err
+	}
 	body, err := req.body()
 	if err != nil {
 		return nil, err
@@ -2041,6 +2680,54 @@ func (s *orderService) Replace(ctx context.Context, specifier OrderSpecifier, re
 	}
 }
 
+// ReplacePrice replaces a pending Order with a copy that differs only in its price. The Order
+// is fetched with [orderService.Details], converted with [NewReplaceOrderRequest] so that its
+// units, time in force, GTD time, on-fill details and client extensions are kept, and replaced
+// with [orderService.Replace]. Stop Loss and Guaranteed Stop Loss Orders defined by a distance
+// are replaced with an absolute price. Trailing Stop Loss Orders have no price and cannot be
+// replaced this way. An Order whose GTD time has passed, which OANDA is about to cancel, is not
+// replaced and an error is returned; use [orderService.Replace] with a new GTD time instead.
+func (s *orderService) ReplacePrice(ctx context.Context, specifier OrderSpecifier, price PriceValue) (*OrderReplaceResponse, error) {
+	details, err := s.Details(ctx, specifier)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order details: %w", err)
+	}
+	req, err := NewReplaceOrderRequest(details.Order)
+	if err != nil {
+		return nil, err
+	}
+	var timeInForce TimeInForce
+	var gtdTime *DateTime
+	switch r := req.(type) {
+	case *LimitOrderRequest:
+		r.Price = price
+		timeInForce, gtdTime = r.TimeInForce, r.GtdTime
+	case *StopOrderRequest:
+		r.Price = price
+		timeInForce, gtdTime = r.TimeInForce, r.GtdTime
+	case *MarketIfTouchedOrderRequest:
+		r.Price = price
+		timeInForce, gtdTime = r.TimeInForce, r.GtdTime
+	case *TakeProfitOrderRequest:
+		r.Price = price
+		timeInForce, gtdTime = r.TimeInForce, r.GtdTime
+	case *StopLossOrderRequest:
+		r.Price = &price
+		r.Distance = nil
+		timeInForce, gtdTime = r.TimeInForce, r.GtdTime
+	case *GuaranteedStopLossOrderRequest:
+		r.Price = &price
+		r.Distance = nil
+		timeInForce, gtdTime = r.TimeInForce, r.GtdTime
+	default:
+		return nil, fmt.Errorf("%s orders have no price to replace", details.Order.GetType())
+	}
+	if err := validateGTD("gtdTime", timeInForce, gtdTime); err != nil {
+		return nil, fmt.Errorf("order %s has expired and cannot be replaced with its GTD time: %w", details.Order.GetID(), err)
+	}
+	return s.Replace(ctx, specifier, req)
+}
+
 // OrderCancelResponse is the successful response returned by [orderService.Cancel].
 type OrderCancelResponse struct {
 	OrderCancelTransaction OrderCancelTransaction `json:"orderCancelTransaction"`
@@ -2070,6 +2757,127 @@ func (s *orderService) Cancel(ctx context.Context, specifier OrderSpecifier) (*O
 	}
 }
 
+// OrderFilter selects pending Orders for bulk operations such as [orderService.CancelAll]. An
+// empty filter matches every pending Order. Create one with [NewOrderFilter].
+type OrderFilter struct {
+	// Instrument restricts the filter to Orders for the Instrument, including the dependent
+	// Orders of its Trades.
+	Instrument *InstrumentName
+	// Types restricts the filter to Orders of the given types.
+	Types []OrderType
+	// ClientTag restricts the filter to Orders whose client extensions carry the tag.
+	ClientTag *ClientTag
+}
+
+// NewOrderFilter creates a new OrderFilter that matches every pending Order. Use the builder
+// methods (SetInstrument, AddTypes, SetClientTag) to narrow it down.
+func NewOrderFilter() *OrderFilter {
+	return &OrderFilter{}
+}
+
+// SetInstrument restricts the filter to Orders for the specified Instrument.
+func (f *OrderFilter) SetInstrument(instrument InstrumentName) *OrderFilter {
+	f.Instrument = &instrument
+	return f
+}
+
+// AddTypes restricts the filter to Orders of the specified types.
+func (f *OrderFilter) AddTypes(types ...OrderType) *OrderFilter {
+	f.Types = append(f.Types, types...)
+	return f
+}
+
+// SetClientTag restricts the filter to Orders tagged with the specified client tag.
+func (f *OrderFilter) SetClientTag(tag ClientTag) *OrderFilter {
+	f.ClientTag = &tag
+	return f
+}
+
+// Match reports whether order satisfies the type and client tag conditions of the filter. The
+// Instrument condition is applied when listing Orders.
+func (f *OrderFilter) Match(order Order) bool {
+	if f == nil {
+		return true
+	}
+	if len(f.Types) > 0 && !slices.Contains(f.Types, order.GetType()) {
+		return false
+	}
+	if f.ClientTag != nil {
+		ext := order.GetClientExtensions()
+		if ext == nil || ext.Tag == nil || *ext.Tag != *f.ClientTag {
+			return false
+		}
+	}
+	return true
+}
+
+// pendingOrders returns the pending Orders matching filter.
+func (s *orderService) pendingOrders(ctx context.Context, filter *OrderFilter) ([]Order, error) {
+	var orders []Order
+	if filter == nil || filter.Instrument == nil {
+		resp, err := s.ListPending(ctx)
+		if err != nil {
+			return nil, err
+		}
+		orders = resp.Orders
+	} else {
+		req := NewOrderListRequest().SetState(OrderStatePending).SetInstrument(*filter.Instrument)
+		for order, err := range s.Iterate(ctx, req) {
+			if err != nil {
+				return nil, err
+			}
+			orders = append(orders, order)
+		}
+	}
+	matched := orders[:0]
+	for _, order := range orders {
+		if filter.Match(order) {
+			matched = append(matched, order)
+		}
+	}
+	return matched, nil
+}
+
+// OrderCancelResult is the outcome of cancelling a single Order with [orderService.CancelAll].
+type OrderCancelResult struct {
+	// OrderID is the ID of the Order that was cancelled.
+	OrderID OrderID
+	// Response is the response of the cancellation, or nil if it failed.
+	Response *OrderCancelResponse
+	// Err is the error that made the cancellation fail, or nil if it succeeded.
+	Err error
+}
+
+// cancelAllConcurrency is the maximum number of cancel requests CancelAll has in flight.
+const cancelAllConcurrency = 8
+
+// CancelAll cancels every pending Order matching filter for the Account configured via
+// WithAccountID. A nil filter matches every pending Order. The Orders are cancelled
+// concurrently; the result of each cancellation is reported in the returned slice, in the
+// order the Orders were listed. The error is non-nil only if the pending Orders could not be
+// listed.
+func (s *orderService) CancelAll(ctx context.Context, filter *OrderFilter) ([]OrderCancelResult, error) {
+	orders, err := s.pendingOrders(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending orders: %w", err)
+	}
+	results := make([]OrderCancelResult, len(orders))
+	sem := make(chan struct{}, cancelAllConcurrency)
+	var wg sync.WaitGroup
+	for i, order := range orders {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			resp, err := s.Cancel(ctx, order.GetID())
+			results[i] = OrderCancelResult{OrderID: order.GetID(), Response: resp, Err: err}
+		}()
+	}
+	wg.Wait()
+	return results, nil
+}
+
 // OrderUpdateClientExtensionsRequest is the request body for updating client extensions on an Order.
 type OrderUpdateClientExtensionsRequest struct {
 	ClientExtensions      *ClientExtensions `json:"clientExtensions,omitempty"`
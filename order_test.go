@@ -1,7 +1,16 @@
 package oanda
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestOrderService(t *testing.T) {
@@ -161,3 +170,476 @@ func TestOrderList(t *testing.T) {
 	}
 	debugResponse(resp)
 }
+
+func TestOrderCreateResponse_UnmarshalJSON(t *testing.T) {
+	raw := []byte(`{
+		"orderCreateTransaction":{"type":"LIMIT_ORDER","id":"10","instrument":"EUR_USD","units":"100","price":"1.10000",
+			"clientExtensions":{"id":"my-order"}},
+		"orderReissueRejectTransaction":{"type":"LIMIT_ORDER_REJECT","id":"11","rejectReason":"INSUFFICIENT_MARGIN"},
+		"relatedTransactionIDs":["10","11"],
+		"lastTransactionID":"11"
+	}`)
+	var resp OrderCreateResponse
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	limit, ok := resp.OrderCreateTransaction.(*LimitOrderTransaction)
+	if !ok {
+		t.Fatalf("got %T, want *LimitOrderTransaction", resp.OrderCreateTransaction)
+	}
+	if limit.Price != "1.10000" || limit.Units != "100" {
+		t.Errorf("got price %s and units %s, want 1.10000 and 100", limit.Price, limit.Units)
+	}
+	if got := resp.OrderCreateTransaction.GetOrderType(); got != OrderTypeLimit {
+		t.Errorf("got order type %s, want %s", got, OrderTypeLimit)
+	}
+	if ext := resp.OrderCreateTransaction.GetClientExtensions(); ext == nil || *ext.ID != "my-order" {
+		t.Errorf("got client extensions %v, want ID my-order", ext)
+	}
+	if resp.OrderReissueTransaction != nil {
+		t.Errorf("got reissue transaction %v, want nil", resp.OrderReissueTransaction)
+	}
+	if got := resp.OrderReissueRejectTransaction.GetRejectReason(); got != "INSUFFICIENT_MARGIN" {
+		t.Errorf("got reject reason %s, want INSUFFICIENT_MARGIN", got)
+	}
+}
+
+func TestOrderReplaceResponse_UnmarshalJSON(t *testing.T) {
+	raw := []byte(`{
+		"orderCancelTransaction":{"type":"ORDER_CANCEL","id":"20","orderID":"10","reason":"CLIENT_REQUEST_REPLACED",
+			"replacedByOrderID":"21"},
+		"orderCreateTransaction":{"type":"STOP_ORDER","id":"21","instrument":"EUR_USD","units":"100","price":"1.20000",
+			"replacesOrderID":"10"},
+		"orderReissueTransaction":{"type":"STOP_ORDER","id":"22","instrument":"EUR_USD","units":"100","price":"1.20000"},
+		"relatedTransactionIDs":["20","21","22"],
+		"lastTransactionID":"22"
+	}`)
+	var resp OrderReplaceResponse
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	stop, ok := resp.OrderCreateTransaction.(*StopOrderTransaction)
+	if !ok {
+		t.Fatalf("got %T, want *StopOrderTransaction", resp.OrderCreateTransaction)
+	}
+	if stop.Price != "1.20000" || stop.Units != "100" {
+		t.Errorf("got price %s and units %s, want 1.20000 and 100", stop.Price, stop.Units)
+	}
+	if resp.OrderCancelTransaction.ReplacedByOrderID == nil || *resp.OrderCancelTransaction.ReplacedByOrderID != "21" {
+		t.Errorf("got replaced by order ID %v, want 21", resp.OrderCancelTransaction.ReplacedByOrderID)
+	}
+	if got := resp.OrderReissueTransaction.GetID(); got != "22" {
+		t.Errorf("got reissue transaction ID %s, want 22", got)
+	}
+	if resp.OrderReissueRejectTransaction != nil {
+		t.Errorf("got reissue reject transaction %v, want nil", resp.OrderReissueRejectTransaction)
+	}
+	if resp.ReplacingOrderCancelTransaction != nil {
+		t.Errorf("got replacing order cancel transaction %v, want nil", resp.ReplacingOrderCancelTransaction)
+	}
+}
+
+func TestNewBracketOrder(t *testing.T) {
+	tests := []struct {
+		name            string
+		units           DecimalNumber
+		entry, tp, sl   PriceValue
+		wantErrContains string
+	}{
+		{name: "long", units: "100", entry: "1.1000", tp: "1.1200", sl: "1.0900"},
+		{name: "short", units: "-100", entry: "1.1000", tp: "1.0800", sl: "1.1100"},
+		{name: "long take profit below entry", units: "100", entry: "1.1000", tp: "1.0800", sl: "1.0900",
+			wantErrContains: "take profit price 1.0800 must be above entry price 1.1000"},
+		{name: "long stop loss above entry", units: "100", entry: "1.1000", tp: "1.1200", sl: "1.1100",
+			wantErrContains: "stop loss price 1.1100 must be below entry price 1.1000"},
+		{name: "short take profit above entry", units: "-100", entry: "1.1000", tp: "1.1200", sl: "1.1100",
+			wantErrContains: "must be below entry price"},
+		{name: "short stop loss equal to entry", units: "-100", entry: "1.1000", tp: "1.0800", sl: "1.10",
+			wantErrContains: "must be above entry price"},
+		{name: "zero units", units: "0", entry: "1.1000", tp: "1.1200", sl: "1.0900",
+			wantErrContains: "units must not be zero"},
+		{name: "invalid price", units: "100", entry: "abc", tp: "1.1200", sl: "1.0900",
+			wantErrContains: `invalid entry price "abc"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := NewBracketOrder("EUR_USD", tt.units, tt.entry, tt.tp, tt.sl)
+			if tt.wantErrContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErrContains) {
+					t.Fatalf("got error %v, want error containing %q", err, tt.wantErrContains)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("got error: %v", err)
+			}
+			if req.Price != tt.entry || req.TakeProfitOnFill.Price != tt.tp || *req.StopLossOnFill.Price != tt.sl {
+				t.Errorf("got entry %s, take profit %s, stop loss %s", req.Price, req.TakeProfitOnFill.Price, *req.StopLossOnFill.Price)
+			}
+		})
+	}
+}
+
+func TestNewReplaceOrderRequest(t *testing.T) {
+	gtdTime := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339Nano)
+	raw := []byte(fmt.Sprintf(`{"order":{"type":"LIMIT","id":"42","state":"PENDING","instrument":"EUR_USD","units":"-100",
+		"price":"1.10000","timeInForce":"GTD","gtdTime":%q,"positionFill":"REDUCE_ONLY",
+		"triggerCondition":"BID","clientExtensions":{"id":"my-order"},
+		"takeProfitOnFill":{"price":"1.05000","timeInForce":"GTC"}},"lastTransactionID":"42"}`, gtdTime))
+	var resp OrderDetailsResponse
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		t.Fatalf("failed to unmarshal order: %v", err)
+	}
+	req, err := NewReplaceOrderRequest(resp.Order)
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	limit, ok := req.(*LimitOrderRequest)
+	if !ok {
+		t.Fatalf("got %T, want *LimitOrderRequest", req)
+	}
+	limit.Price = "1.12000"
+
+	body, err := limit.body()
+	if err != nil {
+		t.Fatalf("failed to encode request: %v", err)
+	}
+	var got struct {
+		Order map[string]any `json:"order"`
+	}
+	if err := json.Unmarshal(body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to decode request: %v", err)
+	}
+	want := map[string]any{
+		"type":             "LIMIT",
+		"instrument":       "EUR_USD",
+		"units":            "-100",
+		"price":            "1.12000",
+		"timeInForce":      "GTD",
+		"positionFill":     "REDUCE_ONLY",
+		"triggerCondition": "BID",
+	}
+	for key, value := range want {
+		if got.Order[key] != value {
+			t.Errorf("got %s %v, want %v", key, got.Order[key], value)
+		}
+	}
+	for _, key := range []string{"gtdTime", "clientExtensions", "takeProfitOnFill"} {
+		if got.Order[key] == nil {
+			t.Errorf("%s was not copied", key)
+		}
+	}
+	for _, key := range []string{"id", "state"} {
+		if _, ok := got.Order[key]; ok {
+			t.Errorf("request contains order field %s", key)
+		}
+	}
+
+	// The request does not share its details with the Order.
+	*limit.ClientExtensions.ID = "other"
+	limit.TakeProfitOnFill.Price = "1.00000"
+	*limit.GtdTime.Time = limit.GtdTime.Add(time.Hour)
+	order := resp.Order.(LimitOrder)
+	if *order.ClientExtensions.ID != "my-order" || order.TakeProfitOnFill.Price != "1.05000" ||
+		order.GtdTime.Format(time.RFC3339Nano) != gtdTime {
+		t.Errorf("modifying the request changed the order: %+v", order)
+	}
+
+	if _, err := NewReplaceOrderRequest(MarketOrder{OrderBase: OrderBase{Type: OrderTypeMarket}}); err == nil {
+		t.Error("got no error for market order")
+	}
+}
+
+func TestOrderService_CancelAll(t *testing.T) {
+	var mu sync.Mutex
+	var cancelled []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v3/accounts/1/orders":
+			if r.URL.Query().Get("instrument") != "EUR_USD" || r.URL.Query().Get("state") != "PENDING" {
+				t.Errorf("got query %s", r.URL.RawQuery)
+			}
+			_, _ = fmt.Fprint(w, `{"orders":[
+				{"type":"LIMIT","id":"1","state":"PENDING","clientExtensions":{"tag":"bot"}},
+				{"type":"STOP","id":"2","state":"PENDING","clientExtensions":{"tag":"manual"}},
+				{"type":"LIMIT","id":"3","state":"PENDING","clientExtensions":{"tag":"bot"}},
+				{"type":"TAKE_PROFIT","id":"4","state":"PENDING","clientExtensions":{"tag":"bot"}}]}`)
+		case r.Method == http.MethodPut && strings.HasSuffix(r.URL.Path, "/cancel"):
+			id := strings.Split(r.URL.Path, "/")[5]
+			if id == "3" {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = fmt.Fprint(w, `{"errorMessage":"order not found"}`)
+				return
+			}
+			mu.Lock()
+			cancelled = append(cancelled, id)
+			mu.Unlock()
+			_, _ = fmt.Fprintf(w, `{"orderCancelTransaction":{"type":"ORDER_CANCEL","orderID":"%s"}}`, id)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+	client := NewClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+
+	filter := NewOrderFilter().SetInstrument("EUR_USD").AddTypes(OrderTypeLimit, OrderTypeStop).SetClientTag("bot")
+	results, err := client.Order.CancelAll(t.Context(), filter)
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	if len(results) != 2 || results[0].OrderID != "1" || results[1].OrderID != "3" {
+		t.Fatalf("got results %+v, want orders 1 and 3", results)
+	}
+	if results[0].Err != nil || results[0].Response.OrderCancelTransaction.OrderID != "1" {
+		t.Errorf("got result %+v for order 1", results[0])
+	}
+	if results[1].Err == nil || results[1].Response != nil {
+		t.Errorf("got result %+v for order 3, want error", results[1])
+	}
+	if len(cancelled) != 1 || cancelled[0] != "1" {
+		t.Errorf("got cancelled orders %v, want [1]", cancelled)
+	}
+}
+
+func TestOrderSpecifiers(t *testing.T) {
+	tests := []struct {
+		got, want string
+	}{
+		{ByOrderID("123"), "123"},
+		{ByClientOrderID("my-order"), "@my-order"},
+		{ByClientOrderID("@my-order"), "@my-order"},
+		{ByTradeID("456"), "456"},
+		{ByClientTradeID("my-trade"), "@my-trade"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("got specifier %q, want %q", tt.got, tt.want)
+		}
+	}
+}
+
+func TestOrderService_Iterate(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		query := r.URL.Query()
+		if query.Get("count") != "3" || query.Get("state") != "FILLED" {
+			t.Errorf("got query %s", r.URL.RawQuery)
+		}
+		before := 8
+		if b := query.Get("beforeID"); b != "" {
+			before, _ = strconv.Atoi(b)
+		}
+		var orders []string
+		for id := before - 1; id >= 1 && len(orders) < 3; id-- {
+			orders = append(orders, fmt.Sprintf(`{"type":"LIMIT","id":"%d"}`, id))
+		}
+		_, _ = fmt.Fprintf(w, `{"orders":[%s]}`, strings.Join(orders, ","))
+	}))
+	defer server.Close()
+	client := NewClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+
+	req := NewOrderListRequest().SetState(OrderStateFilled).SetCount(3)
+	var ids []string
+	for order, err := range client.Order.Iterate(t.Context(), req) {
+		if err != nil {
+			t.Fatalf("got error: %v", err)
+		}
+		ids = append(ids, order.GetID())
+	}
+	if got := strings.Join(ids, ","); got != "7,6,5,4,3,2,1" {
+		t.Errorf("got orders %s, want 7,6,5,4,3,2,1", got)
+	}
+	if requests != 3 {
+		t.Errorf("got %d requests, want 3", requests)
+	}
+	if req.BeforeID != nil {
+		t.Errorf("request was modified: beforeID %s", *req.BeforeID)
+	}
+
+	requests = 0
+	for order := range client.Order.Iterate(t.Context(), req) {
+		if order.GetID() == "6" {
+			break
+		}
+	}
+	if requests != 1 {
+		t.Errorf("got %d requests after break, want 1", requests)
+	}
+}
+
+func TestSetGTDTime(t *testing.T) {
+	future := time.Now().Add(time.Hour)
+	req := NewLimitOrderRequest("EUR_USD", "100", "1.10000").SetGTDTime(future)
+	if req.TimeInForce != TimeInForceGTD || !req.GtdTime.Equal(future) {
+		t.Fatalf("got time in force %s and GTD time %v", req.TimeInForce, req.GtdTime)
+	}
+	if _, err := req.body(); err != nil {
+		t.Errorf("got error for future GTD time: %v", err)
+	}
+
+	past := time.Now().Add(-time.Minute)
+	if _, err := NewStopLossOrderRequest("1").SetPrice("1.00000").SetGTDTime(past).body(); err == nil {
+		t.Error("got no error for past GTD time")
+	}
+	req = NewLimitOrderRequest("EUR_USD", "100", "1.10000").
+		SetTakeProfitOnFill(NewTakeProfitDetails("1.20000").SetGTDTime(past))
+	if _, err := req.body(); err == nil || !strings.Contains(err.Error(), "takeProfitOnFill.gtdTime") {
+		t.Errorf("got error %v, want error for takeProfitOnFill.gtdTime", err)
+	}
+}
+
+func TestMarketOrderRequest_Closeout(t *testing.T) {
+	body, err := NewMarketOrderRequest("EUR_USD", "-100").CloseTrade("42", "100").body()
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	if !strings.Contains(body.String(), `"tradeClose":{"tradeID":"42","units":"100"}`) {
+		t.Errorf("got body %s", body)
+	}
+
+	body, err = NewMarketOrderRequest("EUR_USD", "-100").CloseoutLongPosition("EUR_USD", "ALL").body()
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	if !strings.Contains(body.String(), `"longPositionCloseout":{"instrument":"EUR_USD","units":"ALL"}`) {
+		t.Errorf("got body %s", body)
+	}
+
+	_, err = NewMarketOrderRequest("EUR_USD", "100").
+		CloseTrade("42", "ALL").
+		CloseoutShortPosition("EUR_USD", "ALL").
+		body()
+	if err == nil {
+		t.Error("got no error for trade close combined with position closeout")
+	}
+}
+
+func TestOrderService_CreateCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		_, _ = fmt.Fprint(w, `{
+			"orderCreateTransaction":{"type":"MARKET_ORDER","id":"10","instrument":"EUR_USD","units":"100"},
+			"orderCancelTransaction":{"type":"ORDER_CANCEL","id":"11","orderID":"10","reason":"TAKE_PROFIT_ON_FILL_LOSS"},
+			"lastTransactionID":"11"}`)
+	}))
+	defer server.Close()
+	req := NewMarketOrderRequest("EUR_USD", "100")
+
+	client := NewClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+	resp, err := client.Order.Create(t.Context(), req)
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	if reason, ok := resp.WasCancelled(); !ok || reason != OrderCancelReasonTakeProfitOnFillLoss {
+		t.Errorf("got %s, %v, want %s, true", reason, ok, OrderCancelReasonTakeProfitOnFillLoss)
+	}
+
+	client = NewClient("api-key", WithBaseURL(server.URL), WithAccountID("1"), WithCancelledOrderError())
+	resp, err = client.Order.Create(t.Context(), req)
+	var cancelledErr OrderCancelledError
+	if !errors.As(err, &cancelledErr) {
+		t.Fatalf("got error %v, want OrderCancelledError", err)
+	}
+	if cancelledErr.OrderID != "10" || cancelledErr.Reason != OrderCancelReasonTakeProfitOnFillLoss {
+		t.Errorf("got order %s cancelled for %s", cancelledErr.OrderID, cancelledErr.Reason)
+	}
+	if resp == nil {
+		t.Error("got nil response with OrderCancelledError")
+	}
+}
+
+func TestOrderService_ReplacePrice(t *testing.T) {
+	var body map[string]map[string]any
+	gtdTime := time.Now().Add(24 * time.Hour)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v3/accounts/1/orders/@my-order":
+			_, _ = fmt.Fprintf(w, `{"order":{"type":"LIMIT","id":"10","state":"PENDING","instrument":"EUR_USD",
+				"units":"100","price":"1.10000","timeInForce":"GTD","gtdTime":%q,
+				"clientExtensions":{"id":"my-order"}}}`, gtdTime.UTC().Format(time.RFC3339Nano))
+		case r.Method == http.MethodPut && r.URL.Path == "/v3/accounts/1/orders/@my-order":
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("failed to decode body: %v", err)
+			}
+			w.WriteHeader(http.StatusCreated)
+			_, _ = fmt.Fprint(w, `{"orderCancelTransaction":{"type":"ORDER_CANCEL","orderID":"10"},
+				"orderCreateTransaction":{"type":"LIMIT_ORDER","id":"11","price":"1.09000"}}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client := NewClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+
+	resp, err := client.Order.ReplacePrice(t.Context(), ByClientOrderID("my-order"), "1.09000")
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	if resp.OrderCreateTransaction.GetID() != "11" {
+		t.Errorf("got order %s, want 11", resp.OrderCreateTransaction.GetID())
+	}
+	order := body["order"]
+	for key, want := range map[string]any{
+		"type": "LIMIT", "instrument": "EUR_USD", "units": "100", "price": "1.09000", "timeInForce": "GTD",
+	} {
+		if order[key] != want {
+			t.Errorf("got %s %v, want %v", key, order[key], want)
+		}
+	}
+	if order["gtdTime"] == nil || order["clientExtensions"] == nil {
+		t.Errorf("got order %v, want gtdTime and clientExtensions kept", order)
+	}
+
+	// An Order whose GTD time has passed is not replaced.
+	body = nil
+	gtdTime = time.Now().Add(-time.Minute)
+	if _, err := client.Order.ReplacePrice(t.Context(), ByClientOrderID("my-order"), "1.09000"); err == nil || !strings.Contains(err.Error(), "expired") {
+		t.Errorf("got error %v, want one for an expired order", err)
+	}
+	if body != nil {
+		t.Errorf("got replace request %v for an expired order", body)
+	}
+}
+
+func TestOrderService_ReplacePrice_OrderPolicy(t *testing.T) {
+	var body map[string]map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			_, _ = fmt.Fprint(w, `{"order":{"type":"LIMIT","id":"10","state":"PENDING","instrument":"EUR_USD",
+				"units":"100","price":"1.10000","timeInForce":"GTC","positionFill":"DEFAULT",
+				"triggerCondition":"DEFAULT"}}`)
+		case http.MethodPut:
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("failed to decode body: %v", err)
+			}
+			w.WriteHeader(http.StatusCreated)
+			_, _ = fmt.Fprint(w, `{"orderCancelTransaction":{"type":"ORDER_CANCEL","orderID":"10"},
+				"orderCreateTransaction":{"type":"LIMIT_ORDER","id":"11","price":"1.09000"}}`)
+		}
+	}))
+	defer server.Close()
+	client := NewClient("api-key", WithBaseURL(server.URL), WithAccountID("1"), WithOrderPolicy(OrderPolicy{
+		TimeInForce:      TimeInForceGFD,
+		PositionFill:     OrderPositionFillOpenOnly,
+		TriggerCondition: OrderTriggerConditionBid,
+		StopLossOnFill:   NewStopLossDetails().SetDistance("0.0050"),
+	}))
+
+	if _, err := client.Order.ReplacePrice(t.Context(), "10", "1.09000"); err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	order := body["order"]
+	for key, want := range map[string]any{
+		"price": "1.09000", "timeInForce": "GTC", "positionFill": "DEFAULT", "triggerCondition": "DEFAULT",
+	} {
+		if order[key] != want {
+			t.Errorf("got %s %v, want %v", key, order[key], want)
+		}
+	}
+	if order["stopLossOnFill"] != nil {
+		t.Errorf("got stop loss on fill %v, want none on the replaced order", order["stopLossOnFill"])
+	}
+}
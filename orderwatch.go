@@ -0,0 +1,177 @@
+package oanda
+
+import (
+	"context"
+	"errors"
+)
+
+// OrderEventType is the kind of an [OrderEvent].
+type OrderEventType string
+
+const (
+	// OrderEventCreated means the watched Order was created. It is reported for Orders that
+	// replace the watched Order.
+	OrderEventCreated OrderEventType = "CREATED"
+	// OrderEventFilled means the watched Order was filled. It ends the watch.
+	OrderEventFilled OrderEventType = "FILLED"
+	// OrderEventCancelled means the watched Order was cancelled without being replaced. It ends
+	// the watch.
+	OrderEventCancelled OrderEventType = "CANCELLED"
+	// OrderEventReplaced means the watched Order was cancelled and replaced by another Order,
+	// which is watched from then on.
+	OrderEventReplaced OrderEventType = "REPLACED"
+	// OrderEventCancelRejected means a request to cancel the Order was rejected. It is only
+	// reported by [orderService.AuditTrail].
+	OrderEventCancelRejected OrderEventType = "CANCEL_REJECTED"
+	// OrderEventClientExtensionsModified means the client extensions of the Order were
+	// modified. It is only reported by [orderService.AuditTrail].
+	OrderEventClientExtensionsModified OrderEventType = "CLIENT_EXTENSIONS_MODIFIED"
+)
+
+// OrderEvent is a lifecycle event of an Order watched with [StreamClient.WatchOrder] or
+// reconstructed with [orderService.AuditTrail].
+type OrderEvent struct {
+	// Type is the kind of event.
+	Type OrderEventType
+	// OrderID is the ID of the Order the event is about.
+	OrderID OrderID
+	// ReplacedByOrderID is the ID of the replacing Order. It is only set for
+	// [OrderEventReplaced].
+	ReplacedByOrderID *OrderID
+	// Transaction is the Transaction the event was derived from: an [OrderTransaction] for
+	// [OrderEventCreated], an [OrderFillTransaction] for [OrderEventFilled], and an
+	// [OrderCancelTransaction] otherwise. Events reported by [orderService.AuditTrail] hold
+	// pointers to the Transactions instead, as returned by [transactionService.GetByIDRange].
+	Transaction Transaction
+}
+
+// Terminal reports whether the event ends the Order's lifecycle.
+func (e OrderEvent) Terminal() bool {
+	return e.Type == OrderEventFilled || e.Type == OrderEventCancelled
+}
+
+// OrderWatch delivers the lifecycle events of a single Order. It is created by
+// [StreamClient.WatchOrder] and implements [Subscription].
+type OrderWatch struct {
+	stream  *TransactionStream
+	events  chan OrderEvent
+	done    chan struct{}
+	cancel  context.CancelFunc
+	orderID OrderID
+}
+
+var _ Subscription[OrderEvent] = (*OrderWatch)(nil)
+
+// WatchOrder opens a transaction stream and reports the lifecycle events of the Order with the
+// given ID. When the Order is replaced, the replacing Order is followed. The events channel is
+// closed after the Order has been filled or cancelled, or when the stream ends. Events that
+// happened before the stream was opened are not reported; check the Order's state with
+// [orderService.Details] after opening the watch to cover them.
+//
+// This corresponds to the OANDA API endpoint: GET /v3/accounts/{accountID}/transactions/stream
+//
+// Reference: https://developer.oanda.com/rest-live-v20/transaction-ep/#collapse_endpoint_5
+func (c *StreamClient) WatchOrder(ctx context.Context, orderID OrderID) (*OrderWatch, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	stream, err := c.TransactionStream(ctx)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	w := &OrderWatch{
+		stream:  stream,
+		events:  make(chan OrderEvent),
+		done:    make(chan struct{}),
+		cancel:  cancel,
+		orderID: orderID,
+	}
+	go w.run(ctx)
+	return w, nil
+}
+
+func (w *OrderWatch) run(ctx context.Context) {
+	defer close(w.done)
+	defer close(w.events)
+	defer w.stream.Close()
+	watching := w.orderID
+	for item := range w.stream.Updates() {
+		event, ok := orderEventFor(item, watching)
+		if !ok {
+			continue
+		}
+		select {
+		case w.events <- event:
+		case <-ctx.Done():
+			return
+		}
+		if event.Terminal() {
+			return
+		}
+		if event.ReplacedByOrderID != nil {
+			watching = *event.ReplacedByOrderID
+		}
+	}
+}
+
+// orderEventFor derives the lifecycle event of the Order with the given ID from item, if any.
+func orderEventFor(item TransactionStreamItem, orderID OrderID) (OrderEvent, bool) {
+	switch t := item.(type) {
+	case OrderFillTransaction:
+		if t.OrderID == orderID {
+			return OrderEvent{Type: OrderEventFilled, OrderID: orderID, Transaction: t}, true
+		}
+	case OrderCancelTransaction:
+		if t.OrderID != orderID {
+			break
+		}
+		if t.ReplacedByOrderID != nil {
+			return OrderEvent{
+				Type:              OrderEventReplaced,
+				OrderID:           orderID,
+				ReplacedByOrderID: t.ReplacedByOrderID,
+				Transaction:       t,
+			}, true
+		}
+		return OrderEvent{Type: OrderEventCancelled, OrderID: orderID, Transaction: t}, true
+	case OrderRejectTransaction:
+	case OrderTransaction:
+		if t.GetID() == orderID {
+			return OrderEvent{Type: OrderEventCreated, OrderID: orderID, Transaction: t}, true
+		}
+	}
+	return OrderEvent{}, false
+}
+
+// Updates returns the channel on which the Order's lifecycle events are delivered. It is closed
+// after a terminal event or when the underlying stream ends.
+func (w *OrderWatch) Updates() <-chan OrderEvent {
+	return w.events
+}
+
+// Err returns the error that ended the underlying stream, or nil if the watch is still
+// running, ended with a terminal event, or was stopped with Close.
+func (w *OrderWatch) Err() error {
+	return w.stream.Err()
+}
+
+// Close stops the watch and waits for it to end. It is safe to call more than once.
+func (w *OrderWatch) Close() error {
+	w.cancel()
+	<-w.done
+	return w.stream.Close()
+}
+
+// Wait blocks until the watched Order has been filled or cancelled and returns the terminal
+// event. Non-terminal events are discarded. If the watch ends first, Wait returns the stream's
+// error, or an error saying the watch ended if there is none.
+func (w *OrderWatch) Wait() (OrderEvent, error) {
+	for event := range w.events {
+		if event.Terminal() {
+			return event, nil
+		}
+	}
+	if err := w.Err(); err != nil {
+		return OrderEvent{}, err
+	}
+	return OrderEvent{}, errors.New("order watch ended before the order was filled or cancelled")
+}
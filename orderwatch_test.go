@@ -0,0 +1,85 @@
+package oanda
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStreamClient_WatchOrder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, line := range []string{
+			`{"type":"LIMIT_ORDER","id":"5","instrument":"USD_JPY","units":"100","price":"150.000"}`,
+			`{"type":"ORDER_CANCEL","id":"6","orderID":"1","reason":"CLIENT_REQUEST_REPLACED","replacedByOrderID":"7"}`,
+			`{"type":"LIMIT_ORDER","id":"7","instrument":"EUR_USD","units":"100","price":"1.10000"}`,
+			`{"type":"HEARTBEAT","lastTransactionID":"7","time":"2024-01-01T00:00:00Z"}`,
+			`{"type":"ORDER_FILL","id":"8","orderID":"7","units":"100"}`,
+			`{"type":"ORDER_CANCEL","id":"9","orderID":"7"}`,
+		} {
+			_, _ = fmt.Fprintln(w, line)
+		}
+	}))
+	defer server.Close()
+	client := NewStreamClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+
+	watch, err := client.WatchOrder(t.Context(), "1")
+	if err != nil {
+		t.Fatalf("failed to watch order: %v", err)
+	}
+	defer watch.Close()
+	var events []OrderEvent
+	for event := range watch.Updates() {
+		events = append(events, event)
+	}
+	if err := watch.Err(); err != nil {
+		t.Errorf("got error: %v", err)
+	}
+	want := []struct {
+		typ     OrderEventType
+		orderID OrderID
+	}{
+		{OrderEventReplaced, "1"},
+		{OrderEventCreated, "7"},
+		{OrderEventFilled, "7"},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events %+v, want %d", len(events), events, len(want))
+	}
+	for i, w := range want {
+		if events[i].Type != w.typ || events[i].OrderID != w.orderID {
+			t.Errorf("got event %s for order %s, want %s for order %s", events[i].Type, events[i].OrderID, w.typ, w.orderID)
+		}
+	}
+	if fill, ok := events[2].Transaction.(OrderFillTransaction); !ok || fill.Units != "100" {
+		t.Errorf("got fill transaction %#v", events[2].Transaction)
+	}
+}
+
+func TestOrderWatch_Wait(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintln(w, `{"type":"ORDER_CANCEL","id":"2","orderID":"1","reason":"TIME_IN_FORCE_EXPIRED"}`)
+	}))
+	defer server.Close()
+	client := NewStreamClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+
+	watch, err := client.WatchOrder(t.Context(), "1")
+	if err != nil {
+		t.Fatalf("failed to watch order: %v", err)
+	}
+	event, err := watch.Wait()
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	if event.Type != OrderEventCancelled {
+		t.Errorf("got event %s, want %s", event.Type, OrderEventCancelled)
+	}
+
+	watch, err = client.WatchOrder(t.Context(), "3")
+	if err != nil {
+		t.Fatalf("failed to watch order: %v", err)
+	}
+	if _, err := watch.Wait(); err == nil {
+		t.Error("got no error for a watch that ended without a terminal event")
+	}
+}
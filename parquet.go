@@ -0,0 +1,356 @@
+package oanda
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"time"
+)
+
+// The subset of the Parquet format used by parquetWriter, with the values of the Thrift enums of
+// the format specification: https://github.com/apache/parquet-format
+const (
+	parquetMagic = "PAR1"
+
+	parquetBoolean   = 0
+	parquetInt64     = 2
+	parquetDouble    = 5
+	parquetByteArray = 6
+
+	parquetConvertedNone            = -1
+	parquetConvertedUTF8            = 0
+	parquetConvertedTimestampMicros = 10
+
+	parquetRequired     = 0
+	parquetPlain        = 0
+	parquetRLE          = 3
+	parquetDataPage     = 0
+	parquetUncompressed = 0
+)
+
+// parquetRowGroupSize is the number of records a parquetWriter buffers per row group.
+const parquetRowGroupSize = 64 * 1024
+
+// parquetColumn is a column of a flat struct type written to Parquet.
+type parquetColumn struct {
+	name      string
+	field     int
+	physical  int32
+	converted int32
+}
+
+// parquetColumns returns the columns of the struct type t, named by the parquet tags of its
+// fields: times are timestamps in microseconds, strings UTF-8 byte arrays, and float64, int64 and
+// bool fields doubles, int64 and booleans. Fields of other types are not supported.
+func parquetColumns(t reflect.Type) ([]parquetColumn, error) {
+	var columns []parquetColumn
+	for i := range t.NumField() {
+		field := t.Field(i)
+		column := parquetColumn{name: field.Tag.Get("parquet"), field: i, converted: parquetConvertedNone}
+		switch {
+		case field.Type == reflect.TypeFor[time.Time]():
+			column.physical, column.converted = parquetInt64, parquetConvertedTimestampMicros
+		case field.Type.Kind() == reflect.Float64:
+			column.physical = parquetDouble
+		case field.Type.Kind() == reflect.Int64:
+			column.physical = parquetInt64
+		case field.Type.Kind() == reflect.Bool:
+			column.physical = parquetBoolean
+		case field.Type.Kind() == reflect.String:
+			column.physical, column.converted = parquetByteArray, parquetConvertedUTF8
+		default:
+			return nil, fmt.Errorf("unsupported parquet field %s of type %s", field.Name, field.Type)
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+// parquetWriter writes records of the flat struct type T as a Parquet file: required columns
+// named by [parquetColumns], PLAIN encoded and uncompressed, one data page per column chunk.
+// Records are buffered and written in row groups of rowGroupSize records, so that memory use is
+// bounded however many records are written. The file footer is written by close.
+type parquetWriter[T any] struct {
+	w            io.Writer
+	columns      []parquetColumn
+	rowGroupSize int
+	rows         []T
+	offset       int64
+	numRows      int64
+	rowGroups    []parquetRowGroup
+	closed       bool
+}
+
+// parquetRowGroup is the metadata of a row group written by a parquetWriter.
+type parquetRowGroup struct {
+	numRows int64
+	size    int64
+	chunks  []parquetChunk
+}
+
+// parquetChunk is the metadata of a column chunk written by a parquetWriter.
+type parquetChunk struct {
+	offset int64
+	size   int64
+}
+
+func newParquetWriter[T any](w io.Writer) *parquetWriter[T] {
+	columns, err := parquetColumns(reflect.TypeFor[T]())
+	if err != nil {
+		// T is one of the record types of this package, whose fields are all supported.
+		panic(err)
+	}
+	return &parquetWriter[T]{w: w, columns: columns, rowGroupSize: parquetRowGroupSize}
+}
+
+// write buffers record, writing a row group once rowGroupSize records are buffered.
+func (w *parquetWriter[T]) write(record T) error {
+	if w.closed {
+		return errors.New("parquet writer is closed")
+	}
+	w.rows = append(w.rows, record)
+	if len(w.rows) >= w.rowGroupSize {
+		return w.flushRowGroup()
+	}
+	return nil
+}
+
+// close writes the buffered records and the file footer. The file is valid, with no rows, even
+// if no record was written.
+func (w *parquetWriter[T]) close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	if len(w.rows) > 0 {
+		if err := w.flushRowGroup(); err != nil {
+			return err
+		}
+	}
+	if err := w.writeMagic(); err != nil {
+		return err
+	}
+	footer := w.fileMetaData()
+	footer = binary.LittleEndian.AppendUint32(footer, uint32(len(footer)))
+	footer = append(footer, parquetMagic...)
+	return w.emit(footer)
+}
+
+func (w *parquetWriter[T]) emit(b []byte) error {
+	n, err := w.w.Write(b)
+	w.offset += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to write parquet file: %w", err)
+	}
+	return nil
+}
+
+// writeMagic writes the magic number that starts a Parquet file, if nothing was written yet.
+func (w *parquetWriter[T]) writeMagic() error {
+	if w.offset > 0 {
+		return nil
+	}
+	return w.emit([]byte(parquetMagic))
+}
+
+// flushRowGroup writes the buffered records as a row group, one column chunk after the other.
+func (w *parquetWriter[T]) flushRowGroup() error {
+	if err := w.writeMagic(); err != nil {
+		return err
+	}
+	group := parquetRowGroup{numRows: int64(len(w.rows))}
+	for _, column := range w.columns {
+		data := w.encodeColumn(column)
+		var header thriftEncoder
+		header.begin()
+		header.i32(1, parquetDataPage)
+		header.i32(2, int32(len(data)))
+		header.i32(3, int32(len(data)))
+		header.structField(5)
+		header.i32(1, int32(len(w.rows)))
+		header.i32(2, parquetPlain)
+		header.i32(3, parquetRLE)
+		header.i32(4, parquetRLE)
+		header.end()
+		header.end()
+
+		chunk := parquetChunk{offset: w.offset, size: int64(len(header.b) + len(data))}
+		if err := w.emit(header.b); err != nil {
+			return err
+		}
+		if err := w.emit(data); err != nil {
+			return err
+		}
+		group.chunks = append(group.chunks, chunk)
+		group.size += chunk.size
+	}
+	w.rowGroups = append(w.rowGroups, group)
+	w.numRows += group.numRows
+	w.rows = w.rows[:0]
+	return nil
+}
+
+// encodeColumn returns the PLAIN encoding of the values of column in the buffered records. As
+// the columns are required, the page has no repetition or definition levels.
+func (w *parquetWriter[T]) encodeColumn(column parquetColumn) []byte {
+	var b []byte
+	for i := range w.rows {
+		v := reflect.ValueOf(&w.rows[i]).Elem().Field(column.field)
+		switch {
+		case column.converted == parquetConvertedTimestampMicros:
+			b = binary.LittleEndian.AppendUint64(b, uint64(v.Interface().(time.Time).UnixMicro()))
+		case column.physical == parquetDouble:
+			b = binary.LittleEndian.AppendUint64(b, math.Float64bits(v.Float()))
+		case column.physical == parquetInt64:
+			b = binary.LittleEndian.AppendUint64(b, uint64(v.Int()))
+		case column.physical == parquetBoolean:
+			// Booleans are bit-packed, least significant bit first.
+			if i%8 == 0 {
+				b = append(b, 0)
+			}
+			if v.Bool() {
+				b[len(b)-1] |= 1 << (i % 8)
+			}
+		case column.physical == parquetByteArray:
+			b = binary.LittleEndian.AppendUint32(b, uint32(v.Len()))
+			b = append(b, v.String()...)
+		}
+	}
+	return b
+}
+
+// fileMetaData returns the Thrift encoding of the FileMetaData of the file.
+func (w *parquetWriter[T]) fileMetaData() []byte {
+	var e thriftEncoder
+	e.begin()
+	e.i32(1, 1)
+	e.list(2, thriftStruct, len(w.columns)+1)
+	e.begin()
+	e.string(4, reflect.TypeFor[T]().Name())
+	e.i32(5, int32(len(w.columns)))
+	e.end()
+	for _, column := range w.columns {
+		e.begin()
+		e.i32(1, column.physical)
+		e.i32(3, parquetRequired)
+		e.string(4, column.name)
+		if column.converted != parquetConvertedNone {
+			e.i32(6, column.converted)
+		}
+		e.end()
+	}
+	e.i64(3, w.numRows)
+	e.list(4, thriftStruct, len(w.rowGroups))
+	for _, group := range w.rowGroups {
+		e.begin()
+		e.list(1, thriftStruct, len(group.chunks))
+		for i, chunk := range group.chunks {
+			column := w.columns[i]
+			e.begin()
+			e.i64(2, chunk.offset)
+			e.structField(3)
+			e.i32(1, column.physical)
+			e.list(2, thriftI32, 1)
+			e.varint(parquetPlain)
+			e.list(3, thriftBinary, 1)
+			e.uvarint(uint64(len(column.name)))
+			e.b = append(e.b, column.name...)
+			e.i32(4, parquetUncompressed)
+			e.i64(5, group.numRows)
+			e.i64(6, chunk.size)
+			e.i64(7, chunk.size)
+			e.i64(9, chunk.offset)
+			e.end()
+			e.end()
+		}
+		e.i64(2, group.size)
+		e.i64(3, group.numRows)
+		e.end()
+	}
+	e.string(6, "github.com/s-shiga/oanda-go")
+	e.end()
+	return e.b
+}
+
+// Types of the Thrift compact protocol.
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftEncoder encodes structs with the Thrift compact protocol, in which the Parquet metadata
+// is serialized. Every struct is opened with begin, or structField for a field, and closed with
+// end.
+type thriftEncoder struct {
+	b []byte
+	// last holds the ID of the last field written to each open struct, as field IDs are
+	// encoded as deltas.
+	last []int16
+}
+
+func (e *thriftEncoder) begin() {
+	e.last = append(e.last, 0)
+}
+
+func (e *thriftEncoder) end() {
+	e.b = append(e.b, 0)
+	e.last = e.last[:len(e.last)-1]
+}
+
+func (e *thriftEncoder) field(id int16, typ byte) {
+	last := &e.last[len(e.last)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		e.b = append(e.b, byte(delta)<<4|typ)
+	} else {
+		e.b = append(e.b, typ)
+		e.varint(int64(id))
+	}
+	*last = id
+}
+
+// varint appends v zigzag encoded, as the compact protocol encodes integers.
+func (e *thriftEncoder) varint(v int64) {
+	e.b = binary.AppendVarint(e.b, v)
+}
+
+func (e *thriftEncoder) uvarint(v uint64) {
+	e.b = binary.AppendUvarint(e.b, v)
+}
+
+func (e *thriftEncoder) i32(id int16, v int32) {
+	e.field(id, thriftI32)
+	e.varint(int64(v))
+}
+
+func (e *thriftEncoder) i64(id int16, v int64) {
+	e.field(id, thriftI64)
+	e.varint(v)
+}
+
+func (e *thriftEncoder) string(id int16, s string) {
+	e.field(id, thriftBinary)
+	e.uvarint(uint64(len(s)))
+	e.b = append(e.b, s...)
+}
+
+func (e *thriftEncoder) structField(id int16) {
+	e.field(id, thriftStruct)
+	e.begin()
+}
+
+// list writes the header of a list field of n elements of type elem, which must follow.
+func (e *thriftEncoder) list(id int16, elem byte, n int) {
+	e.field(id, thriftList)
+	if n < 15 {
+		e.b = append(e.b, byte(n)<<4|elem)
+		return
+	}
+	e.b = append(e.b, 0xf0|elem)
+	e.uvarint(uint64(n))
+}
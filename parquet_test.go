@@ -0,0 +1,175 @@
+package oanda
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+)
+
+// thriftDecoder decodes Thrift compact protocol structs into maps from field IDs to values, to
+// check the metadata written by parquetWriter.
+type thriftDecoder struct {
+	b []byte
+}
+
+func (d *thriftDecoder) uvarint() uint64 {
+	v, n := binary.Uvarint(d.b)
+	d.b = d.b[n:]
+	return v
+}
+
+func (d *thriftDecoder) varint() int64 {
+	v, n := binary.Varint(d.b)
+	d.b = d.b[n:]
+	return v
+}
+
+func (d *thriftDecoder) value(typ byte) any {
+	switch typ {
+	case thriftI32, thriftI64:
+		return d.varint()
+	case thriftBinary:
+		n := d.uvarint()
+		s := string(d.b[:n])
+		d.b = d.b[n:]
+		return s
+	case thriftList:
+		header := d.b[0]
+		d.b = d.b[1:]
+		n := uint64(header >> 4)
+		if n == 15 {
+			n = d.uvarint()
+		}
+		list := make([]any, n)
+		for i := range list {
+			list[i] = d.value(header & 0x0f)
+		}
+		return list
+	case thriftStruct:
+		fields := make(map[int16]any)
+		var id int16
+		for {
+			header := d.b[0]
+			d.b = d.b[1:]
+			if header == 0 {
+				return fields
+			}
+			if delta := int16(header >> 4); delta != 0 {
+				id += delta
+			} else {
+				id = int16(d.varint())
+			}
+			fields[id] = d.value(header & 0x0f)
+		}
+	}
+	panic("unsupported thrift type")
+}
+
+type parquetTestRecord struct {
+	Time  time.Time `parquet:"time"`
+	Name  string    `parquet:"name"`
+	Value float64   `parquet:"value"`
+	Count int64     `parquet:"count"`
+	Flag  bool      `parquet:"flag"`
+}
+
+func TestParquetWriter(t *testing.T) {
+	base := time.Date(2024, 1, 2, 3, 4, 5, 6000, time.UTC)
+	var records []parquetTestRecord
+	for i := range 11 {
+		records = append(records, parquetTestRecord{
+			Time:  base.Add(time.Duration(i) * time.Minute),
+			Name:  string(rune('a' + i)),
+			Value: float64(i) / 2,
+			Count: int64(-i),
+			Flag:  i%3 == 0,
+		})
+	}
+	var buf bytes.Buffer
+	w := newParquetWriter[parquetTestRecord](&buf)
+	w.rowGroupSize = 4
+	for _, record := range records {
+		if err := w.write(record); err != nil {
+			t.Fatalf("failed to write record: %v", err)
+		}
+	}
+	if err := w.close(); err != nil {
+		t.Fatalf("failed to close: %v", err)
+	}
+	if err := w.write(records[0]); err == nil {
+		t.Error("got no error writing to a closed writer")
+	}
+
+	file := buf.Bytes()
+	if !bytes.HasPrefix(file, []byte("PAR1")) || !bytes.HasSuffix(file, []byte("PAR1")) {
+		t.Fatal("file does not start and end with PAR1")
+	}
+	size := binary.LittleEndian.Uint32(file[len(file)-8:])
+	footer := &thriftDecoder{b: file[len(file)-8-int(size) : len(file)-8]}
+	meta := footer.value(thriftStruct).(map[int16]any)
+	if len(footer.b) != 0 || meta[1] != int64(1) || meta[3] != int64(11) {
+		t.Fatalf("got metadata %v", meta)
+	}
+	schema := meta[2].([]any)
+	var names []string
+	for _, element := range schema[1:] {
+		names = append(names, element.(map[int16]any)[4].(string))
+	}
+	if root := schema[0].(map[int16]any); root[4] != "parquetTestRecord" || root[5] != int64(5) ||
+		len(names) != 5 || names[0] != "time" || names[4] != "flag" {
+		t.Fatalf("got schema %v", schema)
+	}
+
+	// Read the columns back from the data pages of every row group.
+	var got []parquetTestRecord
+	for _, group := range meta[4].([]any) {
+		group := group.(map[int16]any)
+		n := int(group[3].(int64))
+		rows := make([]parquetTestRecord, n)
+		for i, chunk := range group[1].([]any) {
+			column := chunk.(map[int16]any)[3].(map[int16]any)
+			page := &thriftDecoder{b: file[column[9].(int64):]}
+			header := page.value(thriftStruct).(map[int16]any)
+			if header[5].(map[int16]any)[1] != int64(n) || column[5] != int64(n) {
+				t.Fatalf("got page header %v for %d rows", header, n)
+			}
+			data := page.b[:header[2].(int64)]
+			for j := range rows {
+				switch i {
+				case 0:
+					rows[j].Time = time.UnixMicro(int64(binary.LittleEndian.Uint64(data))).UTC()
+					data = data[8:]
+				case 1:
+					l := binary.LittleEndian.Uint32(data)
+					rows[j].Name = string(data[4 : 4+l])
+					data = data[4+l:]
+				case 2:
+					rows[j].Value = math.Float64frombits(binary.LittleEndian.Uint64(data))
+					data = data[8:]
+				case 3:
+					rows[j].Count = int64(binary.LittleEndian.Uint64(data))
+					data = data[8:]
+				case 4:
+					rows[j].Flag = data[j/8]&(1<<(j%8)) != 0
+				}
+			}
+		}
+		got = append(got, rows...)
+	}
+	if len(got) != len(records) {
+		t.Fatalf("got %d records, want %d", len(got), len(records))
+	}
+	for i := range records {
+		if !got[i].Time.Equal(records[i].Time) || got[i].Name != records[i].Name || got[i].Value != records[i].Value ||
+			got[i].Count != records[i].Count || got[i].Flag != records[i].Flag {
+			t.Errorf("got record %+v, want %+v", got[i], records[i])
+		}
+	}
+
+	var empty bytes.Buffer
+	if err := newParquetWriter[parquetTestRecord](&empty).close(); err != nil || !bytes.HasPrefix(empty.Bytes(), []byte("PAR1")) {
+		t.Errorf("got empty file %q (%v)", empty.Bytes(), err)
+	}
+}
@@ -0,0 +1,243 @@
+package oanda
+
+import (
+	"iter"
+	"math/big"
+	"time"
+)
+
+// PerformanceStats are trading performance statistics of a set of closed Trades. The result of
+// a Trade is the profit or loss realized over its life, including partial closes, in the
+// Account's home currency; financing and commission are not included.
+type PerformanceStats struct {
+	// Trades is the number of closed Trades.
+	Trades int
+	// Wins is the number of Trades with a profit.
+	Wins int
+	// Losses is the number of Trades with a loss. Trades that broke even are neither wins nor
+	// losses.
+	Losses int
+	// WinRate is Wins as a fraction of Trades.
+	WinRate float64
+	// GrossProfit is the sum of the profits of the winning Trades.
+	GrossProfit AccountUnits
+	// GrossLoss is the sum of the losses of the losing Trades, as a positive amount.
+	GrossLoss AccountUnits
+	// NetPL is GrossProfit less GrossLoss.
+	NetPL AccountUnits
+	// AverageWin is the average profit of the winning Trades.
+	AverageWin AccountUnits
+	// AverageLoss is the average loss of the losing Trades, as a positive amount.
+	AverageLoss AccountUnits
+	// ProfitFactor is GrossProfit divided by GrossLoss, or zero if there are no losses.
+	ProfitFactor float64
+	// Expectancy is the average result of a Trade: NetPL divided by Trades.
+	Expectancy AccountUnits
+	// MaxConsecutiveWins is the longest run of winning Trades, in the order they were closed.
+	MaxConsecutiveWins int
+	// MaxConsecutiveLosses is the longest run of losing Trades, in the order they were closed.
+	MaxConsecutiveLosses int
+	// AverageHoldingTime is the average time between the opening and the closing of a Trade.
+	// Trades opened before the first Transaction summarized are not included.
+	AverageHoldingTime time.Duration
+	// MaxHoldingTime is the longest time a Trade was held.
+	MaxHoldingTime time.Duration
+}
+
+// PerformanceReport is the trading performance of the Trades closed by a set of Transactions, in
+// total and broken down by Instrument and by client tag. Create one with [SummarizePerformance].
+type PerformanceReport struct {
+	// Total is the performance of all Trades.
+	Total PerformanceStats
+	// ByInstrument is the performance of the Trades of each Instrument.
+	ByInstrument map[InstrumentName]PerformanceStats
+	// ByTag is the performance of the Trades with each client tag. Trades without a tag, or
+	// opened before the first Transaction summarized, are reported under the empty tag.
+	ByTag map[ClientTag]PerformanceStats
+}
+
+// SummarizePerformance builds a [PerformanceReport] from the Trades closed by the
+// OrderFillTransactions among transactions, such as the iterator returned by
+// [transactionService.Iterate]. Transactions must be in ID order. The fills that open the Trades
+// and the TradeClientExtensionsModifyTransactions that tag them are used to learn their opening
+// time and client tag; other Transactions are ignored.
+func SummarizePerformance(transactions iter.Seq2[Transaction, error]) (*PerformanceReport, error) {
+	s := performanceSummary{
+		trades:       make(map[TradeID]*tradeRecord),
+		byInstrument: make(map[InstrumentName]*performanceAccumulator),
+		byTag:        make(map[ClientTag]*performanceAccumulator),
+	}
+	for transaction, err := range transactions {
+		if err != nil {
+			return nil, err
+		}
+		if err := s.addTransaction(transaction); err != nil {
+			return nil, err
+		}
+	}
+	report := &PerformanceReport{
+		Total:        s.total.stats(),
+		ByInstrument: make(map[InstrumentName]PerformanceStats, len(s.byInstrument)),
+		ByTag:        make(map[ClientTag]PerformanceStats, len(s.byTag)),
+	}
+	for instrument, a := range s.byInstrument {
+		report.ByInstrument[instrument] = a.stats()
+	}
+	for tag, a := range s.byTag {
+		report.ByTag[tag] = a.stats()
+	}
+	return report, nil
+}
+
+// tradeRecord is what is known of an open Trade.
+type tradeRecord struct {
+	instrument InstrumentName
+	tag        ClientTag
+	openTime   time.Time
+	pl         decimalSum
+}
+
+// performanceSummary accumulates the statistics of a [PerformanceReport].
+type performanceSummary struct {
+	trades       map[TradeID]*tradeRecord
+	total        performanceAccumulator
+	byInstrument map[InstrumentName]*performanceAccumulator
+	byTag        map[ClientTag]*performanceAccumulator
+}
+
+func (s *performanceSummary) addTransaction(transaction Transaction) error {
+	switch t := transaction.(type) {
+	case OrderFillTransaction:
+		return s.addFill(&t)
+	case *OrderFillTransaction:
+		return s.addFill(t)
+	case TradeClientExtensionsModifyTransaction:
+		s.modifyTag(&t)
+	case *TradeClientExtensionsModifyTransaction:
+		s.modifyTag(t)
+	}
+	return nil
+}
+
+func (s *performanceSummary) modifyTag(t *TradeClientExtensionsModifyTransaction) {
+	if tag := t.TradeClientExtensionsModify.Tag; tag != nil {
+		if trade, ok := s.trades[t.TradeID]; ok {
+			trade.tag = *tag
+		}
+	}
+}
+
+func (s *performanceSummary) addFill(fill *OrderFillTransaction) error {
+	var fillTime time.Time
+	if fill.Time.Time != nil {
+		fillTime = *fill.Time.Time
+	}
+	trade := func(id TradeID) *tradeRecord {
+		if trade, ok := s.trades[id]; ok {
+			return trade
+		}
+		return &tradeRecord{instrument: fill.Instrument}
+	}
+	for _, closed := range fill.TradesClosed {
+		t := trade(closed.TradeID)
+		if err := t.pl.add(string(closed.RealizedPL)); err != nil {
+			return err
+		}
+		delete(s.trades, closed.TradeID)
+		var holding time.Duration
+		if !t.openTime.IsZero() && !fillTime.IsZero() {
+			holding = fillTime.Sub(t.openTime)
+		}
+		for _, a := range []*performanceAccumulator{&s.total, entry(s.byInstrument, t.instrument), entry(s.byTag, t.tag)} {
+			a.add(&t.pl, holding)
+		}
+	}
+	if reduced := fill.TradeReduced; reduced != nil {
+		t := trade(reduced.TradeID)
+		if err := t.pl.add(string(reduced.RealizedPL)); err != nil {
+			return err
+		}
+		s.trades[reduced.TradeID] = t
+	}
+	if opened := fill.TradeOpened; opened != nil {
+		t := &tradeRecord{instrument: fill.Instrument, openTime: fillTime}
+		if opened.ClientExtensions != nil && opened.ClientExtensions.Tag != nil {
+			t.tag = *opened.ClientExtensions.Tag
+		}
+		s.trades[opened.TradeID] = t
+	}
+	return nil
+}
+
+// performanceAccumulator accumulates the statistics of closed Trades.
+type performanceAccumulator struct {
+	trades, wins, losses  int
+	profit, loss          decimalSum
+	winRun, lossRun       int
+	maxWinRun, maxLossRun int
+	held                  int
+	holding, maxHolding   time.Duration
+}
+
+// add adds a Trade with the result pl, held for holding, or zero if unknown.
+func (a *performanceAccumulator) add(pl *decimalSum, holding time.Duration) {
+	a.trades++
+	switch pl.sum.Sign() {
+	case 1:
+		a.wins++
+		a.winRun++
+		a.lossRun = 0
+		a.profit.sum.Add(&a.profit.sum, &pl.sum)
+	case -1:
+		a.losses++
+		a.lossRun++
+		a.winRun = 0
+		a.loss.sum.Sub(&a.loss.sum, &pl.sum)
+	default:
+		a.winRun, a.lossRun = 0, 0
+	}
+	a.profit.places = max(a.profit.places, pl.places)
+	a.loss.places = a.profit.places
+	a.maxWinRun = max(a.maxWinRun, a.winRun)
+	a.maxLossRun = max(a.maxLossRun, a.lossRun)
+	if holding > 0 {
+		a.held++
+		a.holding += holding
+		a.maxHolding = max(a.maxHolding, holding)
+	}
+}
+
+func (a *performanceAccumulator) stats() PerformanceStats {
+	places := a.profit.places
+	average := func(sum *big.Rat, n int) AccountUnits {
+		if n == 0 {
+			return AccountUnits(new(big.Rat).FloatString(places))
+		}
+		return AccountUnits(new(big.Rat).Quo(sum, big.NewRat(int64(n), 1)).FloatString(places))
+	}
+	net := new(big.Rat).Sub(&a.profit.sum, &a.loss.sum)
+	stats := PerformanceStats{
+		Trades:               a.trades,
+		Wins:                 a.wins,
+		Losses:               a.losses,
+		GrossProfit:          AccountUnits(a.profit.String()),
+		GrossLoss:            AccountUnits(a.loss.String()),
+		NetPL:                AccountUnits(net.FloatString(places)),
+		AverageWin:           average(&a.profit.sum, a.wins),
+		AverageLoss:          average(&a.loss.sum, a.losses),
+		Expectancy:           average(net, a.trades),
+		MaxConsecutiveWins:   a.maxWinRun,
+		MaxConsecutiveLosses: a.maxLossRun,
+		MaxHoldingTime:       a.maxHolding,
+	}
+	if a.trades > 0 {
+		stats.WinRate = float64(a.wins) / float64(a.trades)
+	}
+	if a.loss.sum.Sign() != 0 {
+		stats.ProfitFactor, _ = new(big.Rat).Quo(&a.profit.sum, &a.loss.sum).Float64()
+	}
+	if a.held > 0 {
+		stats.AverageHoldingTime = a.holding / time.Duration(a.held)
+	}
+	return stats
+}
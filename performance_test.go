@@ -0,0 +1,63 @@
+package oanda
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSummarizePerformance(t *testing.T) {
+	store := NewMemoryTransactionStore()
+	var transactions []Transaction
+	for _, line := range []string{
+		`{"type":"ORDER_FILL","id":"10","time":"2024-01-02T10:00:00Z","instrument":"EUR_USD","units":"100",
+			"tradeOpened":{"tradeID":"10","units":"100","clientExtensions":{"tag":"trend"}}}`,
+		`{"type":"ORDER_FILL","id":"11","time":"2024-01-02T10:00:00Z","instrument":"EUR_USD","units":"-50",
+			"tradeOpened":{"tradeID":"11","units":"-50"}}`,
+		`{"type":"TRADE_CLIENT_EXTENSIONS_MODIFY","id":"12","tradeID":"11","tradeClientExtensionsModify":{"tag":"scalp"}}`,
+		`{"type":"ORDER_FILL","id":"13","time":"2024-01-02T11:00:00Z","instrument":"EUR_USD","units":"-40",
+			"tradeReduced":{"tradeID":"10","units":"-40","realizedPL":"-1.0000"}}`,
+		`{"type":"ORDER_FILL","id":"14","time":"2024-01-02T12:00:00Z","instrument":"EUR_USD","units":"-60",
+			"tradesClosed":[{"tradeID":"10","units":"-60","realizedPL":"4.0000"}]}`,
+		`{"type":"ORDER_FILL","id":"15","time":"2024-01-02T14:00:00Z","instrument":"EUR_USD","units":"50",
+			"tradesClosed":[{"tradeID":"11","units":"50","realizedPL":"-2.0000"}]}`,
+		// Opened before the first Transaction.
+		`{"type":"ORDER_FILL","id":"16","time":"2024-01-03T00:00:00Z","instrument":"USD_JPY","units":"-10",
+			"tradesClosed":[{"tradeID":"1","units":"-10","realizedPL":"-1.00"}]}`,
+	} {
+		transaction, err := unmarshalTransaction([]byte(line))
+		if err != nil {
+			t.Fatalf("failed to decode transaction: %v", err)
+		}
+		transactions = append(transactions, transaction)
+	}
+	if err := store.Append(t.Context(), transactions); err != nil {
+		t.Fatalf("failed to append transactions: %v", err)
+	}
+
+	report, err := SummarizePerformance(store.All(t.Context()))
+	if err != nil {
+		t.Fatalf("failed to summarize: %v", err)
+	}
+	want := PerformanceStats{
+		Trades: 3, Wins: 1, Losses: 2, WinRate: 1.0 / 3,
+		GrossProfit: "3.0000", GrossLoss: "3.0000", NetPL: "0.0000",
+		AverageWin: "3.0000", AverageLoss: "1.5000", ProfitFactor: 1, Expectancy: "0.0000",
+		MaxConsecutiveWins: 1, MaxConsecutiveLosses: 2,
+		AverageHoldingTime: 3 * time.Hour, MaxHoldingTime: 4 * time.Hour,
+	}
+	if report.Total != want {
+		t.Errorf("got total %+v, want %+v", report.Total, want)
+	}
+	if got := report.ByInstrument["USD_JPY"]; got.Trades != 1 || got.AverageLoss != "1.00" || got.AverageHoldingTime != 0 || got.ProfitFactor != 0 {
+		t.Errorf("got USD_JPY %+v, want 1 loss of 1.00 without holding time", got)
+	}
+	if got := report.ByTag["trend"]; got.Wins != 1 || got.NetPL != "3.0000" || got.MaxHoldingTime != 2*time.Hour {
+		t.Errorf("got tag trend %+v, want 1 win of 3.0000 held for 2h", got)
+	}
+	if got := report.ByTag["scalp"]; got.Losses != 1 || got.Expectancy != "-2.0000" {
+		t.Errorf("got tag scalp %+v, want 1 loss of 2.0000", got)
+	}
+	if got := report.ByTag[""]; got.Trades != 1 {
+		t.Errorf("got untagged %+v, want 1 trade", got)
+	}
+}
@@ -0,0 +1,125 @@
+package oanda
+
+import "errors"
+
+// OrderPolicy is a house risk policy applied by a [Client] to every Order it creates or
+// replaces, configured with [WithOrderPolicy]. Defaults only replace the values the request
+// constructors start with (e.g. FOK for [NewMarketOrderRequest], GTC for [NewLimitOrderRequest],
+// DEFAULT for the position fill and trigger condition), and never values set with the request
+// setters, so that e.g. SetGTC or SetPositionFill(OrderPositionFillDefault) keeps the default
+// against the policy. Requests created with [NewReplaceOrderRequest] keep the settings of the
+// Order they replace. Zero fields leave requests unchanged.
+//
+// The policy applies to Market, Limit, Stop and Market If Touched Orders. Orders attached to
+// Trades, such as Take Profit Orders, are sent as they are.
+type OrderPolicy struct {
+	// MarketTimeInForce is the default TimeInForce of Market Orders: FOK or IOC.
+	MarketTimeInForce TimeInForce
+	// TimeInForce is the default TimeInForce of Limit, Stop and Market If Touched Orders. GTD
+	// cannot be used as a default, as it needs a date.
+	TimeInForce TimeInForce
+	// PositionFill is the default position fill.
+	PositionFill OrderPositionFill
+	// TriggerCondition is the default trigger condition of Limit, Stop and Market If Touched
+	// Orders.
+	TriggerCondition OrderTriggerCondition
+	// StopLossOnFill is the Stop Loss attached to Orders that open Trades without a Stop Loss,
+	// Guaranteed Stop Loss or Trailing Stop Loss on fill. It should specify a distance rather
+	// than a price to suit every Order.
+	StopLossOnFill *StopLossDetails
+	// RequireStopLossOnFill makes the Client refuse Orders that may open a Trade without a Stop
+	// Loss, Guaranteed Stop Loss or Trailing Stop Loss on fill. Orders with the REDUCE_ONLY
+	// position fill and Market Orders that close a Trade or Position are exempt.
+	RequireStopLossOnFill bool
+}
+
+// WithOrderPolicy makes a [Client] apply policy to the Orders it creates and replaces. It has no
+// effect on a [StreamClient].
+func WithOrderPolicy(policy OrderPolicy) Option {
+	return func(c *clientConfig) {
+		c.orderPolicy = &policy
+	}
+}
+
+// ErrStopLossRequired is returned for Orders refused by an [OrderPolicy] with
+// RequireStopLossOnFill set.
+var ErrStopLossRequired = errors.New("order policy requires a stop loss on fill")
+
+// orderFields is a set of Order request fields that an OrderPolicy may default.
+type orderFields uint8
+
+const (
+	orderFieldTimeInForce orderFields = 1 << iota
+	orderFieldPositionFill
+	orderFieldTriggerCondition
+	orderFieldStopLossOnFill
+
+	// orderFieldsAll is the set of all the fields, which the requests replacing an Order keep.
+	orderFieldsAll = orderFieldTimeInForce | orderFieldPositionFill | orderFieldTriggerCondition | orderFieldStopLossOnFill
+)
+
+// apply applies the policy to req. It is a no-op on a nil policy.
+func (p *OrderPolicy) apply(req OrderRequest) error {
+	if p == nil {
+		return nil
+	}
+	switch r := req.(type) {
+	case *MarketOrderRequest:
+		if p.MarketTimeInForce != "" && r.TimeInForce == TimeInForceFOK && r.explicit&orderFieldTimeInForce == 0 {
+			r.TimeInForce = p.MarketTimeInForce
+		}
+		p.applyPositionFill(&r.PositionFill, r.explicit)
+		if r.TradeClose != nil || r.LongPositionCloseout != nil || r.ShortPositionCloseout != nil {
+			return nil
+		}
+		return p.applyStopLoss(r.PositionFill, &r.StopLossOnFill,
+			r.GuaranteedStopLossOnFill != nil || r.TrailingStopLossOnFill != nil, r.explicit)
+	case *LimitOrderRequest:
+		p.applyPending(&r.TimeInForce, &r.PositionFill, &r.TriggerCondition, r.explicit)
+		return p.applyStopLoss(r.PositionFill, &r.StopLossOnFill,
+			r.GuaranteedStopLossOnFill != nil || r.TrailingStopLossOnFill != nil, r.explicit)
+	case *StopOrderRequest:
+		p.applyPending(&r.TimeInForce, &r.PositionFill, &r.TriggerCondition, r.explicit)
+		return p.applyStopLoss(r.PositionFill, &r.StopLossOnFill,
+			r.GuaranteedStopLossOnFill != nil || r.TrailingStopLossOnFill != nil, r.explicit)
+	case *MarketIfTouchedOrderRequest:
+		p.applyPending(&r.TimeInForce, &r.PositionFill, &r.TriggerCondition, r.explicit)
+		return p.applyStopLoss(r.PositionFill, &r.StopLossOnFill,
+			r.GuaranteedStopLossOnFill != nil || r.TrailingStopLossOnFill != nil, r.explicit)
+	}
+	return nil
+}
+
+// applyPending applies the defaults of pending Orders to the fields not in explicit.
+func (p *OrderPolicy) applyPending(timeInForce *TimeInForce, positionFill *OrderPositionFill, triggerCondition *OrderTriggerCondition, explicit orderFields) {
+	if p.TimeInForce != "" && *timeInForce == TimeInForceGTC && explicit&orderFieldTimeInForce == 0 {
+		*timeInForce = p.TimeInForce
+	}
+	p.applyPositionFill(positionFill, explicit)
+	if p.TriggerCondition != "" && *triggerCondition == OrderTriggerConditionDefault && explicit&orderFieldTriggerCondition == 0 {
+		*triggerCondition = p.TriggerCondition
+	}
+}
+
+func (p *OrderPolicy) applyPositionFill(positionFill *OrderPositionFill, explicit orderFields) {
+	if p.PositionFill != "" && *positionFill == OrderPositionFillDefault && explicit&orderFieldPositionFill == 0 {
+		*positionFill = p.PositionFill
+	}
+}
+
+// applyStopLoss attaches the default Stop Loss on fill if the Order has no protection and its
+// Stop Loss on fill is not in explicit, and enforces RequireStopLossOnFill.
+func (p *OrderPolicy) applyStopLoss(positionFill OrderPositionFill, stopLossOnFill **StopLossDetails, otherProtection bool, explicit orderFields) error {
+	if *stopLossOnFill != nil || otherProtection || positionFill == OrderPositionFillReduceOnly {
+		return nil
+	}
+	if p.StopLossOnFill != nil && explicit&orderFieldStopLossOnFill == 0 {
+		details := *p.StopLossOnFill
+		*stopLossOnFill = &details
+		return nil
+	}
+	if p.RequireStopLossOnFill {
+		return ErrStopLossRequired
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package oanda
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestOrderPolicy_Apply(t *testing.T) {
+	policy := &OrderPolicy{
+		MarketTimeInForce:     TimeInForceIOC,
+		TimeInForce:           TimeInForceGFD,
+		PositionFill:          OrderPositionFillReduceFirst,
+		TriggerCondition:      OrderTriggerConditionMid,
+		StopLossOnFill:        NewStopLossDetails().SetDistance("0.0050"),
+		RequireStopLossOnFill: true,
+	}
+
+	market := NewMarketOrderRequest("EUR_USD", "100")
+	if err := policy.apply(market); err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	if market.TimeInForce != TimeInForceIOC || market.PositionFill != OrderPositionFillReduceFirst {
+		t.Errorf("got %s and %s, want IOC and REDUCE_FIRST", market.TimeInForce, market.PositionFill)
+	}
+	if market.StopLossOnFill == nil || *market.StopLossOnFill.Distance != "0.0050" {
+		t.Errorf("got stop loss on fill %+v, want distance 0.0050", market.StopLossOnFill)
+	}
+
+	limit := NewLimitOrderRequest("EUR_USD", "100", "1.10000").
+		SetPositionFill(OrderPositionFillOpenOnly).
+		SetTrailingStopLossOnFill(NewTrailingStopLossDetails("0.0100"))
+	if err := policy.apply(limit); err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	if limit.TimeInForce != TimeInForceGFD || limit.TriggerCondition != OrderTriggerConditionMid {
+		t.Errorf("got %s and %s, want GFD and MID", limit.TimeInForce, limit.TriggerCondition)
+	}
+	if limit.PositionFill != OrderPositionFillOpenOnly {
+		t.Errorf("got position fill %s, want explicit OPEN_ONLY kept", limit.PositionFill)
+	}
+	if limit.StopLossOnFill != nil {
+		t.Errorf("got stop loss on fill %+v, want none next to a trailing stop loss", limit.StopLossOnFill)
+	}
+
+	explicitMarket := NewMarketOrderRequest("EUR_USD", "100").SetFOK().SetPositionFill(OrderPositionFillDefault)
+	if err := policy.apply(explicitMarket); err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	if explicitMarket.TimeInForce != TimeInForceFOK || explicitMarket.PositionFill != OrderPositionFillDefault {
+		t.Errorf("got %s and %s, want explicit FOK and DEFAULT kept", explicitMarket.TimeInForce, explicitMarket.PositionFill)
+	}
+
+	explicitStop := NewStopOrderRequest("EUR_USD", "100", "1.20000").
+		SetGTC().
+		SetPositionFill(OrderPositionFillDefault).
+		SetTriggerCondition(OrderTriggerConditionDefault)
+	if err := policy.apply(explicitStop); err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	if explicitStop.TimeInForce != TimeInForceGTC || explicitStop.PositionFill != OrderPositionFillDefault ||
+		explicitStop.TriggerCondition != OrderTriggerConditionDefault {
+		t.Errorf("got %s, %s and %s, want explicit GTC, DEFAULT and DEFAULT kept",
+			explicitStop.TimeInForce, explicitStop.PositionFill, explicitStop.TriggerCondition)
+	}
+
+	strict := &OrderPolicy{RequireStopLossOnFill: true}
+	if err := strict.apply(NewStopOrderRequest("EUR_USD", "100", "1.20000")); !errors.Is(err, ErrStopLossRequired) {
+		t.Errorf("got error %v, want ErrStopLossRequired", err)
+	}
+	if err := strict.apply(NewMarketOrderRequest("EUR_USD", "-100").CloseTrade("1", "ALL")); err != nil {
+		t.Errorf("got error %v for closing order", err)
+	}
+	if err := strict.apply(NewTakeProfitOrderRequest("1", "1.20000")); err != nil {
+		t.Errorf("got error %v for dependent order", err)
+	}
+}
@@ -153,7 +153,7 @@ func NewPositionCloseRequest() *PositionCloseRequest {
 
 // SetLongAll sets the request to close all units of the long side.
 func (r *PositionCloseRequest) SetLongAll() *PositionCloseRequest {
-	v := "ALL"
+	v := string(UnitsAll)
 	r.LongUnits = &v
 	return r
 }
@@ -165,6 +165,13 @@ func (r *PositionCloseRequest) SetLongUnits(units uint) *PositionCloseRequest {
 	return r
 }
 
+// SetLongNone sets the request to leave the long side open.
+func (r *PositionCloseRequest) SetLongNone() *PositionCloseRequest {
+	v := string(UnitsNone)
+	r.LongUnits = &v
+	return r
+}
+
 // SetLongClientExtensions sets the client extensions for the long side close.
 func (r *PositionCloseRequest) SetLongClientExtensions(extensions *ClientExtensions) *PositionCloseRequest {
 	r.LongClientExtensions = extensions
@@ -173,7 +180,7 @@ func (r *PositionCloseRequest) SetLongClientExtensions(extensions *ClientExtensi
 
 // SetShortAll sets the request to close all units of the short side.
 func (r *PositionCloseRequest) SetShortAll() *PositionCloseRequest {
-	v := "ALL"
+	v := string(UnitsAll)
 	r.ShortUnits = &v
 	return r
 }
@@ -185,6 +192,13 @@ func (r *PositionCloseRequest) SetShortUnits(units uint) *PositionCloseRequest {
 	return r
 }
 
+// SetShortNone sets the request to leave the short side open.
+func (r *PositionCloseRequest) SetShortNone() *PositionCloseRequest {
+	v := string(UnitsNone)
+	r.ShortUnits = &v
+	return r
+}
+
 // SetShortClientExtensions sets the client extensions for the short side close.
 func (r *PositionCloseRequest) SetShortClientExtensions(extensions *ClientExtensions) *PositionCloseRequest {
 	r.ShortClientExtensions = extensions
@@ -251,3 +265,170 @@ func (s *positionService) Close(ctx context.Context, instrument InstrumentName,
 		return nil, decodeErrorResponse(httpResp)
 	}
 }
+
+// PositionSideCloseResponse is the outcome of closing one side of a Position: the Market Order
+// created to close it, and the Transaction that filled or cancelled that Order.
+type PositionSideCloseResponse struct {
+	// OrderCreateTransaction is the Transaction that created the closing Market Order.
+	OrderCreateTransaction *MarketOrderTransaction
+	// OrderFillTransaction is the Transaction that filled the Market Order, if it was filled.
+	OrderFillTransaction *OrderFillTransaction
+	// OrderCancelTransaction is the Transaction that cancelled the Market Order, if it was
+	// cancelled.
+	OrderCancelTransaction *OrderCancelTransaction
+	// RelatedTransactionIDs are the IDs of all Transactions created by the request.
+	RelatedTransactionIDs []TransactionID
+	// LastTransactionID is the ID of the most recent Transaction created for the Account.
+	LastTransactionID TransactionID
+}
+
+// WasCancelled reports whether the closing Market Order was cancelled, and why.
+func (r *PositionSideCloseResponse) WasCancelled() (OrderCancelReason, bool) {
+	if r.OrderCancelTransaction == nil {
+		return "", false
+	}
+	return r.OrderCancelTransaction.Reason, true
+}
+
+// Long returns the outcome of closing the long side, or nil if no Order was created for it.
+func (r *PositionCloseResponse) Long() *PositionSideCloseResponse {
+	if r.LongOrderCreateTransaction == nil {
+		return nil
+	}
+	return &PositionSideCloseResponse{
+		OrderCreateTransaction: r.LongOrderCreateTransaction,
+		OrderFillTransaction:   r.LongOrderFillTransaction,
+		OrderCancelTransaction: r.LongOrderCancelTransaction,
+		RelatedTransactionIDs:  r.RelatedTransactionIDs,
+		LastTransactionID:      r.LastTransactionID,
+	}
+}
+
+// Short returns the outcome of closing the short side, or nil if no Order was created for it.
+func (r *PositionCloseResponse) Short() *PositionSideCloseResponse {
+	if r.ShortOrderCreateTransaction == nil {
+		return nil
+	}
+	return &PositionSideCloseResponse{
+		OrderCreateTransaction: r.ShortOrderCreateTransaction,
+		OrderFillTransaction:   r.ShortOrderFillTransaction,
+		OrderCancelTransaction: r.ShortOrderCancelTransaction,
+		RelatedTransactionIDs:  r.RelatedTransactionIDs,
+		LastTransactionID:      r.LastTransactionID,
+	}
+}
+
+// CloseLong closes units of the long side of the Position for instrument, or all of it if units
+// is [UnitsAll], and leaves the short side open. units must be positive.
+//
+// This corresponds to the OANDA API endpoint: PUT /v3/accounts/{accountID}/positions/{instrument}/close
+//
+// Reference: https://developer.oanda.com/rest-live-v20/position-ep/#collapse_endpoint_4
+func (s *positionService) CloseLong(ctx context.Context, instrument InstrumentName, units DecimalNumber) (*PositionSideCloseResponse, error) {
+	if err := validateCloseUnits("longUnits", units); err != nil {
+		return nil, err
+	}
+	req := NewPositionCloseRequest().SetShortNone()
+	req.LongUnits = (*string)(&units)
+	resp, err := s.Close(ctx, instrument, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Long() == nil {
+		return nil, fmt.Errorf("no order was created to close the long position of %s", instrument)
+	}
+	return resp.Long(), nil
+}
+
+// CloseShort closes units of the short side of the Position for instrument, or all of it if
+// units is [UnitsAll], and leaves the long side open. units must be positive, as the API expects.
+//
+// This corresponds to the OANDA API endpoint: PUT /v3/accounts/{accountID}/positions/{instrument}/close
+//
+// Reference: https://developer.oanda.com/rest-live-v20/position-ep/#collapse_endpoint_4
+func (s *positionService) CloseShort(ctx context.Context, instrument InstrumentName, units DecimalNumber) (*PositionSideCloseResponse, error) {
+	if err := validateCloseUnits("shortUnits", units); err != nil {
+		return nil, err
+	}
+	req := NewPositionCloseRequest().SetLongNone()
+	req.ShortUnits = (*string)(&units)
+	resp, err := s.Close(ctx, instrument, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Short() == nil {
+		return nil, fmt.Errorf("no order was created to close the short position of %s", instrument)
+	}
+	return resp.Short(), nil
+}
+
+// CloseAll closes units of each open side of the Position for instrument, or all of them if
+// units is [UnitsAll]. units must be positive. The Position is fetched first, and only its open
+// sides are closed, as the API rejects closing a side that has no units; use
+// [PositionCloseResponse.Long] and [PositionCloseResponse.Short] for the outcome of each side.
+//
+// This corresponds to the OANDA API endpoints: GET /v3/accounts/{accountID}/positions/{instrument}
+// and PUT /v3/accounts/{accountID}/positions/{instrument}/close
+//
+// Reference: https://developer.oanda.com/rest-live-v20/position-ep/#collapse_endpoint_4
+func (s *positionService) CloseAll(ctx context.Context, instrument InstrumentName, units DecimalNumber) (*PositionCloseResponse, error) {
+	if err := validateCloseUnits("units", units); err != nil {
+		return nil, err
+	}
+	resp, err := s.ListByInstrument(ctx, instrument)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get position %s: %w", instrument, err)
+	}
+	req, err := newPositionCloseAllRequest(resp.Position, units)
+	if err != nil {
+		return nil, err
+	}
+	return s.Close(ctx, instrument, req)
+}
+
+// newPositionCloseAllRequest returns a request that closes units of each open side of position.
+func newPositionCloseAllRequest(position Position, units DecimalNumber) (*PositionCloseRequest, error) {
+	req := NewPositionCloseRequest().SetLongNone().SetShortNone()
+	open := false
+	for _, side := range []struct {
+		units DecimalNumber
+		close **string
+	}{
+		{position.Long.Units, &req.LongUnits},
+		{position.Short.Units, &req.ShortUnits},
+	} {
+		if side.units == "" {
+			continue
+		}
+		u, err := side.units.Rat()
+		if err != nil {
+			return nil, fmt.Errorf("invalid units of position %s: %w", position.Instrument, err)
+		}
+		if u.Sign() != 0 {
+			*side.close = (*string)(&units)
+			open = true
+		}
+	}
+	if !open {
+		return nil, fmt.Errorf("position %s has no open units", position.Instrument)
+	}
+	return req, nil
+}
+
+// validateCloseUnits checks that units are ALL or a positive number.
+func validateCloseUnits(field string, units DecimalNumber) error {
+	if err := validateUnits(field, units, UnitsAll); err != nil {
+		return err
+	}
+	if units == UnitsAll {
+		return nil
+	}
+	u, err := units.Rat()
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", field, err)
+	}
+	if u.Sign() <= 0 {
+		return fmt.Errorf("%s %s must be positive", field, units)
+	}
+	return nil
+}
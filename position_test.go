@@ -1,6 +1,13 @@
 package oanda
 
-import "testing"
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
 
 func TestPositionService(t *testing.T) {
 	client := setupClient(t)
@@ -47,3 +54,74 @@ func TestPositionService(t *testing.T) {
 		debugResponse(resp)
 	})
 }
+
+func TestPositionService_CloseSides(t *testing.T) {
+	var closes []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v3/accounts/1/positions/EUR_USD", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `{"position":{"instrument":"EUR_USD","long":{"units":"0"},"short":{"units":"-300"}}}`)
+	})
+	mux.HandleFunc("PUT /v3/accounts/1/positions/EUR_USD/close", func(w http.ResponseWriter, r *http.Request) {
+		var req PositionCloseRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.LongUnits == nil || req.ShortUnits == nil {
+			t.Errorf("got request %+v (%v)", req, err)
+			return
+		}
+		closes = append(closes, *req.LongUnits+"/"+*req.ShortUnits)
+		var resp strings.Builder
+		resp.WriteString(`{"relatedTransactionIDs":["5","6"],"lastTransactionID":"6"`)
+		if *req.LongUnits != string(UnitsNone) {
+			resp.WriteString(`,"longOrderCreateTransaction":{"type":"MARKET_ORDER","id":"5"},
+				"longOrderFillTransaction":{"type":"ORDER_FILL","id":"6","orderID":"5"}`)
+		}
+		if *req.ShortUnits != string(UnitsNone) {
+			resp.WriteString(`,"shortOrderCreateTransaction":{"type":"MARKET_ORDER","id":"5"},
+				"shortOrderCancelTransaction":{"type":"ORDER_CANCEL","id":"6","orderID":"5","reason":"MARKET_HALTED"}`)
+		}
+		resp.WriteString("}")
+		_, _ = fmt.Fprint(w, resp.String())
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client := NewClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+
+	long, err := client.Position.CloseLong(t.Context(), "EUR_USD", "100")
+	if err != nil {
+		t.Fatalf("failed to close long: %v", err)
+	}
+	if long.OrderFillTransaction == nil || long.OrderFillTransaction.OrderID != "5" || long.LastTransactionID != "6" {
+		t.Errorf("got long close %+v", long)
+	}
+	short, err := client.Position.CloseShort(t.Context(), "EUR_USD", UnitsAll)
+	if err != nil {
+		t.Fatalf("failed to close short: %v", err)
+	}
+	if reason, ok := short.WasCancelled(); !ok || reason != "MARKET_HALTED" {
+		t.Errorf("got cancel reason %q %v", reason, ok)
+	}
+	all, err := client.Position.CloseAll(t.Context(), "EUR_USD", UnitsAll)
+	if err != nil {
+		t.Fatalf("failed to close all: %v", err)
+	}
+	if all.Long() != nil || all.Short() == nil {
+		t.Errorf("got close all %+v", all)
+	}
+	if _, err := client.Position.CloseAll(t.Context(), "EUR_USD", "200"); err != nil {
+		t.Fatalf("failed to close 200 units: %v", err)
+	}
+	if got := strings.Join(closes, ","); got != "100/NONE,NONE/ALL,NONE/ALL,NONE/200" {
+		t.Errorf("got closes %s, want 100/NONE,NONE/ALL,NONE/ALL,NONE/200", got)
+	}
+	if _, err := client.Position.CloseAll(t.Context(), "EUR_USD", "-200"); err == nil {
+		t.Error("got no error closing -200 units")
+	}
+
+	for _, units := range []DecimalNumber{"-100", "0", UnitsNone, "abc"} {
+		if _, err := client.Position.CloseLong(t.Context(), "EUR_USD", units); err == nil {
+			t.Errorf("got no error closing %s long units", units)
+		}
+	}
+	if _, err := newPositionCloseAllRequest(Position{Instrument: "EUR_USD", Long: PositionSide{Units: "0"}, Short: PositionSide{Units: "0"}}, UnitsAll); err == nil {
+		t.Error("got no error closing a flat position")
+	}
+}
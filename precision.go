@@ -0,0 +1,112 @@
+package oanda
+
+import (
+	"context"
+	"math/big"
+	"strconv"
+)
+
+// InstrumentPrecision is the precision of an Instrument's prices and units, from the Instrument
+// metadata of the Account. Get one with [Instrument.Precision] or [InstrumentCache.Precision].
+type InstrumentPrecision struct {
+	// Instrument is the name of the Instrument.
+	Instrument InstrumentName
+	// PipLocation is the location of the pip: a pip is 10 to the power of PipLocation, such as
+	// 0.0001 for -4.
+	PipLocation int
+	// DisplayPrecision is the number of decimal places of the Instrument's prices.
+	DisplayPrecision int
+	// TradeUnitsPrecision is the number of decimal places allowed in units.
+	TradeUnitsPrecision int
+}
+
+// Precision returns the precision of the Instrument's prices and units.
+func (i Instrument) Precision() InstrumentPrecision {
+	return InstrumentPrecision{
+		Instrument:          i.Name,
+		PipLocation:         i.PipLocation,
+		DisplayPrecision:    i.DisplayPrecision,
+		TradeUnitsPrecision: i.TradeUnitsPrecision,
+	}
+}
+
+// Precision returns the precision of the named Instrument, fetching the metadata of all
+// Instruments if it has not been fetched yet.
+func (c *InstrumentCache) Precision(ctx context.Context, name InstrumentName) (InstrumentPrecision, error) {
+	instrument, err := c.Get(ctx, name)
+	if err != nil {
+		return InstrumentPrecision{}, err
+	}
+	return instrument.Precision(), nil
+}
+
+// PipSize returns the size of a pip, such as 0.0001 for EUR_USD.
+func (p InstrumentPrecision) PipSize() DecimalNumber {
+	return DecimalNumber(p.pip().FloatString(max(-p.PipLocation, 0)))
+}
+
+// RoundPrice rounds price to the nearest price with DisplayPrecision decimal places, rounding
+// halves away from zero. The rounding is exact, unlike that of [Price].
+func (p InstrumentPrecision) RoundPrice(price PriceValue) (PriceValue, error) {
+	r, err := price.Rat()
+	if err != nil {
+		return "", err
+	}
+	return PriceValue(r.FloatString(p.DisplayPrecision)), nil
+}
+
+// RoundUnits truncates units toward zero to TradeUnitsPrecision decimal places, so that an Order
+// is never larger than requested.
+func (p InstrumentPrecision) RoundUnits(units DecimalNumber) (DecimalNumber, error) {
+	r, err := units.Rat()
+	if err != nil {
+		return "", err
+	}
+	return DecimalNumber(truncateDecimal(r, p.TradeUnitsPrecision)), nil
+}
+
+// PipsBetween returns the number of pips from price a to price b, which is negative if b is
+// below a.
+func (p InstrumentPrecision) PipsBetween(a, b PriceValue) (float64, error) {
+	from, err := a.Rat()
+	if err != nil {
+		return 0, err
+	}
+	to, err := b.Rat()
+	if err != nil {
+		return 0, err
+	}
+	pips, _ := new(big.Rat).Quo(to.Sub(to, from), p.pip()).Float64()
+	return pips, nil
+}
+
+// AddPips returns price moved by pips, which may be negative or fractional, rounded to
+// DisplayPrecision decimal places.
+func (p InstrumentPrecision) AddPips(price PriceValue, pips float64) (PriceValue, error) {
+	r, err := price.Rat()
+	if err != nil {
+		return "", err
+	}
+	offset, err := parseRat(strconv.FormatFloat(pips, 'f', -1, 64))
+	if err != nil {
+		return "", err
+	}
+	r.Add(r, offset.Mul(offset, p.pip()))
+	return PriceValue(r.FloatString(p.DisplayPrecision)), nil
+}
+
+// pip returns 10 to the power of PipLocation.
+func (p InstrumentPrecision) pip() *big.Rat {
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(absInt(p.PipLocation))), nil)
+	if p.PipLocation < 0 {
+		return new(big.Rat).SetFrac(big.NewInt(1), scale)
+	}
+	return new(big.Rat).SetInt(scale)
+}
+
+func absInt(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
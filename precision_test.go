@@ -0,0 +1,58 @@
+package oanda
+
+import (
+	"math"
+	"testing"
+)
+
+func TestInstrumentPrecision(t *testing.T) {
+	server := newInstrumentServer(t)
+	defer server.Close()
+	client := NewClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+	cache := NewInstrumentCache(client)
+
+	p, err := cache.Precision(t.Context(), "EUR_USD")
+	if err != nil {
+		t.Fatalf("failed to get precision: %v", err)
+	}
+	if want := (InstrumentPrecision{Instrument: "EUR_USD", PipLocation: -4, DisplayPrecision: 5}); p != want {
+		t.Errorf("got %+v, want %+v", p, want)
+	}
+	if _, err := cache.Precision(t.Context(), "XAU_XAG"); err == nil {
+		t.Error("got no error for an unknown instrument")
+	}
+
+	if got := p.PipSize(); got != "0.0001" {
+		t.Errorf("got pip size %s, want 0.0001", got)
+	}
+	for price, want := range map[PriceValue]PriceValue{"1.123455": "1.12346", "1.1": "1.10000", "-1.123455": "-1.12346"} {
+		if got, err := p.RoundPrice(price); err != nil || got != want {
+			t.Errorf("%s: got price %s (%v), want %s", price, got, err, want)
+		}
+	}
+	for units, want := range map[DecimalNumber]DecimalNumber{"100.9": "100", "-100.9": "-100", "5": "5"} {
+		if got, err := p.RoundUnits(units); err != nil || got != want {
+			t.Errorf("%s: got units %s (%v), want %s", units, got, err, want)
+		}
+	}
+	if pips, err := p.PipsBetween("1.10000", "1.09875"); err != nil || math.Abs(pips+12.5) > 1e-9 {
+		t.Errorf("got %v pips (%v), want -12.5", pips, err)
+	}
+	if got, err := p.AddPips("1.10000", 12.5); err != nil || got != "1.10125" {
+		t.Errorf("got %s (%v), want 1.10125", got, err)
+	}
+	if _, err := p.RoundPrice("abc"); err == nil {
+		t.Error("got no error for an invalid price")
+	}
+
+	jpy := Instrument{Name: "USD_JPY", PipLocation: -2, DisplayPrecision: 3, TradeUnitsPrecision: 0}.Precision()
+	if got := jpy.PipSize(); got != "0.01" {
+		t.Errorf("got pip size %s, want 0.01", got)
+	}
+	if pips, err := jpy.PipsBetween("150.000", "150.255"); err != nil || math.Abs(pips-25.5) > 1e-9 {
+		t.Errorf("got %v pips (%v), want 25.5", pips, err)
+	}
+	if got := (InstrumentPrecision{PipLocation: 1}).PipSize(); got != "10" {
+		t.Errorf("got pip size %s, want 10", got)
+	}
+}
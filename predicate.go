@@ -0,0 +1,189 @@
+package oanda
+
+import (
+	"iter"
+	"reflect"
+	"slices"
+	"time"
+)
+
+// TransactionPredicate reports whether a Transaction matches a condition. Predicates are built
+// with constructors such as [TransactionsOfType] and [TransactionsOfInstrument], combined with
+// [AllOf], [AnyOf] and [TransactionPredicate.Not], and applied either to an iterator of
+// Transactions with [TransactionPredicate.Filter], such as synced history from
+// [TransactionStore.All] or [StreamClient.TransactionFeed], or to the items of a Transaction
+// stream, which are Transactions too. Both the pointers returned by the REST endpoints and the
+// values delivered by the Transaction stream are supported.
+type TransactionPredicate func(Transaction) bool
+
+// TransactionsOfType matches the Transactions of any of the given types.
+func TransactionsOfType(types ...TransactionType) TransactionPredicate {
+	return func(t Transaction) bool {
+		return slices.Contains(types, t.GetType())
+	}
+}
+
+// TransactionsOfInstrument matches the Transactions of any of the given Instruments: those with
+// an Instrument field, such as Order and fill Transactions, and DailyFinancingTransactions that
+// finance a Position in one of them.
+func TransactionsOfInstrument(instruments ...InstrumentName) TransactionPredicate {
+	return func(t Transaction) bool {
+		switch t := t.(type) {
+		case DailyFinancingTransaction:
+			return financesAny(&t, instruments)
+		case *DailyFinancingTransaction:
+			return financesAny(t, instruments)
+		}
+		v := transactionStruct(t)
+		if !v.IsValid() {
+			return false
+		}
+		f := v.FieldByName("Instrument")
+		return f.IsValid() && f.Kind() == reflect.String && slices.Contains(instruments, f.String())
+	}
+}
+
+func financesAny(t *DailyFinancingTransaction, instruments []InstrumentName) bool {
+	for _, financing := range t.PositionFinancings {
+		if slices.Contains(instruments, financing.Instrument) {
+			return true
+		}
+	}
+	return false
+}
+
+// TransactionsWithTag matches the Transactions that carry any of the given client tags in the
+// client extensions of the Order or Trade they create or modify, or of the Trade opened by a
+// fill.
+func TransactionsWithTag(tags ...ClientTag) TransactionPredicate {
+	return func(t Transaction) bool {
+		for _, extensions := range transactionClientExtensions(t) {
+			if extensions.Tag != nil && slices.Contains(tags, *extensions.Tag) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// TransactionsAfter matches the Transactions created after t.
+func TransactionsAfter(t time.Time) TransactionPredicate {
+	return func(transaction Transaction) bool {
+		created := transaction.GetTime()
+		return created.Time != nil && created.After(t)
+	}
+}
+
+// TransactionsBefore matches the Transactions created before t.
+func TransactionsBefore(t time.Time) TransactionPredicate {
+	return func(transaction Transaction) bool {
+		created := transaction.GetTime()
+		return created.Time != nil && created.Before(t)
+	}
+}
+
+// TransactionsAfterID matches the Transactions with an ID after id, in the order of
+// [CompareTransactionIDs].
+func TransactionsAfterID(id TransactionID) TransactionPredicate {
+	return func(t Transaction) bool {
+		return t.GetID() != "" && CompareTransactionIDs(t.GetID(), id) > 0
+	}
+}
+
+// AllOf matches the Transactions that match every one of predicates. With no predicates, it
+// matches every Transaction.
+func AllOf(predicates ...TransactionPredicate) TransactionPredicate {
+	return func(t Transaction) bool {
+		for _, p := range predicates {
+			if !p(t) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// AnyOf matches the Transactions that match at least one of predicates. With no predicates, it
+// matches no Transaction.
+func AnyOf(predicates ...TransactionPredicate) TransactionPredicate {
+	return func(t Transaction) bool {
+		for _, p := range predicates {
+			if p(t) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not matches the Transactions that p does not match.
+func (p TransactionPredicate) Not() TransactionPredicate {
+	return func(t Transaction) bool {
+		return !p(t)
+	}
+}
+
+// Filter returns an iterator over the Transactions of transactions that match p. Errors are
+// passed through.
+func (p TransactionPredicate) Filter(transactions iter.Seq2[Transaction, error]) iter.Seq2[Transaction, error] {
+	return func(yield func(Transaction, error) bool) {
+		for transaction, err := range transactions {
+			if err != nil {
+				if !yield(nil, err) {
+					return
+				}
+				continue
+			}
+			if p(transaction) && !yield(transaction, nil) {
+				return
+			}
+		}
+	}
+}
+
+// transactionStruct returns the struct value of t, or an invalid value if t is not a struct or a
+// pointer to one.
+func transactionStruct(t Transaction) reflect.Value {
+	v := reflect.ValueOf(t)
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}
+	}
+	return v
+}
+
+// transactionClientExtensions returns the client extensions carried by t. The many Order
+// Transaction types declare them in fields with the same names, which are found by reflection.
+func transactionClientExtensions(t Transaction) []*ClientExtensions {
+	var extensions []*ClientExtensions
+	switch t := t.(type) {
+	case OrderFillTransaction:
+		if t.TradeOpened != nil {
+			extensions = append(extensions, t.TradeOpened.ClientExtensions)
+		}
+	case *OrderFillTransaction:
+		if t.TradeOpened != nil {
+			extensions = append(extensions, t.TradeOpened.ClientExtensions)
+		}
+	}
+	v := transactionStruct(t)
+	if !v.IsValid() {
+		return extensions
+	}
+	for _, name := range []string{"ClientExtensions", "TradeClientExtensions", "ClientExtensionsModify", "TradeClientExtensionsModify"} {
+		switch f := v.FieldByName(name); {
+		case !f.IsValid():
+		case f.Type() == reflect.TypeFor[*ClientExtensions]():
+			extensions = append(extensions, f.Interface().(*ClientExtensions))
+		case f.Type() == reflect.TypeFor[ClientExtensions]():
+			e := f.Interface().(ClientExtensions)
+			extensions = append(extensions, &e)
+		}
+	}
+	return slices.DeleteFunc(extensions, func(e *ClientExtensions) bool { return e == nil })
+}
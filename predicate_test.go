@@ -0,0 +1,64 @@
+package oanda
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTransactionPredicate(t *testing.T) {
+	store := NewMemoryTransactionStore()
+	var transactions []Transaction
+	for _, line := range []string{
+		`{"type":"MARKET_ORDER","id":"1","time":"2024-01-01T00:00:00Z","instrument":"EUR_USD","units":"10","tradeClientExtensions":{"tag":"scalp"}}`,
+		`{"type":"ORDER_FILL","id":"2","time":"2024-01-01T00:00:00Z","instrument":"EUR_USD","units":"10","tradeOpened":{"tradeID":"2","units":"10","clientExtensions":{"tag":"scalp"}}}`,
+		`{"type":"TAKE_PROFIT_ORDER","id":"3","time":"2024-01-02T00:00:00Z","tradeID":"2","price":"1.2","clientExtensions":{"tag":"exit"}}`,
+		`{"type":"DAILY_FINANCING","id":"4","time":"2024-01-03T00:00:00Z","positionFinancings":[{"instrument":"EUR_USD","financing":"-0.01"}]}`,
+		`{"type":"TRADE_CLIENT_EXTENSIONS_MODIFY","id":"5","time":"2024-01-03T00:00:00Z","tradeID":"2","tradeClientExtensionsModify":{"tag":"swing"}}`,
+		`{"type":"LIMIT_ORDER","id":"6","time":"2024-01-04T00:00:00Z","instrument":"USD_JPY","units":"10","price":"150"}`,
+	} {
+		transaction, err := unmarshalTransaction([]byte(line))
+		if err != nil {
+			t.Fatalf("failed to decode transaction: %v", err)
+		}
+		transactions = append(transactions, transaction)
+	}
+	if err := store.Append(t.Context(), transactions); err != nil {
+		t.Fatalf("failed to append transactions: %v", err)
+	}
+
+	for _, test := range []struct {
+		name      string
+		predicate TransactionPredicate
+		want      string
+	}{
+		{"type", TransactionsOfType(TransactionTypeOrderFill, TransactionTypeLimitOrder), "2,6"},
+		{"instrument", TransactionsOfInstrument("EUR_USD"), "1,2,4"},
+		{"tag", TransactionsWithTag("scalp", "swing"), "1,2,5"},
+		{"time", AllOf(TransactionsAfter(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)), TransactionsBefore(time.Date(2024, 1, 4, 0, 0, 0, 0, time.UTC))), "3,4,5"},
+		{"id", TransactionsAfterID("4"), "5,6"},
+		{"any", AnyOf(TransactionsWithTag("exit"), TransactionsOfInstrument("USD_JPY")), "3,6"},
+		{"not", TransactionsOfInstrument("EUR_USD").Not(), "3,5,6"},
+		{"none", AnyOf(), ""},
+	} {
+		var ids []TransactionID
+		for transaction, err := range test.predicate.Filter(store.All(t.Context())) {
+			if err != nil {
+				t.Fatalf("%s: got error: %v", test.name, err)
+			}
+			ids = append(ids, transaction.GetID())
+		}
+		if got := strings.Join(ids, ","); got != test.want {
+			t.Errorf("%s: got %s, want %s", test.name, got, test.want)
+		}
+	}
+
+	// Stream items are values rather than pointers.
+	item, _, err := parseTransactionStreamItem([]byte(`{"type":"ORDER_FILL","id":"7","instrument":"EUR_USD","tradeOpened":{"tradeID":"7","units":"1","clientExtensions":{"tag":"scalp"}}}`))
+	if err != nil {
+		t.Fatalf("failed to parse stream item: %v", err)
+	}
+	if !AllOf(TransactionsOfInstrument("EUR_USD"), TransactionsWithTag("scalp"))(item) {
+		t.Error("got no match for a stream item")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math/big"
 	"net/url"
 	"strconv"
 	"strings"
@@ -17,8 +18,12 @@ import (
 
 // ClientPrice represents the price available for an Account at a given time.
 type ClientPrice struct {
-	Type string   `json:"type"`
-	Time DateTime `json:"time"`
+	Type string `json:"type"`
+	// Instrument is the Price's Instrument.
+	Instrument InstrumentName `json:"instrument"`
+	Time       DateTime       `json:"time"`
+	// Tradeable is a flag indicating if the Price is tradeable or not.
+	Tradeable bool `json:"tradeable"`
 	// Bids are the bid prices available.
 	Bids []PriceBucket `json:"bids"`
 	// Asks are the ask prices available.
@@ -27,6 +32,15 @@ type ClientPrice struct {
 	CloseoutBid PriceValue `json:"closeoutBid"`
 	// CloseoutAsk is the closeout ask price.
 	CloseoutAsk PriceValue `json:"closeoutAsk"`
+	// QuoteHomeConversionFactors are the factors converting the Instrument's quote currency
+	// into the Account's home currency, if provided. Prefer
+	// [PriceInformationResponse.QuoteHomeFactors], which also uses the home conversions of the
+	// response.
+	QuoteHomeConversionFactors *QuoteHomeConversionFactors `json:"quoteHomeConversionFactors,omitempty"`
+	// UnitsAvailable is the number of units available to trade at the price, only set if the
+	// prices were requested with [PriceInformationRequest.SetIncludeUnitsAvailable].
+	UnitsAvailable *UnitsAvailable `json:"unitsAvailable,omitempty"`
+	Received
 }
 
 // GetType returns the price type string.
@@ -39,6 +53,99 @@ func (p ClientPrice) GetTime() DateTime {
 	return p.Time
 }
 
+// BestBid returns the bid bucket with the highest price, and false if there are no valid bids.
+func (p ClientPrice) BestBid() (PriceBucket, bool) {
+	return bestBucket(p.Bids, 1)
+}
+
+// BestAsk returns the ask bucket with the lowest price, and false if there are no valid asks.
+func (p ClientPrice) BestAsk() (PriceBucket, bool) {
+	return bestBucket(p.Asks, -1)
+}
+
+// Mid returns the midpoint of the best bid and the best ask, with as many decimal places as the
+// more precise of them.
+func (p ClientPrice) Mid() (PriceValue, error) {
+	bid, ask, err := p.top()
+	if err != nil {
+		return "", err
+	}
+	return midPrice(bid.Price, ask.Price)
+}
+
+// Spread returns the best ask minus the best bid.
+func (p ClientPrice) Spread() (PriceValue, error) {
+	bid, ask, err := p.top()
+	if err != nil {
+		return "", err
+	}
+	b, _ := bid.Price.Rat()
+	a, _ := ask.Price.Rat()
+	digits := max(fractionDigits(string(bid.Price)), fractionDigits(string(ask.Price)))
+	return PriceValue(a.Sub(a, b).FloatString(digits)), nil
+}
+
+// SpreadPips returns the spread in pips of the Instrument with precision, such as 1.2 for a
+// EUR_USD spread of 0.00012.
+func (p ClientPrice) SpreadPips(precision InstrumentPrecision) (float64, error) {
+	bid, ask, err := p.top()
+	if err != nil {
+		return 0, err
+	}
+	return precision.PipsBetween(bid.Price, ask.Price)
+}
+
+// TopLiquidity returns the liquidity available at the best bid and the best ask, which is 0 for
+// a side without prices.
+func (p ClientPrice) TopLiquidity() (bid, ask int) {
+	if b, ok := p.BestBid(); ok {
+		bid = b.Liquidity
+	}
+	if a, ok := p.BestAsk(); ok {
+		ask = a.Liquidity
+	}
+	return bid, ask
+}
+
+// ClampUnits limits units to the units available to an Order with the given positionFill, as
+// [UnitsAvailable.Clamp]. It returns an error if the price has no UnitsAvailable.
+func (p ClientPrice) ClampUnits(units DecimalNumber, positionFill OrderPositionFill) (DecimalNumber, error) {
+	if p.UnitsAvailable == nil {
+		return "", fmt.Errorf("no units available for %s", p.Instrument)
+	}
+	return p.UnitsAvailable.Clamp(units, positionFill)
+}
+
+// top returns the best bid and the best ask, or an error if either side has no valid price.
+func (p ClientPrice) top() (bid, ask PriceBucket, err error) {
+	bid, ok := p.BestBid()
+	if !ok {
+		return bid, ask, fmt.Errorf("no bids for %s", p.Instrument)
+	}
+	ask, ok = p.BestAsk()
+	if !ok {
+		return bid, ask, fmt.Errorf("no asks for %s", p.Instrument)
+	}
+	return bid, ask, nil
+}
+
+// bestBucket returns the bucket of buckets with the highest price if sign is 1, or the lowest if
+// sign is -1. Buckets with prices that cannot be parsed are skipped.
+func bestBucket(buckets []PriceBucket, sign int) (PriceBucket, bool) {
+	var best PriceBucket
+	var bestPrice *big.Rat
+	for _, bucket := range buckets {
+		price, err := bucket.Price.Rat()
+		if err != nil {
+			continue
+		}
+		if bestPrice == nil || price.Cmp(bestPrice) == sign {
+			best, bestPrice = bucket, price
+		}
+	}
+	return best, bestPrice != nil
+}
+
 // PriceStatus represents the status of the Price.
 type PriceStatus string
 
@@ -62,6 +169,128 @@ type QuoteHomeConversionFactors struct {
 	NegativeUnits DecimalNumber `json:"negativeUnits"`
 }
 
+// QuoteHome returns the factors as QuoteHomeFactors.
+func (f QuoteHomeConversionFactors) QuoteHome() (QuoteHomeFactors, error) {
+	return newQuoteHomeFactors(f.PositiveUnits, f.NegativeUnits)
+}
+
+// QuoteHomeFactors are the factors converting amounts of an Instrument's quote currency into the
+// Account's home currency: Gain converts profits and Loss converts losses. They are read from
+// prices with [PriceInformationResponse.QuoteHomeFactors] and from fills with
+// [HomeConversionFactors.QuoteHome].
+type QuoteHomeFactors struct {
+	Gain float64
+	Loss float64
+}
+
+func newQuoteHomeFactors(gain, loss DecimalNumber) (QuoteHomeFactors, error) {
+	g, err := gain.Float64()
+	if err != nil {
+		return QuoteHomeFactors{}, fmt.Errorf("invalid gain conversion factor: %w", err)
+	}
+	l, err := loss.Float64()
+	if err != nil {
+		return QuoteHomeFactors{}, fmt.Errorf("invalid loss conversion factor: %w", err)
+	}
+	return QuoteHomeFactors{Gain: g, Loss: l}, nil
+}
+
+// Convert converts amount of the quote currency into the home currency, with Gain if amount is
+// positive and Loss if it is negative.
+func (f QuoteHomeFactors) Convert(amount float64) float64 {
+	if amount < 0 {
+		return amount * f.Loss
+	}
+	return amount * f.Gain
+}
+
+// PnL returns the profit or loss, in the home currency, of a Position of units, positive for long
+// and negative for short, when the price moves by move, positive for a rise.
+func (f QuoteHomeFactors) PnL(units DecimalNumber, move float64) (float64, error) {
+	u, err := units.Float64()
+	if err != nil {
+		return 0, err
+	}
+	return f.Convert(u * move), nil
+}
+
+// PipPnL returns the profit or loss, in the home currency, of a Position of units, positive for
+// long and negative for short, when the price moves by pips, positive for a rise.
+func (f QuoteHomeFactors) PipPnL(units DecimalNumber, pips float64, precision InstrumentPrecision) (float64, error) {
+	pip, _ := precision.pip().Float64()
+	return f.PnL(units, pips*pip)
+}
+
+// UnitsAvailableDetails represents the number of units available to Orders of each direction.
+type UnitsAvailableDetails struct {
+	// Long is the number of units available to an Order with a positive number of units.
+	Long DecimalNumber `json:"long"`
+	// Short is the number of units available to an Order with a negative number of units.
+	Short DecimalNumber `json:"short"`
+}
+
+// UnitsAvailable represents the number of units that can be traded for an Instrument, depending
+// on the positionFill of the Order.
+type UnitsAvailable struct {
+	// Default is the units available to Orders with OrderPositionFillDefault.
+	Default UnitsAvailableDetails `json:"default"`
+	// ReduceFirst is the units available to Orders with OrderPositionFillReduceFirst.
+	ReduceFirst UnitsAvailableDetails `json:"reduceFirst"`
+	// ReduceOnly is the units available to Orders with OrderPositionFillReduceOnly.
+	ReduceOnly UnitsAvailableDetails `json:"reduceOnly"`
+	// OpenOnly is the units available to Orders with OrderPositionFillOpenOnly.
+	OpenOnly UnitsAvailableDetails `json:"openOnly"`
+}
+
+// For returns the units available to Orders with positionFill. An empty positionFill is
+// OrderPositionFillDefault.
+func (u *UnitsAvailable) For(positionFill OrderPositionFill) (UnitsAvailableDetails, error) {
+	switch positionFill {
+	case "", OrderPositionFillDefault:
+		return u.Default, nil
+	case OrderPositionFillReduceFirst:
+		return u.ReduceFirst, nil
+	case OrderPositionFillReduceOnly:
+		return u.ReduceOnly, nil
+	case OrderPositionFillOpenOnly:
+		return u.OpenOnly, nil
+	default:
+		return UnitsAvailableDetails{}, fmt.Errorf("invalid position fill %q", positionFill)
+	}
+}
+
+// Clamp limits units, positive to buy and negative to sell, to the units available to an Order
+// with positionFill in that direction, so that the Order is not rejected for its size. Units
+// within the limit are returned unchanged. The result is zero if nothing is available, such as
+// when reducing a Position that does not exist.
+func (u *UnitsAvailable) Clamp(units DecimalNumber, positionFill OrderPositionFill) (DecimalNumber, error) {
+	details, err := u.For(positionFill)
+	if err != nil {
+		return "", err
+	}
+	want, err := units.Rat()
+	if err != nil {
+		return "", err
+	}
+	available := details.Long
+	if want.Sign() < 0 {
+		available = details.Short
+	}
+	limit, err := available.Rat()
+	if err != nil {
+		return "", fmt.Errorf("invalid units available: %w", err)
+	}
+	// The units available are given as magnitudes in both directions.
+	limit.Abs(limit)
+	if new(big.Rat).Abs(want).Cmp(limit) <= 0 {
+		return units, nil
+	}
+	if want.Sign() < 0 {
+		limit.Neg(limit)
+	}
+	return DecimalNumber(limit.FloatString(fractionDigits(string(available)))), nil
+}
+
 // HomeConversions represents the factors to use to convert quantities of a given
 // currency into the Account's home currency.
 type HomeConversions struct {
@@ -78,12 +307,19 @@ type HomeConversions struct {
 	PositionValue DecimalNumber `json:"positionValue"`
 }
 
+// QuoteHome returns the AccountGain and AccountLoss factors as QuoteHomeFactors, for the
+// Instruments whose quote currency is Currency.
+func (c HomeConversions) QuoteHome() (QuoteHomeFactors, error) {
+	return newQuoteHomeFactors(c.AccountGain, c.AccountLoss)
+}
+
 // PricingHeartbeat represents a heartbeat message sent for a Pricing stream.
 type PricingHeartbeat struct {
 	// Type is the string "HEARTBEAT".
 	Type string `json:"type"`
 	// Time is the date/time when the PricingHeartbeat was created.
 	Time DateTime `json:"time"`
+	Received
 }
 
 // GetType returns the heartbeat type string ("HEARTBEAT").
@@ -101,11 +337,52 @@ func (p PricingHeartbeat) GetTime() DateTime {
 // e.g. "EUR_USD:S10:BM"
 type CandleSpecification string
 
+// NewCandleSpecification returns the CandleSpecification of the candlesticks of instrument with
+// the given granularity, based on the price components in price. An empty price selects midpoint
+// candlesticks.
+func NewCandleSpecification(instrument InstrumentName, granularity CandlestickGranularity, price PriceComponents) CandleSpecification {
+	if price == 0 {
+		price = PriceComponentMid
+	}
+	return CandleSpecification(fmt.Sprintf("%s:%s:%s", instrument, granularity, price.String()))
+}
+
+// Parse splits the specification into its Instrument, granularity and price components. It
+// returns an error if the price components are invalid.
+func (s CandleSpecification) Parse() (InstrumentName, CandlestickGranularity, PriceComponents, error) {
+	parts := strings.Split(string(s), ":")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return "", "", 0, fmt.Errorf("invalid candle specification %q", s)
+	}
+	price, err := ParsePriceComponents(parts[2])
+	if err != nil {
+		return "", "", 0, fmt.Errorf("candle specification %q: %w", s, err)
+	}
+	return parts[0], CandlestickGranularity(parts[1]), price, nil
+}
+
 // Common Definitions https://developer.oanda.com/rest-live-v20/pricing-common-df/
 
 // PriceValue is a string representation of a decimal number that represents a price value.
 type PriceValue string
 
+// Price returns the PriceValue for v formatted with the given number of decimal places, which
+// should be the Instrument's DisplayPrecision. Rounding is done on the binary float64 value, so
+// a value such as 1.073255 that is not exactly representable may round down.
+func Price(v float64, precision int) PriceValue {
+	return PriceValue(strconv.FormatFloat(v, 'f', precision, 64))
+}
+
+// Float64 parses the price as a float64.
+func (p PriceValue) Float64() (float64, error) {
+	return parseFloat(string(p))
+}
+
+// Rat parses the price exactly as a big.Rat.
+func (p PriceValue) Rat() (*big.Rat, error) {
+	return parseRat(string(p))
+}
+
 // PriceBucket represents a price available for a specified liquidity amount.
 type PriceBucket struct {
 	// Price is the price offered.
@@ -176,6 +453,12 @@ func (r *PriceLatestCandlesticksRequest) AddSpecifications(specs ...CandleSpecif
 	return r
 }
 
+// AddCandles adds the specification of the candlesticks of instrument with the given
+// granularity and price components to the request, as with [NewCandleSpecification].
+func (r *PriceLatestCandlesticksRequest) AddCandles(instrument InstrumentName, granularity CandlestickGranularity, price PriceComponents) *PriceLatestCandlesticksRequest {
+	return r.AddSpecifications(NewCandleSpecification(instrument, granularity, price))
+}
+
 // SetUnits sets the number of units used to calculate the volume-weighted average bid and ask prices.
 func (r *PriceLatestCandlesticksRequest) SetUnits(units DecimalNumber) *PriceLatestCandlesticksRequest {
 	r.units = &units
@@ -210,6 +493,15 @@ func (r *PriceLatestCandlesticksRequest) validate() error {
 	if len(r.specifications) == 0 {
 		return errors.New("missing specifications")
 	}
+	for _, spec := range r.specifications {
+		_, granularity, _, err := spec.Parse()
+		if err != nil {
+			return err
+		}
+		if err := granularity.validate(); err != nil {
+			return fmt.Errorf("candle specification %q: %w", spec, err)
+		}
+	}
 	if r.dailyAlignment != nil {
 		if *r.dailyAlignment < 0 || *r.dailyAlignment > 23 {
 			return fmt.Errorf("daily alignment must be between 0 and 23")
@@ -220,6 +512,11 @@ func (r *PriceLatestCandlesticksRequest) validate() error {
 			return err
 		}
 	}
+	if r.weeklyAlignment != nil {
+		if err := r.weeklyAlignment.validate(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -280,6 +577,7 @@ type PriceInformationRequest struct {
 	Instruments            []InstrumentName
 	Since                  *DateTime
 	IncludeHomeConversions bool
+	IncludeUnitsAvailable  bool
 }
 
 // NewPriceInformationRequest creates a new empty [PriceInformationRequest].
@@ -296,7 +594,8 @@ func (r *PriceInformationRequest) AddInstruments(instruments ...InstrumentName)
 	return r
 }
 
-// SetSince filters to return only prices that have changed since the given time.
+// SetSince filters to return only prices that have changed since the given time. Polling with the
+// Time of the previous [PriceInformationResponse] returns only the prices that changed in between.
 func (r *PriceInformationRequest) SetSince(since DateTime) *PriceInformationRequest {
 	r.Since = &since
 	return r
@@ -308,10 +607,20 @@ func (r *PriceInformationRequest) SetIncludeHomeConversions() *PriceInformationR
 	return r
 }
 
+// SetIncludeUnitsAvailable enables inclusion of the units available to trade in each price, see
+// [ClientPrice.UnitsAvailable].
+func (r *PriceInformationRequest) SetIncludeUnitsAvailable() *PriceInformationRequest {
+	r.IncludeUnitsAvailable = true
+	return r
+}
+
 func (r *PriceInformationRequest) validate() error {
 	if len(r.Instruments) == 0 {
 		return errors.New("missing instruments")
 	}
+	if r.Since != nil && r.Since.Time == nil {
+		return errors.New("missing since time")
+	}
 	return nil
 }
 
@@ -322,11 +631,14 @@ func (r *PriceInformationRequest) values() (url.Values, error) {
 	values := url.Values{}
 	values.Set("instruments", strings.Join(r.Instruments, ","))
 	if r.Since != nil {
-		values.Set("since", r.Since.String())
+		values.Set("since", r.Since.Format(time.RFC3339Nano))
 	}
 	if r.IncludeHomeConversions {
 		values.Set("includeHomeConversions", "true")
 	}
+	if r.IncludeUnitsAvailable {
+		values.Set("includeUnitsAvailable", "true")
+	}
 	return values, nil
 }
 
@@ -337,6 +649,47 @@ type PriceInformationResponse struct {
 	Time            DateTime          `json:"time"`
 }
 
+// HomeConversionsFor returns the home conversion factors of currency, and false if the response
+// has none for it, which is the case unless the request was made with
+// [PriceInformationRequest.SetIncludeHomeConversions].
+func (r *PriceInformationResponse) HomeConversionsFor(currency Currency) (HomeConversions, bool) {
+	for _, c := range r.HomeConversions {
+		if c.Currency == currency {
+			return c, true
+		}
+	}
+	return HomeConversions{}, false
+}
+
+// QuoteHomeFactors returns the factors converting the quote currency of instrument into the
+// Account's home currency: the home conversions of the quote currency if the request was made
+// with [PriceInformationRequest.SetIncludeHomeConversions], or else the
+// QuoteHomeConversionFactors of the price of instrument. It returns an error if the response has
+// neither.
+func (r *PriceInformationResponse) QuoteHomeFactors(instrument InstrumentName) (QuoteHomeFactors, error) {
+	_, quote, ok := strings.Cut(instrument, "_")
+	if !ok {
+		return QuoteHomeFactors{}, fmt.Errorf("invalid instrument name %q", instrument)
+	}
+	if conversions, ok := r.HomeConversionsFor(Currency(quote)); ok {
+		return conversions.QuoteHome()
+	}
+	if price, ok := r.PriceFor(instrument); ok && price.QuoteHomeConversionFactors != nil {
+		return price.QuoteHomeConversionFactors.QuoteHome()
+	}
+	return QuoteHomeFactors{}, fmt.Errorf("no home conversion factor for %s", quote)
+}
+
+// PriceFor returns the price of instrument, and false if the response has none for it.
+func (r *PriceInformationResponse) PriceFor(instrument InstrumentName) (ClientPrice, bool) {
+	for _, p := range r.Prices {
+		if p.Instrument == instrument {
+			return p, true
+		}
+	}
+	return ClientPrice{}, false
+}
+
 // Information retrieves pricing information for the specified instruments.
 //
 // This corresponds to the OANDA API endpoint: GET /v3/accounts/{accountID}/pricing
@@ -471,6 +824,10 @@ type PriceStreamRequest struct {
 	instruments           []InstrumentName
 	snapShot              bool
 	includeHomeConversion bool
+	skipHeartbeats        bool
+	reuseBuffers          bool
+	scratch               ClientPrice
+	heartbeat             *heartbeatClock
 }
 
 // NewPriceStreamRequest creates a new [PriceStreamRequest] for the given instruments.
@@ -480,6 +837,7 @@ func NewPriceStreamRequest(instruments ...InstrumentName) *PriceStreamRequest {
 		instruments:           instruments,
 		snapShot:              true,
 		includeHomeConversion: false,
+		heartbeat:             &heartbeatClock{},
 	}
 }
 
@@ -495,6 +853,38 @@ func (r *PriceStreamRequest) SetIncludeHomeConversions() *PriceStreamRequest {
 	return r
 }
 
+// SkipHeartbeats stops [PricingHeartbeat] items from being sent to the stream channel. The
+// heartbeats are still tracked and reported by [PriceStreamRequest.LastHeartbeat].
+func (r *PriceStreamRequest) SkipHeartbeats() *PriceStreamRequest {
+	r.skipHeartbeats = true
+	return r
+}
+
+// SetReuseBuffers makes the stream decode every [ClientPrice] into the same Bids and Asks
+// backing arrays instead of allocating new ones, reducing garbage for high-frequency streams.
+// With reuse enabled, the Bids and Asks of a received price are only valid until the next
+// item is received; copy them if they must be retained. A request with reuse enabled must not
+// be used by more than one stream at a time.
+func (r *PriceStreamRequest) SetReuseBuffers() *PriceStreamRequest {
+	r.reuseBuffers = true
+	return r
+}
+
+// LastHeartbeat returns when the most recent [PricingHeartbeat] of a stream opened with this
+// request was received, by the local clock, or the zero time if no heartbeat has been received
+// yet. Comparing it with time.Now tells how stale the stream is regardless of any skew between
+// the local and server clocks. It is safe to call while the stream is running.
+func (r *PriceStreamRequest) LastHeartbeat() time.Time {
+	return r.heartbeat.last()
+}
+
+// LastHeartbeatServerTime returns the server time reported by the most recent
+// [PricingHeartbeat] of a stream opened with this request, or the zero time if no heartbeat has
+// been received yet. It is safe to call while the stream is running.
+func (r *PriceStreamRequest) LastHeartbeatServerTime() time.Time {
+	return r.heartbeat.lastServerTime()
+}
+
 func (r *PriceStreamRequest) values() (url.Values, error) {
 	values := url.Values{}
 	if len(r.instruments) == 0 {
@@ -515,10 +905,13 @@ func (r *PriceStreamRequest) values() (url.Values, error) {
 type PriceStreamItem interface {
 	GetType() string
 	GetTime() DateTime
+	// GetReceivedAt returns the local time at which the item was received on the stream.
+	GetReceivedAt() time.Time
 }
 
 // Price opens a streaming connection for pricing data. Items are sent to ch until
-// done is closed or the context is cancelled.
+// done is closed or the context is cancelled. Heartbeats are delivered as [PricingHeartbeat]
+// unless [PriceStreamRequest.SkipHeartbeats] is set.
 //
 // This corresponds to the OANDA API endpoint: GET /v3/accounts/{accountID}/pricing/stream
 //
@@ -529,10 +922,118 @@ func (c *StreamClient) Price(ctx context.Context, req *PriceStreamRequest, ch ch
 	if err != nil {
 		return err
 	}
-	return streamLoop(ctx, c, path, values, ch, done, parsePriceStreamItem)
+	return streamLoop(ctx, c, path, values, ch, done, req.parse)
+}
+
+// PriceStream is a running pricing stream opened with [StreamClient.PriceStream]. The stream
+// owns its channel and closes it when the stream ends.
+type PriceStream struct {
+	stream *stream[PriceStreamItem]
+	req    *PriceStreamRequest
+}
+
+// PriceStream opens a streaming connection for pricing data and returns a handle to it. The
+// stream runs until ctx is cancelled, [PriceStream.Close] is called, the server ends the
+// stream, or an error occurs; in each case the channel returned by [PriceStream.Updates] is
+// closed and the cause is reported by [PriceStream.Err]. Unlike [StreamClient.Price], the
+// caller neither creates nor closes the channel.
+//
+// This corresponds to the OANDA API endpoint: GET /v3/accounts/{accountID}/pricing/stream
+//
+// Reference: https://developer.oanda.com/rest-live-v20/pricing-ep/#collapse_endpoint_3
+func (c *StreamClient) PriceStream(ctx context.Context, req *PriceStreamRequest) (*PriceStream, error) {
+	path := fmt.Sprintf("/v3/accounts/%s/pricing/stream", c.accountID)
+	values, err := req.values()
+	if err != nil {
+		return nil, err
+	}
+	return &PriceStream{
+		stream: startStream(ctx, c, path, values, req.parse),
+		req:    req,
+	}, nil
+}
+
+// Updates returns the channel on which pricing items are delivered. It is closed when the
+// stream ends.
+func (s *PriceStream) Updates() <-chan PriceStreamItem {
+	return s.stream.updates
+}
+
+// Err returns the error that ended the stream, or nil if the stream is still running, ended
+// normally, or was stopped with Close. A cancelled context is reported as the context's error.
+func (s *PriceStream) Err() error {
+	return s.stream.getErr()
+}
+
+// Close stops the stream and waits for it to end. It is safe to call more than once.
+func (s *PriceStream) Close() error {
+	return s.stream.close()
+}
+
+// LastHeartbeat returns when the most recent [PricingHeartbeat] of the stream was received, by
+// the local clock, or the zero time if no heartbeat has been received yet.
+func (s *PriceStream) LastHeartbeat() time.Time {
+	return s.req.LastHeartbeat()
+}
+
+// LastHeartbeatServerTime returns the server time reported by the most recent
+// [PricingHeartbeat] of the stream, or the zero time if no heartbeat has been received yet.
+func (s *PriceStream) LastHeartbeatServerTime() time.Time {
+	return s.req.LastHeartbeatServerTime()
+}
+
+// processPrices passes the ClientPrices received on items, such as the channel returned by
+// [PriceStream.Updates], to process until items is closed or ctx is cancelled, in which case it
+// returns the context's error. Other items are skipped. The errors of process are passed to
+// handleError, if not nil, as they occur.
+func processPrices(ctx context.Context, items <-chan PriceStreamItem, process func(ClientPrice) error, handleError func(error)) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case item, ok := <-items:
+			if !ok {
+				return nil
+			}
+			price, ok := item.(ClientPrice)
+			if !ok {
+				continue
+			}
+			if err := process(price); err != nil && handleError != nil {
+				handleError(err)
+			}
+		}
+	}
+}
+
+// parse decodes a pricing stream message, recording heartbeats and dropping them if the
+// request asks for it.
+func (r *PriceStreamRequest) parse(raw json.RawMessage) (PriceStreamItem, bool, error) {
+	price := &ClientPrice{}
+	if r.reuseBuffers {
+		price = &r.scratch
+	}
+	item, ok, err := parsePriceStreamItemInto(raw, price)
+	if err != nil || !ok {
+		return item, ok, err
+	}
+	if heartbeat, isHeartbeat := item.(PricingHeartbeat); isHeartbeat {
+		r.heartbeat.record(heartbeat.Time, heartbeat.ReceivedAt)
+		if r.skipHeartbeats {
+			return nil, false, nil
+		}
+	}
+	return item, true, nil
 }
 
 func parsePriceStreamItem(raw json.RawMessage) (PriceStreamItem, bool, error) {
+	return parsePriceStreamItemInto(raw, &ClientPrice{})
+}
+
+// parsePriceStreamItemInto is like parsePriceStreamItem but decodes prices into price, reusing
+// the backing arrays of its Bids and Asks.
+func parsePriceStreamItemInto(raw json.RawMessage, price *ClientPrice) (PriceStreamItem, bool, error) {
+	receivedAt := time.Now()
 	var typeOnly struct {
 		Type string `json:"type"`
 	}
@@ -541,16 +1042,18 @@ func parsePriceStreamItem(raw json.RawMessage) (PriceStreamItem, bool, error) {
 	}
 	switch typeOnly.Type {
 	case "PRICE":
-		var price ClientPrice
-		if err := json.Unmarshal(raw, &price); err != nil {
+		*price = ClientPrice{Bids: price.Bids[:0], Asks: price.Asks[:0]}
+		if err := json.Unmarshal(raw, price); err != nil {
 			return nil, false, err
 		}
-		return price, true, nil
+		price.ReceivedAt = receivedAt
+		return *price, true, nil
 	case "HEARTBEAT":
 		var heartbeat PricingHeartbeat
 		if err := json.Unmarshal(raw, &heartbeat); err != nil {
 			return nil, false, err
 		}
+		heartbeat.ReceivedAt = receivedAt
 		return heartbeat, true, nil
 	}
 	return nil, false, nil
@@ -1,6 +1,11 @@
 package oanda
 
 import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 )
@@ -15,6 +20,120 @@ func TestPriceService_LatestCandlestick(t *testing.T) {
 	debugResponse(resp)
 }
 
+func TestPriceService_LatestCandlesticks_Request(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/v3/accounts/1/candles/latest" || q.Get("candleSpecifications") != "EUR_USD:M1:BA,USD_JPY:H1:M" ||
+			q.Get("units") != "1000" || q.Get("smooth") != "True" {
+			t.Errorf("unexpected request %s?%s", r.URL.Path, r.URL.RawQuery)
+		}
+		_, _ = fmt.Fprint(w, `{"latestCandles":[
+			{"instrument":"EUR_USD","granularity":"M1","candles":[{"time":"2025-01-01T00:00:00Z","bid":{"c":"1.1"},"ask":{"c":"1.2"},"complete":false}]},
+			{"instrument":"USD_JPY","granularity":"H1","candles":[{"time":"2025-01-01T00:00:00Z","mid":{"c":"150.0"},"complete":true}]}]}`)
+	}))
+	defer server.Close()
+	client := NewClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+
+	req := NewPriceLatestCandlesticksRequest().AddCandles("EUR_USD", M1, PriceComponentBid|PriceComponentAsk).AddCandles("USD_JPY", H1, 0).
+		SetUnits("1000").SetSmooth()
+	resp, err := client.Price.LatestCandlesticks(t.Context(), req)
+	if err != nil {
+		t.Fatalf("failed to get latest candlesticks: %v", err)
+	}
+	if len(resp) != 2 || resp[0].Candles[0].Ask.C != "1.2" || resp[1].Granularity != H1 || resp[1].Candles[0].Mid.C != "150.0" {
+		t.Errorf("got %+v", resp)
+	}
+
+	instrument, granularity, price, err := NewCandleSpecification("EUR_USD", S10, PriceComponentMid|PriceComponentBid).Parse()
+	if err != nil || instrument != "EUR_USD" || granularity != S10 || price != PriceComponentBid|PriceComponentMid {
+		t.Errorf("got %s, %s, %s (%v), want EUR_USD, S10 and BM", instrument, granularity, price, err)
+	}
+	if _, err := client.Price.LatestCandlesticks(t.Context(), NewPriceLatestCandlesticksRequest().AddSpecifications("EUR_USD:M1")); err == nil {
+		t.Error("got no error for an invalid specification")
+	}
+}
+
+func TestPriceService_Information_Since(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/v3/accounts/1/pricing" || q.Get("instruments") != "EUR_USD,USD_JPY" ||
+			q.Get("includeHomeConversions") != "true" {
+			t.Errorf("unexpected request %s?%s", r.URL.Path, r.URL.RawQuery)
+		}
+		if q.Has("since") && q.Get("since") != "2025-01-01T00:00:00.123456789Z" {
+			t.Errorf("got since %q", q.Get("since"))
+		}
+		_, _ = fmt.Fprint(w, `{"prices":[],"time":"2025-01-01T00:00:00.123456789Z","homeConversions":[
+			{"currency":"USD","accountGain":"1","accountLoss":"1","positionValue":"1"},
+			{"currency":"JPY","accountGain":"0.0066","accountLoss":"0.0067","positionValue":"0.00665"}]}`)
+	}))
+	defer server.Close()
+	client := NewClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+
+	req := NewPriceInformationRequest().AddInstruments("EUR_USD", "USD_JPY").SetIncludeHomeConversions()
+	resp, err := client.Price.Information(t.Context(), req)
+	if err != nil {
+		t.Fatalf("failed to get prices: %v", err)
+	}
+	if c, ok := resp.HomeConversionsFor("JPY"); !ok || c.AccountLoss != "0.0067" || c.PositionValue != "0.00665" {
+		t.Errorf("got JPY conversions %+v, %t", c, ok)
+	}
+	if _, ok := resp.HomeConversionsFor("EUR"); ok {
+		t.Error("got conversions for EUR")
+	}
+	if _, err := client.Price.Information(t.Context(), req.SetSince(resp.Time)); err != nil {
+		t.Fatalf("failed to get prices since %v: %v", resp.Time, err)
+	}
+	if _, err := client.Price.Information(t.Context(), req.SetSince(DateTime{})); err == nil {
+		t.Error("got no error for an empty since time")
+	}
+}
+
+func TestClientPrice_Accessors(t *testing.T) {
+	var resp PriceInformationResponse
+	err := json.Unmarshal([]byte(`{"prices":[{"instrument":"EUR_USD",
+		"bids":[{"price":"1.10010","liquidity":1000000},{"price":"1.10012","liquidity":250000}],
+		"asks":[{"price":"1.10030","liquidity":500000},{"price":"1.10024","liquidity":100000}]},
+		{"instrument":"USD_JPY","bids":[],"asks":[{"price":"150.000","liquidity":1}]}]}`), &resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	price, ok := resp.PriceFor("EUR_USD")
+	if !ok {
+		t.Fatal("no price for EUR_USD")
+	}
+	if bid, ok := price.BestBid(); !ok || bid.Price != "1.10012" {
+		t.Errorf("got best bid %+v", bid)
+	}
+	if ask, ok := price.BestAsk(); !ok || ask.Price != "1.10024" {
+		t.Errorf("got best ask %+v", ask)
+	}
+	if mid, err := price.Mid(); err != nil || mid != "1.10018" {
+		t.Errorf("got mid %s, %v", mid, err)
+	}
+	if spread, err := price.Spread(); err != nil || spread != "0.00012" {
+		t.Errorf("got spread %s, %v", spread, err)
+	}
+	precision := InstrumentPrecision{Instrument: "EUR_USD", PipLocation: -4, DisplayPrecision: 5}
+	if pips, err := price.SpreadPips(precision); err != nil || math.Abs(pips-1.2) > 1e-9 {
+		t.Errorf("got spread of %v pips, %v", pips, err)
+	}
+	if bid, ask := price.TopLiquidity(); bid != 250000 || ask != 100000 {
+		t.Errorf("got liquidity %d and %d", bid, ask)
+	}
+
+	jpy, _ := resp.PriceFor("USD_JPY")
+	if _, err := jpy.Mid(); err == nil {
+		t.Error("got no error for a price without bids")
+	}
+	if bid, ask := jpy.TopLiquidity(); bid != 0 || ask != 1 {
+		t.Errorf("got liquidity %d and %d", bid, ask)
+	}
+	if _, ok := resp.PriceFor("GBP_USD"); ok {
+		t.Error("got a price for GBP_USD")
+	}
+}
+
 func TestPriceService_Information(t *testing.T) {
 	client := setupClient(t)
 	req := NewPriceInformationRequest().AddInstruments("EUR_USD")
@@ -44,3 +163,164 @@ func TestStreamClient_Price(t *testing.T) {
 		t.Errorf("got error: %v", err)
 	}
 }
+
+func TestPriceStreamRequest_Heartbeats(t *testing.T) {
+	heartbeat := []byte(`{"type":"HEARTBEAT","time":"2024-01-02T03:04:05.000000000Z"}`)
+	want := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	t.Run("deliver", func(t *testing.T) {
+		req := NewPriceStreamRequest("EUR_USD")
+		before := time.Now()
+		item, ok, err := req.parse(heartbeat)
+		if err != nil {
+			t.Fatalf("failed to parse heartbeat: %v", err)
+		}
+		if _, isHeartbeat := item.(PricingHeartbeat); !ok || !isHeartbeat {
+			t.Errorf("got %T (ok=%v), want PricingHeartbeat", item, ok)
+		}
+		if got := req.LastHeartbeatServerTime(); !got.Equal(want) {
+			t.Errorf("got last heartbeat server time %v, want %v", got, want)
+		}
+		// The receive time is taken from the local clock, not from the heartbeat.
+		if got := req.LastHeartbeat(); got.Before(before) || got.After(time.Now()) {
+			t.Errorf("got last heartbeat %v, want the time it was parsed", got)
+		}
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		req := NewPriceStreamRequest("EUR_USD").SkipHeartbeats()
+		_, ok, err := req.parse(heartbeat)
+		if err != nil {
+			t.Fatalf("failed to parse heartbeat: %v", err)
+		}
+		if ok {
+			t.Error("heartbeat was not skipped")
+		}
+		if got := req.LastHeartbeatServerTime(); !got.Equal(want) || req.LastHeartbeat().IsZero() {
+			t.Errorf("got last heartbeat %v at server time %v, want %v", req.LastHeartbeat(), got, want)
+		}
+	})
+}
+
+var benchmarkPrice = []byte(`{"type":"PRICE","time":"2024-01-02T03:04:05.123456789Z",` +
+	`"bids":[{"price":"1.10000","liquidity":1000000},{"price":"1.09990","liquidity":2000000}],` +
+	`"asks":[{"price":"1.10010","liquidity":1000000},{"price":"1.10020","liquidity":2000000}],` +
+	`"closeoutBid":"1.09980","closeoutAsk":"1.10030","instrument":"EUR_USD","tradeable":true}`)
+
+func TestPriceStreamRequest_ReuseBuffers(t *testing.T) {
+	req := NewPriceStreamRequest("EUR_USD").SetReuseBuffers()
+	first, _, err := req.parse(benchmarkPrice)
+	if err != nil {
+		t.Fatalf("failed to parse price: %v", err)
+	}
+	second, _, err := req.parse(benchmarkPrice)
+	if err != nil {
+		t.Fatalf("failed to parse price: %v", err)
+	}
+	a, b := first.(ClientPrice), second.(ClientPrice)
+	if len(b.Bids) != 2 || len(b.Asks) != 2 {
+		t.Fatalf("got %d bids and %d asks, want 2 and 2", len(b.Bids), len(b.Asks))
+	}
+	if &a.Bids[0] != &b.Bids[0] {
+		t.Error("bids backing array was not reused")
+	}
+}
+
+func BenchmarkPriceStreamRequest_parse(b *testing.B) {
+	for _, reuse := range []bool{false, true} {
+		name := "alloc"
+		if reuse {
+			name = "reuse"
+		}
+		b.Run(name, func(b *testing.B) {
+			req := NewPriceStreamRequest("EUR_USD")
+			if reuse {
+				req.SetReuseBuffers()
+			}
+			b.ReportAllocs()
+			for b.Loop() {
+				if _, _, err := req.parse(benchmarkPrice); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestPriceService_Information_UnitsAvailable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("includeUnitsAvailable") != "true" {
+			t.Errorf("unexpected request %s?%s", r.URL.Path, r.URL.RawQuery)
+		}
+		_, _ = fmt.Fprint(w, `{"prices":[{"instrument":"EUR_USD","unitsAvailable":{
+			"default":{"long":"250000","short":"400000"},
+			"reduceFirst":{"long":"250000","short":"400000"},
+			"reduceOnly":{"long":"0","short":"150000"},
+			"openOnly":{"long":"250000","short":"250000"}}}]}`)
+	}))
+	defer server.Close()
+	client := NewClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+
+	resp, err := client.Price.Information(t.Context(), NewPriceInformationRequest().AddInstruments("EUR_USD").SetIncludeUnitsAvailable())
+	if err != nil {
+		t.Fatalf("failed to get prices: %v", err)
+	}
+	price, _ := resp.PriceFor("EUR_USD")
+	for _, test := range []struct {
+		units        DecimalNumber
+		positionFill OrderPositionFill
+		want         DecimalNumber
+	}{
+		{"100000", "", "100000"},
+		{"300000", OrderPositionFillDefault, "250000"},
+		{"-500000", OrderPositionFillReduceFirst, "-400000"},
+		{"-100000", OrderPositionFillReduceOnly, "-100000"},
+		{"100000", OrderPositionFillReduceOnly, "0"},
+		{"-300000", OrderPositionFillOpenOnly, "-250000"},
+	} {
+		got, err := price.ClampUnits(test.units, test.positionFill)
+		if err != nil || got != test.want {
+			t.Errorf("%s %s: got %s (%v), want %s", test.units, test.positionFill, got, err, test.want)
+		}
+	}
+	if _, err := price.ClampUnits("100", "FILL_ALL"); err == nil {
+		t.Error("got no error for an invalid position fill")
+	}
+	if _, err := (ClientPrice{Instrument: "EUR_USD"}).ClampUnits("100", ""); err == nil {
+		t.Error("got no error for a price without units available")
+	}
+}
+
+func TestQuoteHomeFactors(t *testing.T) {
+	var resp PriceInformationResponse
+	err := json.Unmarshal([]byte(`{"prices":[
+		{"instrument":"EUR_GBP","quoteHomeConversionFactors":{"positiveUnits":"1.25","negativeUnits":"1.26"}},
+		{"instrument":"EUR_CHF"}],
+		"homeConversions":[{"currency":"JPY","accountGain":"0.0066","accountLoss":"0.0067","positionValue":"0.00665"}]}`), &resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jpy, err := resp.QuoteHomeFactors("USD_JPY")
+	if err != nil || jpy != (QuoteHomeFactors{Gain: 0.0066, Loss: 0.0067}) {
+		t.Fatalf("got USD_JPY factors %+v (%v)", jpy, err)
+	}
+	precision := InstrumentPrecision{Instrument: "USD_JPY", PipLocation: -2, DisplayPrecision: 3}
+	// A short of 10000 loses 100 pips of 0.01 JPY, 10000 JPY converted at the loss factor.
+	if pnl, err := jpy.PipPnL("-10000", 100, precision); err != nil || math.Abs(pnl+67) > 1e-9 {
+		t.Errorf("got %v (%v), want -67", pnl, err)
+	}
+	if pnl, err := jpy.PnL("10000", 0.5); err != nil || math.Abs(pnl-33) > 1e-9 {
+		t.Errorf("got %v (%v), want 33", pnl, err)
+	}
+	gbp, err := resp.QuoteHomeFactors("EUR_GBP")
+	if err != nil || gbp != (QuoteHomeFactors{Gain: 1.25, Loss: 1.26}) {
+		t.Errorf("got EUR_GBP factors %+v (%v)", gbp, err)
+	}
+	if _, err := resp.QuoteHomeFactors("EUR_CHF"); err == nil {
+		t.Error("got no error for a price without factors")
+	}
+	fill := HomeConversionFactors{GainQuoteHome: ConversionFactor{"0.5"}, LossQuoteHome: ConversionFactor{"0.6"}}
+	if f, err := fill.QuoteHome(); err != nil || f.Convert(-10) != -6 || f.Convert(10) != 5 {
+		t.Errorf("got fill factors %+v (%v)", f, err)
+	}
+}
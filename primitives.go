@@ -1,7 +1,11 @@
 package oanda
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
 	"time"
 )
 
@@ -13,10 +17,84 @@ import (
 // on what the number represents.
 type DecimalNumber string
 
+// Units returns the DecimalNumber for a whole number of units. Negative values denote short
+// Orders and Positions.
+func Units(n int64) DecimalNumber {
+	return DecimalNumber(strconv.FormatInt(n, 10))
+}
+
+// UnitsFloat returns the DecimalNumber for a fractional number of units, using the fewest
+// digits that represent f. Instruments only accept as many decimal places as their
+// TradeUnitsPrecision allows.
+func UnitsFloat(f float64) DecimalNumber {
+	return DecimalNumber(strconv.FormatFloat(f, 'f', -1, 64))
+}
+
+// Special units values accepted by the close endpoints in place of a number.
+const (
+	// UnitsAll closes all units of a Trade or of one side of a Position. It is accepted by
+	// [tradeService.Close], [positionService.Close] and the trade close and position closeout
+	// of a Market Order.
+	UnitsAll DecimalNumber = "ALL"
+	// UnitsNone leaves one side of a Position open. It is only accepted by
+	// [positionService.Close].
+	UnitsNone DecimalNumber = "NONE"
+)
+
+// validateUnits returns an error if units is one of the special values [UnitsAll] and
+// [UnitsNone] other than those in allowed. field names the request field in the error.
+func validateUnits(field string, units DecimalNumber, allowed ...DecimalNumber) error {
+	if units != UnitsAll && units != UnitsNone {
+		return nil
+	}
+	for _, a := range allowed {
+		if units == a {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s must be a number, not %s", field, units)
+}
+
+// Float64 parses the number as a float64.
+func (d DecimalNumber) Float64() (float64, error) {
+	return parseFloat(string(d))
+}
+
+// Rat parses the number exactly as a big.Rat.
+func (d DecimalNumber) Rat() (*big.Rat, error) {
+	return parseRat(string(d))
+}
+
 // AccountUnits is a quantity of an Account's home currency. This is a DecimalNumber encoded as a
 // string. The amount of precision provided depends on the Account's home currency.
 type AccountUnits string
 
+// Float64 parses the amount as a float64.
+func (u AccountUnits) Float64() (float64, error) {
+	return parseFloat(string(u))
+}
+
+// Rat parses the amount exactly as a big.Rat.
+func (u AccountUnits) Rat() (*big.Rat, error) {
+	return parseRat(string(u))
+}
+
+func parseFloat(s string) (float64, error) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid decimal number %q", s)
+	}
+	return f, nil
+}
+
+func parseRat(s string) (*big.Rat, error) {
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, fmt.Errorf("invalid decimal number %q", s)
+	}
+	return r, nil
+}
+
 // Currency represents a currency name identifier. This is an ISO 4217 currency code (e.g., USD, EUR, JPY).
 type Currency string
 
@@ -141,6 +219,28 @@ type Instrument struct {
 	Tags []Tag `json:"tags"`
 }
 
+// TagNames returns the names of the Instrument's tags of tagType, such as "ASSET_CLASS", in the
+// order they were reported.
+func (i Instrument) TagNames(tagType string) []string {
+	var names []string
+	for _, tag := range i.Tags {
+		if tag.Type == tagType {
+			names = append(names, tag.Name)
+		}
+	}
+	return names
+}
+
+// HasTag reports whether the Instrument has a tag of tagType named name.
+func (i Instrument) HasTag(tagType, name string) bool {
+	for _, tag := range i.Tags {
+		if tag.Type == tagType && tag.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
 // DateTime represents a date and time value in RFC 3339 format. The DateTime format is used for
 // fields representing specific points in time.
 type DateTime struct {
@@ -256,3 +356,46 @@ type HomeConversionFactors struct {
 	// realized in Instrument base units into units of the Account's home currency.
 	LossBaseHome ConversionFactor `json:"lossBaseHome"`
 }
+
+// QuoteHome returns the GainQuoteHome and LossQuoteHome factors as QuoteHomeFactors.
+func (f HomeConversionFactors) QuoteHome() (QuoteHomeFactors, error) {
+	return newQuoteHomeFactors(f.GainQuoteHome.Factor, f.LossQuoteHome.Factor)
+}
+
+// Raw retains the JSON an Order or Transaction was decoded from. It is embedded in [OrderBase]
+// and [TransactionBase] so that fields the library does not model yet remain accessible, and so
+// that values can be re-serialized without loss with [MarshalRaw]. json.Marshal encodes the
+// modeled fields only.
+type Raw struct {
+	// RawJSON is the JSON the value was decoded from. It is nil for values that were not
+	// decoded polymorphically, such as the concrete Transaction fields of a response (e.g.
+	// [OrderCreateResponse.OrderFillTransaction]), or that were built by the caller.
+	RawJSON json.RawMessage `json:"-"`
+}
+
+// GetRawJSON returns the JSON the value was decoded from, or nil if it is unknown.
+func (r Raw) GetRawJSON() json.RawMessage {
+	return r.RawJSON
+}
+
+// setRawJSON stores a copy of raw, as the decoders may reuse the underlying buffer.
+func (r *Raw) setRawJSON(raw json.RawMessage) {
+	r.RawJSON = bytes.Clone(raw)
+}
+
+// MarshalRaw returns the JSON encoding of v without loss: for an Order or Transaction decoded
+// from JSON, the JSON it was decoded from as retained by [Raw], including the fields the library
+// does not model; for other values, such as those built by the caller, json.Marshal(v). As the
+// retained JSON is returned as is, changes made to the fields of v after decoding are not
+// reflected.
+func MarshalRaw(v any) ([]byte, error) {
+	if r, ok := v.(interface{ GetRawJSON() json.RawMessage }); ok && len(r.GetRawJSON()) > 0 {
+		return bytes.Clone(r.GetRawJSON()), nil
+	}
+	return json.Marshal(v)
+}
+
+// rawJSONSetter is implemented by pointers to types that embed [Raw].
+type rawJSONSetter interface {
+	setRawJSON(json.RawMessage)
+}
@@ -0,0 +1,111 @@
+package oanda
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestNumericConstructors(t *testing.T) {
+	tests := []struct {
+		got, want string
+	}{
+		{string(Units(12345)), "12345"},
+		{string(Units(-100)), "-100"},
+		{string(UnitsFloat(0.5)), "0.5"},
+		{string(UnitsFloat(-1.25)), "-1.25"},
+		{string(Price(1.07325, 5)), "1.07325"},
+		{string(Price(151.2, 3)), "151.200"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("got %q, want %q", tt.got, tt.want)
+		}
+	}
+}
+
+func TestDecimalParsing(t *testing.T) {
+	f, err := PriceValue("1.07325").Float64()
+	if err != nil || f != 1.07325 {
+		t.Errorf("got %v (%v), want 1.07325", f, err)
+	}
+	r, err := DecimalNumber("-0.1").Rat()
+	if err != nil || r.String() != "-1/10" {
+		t.Errorf("got %v (%v), want -1/10", r, err)
+	}
+	r, err = AccountUnits("1000.50").Rat()
+	if err != nil || r.FloatString(2) != "1000.50" {
+		t.Errorf("got %v (%v), want 1000.50", r, err)
+	}
+	if _, err := DecimalNumber("abc").Float64(); err == nil {
+		t.Error("got no error for invalid number")
+	}
+	if _, err := PriceValue("").Rat(); err == nil {
+		t.Error("got no error for empty price")
+	}
+}
+
+func TestSpecialUnits(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    func() error
+		wantErr bool
+	}{
+		{"market order ALL", func() error { _, err := NewMarketOrderRequest("EUR_USD", UnitsAll).body(); return err }, true},
+		{"limit order NONE", func() error { _, err := NewLimitOrderRequest("EUR_USD", UnitsNone, "1.1").body(); return err }, true},
+		{"trade close ALL", func() error { _, err := NewTradeCloseALLRequest().body(); return err }, false},
+		{"trade close NONE", func() error { _, err := NewTradeCloseRequest(UnitsNone).body(); return err }, true},
+		{"market order trade close ALL", func() error {
+			_, err := NewMarketOrderRequest("EUR_USD", "-100").CloseTrade("42", UnitsAll).body()
+			return err
+		}, false},
+		{"market order position closeout NONE", func() error {
+			_, err := NewMarketOrderRequest("EUR_USD", "-100").CloseoutLongPosition("EUR_USD", UnitsNone).body()
+			return err
+		}, true},
+		{"position close NONE", func() error { _, err := NewPositionCloseRequest().SetLongAll().SetShortNone().body(); return err }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.body(); (err != nil) != tt.wantErr {
+				t.Errorf("got error %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMarshalRaw(t *testing.T) {
+	rawOrder := `{"type":"LIMIT","id":"5","createTime":"2024-01-01T00:00:00Z","state":"PENDING","price":"1.10000","futureField":{"a":1}}`
+	order, err := unmarshalOrder(json.RawMessage(rawOrder))
+	if err != nil {
+		t.Fatalf("failed to unmarshal order: %v", err)
+	}
+	rawTransaction := `{"type":"ORDER_FILL","id":"6","time":"2024-01-01T00:00:00Z","orderID":"5","futureField":"kept"}`
+	transaction, err := unmarshalTransaction(json.RawMessage(rawTransaction))
+	if err != nil {
+		t.Fatalf("failed to unmarshal transaction: %v", err)
+	}
+	for _, test := range []struct {
+		v    any
+		want string
+	}{{order, rawOrder}, {transaction, rawTransaction}} {
+		if plain, _ := json.Marshal(test.v); strings.Contains(string(plain), "futureField") {
+			t.Errorf("got json.Marshal %s, want the modeled fields only", plain)
+		}
+		b, err := MarshalRaw(test.v)
+		if err != nil || string(b) != test.want {
+			t.Errorf("got %s (%v), want %s", b, err, test.want)
+		}
+		// The encoding decodes to an equal value, unknown fields included.
+		var got, want map[string]any
+		_ = json.Unmarshal(b, &got)
+		_ = json.Unmarshal([]byte(test.want), &want)
+		if got["futureField"] == nil || len(got) != len(want) {
+			t.Errorf("got round trip %v, want %v", got, want)
+		}
+	}
+
+	if b, err := MarshalRaw(NewLimitOrderRequest("EUR_USD", "100", "1.10000")); err != nil || !strings.Contains(string(b), `"price":"1.10000"`) {
+		t.Errorf("got %s (%v) for a value not decoded from JSON", b, err)
+	}
+}
@@ -0,0 +1,108 @@
+package oanda
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultRateLimit is the number of requests per second a [Client] sends by default, below
+	// the 120 requests per second OANDA allows before responding with status 429.
+	DefaultRateLimit = 100
+	// DefaultRateLimitBurst is the number of requests a [Client] sends at once by default
+	// before pacing them at [DefaultRateLimit].
+	DefaultRateLimitBurst = 20
+)
+
+// rateLimitRetries is the number of times a request answered with status 429 (Too Many
+// Requests) is sent again before the response is returned.
+const rateLimitRetries = 3
+
+// rateLimitBackoff is the delay before the first retry of a request answered with status 429
+// without a Retry-After header. It doubles with every retry.
+const rateLimitBackoff = time.Second
+
+// WithRateLimit sets the number of requests per second a [Client] sends and the number it may
+// send at once, [DefaultRateLimit] and [DefaultRateLimitBurst] by default. The limit is shared
+// by every request of the Client, including the pages and series fetched concurrently by
+// [transactionService.DownloadRange] and [instrumentService.CandlesBatch]. A non-positive
+// requestsPerSecond disables pacing. Either way, requests answered with status 429 are sent
+// again after the delay of the Retry-After header, or an exponential backoff, during which the
+// other requests of the Client wait too. It has no effect on a [StreamClient].
+func WithRateLimit(requestsPerSecond float64, burst int) Option {
+	return func(c *clientConfig) {
+		c.limiter = newRateLimiter(requestsPerSecond, burst)
+	}
+}
+
+// rateLimiter paces requests with the generic cell rate algorithm: a request may be sent once
+// the theoretical arrival time of the next one is no more than burst-1 intervals ahead.
+type rateLimiter struct {
+	interval time.Duration
+	burst    int
+
+	mu sync.Mutex
+	// next is the theoretical arrival time of the next request.
+	next time.Time
+	// blockedUntil is the time until which no request is sent, after a 429 response.
+	blockedUntil time.Time
+}
+
+func newRateLimiter(requestsPerSecond float64, burst int) *rateLimiter {
+	l := &rateLimiter{burst: max(burst, 1)}
+	if requestsPerSecond > 0 {
+		l.interval = time.Duration(float64(time.Second) / requestsPerSecond)
+	}
+	return l
+}
+
+// wait blocks until a request may be sent or ctx is done.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	var delay time.Duration
+	if l.interval > 0 {
+		arrival := maxTime(l.next, now)
+		delay = arrival.Sub(now) - time.Duration(l.burst-1)*l.interval
+		l.next = arrival.Add(l.interval)
+	}
+	delay = max(delay, l.blockedUntil.Sub(now))
+	l.mu.Unlock()
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// block holds back every request for d.
+func (l *rateLimiter) block(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until := time.Now().Add(d); until.After(l.blockedUntil) {
+		l.blockedUntil = until
+	}
+}
+
+// retryDelay returns how long to wait before retrying the request that got resp, a 429
+// response, for the given retry: the delay of its Retry-After header, in seconds or as a date,
+// or rateLimitBackoff doubled for every previous retry.
+func retryDelay(resp *http.Response, retry int) time.Duration {
+	header := resp.Header.Get("Retry-After")
+	if seconds, err := strconv.Atoi(header); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(header); err == nil {
+		return time.Until(date)
+	}
+	return rateLimitBackoff << retry
+}
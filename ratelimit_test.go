@@ -0,0 +1,90 @@
+package oanda
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_RateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{}`)
+	}))
+	defer server.Close()
+	client := NewClient("api-key", WithBaseURL(server.URL), WithRateLimit(50, 2))
+
+	start := time.Now()
+	for range 6 {
+		if _, err := doGet[struct{}](client, t.Context(), "/v3/accounts", nil); err != nil {
+			t.Fatalf("got error: %v", err)
+		}
+	}
+	// Two requests are sent at once, and the other four 20ms apart.
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Errorf("sent 6 requests in %v, want at least 80ms at 50 per second", elapsed)
+	}
+}
+
+func TestClient_TooManyRequests(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != `{"order":{}}` {
+			t.Errorf("got body %q", body)
+		}
+		if requests.Add(1) <= 2 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = io.WriteString(w, `{"errorMessage":"Rate limit violation"}`)
+			return
+		}
+		_, _ = io.WriteString(w, `{}`)
+	}))
+	defer server.Close()
+	client := NewClient("api-key", WithBaseURL(server.URL), WithRateLimit(0, 0))
+
+	resp, err := client.sendPostRequest(t.Context(), "/v3/accounts/1/orders", strings.NewReader(`{"order":{}}`))
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	closeBody(resp)
+	if resp.StatusCode != http.StatusOK || requests.Load() != 3 {
+		t.Errorf("got status %d after %d requests, want 200 after 3", resp.StatusCode, requests.Load())
+	}
+
+	// The response is returned once the retries are exhausted.
+	requests.Store(-10)
+	resp, err = client.sendPostRequest(t.Context(), "/v3/accounts/1/orders", strings.NewReader(`{"order":{}}`))
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	closeBody(resp)
+	if resp.StatusCode != http.StatusTooManyRequests || requests.Load() != -10+rateLimitRetries+1 {
+		t.Errorf("got status %d after %d requests, want 429 after %d", resp.StatusCode, requests.Load()+10, rateLimitRetries+1)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	for _, test := range []struct {
+		header string
+		retry  int
+		want   time.Duration
+	}{
+		{"2", 0, 2 * time.Second},
+		{"", 0, rateLimitBackoff},
+		{"", 2, 4 * rateLimitBackoff},
+		{"soon", 1, 2 * rateLimitBackoff},
+	} {
+		resp := &http.Response{Header: http.Header{}}
+		if test.header != "" {
+			resp.Header.Set("Retry-After", test.header)
+		}
+		if got := retryDelay(resp, test.retry); got != test.want {
+			t.Errorf("Retry-After %q, retry %d: got %v, want %v", test.header, test.retry, got, test.want)
+		}
+	}
+}
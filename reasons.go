@@ -0,0 +1,427 @@
+package oanda
+
+import "strings"
+
+// reasonText explains a reject or cancel reason and, where there is one, how to avoid it.
+type reasonText struct {
+	description string
+	remedy      string
+}
+
+// describeReason renders text, or a sentence derived from the reason code for reasons without
+// an explanation, such as reasons added to the API after this library.
+func describeReason(code string, text reasonText, ok bool) string {
+	if !ok {
+		if code == "" {
+			return "No reason was given."
+		}
+		words := strings.ToLower(strings.ReplaceAll(code, "_", " "))
+		return strings.ToUpper(words[:1]) + words[1:] + "."
+	}
+	if text.remedy == "" {
+		return text.description
+	}
+	return text.description + " " + text.remedy
+}
+
+// Describe returns a human-readable explanation of the reason, followed by a suggested
+// remediation where one applies. Reasons the library does not know are rendered from their
+// code, e.g. "Some new reason." for SOME_NEW_REASON.
+func (r OrderCancelReason) Describe() string {
+	text, ok := orderCancelReasonTexts[r]
+	return describeReason(string(r), text, ok)
+}
+
+// Describe returns a human-readable explanation of the reason, followed by a suggested
+// remediation where one applies. Reasons the library does not know are rendered from their
+// code, e.g. "Some new reason." for SOME_NEW_REASON.
+func (r TransactionRejectReason) Describe() string {
+	text, ok := transactionRejectReasonTexts[r]
+	return describeReason(string(r), text, ok)
+}
+
+var orderCancelReasonTexts = map[OrderCancelReason]reasonText{
+	OrderCancelReasonInternalServerError: {
+		"The Order was cancelled because of an internal server error at OANDA.",
+		"Retry the Order; contact OANDA if it keeps happening."},
+	OrderCancelReasonAccountLocked: {
+		"The Order was cancelled because the Account is locked.",
+		"Contact OANDA to unlock the Account."},
+	OrderCancelReasonAccountNewPositionsLocked: {
+		"The Order was cancelled because the Account is not allowed to open new positions.",
+		"Only Orders that reduce existing positions can be filled; contact OANDA for details."},
+	OrderCancelReasonAccountOrderCreationLocked: {
+		"The Order was cancelled because Order creation is locked for the Account.",
+		"Contact OANDA to lift the restriction."},
+	OrderCancelReasonAccountOrderFillLocked: {
+		"The Order was cancelled because Order fills are locked for the Account.",
+		"Contact OANDA to lift the restriction."},
+	OrderCancelReasonClientRequest: {
+		"The Order was cancelled at the client's request.", ""},
+	OrderCancelReasonMigration: {
+		"The Order was cancelled because the Account was migrated.",
+		"Recreate the Order if it is still needed."},
+	OrderCancelReasonMarketHalted: {
+		"The Order was cancelled because the market was halted.",
+		"Retry once trading in the Instrument resumes."},
+	OrderCancelReasonLinkedTradeClosed: {
+		"The Order was cancelled because the Trade it was attached to was closed.", ""},
+	OrderCancelReasonTimeInForceExpired: {
+		"The Order was cancelled because its time in force expired.",
+		"Use a later GTD time or GTC to keep the Order pending longer."},
+	OrderCancelReasonInsufficientMargin: {
+		"The Order was cancelled because the Account did not have enough margin available to fill it.",
+		"Reduce the Order's units, close other positions or deposit funds."},
+	OrderCancelReasonFifoViolation: {
+		"The Order was cancelled because filling it would have violated the FIFO rule.",
+		"Close the oldest Trades of the Instrument first, or reduce the position instead of closing a specific Trade."},
+	OrderCancelReasonBoundsViolation: {
+		"The Order was cancelled because the fill price would have been outside its price bound.",
+		"Widen the price bound or remove it."},
+	OrderCancelReasonClientRequestReplaced: {
+		"The Order was cancelled at the client's request and replaced by another Order.", ""},
+	OrderCancelReasonInsufficientLiquidity: {
+		"The Order was cancelled because there was not enough liquidity to fill it.",
+		"Retry with fewer units or when the market is more liquid."},
+	OrderCancelReasonTakeProfitOnFillGtdTimestampInPast: {
+		"The Order was cancelled because the GTD time of its Take Profit on fill was in the past.",
+		"Use a GTD time in the future for the Take Profit."},
+	OrderCancelReasonTakeProfitOnFillLoss: {
+		"The Order was cancelled because its Take Profit on fill would have closed the Trade at a loss.",
+		"Place the Take Profit on the profitable side of the fill price."},
+	OrderCancelReasonLosingTakeProfit: {
+		"The Order was cancelled because its Take Profit would have closed the Trade at a loss.",
+		"Place the Take Profit on the profitable side of the Trade's open price."},
+	OrderCancelReasonStopLossOnFillGtdTimestampInPast: {
+		"The Order was cancelled because the GTD time of its Stop Loss on fill was in the past.",
+		"Use a GTD time in the future for the Stop Loss."},
+	OrderCancelReasonStopLossOnFillLoss: {
+		"The Order was cancelled because its Stop Loss on fill would have been triggered immediately.",
+		"Place the Stop Loss on the losing side of the fill price."},
+	OrderCancelReasonStopLossOnFillPriceDistanceMaximumExceeded: {
+		"The Order was cancelled because its Stop Loss on fill was further from the fill price than allowed.",
+		"Move the Stop Loss closer to the entry price."},
+	OrderCancelReasonStopLossOnFillRequired: {
+		"The Order was cancelled because the Account requires a Stop Loss on fill.",
+		"Attach a Stop Loss on fill to the Order."},
+	OrderCancelReasonStopLossOnFillGuaranteedRequired: {
+		"The Order was cancelled because the Account requires a guaranteed Stop Loss on fill.",
+		"Attach a Guaranteed Stop Loss on fill to the Order."},
+	OrderCancelReasonStopLossOnFillGuaranteedNotAllowed: {
+		"The Order was cancelled because guaranteed Stop Losses are not allowed for the Account or Instrument.",
+		"Use a regular Stop Loss instead."},
+	OrderCancelReasonStopLossOnFillGuaranteedMinimumDistanceNotMet: {
+		"The Order was cancelled because its guaranteed Stop Loss on fill was closer to the fill price than the Instrument's minimum distance.",
+		"Move the guaranteed Stop Loss further from the entry price; see the Instrument's guaranteed stop loss minimum distance."},
+	OrderCancelReasonStopLossOnFillGuaranteedLevelRestrictionExceeded: {
+		"The Order was cancelled because its guaranteed Stop Loss on fill would have exceeded the Instrument's guaranteed Stop Loss level restriction.",
+		"Reduce the units or spread guaranteed Stop Losses over more price levels."},
+	OrderCancelReasonStopLossOnFillGuaranteedHedgingNotAllowed: {
+		"The Order was cancelled because guaranteed Stop Losses cannot be used while hedging.",
+		"Close the opposing position or use a regular Stop Loss."},
+	OrderCancelReasonStopLossOnFillTimeInForceInvalid: {
+		"The Order was cancelled because the time in force of its Stop Loss on fill was invalid.",
+		"Use GTC, GTD or GFD for the Stop Loss."},
+	OrderCancelReasonStopLossOnFillTriggerConditionInvalid: {
+		"The Order was cancelled because the trigger condition of its Stop Loss on fill was invalid.",
+		"Use a trigger condition supported by the Instrument, or DEFAULT."},
+	OrderCancelReasonTakeProfitOnFillPriceDistanceMaximumExceeded: {
+		"The Order was cancelled because its Take Profit on fill was further from the fill price than allowed.",
+		"Move the Take Profit closer to the entry price."},
+	OrderCancelReasonTrailingStopLossOnFillGtdTimestampInPast: {
+		"The Order was cancelled because the GTD time of its Trailing Stop Loss on fill was in the past.",
+		"Use a GTD time in the future for the Trailing Stop Loss."},
+	OrderCancelReasonClientTradeIdAlreadyExists: {
+		"The Order was cancelled because the client Trade ID it specified is already in use.",
+		"Use a unique client Trade ID."},
+	OrderCancelReasonPositionCloseoutFailed: {
+		"The Order was cancelled because the position closeout it requested failed.",
+		"Check that the position exists and retry."},
+	OrderCancelReasonOpenTradesAllowedExceeded: {
+		"The Order was cancelled because filling it would have exceeded the number of open Trades allowed.",
+		"Close some Trades before opening new ones."},
+	OrderCancelReasonPendingOrdersAllowedExceeded: {
+		"The Order was cancelled because filling it would have exceeded the number of pending Orders allowed.",
+		"Cancel some pending Orders first."},
+	OrderCancelReasonTakeProfitOnFillClientOrderIdAlreadyExists: {
+		"The Order was cancelled because the client Order ID of its Take Profit on fill is already in use.",
+		"Use a unique client Order ID for the Take Profit."},
+	OrderCancelReasonStopLossOnFillClientOrderIdAlreadyExists: {
+		"The Order was cancelled because the client Order ID of its Stop Loss on fill is already in use.",
+		"Use a unique client Order ID for the Stop Loss."},
+	OrderCancelReasonTrailingStopLossOnFillClientOrderIdAlreadyExists: {
+		"The Order was cancelled because the client Order ID of its Trailing Stop Loss on fill is already in use.",
+		"Use a unique client Order ID for the Trailing Stop Loss."},
+	OrderCancelReasonPositionSizeExceeded: {
+		"The Order was cancelled because filling it would have exceeded the maximum position size.",
+		"Reduce the Order's units."},
+	OrderCancelReasonHedgingGsloViolation: {
+		"The Order was cancelled because it would have created a hedged position while a guaranteed Stop Loss exists.",
+		"Reduce the existing position instead of hedging it."},
+	OrderCancelReasonAccountPositionValueLimitExceeded: {
+		"The Order was cancelled because filling it would have exceeded the Account's position value limit.",
+		"Reduce the Order's units or close other positions."},
+	OrderCancelReasonInstrumentBidReduceOnly: {
+		"The Order was cancelled because the Instrument's bid side only accepts Orders that reduce positions.",
+		"Only reduce existing positions in the Instrument."},
+	OrderCancelReasonInstrumentAskReduceOnly: {
+		"The Order was cancelled because the Instrument's ask side only accepts Orders that reduce positions.",
+		"Only reduce existing positions in the Instrument."},
+	OrderCancelReasonInstrumentBidHalted: {
+		"The Order was cancelled because trading on the Instrument's bid side is halted.",
+		"Retry once trading resumes."},
+	OrderCancelReasonInstrumentAskHalted: {
+		"The Order was cancelled because trading on the Instrument's ask side is halted.",
+		"Retry once trading resumes."},
+	OrderCancelReasonStopLossOnFillGuaranteedBidHalted: {
+		"The Order was cancelled because guaranteed Stop Losses on the Instrument's bid side are halted.",
+		"Retry once trading resumes, or use a regular Stop Loss."},
+	OrderCancelReasonStopLossOnFillGuaranteedAskHalted: {
+		"The Order was cancelled because guaranteed Stop Losses on the Instrument's ask side are halted.",
+		"Retry once trading resumes, or use a regular Stop Loss."},
+	OrderCancelReasonGuaranteedStopLossOnFillBidHalted: {
+		"The Order was cancelled because Guaranteed Stop Loss Orders on the Instrument's bid side are halted.",
+		"Retry once trading resumes, or use a regular Stop Loss."},
+	OrderCancelReasonGuaranteedStopLossOnFillAskHalted: {
+		"The Order was cancelled because Guaranteed Stop Loss Orders on the Instrument's ask side are halted.",
+		"Retry once trading resumes, or use a regular Stop Loss."},
+}
+
+var transactionRejectReasonTexts = map[TransactionRejectReason]reasonText{
+	TransactionRejectReasonInternalServerError: {
+		"The request was rejected because of an internal server error at OANDA.",
+		"Retry the request; contact OANDA if it keeps happening."},
+	TransactionRejectReasonInstrumentPriceUnknown: {
+		"The request was rejected because there is no current price for the Instrument.",
+		"Retry when the market for the Instrument is open."},
+	TransactionRejectReasonAccountNotActive: {
+		"The request was rejected because the Account is not active.",
+		"Contact OANDA to activate the Account."},
+	TransactionRejectReasonAccountLocked: {
+		"The request was rejected because the Account is locked.",
+		"Contact OANDA to unlock the Account."},
+	TransactionRejectReasonAccountOrderCreationLocked: {
+		"The request was rejected because Order creation is locked for the Account.",
+		"Contact OANDA to lift the restriction."},
+	TransactionRejectReasonAccountConfigurationLocked: {
+		"The request was rejected because configuration changes are locked for the Account.",
+		"Contact OANDA to lift the restriction."},
+	TransactionRejectReasonAccountDepositLocked: {
+		"The request was rejected because deposits are locked for the Account.",
+		"Contact OANDA to lift the restriction."},
+	TransactionRejectReasonAccountWithdrawalLocked: {
+		"The request was rejected because withdrawals are locked for the Account.",
+		"Contact OANDA to lift the restriction."},
+	TransactionRejectReasonAccountOrderCancelLocked: {
+		"The request was rejected because Order cancellation is locked for the Account.",
+		"Contact OANDA to lift the restriction."},
+	TransactionRejectReasonInstrumentNotTradeable: {
+		"The request was rejected because the Instrument cannot be traded by the Account.",
+		"Check the Account's tradeable Instruments."},
+	TransactionRejectReasonPendingOrdersAllowedExceeded: {
+		"The request was rejected because the Account has reached the maximum number of pending Orders.",
+		"Cancel some pending Orders first."},
+	TransactionRejectReasonOrderIdUnspecified: {
+		"The request was rejected because no Order ID was given.",
+		"Specify the Order by ID or client ID."},
+	TransactionRejectReasonOrderDoesntExist: {
+		"The request was rejected because the Order does not exist.",
+		"Check the Order ID; the Order may have been filled or cancelled already."},
+	TransactionRejectReasonOrderIdentifierInconsistency: {
+		"The request was rejected because the Order ID and client Order ID refer to different Orders.",
+		"Specify the Order by one identifier only."},
+	TransactionRejectReasonTradeIdUnspecified: {
+		"The request was rejected because no Trade ID was given.",
+		"Specify the Trade by ID or client ID."},
+	TransactionRejectReasonTradeDoesntExist: {
+		"The request was rejected because the Trade does not exist.",
+		"Check the Trade ID; the Trade may have been closed already."},
+	TransactionRejectReasonTradeIdentifierInconsistency: {
+		"The request was rejected because the Trade ID and client Trade ID refer to different Trades.",
+		"Specify the Trade by one identifier only."},
+	TransactionRejectReasonInsufficientMargin: {
+		"The request was rejected because the Account does not have enough margin available.",
+		"Reduce the Order's units, close other positions or deposit funds."},
+	TransactionRejectReasonInstrumentMissing: {
+		"The request was rejected because no Instrument was given.",
+		"Set the Order's Instrument."},
+	TransactionRejectReasonInstrumentUnknown: {
+		"The request was rejected because the Instrument is unknown.",
+		"Check the Instrument name, e.g. EUR_USD."},
+	TransactionRejectReasonUnitsMissing: {
+		"The request was rejected because no units were given.",
+		"Set the Order's units."},
+	TransactionRejectReasonUnitsInvalid: {
+		"The request was rejected because the units are invalid.",
+		"Use a non-zero decimal number of units."},
+	TransactionRejectReasonUnitsPrecisionExceeded: {
+		"The request was rejected because the units have more decimal places than the Instrument allows.",
+		"Round the units to the Instrument's trade units precision."},
+	TransactionRejectReasonUnitsLimitExceeded: {
+		"The request was rejected because the units exceed the Instrument's maximum Order size.",
+		"Reduce the units or split the Order."},
+	TransactionRejectReasonUnitsMinimumNotMet: {
+		"The request was rejected because the units are below the Instrument's minimum trade size.",
+		"Increase the units to at least the minimum trade size."},
+	TransactionRejectReasonPriceInvalid: {
+		"The request was rejected because the price is invalid.",
+		"Use a positive decimal price."},
+	TransactionRejectReasonPricePrecisionExceeded: {
+		"The request was rejected because the price has more decimal places than the Instrument allows.",
+		"Round the price to the Instrument's display precision."},
+	TransactionRejectReasonPriceDistanceMissing: {
+		"The request was rejected because no price distance was given.",
+		"Set either a price or a distance."},
+	TransactionRejectReasonPriceDistanceInvalid: {
+		"The request was rejected because the price distance is invalid.",
+		"Use a positive decimal distance."},
+	TransactionRejectReasonPriceDistancePrecisionExceeded: {
+		"The request was rejected because the price distance has more decimal places than the Instrument allows.",
+		"Round the distance to the Instrument's display precision."},
+	TransactionRejectReasonPriceDistanceMaximumExceeded: {
+		"The request was rejected because the price distance is larger than allowed.",
+		"Use a smaller distance."},
+	TransactionRejectReasonPriceDistanceMinimumNotMet: {
+		"The request was rejected because the price distance is smaller than allowed.",
+		"Use a larger distance; see the Instrument's minimum distances."},
+	TransactionRejectReasonTimeInForceMissing: {
+		"The request was rejected because no time in force was given.",
+		"Set the Order's time in force."},
+	TransactionRejectReasonTimeInForceInvalid: {
+		"The request was rejected because the time in force is not valid for the Order type.",
+		"Market Orders accept FOK and IOC; pending Orders accept GTC, GTD and GFD."},
+	TransactionRejectReasonTimeInForceGtdTimestampMissing: {
+		"The request was rejected because the time in force is GTD but no GTD time was given.",
+		"Set the GTD time."},
+	TransactionRejectReasonTimeInForceGtdTimestampInPast: {
+		"The request was rejected because the GTD time is in the past.",
+		"Use a GTD time in the future."},
+	TransactionRejectReasonPriceBoundInvalid: {
+		"The request was rejected because the price bound is invalid.",
+		"Use a positive decimal price bound."},
+	TransactionRejectReasonPriceBoundPrecisionExceeded: {
+		"The request was rejected because the price bound has more decimal places than the Instrument allows.",
+		"Round the price bound to the Instrument's display precision."},
+	TransactionRejectReasonOrdersOnFillDuplicateClientOrderIds: {
+		"The request was rejected because Orders to be created on fill share a client Order ID.",
+		"Give each on-fill Order a unique client Order ID."},
+	TransactionRejectReasonTradeOnFillClientExtensionsNotSupported: {
+		"The request was rejected because Trade client extensions are not supported for this Order.",
+		"Remove the Trade client extensions."},
+	TransactionRejectReasonClientOrderIdInvalid: {
+		"The request was rejected because the client Order ID is invalid.",
+		"Use a client Order ID of at most 128 characters."},
+	TransactionRejectReasonClientOrderIdAlreadyExists: {
+		"The request was rejected because the client Order ID is already in use.",
+		"Use a unique client Order ID."},
+	TransactionRejectReasonClientOrderTagInvalid: {
+		"The request was rejected because the client Order tag is invalid.",
+		"Use a tag of at most 128 characters."},
+	TransactionRejectReasonClientOrderCommentInvalid: {
+		"The request was rejected because the client Order comment is invalid.",
+		"Use a comment of at most 128 characters."},
+	TransactionRejectReasonClientTradeIdInvalid: {
+		"The request was rejected because the client Trade ID is invalid.",
+		"Use a client Trade ID of at most 128 characters."},
+	TransactionRejectReasonClientTradeIdAlreadyExists: {
+		"The request was rejected because the client Trade ID is already in use.",
+		"Use a unique client Trade ID."},
+	TransactionRejectReasonClientTradeTagInvalid: {
+		"The request was rejected because the client Trade tag is invalid.",
+		"Use a tag of at most 128 characters."},
+	TransactionRejectReasonClientTradeCommentInvalid: {
+		"The request was rejected because the client Trade comment is invalid.",
+		"Use a comment of at most 128 characters."},
+	TransactionRejectReasonOrderFillPositionActionMissing: {
+		"The request was rejected because no position fill was given.",
+		"Set the Order's position fill, e.g. DEFAULT."},
+	TransactionRejectReasonOrderFillPositionActionInvalid: {
+		"The request was rejected because the position fill is invalid.",
+		"Use DEFAULT, OPEN_ONLY, REDUCE_FIRST or REDUCE_ONLY."},
+	TransactionRejectReasonTriggerConditionMissing: {
+		"The request was rejected because no trigger condition was given.",
+		"Set the Order's trigger condition, e.g. DEFAULT."},
+	TransactionRejectReasonTriggerConditionInvalid: {
+		"The request was rejected because the trigger condition is invalid.",
+		"Use DEFAULT, INVERSE, BID, ASK or MID."},
+	TransactionRejectReasonTakeProfitOrderAlreadyExists: {
+		"The request was rejected because the Trade already has a Take Profit Order.",
+		"Replace the existing Take Profit Order instead."},
+	TransactionRejectReasonStopLossOrderAlreadyExists: {
+		"The request was rejected because the Trade already has a Stop Loss Order.",
+		"Replace the existing Stop Loss Order instead."},
+	TransactionRejectReasonGuaranteedStopLossOrderAlreadyExists: {
+		"The request was rejected because the Trade already has a Guaranteed Stop Loss Order.",
+		"Replace the existing Guaranteed Stop Loss Order instead."},
+	TransactionRejectReasonTrailingStopLossOrderAlreadyExists: {
+		"The request was rejected because the Trade already has a Trailing Stop Loss Order.",
+		"Replace the existing Trailing Stop Loss Order instead."},
+	TransactionRejectReasonCloseTradeTypeMissing: {
+		"The request was rejected because it did not say how much of the Trade to close.",
+		"Set the units to close, or ALL."},
+	TransactionRejectReasonCloseTradePartialUnitsMissing: {
+		"The request was rejected because the units of a partial Trade close were missing.",
+		"Set the units to close."},
+	TransactionRejectReasonCloseTradeUnitsExceedTradeSize: {
+		"The request was rejected because the units to close exceed the Trade's size.",
+		"Close at most the Trade's current units, or use ALL."},
+	TransactionRejectReasonCloseoutPositionDoesntExist: {
+		"The request was rejected because the position to close out does not exist.",
+		"Check the Instrument and the side of the position."},
+	TransactionRejectReasonCloseoutPositionIncompleteSpecification: {
+		"The request was rejected because the position closeout was not fully specified.",
+		"Set the Instrument and the units to close out."},
+	TransactionRejectReasonCloseoutPositionUnitsExceedPositionSize: {
+		"The request was rejected because the units to close out exceed the position's size.",
+		"Close out at most the position's units, or use ALL."},
+	TransactionRejectReasonCloseoutPositionReject: {
+		"The request was rejected because the position closeout was rejected.",
+		"Check the position and retry."},
+	TransactionRejectReasonCloseoutPositionPartialUnitsMissing: {
+		"The request was rejected because the units of a partial position closeout were missing.",
+		"Set the units to close out."},
+	TransactionRejectReasonMarkupGroupIdInvalid: {
+		"The request was rejected because the markup group ID is invalid.", ""},
+	TransactionRejectReasonPositionAggregationModeInvalid: {
+		"The request was rejected because the position aggregation mode is invalid.", ""},
+	TransactionRejectReasonAdminConfigureDataMissing: {
+		"The request was rejected because the administrative configuration data was missing.", ""},
+	TransactionRejectReasonMarginRateInvalid: {
+		"The request was rejected because the margin rate is invalid.",
+		"Use a margin rate the Account is allowed to use."},
+	TransactionRejectReasonMarginRateWouldTriggerCloseout: {
+		"The request was rejected because the new margin rate would trigger a margin closeout.",
+		"Reduce open positions before changing the margin rate."},
+	TransactionRejectReasonAliasInvalid: {
+		"The request was rejected because the Account alias is invalid.",
+		"Use a shorter alias."},
+	TransactionRejectReasonClientConfigureDataMissing: {
+		"The request was rejected because no configuration was given.",
+		"Set the alias or the margin rate to change."},
+	TransactionRejectReasonMarginRateWouldTriggerMarginCall: {
+		"The request was rejected because the new margin rate would trigger a margin call.",
+		"Reduce open positions before changing the margin rate."},
+	TransactionRejectReasonAmountInvalid: {
+		"The request was rejected because the funding amount is invalid.", ""},
+	TransactionRejectReasonInsufficientFunds: {
+		"The request was rejected because the Account does not have enough funds.", ""},
+	TransactionRejectReasonAmountMissing: {
+		"The request was rejected because the funding amount was missing.", ""},
+	TransactionRejectReasonFundingReasonMissing: {
+		"The request was rejected because the funding reason was missing.", ""},
+	TransactionRejectReasonClientExtensionsDataMissing: {
+		"The request was rejected because no client extensions were given.",
+		"Set the client extensions to change."},
+	TransactionRejectReasonReplacingOrderInvalid: {
+		"The request was rejected because the replacing Order is invalid.",
+		"Check that the replacing Order has the same type as the Order it replaces."},
+	TransactionRejectReasonReplacingTradeIdInvalid: {
+		"The request was rejected because the replacing Order refers to a different Trade.",
+		"Keep the Trade ID of the Order being replaced."},
+	TransactionRejectReasonOrderCannotBeReplaced: {
+		"The request was rejected because the Order cannot be replaced.",
+		"Only pending Orders can be replaced; it may have been filled or cancelled already."},
+	TransactionRejectReasonOrderCannotBeCancelled: {
+		"The request was rejected because the Order cannot be cancelled.",
+		"Only pending Orders can be cancelled; it may have been filled or cancelled already."},
+}
@@ -0,0 +1,44 @@
+package oanda
+
+import "testing"
+
+func TestReason_Describe(t *testing.T) {
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{
+			name: "cancel reason",
+			got:  OrderCancelReasonClientRequest.Describe(),
+			want: "The Order was cancelled at the client's request.",
+		},
+		{
+			name: "cancel reason with remedy",
+			got:  OrderCancelReasonTimeInForceExpired.Describe(),
+			want: "The Order was cancelled because its time in force expired. Use a later GTD time or GTC to keep the Order pending longer.",
+		},
+		{
+			name: "reject reason with remedy",
+			got:  TransactionRejectReasonUnitsMinimumNotMet.Describe(),
+			want: "The request was rejected because the units are below the Instrument's minimum trade size. Increase the units to at least the minimum trade size.",
+		},
+		{
+			name: "unknown reason",
+			got:  TransactionRejectReason("SOME_NEW_REASON").Describe(),
+			want: "Some new reason.",
+		},
+		{
+			name: "empty reason",
+			got:  OrderCancelReason("").Describe(),
+			want: "No reason was given.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %q, want %q", tt.got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package oanda
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// OrderDecoder decodes the JSON of an Order into a concrete type.
+type OrderDecoder func(raw json.RawMessage) (Order, error)
+
+// TransactionDecoder decodes the JSON of a Transaction into a concrete type.
+type TransactionDecoder func(raw json.RawMessage) (Transaction, error)
+
+var registry = struct {
+	mu           sync.RWMutex
+	orders       map[OrderType]OrderDecoder
+	transactions map[TransactionType]TransactionDecoder
+}{
+	orders:       make(map[OrderType]OrderDecoder),
+	transactions: make(map[TransactionType]TransactionDecoder),
+}
+
+// RegisterOrderType registers decode as the decoder for Orders of the given type, allowing
+// applications to handle Order types that OANDA has added but the library does not know yet.
+// Decoders for the types the library knows are never consulted. Registering a type again
+// replaces its decoder, and a nil decoder removes it.
+func RegisterOrderType(orderType OrderType, decode OrderDecoder) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	if decode == nil {
+		delete(registry.orders, orderType)
+		return
+	}
+	registry.orders[orderType] = decode
+}
+
+// RegisterTransactionType registers decode as the decoder for Transactions of the given type,
+// allowing applications to handle Transaction types that OANDA has added but the library does
+// not know yet. Decoders for the types the library knows are never consulted. Registering a type
+// again replaces its decoder, and a nil decoder removes it.
+//
+// On the transaction stream, a decoded Transaction is delivered as is if it also implements
+// [TransactionStreamItem], and as an [UnknownTransaction] otherwise.
+func RegisterTransactionType(transactionType TransactionType, decode TransactionDecoder) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	if decode == nil {
+		delete(registry.transactions, transactionType)
+		return
+	}
+	registry.transactions[transactionType] = decode
+}
+
+func registeredOrderDecoder(orderType OrderType) (OrderDecoder, bool) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	decode, ok := registry.orders[orderType]
+	return decode, ok
+}
+
+func registeredTransactionDecoder(transactionType TransactionType) (TransactionDecoder, bool) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	decode, ok := registry.transactions[transactionType]
+	return decode, ok
+}
+
+// UnknownOrder is an Order of a type that the library does not know and for which no decoder
+// has been registered with [RegisterOrderType]. Only the fields common to all Orders are
+// decoded; the complete Order is available in RawJSON.
+type UnknownOrder struct {
+	OrderBase
+}
+
+func (o UnknownOrder) GetID() OrderID {
+	return o.ID
+}
+
+func (o UnknownOrder) GetCreateTime() DateTime {
+	return o.CreateTime
+}
+
+func (o UnknownOrder) GetState() OrderState {
+	return o.State
+}
+
+func (o UnknownOrder) GetClientExtensions() *ClientExtensions {
+	return o.ClientExtensions
+}
+
+func (o UnknownOrder) GetType() OrderType {
+	return o.Type
+}
+
+// UnknownTransaction is a Transaction of a type that the library does not know and for which no
+// decoder has been registered with [RegisterTransactionType]. Only the fields common to all
+// Transactions are decoded; the complete Transaction is available in RawJSON. REST responses
+// hold a *UnknownTransaction and the transaction stream delivers an UnknownTransaction value, so
+// that new Transaction types are neither dropped nor mistaken for gaps in the Transaction IDs.
+type UnknownTransaction struct {
+	TransactionBase
+}
+
+// unmarshalUnknownOrder decodes an Order of a type the library does not know, using the
+// registered decoder if there is one.
+func unmarshalUnknownOrder(orderType OrderType, rawOrder json.RawMessage) (Order, error) {
+	if decode, ok := registeredOrderDecoder(orderType); ok {
+		order, err := decode(rawOrder)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal %s order: %w", orderType, err)
+		}
+		return order, nil
+	}
+	var unknownOrder UnknownOrder
+	if err := json.Unmarshal(rawOrder, &unknownOrder); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal unknown order: %w", err)
+	}
+	unknownOrder.setRawJSON(rawOrder)
+	return unknownOrder, nil
+}
+
+// unmarshalUnknownTransaction decodes a Transaction of a type the library does not know, using
+// the registered decoder if there is one.
+func unmarshalUnknownTransaction(transactionType TransactionType, rawTransaction json.RawMessage) (Transaction, error) {
+	if decode, ok := registeredTransactionDecoder(transactionType); ok {
+		transaction, err := decode(rawTransaction)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal %s transaction: %w", transactionType, err)
+		}
+		return transaction, nil
+	}
+	var unknownTransaction UnknownTransaction
+	if err := json.Unmarshal(rawTransaction, &unknownTransaction); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal unknown transaction: %w", err)
+	}
+	return &unknownTransaction, nil
+}
@@ -0,0 +1,104 @@
+package oanda
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+type boxOrder struct {
+	UnknownOrder
+	Upper PriceValue `json:"upper"`
+}
+
+type boxOrderTransaction struct {
+	TransactionBase
+	Upper PriceValue `json:"upper"`
+}
+
+func TestRegistry(t *testing.T) {
+	rawOrder := []byte(`{"type":"BOX","id":"5","state":"PENDING","upper":"1.20000"}`)
+	rawTransaction := []byte(`{"type":"BOX_ORDER","id":"6","upper":"1.20000"}`)
+
+	order, err := unmarshalOrder(rawOrder)
+	if err != nil {
+		t.Fatalf("failed to unmarshal order: %v", err)
+	}
+	unknownOrder, ok := order.(UnknownOrder)
+	if !ok {
+		t.Fatalf("got %T, want UnknownOrder", order)
+	}
+	if unknownOrder.GetID() != "5" || !strings.Contains(string(unknownOrder.RawJSON), `"upper"`) {
+		t.Errorf("got order %s with raw JSON %s", unknownOrder.GetID(), unknownOrder.RawJSON)
+	}
+	transaction, err := unmarshalTransaction(rawTransaction)
+	if err != nil {
+		t.Fatalf("failed to unmarshal transaction: %v", err)
+	}
+	if unknown, ok := transaction.(*UnknownTransaction); !ok || unknown.GetID() != "6" || unknown.RawJSON == nil {
+		t.Errorf("got %#v, want *UnknownTransaction with ID 6 and raw JSON", transaction)
+	}
+	if item, ok, err := parseTransactionStreamItem(rawTransaction); err != nil || !ok {
+		t.Errorf("failed to parse stream item: ok=%v err=%v", ok, err)
+	} else if unknown, ok := item.(UnknownTransaction); !ok || unknown.GetID() != "6" || unknown.RawJSON == nil || unknown.GetReceivedAt().IsZero() {
+		t.Errorf("got stream item %#v, want UnknownTransaction with ID 6, raw JSON and received time", item)
+	}
+
+	RegisterOrderType("BOX", func(raw json.RawMessage) (Order, error) {
+		var o boxOrder
+		err := json.Unmarshal(raw, &o)
+		return o, err
+	})
+	t.Cleanup(func() { RegisterOrderType("BOX", nil) })
+	RegisterTransactionType("BOX_ORDER", func(raw json.RawMessage) (Transaction, error) {
+		var t boxOrderTransaction
+		err := json.Unmarshal(raw, &t)
+		return &t, err
+	})
+	t.Cleanup(func() { RegisterTransactionType("BOX_ORDER", nil) })
+
+	order, err = unmarshalOrder(rawOrder)
+	if err != nil {
+		t.Fatalf("failed to unmarshal order: %v", err)
+	}
+	if box, ok := order.(boxOrder); !ok || box.Upper != "1.20000" {
+		t.Errorf("got %#v, want boxOrder with upper 1.20000", order)
+	}
+	transaction, err = unmarshalTransaction(rawTransaction)
+	if err != nil {
+		t.Fatalf("failed to unmarshal transaction: %v", err)
+	}
+	if box, ok := transaction.(*boxOrderTransaction); !ok || box.Upper != "1.20000" || box.RawJSON == nil {
+		t.Errorf("got %#v, want *boxOrderTransaction with upper 1.20000 and raw JSON", transaction)
+	}
+	item, ok, err := parseTransactionStreamItem(rawTransaction)
+	if err != nil || !ok {
+		t.Fatalf("failed to parse stream item: ok=%v err=%v", ok, err)
+	}
+	if box, ok := item.(*boxOrderTransaction); !ok || box.GetReceivedAt().IsZero() {
+		t.Errorf("got %#v, want *boxOrderTransaction with received time", item)
+	}
+}
+
+func TestOrderCreateResponse_UnknownTransaction(t *testing.T) {
+	var resp OrderCreateResponse
+	if err := json.Unmarshal([]byte(`{"orderCreateTransaction":{"type":"BOX_ORDER","id":"6","upper":"1.20000"},
+		"lastTransactionID":"6"}`), &resp); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	unknown := resp.UnknownTransactions["orderCreateTransaction"]
+	if resp.OrderCreateTransaction != nil || unknown == nil || unknown.GetID() != "6" ||
+		!strings.Contains(string(unknown.RawJSON), `"upper"`) {
+		t.Errorf("got transaction %v and unknown transactions %v, want the BOX_ORDER kept as unknown",
+			resp.OrderCreateTransaction, resp.UnknownTransactions)
+	}
+
+	var errResp OrderErrorResponse
+	if err := json.Unmarshal([]byte(`{"orderRejectTransaction":{"type":"BOX_ORDER_REJECT","id":"7"},
+		"errorCode":"BOX_INVALID"}`), &errResp); err != nil {
+		t.Fatalf("failed to unmarshal error response: %v", err)
+	}
+	if unknown := errResp.UnknownTransactions["orderRejectTransaction"]; unknown == nil || unknown.GetID() != "7" {
+		t.Errorf("got unknown transactions %v, want the BOX_ORDER_REJECT kept", errResp.UnknownTransactions)
+	}
+}
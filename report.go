@@ -0,0 +1,267 @@
+package oanda
+
+import (
+	"iter"
+	"math/big"
+	"time"
+)
+
+// PLTotals is the realized profit and loss of a set of Order fills, in the Account's home
+// currency.
+type PLTotals struct {
+	// Fills is the number of fills.
+	Fills int
+	// RealizedPL is the profit or loss realized by closing or reducing Trades.
+	RealizedPL AccountUnits
+	// Commission is the commission charged.
+	Commission AccountUnits
+	// Financing is the financing paid (negative) or collected (positive) when closing or reducing
+	// Trades.
+	Financing AccountUnits
+	// GuaranteedExecutionFee is the fee charged for guaranteed Stop Losses.
+	GuaranteedExecutionFee AccountUnits
+	// Net is RealizedPL plus Financing, less Commission and GuaranteedExecutionFee: the change in
+	// the Account balance caused by the fills.
+	Net AccountUnits
+}
+
+// PLReport is the realized profit and loss of Order fills, in total and broken down by
+// Instrument, by day and by client tag. Build one with a [PLReportBuilder].
+type PLReport struct {
+	// Total is the profit and loss of all fills.
+	Total PLTotals
+	// ByInstrument is the profit and loss of the fills of each Instrument.
+	ByInstrument map[InstrumentName]PLTotals
+	// ByDay is the profit and loss of the fills of each day, keyed by date in YYYY-MM-DD format
+	// in the builder's location.
+	ByDay map[string]PLTotals
+	// ByTag is the profit and loss of the Trades with each client tag. The amounts of a fill are
+	// attributed to the tags of the Trades it opens, closes or reduces; its commission to the tag
+	// of the first of them. Trades without a tag, or whose tag is unknown, are reported under the
+	// empty tag.
+	ByTag map[ClientTag]PLTotals
+}
+
+// PLReportBuilder aggregates OrderFillTransactions into a [PLReport]. Other Transactions are
+// used only to follow the client tags of Trades. Create one with [NewPLReportBuilder] and add
+// Transactions in ID order.
+type PLReportBuilder struct {
+	location     *time.Location
+	tags         map[TradeID]ClientTag
+	total        plAccumulator
+	byInstrument map[InstrumentName]*plAccumulator
+	byDay        map[string]*plAccumulator
+	byTag        map[ClientTag]*plAccumulator
+}
+
+// NewPLReportBuilder creates a new, empty PLReportBuilder that splits days in UTC.
+func NewPLReportBuilder() *PLReportBuilder {
+	return &PLReportBuilder{
+		location:     time.UTC,
+		tags:         make(map[TradeID]ClientTag),
+		byInstrument: make(map[InstrumentName]*plAccumulator),
+		byDay:        make(map[string]*plAccumulator),
+		byTag:        make(map[ClientTag]*plAccumulator),
+	}
+}
+
+// SetLocation sets the location in which days are split, such as the trading day's time zone.
+func (b *PLReportBuilder) SetLocation(location *time.Location) *PLReportBuilder {
+	b.location = location
+	return b
+}
+
+// SetTradeTag sets the client tag of a Trade opened before the first added Transaction, whose
+// tag the builder cannot learn from the fill that opened it.
+func (b *PLReportBuilder) SetTradeTag(tradeID TradeID, tag ClientTag) *PLReportBuilder {
+	b.tags[tradeID] = tag
+	return b
+}
+
+// Add adds transaction to the report. It fails if an amount of a fill is not a valid decimal
+// number, in which case the report is left unchanged.
+func (b *PLReportBuilder) Add(transaction Transaction) error {
+	switch t := transaction.(type) {
+	case OrderFillTransaction:
+		return b.addFill(&t)
+	case *OrderFillTransaction:
+		return b.addFill(t)
+	case TradeClientExtensionsModifyTransaction:
+		b.modifyTag(&t)
+	case *TradeClientExtensionsModifyTransaction:
+		b.modifyTag(t)
+	}
+	return nil
+}
+
+// AddAll adds every Transaction of transactions, such as the iterator returned by
+// [transactionService.Iterate]. It stops at the first error.
+func (b *PLReportBuilder) AddAll(transactions iter.Seq2[Transaction, error]) error {
+	for transaction, err := range transactions {
+		if err != nil {
+			return err
+		}
+		if err := b.Add(transaction); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *PLReportBuilder) modifyTag(t *TradeClientExtensionsModifyTransaction) {
+	if tag := t.TradeClientExtensionsModify.Tag; tag != nil {
+		b.tags[t.TradeID] = *tag
+	}
+}
+
+// tradeAmounts are the amounts of a fill attributed to one Trade.
+type tradeAmounts struct {
+	tag                    ClientTag
+	realizedPL             AccountUnits
+	financing              AccountUnits
+	guaranteedExecutionFee AccountUnits
+}
+
+func (b *PLReportBuilder) addFill(fill *OrderFillTransaction) error {
+	amounts, err := parseAmounts(fill.PL, fill.Commission, fill.Financing, fill.GuaranteedExecutionFee)
+	if err != nil {
+		return err
+	}
+
+	var trades []tradeAmounts
+	reduces := fill.TradesClosed
+	if fill.TradeReduced != nil {
+		reduces = append(reduces[:len(reduces):len(reduces)], *fill.TradeReduced)
+	}
+	for _, reduce := range reduces {
+		trades = append(trades, tradeAmounts{b.tags[reduce.TradeID], reduce.RealizedPL, reduce.Financing, reduce.GuaranteedExecutionFee})
+	}
+	var openedTag ClientTag
+	if opened := fill.TradeOpened; opened != nil {
+		if opened.ClientExtensions != nil && opened.ClientExtensions.Tag != nil {
+			openedTag = *opened.ClientExtensions.Tag
+		}
+		trades = append(trades, tradeAmounts{tag: openedTag, guaranteedExecutionFee: opened.GuaranteedExecutionFee})
+	}
+	tagAmounts := make([][4]plAmount, len(trades))
+	for i, trade := range trades {
+		var commission AccountUnits
+		if i == 0 {
+			commission = fill.Commission
+		}
+		if tagAmounts[i], err = parseAmounts(trade.realizedPL, commission, trade.financing, trade.guaranteedExecutionFee); err != nil {
+			return err
+		}
+	}
+
+	if opened := fill.TradeOpened; opened != nil && openedTag != "" {
+		b.tags[opened.TradeID] = openedTag
+	}
+	b.total.add(amounts)
+	entry(b.byInstrument, fill.Instrument).add(amounts)
+	day := ""
+	if fill.Time.Time != nil {
+		day = fill.Time.In(b.location).Format(time.DateOnly)
+	}
+	entry(b.byDay, day).add(amounts)
+	if len(trades) == 0 {
+		entry(b.byTag, "").add(amounts)
+	}
+	counted := make(map[ClientTag]bool)
+	for i, trade := range trades {
+		a := entry(b.byTag, trade.tag)
+		a.addAmounts(tagAmounts[i])
+		if !counted[trade.tag] {
+			counted[trade.tag] = true
+			a.fills++
+		}
+	}
+	return nil
+}
+
+// Report returns the report of the Transactions added so far.
+func (b *PLReportBuilder) Report() *PLReport {
+	report := &PLReport{
+		Total:        b.total.totals(),
+		ByInstrument: make(map[InstrumentName]PLTotals, len(b.byInstrument)),
+		ByDay:        make(map[string]PLTotals, len(b.byDay)),
+		ByTag:        make(map[ClientTag]PLTotals, len(b.byTag)),
+	}
+	for instrument, a := range b.byInstrument {
+		report.ByInstrument[instrument] = a.totals()
+	}
+	for day, a := range b.byDay {
+		report.ByDay[day] = a.totals()
+	}
+	for tag, a := range b.byTag {
+		report.ByTag[tag] = a.totals()
+	}
+	return report
+}
+
+// plAmount is an exact amount together with the number of decimal places it was reported with.
+type plAmount struct {
+	value  *big.Rat
+	places int
+}
+
+// parseAmounts parses the realized P/L, commission, financing and guaranteed execution fee of a
+// fill. Empty amounts are zero.
+func parseAmounts(amounts ...AccountUnits) ([4]plAmount, error) {
+	var parsed [4]plAmount
+	for i, amount := range amounts {
+		if amount == "" {
+			parsed[i] = plAmount{value: new(big.Rat)}
+			continue
+		}
+		r, err := amount.Rat()
+		if err != nil {
+			return parsed, err
+		}
+		parsed[i] = plAmount{value: r, places: fractionDigits(string(amount))}
+	}
+	return parsed, nil
+}
+
+// plAccumulator sums the amounts of fills exactly.
+type plAccumulator struct {
+	fills   int
+	amounts [4]big.Rat
+	places  int
+}
+
+// entry returns the value of key in m, adding a zero value if there is none.
+func entry[K comparable, V any](m map[K]*V, key K) *V {
+	v, ok := m[key]
+	if !ok {
+		v = new(V)
+		m[key] = v
+	}
+	return v
+}
+
+func (a *plAccumulator) add(amounts [4]plAmount) {
+	a.fills++
+	a.addAmounts(amounts)
+}
+
+func (a *plAccumulator) addAmounts(amounts [4]plAmount) {
+	for i, amount := range amounts {
+		a.amounts[i].Add(&a.amounts[i], amount.value)
+		a.places = max(a.places, amount.places)
+	}
+}
+
+func (a *plAccumulator) totals() PLTotals {
+	net := new(big.Rat).Add(&a.amounts[0], &a.amounts[2])
+	net.Sub(net, &a.amounts[1])
+	net.Sub(net, &a.amounts[3])
+	return PLTotals{
+		Fills:                  a.fills,
+		RealizedPL:             AccountUnits(a.amounts[0].FloatString(a.places)),
+		Commission:             AccountUnits(a.amounts[1].FloatString(a.places)),
+		Financing:              AccountUnits(a.amounts[2].FloatString(a.places)),
+		GuaranteedExecutionFee: AccountUnits(a.amounts[3].FloatString(a.places)),
+		Net:                    AccountUnits(net.FloatString(a.places)),
+	}
+}
@@ -0,0 +1,64 @@
+package oanda
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPLReportBuilder(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*60*60)
+	builder := NewPLReportBuilder().SetLocation(tokyo).SetTradeTag("1", "old")
+	for _, line := range []string{
+		`{"type":"ORDER_FILL","id":"10","time":"2024-01-02T10:00:00Z","instrument":"EUR_USD","units":"100","pl":"0.0000","commission":"0.0100","financing":"0.0000","guaranteedExecutionFee":"0.0500",
+			"tradeOpened":{"tradeID":"10","units":"100","guaranteedExecutionFee":"0.0500","clientExtensions":{"tag":"scalp"}}}`,
+		`{"type":"ORDER_FILL","id":"11","time":"2024-01-02T16:00:00Z","instrument":"USD_JPY","units":"-50","pl":"2.50","commission":"0","financing":"-0.10","guaranteedExecutionFee":"0",
+			"tradesClosed":[{"tradeID":"1","units":"-50","realizedPL":"2.50","financing":"-0.10"}]}`,
+		`{"type":"TRADE_CLIENT_EXTENSIONS_MODIFY","id":"12","tradeID":"10","tradeClientExtensionsModify":{"tag":"swing"}}`,
+		`{"type":"ORDER_FILL","id":"13","time":"2024-01-03T01:00:00Z","instrument":"EUR_USD","units":"-100","pl":"-1.2500","commission":"0.0100","financing":"0.0200","guaranteedExecutionFee":"0.0000",
+			"tradesClosed":[{"tradeID":"10","units":"-100","realizedPL":"-1.2500","financing":"0.0200"}]}`,
+		`{"type":"DAILY_FINANCING","id":"14","financing":"-5.0000"}`,
+	} {
+		transaction, err := unmarshalTransaction([]byte(line))
+		if err != nil {
+			t.Fatalf("failed to decode transaction: %v", err)
+		}
+		if err := builder.Add(transaction); err != nil {
+			t.Fatalf("failed to add transaction %s: %v", transaction.GetID(), err)
+		}
+	}
+	report := builder.Report()
+
+	want := PLTotals{Fills: 3, RealizedPL: "1.2500", Commission: "0.0200", Financing: "-0.0800", GuaranteedExecutionFee: "0.0500", Net: "1.1000"}
+	if report.Total != want {
+		t.Errorf("got total %+v, want %+v", report.Total, want)
+	}
+	if got := report.ByInstrument["EUR_USD"]; got.Fills != 2 || got.Net != "-1.3000" {
+		t.Errorf("got EUR_USD %+v, want 2 fills netting -1.3000", got)
+	}
+	if got := report.ByDay["2024-01-03"]; got.Fills != 2 || got.RealizedPL != "1.2500" {
+		t.Errorf("got 2024-01-03 %+v, want 2 fills realizing 1.2500 in Tokyo time", got)
+	}
+	if got := report.ByDay["2024-01-02"]; got.Fills != 1 || got.RealizedPL != "0.0000" {
+		t.Errorf("got 2024-01-02 %+v, want 1 fill realizing nothing", got)
+	}
+	for tag, want := range map[ClientTag]PLTotals{
+		"scalp": {Fills: 1, RealizedPL: "0.0000", Commission: "0.0100", Financing: "0.0000", GuaranteedExecutionFee: "0.0500", Net: "-0.0600"},
+		"swing": {Fills: 1, RealizedPL: "-1.2500", Commission: "0.0100", Financing: "0.0200", GuaranteedExecutionFee: "0.0000", Net: "-1.2400"},
+		"old":   {Fills: 1, RealizedPL: "2.50", Commission: "0.00", Financing: "-0.10", GuaranteedExecutionFee: "0.00", Net: "2.40"},
+	} {
+		if got := report.ByTag[tag]; got != want {
+			t.Errorf("got tag %s %+v, want %+v", tag, got, want)
+		}
+	}
+
+	invalid, err := unmarshalTransaction([]byte(`{"type":"ORDER_FILL","id":"15","pl":"abc"}`))
+	if err != nil {
+		t.Fatalf("failed to decode transaction: %v", err)
+	}
+	if err := builder.Add(invalid); err == nil {
+		t.Error("got no error for invalid amount")
+	}
+	if got := builder.Report().Total.Fills; got != 3 {
+		t.Errorf("got %d fills after invalid fill, want 3", got)
+	}
+}
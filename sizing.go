@@ -0,0 +1,188 @@
+package oanda
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+)
+
+// PositionSize returns the number of units of an Instrument that lose risk, in the Account's home
+// currency, if the price moves stopDistance against the Position. lossFactor converts an amount
+// of the Instrument's quote currency into the home currency; it is the Loss of the Instrument's
+// [QuoteHomeFactors]. The units are positive and
+// truncated to the Instrument's TradeUnitsPrecision, so that the loss never exceeds risk.
+func PositionSize(risk float64, stopDistance DecimalNumber, lossFactor float64, precision InstrumentPrecision) (DecimalNumber, error) {
+	distance, err := stopDistance.Float64()
+	if err != nil {
+		return "", err
+	}
+	if risk <= 0 || distance <= 0 || lossFactor <= 0 {
+		return "", errors.New("risk, stop distance and loss factor must be positive")
+	}
+	units := new(big.Rat).SetFloat64(risk / (distance * lossFactor))
+	if units == nil {
+		return "", fmt.Errorf("invalid position size for risk %v and stop distance %s", risk, stopDistance)
+	}
+	return DecimalNumber(truncateDecimal(units, precision.TradeUnitsPrecision)), nil
+}
+
+// PipValue returns the value of a one pip move of units of an Instrument, in the Account's home
+// currency. factor converts an amount of the Instrument's quote currency into the home currency,
+// such as the Gain or Loss of the Instrument's [QuoteHomeFactors].
+func PipValue(units DecimalNumber, precision InstrumentPrecision, factor float64) (float64, error) {
+	u, err := units.Rat()
+	if err != nil {
+		return 0, err
+	}
+	value, _ := new(big.Rat).Mul(new(big.Rat).Abs(u), precision.pip()).Float64()
+	return value * factor, nil
+}
+
+// PositionSizeRequest describes a risk-based position size: the Instrument, the distance to the
+// stop loss, and the amount to risk. Use [NewPositionSizeRequest] to create one, then set one of
+// the stop distances and one of the risks.
+type PositionSizeRequest struct {
+	instrument   InstrumentName
+	stopDistance *DecimalNumber
+	stopPips     *float64
+	riskAmount   *float64
+	riskPercent  *float64
+}
+
+// NewPositionSizeRequest creates a new PositionSizeRequest for instrument.
+func NewPositionSizeRequest(instrument InstrumentName) *PositionSizeRequest {
+	return &PositionSizeRequest{instrument: instrument}
+}
+
+// SetStopDistance sets the price distance to the stop loss, such as "0.0050".
+func (r *PositionSizeRequest) SetStopDistance(distance DecimalNumber) *PositionSizeRequest {
+	r.stopDistance = &distance
+	return r
+}
+
+// SetStopPips sets the distance to the stop loss in pips.
+func (r *PositionSizeRequest) SetStopPips(pips float64) *PositionSizeRequest {
+	r.stopPips = &pips
+	return r
+}
+
+// SetRiskAmount sets the amount to risk, in the Account's home currency.
+func (r *PositionSizeRequest) SetRiskAmount(amount float64) *PositionSizeRequest {
+	r.riskAmount = &amount
+	return r
+}
+
+// SetRiskPercent sets the amount to risk as a percentage of the Account's NAV, such as 1 for 1%.
+func (r *PositionSizeRequest) SetRiskPercent(percent float64) *PositionSizeRequest {
+	r.riskPercent = &percent
+	return r
+}
+
+func (r *PositionSizeRequest) validate() error {
+	if r.instrument == "" {
+		return errors.New("missing instrument")
+	}
+	if (r.stopDistance == nil) == (r.stopPips == nil) {
+		return errors.New("exactly one of stop distance and stop pips must be set")
+	}
+	if (r.riskAmount == nil) == (r.riskPercent == nil) {
+		return errors.New("exactly one of risk amount and risk percent must be set")
+	}
+	return nil
+}
+
+// PositionSizeResult is the result of [PositionSizer.Size].
+type PositionSizeResult struct {
+	// Units is the position size, positive and truncated to the Instrument's
+	// TradeUnitsPrecision. Negate it for a short Position.
+	Units DecimalNumber
+	// Risk is the loss of Units at the stop loss, in the Account's home currency. It is at most
+	// the requested risk.
+	Risk float64
+	// PipValue is the value of a one pip move of Units, in the Account's home currency.
+	PipValue float64
+	// LossFactor is the factor used to convert the Instrument's quote currency into the home
+	// currency.
+	LossFactor float64
+}
+
+// PositionSizer computes risk-based position sizes from the Account's NAV, the Instrument's
+// precision and the current home conversion factors. Create one with [NewPositionSizer].
+type PositionSizer struct {
+	client      *Client
+	instruments *InstrumentCache
+}
+
+// NewPositionSizer creates a new PositionSizer that reads the Account and prices with client and
+// Instrument precision from instruments.
+func NewPositionSizer(client *Client, instruments *InstrumentCache) *PositionSizer {
+	return &PositionSizer{client: client, instruments: instruments}
+}
+
+// Size computes the position size described by req. It returns an error if the size is below
+// the Instrument's minimum trade size.
+func (s *PositionSizer) Size(ctx context.Context, req *PositionSizeRequest) (*PositionSizeResult, error) {
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+	meta, err := s.instruments.Get(ctx, req.instrument)
+	if err != nil {
+		return nil, err
+	}
+	precision := meta.Precision()
+	risk := 0.0
+	if req.riskAmount != nil {
+		risk = *req.riskAmount
+	} else {
+		summary, err := s.client.Account.Summary(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get account summary: %w", err)
+		}
+		nav, err := summary.Account.NAV.Float64()
+		if err != nil {
+			return nil, err
+		}
+		risk = nav * *req.riskPercent / 100
+	}
+	distance := DecimalNumber("")
+	if req.stopDistance != nil {
+		distance = *req.stopDistance
+	} else {
+		pips, err := parseRat(strconv.FormatFloat(*req.stopPips, 'f', -1, 64))
+		if err != nil {
+			return nil, err
+		}
+		distance = DecimalNumber(pips.Mul(pips, precision.pip()).FloatString(precision.DisplayPrecision))
+	}
+
+	pricing, err := s.client.Price.Information(ctx,
+		NewPriceInformationRequest().AddInstruments(req.instrument).SetIncludeHomeConversions())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get price: %w", err)
+	}
+	factors, err := pricing.QuoteHomeFactors(req.instrument)
+	if err != nil {
+		return nil, err
+	}
+	lossFactor := factors.Loss
+	units, err := PositionSize(risk, distance, lossFactor, precision)
+	if err != nil {
+		return nil, err
+	}
+	minimum, err := meta.MinimumTradeSize.Rat()
+	if err != nil {
+		minimum = new(big.Rat)
+	}
+	if u, _ := units.Rat(); u.Sign() == 0 || u.Cmp(minimum) < 0 {
+		return nil, fmt.Errorf("position size %s is below the minimum trade size of %s", units, meta.MinimumTradeSize)
+	}
+	u, _ := units.Float64()
+	d, _ := distance.Float64()
+	pipValue, err := PipValue(units, precision, lossFactor)
+	if err != nil {
+		return nil, err
+	}
+	return &PositionSizeResult{Units: units, Risk: u * d * lossFactor, PipValue: pipValue, LossFactor: lossFactor}, nil
+}
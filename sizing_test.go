@@ -0,0 +1,62 @@
+package oanda
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPositionSizer_Size(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v3/accounts/1/summary", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `{"account":{"NAV":"10000.0000"}}`)
+	})
+	mux.HandleFunc("/v3/accounts/1/instruments", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `{"instruments":[
+			{"name":"EUR_USD","pipLocation":-4,"displayPrecision":5,"tradeUnitsPrecision":0,"minimumTradeSize":"1"},
+			{"name":"USD_JPY","pipLocation":-2,"displayPrecision":3,"tradeUnitsPrecision":0,"minimumTradeSize":"1"}]}`)
+	})
+	mux.HandleFunc("/v3/accounts/1/pricing", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("includeHomeConversions") != "true" {
+			t.Error("home conversions not requested")
+		}
+		_, _ = fmt.Fprint(w, `{"prices":[],"homeConversions":[
+			{"currency":"USD","accountGain":"1","accountLoss":"1","positionValue":"1"},
+			{"currency":"JPY","accountGain":"0.0066","accountLoss":"0.0067","positionValue":"0.00665"}]}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client := NewClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+	sizer := NewPositionSizer(client, NewInstrumentCache(client))
+
+	// 1% of 10000 over 20 pips of 0.0001 USD.
+	result, err := sizer.Size(t.Context(), NewPositionSizeRequest("EUR_USD").SetRiskPercent(1).SetStopPips(20))
+	if err != nil {
+		t.Fatalf("failed to size: %v", err)
+	}
+	if result.Units != "50000" || math.Abs(result.Risk-100) > 1e-9 || math.Abs(result.PipValue-5) > 1e-9 {
+		t.Errorf("got %+v, want 50000 units risking 100 at 5 per pip", result)
+	}
+
+	// 100 over 0.5 JPY converted at 0.0067 is 29850.7 units, truncated.
+	result, err = sizer.Size(t.Context(), NewPositionSizeRequest("USD_JPY").SetRiskAmount(100).SetStopDistance("0.500"))
+	if err != nil {
+		t.Fatalf("failed to size: %v", err)
+	}
+	if result.Units != "29850" || result.Risk > 100 || math.Abs(result.Risk-99.9975) > 1e-9 || result.LossFactor != 0.0067 {
+		t.Errorf("got %+v, want 29850 units risking 99.9975", result)
+	}
+
+	for _, req := range []*PositionSizeRequest{
+		NewPositionSizeRequest("EUR_USD").SetRiskAmount(100),
+		NewPositionSizeRequest("EUR_USD").SetStopPips(10),
+		NewPositionSizeRequest("EUR_USD").SetStopPips(10).SetStopDistance("0.001").SetRiskAmount(100),
+		NewPositionSizeRequest("EUR_USD").SetStopPips(1000000).SetRiskAmount(0.01),
+	} {
+		if _, err := sizer.Size(t.Context(), req); err == nil {
+			t.Errorf("got no error for %+v", req)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package oanda
+
+import "fmt"
+
+// SmoothCandles returns a copy of candles, in time order, normalized to smoothed candlesticks:
+// the open of each candlestick is the close of the one before it, as with
+// [CandlesticksRequest.SetSmooth], and its high and low are extended to include the open. The
+// API need not extend them, so candlesticks fetched smoothed can have an open outside of their
+// range; normalizing them too gives the same series whether or not the candlesticks were
+// fetched smoothed, apart from the first open, so that indicators and [CandleValidator] treat
+// them alike. Each price component is smoothed on its own, and the first candlestick keeps its
+// open. It returns an error if a price cannot be parsed.
+func SmoothCandles(candles []Candlestick) ([]Candlestick, error) {
+	smoothed := make([]Candlestick, len(candles))
+	for i, candle := range candles {
+		var prev [3]*CandlestickData
+		if i > 0 {
+			prev = [3]*CandlestickData{smoothed[i-1].Bid, smoothed[i-1].Ask, smoothed[i-1].Mid}
+		}
+		for j, data := range []**CandlestickData{&candle.Bid, &candle.Ask, &candle.Mid} {
+			if *data == nil {
+				continue
+			}
+			smooth := **data
+			if prev[j] != nil {
+				smooth.O = prev[j].C
+			}
+			if err := extendRange(&smooth); err != nil {
+				return nil, fmt.Errorf("invalid candlestick at %v: %w", candle.Time, err)
+			}
+			*data = &smooth
+		}
+		smoothed[i] = candle
+	}
+	return smoothed, nil
+}
+
+// extendRange extends the high and low of data to include its open.
+func extendRange(data *CandlestickData) error {
+	o, err := data.O.Rat()
+	if err != nil {
+		return err
+	}
+	h, err := data.H.Rat()
+	if err != nil {
+		return err
+	}
+	l, err := data.L.Rat()
+	if err != nil {
+		return err
+	}
+	if o.Cmp(h) > 0 {
+		data.H = data.O
+	}
+	if o.Cmp(l) < 0 {
+		data.L = data.O
+	}
+	return nil
+}
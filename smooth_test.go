@@ -0,0 +1,50 @@
+package oanda
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSmoothCandles(t *testing.T) {
+	start := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	candle := func(i int, o, h, l, c PriceValue) Candlestick {
+		at := start.Add(time.Duration(i) * time.Hour)
+		return Candlestick{Time: DateTime{&at}, Complete: true, Mid: &CandlestickData{O: o, H: h, L: l, C: c}}
+	}
+	raw := []Candlestick{
+		candle(0, "1.1000", "1.1020", "1.0990", "1.1010"),
+		candle(1, "1.1012", "1.1030", "1.1011", "1.1025"),
+		candle(2, "1.1020", "1.1022", "1.1000", "1.1005"),
+	}
+	// The API's smoothed candlesticks take the previous close as the open but keep their range.
+	api := []Candlestick{
+		candle(0, "1.1000", "1.1020", "1.0990", "1.1010"),
+		candle(1, "1.1010", "1.1030", "1.1011", "1.1025"),
+		candle(2, "1.1025", "1.1022", "1.1000", "1.1005"),
+	}
+	want := []Candlestick{
+		candle(0, "1.1000", "1.1020", "1.0990", "1.1010"),
+		candle(1, "1.1010", "1.1030", "1.1010", "1.1025"),
+		candle(2, "1.1025", "1.1025", "1.1000", "1.1005"),
+	}
+	for name, candles := range map[string][]Candlestick{"raw": raw, "api": api} {
+		got, err := SmoothCandles(candles)
+		if err != nil {
+			t.Fatalf("%s: failed to smooth: %v", name, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %+v, want %+v", name, got, want)
+		}
+		anomalies, err := NewCandleValidator(H1).SetAlignment(CandleAlignment{}).Validate(got)
+		if err != nil || len(anomalies) != 0 {
+			t.Errorf("%s: got anomalies %+v (%v)", name, anomalies, err)
+		}
+	}
+	if raw[1].Mid.O != "1.1012" {
+		t.Error("candles were modified")
+	}
+	if _, err := SmoothCandles([]Candlestick{candle(0, "x", "1", "1", "1")}); err == nil {
+		t.Error("got no error for an invalid price")
+	}
+}
@@ -0,0 +1,177 @@
+package oanda
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+	"time"
+)
+
+// SpreadStats summarizes the spreads of an Instrument, the best ask minus the best bid, over a
+// window of time. It is returned by [SpreadTracker.Stats].
+type SpreadStats struct {
+	// Instrument is the Instrument of the spreads.
+	Instrument InstrumentName
+	// Samples is the number of prices in the window.
+	Samples int
+	// Current is the spread of the latest price.
+	Current float64
+	// Mean is the mean spread.
+	Mean float64
+	// P95 is the 95th percentile of the spreads, by the nearest-rank method.
+	P95 float64
+	// Max is the widest spread.
+	Max float64
+}
+
+// SpreadEvent reports that the spread of an Instrument crossed the threshold set with
+// [SpreadTracker.SetThreshold].
+type SpreadEvent struct {
+	// Instrument is the Instrument whose spread crossed the threshold.
+	Instrument InstrumentName
+	// Time is the time of the price that crossed the threshold.
+	Time time.Time
+	// Spread is the spread of the price.
+	Spread float64
+	// Threshold is the threshold that was crossed.
+	Threshold float64
+	// Exceeded is true if the spread widened above the threshold, and false if it narrowed back
+	// to or below it.
+	Exceeded bool
+}
+
+// SpreadTracker keeps the spreads of the prices received on a pricing stream and computes
+// rolling statistics per Instrument, such as to pause a strategy while spreads widen around
+// news. Spreads are in price units, such as 0.00012 for 1.2 pips of EUR_USD. Windows are
+// measured in the time of the prices, not the local clock. A SpreadTracker is safe for
+// concurrent use. Create one with [NewSpreadTracker].
+type SpreadTracker struct {
+	retention  time.Duration
+	mu         sync.Mutex
+	samples    map[InstrumentName][]spreadSample
+	thresholds map[InstrumentName]float64
+	exceeded   map[InstrumentName]bool
+	onCross    func(SpreadEvent)
+}
+
+type spreadSample struct {
+	time   time.Time
+	spread float64
+}
+
+// NewSpreadTracker creates a new SpreadTracker that keeps the spreads of the last retention,
+// the longest window that [SpreadTracker.Stats] can summarize.
+func NewSpreadTracker(retention time.Duration) *SpreadTracker {
+	return &SpreadTracker{
+		retention:  retention,
+		samples:    make(map[InstrumentName][]spreadSample),
+		thresholds: make(map[InstrumentName]float64),
+		exceeded:   make(map[InstrumentName]bool),
+	}
+}
+
+// SetThreshold sets the spread of instrument above which the callback set with
+// [SpreadTracker.OnThreshold] is called.
+func (t *SpreadTracker) SetThreshold(instrument InstrumentName, threshold float64) *SpreadTracker {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.thresholds[instrument] = threshold
+	return t
+}
+
+// OnThreshold sets the function called when the spread of an Instrument with a threshold widens
+// above it, and again when it narrows back. The function is called synchronously by
+// [SpreadTracker.Add] and must not call the tracker's methods.
+func (t *SpreadTracker) OnThreshold(callback func(SpreadEvent)) *SpreadTracker {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onCross = callback
+	return t
+}
+
+// Add records the spread of price. Prices without a time, bid or ask are ignored.
+func (t *SpreadTracker) Add(price ClientPrice) error {
+	if price.Time.Time == nil || len(price.Bids) == 0 || len(price.Asks) == 0 {
+		return nil
+	}
+	value, err := price.Spread()
+	if err != nil {
+		return err
+	}
+	spread, err := value.Float64()
+	if err != nil {
+		return err
+	}
+	at := *price.Time.Time
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	samples := append(t.samples[price.Instrument], spreadSample{at, spread})
+	cutoff := at.Add(-t.retention)
+	i := 0
+	for i < len(samples) && samples[i].time.Before(cutoff) {
+		i++
+	}
+	t.samples[price.Instrument] = slices.Delete(samples, 0, i)
+
+	threshold, ok := t.thresholds[price.Instrument]
+	if !ok {
+		return nil
+	}
+	exceeded := spread > threshold
+	if exceeded != t.exceeded[price.Instrument] {
+		t.exceeded[price.Instrument] = exceeded
+		if t.onCross != nil {
+			t.onCross(SpreadEvent{Instrument: price.Instrument, Time: at, Spread: spread, Threshold: threshold, Exceeded: exceeded})
+		}
+	}
+	return nil
+}
+
+// Track records the prices received on a pricing stream channel, such as the one returned by
+// [PriceStream.Updates], until the channel is closed. Invalid prices are skipped; the errors for
+// them are returned joined.
+func (t *SpreadTracker) Track(items <-chan PriceStreamItem) error {
+	var errs []error
+	for item := range items {
+		if price, ok := item.(ClientPrice); ok {
+			if err := t.Add(price); err != nil {
+				errs = append(errs, fmt.Errorf("invalid price of %s: %w", price.Instrument, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Stats returns the statistics of the spreads of instrument over the window ending at its latest
+// price. A window longer than the tracker's retention covers the retention. It returns false if
+// no price of instrument has been recorded.
+func (t *SpreadTracker) Stats(instrument InstrumentName, window time.Duration) (SpreadStats, bool) {
+	t.mu.Lock()
+	samples := t.samples[instrument]
+	if len(samples) == 0 {
+		t.mu.Unlock()
+		return SpreadStats{}, false
+	}
+	cutoff := samples[len(samples)-1].time.Add(-window)
+	spreads := make([]float64, 0, len(samples))
+	for _, s := range samples {
+		if !s.time.Before(cutoff) {
+			spreads = append(spreads, s.spread)
+		}
+	}
+	t.mu.Unlock()
+
+	stats := SpreadStats{Instrument: instrument, Samples: len(spreads), Current: spreads[len(spreads)-1]}
+	var sum float64
+	for _, s := range spreads {
+		sum += s
+	}
+	stats.Mean = sum / float64(len(spreads))
+	slices.Sort(spreads)
+	stats.Max = spreads[len(spreads)-1]
+	stats.P95 = spreads[int(math.Ceil(0.95*float64(len(spreads))))-1]
+	return stats, true
+}
@@ -0,0 +1,56 @@
+package oanda
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSpreadTracker(t *testing.T) {
+	base := time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)
+	var events []string
+	tracker := NewSpreadTracker(time.Minute).SetThreshold("EUR_USD", 0.00030).OnThreshold(func(e SpreadEvent) {
+		events = append(events, fmt.Sprintf("%s %t %.5f", e.Time.Format("15:04:05"), e.Exceeded, e.Spread))
+	})
+	items := make(chan PriceStreamItem, 30)
+	// 20 prices a second apart with spreads rising from 1 to 11 pips and falling to 2, then one of 2 pips.
+	for i := 1; i <= 21; i++ {
+		at := base.Add(time.Duration(i) * time.Second)
+		spread := min(i, 22-i)
+		if i == 21 {
+			spread = 2
+		}
+		items <- ClientPrice{
+			Instrument: "EUR_USD", Time: DateTime{&at},
+			Bids: []PriceBucket{{Price: "1.10000"}},
+			Asks: []PriceBucket{{Price: PriceValue(fmt.Sprintf("%.5f", 1.1+float64(spread)/10000))}},
+		}
+	}
+	items <- PricingHeartbeat{Type: "HEARTBEAT"}
+	items <- ClientPrice{Instrument: "EUR_USD", Time: DateTime{&base}, Bids: []PriceBucket{{Price: "x"}}, Asks: []PriceBucket{{Price: "1"}}}
+	close(items)
+	if err := tracker.Track(items); err == nil || !strings.Contains(err.Error(), "EUR_USD") {
+		t.Errorf("got error %v, want one for the invalid price", err)
+	}
+
+	stats, ok := tracker.Stats("EUR_USD", time.Hour)
+	if !ok || stats.Samples != 21 || math.Abs(stats.Current-0.0002) > 1e-9 || math.Abs(stats.Max-0.0011) > 1e-9 {
+		t.Errorf("got %+v", stats)
+	}
+	if math.Abs(stats.Mean-0.0122/21) > 1e-9 || math.Abs(stats.P95-0.0010) > 1e-9 {
+		t.Errorf("got mean %v and p95 %v", stats.Mean, stats.P95)
+	}
+	stats, _ = tracker.Stats("EUR_USD", 3*time.Second)
+	if stats.Samples != 4 || math.Abs(stats.Max-0.0004) > 1e-9 {
+		t.Errorf("got %+v over 3s, want 4 samples up to 4 pips", stats)
+	}
+	if _, ok := tracker.Stats("USD_JPY", time.Minute); ok {
+		t.Error("got stats for an untracked instrument")
+	}
+	want := "10:00:04 true 0.00040,10:00:19 false 0.00030"
+	if strings.Join(events, ",") != want {
+		t.Errorf("got events %v, want %s", events, want)
+	}
+}
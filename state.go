@@ -0,0 +1,574 @@
+package oanda
+
+import (
+	"encoding/json"
+	"fmt"
+	"iter"
+	"math/big"
+	"slices"
+	"strconv"
+)
+
+// AccountState is a local mirror of the price-independent state of an Account: its balance and
+// lifetime totals, open Trades, pending Orders and Positions. It is built from a snapshot of the
+// Account and kept up to date by applying the Account's Transactions with
+// [AccountState.Apply], the way the server applies them, so that an application can follow its
+// Account from the transaction stream without polling. Price-dependent values, such as unrealized
+// P/L and margin used, are not mirrored.
+//
+// An AccountState is not safe for concurrent use.
+type AccountState struct {
+	// ID is the Account's identifier.
+	ID AccountID
+	// LastTransactionID is the ID of the last Transaction applied.
+	LastTransactionID TransactionID
+	// Balance is the balance of the Account.
+	Balance AccountUnits
+	// PL is the profit/loss realized over the lifetime of the Account.
+	PL AccountUnits
+	// ResettablePL is the profit/loss realized since the Account's resettable P/L was last reset.
+	ResettablePL AccountUnits
+	// Financing is the financing paid/collected over the lifetime of the Account.
+	Financing AccountUnits
+	// Commission is the commission paid over the lifetime of the Account.
+	Commission AccountUnits
+	// DividendAdjustment is the dividend adjustment paid or collected over the lifetime of the
+	// Account.
+	DividendAdjustment AccountUnits
+	// GuaranteedExecutionFees is the fees charged over the lifetime of the Account for guaranteed
+	// Stop Loss Orders.
+	GuaranteedExecutionFees AccountUnits
+	// Trades are the open Trades by ID.
+	Trades map[TradeID]*TradeSummary
+	// Orders are the pending Orders by ID.
+	Orders map[OrderID]Order
+	// Positions are the Positions by Instrument, including those without open Trades.
+	Positions map[InstrumentName]*Position
+}
+
+// NewAccountState creates a new AccountState from account, as returned by
+// [accountService.Details]. Transactions up to the Account's LastTransactionID are considered
+// applied.
+func NewAccountState(account *Account) *AccountState {
+	s := &AccountState{
+		ID:                      account.ID,
+		LastTransactionID:       account.LastTransactionID,
+		Balance:                 account.Balance,
+		PL:                      account.PL,
+		ResettablePL:            account.ResettablePL,
+		Financing:               account.Financing,
+		Commission:              account.Commission,
+		DividendAdjustment:      account.DividendAdjustment,
+		GuaranteedExecutionFees: account.GuaranteedExecutionFees,
+		Trades:                  make(map[TradeID]*TradeSummary, len(account.Trades)),
+		Orders:                  make(map[OrderID]Order, len(account.Orders)),
+		Positions:               make(map[InstrumentName]*Position, len(account.Positions)),
+	}
+	for _, trade := range account.Trades {
+		s.Trades[trade.ID] = &trade
+	}
+	for _, order := range account.Orders {
+		s.Orders[order.GetID()] = order
+	}
+	for _, position := range account.Positions {
+		s.Positions[position.Instrument] = &position
+	}
+	return s
+}
+
+// Apply applies transaction to the state. Transactions must be applied in ID order; those with an
+// ID not after LastTransactionID have already been applied and are ignored, so the transaction
+// stream can be applied from any point before the snapshot. If Apply fails, the state may be
+// partially updated and should be rebuilt from a new snapshot.
+func (s *AccountState) Apply(transaction Transaction) error {
+	id, err := ParseTransactionID(transaction.GetID())
+	if err != nil {
+		// Heartbeats and other stream items without an ID do not change the state.
+		return nil
+	}
+	if last, err := ParseTransactionID(s.LastTransactionID); err == nil && id <= last {
+		return nil
+	}
+	if err := DispatchTransaction(transaction, stateApplier{s: s}); err != nil {
+		return fmt.Errorf("failed to apply transaction %s: %w", transaction.GetID(), err)
+	}
+	s.LastTransactionID = transaction.GetID()
+	return nil
+}
+
+// ApplyAll applies every Transaction of transactions, such as the iterator returned by
+// [transactionService.Iterate]. It stops at the first error.
+func (s *AccountState) ApplyAll(transactions iter.Seq2[Transaction, error]) error {
+	for transaction, err := range transactions {
+		if err != nil {
+			return err
+		}
+		if err := s.Apply(transaction); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AccountStateMismatch is a field whose value in an [AccountState] differs from the server's.
+type AccountStateMismatch struct {
+	// Field is the name of the field, as in the JSON of an AccountSummary.
+	Field string
+	// Local is the value in the AccountState.
+	Local string
+	// Server is the value reported by the server.
+	Server string
+}
+
+// Reconcile compares the state with summary, as returned by [accountService.Summary], and returns
+// the fields whose values differ. Amounts are compared numerically. The state should have been
+// applied up to the summary's LastTransactionID; otherwise the difference in lastTransactionID
+// is reported first and the other differences may be due to it.
+func (s *AccountState) Reconcile(summary *AccountSummary) []AccountStateMismatch {
+	var mismatches []AccountStateMismatch
+	compare := func(field, local, server string) {
+		if local == server {
+			return
+		}
+		l, lerr := parseRat(local)
+		r, rerr := parseRat(server)
+		if lerr == nil && rerr == nil && l.Cmp(r) == 0 {
+			return
+		}
+		mismatches = append(mismatches, AccountStateMismatch{field, local, server})
+	}
+	openPositions := 0
+	for _, position := range s.Positions {
+		if isNonZero(position.Long.Units) || isNonZero(position.Short.Units) {
+			openPositions++
+		}
+	}
+	if s.LastTransactionID != summary.LastTransactionID {
+		mismatches = append(mismatches, AccountStateMismatch{"lastTransactionID", s.LastTransactionID, summary.LastTransactionID})
+	}
+	compare("balance", string(s.Balance), string(summary.Balance))
+	compare("pl", string(s.PL), string(summary.PL))
+	compare("resettablePL", string(s.ResettablePL), string(summary.ResettablePL))
+	compare("financing", string(s.Financing), string(summary.Financing))
+	compare("commission", string(s.Commission), string(summary.Commission))
+	compare("dividendAdjustment", string(s.DividendAdjustment), string(summary.DividendAdjustment))
+	compare("guaranteedExecutionFees", string(s.GuaranteedExecutionFees), string(summary.GuaranteedExecutionFees))
+	compare("openTradeCount", strconv.Itoa(len(s.Trades)), strconv.Itoa(summary.OpenTradeCount))
+	compare("openPositionCount", strconv.Itoa(openPositions), strconv.Itoa(summary.OpenPositionCount))
+	compare("pendingOrderCount", strconv.Itoa(len(s.Orders)), strconv.Itoa(summary.PendingOrderCount))
+	return mismatches
+}
+
+// stateApplier applies Transactions to an AccountState. Transactions without a Visit method,
+// such as rejections, do not change the state.
+type stateApplier struct {
+	BaseTransactionVisitor
+	s *AccountState
+}
+
+func (a stateApplier) VisitTransferFunds(t *TransferFundsTransaction) error {
+	a.s.setBalance(t.AccountBalance)
+	return nil
+}
+
+func (a stateApplier) VisitLimitOrder(t *LimitOrderTransaction) error {
+	return a.s.createOrder(t, "")
+}
+
+func (a stateApplier) VisitStopOrder(t *StopOrderTransaction) error {
+	return a.s.createOrder(t, "")
+}
+
+func (a stateApplier) VisitMarketIfTouchedOrder(t *MarketIfTouchedOrderTransaction) error {
+	return a.s.createOrder(t, "")
+}
+
+func (a stateApplier) VisitTakeProfitOrder(t *TakeProfitOrderTransaction) error {
+	return a.s.createOrder(t, t.TradeID)
+}
+
+func (a stateApplier) VisitStopLossOrder(t *StopLossOrderTransaction) error {
+	return a.s.createOrder(t, t.TradeID)
+}
+
+func (a stateApplier) VisitGuaranteedStopLossOrder(t *GuaranteedStopLossOrderTransaction) error {
+	return a.s.createOrder(t, t.TradeID)
+}
+
+func (a stateApplier) VisitTrailingStopLossOrder(t *TrailingStopLossOrderTransaction) error {
+	return a.s.createOrder(t, t.TradeID)
+}
+
+func (a stateApplier) VisitOrderFill(t *OrderFillTransaction) error {
+	return a.s.fill(t)
+}
+
+func (a stateApplier) VisitOrderCancel(t *OrderCancelTransaction) error {
+	a.s.removeOrder(t.OrderID)
+	return nil
+}
+
+func (a stateApplier) VisitOrderClientExtensionsModify(t *OrderClientExtensionsModifyTransaction) error {
+	order, ok := a.s.Orders[t.OrderID]
+	if !ok {
+		return nil
+	}
+	fields := make(map[string]any)
+	if t.ClientExtensionsModify != nil {
+		fields["clientExtensions"] = t.ClientExtensionsModify
+	}
+	if t.TradeClientExtensionsModify != nil {
+		fields["tradeClientExtensions"] = t.TradeClientExtensionsModify
+	}
+	raw, ok := order.(interface{ GetRawJSON() json.RawMessage })
+	if !ok || raw.GetRawJSON() == nil {
+		return fmt.Errorf("order %s has no raw JSON to modify", t.OrderID)
+	}
+	patched, err := patchOrder(raw.GetRawJSON(), fields)
+	if err != nil {
+		return err
+	}
+	a.s.Orders[t.OrderID] = patched
+	return nil
+}
+
+func (a stateApplier) VisitTradeClientExtensionsModify(t *TradeClientExtensionsModifyTransaction) error {
+	if trade, ok := a.s.Trades[t.TradeID]; ok {
+		extensions := t.TradeClientExtensionsModify
+		trade.ClientExtensions = &extensions
+	}
+	return nil
+}
+
+func (a stateApplier) VisitDailyFinancing(t *DailyFinancingTransaction) error {
+	a.s.setBalance(t.AccountBalance)
+	if err := addTo(&a.s.Financing, t.Financing); err != nil {
+		return err
+	}
+	for _, financing := range t.PositionFinancings {
+		position := a.s.position(financing.Instrument)
+		if err := addTo(&position.Financing, financing.Financing); err != nil {
+			return err
+		}
+		for _, tradeFinancing := range financing.OpenTradeFinancings {
+			trade, ok := a.s.Trades[tradeFinancing.TradeID]
+			if !ok {
+				continue
+			}
+			if err := addTo(&trade.Financing, tradeFinancing.Financing); err != nil {
+				return err
+			}
+			if err := addTo(&sideOf(position, trade.CurrentUnits).Financing, tradeFinancing.Financing); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (a stateApplier) VisitDividendAdjustment(t *DividendAdjustmentTransaction) error {
+	a.s.setBalance(t.AccountBalance)
+	if err := addTo(&a.s.DividendAdjustment, t.DividendAdjustment); err != nil {
+		return err
+	}
+	position := a.s.position(t.Instrument)
+	if err := addTo(&position.DividendAdjustment, t.DividendAdjustment); err != nil {
+		return err
+	}
+	for _, adjustment := range t.OpenTradeDividendAdjustments {
+		trade, ok := a.s.Trades[adjustment.TradeID]
+		if !ok {
+			continue
+		}
+		if err := addTo(&trade.DividendAdjustment, adjustment.DividendAdjustment); err != nil {
+			return err
+		}
+		if err := addTo(&sideOf(position, trade.CurrentUnits).DividendAdjustment, adjustment.DividendAdjustment); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a stateApplier) VisitResetResettablePL(*ResetResettablePLTransaction) error {
+	a.s.ResettablePL = resetAmount(a.s.ResettablePL)
+	for _, position := range a.s.Positions {
+		position.ResettablePL = resetAmount(position.ResettablePL)
+		position.Long.ResettablePL = resetAmount(position.Long.ResettablePL)
+		position.Short.ResettablePL = resetAmount(position.Short.ResettablePL)
+	}
+	return nil
+}
+
+func (s *AccountState) setBalance(balance AccountUnits) {
+	if balance != "" {
+		s.Balance = balance
+	}
+}
+
+// createOrder adds the pending Order created by t, and links it to the Trade it depends on.
+func (s *AccountState) createOrder(t OrderTransaction, tradeID TradeID) error {
+	raw, err := transactionJSON(t)
+	if err != nil {
+		return err
+	}
+	order, err := patchOrder(raw, map[string]any{
+		"type":       t.GetOrderType(),
+		"state":      OrderStatePending,
+		"createTime": t.GetTime(),
+	})
+	if err != nil {
+		return err
+	}
+	s.Orders[order.GetID()] = order
+	if trade, ok := s.Trades[tradeID]; ok {
+		id := order.GetID()
+		switch t.GetOrderType() {
+		case OrderTypeTakeProfit:
+			trade.TakeProfitOrderID = &id
+		case OrderTypeStopLoss:
+			trade.StopLossOrderID = &id
+		case OrderTypeGuaranteedStopLoss:
+			trade.GuaranteedStopLossOrderID = &id
+		case OrderTypeTrailingStopLoss:
+			trade.TrailingStopLossOrderID = &id
+		}
+	}
+	return nil
+}
+
+// removeOrder removes a pending Order that was filled or cancelled, and unlinks it from its
+// Trade.
+func (s *AccountState) removeOrder(orderID OrderID) {
+	delete(s.Orders, orderID)
+	for _, trade := range s.Trades {
+		for _, id := range []**OrderID{&trade.TakeProfitOrderID, &trade.StopLossOrderID, &trade.GuaranteedStopLossOrderID, &trade.TrailingStopLossOrderID} {
+			if *id != nil && **id == orderID {
+				*id = nil
+			}
+		}
+	}
+}
+
+func (s *AccountState) fill(t *OrderFillTransaction) error {
+	s.removeOrder(t.OrderID)
+	s.setBalance(t.AccountBalance)
+	position := s.position(t.Instrument)
+	for _, totals := range []struct {
+		account, position *AccountUnits
+		amount            AccountUnits
+	}{
+		{&s.PL, &position.PL, t.PL},
+		{&s.ResettablePL, &position.ResettablePL, t.PL},
+		{&s.Financing, &position.Financing, t.Financing},
+		{&s.Commission, &position.Commission, t.Commission},
+		{&s.GuaranteedExecutionFees, nil, t.GuaranteedExecutionFee},
+	} {
+		if err := addTo(totals.account, totals.amount); err != nil {
+			return err
+		}
+		if totals.position != nil {
+			if err := addTo(totals.position, totals.amount); err != nil {
+				return err
+			}
+		}
+	}
+	if t.GuaranteedExecutionFee != "" {
+		if position.GuaranteedExecutionsFees == nil {
+			position.GuaranteedExecutionsFees = new(AccountUnits)
+		}
+		if err := addTo(position.GuaranteedExecutionsFees, t.GuaranteedExecutionFee); err != nil {
+			return err
+		}
+	}
+
+	for _, closed := range t.TradesClosed {
+		if err := s.reduceTrade(position, closed, true); err != nil {
+			return err
+		}
+	}
+	if t.TradeReduced != nil {
+		if err := s.reduceTrade(position, *t.TradeReduced, false); err != nil {
+			return err
+		}
+	}
+	if opened := t.TradeOpened; opened != nil {
+		s.Trades[opened.TradeID] = &TradeSummary{
+			ID:                    opened.TradeID,
+			Instrument:            t.Instrument,
+			Price:                 opened.Price,
+			OpenTime:              t.Time,
+			State:                 TradeStateOpen,
+			InitialUnits:          opened.Units,
+			InitialMarginRequired: opened.InitialMarginRequired,
+			CurrentUnits:          opened.Units,
+			RealizedPL:            "0",
+			Financing:             "0",
+			DividendAdjustment:    "0",
+			ClientExtensions:      opened.ClientExtensions,
+		}
+		if err := addTo(&sideOf(position, opened.Units).GuaranteedExecutionFees, opened.GuaranteedExecutionFee); err != nil {
+			return err
+		}
+	}
+	return s.refreshPosition(position)
+}
+
+// reduceTrade applies the closing or reduction of a Trade to the Trade and its Position side.
+func (s *AccountState) reduceTrade(position *Position, reduce TradeReduce, closed bool) error {
+	trade, ok := s.Trades[reduce.TradeID]
+	if !ok {
+		return fmt.Errorf("unknown trade %s", reduce.TradeID)
+	}
+	side := sideOf(position, trade.CurrentUnits)
+	for _, totals := range []struct {
+		fields []*AccountUnits
+		amount AccountUnits
+	}{
+		{[]*AccountUnits{&side.PL, &side.ResettablePL, &trade.RealizedPL}, reduce.RealizedPL},
+		{[]*AccountUnits{&side.Financing, &trade.Financing}, reduce.Financing},
+		{[]*AccountUnits{&side.GuaranteedExecutionFees}, reduce.GuaranteedExecutionFee},
+	} {
+		for _, field := range totals.fields {
+			if err := addTo(field, totals.amount); err != nil {
+				return err
+			}
+		}
+	}
+	if closed {
+		delete(s.Trades, reduce.TradeID)
+		return nil
+	}
+	// The reduced units have the sign of the fill, opposite to the Trade's.
+	current, err := trade.CurrentUnits.Rat()
+	if err != nil {
+		return err
+	}
+	reduced, err := reduce.Units.Rat()
+	if err != nil {
+		return err
+	}
+	reduced.Abs(reduced)
+	if current.Sign() < 0 {
+		reduced.Neg(reduced)
+	}
+	places := max(fractionDigits(string(trade.CurrentUnits)), fractionDigits(string(reduce.Units)))
+	trade.CurrentUnits = DecimalNumber(current.Sub(current, reduced).FloatString(places))
+	return nil
+}
+
+// refreshPosition recomputes the units, average price and Trades of each side of position from
+// the open Trades.
+func (s *AccountState) refreshPosition(position *Position) error {
+	var trades []*TradeSummary
+	for _, trade := range s.Trades {
+		if trade.Instrument == position.Instrument {
+			trades = append(trades, trade)
+		}
+	}
+	slices.SortFunc(trades, func(a, b *TradeSummary) int {
+		return CompareTransactionIDs(a.ID, b.ID)
+	})
+	for _, direction := range []Direction{DirectionLong, DirectionShort} {
+		side := &position.Long
+		if direction == DirectionShort {
+			side = &position.Short
+		}
+		var units decimalSum
+		cost := new(big.Rat)
+		pricePlaces := 0
+		side.TradeIDs = nil
+		for _, trade := range trades {
+			if directionOf(trade.CurrentUnits) != direction {
+				continue
+			}
+			if err := units.add(string(trade.CurrentUnits)); err != nil {
+				return err
+			}
+			u, err := trade.CurrentUnits.Rat()
+			if err != nil {
+				return err
+			}
+			price, err := trade.Price.Rat()
+			if err != nil {
+				return err
+			}
+			cost.Add(cost, price.Mul(price, u))
+			pricePlaces = max(pricePlaces, fractionDigits(string(trade.Price)))
+			side.TradeIDs = append(side.TradeIDs, trade.ID)
+		}
+		side.Units = DecimalNumber(units.String())
+		side.AveragePrice = nil
+		if units.sum.Sign() != 0 {
+			average := PriceValue(cost.Quo(cost, &units.sum).FloatString(pricePlaces))
+			side.AveragePrice = &average
+		}
+	}
+	return nil
+}
+
+// position returns the Position in instrument, adding it if the Account has none.
+func (s *AccountState) position(instrument InstrumentName) *Position {
+	position, ok := s.Positions[instrument]
+	if !ok {
+		position = &Position{Instrument: instrument}
+		s.Positions[instrument] = position
+	}
+	return position
+}
+
+// sideOf returns the side of position that holds Trades of units.
+func sideOf(position *Position, units DecimalNumber) *PositionSide {
+	if directionOf(units) == DirectionShort {
+		return &position.Short
+	}
+	return &position.Long
+}
+
+// addTo adds amount to the decimal number at field, keeping the larger number of decimal places.
+// Empty values are zero.
+func addTo[T ~string](field *T, amount T) error {
+	if amount == "" {
+		return nil
+	}
+	var sum decimalSum
+	if err := sum.add(string(*field)); err != nil {
+		return err
+	}
+	if err := sum.add(string(amount)); err != nil {
+		return err
+	}
+	*field = T(sum.String())
+	return nil
+}
+
+// resetAmount returns zero with the decimal places of amount.
+func resetAmount(amount AccountUnits) AccountUnits {
+	return AccountUnits(new(big.Rat).FloatString(fractionDigits(string(amount))))
+}
+
+// isNonZero reports whether units is a non-zero number.
+func isNonZero(units DecimalNumber) bool {
+	r, err := units.Rat()
+	return err == nil && r.Sign() != 0
+}
+
+// patchOrder decodes an Order from raw with fields replaced by the given values.
+func patchOrder(raw json.RawMessage, fields map[string]any) (Order, error) {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &object); err != nil {
+		return nil, fmt.Errorf("failed to decode order: %w", err)
+	}
+	for name, value := range fields {
+		b, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		object[name] = b
+	}
+	patched, err := json.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalOrder(patched)
+}
@@ -0,0 +1,112 @@
+package oanda
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestAccountState(t *testing.T) {
+	var account Account
+	if err := json.Unmarshal([]byte(`{"id":"001","lastTransactionID":"9","balance":"1000.0000","pl":"0.0000","resettablePL":"0.0000",
+		"financing":"0.0000","commission":"0.0000","dividendAdjustment":"0","guaranteedExecutionFees":"0.0000",
+		"trades":[{"id":"5","instrument":"EUR_USD","price":"1.10000","state":"OPEN","initialUnits":"-100","currentUnits":"-100","realizedPL":"0.0000","financing":"0.0000"}],
+		"positions":[{"instrument":"EUR_USD","pl":"0.0000","resettablePL":"0.0000","financing":"0.0000","commission":"0.0000",
+			"long":{"units":"0","pl":"0.0000","resettablePL":"0.0000","financing":"0.0000"},
+			"short":{"units":"-100","averagePrice":"1.10000","tradeIDs":["5"],"pl":"0.0000","resettablePL":"0.0000","financing":"0.0000"}}],
+		"orders":[]}`), &account); err != nil {
+		t.Fatalf("failed to decode account: %v", err)
+	}
+	state := NewAccountState(&account)
+
+	for _, line := range []string{
+		// Already reflected in the snapshot.
+		`{"type":"TRANSFER_FUNDS","id":"8","amount":"1000.0000","accountBalance":"1000.0000"}`,
+		`{"type":"LIMIT_ORDER","id":"10","time":"2024-01-02T09:00:00Z","instrument":"EUR_USD","units":"200","price":"1.09000","timeInForce":"GTC"}`,
+		`{"type":"ORDER_FILL","id":"11","time":"2024-01-02T10:00:00Z","orderID":"10","instrument":"EUR_USD","units":"200","price":"1.09000",
+			"pl":"1.0000","commission":"0.0100","financing":"-0.0200","accountBalance":"1000.9700",
+			"tradesClosed":[{"tradeID":"5","units":"100","realizedPL":"1.0000","financing":"-0.0200"}],
+			"tradeOpened":{"tradeID":"11","units":"100","price":"1.09000","initialMarginRequired":"3.2700"}}`,
+		`{"type":"TAKE_PROFIT_ORDER","id":"12","time":"2024-01-02T10:00:00Z","tradeID":"11","price":"1.10000","timeInForce":"GTC"}`,
+		`{"type":"MARKET_ORDER","id":"13","instrument":"EUR_USD","units":"50"}`,
+		`{"type":"ORDER_FILL","id":"14","time":"2024-01-02T11:00:00Z","orderID":"13","instrument":"EUR_USD","units":"50","price":"1.09200",
+			"pl":"0.0000","commission":"0.0000","financing":"0.0000","accountBalance":"1000.9700",
+			"tradeOpened":{"tradeID":"14","units":"50","price":"1.09200"}}`,
+		`{"type":"DAILY_FINANCING","id":"15","financing":"-0.0300","accountBalance":"1000.9400",
+			"positionFinancings":[{"instrument":"EUR_USD","financing":"-0.0300","openTradeFinancings":[{"tradeID":"11","financing":"-0.0200"},{"tradeID":"14","financing":"-0.0100"}]}]}`,
+		`{"type":"TRADE_CLIENT_EXTENSIONS_MODIFY","id":"16","tradeID":"14","tradeClientExtensionsModify":{"tag":"scalp"}}`,
+		`{"type":"ORDER_FILL","id":"17","time":"2024-01-02T12:00:00Z","orderID":"17","instrument":"EUR_USD","units":"-40","price":"1.09500",
+			"pl":"0.2000","commission":"0.0000","financing":"0.0000","accountBalance":"1001.1400",
+			"tradeReduced":{"tradeID":"11","units":"-40","realizedPL":"0.2000","financing":"0.0000"}}`,
+		`{"type":"ORDER_CANCEL","id":"18","orderID":"12","reason":"CLIENT_REQUEST"}`,
+		`{"type":"LIMIT_ORDER_REJECT","id":"19","instrument":"EUR_USD","units":"1","price":"1.0","rejectReason":"INSUFFICIENT_MARGIN"}`,
+	} {
+		transaction, err := unmarshalTransaction([]byte(line))
+		if err != nil {
+			t.Fatalf("failed to decode transaction: %v", err)
+		}
+		if err := state.Apply(transaction); err != nil {
+			t.Fatalf("failed to apply transaction %s: %v", transaction.GetID(), err)
+		}
+	}
+
+	if state.LastTransactionID != "19" || state.Balance != "1001.1400" {
+		t.Errorf("got last transaction %s and balance %s, want 19 and 1001.1400", state.LastTransactionID, state.Balance)
+	}
+	if state.PL != "1.2000" || state.Financing != "-0.0500" || state.Commission != "0.0100" {
+		t.Errorf("got pl %s, financing %s and commission %s, want 1.2000, -0.0500 and 0.0100", state.PL, state.Financing, state.Commission)
+	}
+	if len(state.Orders) != 0 {
+		t.Errorf("got %d pending orders, want none", len(state.Orders))
+	}
+	if _, ok := state.Trades["5"]; ok || len(state.Trades) != 2 {
+		t.Fatalf("got trades %v, want 11 and 14", state.Trades)
+	}
+	trade := state.Trades["11"]
+	if trade.CurrentUnits != "60" || trade.RealizedPL != "0.2000" || trade.Financing != "-0.0200" || trade.TakeProfitOrderID != nil {
+		t.Errorf("got trade 11 %+v, want 60 units realizing 0.2000 without take profit", trade)
+	}
+	if tag := state.Trades["14"].ClientExtensions; tag == nil || tag.Tag == nil || *tag.Tag != "scalp" {
+		t.Errorf("got trade 14 client extensions %+v, want tag scalp", tag)
+	}
+	position := state.Positions["EUR_USD"]
+	if position.Long.Units != "110" || position.Long.AveragePrice == nil || *position.Long.AveragePrice != "1.09091" ||
+		!slices.Equal(position.Long.TradeIDs, []TradeID{"11", "14"}) {
+		t.Errorf("got long side %+v, want 110 units of 11 and 14 averaging 1.09091", position.Long)
+	}
+	if position.Short.Units != "0" || position.Short.AveragePrice != nil || position.Short.PL != "1.0000" {
+		t.Errorf("got short side %+v, want no units and 1.0000 realized", position.Short)
+	}
+	if position.PL != "1.2000" || position.Financing != "-0.0500" || position.Long.Financing != "-0.0300" {
+		t.Errorf("got position pl %s and financing %s/%s, want 1.2000 and -0.0500/-0.0300", position.PL, position.Financing, position.Long.Financing)
+	}
+
+	summary := AccountSummary{
+		LastTransactionID: "19", Balance: "1001.14", PL: "1.2000", ResettablePL: "1.2000", Financing: "-0.0500",
+		Commission: "0.0100", DividendAdjustment: "0", GuaranteedExecutionFees: "0.0000",
+		OpenTradeCount: 2, OpenPositionCount: 1, PendingOrderCount: 1,
+	}
+	want := []AccountStateMismatch{{"pendingOrderCount", "0", "1"}}
+	if got := state.Reconcile(&summary); !slices.Equal(got, want) {
+		t.Errorf("got mismatches %v, want %v", got, want)
+	}
+
+	reset, err := unmarshalTransaction([]byte(`{"type":"RESET_RESETTABLE_PL","id":"20"}`))
+	if err != nil {
+		t.Fatalf("failed to decode transaction: %v", err)
+	}
+	if err := state.Apply(reset); err != nil {
+		t.Fatalf("failed to apply reset: %v", err)
+	}
+	if state.ResettablePL != "0.0000" || position.ResettablePL != "0.0000" {
+		t.Errorf("got resettable pl %s and %s, want 0.0000", state.ResettablePL, position.ResettablePL)
+	}
+
+	unknown, err := unmarshalTransaction([]byte(`{"type":"ORDER_FILL","id":"21","instrument":"EUR_USD","units":"1","tradesClosed":[{"tradeID":"99","units":"1"}]}`))
+	if err != nil {
+		t.Fatalf("failed to decode transaction: %v", err)
+	}
+	if err := state.Apply(unknown); err == nil {
+		t.Error("got no error for closing an unknown trade")
+	}
+}
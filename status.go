@@ -0,0 +1,128 @@
+package oanda
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// OANDAStatusURL is the assumed base URL of the OANDA API status service, and OANDAStatusPath
+// the assumed path of its overall status. OANDA does not document a status API: the status
+// page is assumed to be an Atlassian Statuspage, whose public API reports the overall status at
+// OANDAStatusPath in the format decoded by [StatusClient.Health]. Use [WithBaseURL] and
+// [StatusClient.SetPath] to point the client elsewhere if the page moves.
+const (
+	OANDAStatusURL  = "https://api-status.oanda.com"
+	OANDAStatusPath = "/api/v2/status.json"
+)
+
+// StatusIndicator is the overall severity reported by the OANDA API status service.
+type StatusIndicator string
+
+const (
+	// StatusIndicatorNone means all systems are operational.
+	StatusIndicatorNone StatusIndicator = "none"
+	// StatusIndicatorMinor means a minor incident or degraded performance has been declared.
+	StatusIndicatorMinor StatusIndicator = "minor"
+	// StatusIndicatorMajor means a major incident or partial outage has been declared.
+	StatusIndicatorMajor StatusIndicator = "major"
+	// StatusIndicatorCritical means a critical incident or major outage has been declared.
+	StatusIndicatorCritical StatusIndicator = "critical"
+	// StatusIndicatorMaintenance means scheduled maintenance is in progress.
+	StatusIndicatorMaintenance StatusIndicator = "maintenance"
+)
+
+// Status is the overall state of the OANDA API as reported by the status service.
+type Status struct {
+	// Indicator is the severity of the current state.
+	Indicator StatusIndicator `json:"indicator"`
+	// Description is a human-readable summary of the current state.
+	Description string `json:"description"`
+	// UpdatedAt is the time when the status was last updated.
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Healthy reports whether no incident or maintenance is currently declared.
+func (s Status) Healthy() bool {
+	return s.Indicator == StatusIndicatorNone
+}
+
+// Incident reports whether a major or critical incident, or maintenance, is currently declared.
+func (s Status) Incident() bool {
+	switch s.Indicator {
+	case StatusIndicatorMajor, StatusIndicatorCritical, StatusIndicatorMaintenance:
+		return true
+	default:
+		return false
+	}
+}
+
+// StatusClient is a client for the OANDA API status service. It does not require an API key.
+// Create one with [NewStatusClient].
+type StatusClient struct {
+	clientConfig
+	path string
+}
+
+// NewStatusClient creates a new client for the OANDA API status service at [OANDAStatusURL].
+// Use [WithBaseURL] to point it at a different status page and [WithHTTPClient] to replace the
+// HTTP client.
+func NewStatusClient(opts ...Option) *StatusClient {
+	client := &StatusClient{
+		clientConfig: defaultConfig(OANDAStatusURL, ""),
+		path:         OANDAStatusPath,
+	}
+	for _, opt := range opts {
+		opt(&client.clientConfig)
+	}
+	return client
+}
+
+// SetPath sets the path of the overall status relative to the base URL. The default is
+// [OANDAStatusPath].
+func (c *StatusClient) SetPath(path string) *StatusClient {
+	c.path = path
+	return c
+}
+
+// Health retrieves the current overall status of the OANDA API.
+//
+// This corresponds to the status service endpoint: GET /api/v2/status.json, which is an
+// assumption; see [OANDAStatusURL].
+func (c *StatusClient) Health(ctx context.Context) (*Status, error) {
+	u, err := joinURL(c.baseURL, c.path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+	httpResp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send GET request: %w", err)
+	}
+	defer closeBody(httpResp)
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, wrapHTTPError(httpResp.StatusCode, fmt.Errorf("unexpected status %s", httpResp.Status))
+	}
+	resp, err := decodeJSON[struct {
+		Page struct {
+			UpdatedAt time.Time `json:"updated_at"`
+		} `json:"page"`
+		Status struct {
+			Indicator   StatusIndicator `json:"indicator"`
+			Description string          `json:"description"`
+		} `json:"status"`
+	}](httpResp)
+	if err != nil {
+		return nil, err
+	}
+	return &Status{
+		Indicator:   resp.Status.Indicator,
+		Description: resp.Status.Description,
+		UpdatedAt:   resp.Page.UpdatedAt,
+	}, nil
+}
@@ -0,0 +1,59 @@
+package oanda
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newStatusServer(indicator StatusIndicator) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/status.json" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"page":{"updated_at":"2024-01-01T00:00:00Z"},"status":{"indicator":%q,"description":"test"}}`, indicator)
+	}))
+}
+
+func TestStatusClient_Health(t *testing.T) {
+	server := newStatusServer(StatusIndicatorMajor)
+	defer server.Close()
+
+	status, err := NewStatusClient(WithBaseURL(server.URL)).Health(t.Context())
+	if err != nil {
+		t.Fatalf("failed to get health: %v", err)
+	}
+	if status.Indicator != StatusIndicatorMajor || status.Healthy() || !status.Incident() {
+		t.Errorf("unexpected status: %+v", status)
+	}
+	if want := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC); !status.UpdatedAt.Equal(want) {
+		t.Errorf("got updated at %v, want %v", status.UpdatedAt, want)
+	}
+
+	if _, err := NewStatusClient(WithBaseURL(server.URL)).SetPath("/status").Health(t.Context()); err == nil {
+		t.Error("got no error for a status page at another path")
+	}
+}
+
+func TestStreamManager_IncidentBackoff(t *testing.T) {
+	for _, tt := range []struct {
+		indicator StatusIndicator
+		want      time.Duration
+	}{
+		{StatusIndicatorNone, time.Second},
+		{StatusIndicatorMinor, time.Second},
+		{StatusIndicatorCritical, 6 * time.Second},
+	} {
+		server := newStatusServer(tt.indicator)
+		manager := NewStreamManager(NewStreamClient("api-key"), "1").
+			SetReconnectDelay(time.Second).
+			SetStatusClient(NewStatusClient(WithBaseURL(server.URL)))
+		if got := manager.nextReconnectDelay(t.Context()); got != tt.want {
+			t.Errorf("%s: got delay %v, want %v", tt.indicator, got, tt.want)
+		}
+		server.Close()
+	}
+}
@@ -0,0 +1,241 @@
+package oanda
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"os"
+	"sync"
+)
+
+// TransactionStore keeps a local copy of an Account's Transactions, kept up to date with
+// [transactionService.Sync]. Implementations must be safe for concurrent use.
+type TransactionStore interface {
+	// Append stores transactions, which are in ID order and follow the last stored Transaction.
+	Append(ctx context.Context, transactions []Transaction) error
+	// LastID returns the ID of the last stored Transaction, or an empty ID if the store is empty.
+	LastID(ctx context.Context) (TransactionID, error)
+	// All returns an iterator over the stored Transactions in ID order.
+	All(ctx context.Context) iter.Seq2[Transaction, error]
+}
+
+// Sync downloads the Transactions created since the last Transaction in store and appends them
+// to it, using the store's last Transaction ID as the checkpoint. The first Sync of an empty
+// store downloads the Account's complete history; later ones only what is new, so a restarted
+// application does not download the history again. Transactions are appended in batches as
+// they are downloaded, so an interrupted Sync resumes where it stopped. Sync returns the number
+// of Transactions appended.
+//
+// This corresponds to the OANDA API endpoint: GET /v3/accounts/{accountID}/transactions/sinceid
+//
+// Reference: https://developer.oanda.com/rest-live-v20/transaction-ep/#collapse_endpoint_4
+func (s *transactionService) Sync(ctx context.Context, store TransactionStore) (int, error) {
+	lastID, err := store.LastID(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get last stored transaction ID: %w", err)
+	}
+	if lastID == "" {
+		lastID = "0"
+	}
+	synced := 0
+	for {
+		resp, err := s.GetBySinceID(ctx, NewTransactionGetBySinceIDRequest(lastID))
+		if err != nil {
+			return synced, fmt.Errorf("failed to get transactions since %s: %w", lastID, err)
+		}
+		if len(resp.Transactions) == 0 {
+			return synced, nil
+		}
+		if err := store.Append(ctx, resp.Transactions); err != nil {
+			return synced, fmt.Errorf("failed to store transactions: %w", err)
+		}
+		synced += len(resp.Transactions)
+		lastID = resp.Transactions[len(resp.Transactions)-1].GetID()
+		if lastID == resp.LastTransactionID {
+			return synced, nil
+		}
+	}
+}
+
+// MemoryTransactionStore is a [TransactionStore] that keeps Transactions in memory. Create one
+// with [NewMemoryTransactionStore].
+type MemoryTransactionStore struct {
+	mu           sync.Mutex
+	transactions []Transaction
+}
+
+// NewMemoryTransactionStore creates a new, empty MemoryTransactionStore.
+func NewMemoryTransactionStore() *MemoryTransactionStore {
+	return &MemoryTransactionStore{}
+}
+
+// Append stores transactions.
+func (s *MemoryTransactionStore) Append(_ context.Context, transactions []Transaction) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.transactions = append(s.transactions, transactions...)
+	return nil
+}
+
+// LastID returns the ID of the last stored Transaction.
+func (s *MemoryTransactionStore) LastID(_ context.Context) (TransactionID, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.transactions) == 0 {
+		return "", nil
+	}
+	return s.transactions[len(s.transactions)-1].GetID(), nil
+}
+
+// All returns an iterator over a snapshot of the stored Transactions.
+func (s *MemoryTransactionStore) All(_ context.Context) iter.Seq2[Transaction, error] {
+	s.mu.Lock()
+	transactions := s.transactions[:len(s.transactions):len(s.transactions)]
+	s.mu.Unlock()
+	return func(yield func(Transaction, error) bool) {
+		for _, transaction := range transactions {
+			if !yield(transaction, nil) {
+				return
+			}
+		}
+	}
+}
+
+// FileTransactionStore is a [TransactionStore] that keeps Transactions in a JSON Lines file,
+// one Transaction per line as received from OANDA, so that fields this package does not decode
+// are kept as well. Create one with [NewFileTransactionStore].
+type FileTransactionStore struct {
+	path   string
+	mu     sync.Mutex
+	lastID *TransactionID
+}
+
+// NewFileTransactionStore creates a new FileTransactionStore backed by the file at path. The
+// file is created on the first Append.
+func NewFileTransactionStore(path string) *FileTransactionStore {
+	return &FileTransactionStore{path: path}
+}
+
+// Append appends transactions to the file.
+func (s *FileTransactionStore) Append(_ context.Context, transactions []Transaction) error {
+	if len(transactions) == 0 {
+		return nil
+	}
+	var buf bytes.Buffer
+	for _, transaction := range transactions {
+		line, err := transactionJSON(transaction)
+		if err != nil {
+			return err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	lastID := transactions[len(transactions)-1].GetID()
+	s.lastID = &lastID
+	return nil
+}
+
+// transactionJSON returns the compact encoding of transaction with [MarshalRaw], on one line.
+func transactionJSON(transaction Transaction) ([]byte, error) {
+	b, err := MarshalRaw(transaction)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, b); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// LastID returns the ID of the last stored Transaction. The file is read on the first call
+// only.
+func (s *FileTransactionStore) LastID(_ context.Context) (TransactionID, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.lastID != nil {
+		return *s.lastID, nil
+	}
+	var lastID TransactionID
+	for line, err := range s.lines() {
+		if err != nil {
+			return "", err
+		}
+		var idOnly struct {
+			ID TransactionID `json:"id"`
+		}
+		if err := json.Unmarshal(line, &idOnly); err != nil {
+			return "", fmt.Errorf("failed to decode transaction store: %w", err)
+		}
+		lastID = idOnly.ID
+	}
+	s.lastID = &lastID
+	return lastID, nil
+}
+
+// All returns an iterator over the stored Transactions, decoded like the Transactions returned
+// by [transactionService.GetBySinceID].
+func (s *FileTransactionStore) All(_ context.Context) iter.Seq2[Transaction, error] {
+	return func(yield func(Transaction, error) bool) {
+		for line, err := range s.lines() {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			transaction, err := unmarshalTransaction(line)
+			if err != nil {
+				yield(nil, fmt.Errorf("failed to decode transaction store: %w", err))
+				return
+			}
+			if !yield(transaction, nil) {
+				return
+			}
+		}
+	}
+}
+
+// lines returns an iterator over the non-empty lines of the file. A missing file has no lines.
+func (s *FileTransactionStore) lines() iter.Seq2[[]byte, error] {
+	return func(yield func([]byte, error) bool) {
+		f, err := os.Open(s.path)
+		if errors.Is(err, os.ErrNotExist) {
+			return
+		}
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		defer f.Close()
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(nil, 16<<20)
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			if !yield(bytes.Clone(line), nil) {
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			yield(nil, fmt.Errorf("failed to read transaction store: %w", err))
+		}
+	}
+}
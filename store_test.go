@@ -0,0 +1,68 @@
+package oanda
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestTransactionService_Sync(t *testing.T) {
+	lastTransactionID := 5
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v3/accounts/1/transactions/sinceid" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		since, _ := strconv.Atoi(r.URL.Query().Get("id"))
+		// Return at most two Transactions per request, like a server-side cap.
+		var transactions []string
+		for id := since + 1; id <= min(since+2, lastTransactionID); id++ {
+			transactions = append(transactions,
+				fmt.Sprintf(`{"type":"ORDER_FILL","id":"%d","orderID":"1","extra":"kept"}`, id))
+		}
+		_, _ = fmt.Fprintf(w, `{"transactions":[%s],"lastTransactionID":"%d"}`,
+			strings.Join(transactions, ","), lastTransactionID)
+	}))
+	defer server.Close()
+	client := NewClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+	path := filepath.Join(t.TempDir(), "transactions.jsonl")
+
+	for _, store := range []TransactionStore{NewMemoryTransactionStore(), NewFileTransactionStore(path)} {
+		lastTransactionID = 5
+		if n, err := client.Transaction.Sync(t.Context(), store); err != nil || n != 5 {
+			t.Fatalf("%T: got %d synced (%v), want 5", store, n, err)
+		}
+		lastTransactionID = 7
+		if n, err := client.Transaction.Sync(t.Context(), store); err != nil || n != 2 {
+			t.Fatalf("%T: got %d synced (%v), want 2", store, n, err)
+		}
+		var ids []TransactionID
+		for transaction, err := range store.All(t.Context()) {
+			if err != nil {
+				t.Fatalf("%T: got error: %v", store, err)
+			}
+			if _, ok := transaction.(*OrderFillTransaction); !ok {
+				t.Errorf("%T: got %T, want *OrderFillTransaction", store, transaction)
+			}
+			ids = append(ids, transaction.GetID())
+		}
+		if strings.Join(ids, ",") != "1,2,3,4,5,6,7" {
+			t.Errorf("%T: got IDs %v, want 1 to 7", store, ids)
+		}
+	}
+
+	// A new store on the same file resumes from the stored checkpoint.
+	reopened := NewFileTransactionStore(path)
+	if lastID, err := reopened.LastID(t.Context()); err != nil || lastID != "7" {
+		t.Errorf("got last ID %q (%v), want 7", lastID, err)
+	}
+	for transaction := range reopened.All(t.Context()) {
+		if raw := transaction.(*OrderFillTransaction).GetRawJSON(); !strings.Contains(string(raw), `"extra":"kept"`) {
+			t.Errorf("got raw JSON %s, want extra field", raw)
+		}
+		break
+	}
+}
@@ -0,0 +1,252 @@
+package oanda
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Received records the local time at which a stream item was received. It is embedded in the
+// types delivered by the pricing and transaction streams.
+type Received struct {
+	// ReceivedAt is the local time at which the item was decoded from the stream. It is the
+	// zero time for values that were not received on a stream, such as Transactions retrieved
+	// with the REST endpoints.
+	ReceivedAt time.Time `json:"-"`
+}
+
+// GetReceivedAt returns the local time at which the item was received on a stream.
+func (r Received) GetReceivedAt() time.Time {
+	return r.ReceivedAt
+}
+
+func (r *Received) setReceivedAt(t time.Time) {
+	r.ReceivedAt = t
+}
+
+// heartbeatClock records when the most recent heartbeat of a stream was received, and the
+// server time it reported. It is safe for concurrent use.
+type heartbeatClock struct {
+	mu         sync.Mutex
+	receivedAt time.Time
+	serverTime time.Time
+}
+
+func (c *heartbeatClock) record(serverTime DateTime, receivedAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.receivedAt = receivedAt
+	c.serverTime = time.Time{}
+	if serverTime.Time != nil {
+		c.serverTime = serverTime.UTC()
+	}
+}
+
+// last returns when the most recent heartbeat was received, by the local clock, or the zero
+// time if none was received.
+func (c *heartbeatClock) last() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.receivedAt
+}
+
+// lastServerTime returns the server time of the most recent heartbeat, or the zero time if none
+// was received.
+func (c *heartbeatClock) lastServerTime() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.serverTime
+}
+
+// Subscription is implemented by running streams such as [PriceStream] and
+// [TransactionStream], so that supervising code and tests can handle them uniformly.
+type Subscription[T any] interface {
+	// Updates returns the channel on which items are delivered. It is closed when the
+	// subscription ends.
+	Updates() <-chan T
+	// Err returns the error that ended the subscription, or nil if it is still running, ended
+	// normally, or was stopped with Close.
+	Err() error
+	// Close stops the subscription and waits for it to end. It is safe to call more than once.
+	Close() error
+}
+
+var (
+	_ Subscription[PriceStreamItem]       = (*PriceStream)(nil)
+	_ Subscription[TransactionStreamItem] = (*TransactionStream)(nil)
+)
+
+// stream runs a streamLoop in its own goroutine and owns the channel the items are sent on.
+// The channel is closed when the stream ends, after the terminating error has been recorded.
+type stream[T any] struct {
+	updates chan T
+	done    chan struct{}
+	cancel  context.CancelFunc
+	closed  atomic.Bool
+	mu      sync.Mutex
+	err     error
+}
+
+func startStream[T any](
+	ctx context.Context,
+	c *StreamClient,
+	path string,
+	values url.Values,
+	parse func(json.RawMessage) (T, bool, error),
+) *stream[T] {
+	ctx, cancel := context.WithCancel(ctx)
+	s := &stream[T]{
+		updates: make(chan T),
+		done:    make(chan struct{}),
+		cancel:  cancel,
+	}
+	go func() {
+		defer close(s.done)
+		defer cancel()
+		err := streamLoop(ctx, c, path, values, s.updates, nil, parse)
+		s.mu.Lock()
+		s.err = err
+		s.mu.Unlock()
+		close(s.updates)
+	}()
+	return s
+}
+
+func (s *stream[T]) getErr() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed.Load() && errors.Is(s.err, context.Canceled) {
+		return nil
+	}
+	return s.err
+}
+
+func (s *stream[T]) close() error {
+	s.closed.Store(true)
+	s.cancel()
+	<-s.done
+	return nil
+}
+
+// AccountTransactionStreamItem is a [TransactionStreamItem] labeled with the Account whose
+// transaction stream it was received on.
+type AccountTransactionStreamItem struct {
+	// AccountID is the Account the item belongs to.
+	AccountID AccountID
+	// Item is the Transaction or heartbeat received on the Account's stream.
+	Item TransactionStreamItem
+}
+
+// StreamManager maintains transaction streams for several Accounts concurrently and merges
+// their items into a single channel. Each Account's stream is reconnected independently when
+// it ends or fails. Create one with [NewStreamManager].
+type StreamManager struct {
+	client          *StreamClient
+	accountIDs      []AccountID
+	reconnectDelay  time.Duration
+	onError         func(AccountID, error)
+	status          *StatusClient
+	incidentBackoff int
+}
+
+// NewStreamManager creates a new StreamManager that opens a transaction stream for each of
+// the given Accounts using the credentials and base URL of client. The Account configured on
+// client via [WithAccountID] is not used. The default reconnect delay is 5 seconds.
+func NewStreamManager(client *StreamClient, accountIDs ...AccountID) *StreamManager {
+	return &StreamManager{
+		client:          client,
+		accountIDs:      accountIDs,
+		reconnectDelay:  5 * time.Second,
+		incidentBackoff: 6,
+	}
+}
+
+// SetReconnectDelay sets how long to wait before reconnecting an Account's stream after it
+// has ended or failed.
+func (m *StreamManager) SetReconnectDelay(delay time.Duration) *StreamManager {
+	m.reconnectDelay = delay
+	return m
+}
+
+// SetErrorHandler sets a function that is called whenever an Account's stream fails. The
+// stream is reconnected after the handler returns. The handler may be called concurrently
+// for different Accounts.
+func (m *StreamManager) SetErrorHandler(handler func(AccountID, error)) *StreamManager {
+	m.onError = handler
+	return m
+}
+
+// SetStatusClient makes the manager consult the OANDA API status service before reconnecting a
+// stream. While an incident or maintenance is declared, the reconnect delay is multiplied by
+// the incident backoff factor (6 by default, see [StreamManager.SetIncidentBackoff]).
+func (m *StreamManager) SetStatusClient(status *StatusClient) *StreamManager {
+	m.status = status
+	return m
+}
+
+// SetIncidentBackoff sets the factor the reconnect delay is multiplied by while the status
+// service reports an incident. It has no effect unless a status client is set.
+func (m *StreamManager) SetIncidentBackoff(factor int) *StreamManager {
+	m.incidentBackoff = factor
+	return m
+}
+
+// nextReconnectDelay returns how long to wait before reconnecting. Failures to reach the
+// status service are ignored and result in the regular delay.
+func (m *StreamManager) nextReconnectDelay(ctx context.Context) time.Duration {
+	if m.status == nil || m.incidentBackoff <= 1 {
+		return m.reconnectDelay
+	}
+	status, err := m.status.Health(ctx)
+	if err != nil || !status.Incident() {
+		return m.reconnectDelay
+	}
+	return m.reconnectDelay * time.Duration(m.incidentBackoff)
+}
+
+// Run streams Transactions for all Accounts managed by m and sends them, labeled with their
+// Account, to ch. Run blocks until ctx is cancelled and every Account's stream has stopped,
+// then returns the context's error. Run does not close ch.
+func (m *StreamManager) Run(ctx context.Context, ch chan<- AccountTransactionStreamItem) error {
+	var wg sync.WaitGroup
+	for _, accountID := range m.accountIDs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.runAccount(ctx, accountID, ch)
+		}()
+	}
+	wg.Wait()
+	return ctx.Err()
+}
+
+func (m *StreamManager) runAccount(ctx context.Context, accountID AccountID, ch chan<- AccountTransactionStreamItem) {
+	client := &StreamClient{clientConfig: m.client.clientConfig}
+	client.accountID = accountID
+	path := fmt.Sprintf("/v3/accounts/%s/transactions/stream", accountID)
+	parse := func(raw json.RawMessage) (AccountTransactionStreamItem, bool, error) {
+		item, ok, err := parseTransactionStreamItem(raw)
+		return AccountTransactionStreamItem{AccountID: accountID, Item: item}, ok, err
+	}
+	for {
+		err := streamLoop(ctx, client, path, nil, ch, nil, parse)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil && m.onError != nil {
+			m.onError(accountID, err)
+		}
+		timer := time.NewTimer(m.nextReconnectDelay(ctx))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
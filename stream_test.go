@@ -0,0 +1,330 @@
+package oanda
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStreamManager(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accountID := strings.Split(r.URL.Path, "/")[3]
+		_, _ = fmt.Fprintf(w, `{"type":"HEARTBEAT","lastTransactionID":"%s","time":"2024-01-01T00:00:00Z"}`+"\n", accountID)
+	}))
+	defer server.Close()
+
+	client := NewStreamClient("api-key", WithBaseURL(server.URL))
+	manager := NewStreamManager(client, "1", "2").SetReconnectDelay(10 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	defer cancel()
+	ch := make(chan AccountTransactionStreamItem)
+	errCh := make(chan error, 1)
+	go func() {
+		// Run has returned, so nothing sends on ch anymore and closing it ends the drain below.
+		defer close(ch)
+		errCh <- manager.Run(ctx, ch)
+	}()
+
+	seen := make(map[AccountID]int)
+	for len(seen) < 2 || seen["1"] < 2 || seen["2"] < 2 {
+		var item AccountTransactionStreamItem
+		select {
+		case item = <-ch:
+		case <-ctx.Done():
+			t.Fatalf("timed out waiting for items: %v", seen)
+		}
+		if item.Item.GetID() != item.AccountID {
+			t.Errorf("got item %s labeled with account %s", item.Item.GetID(), item.AccountID)
+		}
+		seen[item.AccountID]++
+	}
+	cancel()
+	go func() {
+		for range ch {
+		}
+	}()
+	if err := <-errCh; err != context.Canceled {
+		t.Errorf("got error %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestStreamClient_PriceStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintln(w, `{"type":"PRICE","instrument":"EUR_USD","time":"2024-01-01T00:00:00Z"}`)
+		_, _ = fmt.Fprintln(w, `{"type":"HEARTBEAT","time":"2024-01-01T00:00:05Z"}`)
+	}))
+	defer server.Close()
+
+	client := NewStreamClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+	stream, err := client.PriceStream(t.Context(), NewPriceStreamRequest("EUR_USD"))
+	if err != nil {
+		t.Fatalf("failed to open price stream: %v", err)
+	}
+	var items []PriceStreamItem
+	for item := range stream.Updates() {
+		items = append(items, item)
+	}
+	if err := stream.Err(); err != nil {
+		t.Errorf("got error: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("got %d items, want 2", len(items))
+	}
+	want := time.Date(2024, 1, 1, 0, 0, 5, 0, time.UTC)
+	if got := stream.LastHeartbeatServerTime(); !got.Equal(want) {
+		t.Errorf("got last heartbeat server time %v, want %v", got, want)
+	}
+	if got := stream.LastHeartbeat(); got.IsZero() || got.After(time.Now()) || got.Year() == 2024 {
+		t.Errorf("got last heartbeat %v, want the local time it was received", got)
+	}
+}
+
+func TestStreamClient_TransactionStreamCancel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintln(w, `{"type":"HEARTBEAT","lastTransactionID":"1","time":"2024-01-01T00:00:00Z"}`)
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := NewStreamClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+	ctx, cancel := context.WithCancel(t.Context())
+	stream, err := client.TransactionStream(ctx)
+	if err != nil {
+		t.Fatalf("failed to open transaction stream: %v", err)
+	}
+	<-stream.Updates()
+	cancel()
+	for range stream.Updates() {
+	}
+	if err := stream.Err(); err == nil {
+		t.Error("expected an error after cancellation")
+	}
+}
+
+func TestSubscription_Close(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintln(w, `{"type":"HEARTBEAT","lastTransactionID":"1","time":"2024-01-01T00:00:00Z"}`)
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := NewStreamClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+	transactions, err := client.TransactionStream(t.Context())
+	if err != nil {
+		t.Fatalf("failed to open transaction stream: %v", err)
+	}
+	prices, err := client.PriceStream(t.Context(), NewPriceStreamRequest("EUR_USD"))
+	if err != nil {
+		t.Fatalf("failed to open price stream: %v", err)
+	}
+	for _, sub := range []interface {
+		Err() error
+		Close() error
+	}{transactions, prices} {
+		if err := sub.Close(); err != nil {
+			t.Errorf("failed to close subscription: %v", err)
+		}
+		if err := sub.Close(); err != nil {
+			t.Errorf("failed to close subscription twice: %v", err)
+		}
+		if err := sub.Err(); err != nil {
+			t.Errorf("got error after close: %v", err)
+		}
+	}
+	if _, ok := <-transactions.Updates(); ok {
+		t.Error("updates channel is still open after close")
+	}
+}
+
+func TestStreamClient_TransactionSince(t *testing.T) {
+	transaction := func(id int) string {
+		return fmt.Sprintf(`{"type":"DAILY_FINANCING","id":"%d","time":"2024-01-01T00:00:00Z"}`, id)
+	}
+	var pages []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/summary"):
+			_, _ = fmt.Fprint(w, `{"account":{},"lastTransactionID":"1200"}`)
+		case strings.HasSuffix(r.URL.Path, "/idrange"):
+			from, to := r.URL.Query().Get("from"), r.URL.Query().Get("to")
+			pages = append(pages, from+"-"+to)
+			var f, l int
+			_, _ = fmt.Sscan(from, &f)
+			_, _ = fmt.Sscan(to, &l)
+			var items []string
+			for id := f; id <= l; id++ {
+				items = append(items, transaction(id))
+			}
+			_, _ = fmt.Fprintf(w, `{"transactions":[%s],"lastTransactionID":"1203"}`, strings.Join(items, ","))
+		case strings.HasSuffix(r.URL.Path, "/stream"):
+			_, _ = fmt.Fprintln(w, transaction(1204))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+	streamClient := NewStreamClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+	ch := make(chan TransactionStreamItem)
+	errCh := make(chan error, 1)
+	go func() {
+		defer close(ch)
+		errCh <- streamClient.TransactionSince(t.Context(), client, "100", ch, nil)
+	}()
+
+	next := 101
+	gaps := 0
+	for item := range ch {
+		if item.GetType() == TransactionTypeGapDetected {
+			gaps++
+			continue
+		}
+		if want := fmt.Sprint(next); item.GetID() != want {
+			t.Fatalf("got transaction %s, want %s", item.GetID(), want)
+		}
+		next++
+	}
+	if err := <-errCh; err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	if next != 1205 || gaps != 1 {
+		t.Errorf("got last transaction %d and %d gaps, want 1204 and 1", next-1, gaps)
+	}
+	want := []string{"101-600", "601-1100", "1101-1200", "1201-1203"}
+	if strings.Join(pages, ",") != strings.Join(want, ",") {
+		t.Errorf("got pages %v, want %v", pages, want)
+	}
+}
+
+func TestStreamClient_TransactionWithGapDetection_Heartbeat(t *testing.T) {
+	transaction := func(id int) string {
+		return fmt.Sprintf(`{"type":"DAILY_FINANCING","id":"%d","time":"2024-01-01T00:00:00Z"}`, id)
+	}
+	var pages []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/idrange"):
+			var f, l int
+			_, _ = fmt.Sscan(r.URL.Query().Get("from"), &f)
+			_, _ = fmt.Sscan(r.URL.Query().Get("to"), &l)
+			pages = append(pages, fmt.Sprintf("%d-%d", f, l))
+			var items []string
+			for id := f; id <= l; id++ {
+				items = append(items, transaction(id))
+			}
+			_, _ = fmt.Fprintf(w, `{"transactions":[%s]}`, strings.Join(items, ","))
+		case strings.HasSuffix(r.URL.Path, "/stream"):
+			// 101 is repeated; 102 and 103 are lost, the heartbeat reveals them before 104 is
+			// created, and 103 arrives late.
+			for _, line := range []string{transaction(101), transaction(101), `{"type":"HEARTBEAT","lastTransactionID":"103","time":"2024-01-01T00:00:00Z"}`,
+				transaction(103), transaction(104), `{"type":"HEARTBEAT","lastTransactionID":"104","time":"2024-01-01T00:00:00Z"}`} {
+				_, _ = fmt.Fprintln(w, line)
+			}
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+	streamClient := NewStreamClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+	ch := make(chan TransactionStreamItem)
+	errCh := make(chan error, 1)
+	go func() {
+		defer close(ch)
+		errCh <- streamClient.TransactionWithGapDetection(t.Context(), client, ch, nil)
+	}()
+	var items []string
+	for item := range ch {
+		switch item := item.(type) {
+		case TransactionGapDetected:
+			items = append(items, "gap "+item.From+"-"+item.To)
+		case TransactionHeartbeat:
+			items = append(items, "heartbeat "+item.LastTransactionID)
+		default:
+			items = append(items, item.GetID())
+		}
+	}
+	if err := <-errCh; err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	want := "101,gap 102-103,102,103,heartbeat 103,104,heartbeat 104"
+	if strings.Join(items, ",") != want {
+		t.Errorf("got items %v, want %s", items, want)
+	}
+	if strings.Join(pages, ",") != "102-103" {
+		t.Errorf("got pages %v, want 102-103", pages)
+	}
+
+	other := NewClient("api-key", WithBaseURL(server.URL), WithAccountID("2"))
+	if err := streamClient.TransactionWithGapDetection(t.Context(), other, ch, nil); err == nil {
+		t.Error("got no error for a backfill client of another account")
+	}
+	if err := streamClient.TransactionSince(t.Context(), other, "100", ch, nil); err == nil {
+		t.Error("got no error for a backfill client of another account")
+	}
+}
+
+func TestStreamClient_TransactionFeed(t *testing.T) {
+	transaction := func(id int) string {
+		return fmt.Sprintf(`{"type":"DAILY_FINANCING","id":"%d","time":"2024-01-01T00:00:00Z"}`, id)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/summary"):
+			_, _ = fmt.Fprint(w, `{"account":{},"lastTransactionID":"105"}`)
+		case strings.HasSuffix(r.URL.Path, "/idrange"):
+			var f, l int
+			_, _ = fmt.Sscan(r.URL.Query().Get("from"), &f)
+			_, _ = fmt.Sscan(r.URL.Query().Get("to"), &l)
+			var items []string
+			for id := f; id <= l; id++ {
+				items = append(items, transaction(id))
+			}
+			_, _ = fmt.Fprintf(w, `{"transactions":[%s]}`, strings.Join(items, ","))
+		case strings.HasSuffix(r.URL.Path, "/stream"):
+			// The stream repeats a Transaction and skips two before continuing.
+			for _, line := range []string{transaction(105), `{"type":"HEARTBEAT","lastTransactionID":"105","time":"2024-01-01T00:00:00Z"}`,
+				transaction(108), transaction(108), transaction(109)} {
+				_, _ = fmt.Fprintln(w, line)
+			}
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+	streamClient := NewStreamClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+	var ids []string
+	for transaction, err := range streamClient.TransactionFeed(t.Context(), client, "100") {
+		if err != nil {
+			t.Fatalf("got error: %v", err)
+		}
+		ids = append(ids, transaction.GetID())
+	}
+	if want := "101,102,103,104,105,106,107,108,109"; strings.Join(ids, ",") != want {
+		t.Errorf("got transactions %v, want %s", ids, want)
+	}
+
+	ids = nil
+	for transaction, err := range streamClient.TransactionFeed(t.Context(), client, "103") {
+		if err != nil {
+			t.Fatalf("got error: %v", err)
+		}
+		if ids = append(ids, transaction.GetID()); len(ids) == 2 {
+			break
+		}
+	}
+	if strings.Join(ids, ",") != "104,105" {
+		t.Errorf("got transactions %v, want 104 and 105", ids)
+	}
+
+	for _, err := range streamClient.TransactionFeed(t.Context(), client, "abc") {
+		if err == nil {
+			t.Error("got no error for an invalid transaction ID")
+		}
+	}
+}
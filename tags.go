@@ -0,0 +1,132 @@
+package oanda
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// TagRequest selects the Orders and Trades whose client extensions are updated by
+// [Client.TagAll], and the tag and comment applied to them. Use NewTagRequest to create a new
+// request and the builder methods to configure it.
+type TagRequest struct {
+	// Orders are the Orders to update.
+	Orders []OrderSpecifier
+	// Trades are the Trades to update.
+	Trades []TradeSpecifier
+	// Tag is the tag to apply, or nil to leave the tags unchanged.
+	Tag *ClientTag
+	// Comment is the comment to apply, or nil to leave the comments unchanged.
+	Comment *ClientComment
+}
+
+// NewTagRequest creates a new TagRequest that selects no Orders or Trades.
+func NewTagRequest() *TagRequest {
+	return &TagRequest{}
+}
+
+// AddOrders adds Orders to update.
+func (r *TagRequest) AddOrders(orders ...OrderSpecifier) *TagRequest {
+	r.Orders = append(r.Orders, orders...)
+	return r
+}
+
+// AddTrades adds Trades to update.
+func (r *TagRequest) AddTrades(trades ...TradeSpecifier) *TagRequest {
+	r.Trades = append(r.Trades, trades...)
+	return r
+}
+
+// SetTag sets the tag to apply.
+func (r *TagRequest) SetTag(tag ClientTag) *TagRequest {
+	r.Tag = &tag
+	return r
+}
+
+// SetComment sets the comment to apply.
+func (r *TagRequest) SetComment(comment ClientComment) *TagRequest {
+	r.Comment = &comment
+	return r
+}
+
+func (r *TagRequest) validate() error {
+	if r.Tag == nil && r.Comment == nil {
+		return errors.New("tag or comment is required")
+	}
+	return nil
+}
+
+// clientExtensions returns the client extensions to send. The client ID is left out so that
+// the existing IDs are kept.
+func (r *TagRequest) clientExtensions() *ClientExtensions {
+	return &ClientExtensions{Tag: r.Tag, Comment: r.Comment}
+}
+
+// TagResult is the outcome of updating the client extensions of a single Order or Trade with
+// [Client.TagAll]. Exactly one of OrderSpecifier and TradeSpecifier is set.
+type TagResult struct {
+	// OrderSpecifier is the Order that was updated.
+	OrderSpecifier OrderSpecifier
+	// TradeSpecifier is the Trade that was updated.
+	TradeSpecifier TradeSpecifier
+	// Err is the error that made the update fail, or nil if it succeeded.
+	Err error
+}
+
+// tagAllConcurrency is the maximum number of update requests TagAll has in flight.
+const tagAllConcurrency = 8
+
+// TagAll applies the tag and comment of req to the client extensions of every Order and Trade
+// it selects, for example to mark everything belonging to one strategy run. The client IDs of
+// the Orders and Trades are left unchanged. The updates are sent concurrently; the result of
+// each update is reported in the returned slice, Orders first and in the order they were
+// added. The error joins the errors of the failed updates, or reports an invalid request.
+func (c *Client) TagAll(ctx context.Context, req *TagRequest) ([]TagResult, error) {
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+	extensions := req.clientExtensions()
+	results := make([]TagResult, len(req.Orders)+len(req.Trades))
+	sem := make(chan struct{}, tagAllConcurrency)
+	var wg sync.WaitGroup
+	run := func(i int, update func() error) {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i].Err = update()
+		}()
+	}
+	for i, specifier := range req.Orders {
+		results[i].OrderSpecifier = specifier
+		run(i, func() error {
+			_, err := c.Order.UpdateClientExtensions(ctx, specifier,
+				OrderUpdateClientExtensionsRequest{ClientExtensions: extensions})
+			return err
+		})
+	}
+	for i, specifier := range req.Trades {
+		i += len(req.Orders)
+		results[i].TradeSpecifier = specifier
+		run(i, func() error {
+			_, err := c.Trade.UpdateClientExtensions(ctx, specifier,
+				TradeUpdateClientExtensionsRequest{ClientExtensions: extensions})
+			return err
+		})
+	}
+	wg.Wait()
+
+	var errs []error
+	for _, result := range results {
+		switch {
+		case result.Err == nil:
+		case result.OrderSpecifier != "":
+			errs = append(errs, fmt.Errorf("failed to tag order %s: %w", result.OrderSpecifier, result.Err))
+		default:
+			errs = append(errs, fmt.Errorf("failed to tag trade %s: %w", result.TradeSpecifier, result.Err))
+		}
+	}
+	return results, errors.Join(errs...)
+}
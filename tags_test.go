@@ -0,0 +1,64 @@
+package oanda
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestClient_TagAll(t *testing.T) {
+	var mu sync.Mutex
+	bodies := make(map[string]map[string]ClientExtensions)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || !strings.HasSuffix(r.URL.Path, "/clientExtensions") {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if strings.Contains(r.URL.Path, "/trades/2/") {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = fmt.Fprint(w, `{"errorMessage":"trade not found"}`)
+			return
+		}
+		var body map[string]ClientExtensions
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("failed to decode body: %v", err)
+		}
+		mu.Lock()
+		bodies[r.URL.Path] = body
+		mu.Unlock()
+		_, _ = fmt.Fprint(w, `{}`)
+	}))
+	defer server.Close()
+	client := NewClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+
+	if _, err := client.TagAll(t.Context(), NewTagRequest().AddOrders("1")); err == nil {
+		t.Error("got no error for request without tag or comment")
+	}
+
+	req := NewTagRequest().AddOrders("10", "11").AddTrades("1", "2").SetTag("run-42")
+	results, err := client.TagAll(t.Context(), req)
+	if len(results) != 4 {
+		t.Fatalf("got %d results, want 4", len(results))
+	}
+	if err == nil || !strings.Contains(err.Error(), "failed to tag trade 2") {
+		t.Errorf("got error %v, want failure for trade 2", err)
+	}
+	var notFound NotFound
+	if !errors.As(results[3].Err, &notFound) || results[3].TradeSpecifier != "2" {
+		t.Errorf("got result %+v, want NotFound for trade 2", results[3])
+	}
+	for _, path := range []string{
+		"/v3/accounts/1/orders/10/clientExtensions",
+		"/v3/accounts/1/orders/11/clientExtensions",
+		"/v3/accounts/1/trades/1/clientExtensions",
+	} {
+		ext, ok := bodies[path]["clientExtensions"]
+		if !ok || ext.Tag == nil || *ext.Tag != "run-42" || ext.ID != nil || ext.Comment != nil {
+			t.Errorf("%s: got body %+v, want tag run-42 only", path, bodies[path])
+		}
+	}
+}
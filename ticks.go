@@ -0,0 +1,305 @@
+package oanda
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// TickSink stores the prices recorded by a [TickRecorder]. The package provides
+// [MemoryTickSink] and [FileTickSink]; a database such as SQLite or InfluxDB is supported by
+// implementing the interface on top of a table or measurement of prices.
+type TickSink interface {
+	// WriteTicks stores a batch of prices, in the order they were received.
+	WriteTicks(ctx context.Context, ticks []ClientPrice) error
+	// Close flushes the prices written so far and releases the resources of the sink.
+	Close() error
+}
+
+const (
+	// tickBatchSize is the number of prices a TickRecorder writes at a time when no batch size
+	// is set.
+	tickBatchSize = 100
+	// tickFlushInterval is the longest a TickRecorder holds prices back when no flush interval
+	// is set.
+	tickFlushInterval = time.Second
+)
+
+// TickRecorder writes every price received on a pricing stream to a [TickSink] in batches, so
+// that users can build their own tick databases for research. Create one with
+// [NewTickRecorder].
+type TickRecorder struct {
+	sink          TickSink
+	batchSize     int
+	flushInterval time.Duration
+}
+
+// NewTickRecorder creates a new TickRecorder writing to sink in batches of 100 prices, at least
+// once a second.
+func NewTickRecorder(sink TickSink) *TickRecorder {
+	return &TickRecorder{sink: sink, batchSize: tickBatchSize, flushInterval: tickFlushInterval}
+}
+
+// SetBatchSize sets the number of prices written at a time.
+func (r *TickRecorder) SetBatchSize(batchSize int) *TickRecorder {
+	r.batchSize = max(batchSize, 1)
+	return r
+}
+
+// SetFlushInterval sets the longest time prices are held back before they are written, so that
+// a quiet market does not delay them. A zero interval only writes full batches.
+func (r *TickRecorder) SetFlushInterval(flushInterval time.Duration) *TickRecorder {
+	r.flushInterval = flushInterval
+	return r
+}
+
+// Record writes the prices received on a pricing stream channel, such as the one returned by
+// [PriceStream.Updates], to the sink until the channel is closed or ctx is done, and writes the
+// last batch before it returns. Heartbeats are skipped. It stops at the first error of the sink,
+// and returns ctx's error if ctx is done. Record returns the number of prices written; it does
+// not close the sink.
+func (r *TickRecorder) Record(ctx context.Context, items <-chan PriceStreamItem) (int, error) {
+	var flushes <-chan time.Time
+	if r.flushInterval > 0 {
+		ticker := time.NewTicker(r.flushInterval)
+		defer ticker.Stop()
+		flushes = ticker.C
+	}
+	recorded := 0
+	batch := make([]ClientPrice, 0, r.batchSize)
+	flush := func(ctx context.Context) error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := r.sink.WriteTicks(ctx, batch); err != nil {
+			return fmt.Errorf("failed to write %d ticks: %w", len(batch), err)
+		}
+		recorded += len(batch)
+		// The sink may keep the batch, so the next one gets its own array.
+		batch = make([]ClientPrice, 0, r.batchSize)
+		return nil
+	}
+	for {
+		select {
+		case item, ok := <-items:
+			if !ok {
+				return recorded, flush(ctx)
+			}
+			price, ok := item.(ClientPrice)
+			if !ok {
+				continue
+			}
+			batch = append(batch, price)
+			if len(batch) >= r.batchSize {
+				if err := flush(ctx); err != nil {
+					return recorded, err
+				}
+			}
+		case <-flushes:
+			if err := flush(ctx); err != nil {
+				return recorded, err
+			}
+		case <-ctx.Done():
+			if err := flush(context.WithoutCancel(ctx)); err != nil {
+				return recorded, err
+			}
+			return recorded, ctx.Err()
+		}
+	}
+}
+
+// MemoryTickSink is a [TickSink] that keeps prices in memory. Create one with
+// [NewMemoryTickSink].
+type MemoryTickSink struct {
+	mu    sync.Mutex
+	ticks []ClientPrice
+}
+
+// NewMemoryTickSink creates a new, empty MemoryTickSink.
+func NewMemoryTickSink() *MemoryTickSink {
+	return &MemoryTickSink{}
+}
+
+// WriteTicks stores ticks.
+func (s *MemoryTickSink) WriteTicks(_ context.Context, ticks []ClientPrice) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ticks = append(s.ticks, ticks...)
+	return nil
+}
+
+// Close does nothing.
+func (s *MemoryTickSink) Close() error {
+	return nil
+}
+
+// Ticks returns a copy of the stored prices.
+func (s *MemoryTickSink) Ticks() []ClientPrice {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.ticks)
+}
+
+// FileTickSink is a [TickSink] that writes prices to JSON Lines files in a directory, one price
+// per line. A new file is started for every rotation period of the time of the prices, named
+// after the start of the period such as "ticks-20240102T000000Z.jsonl", and, if a maximum size
+// is set, whenever the file would grow beyond it, such as "ticks-20240102T000000Z.1.jsonl".
+// Writing resumes at the end of the latest existing file of a period. Create one with
+// [NewFileTickSink].
+type FileTickSink struct {
+	dir      string
+	rotation time.Duration
+	maxSize  int64
+	mu       sync.Mutex
+	file     *os.File
+	w        *bufio.Writer
+	period   time.Time
+	seq      int
+	size     int64
+}
+
+// NewFileTickSink creates a new FileTickSink writing to the directory dir, which is created on
+// the first write. Files are rotated daily unless [FileTickSink.SetRotation] is called.
+func NewFileTickSink(dir string) *FileTickSink {
+	return &FileTickSink{dir: dir, rotation: 24 * time.Hour}
+}
+
+// SetRotation sets the period of time covered by each file, as with [time.Time.Truncate], so
+// that hourly and daily files start on the hour and at midnight UTC. A zero rotation writes all
+// prices to "ticks.jsonl".
+func (s *FileTickSink) SetRotation(rotation time.Duration) *FileTickSink {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rotation = rotation
+	return s
+}
+
+// SetMaxSize sets the size in bytes beyond which a file is not extended. A file always holds at
+// least one price. A zero maxSize does not limit the size of files.
+func (s *FileTickSink) SetMaxSize(maxSize int64) *FileTickSink {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.maxSize = maxSize
+	return s
+}
+
+// WriteTicks appends ticks to the files of their periods. Prices without a time are written to
+// the current file, or that of the current period if no file is open.
+func (s *FileTickSink) WriteTicks(_ context.Context, ticks []ClientPrice) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, tick := range ticks {
+		line, err := json.Marshal(tick)
+		if err != nil {
+			return err
+		}
+		line = append(line, '\n')
+		period := s.period
+		if s.rotation > 0 && (tick.Time.Time != nil || s.file == nil) {
+			t := time.Now()
+			if tick.Time.Time != nil {
+				t = *tick.Time.Time
+			}
+			period = t.UTC().Truncate(s.rotation)
+		}
+		if s.file == nil || !period.Equal(s.period) {
+			if err := s.open(period); err != nil {
+				return err
+			}
+		}
+		if s.maxSize > 0 && s.size > 0 && s.size+int64(len(line)) > s.maxSize {
+			if err := s.openNext(); err != nil {
+				return err
+			}
+		}
+		if _, err := s.w.Write(line); err != nil {
+			return err
+		}
+		s.size += int64(len(line))
+	}
+	if s.file == nil {
+		return nil
+	}
+	return s.w.Flush()
+}
+
+// Close flushes and closes the current file.
+func (s *FileTickSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closeFile()
+}
+
+// open closes the current file and opens the latest file of period.
+func (s *FileTickSink) open(period time.Time) error {
+	if err := s.closeFile(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return err
+	}
+	seq := 0
+	for {
+		if _, err := os.Stat(s.path(period, seq+1)); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				break
+			}
+			return err
+		}
+		seq++
+	}
+	return s.openFile(period, seq)
+}
+
+// openNext closes the current file and opens the next file of its period.
+func (s *FileTickSink) openNext() error {
+	if err := s.closeFile(); err != nil {
+		return err
+	}
+	return s.openFile(s.period, s.seq+1)
+}
+
+func (s *FileTickSink) openFile(period time.Time, seq int) error {
+	f, err := os.OpenFile(s.path(period, seq), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	s.file, s.w, s.period, s.seq, s.size = f, bufio.NewWriter(f), period, seq, info.Size()
+	return nil
+}
+
+func (s *FileTickSink) closeFile() error {
+	if s.file == nil {
+		return nil
+	}
+	f := s.file
+	s.file = nil
+	if err := s.w.Flush(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (s *FileTickSink) path(period time.Time, seq int) string {
+	name := "ticks"
+	if s.rotation > 0 {
+		name += "-" + period.Format("20060102T150405Z")
+	}
+	if seq > 0 {
+		name += fmt.Sprintf(".%d", seq)
+	}
+	return filepath.Join(s.dir, name+".jsonl")
+}
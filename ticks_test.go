@@ -0,0 +1,109 @@
+package oanda
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+type batchTickSink struct {
+	MemoryTickSink
+	batches []int
+	err     error
+}
+
+func (s *batchTickSink) WriteTicks(ctx context.Context, ticks []ClientPrice) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.batches = append(s.batches, len(ticks))
+	return s.MemoryTickSink.WriteTicks(ctx, ticks)
+}
+
+func TestTickRecorder(t *testing.T) {
+	base := time.Date(2024, 1, 2, 23, 59, 58, 0, time.UTC)
+	items := make(chan PriceStreamItem, 10)
+	for i := range 5 {
+		at := base.Add(time.Duration(i) * time.Second)
+		items <- ClientPrice{Instrument: "EUR_USD", Time: DateTime{&at}, Bids: []PriceBucket{{Price: "1.10000"}}}
+		if i == 2 {
+			items <- PricingHeartbeat{Type: "HEARTBEAT", Time: DateTime{&at}}
+		}
+	}
+	close(items)
+	sink := &batchTickSink{}
+	n, err := NewTickRecorder(sink).SetBatchSize(2).SetFlushInterval(0).Record(t.Context(), items)
+	if err != nil || n != 5 {
+		t.Fatalf("got %d ticks recorded (%v), want 5", n, err)
+	}
+	if !slices.Equal(sink.batches, []int{2, 2, 1}) || len(sink.Ticks()) != 5 {
+		t.Errorf("got batches %v", sink.batches)
+	}
+
+	items = make(chan PriceStreamItem, 1)
+	items <- ClientPrice{Instrument: "EUR_USD"}
+	failing := &batchTickSink{err: errors.New("disk full")}
+	if _, err := NewTickRecorder(failing).SetBatchSize(1).Record(t.Context(), items); err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Errorf("got error %v, want the sink's", err)
+	}
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	if _, err := NewTickRecorder(sink).Record(ctx, make(chan PriceStreamItem)); !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v, want context.Canceled", err)
+	}
+
+	line, err := json.Marshal(sink.Ticks()[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Files hold two prices at most.
+	maxSize := int64(2*len(line) + 2)
+	dir := t.TempDir()
+	files := NewFileTickSink(dir).SetMaxSize(maxSize)
+	if err := files.WriteTicks(t.Context(), sink.Ticks()); err != nil {
+		t.Fatalf("failed to write ticks: %v", err)
+	}
+	if err := files.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// Reopening resumes the latest file of the period.
+	more := sink.Ticks()[4:]
+	files = NewFileTickSink(dir).SetMaxSize(maxSize)
+	if err := files.WriteTicks(t.Context(), more); err != nil {
+		t.Fatal(err)
+	}
+	if err := files.Close(); err != nil {
+		t.Fatal(err)
+	}
+	lines := make(map[string]int)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		b, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines[entry.Name()] = strings.Count(string(b), "\n")
+	}
+	want := map[string]int{
+		"ticks-20240102T000000Z.jsonl":   2,
+		"ticks-20240103T000000Z.jsonl":   2,
+		"ticks-20240103T000000Z.1.jsonl": 2,
+	}
+	if len(lines) != len(want) {
+		t.Errorf("got files %v, want %v", lines, want)
+	}
+	for name, n := range want {
+		if lines[name] != n {
+			t.Errorf("got %d lines in %s, want %d", lines[name], name, n)
+		}
+	}
+}
@@ -6,8 +6,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"iter"
+	"math/big"
 	"net/http"
 	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 )
@@ -25,6 +28,17 @@ type TradeID = string
 // OANDA-assigned TradeID or the Trade's client-provided ClientID prefixed by the "@" symbol.
 type TradeSpecifier = string
 
+// ByTradeID returns the TradeSpecifier that refers to a Trade by its OANDA-assigned TradeID.
+func ByTradeID(id TradeID) TradeSpecifier {
+	return id
+}
+
+// ByClientTradeID returns the TradeSpecifier that refers to a Trade by its client-provided
+// ClientID. The "@" prefix is added unless id already has it.
+func ByClientTradeID(id ClientID) TradeSpecifier {
+	return clientSpecifier(id)
+}
+
 // Trade is the specification of a Trade within an Account. This includes the full representation
 // of the Trade's dependent Orders in addition to the IDs of those Orders.
 type Trade struct {
@@ -310,6 +324,49 @@ func (s *tradeService) List(ctx context.Context, req *TradeListRequest) (*TradeL
 	return doGet[TradeListResponse](s.client, ctx, path, v)
 }
 
+// tradePageSize is the page size Iterate uses when the request does not specify a count.
+const tradePageSize = 500
+
+// Iterate returns an iterator over all Trades matching req, from the most recent to the oldest,
+// such as all closed Trades of an Instrument with
+// NewTradeListRequest().SetStateFilter(TradeStateFilterClosed).SetInstrument(instrument). It
+// calls [tradeService.List] repeatedly, moving BeforeID past the oldest Trade of each page, until
+// a page comes back short. req.Count is used as the page size and defaults to 500; req itself is
+// not modified. Iteration stops after the first error, which is yielded with a zero Trade.
+func (s *tradeService) Iterate(ctx context.Context, req *TradeListRequest) iter.Seq2[Trade, error] {
+	return func(yield func(Trade, error) bool) {
+		page := *req
+		page.IDs = slices.Clone(req.IDs)
+		if page.Count == nil {
+			page.SetCount(tradePageSize)
+		}
+		for {
+			resp, err := s.List(ctx, &page)
+			if err != nil {
+				yield(Trade{}, err)
+				return
+			}
+			for _, trade := range resp.Trades {
+				// Skip the boundary Trade in case the server treats beforeID as inclusive.
+				if page.BeforeID != nil && trade.ID == *page.BeforeID {
+					continue
+				}
+				if !yield(trade, nil) {
+					return
+				}
+			}
+			if len(resp.Trades) < *page.Count || len(resp.Trades) == 0 {
+				return
+			}
+			last := resp.Trades[len(resp.Trades)-1].ID
+			if page.BeforeID != nil && last == *page.BeforeID {
+				return
+			}
+			page.SetBeforeID(last)
+		}
+	}
+}
+
 // ListOpen retrieves all currently open Trades for the Account configured via [WithAccountID].
 //
 // This corresponds to the OANDA API endpoint: GET /v3/accounts/{accountID}/openTrades
@@ -349,6 +406,9 @@ type TradeCloseRequest struct {
 }
 
 func (r TradeCloseRequest) body() (*bytes.Buffer, error) {
+	if err := validateUnits("units", r.Units, UnitsAll); err != nil {
+		return nil, err
+	}
 	jsonBody, err := json.Marshal(r)
 	if err != nil {
 		return nil, err
@@ -363,7 +423,70 @@ func NewTradeCloseRequest(units DecimalNumber) TradeCloseRequest {
 
 // NewTradeCloseALLRequest creates a request to fully close a Trade.
 func NewTradeCloseALLRequest() TradeCloseRequest {
-	return TradeCloseRequest{Units: "ALL"}
+	return TradeCloseRequest{Units: UnitsAll}
+}
+
+// NewTradePartialCloseRequest creates a request to close units of a Trade with currentUnits
+// open, such as the CurrentUnits of a Trade cached in an [AccountState]. units must be positive
+// and at most the magnitude of currentUnits; closing all of them, or passing [UnitsAll], creates
+// a request to fully close the Trade.
+func NewTradePartialCloseRequest(currentUnits, units DecimalNumber) (TradeCloseRequest, error) {
+	open, err := openTradeUnits(currentUnits)
+	if err != nil {
+		return TradeCloseRequest{}, err
+	}
+	if units == UnitsAll {
+		return NewTradeCloseALLRequest(), nil
+	}
+	if err := validateUnits("units", units); err != nil {
+		return TradeCloseRequest{}, err
+	}
+	u, err := units.Rat()
+	if err != nil {
+		return TradeCloseRequest{}, fmt.Errorf("invalid units: %w", err)
+	}
+	if u.Sign() <= 0 {
+		return TradeCloseRequest{}, fmt.Errorf("units must be positive, got %s", units)
+	}
+	switch u.Cmp(open) {
+	case 1:
+		return TradeCloseRequest{}, fmt.Errorf("cannot close %s units of a Trade with %s units open", units, currentUnits)
+	case 0:
+		return NewTradeCloseALLRequest(), nil
+	}
+	return NewTradeCloseRequest(units), nil
+}
+
+// NewTradeFractionCloseRequest creates a request to close fraction of a Trade with currentUnits
+// open, such as 0.5 to close half of it. The units are truncated to tradeUnitsPrecision, the
+// TradeUnitsPrecision of the Trade's Instrument. fraction must be greater than 0 and at most 1;
+// closing all of the units creates a request to fully close the Trade.
+func NewTradeFractionCloseRequest(currentUnits DecimalNumber, fraction float64, tradeUnitsPrecision int) (TradeCloseRequest, error) {
+	open, err := openTradeUnits(currentUnits)
+	if err != nil {
+		return TradeCloseRequest{}, err
+	}
+	if !(fraction > 0 && fraction <= 1) {
+		return TradeCloseRequest{}, fmt.Errorf("fraction must be greater than 0 and at most 1, got %v", fraction)
+	}
+	f := new(big.Rat).SetFloat64(fraction)
+	units := DecimalNumber(truncateDecimal(f.Mul(f, open), tradeUnitsPrecision))
+	if u, _ := units.Rat(); u.Sign() == 0 {
+		return TradeCloseRequest{}, fmt.Errorf("%v of %s units rounds to zero", fraction, currentUnits)
+	}
+	return NewTradePartialCloseRequest(currentUnits, units)
+}
+
+// openTradeUnits returns the magnitude of currentUnits, or an error if it is zero.
+func openTradeUnits(currentUnits DecimalNumber) (*big.Rat, error) {
+	open, err := currentUnits.Rat()
+	if err != nil {
+		return nil, fmt.Errorf("invalid current units: %w", err)
+	}
+	if open.Sign() == 0 {
+		return nil, errors.New("trade has no open units")
+	}
+	return open.Abs(open), nil
 }
 
 // TradeCloseResponse is the successful response returned by [Client.TradeClose].
@@ -429,13 +552,57 @@ func (s *tradeService) Close(ctx context.Context, specifier TradeSpecifier, req
 	}
 }
 
+// ClosePartial closes units of a Trade, after fetching the Trade to check them against its open
+// units as [NewTradePartialCloseRequest] does. Closing all of the open units fully closes the
+// Trade. To avoid the fetch, such as with the Trades of an [AccountState], use
+// [NewTradePartialCloseRequest] and [tradeService.Close].
+//
+// This corresponds to the OANDA API endpoint: PUT /v3/accounts/{accountID}/trades/{tradeSpecifier}/close
+//
+// Reference: https://developer.oanda.com/rest-live-v20/trade-ep/#collapse_endpoint_5
+func (s *tradeService) ClosePartial(ctx context.Context, specifier TradeSpecifier, units DecimalNumber) (*TradeCloseResponse, error) {
+	details, err := s.Details(ctx, specifier)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trade: %w", err)
+	}
+	req, err := NewTradePartialCloseRequest(details.Trade.CurrentUnits, units)
+	if err != nil {
+		return nil, err
+	}
+	return s.Close(ctx, specifier, req)
+}
+
+// CloseFraction closes fraction of a Trade, such as 0.5 to close half of it, after fetching the
+// Trade's open units and the precision of its Instrument from the Client's [InstrumentCache]. The
+// units are computed as [NewTradeFractionCloseRequest] does, and a fraction of 1 fully closes the
+// Trade.
+//
+// This corresponds to the OANDA API endpoint: PUT /v3/accounts/{accountID}/trades/{tradeSpecifier}/close
+//
+// Reference: https://developer.oanda.com/rest-live-v20/trade-ep/#collapse_endpoint_5
+func (s *tradeService) CloseFraction(ctx context.Context, specifier TradeSpecifier, fraction float64) (*TradeCloseResponse, error) {
+	details, err := s.Details(ctx, specifier)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trade: %w", err)
+	}
+	precision, err := s.client.Instruments().Precision(ctx, details.Trade.Instrument)
+	if err != nil {
+		return nil, err
+	}
+	req, err := NewTradeFractionCloseRequest(details.Trade.CurrentUnits, fraction, precision.TradeUnitsPrecision)
+	if err != nil {
+		return nil, err
+	}
+	return s.Close(ctx, specifier, req)
+}
+
 // TradeUpdateClientExtensionsRequest is the request body for updating client extensions on a Trade.
 type TradeUpdateClientExtensionsRequest struct {
 	ClientExtensions *ClientExtensions `json:"clientExtensions"`
 }
 
 func (r TradeUpdateClientExtensionsRequest) body() (*bytes.Buffer, error) {
-	jsonBody, err := json.Marshal(r.ClientExtensions)
+	jsonBody, err := json.Marshal(r)
 	if err != nil {
 		return nil, err
 	}
@@ -491,15 +658,228 @@ func (s *tradeService) UpdateClientExtensions(ctx context.Context, specifier Tra
 
 // TradeUpdateOrdersRequest is the request body for creating, replacing, or cancelling
 // a Trade's dependent Orders (Take Profit, Stop Loss, Trailing Stop Loss, Guaranteed Stop Loss).
+// Dependent Orders that are neither set nor cancelled are left unchanged.
 type TradeUpdateOrdersRequest struct {
 	TakeProfit         *TakeProfitDetails         `json:"takeProfit,omitempty"`
 	StopLoss           *StopLossDetails           `json:"stopLoss,omitempty"`
 	TrailingStopLoss   *TrailingStopLossDetails   `json:"trailingStopLoss,omitempty"`
 	GuaranteedStopLoss *GuaranteedStopLossDetails `json:"guaranteedStopLoss,omitempty"`
+
+	cancelTakeProfit         bool
+	cancelStopLoss           bool
+	cancelTrailingStopLoss   bool
+	cancelGuaranteedStopLoss bool
+	reference                *tradeReference
+}
+
+// tradeReference is the Trade side and market price that the prices of the dependent Orders
+// are checked against.
+type tradeReference struct {
+	long  bool
+	price PriceValue
+}
+
+// NewTradeUpdateOrdersRequest creates a new TradeUpdateOrdersRequest that leaves every
+// dependent Order unchanged.
+func NewTradeUpdateOrdersRequest() *TradeUpdateOrdersRequest {
+	return &TradeUpdateOrdersRequest{}
+}
+
+// SetTakeProfit creates the Trade's Take Profit Order, or replaces the existing one.
+func (r *TradeUpdateOrdersRequest) SetTakeProfit(details *TakeProfitDetails) *TradeUpdateOrdersRequest {
+	r.TakeProfit = details
+	r.cancelTakeProfit = false
+	return r
+}
+
+// CancelTakeProfit cancels the Trade's Take Profit Order.
+func (r *TradeUpdateOrdersRequest) CancelTakeProfit() *TradeUpdateOrdersRequest {
+	r.TakeProfit = nil
+	r.cancelTakeProfit = true
+	return r
+}
+
+// SetStopLoss creates the Trade's Stop Loss Order, or replaces the existing one.
+func (r *TradeUpdateOrdersRequest) SetStopLoss(details *StopLossDetails) *TradeUpdateOrdersRequest {
+	r.StopLoss = details
+	r.cancelStopLoss = false
+	return r
+}
+
+// CancelStopLoss cancels the Trade's Stop Loss Order.
+func (r *TradeUpdateOrdersRequest) CancelStopLoss() *TradeUpdateOrdersRequest {
+	r.StopLoss = nil
+	r.cancelStopLoss = true
+	return r
+}
+
+// SetTrailingStopLoss creates the Trade's Trailing Stop Loss Order, or replaces the existing one.
+func (r *TradeUpdateOrdersRequest) SetTrailingStopLoss(details *TrailingStopLossDetails) *TradeUpdateOrdersRequest {
+	r.TrailingStopLoss = details
+	r.cancelTrailingStopLoss = false
+	return r
+}
+
+// CancelTrailingStopLoss cancels the Trade's Trailing Stop Loss Order.
+func (r *TradeUpdateOrdersRequest) CancelTrailingStopLoss() *TradeUpdateOrdersRequest {
+	r.TrailingStopLoss = nil
+	r.cancelTrailingStopLoss = true
+	return r
+}
+
+// SetGuaranteedStopLoss creates the Trade's Guaranteed Stop Loss Order, or replaces the existing one.
+func (r *TradeUpdateOrdersRequest) SetGuaranteedStopLoss(details *GuaranteedStopLossDetails) *TradeUpdateOrdersRequest {
+	r.GuaranteedStopLoss = details
+	r.cancelGuaranteedStopLoss = false
+	return r
+}
+
+// CancelGuaranteedStopLoss cancels the Trade's Guaranteed Stop Loss Order.
+func (r *TradeUpdateOrdersRequest) CancelGuaranteedStopLoss() *TradeUpdateOrdersRequest {
+	r.GuaranteedStopLoss = nil
+	r.cancelGuaranteedStopLoss = true
+	return r
+}
+
+// SetReferencePrice checks the prices of the dependent Orders against price, the current price
+// of the Trade's instrument, before the request is sent. currentUnits are the Trade's current
+// units, whose sign gives its side: the take profit of a long Trade must be above price and its
+// stop losses below it, and the reverse for a short Trade.
+func (r *TradeUpdateOrdersRequest) SetReferencePrice(currentUnits DecimalNumber, price PriceValue) *TradeUpdateOrdersRequest {
+	r.reference = &tradeReference{long: !strings.HasPrefix(string(currentUnits), "-"), price: price}
+	return r
+}
+
+// validate checks that the request changes a dependent Order, that every dependent Order is
+// either set or cancelled, and that the details of those that are set are valid.
+func (r *TradeUpdateOrdersRequest) validate() error {
+	if r.TakeProfit == nil && r.StopLoss == nil && r.TrailingStopLoss == nil && r.GuaranteedStopLoss == nil &&
+		!r.cancelTakeProfit && !r.cancelStopLoss && !r.cancelTrailingStopLoss && !r.cancelGuaranteedStopLoss {
+		return errors.New("no dependent order to update")
+	}
+	if tp := r.TakeProfit; tp != nil {
+		if tp.Price == "" {
+			return errors.New("takeProfit.price must be set")
+		}
+		if err := validateGTD("takeProfit.gtdTime", tp.TimeInForce, tp.GtdTime); err != nil {
+			return err
+		}
+	}
+	if sl := r.StopLoss; sl != nil {
+		if err := validatePriceOrDistance("stopLoss", sl.Price, sl.Distance); err != nil {
+			return err
+		}
+		if err := validateGTD("stopLoss.gtdTime", sl.TimeInForce, sl.GtdTime); err != nil {
+			return err
+		}
+	}
+	if tsl := r.TrailingStopLoss; tsl != nil {
+		if err := validatePositiveDistance("trailingStopLoss.distance", tsl.Distance); err != nil {
+			return err
+		}
+		if err := validateGTD("trailingStopLoss.gtdTime", tsl.TimeInForce, tsl.GtdTime); err != nil {
+			return err
+		}
+	}
+	if gsl := r.GuaranteedStopLoss; gsl != nil {
+		if err := validatePriceOrDistance("guaranteedStopLoss", gsl.Price, gsl.Distance); err != nil {
+			return err
+		}
+		if err := validateGTD("guaranteedStopLoss.gtdTime", gsl.TimeInForce, gsl.GtdTime); err != nil {
+			return err
+		}
+	}
+	if r.reference != nil {
+		return r.validateSides()
+	}
+	return nil
+}
+
+// validateSides checks that the take profit price is on the profitable side of the reference
+// price and the stop loss prices on the losing side.
+func (r *TradeUpdateOrdersRequest) validateSides() error {
+	ref, err := r.reference.price.Rat()
+	if err != nil {
+		return fmt.Errorf("invalid reference price: %w", err)
+	}
+	check := func(field string, price *PriceValue, above bool) error {
+		if price == nil {
+			return nil
+		}
+		p, err := price.Rat()
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", field, err)
+		}
+		if cmp := p.Cmp(ref); above && cmp <= 0 {
+			return fmt.Errorf("%s %s must be above the current price %s", field, *price, r.reference.price)
+		} else if !above && cmp >= 0 {
+			return fmt.Errorf("%s %s must be below the current price %s", field, *price, r.reference.price)
+		}
+		return nil
+	}
+	long := r.reference.long
+	if r.TakeProfit != nil {
+		if err := check("takeProfit.price", &r.TakeProfit.Price, long); err != nil {
+			return err
+		}
+	}
+	if r.StopLoss != nil {
+		if err := check("stopLoss.price", r.StopLoss.Price, !long); err != nil {
+			return err
+		}
+	}
+	if r.GuaranteedStopLoss != nil {
+		if err := check("guaranteedStopLoss.price", r.GuaranteedStopLoss.Price, !long); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
+// validatePriceOrDistance checks that exactly one of price and distance of the stop loss field
+// is set, and that the distance is positive.
+func validatePriceOrDistance(field string, price *PriceValue, distance *DecimalNumber) error {
+	if price == nil && distance == nil {
+		return fmt.Errorf("%s: price or distance must be set", field)
+	}
+	if price != nil && distance != nil {
+		return fmt.Errorf("%s: price and distance cannot be set at the same time", field)
+	}
+	if distance != nil {
+		return validatePositiveDistance(field+".distance", *distance)
+	}
+	return nil
+}
+
+func validatePositiveDistance(field string, distance DecimalNumber) error {
+	d, err := distance.Rat()
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", field, err)
+	}
+	if d.Sign() <= 0 {
+		return fmt.Errorf("%s %s must be positive", field, distance)
+	}
+	return nil
+}
+
+// body validates the request and encodes it, sending null for the cancelled dependent Orders.
 func (r TradeUpdateOrdersRequest) body() (*bytes.Buffer, error) {
-	jsonBody, err := json.Marshal(r)
+	if err := r.validate(); err != nil {
+		return nil, err
+	}
+	fields := make(map[string]any)
+	set := func(name string, details any, isSet, cancel bool) {
+		if isSet {
+			fields[name] = details
+		} else if cancel {
+			fields[name] = nil
+		}
+	}
+	set("takeProfit", r.TakeProfit, r.TakeProfit != nil, r.cancelTakeProfit)
+	set("stopLoss", r.StopLoss, r.StopLoss != nil, r.cancelStopLoss)
+	set("trailingStopLoss", r.TrailingStopLoss, r.TrailingStopLoss != nil, r.cancelTrailingStopLoss)
+	set("guaranteedStopLoss", r.GuaranteedStopLoss, r.GuaranteedStopLoss != nil, r.cancelGuaranteedStopLoss)
+	jsonBody, err := json.Marshal(fields)
 	if err != nil {
 		return nil, err
 	}
@@ -546,7 +926,8 @@ func (r TradeUpdateOrdersErrorResponse) Error() string {
 }
 
 // UpdateOrders creates, replaces, or cancels a Trade's dependent Orders
-// (Take Profit, Stop Loss, Trailing Stop Loss, Guaranteed Stop Loss).
+// (Take Profit, Stop Loss, Trailing Stop Loss, Guaranteed Stop Loss) in one call. req is
+// validated before it is sent.
 //
 // This corresponds to the OANDA API endpoint: PUT /v3/accounts/{accountID}/trades/{tradeSpecifier}/orders
 //
@@ -1,6 +1,13 @@
 package oanda
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -97,3 +104,185 @@ func TestTradeService(t *testing.T) {
 		debugResponse(resp)
 	})
 }
+
+func TestTradeService_Iterate(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		query := r.URL.Query()
+		if query.Get("count") != "3" || query.Get("state") != "CLOSED" || query.Get("instrument") != "EUR_USD" {
+			t.Errorf("got query %s", r.URL.RawQuery)
+		}
+		before := 8
+		if b := query.Get("beforeID"); b != "" {
+			before, _ = strconv.Atoi(b)
+		}
+		var trades []string
+		for id := before - 1; id >= 1 && len(trades) < 3; id-- {
+			trades = append(trades, fmt.Sprintf(`{"id":"%d","instrument":"EUR_USD","state":"CLOSED"}`, id))
+		}
+		_, _ = fmt.Fprintf(w, `{"trades":[%s]}`, strings.Join(trades, ","))
+	}))
+	defer server.Close()
+	client := NewClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+
+	req := NewTradeListRequest().SetStateFilter(TradeStateFilterClosed).SetInstrument("EUR_USD").SetCount(3)
+	var ids []string
+	for trade, err := range client.Trade.Iterate(t.Context(), req) {
+		if err != nil {
+			t.Fatalf("got error: %v", err)
+		}
+		ids = append(ids, trade.ID)
+	}
+	if got := strings.Join(ids, ","); got != "7,6,5,4,3,2,1" {
+		t.Errorf("got trades %s, want 7,6,5,4,3,2,1", got)
+	}
+	if requests != 3 {
+		t.Errorf("got %d requests, want 3", requests)
+	}
+	if req.BeforeID != nil {
+		t.Errorf("request was modified: beforeID %s", *req.BeforeID)
+	}
+
+	requests = 0
+	for trade := range client.Trade.Iterate(t.Context(), req) {
+		if trade.ID == "6" {
+			break
+		}
+	}
+	if requests != 1 {
+		t.Errorf("got %d requests after break, want 1", requests)
+	}
+
+	for _, err := range client.Trade.Iterate(t.Context(), NewTradeListRequest().SetCount(501)) {
+		if err == nil {
+			t.Error("got no error for an invalid count")
+		}
+	}
+}
+
+func TestTradeService_ClosePartial(t *testing.T) {
+	var closed []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v3/accounts/1/trades/12", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `{"trade":{"id":"12","instrument":"EUR_USD","currentUnits":"-1000","state":"OPEN"}}`)
+	})
+	mux.HandleFunc("GET /v3/accounts/1/instruments", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `{"instruments":[{"name":"EUR_USD","pipLocation":-4,"displayPrecision":5,"tradeUnitsPrecision":0}]}`)
+	})
+	mux.HandleFunc("PUT /v3/accounts/1/trades/12/close", func(w http.ResponseWriter, r *http.Request) {
+		var req TradeCloseRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+		closed = append(closed, string(req.Units))
+		_, _ = fmt.Fprint(w, `{"lastTransactionID":"20"}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client := NewClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+
+	for _, units := range []DecimalNumber{"400", "1000", UnitsAll} {
+		if _, err := client.Trade.ClosePartial(t.Context(), "12", units); err != nil {
+			t.Errorf("failed to close %s units: %v", units, err)
+		}
+	}
+	for _, fraction := range []float64{0.5, 0.3333, 1} {
+		if _, err := client.Trade.CloseFraction(t.Context(), "12", fraction); err != nil {
+			t.Errorf("failed to close %v: %v", fraction, err)
+		}
+	}
+	if got := strings.Join(closed, ","); got != "400,ALL,ALL,500,333,ALL" {
+		t.Errorf("got closes %s, want 400,ALL,ALL,500,333,ALL", got)
+	}
+
+	closed = nil
+	for _, units := range []DecimalNumber{"1500", "-400", "0", UnitsNone, "abc"} {
+		if _, err := client.Trade.ClosePartial(t.Context(), "12", units); err == nil {
+			t.Errorf("got no error closing %s units", units)
+		}
+	}
+	for _, fraction := range []float64{0, 1.5, 0.0001} {
+		if _, err := client.Trade.CloseFraction(t.Context(), "12", fraction); err == nil {
+			t.Errorf("got no error closing %v", fraction)
+		}
+	}
+	if len(closed) != 0 {
+		t.Errorf("got closes %v for invalid units", closed)
+	}
+
+	if req, err := NewTradeFractionCloseRequest("10", 0.25, 1); err != nil || req.Units != "2.5" {
+		t.Errorf("got %+v (%v), want 2.5 units", req, err)
+	}
+	if _, err := NewTradePartialCloseRequest("0", "1"); err == nil {
+		t.Error("got no error for a closed trade")
+	}
+}
+
+func TestTradeService_UpdateOrders(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != "/v3/accounts/1/trades/12/orders" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var body strings.Builder
+		_, _ = io.Copy(&body, r.Body)
+		bodies = append(bodies, body.String())
+		_, _ = fmt.Fprint(w, `{"lastTransactionID":"20"}`)
+	}))
+	defer server.Close()
+	client := NewClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+
+	req := NewTradeUpdateOrdersRequest().
+		SetTakeProfit(NewTakeProfitDetails("1.1100")).
+		SetStopLoss(NewStopLossDetails().SetDistance("0.0050")).
+		CancelTrailingStopLoss().
+		SetReferencePrice("1000", "1.1000")
+	if _, err := client.Trade.UpdateOrders(t.Context(), "12", req); err != nil {
+		t.Fatalf("failed to update orders: %v", err)
+	}
+	var got map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(bodies[0]), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 || string(got["trailingStopLoss"]) != "null" ||
+		!strings.Contains(string(got["takeProfit"]), `"price":"1.1100"`) ||
+		!strings.Contains(string(got["stopLoss"]), `"distance":"0.0050"`) {
+		t.Errorf("got body %s", bodies[0])
+	}
+
+	tests := []struct {
+		name string
+		req  *TradeUpdateOrdersRequest
+		want string
+	}{
+		{"empty", NewTradeUpdateOrdersRequest(), "no dependent order"},
+		{"price and distance", NewTradeUpdateOrdersRequest().
+			SetStopLoss(NewStopLossDetails().SetPrice("1.0900").SetDistance("0.0100")), "cannot be set at the same time"},
+		{"no price or distance", NewTradeUpdateOrdersRequest().
+			SetGuaranteedStopLoss(NewGuaranteedStopLossDetails()), "price or distance must be set"},
+		{"negative distance", NewTradeUpdateOrdersRequest().
+			SetTrailingStopLoss(NewTrailingStopLossDetails("-0.0050")), "must be positive"},
+		{"long take profit below", NewTradeUpdateOrdersRequest().
+			SetTakeProfit(NewTakeProfitDetails("1.0900")).SetReferencePrice("1000", "1.1000"), "must be above"},
+		{"short stop loss below", NewTradeUpdateOrdersRequest().
+			SetStopLoss(NewStopLossDetails().SetPrice("1.0900")).SetReferencePrice("-1000", "1.1000"), "must be above"},
+		{"short take profit above", NewTradeUpdateOrdersRequest().
+			SetTakeProfit(NewTakeProfitDetails("1.1100")).SetReferencePrice("-1000", "1.1000"), "must be below"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := client.Trade.UpdateOrders(t.Context(), "12", tt.req); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got error %v, want %q", err, tt.want)
+			}
+		})
+	}
+	if len(bodies) != 1 {
+		t.Errorf("got %d requests, want 1", len(bodies))
+	}
+
+	cancelled := NewTradeUpdateOrdersRequest().SetTakeProfit(NewTakeProfitDetails("1.1100")).CancelTakeProfit()
+	if body, err := cancelled.body(); err != nil || body.String() != `{"takeProfit":null}` {
+		t.Errorf("got body %v (%v), want a cancelled take profit", body, err)
+	}
+}
@@ -0,0 +1,372 @@
+package oanda
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TrailingState is the state of a Trade managed by a [TrailingStopManager], passed to its
+// [TrailingRule]s. Prices are the prices the Trade closes at: the bid for a long Trade and the
+// ask for a short one.
+type TrailingState struct {
+	// TradeID is the ID of the Trade.
+	TradeID TradeID
+	// Instrument is the Instrument of the Trade.
+	Instrument InstrumentName
+	// Long is true for a long Trade and false for a short one.
+	Long bool
+	// Entry is the price the Trade was opened at.
+	Entry float64
+	// OpenTime is the time the Trade was opened.
+	OpenTime time.Time
+	// Price is the latest price.
+	Price float64
+	// Time is the time of the latest price.
+	Time time.Time
+	// Best is the most favourable price since the manager started tracking the Trade, or Entry
+	// if no price has moved beyond it.
+	Best float64
+	// Stop is the price of the Trade's Stop Loss Order, or 0 if it has none.
+	Stop float64
+}
+
+// Excursion returns how far the price has moved in the Trade's favour from Entry at best, in
+// price units.
+func (s TrailingState) Excursion() float64 {
+	if s.Long {
+		return s.Best - s.Entry
+	}
+	return s.Entry - s.Best
+}
+
+// behind returns the price distance behind from on the losing side of the Trade.
+func (s TrailingState) behind(from, distance float64) float64 {
+	if s.Long {
+		return from - distance
+	}
+	return from + distance
+}
+
+// tighter reports whether stop a is closer to the price than stop b for the side of the Trade.
+func (s TrailingState) tighter(a, b float64) bool {
+	if s.Long {
+		return a > b
+	}
+	return a < b
+}
+
+// TrailingRule returns the stop loss price a Trade should have in state, or false to leave it.
+// A [TrailingStopManager] only ever moves a stop loss towards the price, so a rule may return a
+// price looser than the current one. The rules built by [DistanceTrailing], [StepTrailing],
+// [ATRTrailing] and [TimeTightening] can be combined; the tightest stop wins.
+type TrailingRule func(state TrailingState) (float64, bool)
+
+// DistanceTrailing trails the stop loss distance behind the best price, like OANDA's Trailing
+// Stop Loss Orders but evaluated on the client side.
+func DistanceTrailing(distance float64) TrailingRule {
+	return func(state TrailingState) (float64, bool) {
+		return state.behind(state.Best, distance), true
+	}
+}
+
+// StepTrailing moves the stop loss in steps: it starts distance behind the entry price, and
+// moves by step every time the best price has moved another step in the Trade's favour. It
+// leaves the stop loss alone until the first step.
+func StepTrailing(step, distance float64) TrailingRule {
+	return func(state TrailingState) (float64, bool) {
+		if step <= 0 {
+			return 0, false
+		}
+		steps := math.Floor(state.Excursion() / step)
+		if steps < 1 {
+			return 0, false
+		}
+		return state.behind(state.Entry, distance-steps*step), true
+	}
+}
+
+// ATRTrailing trails the stop loss multiplier times the average true range behind the best
+// price, as a chandelier stop. atr returns the current average true range of an Instrument in
+// price units, such as the latest value of indicators.ATR, or 0 while it is unknown.
+func ATRTrailing(multiplier float64, atr func(InstrumentName) float64) TrailingRule {
+	return func(state TrailingState) (float64, bool) {
+		value := atr(state.Instrument)
+		if value <= 0 {
+			return 0, false
+		}
+		return state.behind(state.Best, multiplier*value), true
+	}
+}
+
+// TimeTightening trails the stop loss behind the best price by a distance that shrinks with the
+// age of the Trade: from initial when it is opened to final once it has been open for over,
+// linearly, so that a Trade that does not move gives back less over time.
+func TimeTightening(initial, final float64, over time.Duration) TrailingRule {
+	return func(state TrailingState) (float64, bool) {
+		if state.OpenTime.IsZero() || over <= 0 {
+			return 0, false
+		}
+		elapsed := min(max(float64(state.Time.Sub(state.OpenTime))/float64(over), 0), 1)
+		return state.behind(state.Best, initial+(final-initial)*elapsed), true
+	}
+}
+
+// TrailingStopUpdate reports that a [TrailingStopManager] replaced the Stop Loss Order of a
+// Trade.
+type TrailingStopUpdate struct {
+	// TradeID is the ID of the Trade.
+	TradeID TradeID
+	// Instrument is the Instrument of the Trade.
+	Instrument InstrumentName
+	// Previous is the price of the replaced Stop Loss Order, or empty if the Trade had none.
+	Previous PriceValue
+	// Stop is the price of the new Stop Loss Order.
+	Stop PriceValue
+	// Price is the price that moved the stop loss.
+	Price ClientPrice
+	// Response is the response of [tradeService.UpdateOrders].
+	Response *TradeUpdateOrdersResponse
+}
+
+// TrailingStopManager implements trailing rules richer than OANDA's Trailing Stop Loss Orders
+// on the client side. It evaluates its [TrailingRule]s for the tracked Trades against the prices
+// of a pricing stream, and replaces a Trade's Stop Loss Order when they move it towards the
+// price. Create one with [NewTrailingStopManager].
+type TrailingStopManager struct {
+	client   *Client
+	rules    []TrailingRule
+	minStep  float64
+	onUpdate func(TrailingStopUpdate)
+	onError  func(error)
+
+	mu     sync.Mutex
+	trades map[TradeID]*trailingTrade
+}
+
+type trailingTrade struct {
+	state     TrailingState
+	units     DecimalNumber
+	precision InstrumentPrecision
+}
+
+// NewTrailingStopManager creates a new TrailingStopManager that replaces stop losses with client
+// according to rules.
+func NewTrailingStopManager(client *Client, rules ...TrailingRule) *TrailingStopManager {
+	return &TrailingStopManager{client: client, rules: rules, trades: make(map[TradeID]*trailingTrade)}
+}
+
+// SetMinStep sets the distance, in price units, a stop loss must move by before it is replaced,
+// so that a stop loss is not replaced on every price. Default is 0, any move.
+func (m *TrailingStopManager) SetMinStep(step float64) *TrailingStopManager {
+	m.minStep = step
+	return m
+}
+
+// OnUpdate sets a callback called with every replaced stop loss.
+func (m *TrailingStopManager) OnUpdate(fn func(TrailingStopUpdate)) *TrailingStopManager {
+	m.onUpdate = fn
+	return m
+}
+
+// SetErrorHandler sets a function that is called with the errors of processing a price while
+// [TrailingStopManager.Run] is running.
+func (m *TrailingStopManager) SetErrorHandler(handler func(error)) *TrailingStopManager {
+	m.onError = handler
+	return m
+}
+
+// Track fetches the open Trade identified by specifier and starts managing its stop loss.
+func (m *TrailingStopManager) Track(ctx context.Context, specifier TradeSpecifier) error {
+	resp, err := m.client.Trade.Details(ctx, specifier)
+	if err != nil {
+		return fmt.Errorf("failed to get trade %s: %w", specifier, err)
+	}
+	trade := resp.Trade
+	if trade.State != TradeStateOpen {
+		return fmt.Errorf("trade %s is %s", trade.ID, trade.State)
+	}
+	precision, err := m.client.Instruments().Precision(ctx, trade.Instrument)
+	if err != nil {
+		return err
+	}
+	entry, err := trade.Price.Float64()
+	if err != nil {
+		return fmt.Errorf("invalid price of trade %s: %w", trade.ID, err)
+	}
+	state := TrailingState{
+		TradeID:    trade.ID,
+		Instrument: trade.Instrument,
+		Long:       !strings.HasPrefix(string(trade.CurrentUnits), "-"),
+		Entry:      entry,
+		OpenTime:   bookTime(trade.OpenTime),
+		Best:       entry,
+	}
+	if trade.StopLossOrder != nil {
+		if state.Stop, err = trade.StopLossOrder.Price.Float64(); err != nil {
+			return fmt.Errorf("invalid stop loss of trade %s: %w", trade.ID, err)
+		}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.trades[trade.ID] = &trailingTrade{state: state, units: trade.CurrentUnits, precision: precision}
+	return nil
+}
+
+// Untrack stops managing the stop loss of the Trade with the given ID. It returns false if the
+// Trade is not tracked.
+func (m *TrailingStopManager) Untrack(tradeID TradeID) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.trades[tradeID]
+	delete(m.trades, tradeID)
+	return ok
+}
+
+// Trades returns the states of the tracked Trades, ordered by Trade ID.
+func (m *TrailingStopManager) Trades() []TrailingState {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	states := make([]TrailingState, 0, len(m.trades))
+	for _, trade := range m.trades {
+		states = append(states, trade.state)
+	}
+	slices.SortFunc(states, func(a, b TrailingState) int { return strings.Compare(a.TradeID, b.TradeID) })
+	return states
+}
+
+// Process evaluates the rules for the Trades on the Instrument of price, replaces the stop
+// losses they tighten, and returns the replacements. A stop loss is only replaced if it stays
+// on the losing side of the price. Trades that no longer exist are untracked; the other errors
+// are returned joined.
+func (m *TrailingStopManager) Process(ctx context.Context, price ClientPrice) ([]TrailingStopUpdate, error) {
+	at := time.Now()
+	if price.Time.Time != nil {
+		at = *price.Time.Time
+	}
+	type move struct {
+		trade TrailingState
+		units DecimalNumber
+		stop  PriceValue
+	}
+	var moves []move
+	var errs []error
+	m.mu.Lock()
+	for _, trade := range m.trades {
+		if trade.state.Instrument != price.Instrument {
+			continue
+		}
+		stop, ok, err := m.evaluate(trade, price, at)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("trade %s: %w", trade.state.TradeID, err))
+		} else if ok {
+			moves = append(moves, move{trade.state, trade.units, stop})
+		}
+	}
+	m.mu.Unlock()
+	slices.SortFunc(moves, func(a, b move) int { return strings.Compare(a.trade.TradeID, b.trade.TradeID) })
+
+	var updates []TrailingStopUpdate
+	for _, mv := range moves {
+		req := NewTradeUpdateOrdersRequest().
+			SetStopLoss(NewStopLossDetails().SetPrice(mv.stop)).
+			SetReferencePrice(mv.units, Price(mv.trade.Price, fractionDigits(string(mv.stop))))
+		resp, err := m.client.Trade.UpdateOrders(ctx, mv.trade.TradeID, req)
+		if err != nil {
+			var notFound NotFound
+			if errors.As(err, &notFound) {
+				m.Untrack(mv.trade.TradeID)
+			}
+			errs = append(errs, fmt.Errorf("failed to replace stop loss of trade %s: %w", mv.trade.TradeID, err))
+			continue
+		}
+		stop, _ := mv.stop.Float64()
+		m.mu.Lock()
+		if trade, ok := m.trades[mv.trade.TradeID]; ok {
+			trade.state.Stop = stop
+		}
+		m.mu.Unlock()
+		update := TrailingStopUpdate{
+			TradeID:    mv.trade.TradeID,
+			Instrument: mv.trade.Instrument,
+			Stop:       mv.stop,
+			Price:      price,
+			Response:   resp,
+		}
+		if mv.trade.Stop != 0 {
+			update.Previous = Price(mv.trade.Stop, fractionDigits(string(mv.stop)))
+		}
+		if m.onUpdate != nil {
+			m.onUpdate(update)
+		}
+		updates = append(updates, update)
+	}
+	return updates, errors.Join(errs...)
+}
+
+// evaluate records price in the state of trade and returns the stop loss its rules move it to,
+// rounded to the Instrument's DisplayPrecision, or false if it stays.
+func (m *TrailingStopManager) evaluate(trade *trailingTrade, price ClientPrice, at time.Time) (PriceValue, bool, error) {
+	state := &trade.state
+	bucket, ok := price.BestBid()
+	if !state.Long {
+		bucket, ok = price.BestAsk()
+	}
+	if !ok {
+		return "", false, errors.New("price has no closing side")
+	}
+	current, err := bucket.Price.Float64()
+	if err != nil {
+		return "", false, err
+	}
+	state.Price, state.Time = current, at
+	if state.tighter(current, state.Best) {
+		state.Best = current
+	}
+	best, found := 0.0, false
+	for _, rule := range m.rules {
+		if stop, ok := rule(*state); ok && (!found || state.tighter(stop, best)) {
+			best, found = stop, true
+		}
+	}
+	if !found {
+		return "", false, nil
+	}
+	stop := Price(best, trade.precision.DisplayPrecision)
+	rounded, err := stop.Float64()
+	if err != nil {
+		return "", false, err
+	}
+	if !state.tighter(current, rounded) {
+		return "", false, nil
+	}
+	if state.Stop == 0 {
+		return stop, true, nil
+	}
+	moved := rounded - state.Stop
+	if !state.Long {
+		moved = -moved
+	}
+	// Prices are multiples of the display unit, so half of it absorbs floating-point error.
+	half := math.Pow10(-trade.precision.DisplayPrecision) / 2
+	if moved < half || moved+half < m.minStep {
+		return "", false, nil
+	}
+	return stop, true, nil
+}
+
+// Run passes the prices received on a pricing stream channel, such as the one returned by
+// [PriceStream.Updates], to [TrailingStopManager.Process] until the channel is closed or ctx is
+// cancelled, in which case it returns the context's error. Errors are passed to the handler set
+// with SetErrorHandler as they occur.
+func (m *TrailingStopManager) Run(ctx context.Context, items <-chan PriceStreamItem) error {
+	return processPrices(ctx, items, func(price ClientPrice) error {
+		_, err := m.Process(ctx, price)
+		return err
+	}, m.onError)
+}
@@ -0,0 +1,138 @@
+package oanda
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTrailingStopManager(t *testing.T) {
+	var mu sync.Mutex
+	var stops []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v3/accounts/1/trades/7", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `{"trade":{"id":"7","instrument":"EUR_USD","price":"1.10000","openTime":"2025-01-01T12:00:00Z",
+			"state":"OPEN","currentUnits":"1000","stopLossOrder":{"type":"STOP_LOSS","id":"9","price":"1.09500"}}}`)
+	})
+	mux.HandleFunc("GET /v3/accounts/1/trades/8", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `{"trade":{"id":"8","instrument":"EUR_USD","price":"1.10000","state":"OPEN","currentUnits":"-500"}}`)
+	})
+	mux.HandleFunc("GET /v3/accounts/1/instruments", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `{"instruments":[{"name":"EUR_USD","pipLocation":-4,"displayPrecision":5,"tradeUnitsPrecision":0}]}`)
+	})
+	mux.HandleFunc("PUT /v3/accounts/1/trades/7/orders", func(w http.ResponseWriter, r *http.Request) {
+		var req TradeUpdateOrdersRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.StopLoss == nil || req.StopLoss.Price == nil {
+			t.Errorf("got stop loss %+v (%v)", req.StopLoss, err)
+			return
+		}
+		mu.Lock()
+		stops = append(stops, string(*req.StopLoss.Price))
+		mu.Unlock()
+		_, _ = fmt.Fprint(w, `{"lastTransactionID":"20"}`)
+	})
+	mux.HandleFunc("PUT /v3/accounts/1/trades/8/orders", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = fmt.Fprint(w, `{"errorMessage":"The Trade specified does not exist"}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client := NewClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+
+	noATR := func(InstrumentName) float64 { return 0 }
+	var updates []TrailingStopUpdate
+	manager := NewTrailingStopManager(client, StepTrailing(0.0010, 0.0050), ATRTrailing(2, noATR)).
+		SetMinStep(0.0010).
+		OnUpdate(func(update TrailingStopUpdate) { updates = append(updates, update) })
+	for _, id := range []TradeSpecifier{"7", "8"} {
+		if err := manager.Track(t.Context(), id); err != nil {
+			t.Fatalf("failed to track trade %s: %v", id, err)
+		}
+	}
+
+	price := func(bid string) ClientPrice {
+		return ClientPrice{
+			Instrument: "EUR_USD",
+			Bids:       []PriceBucket{{Price: PriceValue(bid), Liquidity: 1000000}},
+			Asks:       []PriceBucket{{Price: PriceValue(bid) + "2", Liquidity: 1000000}},
+		}
+	}
+	var errs []error
+	for _, bid := range []string{"1.1005", "1.1012", "1.1015", "1.1025", "1.1010"} {
+		if _, err := manager.Process(t.Context(), price(bid)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if got := strings.Join(stops, ","); got != "1.09600,1.09700" {
+		t.Errorf("got stops %s, want 1.09600,1.09700", got)
+	}
+	if len(updates) != 2 || updates[0].Previous != "1.09500" || updates[1].Previous != "1.09600" {
+		t.Errorf("got updates %+v", updates)
+	}
+	// Trade 8 gets no stop loss from the step rule, so the manager never reaches the API for it.
+	if len(errs) != 0 {
+		t.Errorf("got errors %v", errs)
+	}
+	states := manager.Trades()
+	if len(states) != 2 || states[0].Best != 1.1025 || states[0].Stop != 1.097 || states[1].Long {
+		t.Errorf("got states %+v", states)
+	}
+
+	var runErrs []error
+	short := NewTrailingStopManager(client, DistanceTrailing(0.0020)).
+		SetErrorHandler(func(err error) { runErrs = append(runErrs, err) })
+	if err := short.Track(t.Context(), "8"); err != nil {
+		t.Fatal(err)
+	}
+	items := make(chan PriceStreamItem, 1)
+	items <- price("1.1000")
+	close(items)
+	if err := short.Run(t.Context(), items); err != nil {
+		t.Errorf("got error %v after the channel was closed", err)
+	}
+	if len(runErrs) != 1 || !strings.Contains(runErrs[0].Error(), "trade 8") {
+		t.Errorf("got errors %v for a closed trade", runErrs)
+	}
+	if len(short.Trades()) != 0 {
+		t.Error("closed trade is still tracked")
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	if err := short.Run(ctx, make(chan PriceStreamItem)); !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v, want context.Canceled", err)
+	}
+}
+
+func TestTrailingRules(t *testing.T) {
+	open := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	state := TrailingState{Long: false, Entry: 1.1000, OpenTime: open, Time: open.Add(30 * time.Minute), Best: 1.0965}
+	tests := []struct {
+		name string
+		rule TrailingRule
+		want float64
+		ok   bool
+	}{
+		{"distance", DistanceTrailing(0.0020), 1.0985, true},
+		{"step", StepTrailing(0.0010, 0.0050), 1.1020, true},
+		{"atr", ATRTrailing(2, func(InstrumentName) float64 { return 0.0005 }), 1.0975, true},
+		{"time", TimeTightening(0.0040, 0.0020, time.Hour), 1.0995, true},
+		{"step before the first step", StepTrailing(0.0040, 0.0050), 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.rule(state)
+			if ok != tt.ok || math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("got %v %v, want %v %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
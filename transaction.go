@@ -1,13 +1,16 @@
 package oanda
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"iter"
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -23,6 +26,25 @@ type Transaction interface {
 	GetType() TransactionType
 }
 
+// OrderTransaction is the interface implemented by the Transactions that create an Order, such
+// as [MarketOrderTransaction] and [LimitOrderTransaction]. Use a type switch on the concrete
+// type to access order-specific fields such as price, units, and on-fill details.
+type OrderTransaction interface {
+	Transaction
+	// GetOrderType returns the type of the Order created by the Transaction.
+	GetOrderType() OrderType
+	// GetClientExtensions returns the client extensions of the Order.
+	GetClientExtensions() *ClientExtensions
+}
+
+// OrderRejectTransaction is the interface implemented by the Transactions that reject the
+// creation of an Order, such as [MarketOrderRejectTransaction].
+type OrderRejectTransaction interface {
+	OrderTransaction
+	// GetRejectReason returns the reason the Order was rejected.
+	GetRejectReason() TransactionRejectReason
+}
+
 func unmarshalTransaction(rawTransaction json.RawMessage) (Transaction, error) {
 	var typeOnly struct {
 		Type TransactionType `json:"type"`
@@ -33,6 +55,24 @@ func unmarshalTransaction(rawTransaction json.RawMessage) (Transaction, error) {
 
 	var transaction Transaction
 	switch typeOnly.Type {
+	case TransactionTypeOneCancelsAllOrder:
+		var oneCancelsAllOrderTransaction OneCancelsAllOrderTransaction
+		if err := json.Unmarshal(rawTransaction, &oneCancelsAllOrderTransaction); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal one cancels all order transaction: %w", err)
+		}
+		transaction = &oneCancelsAllOrderTransaction
+	case TransactionTypeOneCancelsAllOrderReject:
+		var oneCancelsAllOrderRejectTransaction OneCancelsAllOrderRejectTransaction
+		if err := json.Unmarshal(rawTransaction, &oneCancelsAllOrderRejectTransaction); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal one cancels all order reject transaction: %w", err)
+		}
+		transaction = &oneCancelsAllOrderRejectTransaction
+	case TransactionTypeOneCancelsAllOrderTriggered:
+		var oneCancelsAllOrderTriggeredTransaction OneCancelsAllOrderTriggeredTransaction
+		if err := json.Unmarshal(rawTransaction, &oneCancelsAllOrderTriggeredTransaction); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal one cancels all order triggered transaction: %w", err)
+		}
+		transaction = &oneCancelsAllOrderTriggeredTransaction
 	case TransactionTypeOrderFill:
 		var orderFillTransaction OrderFillTransaction
 		if err := json.Unmarshal(rawTransaction, &orderFillTransaction); err != nil {
@@ -267,10 +307,53 @@ func unmarshalTransaction(rawTransaction json.RawMessage) (Transaction, error) {
 			return nil, fmt.Errorf("failed to unmarshal heartbeat transaction: %w", err)
 		}
 		transaction = &heartbeat
+	default:
+		unknownTransaction, err := unmarshalUnknownTransaction(typeOnly.Type, rawTransaction)
+		if err != nil {
+			return nil, err
+		}
+		transaction = unknownTransaction
+	}
+	if r, ok := transaction.(rawJSONSetter); ok {
+		r.setRawJSON(rawTransaction)
 	}
 	return transaction, nil
 }
 
+// unmarshalTransactionAs decodes a Transaction and asserts that it implements T. An absent or
+// null Transaction results in the zero value of T, and so does an [UnknownTransaction] that does
+// not implement T, which is returned as well so that the caller can keep it.
+func unmarshalTransactionAs[T Transaction](rawTransaction json.RawMessage) (T, *UnknownTransaction, error) {
+	var zero T
+	if len(rawTransaction) == 0 || string(rawTransaction) == "null" {
+		return zero, nil, nil
+	}
+	transaction, err := unmarshalTransaction(rawTransaction)
+	if err != nil || transaction == nil {
+		return zero, nil, err
+	}
+	t, ok := transaction.(T)
+	if unknown, isUnknown := transaction.(*UnknownTransaction); !ok && isUnknown {
+		return zero, unknown, nil
+	}
+	if !ok {
+		return zero, nil, fmt.Errorf("unexpected transaction type %s", transaction.GetType())
+	}
+	return t, nil, nil
+}
+
+// addUnknownTransaction records unknown, if not nil, in unknowns under the JSON name of the
+// response field it was decoded from.
+func addUnknownTransaction(unknowns *map[string]*UnknownTransaction, name string, unknown *UnknownTransaction) {
+	if unknown == nil {
+		return
+	}
+	if *unknowns == nil {
+		*unknowns = make(map[string]*UnknownTransaction)
+	}
+	(*unknowns)[name] = unknown
+}
+
 func unmarshalTransactions(src []json.RawMessage) ([]Transaction, error) {
 	dest := make([]Transaction, 0, len(src))
 	for _, rawTransaction := range src {
@@ -300,6 +383,8 @@ type TransactionBase struct {
 	RequestID RequestID `json:"requestID"`
 	// Type is the Type of the Transaction.
 	Type TransactionType `json:"type"`
+	Received
+	Raw
 }
 
 func (t TransactionBase) GetType() TransactionType {
@@ -314,6 +399,16 @@ func (t TransactionBase) GetTime() DateTime {
 	return t.Time
 }
 
+// GetBatchID returns the ID of the batch the Transaction belongs to.
+func (t TransactionBase) GetBatchID() TransactionID {
+	return t.BatchID
+}
+
+// GetRequestID returns the Request ID of the request which generated the Transaction.
+func (t TransactionBase) GetRequestID() RequestID {
+	return t.RequestID
+}
+
 // CreateTransaction represents a Transaction that creates an Account.
 type CreateTransaction struct {
 	TransactionBase
@@ -426,6 +521,16 @@ type MarketOrderTransaction struct {
 	TradeClientExtensions *ClientExtensions `json:"tradeClientExtensions,omitempty"`
 }
 
+// GetOrderType returns the type of the Order created by the Transaction.
+func (t MarketOrderTransaction) GetOrderType() OrderType {
+	return OrderTypeMarket
+}
+
+// GetClientExtensions returns the client extensions of the Order.
+func (t MarketOrderTransaction) GetClientExtensions() *ClientExtensions {
+	return t.ClientExtensions
+}
+
 // MarketOrderRejectTransaction represents a Transaction that rejects the creation of a Market Order.
 type MarketOrderRejectTransaction struct {
 	TransactionBase
@@ -467,6 +572,21 @@ type MarketOrderRejectTransaction struct {
 	RejectReason TransactionRejectReason `json:"rejectReason"`
 }
 
+// GetOrderType returns the type of the Order created by the Transaction.
+func (t MarketOrderRejectTransaction) GetOrderType() OrderType {
+	return OrderTypeMarket
+}
+
+// GetClientExtensions returns the client extensions of the Order.
+func (t MarketOrderRejectTransaction) GetClientExtensions() *ClientExtensions {
+	return t.ClientExtensions
+}
+
+// GetRejectReason returns the reason the Order was rejected.
+func (t MarketOrderRejectTransaction) GetRejectReason() TransactionRejectReason {
+	return t.RejectReason
+}
+
 // FixedPriceOrderTransaction represents a Transaction that creates a Fixed Price Order.
 type FixedPriceOrderTransaction struct {
 	TransactionBase
@@ -496,6 +616,16 @@ type FixedPriceOrderTransaction struct {
 	TradeClientExtensions *ClientExtensions `json:"tradeClientExtensions,omitempty"`
 }
 
+// GetOrderType returns the type of the Order created by the Transaction.
+func (t FixedPriceOrderTransaction) GetOrderType() OrderType {
+	return OrderTypeFixedPrice
+}
+
+// GetClientExtensions returns the client extensions of the Order.
+func (t FixedPriceOrderTransaction) GetClientExtensions() *ClientExtensions {
+	return t.ClientExtensions
+}
+
 // LimitOrderTransaction represents a Transaction that creates a Limit Order.
 type LimitOrderTransaction struct {
 	TransactionBase
@@ -533,6 +663,16 @@ type LimitOrderTransaction struct {
 	CancellingTransactionID *TransactionID `json:"cancellingTransactionID,omitempty"`
 }
 
+// GetOrderType returns the type of the Order created by the Transaction.
+func (t LimitOrderTransaction) GetOrderType() OrderType {
+	return OrderTypeLimit
+}
+
+// GetClientExtensions returns the client extensions of the Order.
+func (t LimitOrderTransaction) GetClientExtensions() *ClientExtensions {
+	return t.ClientExtensions
+}
+
 // LimitOrderRejectTransaction represents a Transaction that rejects the creation of a Limit Order.
 type LimitOrderRejectTransaction struct {
 	TransactionBase
@@ -570,6 +710,21 @@ type LimitOrderRejectTransaction struct {
 	RejectReason TransactionRejectReason `json:"rejectReason"`
 }
 
+// GetOrderType returns the type of the Order created by the Transaction.
+func (t LimitOrderRejectTransaction) GetOrderType() OrderType {
+	return OrderTypeLimit
+}
+
+// GetClientExtensions returns the client extensions of the Order.
+func (t LimitOrderRejectTransaction) GetClientExtensions() *ClientExtensions {
+	return t.ClientExtensions
+}
+
+// GetRejectReason returns the reason the Order was rejected.
+func (t LimitOrderRejectTransaction) GetRejectReason() TransactionRejectReason {
+	return t.RejectReason
+}
+
 // StopOrderTransaction represents a Transaction that creates a Stop Order.
 type StopOrderTransaction struct {
 	TransactionBase
@@ -609,6 +764,16 @@ type StopOrderTransaction struct {
 	CancellingTransactionID *TransactionID `json:"cancellingTransactionID,omitempty"`
 }
 
+// GetOrderType returns the type of the Order created by the Transaction.
+func (t StopOrderTransaction) GetOrderType() OrderType {
+	return OrderTypeStop
+}
+
+// GetClientExtensions returns the client extensions of the Order.
+func (t StopOrderTransaction) GetClientExtensions() *ClientExtensions {
+	return t.ClientExtensions
+}
+
 // StopOrderRejectTransaction represents a Transaction that rejects the creation of a Stop Order.
 type StopOrderRejectTransaction struct {
 	TransactionBase
@@ -648,6 +813,21 @@ type StopOrderRejectTransaction struct {
 	RejectReason TransactionRejectReason `json:"rejectReason"`
 }
 
+// GetOrderType returns the type of the Order created by the Transaction.
+func (t StopOrderRejectTransaction) GetOrderType() OrderType {
+	return OrderTypeStop
+}
+
+// GetClientExtensions returns the client extensions of the Order.
+func (t StopOrderRejectTransaction) GetClientExtensions() *ClientExtensions {
+	return t.ClientExtensions
+}
+
+// GetRejectReason returns the reason the Order was rejected.
+func (t StopOrderRejectTransaction) GetRejectReason() TransactionRejectReason {
+	return t.RejectReason
+}
+
 // MarketIfTouchedOrderTransaction represents a Transaction that creates a Market If Touched Order.
 type MarketIfTouchedOrderTransaction struct {
 	TransactionBase
@@ -687,6 +867,16 @@ type MarketIfTouchedOrderTransaction struct {
 	CancellingTransactionID *TransactionID `json:"cancellingTransactionID,omitempty"`
 }
 
+// GetOrderType returns the type of the Order created by the Transaction.
+func (t MarketIfTouchedOrderTransaction) GetOrderType() OrderType {
+	return OrderTypeMarketIfTouched
+}
+
+// GetClientExtensions returns the client extensions of the Order.
+func (t MarketIfTouchedOrderTransaction) GetClientExtensions() *ClientExtensions {
+	return t.ClientExtensions
+}
+
 // MarketIfTouchedOrderRejectTransaction represents a Transaction that rejects the creation of a Market If Touched Order.
 type MarketIfTouchedOrderRejectTransaction struct {
 	TransactionBase
@@ -726,6 +916,21 @@ type MarketIfTouchedOrderRejectTransaction struct {
 	RejectReason TransactionRejectReason `json:"rejectReason"`
 }
 
+// GetOrderType returns the type of the Order created by the Transaction.
+func (t MarketIfTouchedOrderRejectTransaction) GetOrderType() OrderType {
+	return OrderTypeMarketIfTouched
+}
+
+// GetClientExtensions returns the client extensions of the Order.
+func (t MarketIfTouchedOrderRejectTransaction) GetClientExtensions() *ClientExtensions {
+	return t.ClientExtensions
+}
+
+// GetRejectReason returns the reason the Order was rejected.
+func (t MarketIfTouchedOrderRejectTransaction) GetRejectReason() TransactionRejectReason {
+	return t.RejectReason
+}
+
 // TakeProfitOrderTransaction represents a Transaction that creates a Take Profit Order.
 type TakeProfitOrderTransaction struct {
 	TransactionBase
@@ -753,6 +958,16 @@ type TakeProfitOrderTransaction struct {
 	CancellingTransactionID *TransactionID `json:"cancellingTransactionID,omitempty"`
 }
 
+// GetOrderType returns the type of the Order created by the Transaction.
+func (t TakeProfitOrderTransaction) GetOrderType() OrderType {
+	return OrderTypeTakeProfit
+}
+
+// GetClientExtensions returns the client extensions of the Order.
+func (t TakeProfitOrderTransaction) GetClientExtensions() *ClientExtensions {
+	return t.ClientExtensions
+}
+
 // TakeProfitOrderRejectTransaction represents a Transaction that rejects the creation of a Take Profit Order.
 type TakeProfitOrderRejectTransaction struct {
 	TransactionBase
@@ -780,6 +995,21 @@ type TakeProfitOrderRejectTransaction struct {
 	RejectReason TransactionRejectReason `json:"rejectReason"`
 }
 
+// GetOrderType returns the type of the Order created by the Transaction.
+func (t TakeProfitOrderRejectTransaction) GetOrderType() OrderType {
+	return OrderTypeTakeProfit
+}
+
+// GetClientExtensions returns the client extensions of the Order.
+func (t TakeProfitOrderRejectTransaction) GetClientExtensions() *ClientExtensions {
+	return t.ClientExtensions
+}
+
+// GetRejectReason returns the reason the Order was rejected.
+func (t TakeProfitOrderRejectTransaction) GetRejectReason() TransactionRejectReason {
+	return t.RejectReason
+}
+
 // StopLossOrderTransaction represents a Transaction that creates a Stop Loss Order.
 type StopLossOrderTransaction struct {
 	TransactionBase
@@ -809,6 +1039,16 @@ type StopLossOrderTransaction struct {
 	CancellingTransactionID *TransactionID `json:"cancellingTransactionID,omitempty"`
 }
 
+// GetOrderType returns the type of the Order created by the Transaction.
+func (t StopLossOrderTransaction) GetOrderType() OrderType {
+	return OrderTypeStopLoss
+}
+
+// GetClientExtensions returns the client extensions of the Order.
+func (t StopLossOrderTransaction) GetClientExtensions() *ClientExtensions {
+	return t.ClientExtensions
+}
+
 // StopLossOrderRejectTransaction represents a Transaction that rejects the creation of a Stop Loss Order.
 type StopLossOrderRejectTransaction struct {
 	TransactionBase
@@ -838,6 +1078,21 @@ type StopLossOrderRejectTransaction struct {
 	RejectReason TransactionRejectReason `json:"rejectReason"`
 }
 
+// GetOrderType returns the type of the Order created by the Transaction.
+func (t StopLossOrderRejectTransaction) GetOrderType() OrderType {
+	return OrderTypeStopLoss
+}
+
+// GetClientExtensions returns the client extensions of the Order.
+func (t StopLossOrderRejectTransaction) GetClientExtensions() *ClientExtensions {
+	return t.ClientExtensions
+}
+
+// GetRejectReason returns the reason the Order was rejected.
+func (t StopLossOrderRejectTransaction) GetRejectReason() TransactionRejectReason {
+	return t.RejectReason
+}
+
 // GuaranteedStopLossOrderTransaction represents a Transaction that creates a Guaranteed Stop Loss Order.
 type GuaranteedStopLossOrderTransaction struct {
 	TransactionBase
@@ -869,6 +1124,16 @@ type GuaranteedStopLossOrderTransaction struct {
 	CancellingTransactionID TransactionID `json:"cancellingTransactionID"`
 }
 
+// GetOrderType returns the type of the Order created by the Transaction.
+func (t GuaranteedStopLossOrderTransaction) GetOrderType() OrderType {
+	return OrderTypeGuaranteedStopLoss
+}
+
+// GetClientExtensions returns the client extensions of the Order.
+func (t GuaranteedStopLossOrderTransaction) GetClientExtensions() *ClientExtensions {
+	return t.ClientExtensions
+}
+
 // GuaranteedStopLossOrderRejectTransaction represents a Transaction that rejects the creation of a Guaranteed Stop Loss Order.
 type GuaranteedStopLossOrderRejectTransaction struct {
 	TransactionBase
@@ -898,6 +1163,21 @@ type GuaranteedStopLossOrderRejectTransaction struct {
 	RejectReason TransactionRejectReason `json:"rejectReason"`
 }
 
+// GetOrderType returns the type of the Order created by the Transaction.
+func (t GuaranteedStopLossOrderRejectTransaction) GetOrderType() OrderType {
+	return OrderTypeGuaranteedStopLoss
+}
+
+// GetClientExtensions returns the client extensions of the Order.
+func (t GuaranteedStopLossOrderRejectTransaction) GetClientExtensions() *ClientExtensions {
+	return t.ClientExtensions
+}
+
+// GetRejectReason returns the reason the Order was rejected.
+func (t GuaranteedStopLossOrderRejectTransaction) GetRejectReason() TransactionRejectReason {
+	return t.RejectReason
+}
+
 // TrailingStopLossOrderTransaction represents a Transaction that creates a Trailing Stop Loss Order.
 type TrailingStopLossOrderTransaction struct {
 	TransactionBase
@@ -925,6 +1205,16 @@ type TrailingStopLossOrderTransaction struct {
 	CancellingTransactionID TransactionID `json:"cancellingTransactionID"`
 }
 
+// GetOrderType returns the type of the Order created by the Transaction.
+func (t TrailingStopLossOrderTransaction) GetOrderType() OrderType {
+	return OrderTypeTrailingStopLoss
+}
+
+// GetClientExtensions returns the client extensions of the Order.
+func (t TrailingStopLossOrderTransaction) GetClientExtensions() *ClientExtensions {
+	return t.ClientExtensions
+}
+
 // TrailingStopLossOrderRejectTransaction represents a Transaction that rejects the creation of a Trailing Stop Loss Order.
 type TrailingStopLossOrderRejectTransaction struct {
 	TransactionBase
@@ -952,6 +1242,21 @@ type TrailingStopLossOrderRejectTransaction struct {
 	RejectReason TransactionRejectReason `json:"rejectReason"`
 }
 
+// GetOrderType returns the type of the Order created by the Transaction.
+func (t TrailingStopLossOrderRejectTransaction) GetOrderType() OrderType {
+	return OrderTypeTrailingStopLoss
+}
+
+// GetClientExtensions returns the client extensions of the Order.
+func (t TrailingStopLossOrderRejectTransaction) GetClientExtensions() *ClientExtensions {
+	return t.ClientExtensions
+}
+
+// GetRejectReason returns the reason the Order was rejected.
+func (t TrailingStopLossOrderRejectTransaction) GetRejectReason() TransactionRejectReason {
+	return t.RejectReason
+}
+
 // OrderFillTransaction represents a Transaction that fills an Order.
 type OrderFillTransaction struct {
 	TransactionBase
@@ -1005,6 +1310,30 @@ type OrderFillTransaction struct {
 	HalfSpreadCost AccountUnits `json:"halfSpreadCost"`
 }
 
+// OneCancelsAllOrderTransaction represents a Transaction that creates a One Cancels All Order
+// group. OANDA lists this type among the Transaction filters but does not publish its schema, so
+// only the fields common to all Transactions are decoded; the others are available from the
+// Transaction's raw JSON.
+type OneCancelsAllOrderTransaction struct {
+	TransactionBase
+}
+
+// OneCancelsAllOrderRejectTransaction represents a Transaction that rejects the creation of a One
+// Cancels All Order group. Fields other than the reject reason are available from the
+// Transaction's raw JSON.
+type OneCancelsAllOrderRejectTransaction struct {
+	TransactionBase
+	// RejectReason is the reason that the Reject Transaction was created.
+	RejectReason TransactionRejectReason `json:"rejectReason"`
+}
+
+// OneCancelsAllOrderTriggeredTransaction represents a Transaction that records the triggering of
+// a One Cancels All Order group. Fields other than those common to all Transactions are available
+// from the Transaction's raw JSON.
+type OneCancelsAllOrderTriggeredTransaction struct {
+	TransactionBase
+}
+
 // OrderCancelTransaction represents a Transaction that cancels an Order.
 type OrderCancelTransaction struct {
 	TransactionBase
@@ -1142,9 +1471,47 @@ type ResetResettablePLTransaction struct {
 
 // Transaction-related Definitions
 
-// TransactionID is the unique identifier of a Transaction.
+// TransactionID is the unique identifier of a Transaction. Transaction IDs are positive integers,
+// assigned in increasing order to the Transactions of an Account; use [ParseTransactionID] and
+// [CompareTransactionIDs] rather than comparing them as strings.
 type TransactionID = string
 
+// ParseTransactionID returns the numeric value of id.
+func ParseTransactionID(id TransactionID) (int64, error) {
+	n, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse transaction ID %q: %w", id, err)
+	}
+	return n, nil
+}
+
+// FormatTransactionID returns the TransactionID with the numeric value n.
+func FormatTransactionID(n int64) TransactionID {
+	return strconv.FormatInt(n, 10)
+}
+
+// NextTransactionID returns the ID of the Transaction that follows id.
+func NextTransactionID(id TransactionID) (TransactionID, error) {
+	n, err := ParseTransactionID(id)
+	if err != nil {
+		return "", err
+	}
+	return FormatTransactionID(n + 1), nil
+}
+
+// CompareTransactionIDs compares a and b in Transaction order, returning -1, 0 or +1 like
+// [cmp.Compare], so that it can be used with [slices.SortFunc]. IDs that are not numbers are
+// ordered by length and then lexicographically. Trade IDs, which are the IDs of the Transactions
+// that opened the Trades, can be compared too.
+func CompareTransactionIDs(a, b TransactionID) int {
+	x, errA := strconv.ParseInt(a, 10, 64)
+	y, errB := strconv.ParseInt(b, 10, 64)
+	if errA == nil && errB == nil {
+		return cmp.Compare(x, y)
+	}
+	return cmp.Or(cmp.Compare(len(a), len(b)), strings.Compare(a, b))
+}
+
 // TransactionType represents the type of a Transaction.
 type TransactionType string
 
@@ -1197,6 +1564,12 @@ const (
 	TransactionTypeTrailingStopLossOrder TransactionType = "TRAILING_STOP_LOSS_ORDER"
 	// TransactionTypeTrailingStopLossOrderReject represents the rejection of the creation of a Trailing Stop Loss Order.
 	TransactionTypeTrailingStopLossOrderReject TransactionType = "TRAILING_STOP_LOSS_ORDER_REJECT"
+	// TransactionTypeOneCancelsAllOrder represents the creation of a One Cancels All Order group in an Account.
+	TransactionTypeOneCancelsAllOrder TransactionType = "ONE_CANCELS_ALL_ORDER"
+	// TransactionTypeOneCancelsAllOrderReject represents the rejection of the creation of a One Cancels All Order group.
+	TransactionTypeOneCancelsAllOrderReject TransactionType = "ONE_CANCELS_ALL_ORDER_REJECT"
+	// TransactionTypeOneCancelsAllOrderTriggered represents the triggering of a One Cancels All Order group.
+	TransactionTypeOneCancelsAllOrderTriggered TransactionType = "ONE_CANCELS_ALL_ORDER_TRIGGERED"
 	// TransactionTypeOrderFill represents the filling of an Order in an Account.
 	TransactionTypeOrderFill TransactionType = "ORDER_FILL"
 	// TransactionTypeOrderCancel represents the cancellation of an Order in an Account.
@@ -1227,6 +1600,9 @@ const (
 	TransactionTypeResetResettablePL TransactionType = "RESET_RESETTABLE_PL"
 	// TransactionTypeHeartbeat represents a heartbeat from transaction stream.
 	TransactionTypeHeartbeat TransactionType = "HEARTBEAT"
+	// TransactionTypeGapDetected represents a gap in Transaction IDs detected on a transaction
+	// stream. It is generated by this library and never sent by OANDA.
+	TransactionTypeGapDetected TransactionType = "GAP_DETECTED"
 )
 
 // FundingReason represents the reason that an Account is being funded.
@@ -1583,6 +1959,12 @@ func (d *TakeProfitDetails) SetGTD(date DateTime) *TakeProfitDetails {
 	return d
 }
 
+// SetGTDTime is like SetGTD but takes a time.Time. The time must be in the future when the
+// request is sent.
+func (d *TakeProfitDetails) SetGTDTime(t time.Time) *TakeProfitDetails {
+	return d.SetGTD(DateTime{Time: &t})
+}
+
 // SetGFD sets the time in force to GFD.
 func (d *TakeProfitDetails) SetGFD() *TakeProfitDetails {
 	d.TimeInForce = TimeInForceGFD
@@ -1640,6 +2022,12 @@ func (d *StopLossDetails) SetGTD(date DateTime) *StopLossDetails {
 	return d
 }
 
+// SetGTDTime is like SetGTD but takes a time.Time. The time must be in the future when the
+// request is sent.
+func (d *StopLossDetails) SetGTDTime(t time.Time) *StopLossDetails {
+	return d.SetGTD(DateTime{Time: &t})
+}
+
 // SetGFD sets the time in force to GFD.
 func (d *StopLossDetails) SetGFD() *StopLossDetails {
 	d.TimeInForce = TimeInForceGFD
@@ -1699,6 +2087,12 @@ func (d *GuaranteedStopLossDetails) SetGTD(date DateTime) *GuaranteedStopLossDet
 	return d
 }
 
+// SetGTDTime is like SetGTD but takes a time.Time. The time must be in the future when the
+// request is sent.
+func (d *GuaranteedStopLossDetails) SetGTDTime(t time.Time) *GuaranteedStopLossDetails {
+	return d.SetGTD(DateTime{Time: &t})
+}
+
 // SetGFD sets the time in force to GFD.
 func (d *GuaranteedStopLossDetails) SetGFD() *GuaranteedStopLossDetails {
 	d.TimeInForce = TimeInForceGFD
@@ -1742,6 +2136,12 @@ func (d *TrailingStopLossDetails) SetGTD(date DateTime) *TrailingStopLossDetails
 	return d
 }
 
+// SetGTDTime is like SetGTD but takes a time.Time. The time must be in the future when the
+// request is sent.
+func (d *TrailingStopLossDetails) SetGTDTime(t time.Time) *TrailingStopLossDetails {
+	return d.SetGTD(DateTime{Time: &t})
+}
+
 // SetGFD sets the time in force to GFD.
 func (d *TrailingStopLossDetails) SetGFD() *TrailingStopLossDetails {
 	d.TimeInForce = TimeInForceGFD
@@ -2173,6 +2573,7 @@ type TransactionHeartbeat struct {
 	LastTransactionID TransactionID `json:"lastTransactionID"`
 	// Time is the date/time when the TransactionHeartbeat was created.
 	Time DateTime `json:"time"`
+	Received
 }
 
 // GetType returns the type of the heartbeat message.
@@ -2190,6 +2591,37 @@ func (t TransactionHeartbeat) GetTime() DateTime {
 	return t.Time
 }
 
+// TransactionGapDetected is delivered on a Transaction stream when the ID of a received
+// Transaction is not the successor of the previously received one, or when a heartbeat reports a
+// last Transaction ID after the last one received, meaning the Transactions in between were
+// never delivered.
+type TransactionGapDetected struct {
+	// Type is the string "GAP_DETECTED".
+	Type TransactionType `json:"type"`
+	// From is the first missing Transaction ID.
+	From TransactionID `json:"from"`
+	// To is the last missing Transaction ID.
+	To TransactionID `json:"to"`
+	// Time is the date/time of the Transaction or heartbeat that revealed the gap.
+	Time DateTime `json:"time"`
+	Received
+}
+
+// GetType returns the type of the gap message.
+func (t TransactionGapDetected) GetType() TransactionType {
+	return t.Type
+}
+
+// GetID returns the last missing Transaction ID.
+func (t TransactionGapDetected) GetID() TransactionID {
+	return t.To
+}
+
+// GetTime returns the time of the Transaction that revealed the gap.
+func (t TransactionGapDetected) GetTime() DateTime {
+	return t.Time
+}
+
 // -------------------------------------------------------------------
 // Endpoints https://developer.oanda.com/rest-live-v20/transaction-ep/
 // -------------------------------------------------------------------
@@ -2244,7 +2676,7 @@ func (req *TransactionListRequest) validate() error {
 		if *req.PageSize < 1 {
 			return errors.New("page size must be greater than zero")
 		}
-		if *req.PageSize > 1000 {
+		if *req.PageSize > transactionMaxPageSize {
 			return errors.New("page size must be equal or less than 1000")
 		}
 	}
@@ -2263,7 +2695,7 @@ func (req *TransactionListRequest) values() (url.Values, error) {
 		v.Set("to", req.To.Format(time.RFC3339))
 	}
 	if req.PageSize != nil {
-		v.Set("page_size", strconv.Itoa(*req.PageSize))
+		v.Set("pageSize", strconv.Itoa(*req.PageSize))
 	}
 	if len(req.Filters) > 0 {
 		var s []string
@@ -2300,8 +2732,85 @@ func (s *transactionService) List(ctx context.Context, req *TransactionListReque
 	return doGet[TransactionListResponse](s.client, ctx, path, v)
 }
 
+// Page retrieves the Transactions of one of the page URLs returned in
+// [TransactionListResponse.Pages]. The page is requested from the base URL the Client is
+// configured with; only the path and query of pageURL are used.
+//
+// This corresponds to the OANDA API endpoint: GET /v3/accounts/{accountID}/transactions/idrange
+//
+// Reference: https://developer.oanda.com/rest-live-v20/transaction-ep/#collapse_endpoint_3
+func (s *transactionService) Page(ctx context.Context, pageURL string) (*TransactionsResponse, error) {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid page URL %q: %w", pageURL, err)
+	}
+	return doGet[TransactionsResponse](s.client, ctx, u.Path, u.Query())
+}
+
+// ListAll retrieves every Transaction matching req by calling [transactionService.List] and
+// then fetching each of the returned pages with [transactionService.Page]. The pages are
+// fetched one at a time within the rate limit of the Client set with [WithRateLimit], and
+// retried when answered with status 429.
+func (s *transactionService) ListAll(ctx context.Context, req *TransactionListRequest) ([]Transaction, error) {
+	var transactions []Transaction
+	for transaction, err := range s.listPages(ctx, req) {
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, transaction)
+	}
+	return transactions, nil
+}
+
+// Iterate returns an iterator over the Transactions created between from and to, oldest
+// first, optionally restricted to the given types. A zero from or to leaves that end of the
+// range open. The range is listed with [transactionService.List] using the maximum page size,
+// and each page is fetched with [transactionService.Page] only when the iteration reaches it,
+// so only one page of Transactions is held in memory at a time. Iteration stops after the
+// first error, which is yielded with a nil Transaction.
+func (s *transactionService) Iterate(ctx context.Context, from, to time.Time, filters ...TransactionFilter) iter.Seq2[Transaction, error] {
+	req := NewTransactionListRequest().SetPageSize(transactionMaxPageSize).SetFilters(filters...)
+	if !from.IsZero() {
+		req.SetFrom(from)
+	}
+	if !to.IsZero() {
+		req.SetTo(to)
+	}
+	return s.listPages(ctx, req)
+}
+
+// transactionMaxPageSize is the largest page size accepted by [transactionService.List].
+const transactionMaxPageSize = 1000
+
+// listPages returns an iterator over the Transactions of the pages listed for req.
+func (s *transactionService) listPages(ctx context.Context, req *TransactionListRequest) iter.Seq2[Transaction, error] {
+	return func(yield func(Transaction, error) bool) {
+		resp, err := s.List(ctx, req)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		for _, pageURL := range resp.Pages {
+			page, err := s.Page(ctx, pageURL)
+			if err != nil {
+				yield(nil, fmt.Errorf("failed to get page %s: %w", pageURL, err))
+				return
+			}
+			for _, transaction := range page.Transactions {
+				if !yield(transaction, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
 // TransactionDetailsResponse is the response returned by [transactionService.Details].
 type TransactionDetailsResponse struct {
+	// Transaction is a pointer to the concrete Transaction type, such as
+	// [*OrderFillTransaction]; use a type switch to access its type-specific fields. Transactions
+	// of types this package does not know are decoded by the decoder registered with
+	// [RegisterTransactionType], or as an [*UnknownTransaction] that keeps the raw JSON.
 	Transaction       Transaction   `json:"transaction"`
 	LastTransactionID TransactionID `json:"lastTransactionID"`
 }
@@ -2372,6 +2881,7 @@ func (r *TransactionGetByIDRangeRequest) values() (url.Values, error) {
 
 // TransactionsResponse is the response returned by [transactionService.GetByIDRange] and [transactionService.GetBySinceID].
 type TransactionsResponse struct {
+	// Transactions are decoded like [TransactionDetailsResponse.Transaction].
 	Transactions      []Transaction `json:"transactions"`
 	LastTransactionID TransactionID `json:"lastTransactionID"`
 }
@@ -2408,6 +2918,94 @@ func (s *transactionService) GetByIDRange(ctx context.Context, req *TransactionG
 	return doGet[TransactionsResponse](s.client, ctx, path, v)
 }
 
+// transactionDownloadConcurrency is the number of pages DownloadRange requests at a time when
+// no parallelism is given.
+const transactionDownloadConcurrency = 4
+
+// DownloadRange returns an iterator over the Transactions with IDs from from to to (inclusive),
+// oldest first. The range is split into pages of up to 1000 Transactions that are fetched with
+// [transactionService.GetByIDRange], up to parallelism at a time (4 if parallelism is not
+// positive), and yielded in ID order as soon as all the earlier pages have been, so that only
+// about parallelism pages are held in memory. The requests share the rate limit of the Client
+// set with [WithRateLimit], so a high parallelism waits for it rather than exceeding it, and
+// are retried when answered with status 429. Iteration stops after the first error, which is
+// yielded with a nil Transaction; stopping the iteration cancels the pages still being fetched.
+//
+// This corresponds to the OANDA API endpoint: GET /v3/accounts/{accountID}/transactions/idrange
+//
+// Reference: https://developer.oanda.com/rest-live-v20/transaction-ep/#collapse_endpoint_3
+func (s *transactionService) DownloadRange(ctx context.Context, from, to TransactionID, parallelism int) iter.Seq2[Transaction, error] {
+	return func(yield func(Transaction, error) bool) {
+		first, err := ParseTransactionID(from)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		last, err := ParseTransactionID(to)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		if parallelism <= 0 {
+			parallelism = transactionDownloadConcurrency
+		}
+		ctx, cancel := context.WithCancel(ctx)
+		var wg sync.WaitGroup
+		// The pages still in flight are cancelled before they are waited for, so that stopping
+		// early does not wait for their responses.
+		defer func() {
+			cancel()
+			wg.Wait()
+		}()
+
+		type page struct {
+			from, to     int64
+			transactions []Transaction
+			err          error
+		}
+		// pending holds the pages in flight, in ID order.
+		var pending []chan page
+		next := first
+		fetch := func() {
+			p := page{from: next, to: min(next+transactionMaxPageSize-1, last)}
+			next = p.to + 1
+			result := make(chan page, 1)
+			pending = append(pending, result)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				req := NewTransactionGetByIDRangeRequest(FormatTransactionID(p.from), FormatTransactionID(p.to))
+				resp, err := s.GetByIDRange(ctx, req)
+				if err != nil {
+					p.err = fmt.Errorf("failed to get transactions %d-%d: %w", p.from, p.to, err)
+				} else {
+					p.transactions = resp.Transactions
+				}
+				result <- p
+			}()
+		}
+		for next <= last && len(pending) < parallelism {
+			fetch()
+		}
+		for len(pending) > 0 {
+			p := <-pending[0]
+			pending = pending[1:]
+			if p.err != nil {
+				yield(nil, p.err)
+				return
+			}
+			if next <= last {
+				fetch()
+			}
+			for _, transaction := range p.transactions {
+				if !yield(transaction, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
 // TransactionGetBySinceIDRequest represents a request to get Transactions since a given ID.
 type TransactionGetBySinceIDRequest struct {
 	ID   TransactionID
@@ -2455,14 +3053,68 @@ func (s *transactionService) GetBySinceID(ctx context.Context, req *TransactionG
 	return doGet[TransactionsResponse](s.client, ctx, path, v)
 }
 
+// transactionRequestIDWindow is the number of most recent Transactions scanned by
+// [transactionService.ByRequestID].
+const transactionRequestIDWindow = 1000
+
+// ByRequestID retrieves every Transaction generated by the client request with the given
+// RequestID, oldest first: for example an Order, its fill, and the dependent Orders and
+// cancellations it caused. It finds the Transactions carrying requestID among the 1000 most
+// recent Transactions, fetched with [transactionService.GetByIDRange], and returns them together
+// with the other Transactions of their batches. An empty result means the request generated no
+// Transactions, or generated them before that window.
+func (s *transactionService) ByRequestID(ctx context.Context, requestID RequestID) ([]Transaction, error) {
+	if requestID == "" {
+		return nil, errors.New("request ID is required")
+	}
+	list, err := s.List(ctx, NewTransactionListRequest())
+	if err != nil {
+		return nil, err
+	}
+	last, err := ParseTransactionID(list.LastTransactionID)
+	if err != nil {
+		return nil, err
+	}
+	if last < 1 {
+		return nil, nil
+	}
+	from := max(1, last-transactionRequestIDWindow+1)
+	resp, err := s.GetByIDRange(ctx, NewTransactionGetByIDRangeRequest(FormatTransactionID(from), list.LastTransactionID))
+	if err != nil {
+		return nil, err
+	}
+
+	type batched interface {
+		GetBatchID() TransactionID
+		GetRequestID() RequestID
+	}
+	batches := make(map[TransactionID]bool)
+	for _, transaction := range resp.Transactions {
+		if t, ok := transaction.(batched); ok && t.GetRequestID() == requestID {
+			batches[t.GetBatchID()] = true
+		}
+	}
+	var transactions []Transaction
+	for _, transaction := range resp.Transactions {
+		t, ok := transaction.(batched)
+		if ok && (t.GetRequestID() == requestID || (t.GetBatchID() != "" && batches[t.GetBatchID()])) {
+			transactions = append(transactions, transaction)
+		}
+	}
+	return transactions, nil
+}
+
 // TransactionStreamItem is an interface for items received from a Transaction stream.
 type TransactionStreamItem interface {
 	GetType() TransactionType
 	GetID() TransactionID
 	GetTime() DateTime
+	// GetReceivedAt returns the local time at which the item was received on the stream.
+	GetReceivedAt() time.Time
 }
 
 // Transaction opens a streaming connection for Transactions on the Account configured via [WithAccountID].
+// Transactions of types this package does not know are delivered as an [UnknownTransaction].
 //
 // This corresponds to the OANDA API endpoint: GET /v3/accounts/{accountID}/transactions/stream
 //
@@ -2472,6 +3124,343 @@ func (c *StreamClient) Transaction(ctx context.Context, ch chan<- TransactionStr
 	return streamLoop(ctx, c, path, nil, ch, done, parseTransactionStreamItem)
 }
 
+// TransactionStream is a running transaction stream opened with [StreamClient.TransactionStream].
+// The stream owns its channel and closes it when the stream ends.
+type TransactionStream struct {
+	stream *stream[TransactionStreamItem]
+}
+
+// TransactionStream opens a streaming connection for Transactions on the Account configured
+// via [WithAccountID] and returns a handle to it. The stream runs until ctx is cancelled,
+// [TransactionStream.Close] is called, the server ends the stream, or an error occurs; in each
+// case the channel returned by [TransactionStream.Updates] is closed and the cause is reported
+// by [TransactionStream.Err]. Unlike [StreamClient.Transaction], the caller neither creates
+// nor closes the channel.
+//
+// This corresponds to the OANDA API endpoint: GET /v3/accounts/{accountID}/transactions/stream
+//
+// Reference: https://developer.oanda.com/rest-live-v20/transaction-ep/#collapse_endpoint_5
+func (c *StreamClient) TransactionStream(ctx context.Context) (*TransactionStream, error) {
+	path := fmt.Sprintf("/v3/accounts/%s/transactions/stream", c.accountID)
+	return &TransactionStream{
+		stream: startStream(ctx, c, path, nil, parseTransactionStreamItem),
+	}, nil
+}
+
+// Updates returns the channel on which Transactions and heartbeats are delivered. It is closed
+// when the stream ends.
+func (s *TransactionStream) Updates() <-chan TransactionStreamItem {
+	return s.stream.updates
+}
+
+// Err returns the error that ended the stream, or nil if the stream is still running, ended
+// normally, or was stopped with Close. A cancelled context is reported as the context's error.
+func (s *TransactionStream) Err() error {
+	return s.stream.getErr()
+}
+
+// Close stops the stream and waits for it to end. It is safe to call more than once.
+func (s *TransactionStream) Close() error {
+	return s.stream.close()
+}
+
+// TransactionWithGapDetection opens a streaming connection for Transactions like
+// [StreamClient.Transaction], and additionally tracks the ordering of Transaction IDs. When a
+// received ID skips one or more IDs, or a heartbeat reports a last Transaction ID that was not
+// received, for example because a Transaction could not be decoded, a [TransactionGapDetected]
+// is sent to ch before the Transaction or heartbeat that revealed the gap. If backfill is not
+// nil, the missing Transactions are then retrieved with the idrange endpoint and sent to ch in
+// order, so that ch carries a contiguous sequence. Transactions whose ID is not after that of
+// the last Transaction sent, such as a backfilled Transaction arriving on the stream later or a
+// Transaction repeated by the stream, are dropped, so ch never carries duplicates. backfill must
+// be configured for the same Account as c.
+//
+// This corresponds to the OANDA API endpoint: GET /v3/accounts/{accountID}/transactions/stream
+//
+// Reference: https://developer.oanda.com/rest-live-v20/transaction-ep/#collapse_endpoint_5
+func (c *StreamClient) TransactionWithGapDetection(
+	ctx context.Context,
+	backfill *Client,
+	ch chan<- TransactionStreamItem,
+	done <-chan struct{},
+) error {
+	if backfill != nil {
+		if err := c.checkBackfillAccount(backfill); err != nil {
+			return err
+		}
+	}
+	return c.transactionWithGapDetection(ctx, backfill, 0, ch, done)
+}
+
+// TransactionSince resumes a Transaction stream after a disconnect. As with the sinceid
+// endpoint, all Transactions created after (but not including) sinceID, up to the Account's
+// last Transaction ID at the time of the call, are first retrieved through backfill and sent
+// to ch. The stream is then opened as with [StreamClient.TransactionWithGapDetection], so that
+// Transactions created while catching up are backfilled as a gap. Missing Transactions are
+// retrieved in pages of [TransactionBackfillPageSize], and each page is sent to ch before the
+// next one is requested, so memory use stays bounded however long the outage was. backfill
+// must be configured for the same Account as c.
+//
+// This corresponds to the OANDA API endpoints: GET /v3/accounts/{accountID}/transactions/idrange
+// and GET /v3/accounts/{accountID}/transactions/stream
+//
+// Reference: https://developer.oanda.com/rest-live-v20/transaction-ep/#collapse_endpoint_5
+func (c *StreamClient) TransactionSince(
+	ctx context.Context,
+	backfill *Client,
+	sinceID TransactionID,
+	ch chan<- TransactionStreamItem,
+	done <-chan struct{},
+) error {
+	if backfill == nil {
+		return errors.New("backfill client must be set")
+	}
+	if err := c.checkBackfillAccount(backfill); err != nil {
+		return err
+	}
+	id, err := ParseTransactionID(sinceID)
+	if err != nil {
+		return err
+	}
+	summary, err := backfill.Account.Summary(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get last transaction ID: %w", err)
+	}
+	latest, err := ParseTransactionID(summary.LastTransactionID)
+	if err != nil {
+		return err
+	}
+	if latest > id {
+		send := streamSender(ctx, ch, done)
+		if err := backfill.Transaction.backfillStreamItems(ctx, id+1, latest, send); err != nil {
+			if errors.Is(err, errStreamDone) {
+				return nil
+			}
+			return fmt.Errorf("failed to backfill transactions %d-%d: %w", id+1, latest, err)
+		}
+		id = latest
+	}
+	return c.transactionWithGapDetection(ctx, backfill, id, ch, done)
+}
+
+// TransactionFeed returns an iterator over every Transaction created after (but not including)
+// sinceID: the Account's history is retrieved through backfill first, as with
+// [StreamClient.TransactionSince], and the iteration then follows the live stream. Heartbeats
+// and [TransactionGapDetected] markers are not yielded, and Transactions whose ID is not greater
+// than that of the previous one, such as those repeated by the stream while catching up, are
+// dropped, so that the yielded IDs are contiguous and strictly increasing. The iterator can be
+// passed to [AccountState.ApplyAll] to keep a local mirror of the Account up to date.
+//
+// Iteration stops when ctx is cancelled, the server ends the stream, or an error occurs; the
+// error, including that of a cancelled ctx, is yielded with a nil Transaction. Stopping the
+// iteration closes the stream.
+//
+// This corresponds to the OANDA API endpoints: GET /v3/accounts/{accountID}/transactions/idrange
+// and GET /v3/accounts/{accountID}/transactions/stream
+//
+// Reference: https://developer.oanda.com/rest-live-v20/transaction-ep/#collapse_endpoint_5
+func (c *StreamClient) TransactionFeed(ctx context.Context, backfill *Client, sinceID TransactionID) iter.Seq2[Transaction, error] {
+	return func(yield func(Transaction, error) bool) {
+		last, err := ParseTransactionID(sinceID)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		ch := make(chan TransactionStreamItem)
+		errCh := make(chan error, 1)
+		go func() {
+			defer close(ch)
+			errCh <- c.TransactionSince(ctx, backfill, sinceID, ch, nil)
+		}()
+		for item := range ch {
+			if item.GetType() == TransactionTypeHeartbeat || item.GetType() == TransactionTypeGapDetected {
+				continue
+			}
+			id, err := ParseTransactionID(item.GetID())
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if id <= last {
+				continue
+			}
+			last = id
+			if !yield(item, nil) {
+				return
+			}
+		}
+		if err := <-errCh; err != nil {
+			yield(nil, err)
+		}
+	}
+}
+
+// checkBackfillAccount returns an error if backfill is configured for another Account than c,
+// as missing Transactions would then be retrieved from the wrong Account.
+func (c *StreamClient) checkBackfillAccount(backfill *Client) error {
+	if backfill.accountID != c.accountID {
+		return fmt.Errorf("backfill client is configured for account %q, but the stream for account %q", backfill.accountID, c.accountID)
+	}
+	return nil
+}
+
+// TransactionBackfillPageSize is the number of Transactions requested per page when missing
+// Transactions are backfilled.
+const TransactionBackfillPageSize = 500
+
+func (c *StreamClient) transactionWithGapDetection(
+	ctx context.Context,
+	backfill *Client,
+	lastID int64,
+	ch chan<- TransactionStreamItem,
+	done <-chan struct{},
+) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	inner := make(chan TransactionStreamItem)
+	errCh := make(chan error, 1)
+	go func() {
+		defer close(inner)
+		errCh <- c.Transaction(ctx, inner, done)
+	}()
+
+	send := streamSender(ctx, ch, done)
+	detector := transactionGapDetector{lastID: lastID}
+	for item := range inner {
+		if item.GetType() != TransactionTypeHeartbeat {
+			if id, err := ParseTransactionID(item.GetID()); err == nil && id <= detector.lastID {
+				continue
+			}
+		}
+		gap, err := detector.observe(item)
+		if err != nil {
+			return err
+		}
+		if gap != nil {
+			if err := send(*gap); err != nil {
+				return ignoreStreamDone(err)
+			}
+			if backfill != nil {
+				from, _ := ParseTransactionID(gap.From)
+				to, _ := ParseTransactionID(gap.To)
+				if err := backfill.Transaction.backfillStreamItems(ctx, from, to, send); err != nil {
+					if errors.Is(err, errStreamDone) {
+						return nil
+					}
+					return fmt.Errorf("failed to backfill transactions %s-%s: %w", gap.From, gap.To, err)
+				}
+			}
+		}
+		if err := send(item); err != nil {
+			return ignoreStreamDone(err)
+		}
+	}
+	return <-errCh
+}
+
+// streamSender returns a function that sends an item to ch, giving up when done fires
+// (errStreamDone) or ctx is cancelled.
+func streamSender[T any](ctx context.Context, ch chan<- T, done <-chan struct{}) func(T) error {
+	return func(item T) error {
+		select {
+		case ch <- item:
+			return nil
+		case <-done:
+			return errStreamDone
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// transactionGapDetector tracks the last Transaction ID seen on a stream.
+type transactionGapDetector struct {
+	lastID int64
+}
+
+// observe records item and returns a [TransactionGapDetected] if item's ID is not the
+// successor of the previously observed ID, or if item is a heartbeat whose last Transaction ID
+// is after it. Duplicates and the first item of a stream never produce a gap.
+func (d *transactionGapDetector) observe(item TransactionStreamItem) (*TransactionGapDetected, error) {
+	// A heartbeat's last Transaction should already have been received; a Transaction's
+	// predecessor should have been.
+	var id, received int64
+	if heartbeat, ok := item.(TransactionHeartbeat); ok {
+		if heartbeat.LastTransactionID == "" {
+			return nil, nil
+		}
+		last, err := ParseTransactionID(heartbeat.LastTransactionID)
+		if err != nil {
+			return nil, err
+		}
+		id, received = last, last
+	} else {
+		var err error
+		if id, err = ParseTransactionID(item.GetID()); err != nil {
+			return nil, err
+		}
+		received = id - 1
+	}
+	last := d.lastID
+	if id <= last {
+		return nil, nil
+	}
+	d.lastID = id
+	if last == 0 || received <= last {
+		return nil, nil
+	}
+	return &TransactionGapDetected{
+		Type:     TransactionTypeGapDetected,
+		From:     FormatTransactionID(last + 1),
+		To:       FormatTransactionID(received),
+		Time:     item.GetTime(),
+		Received: Received{ReceivedAt: item.GetReceivedAt()},
+	}, nil
+}
+
+// backfillStreamItems retrieves the Transactions with IDs from from to to (inclusive) in pages
+// of TransactionBackfillPageSize, decodes them into the same value types that are delivered
+// by the Transaction stream and passes them to yield in order. Only one page is held in memory
+// at a time.
+func (s *transactionService) backfillStreamItems(
+	ctx context.Context,
+	from, to int64,
+	yield func(TransactionStreamItem) error,
+) error {
+	path := fmt.Sprintf("/v3/accounts/%s/transactions/idrange", s.client.accountID)
+	for pageFrom := from; pageFrom <= to; pageFrom += TransactionBackfillPageSize {
+		pageTo := min(pageFrom+TransactionBackfillPageSize-1, to)
+		req := NewTransactionGetByIDRangeRequest(FormatTransactionID(pageFrom), FormatTransactionID(pageTo))
+		v, err := req.values()
+		if err != nil {
+			return err
+		}
+		resp, err := doGet[struct {
+			Transactions []json.RawMessage `json:"transactions"`
+		}](s.client, ctx, path, v)
+		if err != nil {
+			return err
+		}
+		for _, raw := range resp.Transactions {
+			item, ok, err := parseTransactionStreamItem(raw)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+			if err := yield(item); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 var transactionStreamUnmarshalers = map[TransactionType]func(json.RawMessage) (TransactionStreamItem, error){
 	"CREATE":                                unmarshalItem[CreateTransaction],
 	"CLOSE":                                 unmarshalItem[CloseTransaction],
@@ -2497,6 +3486,9 @@ var transactionStreamUnmarshalers = map[TransactionType]func(json.RawMessage) (T
 	"GUARANTEED_STOP_LOSS_ORDER_REJECT":     unmarshalItem[GuaranteedStopLossOrderRejectTransaction],
 	"TRAILING_STOP_LOSS_ORDER":              unmarshalItem[TrailingStopLossOrderTransaction],
 	"TRAILING_STOP_LOSS_ORDER_REJECT":       unmarshalItem[TrailingStopLossOrderRejectTransaction],
+	"ONE_CANCELS_ALL_ORDER":                 unmarshalItem[OneCancelsAllOrderTransaction],
+	"ONE_CANCELS_ALL_ORDER_REJECT":          unmarshalItem[OneCancelsAllOrderRejectTransaction],
+	"ONE_CANCELS_ALL_ORDER_TRIGGERED":       unmarshalItem[OneCancelsAllOrderTriggeredTransaction],
 	"ORDER_FILL":                            unmarshalItem[OrderFillTransaction],
 	"ORDER_CANCEL":                          unmarshalItem[OrderCancelTransaction],
 	"ORDER_CANCEL_REJECT":                   unmarshalItem[OrderCancelRejectTransaction],
@@ -2523,7 +3515,7 @@ func parseTransactionStreamItem(raw json.RawMessage) (TransactionStreamItem, boo
 	}
 	unmarshal, ok := transactionStreamUnmarshalers[typeOnly.Type]
 	if !ok {
-		return nil, false, nil
+		return parseUnknownTransactionStreamItem(typeOnly.Type, raw)
 	}
 	item, err := unmarshal(raw)
 	if err != nil {
@@ -2532,10 +3524,47 @@ func parseTransactionStreamItem(raw json.RawMessage) (TransactionStreamItem, boo
 	return item, true, nil
 }
 
+// parseUnknownTransactionStreamItem decodes a stream item of a type the library does not know
+// with the decoder registered with [RegisterTransactionType], if any. Items without a decoder, or
+// whose decoded Transaction is not a [TransactionStreamItem], are delivered as an
+// [UnknownTransaction] so that no Transaction is dropped from the stream.
+func parseUnknownTransactionStreamItem(transactionType TransactionType, raw json.RawMessage) (TransactionStreamItem, bool, error) {
+	receivedAt := time.Now()
+	if decode, ok := registeredTransactionDecoder(transactionType); ok {
+		transaction, err := decode(raw)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to unmarshal %s transaction: %w", transactionType, err)
+		}
+		if r, ok := transaction.(interface{ setReceivedAt(time.Time) }); ok {
+			r.setReceivedAt(receivedAt)
+		}
+		if r, ok := transaction.(rawJSONSetter); ok {
+			r.setRawJSON(raw)
+		}
+		if item, ok := transaction.(TransactionStreamItem); ok {
+			return item, true, nil
+		}
+	}
+	var unknownTransaction UnknownTransaction
+	if err := json.Unmarshal(raw, &unknownTransaction); err != nil {
+		return nil, false, fmt.Errorf("failed to unmarshal unknown transaction: %w", err)
+	}
+	unknownTransaction.setReceivedAt(receivedAt)
+	unknownTransaction.setRawJSON(raw)
+	return unknownTransaction, true, nil
+}
+
 func unmarshalItem[R TransactionStreamItem](raw json.RawMessage) (TransactionStreamItem, error) {
+	receivedAt := time.Now()
 	var t R
 	if err := json.Unmarshal(raw, &t); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal JSON response: %w", err)
 	}
+	if r, ok := any(&t).(interface{ setReceivedAt(time.Time) }); ok {
+		r.setReceivedAt(receivedAt)
+	}
+	if r, ok := any(&t).(rawJSONSetter); ok {
+		r.setRawJSON(raw)
+	}
 	return t, nil
 }
@@ -1,7 +1,15 @@
 package oanda
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
 	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -76,3 +84,423 @@ func TestStreamClient_Transaction(t *testing.T) {
 		t.Errorf("got error: %v", err)
 	}
 }
+
+func TestTransactionGapDetector(t *testing.T) {
+	var detector transactionGapDetector
+	items := []TransactionStreamItem{
+		TransactionHeartbeat{Type: TransactionTypeHeartbeat, LastTransactionID: "99"},
+		OrderFillTransaction{TransactionBase: TransactionBase{ID: "100", Type: TransactionTypeOrderFill}},
+		OrderFillTransaction{TransactionBase: TransactionBase{ID: "101", Type: TransactionTypeOrderFill}},
+		OrderFillTransaction{TransactionBase: TransactionBase{ID: "105", Type: TransactionTypeOrderFill}},
+		OrderFillTransaction{TransactionBase: TransactionBase{ID: "103", Type: TransactionTypeOrderFill}},
+		OrderFillTransaction{TransactionBase: TransactionBase{ID: "106", Type: TransactionTypeOrderFill}},
+	}
+	var gaps []*TransactionGapDetected
+	for _, item := range items {
+		gap, err := detector.observe(item)
+		if err != nil {
+			t.Fatalf("failed to observe item: %v", err)
+		}
+		if gap != nil {
+			gaps = append(gaps, gap)
+		}
+	}
+	if len(gaps) != 1 {
+		t.Fatalf("got %d gaps, want 1", len(gaps))
+	}
+	if gaps[0].From != "102" || gaps[0].To != "104" {
+		t.Errorf("got gap %s-%s, want 102-104", gaps[0].From, gaps[0].To)
+	}
+
+	gap, err := detector.observe(TransactionHeartbeat{Type: TransactionTypeHeartbeat, LastTransactionID: "108"})
+	if err != nil || gap == nil || gap.From != "107" || gap.To != "108" {
+		t.Fatalf("got gap %+v (%v) for a heartbeat, want 107-108", gap, err)
+	}
+	if gap, err := detector.observe(OrderFillTransaction{TransactionBase: TransactionBase{ID: "109", Type: TransactionTypeOrderFill}}); err != nil || gap != nil {
+		t.Errorf("got gap %+v (%v) after a heartbeat's last transaction, want none", gap, err)
+	}
+}
+
+func TestParseTransactionStreamItem_ReceivedAt(t *testing.T) {
+	before := time.Now()
+	for _, raw := range []string{
+		`{"type":"ORDER_FILL","id":"10","time":"2024-01-01T00:00:00Z"}`,
+		`{"type":"HEARTBEAT","lastTransactionID":"10","time":"2024-01-01T00:00:00Z"}`,
+	} {
+		item, ok, err := parseTransactionStreamItem([]byte(raw))
+		if err != nil || !ok {
+			t.Fatalf("failed to parse %s: ok=%v err=%v", raw, ok, err)
+		}
+		if got := item.GetReceivedAt(); got.Before(before) || got.After(time.Now()) {
+			t.Errorf("%s: got received at %v, want between %v and now", item.GetType(), got, before)
+		}
+	}
+}
+
+func TestRawJSON(t *testing.T) {
+	raw := []byte(`{"type":"ORDER_FILL","id":"10","time":"2024-01-01T00:00:00Z","newField":"value"}`)
+	transaction, err := unmarshalTransaction(raw)
+	if err != nil {
+		t.Fatalf("failed to unmarshal transaction: %v", err)
+	}
+	fill := transaction.(*OrderFillTransaction)
+	item, _, err := parseTransactionStreamItem(raw)
+	if err != nil {
+		t.Fatalf("failed to parse stream item: %v", err)
+	}
+	streamed := item.(OrderFillTransaction)
+	order, err := unmarshalOrder([]byte(`{"type":"LIMIT","id":"5","price":"1.10000","newField":"value"}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal order: %v", err)
+	}
+	// Overwrite the input to check that the retained JSON does not share its buffer.
+	copy(raw, bytes.Repeat([]byte("x"), len(raw)))
+
+	for name, got := range map[string]json.RawMessage{
+		"transaction": fill.GetRawJSON(),
+		"stream item": streamed.GetRawJSON(),
+		"order":       order.(LimitOrder).GetRawJSON(),
+	} {
+		var fields map[string]string
+		if err := json.Unmarshal(got, &fields); err != nil {
+			t.Fatalf("%s: failed to unmarshal raw JSON %s: %v", name, got, err)
+		}
+		if fields["newField"] != "value" {
+			t.Errorf("%s: got raw JSON %s, want newField", name, got)
+		}
+	}
+}
+
+func TestTransactionService_Decoding(t *testing.T) {
+	const transactions = `[
+		{"type":"ORDER_FILL","id":"10","orderID":"9","units":"100","pl":"1.5"},
+		{"type":"LIMIT_ORDER","id":"11","instrument":"EUR_USD","price":"1.10000"},
+		{"type":"SOME_NEW_TYPE","id":"12","newField":"value"}]`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v3/accounts/1/transactions/10":
+			_, _ = fmt.Fprint(w, `{"transaction":{"type":"ORDER_FILL","id":"10","orderID":"9","units":"100","pl":"1.5"}}`)
+		case "/v3/accounts/1/transactions/idrange", "/v3/accounts/1/transactions/sinceid":
+			_, _ = fmt.Fprintf(w, `{"transactions":%s,"lastTransactionID":"12"}`, transactions)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client := NewClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+
+	details, err := client.Transaction.Details(t.Context(), "10")
+	if err != nil {
+		t.Fatalf("failed to get details: %v", err)
+	}
+	if fill, ok := details.Transaction.(*OrderFillTransaction); !ok || fill.OrderID != "9" || fill.PL != "1.5" {
+		t.Errorf("got transaction %#v, want order fill of order 9", details.Transaction)
+	}
+
+	byRange, err := client.Transaction.GetByIDRange(t.Context(), NewTransactionGetByIDRangeRequest("10", "12"))
+	if err != nil {
+		t.Fatalf("failed to get by ID range: %v", err)
+	}
+	bySince, err := client.Transaction.GetBySinceID(t.Context(), NewTransactionGetBySinceIDRequest("9"))
+	if err != nil {
+		t.Fatalf("failed to get by since ID: %v", err)
+	}
+	for name, resp := range map[string]*TransactionsResponse{"idrange": byRange, "sinceid": bySince} {
+		if len(resp.Transactions) != 3 {
+			t.Fatalf("%s: got %d transactions, want 3", name, len(resp.Transactions))
+		}
+		if fill, ok := resp.Transactions[0].(*OrderFillTransaction); !ok || fill.Units != "100" {
+			t.Errorf("%s: got %#v, want order fill of 100 units", name, resp.Transactions[0])
+		}
+		if limit, ok := resp.Transactions[1].(*LimitOrderTransaction); !ok || limit.Price != "1.10000" {
+			t.Errorf("%s: got %#v, want limit order at 1.10000", name, resp.Transactions[1])
+		}
+		unknown, ok := resp.Transactions[2].(*UnknownTransaction)
+		if !ok || unknown.GetType() != "SOME_NEW_TYPE" || !bytes.Contains(unknown.GetRawJSON(), []byte("newField")) {
+			t.Errorf("%s: got %#v, want unknown transaction with raw JSON", name, resp.Transactions[2])
+		}
+	}
+}
+
+func TestTransactionListRequest_Values(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	req := NewTransactionListRequest().SetFrom(from).SetPageSize(500).
+		SetFilters(TransactionFilterOrderFill, TransactionFilterOrderCancel)
+	v, err := req.values()
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	if got, want := v.Encode(), "from=2024-01-01T00%3A00%3A00Z&pageSize=500&type=ORDER_FILL%2CORDER_CANCEL"; got != want {
+		t.Errorf("got query %s, want %s", got, want)
+	}
+}
+
+func TestTransactionService_ListAll(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v3/accounts/1/transactions":
+			if r.URL.Query().Get("type") != "ORDER_FILL" {
+				t.Errorf("got query %s, want type ORDER_FILL", r.URL.RawQuery)
+			}
+			// Page URLs point at the live API; only their path and query must be used.
+			_, _ = fmt.Fprint(w, `{"count":3,"pages":[
+				"https://api-fxtrade.oanda.com/v3/accounts/1/transactions/idrange?from=1&to=2&type=ORDER_FILL",
+				"https://api-fxtrade.oanda.com/v3/accounts/1/transactions/idrange?from=3&to=3&type=ORDER_FILL"]}`)
+		case "/v3/accounts/1/transactions/idrange":
+			from, _ := strconv.Atoi(r.URL.Query().Get("from"))
+			to, _ := strconv.Atoi(r.URL.Query().Get("to"))
+			var transactions []string
+			for id := from; id <= to; id++ {
+				transactions = append(transactions, fmt.Sprintf(`{"type":"ORDER_FILL","id":"%d"}`, id))
+			}
+			_, _ = fmt.Fprintf(w, `{"transactions":[%s]}`, strings.Join(transactions, ","))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client := NewClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+
+	transactions, err := client.Transaction.ListAll(t.Context(),
+		NewTransactionListRequest().SetFilters(TransactionFilterOrderFill))
+	if err != nil {
+		t.Fatalf("failed to list all: %v", err)
+	}
+	var ids []TransactionID
+	for _, transaction := range transactions {
+		if _, ok := transaction.(*OrderFillTransaction); !ok {
+			t.Errorf("got %T, want *OrderFillTransaction", transaction)
+		}
+		ids = append(ids, transaction.GetID())
+	}
+	if strings.Join(ids, ",") != "1,2,3" {
+		t.Errorf("got IDs %v, want 1, 2 and 3", ids)
+	}
+}
+
+func TestTransactionService_Iterate(t *testing.T) {
+	var pagesFetched int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v3/accounts/1/transactions":
+			q := r.URL.Query()
+			if q.Get("from") != "2024-01-01T00:00:00Z" || q.Has("to") || q.Get("pageSize") != "1000" || q.Get("type") != "ORDER_FILL,ORDER_CANCEL" {
+				t.Errorf("got query %s", r.URL.RawQuery)
+			}
+			_, _ = fmt.Fprint(w, `{"pages":["/v3/accounts/1/transactions/idrange?from=1&to=2","/v3/accounts/1/transactions/idrange?from=3&to=4"]}`)
+		case "/v3/accounts/1/transactions/idrange":
+			pagesFetched++
+			from, _ := strconv.Atoi(r.URL.Query().Get("from"))
+			_, _ = fmt.Fprintf(w, `{"transactions":[{"type":"ORDER_FILL","id":"%d"},{"type":"ORDER_CANCEL","id":"%d"}]}`, from, from+1)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client := NewClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var ids []TransactionID
+	for transaction, err := range client.Transaction.Iterate(t.Context(), from, time.Time{},
+		TransactionFilterOrderFill, TransactionFilterOrderCancel) {
+		if err != nil {
+			t.Fatalf("got error: %v", err)
+		}
+		ids = append(ids, transaction.GetID())
+		if len(ids) == 2 {
+			break
+		}
+	}
+	if strings.Join(ids, ",") != "1,2" || pagesFetched != 1 {
+		t.Errorf("got IDs %v after fetching %d pages, want 1 and 2 after fetching 1 page", ids, pagesFetched)
+	}
+}
+
+func TestTransactionService_ByRequestID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v3/accounts/1/transactions":
+			_, _ = fmt.Fprint(w, `{"lastTransactionID":"1205"}`)
+		case "/v3/accounts/1/transactions/idrange":
+			if q := r.URL.Query(); q.Get("from") != "206" || q.Get("to") != "1205" {
+				t.Errorf("got query %s, want from 206 to 1205", r.URL.RawQuery)
+			}
+			_, _ = fmt.Fprint(w, `{"transactions":[
+				{"type":"MARKET_ORDER","id":"1200","batchID":"1200","requestID":"r1"},
+				{"type":"ORDER_FILL","id":"1201","batchID":"1200","requestID":"r1"},
+				{"type":"ORDER_CANCEL","id":"1202","batchID":"1200"},
+				{"type":"MARKET_ORDER","id":"1203","batchID":"1203","requestID":"r2"},
+				{"type":"ORDER_FILL","id":"1204","batchID":"1203","requestID":"r2"},
+				{"type":"DAILY_FINANCING","id":"1205","batchID":"1205"}]}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client := NewClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+
+	transactions, err := client.Transaction.ByRequestID(t.Context(), "r1")
+	if err != nil {
+		t.Fatalf("failed to get transactions by request ID: %v", err)
+	}
+	var ids []TransactionID
+	for _, transaction := range transactions {
+		ids = append(ids, transaction.GetID())
+	}
+	if strings.Join(ids, ",") != "1200,1201,1202" {
+		t.Errorf("got IDs %v, want 1200, 1201 and 1202", ids)
+	}
+	if transactions, err := client.Transaction.ByRequestID(t.Context(), "unknown"); err != nil || len(transactions) != 0 {
+		t.Errorf("got %d transactions (%v) for an unknown request ID, want none", len(transactions), err)
+	}
+	if _, err := client.Transaction.ByRequestID(t.Context(), ""); err == nil {
+		t.Error("got no error for an empty request ID")
+	}
+}
+
+func TestTransactionService_DownloadRange(t *testing.T) {
+	var mu sync.Mutex
+	var inFlight, maxInFlight int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v3/accounts/1/transactions/idrange" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		mu.Lock()
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		}()
+		from, _ := strconv.Atoi(r.URL.Query().Get("from"))
+		to, _ := strconv.Atoi(r.URL.Query().Get("to"))
+		// Earlier pages are slower, so that pages complete out of order.
+		time.Sleep(time.Duration(5000-from) * time.Microsecond)
+		var transactions []string
+		for id := from; id <= to; id++ {
+			transactions = append(transactions, fmt.Sprintf(`{"type":"ORDER_FILL","id":"%d"}`, id))
+		}
+		_, _ = fmt.Fprintf(w, `{"transactions":[%s]}`, strings.Join(transactions, ","))
+	}))
+	defer server.Close()
+	client := NewClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+
+	next := 5
+	for transaction, err := range client.Transaction.DownloadRange(t.Context(), "5", "3504", 2) {
+		if err != nil {
+			t.Fatalf("got error: %v", err)
+		}
+		if want := strconv.Itoa(next); transaction.GetID() != want {
+			t.Fatalf("got transaction %s, want %s", transaction.GetID(), want)
+		}
+		next++
+	}
+	if next != 3505 || maxInFlight != 2 {
+		t.Errorf("got last transaction %d with %d requests in flight, want 3504 with 2", next-1, maxInFlight)
+	}
+
+	for transaction, err := range client.Transaction.DownloadRange(t.Context(), "1", "5000", 0) {
+		if err != nil || transaction.GetID() != "1" {
+			t.Fatalf("got transaction %v (%v), want 1", transaction, err)
+		}
+		break
+	}
+	for _, err := range client.Transaction.DownloadRange(t.Context(), "1", "x", 0) {
+		if err == nil {
+			t.Error("got no error for an invalid transaction ID")
+		}
+	}
+}
+
+func TestTransactionService_DownloadRange_Break(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		from, _ := strconv.Atoi(r.URL.Query().Get("from"))
+		if from != 1 {
+			// Later pages respond only once their request is cancelled.
+			select {
+			case <-r.Context().Done():
+			case <-time.After(10 * time.Second):
+			}
+			return
+		}
+		_, _ = fmt.Fprint(w, `{"transactions":[{"type":"ORDER_FILL","id":"1"}]}`)
+	}))
+	defer server.Close()
+	client := NewClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+
+	start := time.Now()
+	for transaction, err := range client.Transaction.DownloadRange(t.Context(), "1", "5000", 4) {
+		if err != nil || transaction.GetID() != "1" {
+			t.Fatalf("got transaction %v (%v), want 1", transaction, err)
+		}
+		break
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("stopping early took %v, want the pages in flight to be cancelled", elapsed)
+	}
+}
+
+func TestTransactionIDs(t *testing.T) {
+	if n, err := ParseTransactionID("1205"); err != nil || n != 1205 {
+		t.Errorf("got %d (%v), want 1205", n, err)
+	}
+	if _, err := ParseTransactionID("abc"); err == nil {
+		t.Error("got no error for an invalid ID")
+	}
+	if next, err := NextTransactionID("999"); err != nil || next != "1000" {
+		t.Errorf("got next %q (%v), want 1000", next, err)
+	}
+	ids := []TransactionID{"10", "9", "100", "10"}
+	slices.SortFunc(ids, CompareTransactionIDs)
+	if want := []TransactionID{"9", "10", "10", "100"}; !slices.Equal(ids, want) {
+		t.Errorf("got %v, want %v", ids, want)
+	}
+}
+
+func TestOneCancelsAllTransactions(t *testing.T) {
+	for _, raw := range []string{
+		`{"type":"ONE_CANCELS_ALL_ORDER","id":"20","orderIDs":["21","22"]}`,
+		`{"type":"ONE_CANCELS_ALL_ORDER_REJECT","id":"23","rejectReason":"INSUFFICIENT_MARGIN"}`,
+		`{"type":"ONE_CANCELS_ALL_ORDER_TRIGGERED","id":"24"}`,
+	} {
+		transaction, err := unmarshalTransaction([]byte(raw))
+		if err != nil {
+			t.Fatalf("failed to decode %s: %v", raw, err)
+		}
+		item, ok, err := parseTransactionStreamItem([]byte(raw))
+		if err != nil || !ok {
+			t.Fatalf("failed to decode stream item %s: %v", raw, err)
+		}
+		switch transaction := transaction.(type) {
+		case *OneCancelsAllOrderTransaction:
+			if !bytes.Contains(transaction.GetRawJSON(), []byte("orderIDs")) {
+				t.Errorf("got raw JSON %s, want the undecoded fields", transaction.GetRawJSON())
+			}
+			if _, ok := item.(OneCancelsAllOrderTransaction); !ok {
+				t.Errorf("got stream item %T, want OneCancelsAllOrderTransaction", item)
+			}
+		case *OneCancelsAllOrderRejectTransaction:
+			if transaction.RejectReason != TransactionRejectReasonInsufficientMargin {
+				t.Errorf("got reject reason %s, want INSUFFICIENT_MARGIN", transaction.RejectReason)
+			}
+			if _, ok := item.(OneCancelsAllOrderRejectTransaction); !ok {
+				t.Errorf("got stream item %T, want OneCancelsAllOrderRejectTransaction", item)
+			}
+		case *OneCancelsAllOrderTriggeredTransaction:
+			if transaction.GetID() != "24" {
+				t.Errorf("got ID %s, want 24", transaction.GetID())
+			}
+			if _, ok := item.(OneCancelsAllOrderTriggeredTransaction); !ok {
+				t.Errorf("got stream item %T, want OneCancelsAllOrderTriggeredTransaction", item)
+			}
+		default:
+			t.Errorf("got %T for %s, want a one cancels all transaction", transaction, raw)
+		}
+	}
+}
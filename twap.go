@@ -0,0 +1,367 @@
+package oanda
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sync"
+	"time"
+)
+
+// TWAPRequest describes a TWAP (time-weighted average price) execution: a large number of units
+// is split into equal child Market Orders submitted at regular intervals, reducing the market
+// impact of a single large Order. Start one with [Client.StartTWAP]. Create one with
+// [NewTWAPRequest].
+type TWAPRequest struct {
+	// Instrument is the Instrument to trade.
+	Instrument InstrumentName
+	// Units is the total number of units to trade. Negative units denote selling.
+	Units DecimalNumber
+	// Duration is the time over which the child Orders are spread. The first child Order is
+	// submitted immediately and the last one after Duration.
+	Duration time.Duration
+	// Slices is the number of child Orders.
+	Slices int
+	// UnitsPrecision is the number of decimal places of the child Orders' units, which should
+	// be the Instrument's TradeUnitsPrecision. The remainder of the split is added to the last
+	// child Order.
+	UnitsPrecision int
+	// Customize, if set, is called with every child Order request before it is submitted, e.g.
+	// to set a price bound or client extensions.
+	Customize func(slice int, req *MarketOrderRequest)
+}
+
+// NewTWAPRequest creates a new TWAPRequest trading units of instrument in slices child Orders
+// over duration.
+func NewTWAPRequest(instrument InstrumentName, units DecimalNumber, duration time.Duration, slices int) *TWAPRequest {
+	return &TWAPRequest{
+		Instrument: instrument,
+		Units:      units,
+		Duration:   duration,
+		Slices:     slices,
+	}
+}
+
+// SetUnitsPrecision sets the number of decimal places of the child Orders' units.
+func (r *TWAPRequest) SetUnitsPrecision(precision int) *TWAPRequest {
+	r.UnitsPrecision = precision
+	return r
+}
+
+// SetCustomize sets a function that is called with every child Order request before it is
+// submitted.
+func (r *TWAPRequest) SetCustomize(customize func(slice int, req *MarketOrderRequest)) *TWAPRequest {
+	r.Customize = customize
+	return r
+}
+
+// sliceUnits returns the units of each child Order.
+func (r *TWAPRequest) sliceUnits() ([]DecimalNumber, error) {
+	if r.Slices < 1 {
+		return nil, fmt.Errorf("invalid number of slices %d", r.Slices)
+	}
+	if r.Duration < 0 {
+		return nil, fmt.Errorf("invalid duration %s", r.Duration)
+	}
+	total, err := r.Units.Rat()
+	if err != nil {
+		return nil, err
+	}
+	per, _ := new(big.Rat).SetString(truncateDecimal(new(big.Rat).Quo(total, big.NewRat(int64(r.Slices), 1)), r.UnitsPrecision))
+	if per.Sign() == 0 {
+		return nil, fmt.Errorf("%s units cannot be split into %d slices", r.Units, r.Slices)
+	}
+	units := make([]DecimalNumber, r.Slices)
+	for i := range units[:r.Slices-1] {
+		units[i] = DecimalNumber(per.FloatString(r.UnitsPrecision))
+	}
+	last := new(big.Rat).Sub(total, new(big.Rat).Mul(per, big.NewRat(int64(r.Slices-1), 1)))
+	units[r.Slices-1] = DecimalNumber(last.FloatString(r.UnitsPrecision))
+	return units, nil
+}
+
+// TWAPSlice is the outcome of one child Order of a TWAP execution.
+type TWAPSlice struct {
+	// Index is the number of the child Order, starting at 0.
+	Index int
+	// Time is when the child Order was submitted.
+	Time time.Time
+	// Units is the number of units the child Order requested.
+	Units DecimalNumber
+	// OrderID is the ID of the child Order, or empty if it could not be created.
+	OrderID OrderID
+	// FilledUnits is the absolute number of units filled.
+	FilledUnits float64
+	// Price is the average price of the fill, or 0 if the child Order was not filled.
+	Price float64
+	// Err is the error that made the child Order fail, or nil if it was filled.
+	Err error
+}
+
+// TWAPSummary summarizes a TWAP execution.
+type TWAPSummary struct {
+	// Instrument is the traded Instrument.
+	Instrument InstrumentName
+	// TargetUnits is the total number of units requested.
+	TargetUnits DecimalNumber
+	// FilledUnits is the absolute number of units filled so far.
+	FilledUnits float64
+	// ArrivalPrice is the mid price when the execution started.
+	ArrivalPrice float64
+	// AveragePrice is the unit-weighted average fill price, or 0 if nothing has been filled.
+	AveragePrice float64
+	// Slippage is the difference between AveragePrice and ArrivalPrice, positive when the
+	// execution was worse than the arrival price: a higher price when buying or a lower price
+	// when selling. It is 0 if nothing has been filled.
+	Slippage float64
+	// Slices are the child Orders submitted so far.
+	Slices []TWAPSlice
+	// Cancelled reports whether the execution was cancelled with [TWAPExecution.Cancel] before
+	// all child Orders were submitted.
+	Cancelled bool
+}
+
+// TWAPExecution is a running TWAP execution started with [Client.StartTWAP]. It can be paused,
+// resumed and cancelled while it runs.
+type TWAPExecution struct {
+	client *Client
+	req    TWAPRequest
+	units  []DecimalNumber
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu        sync.Mutex
+	paused    bool
+	resumed   chan struct{}
+	cancelled bool
+	summary   TWAPSummary
+	err       error
+}
+
+// StartTWAP fetches the arrival price of the Instrument and starts submitting the child Orders
+// of req in the background. Child Orders that fail are recorded in the summary and not
+// retried; the execution continues with the next child Order. The execution stops when all
+// child Orders have been submitted, when it is cancelled, or when ctx is done.
+func (c *Client) StartTWAP(ctx context.Context, req *TWAPRequest) (*TWAPExecution, error) {
+	units, err := req.sliceUnits()
+	if err != nil {
+		return nil, err
+	}
+	pricing, err := c.Price.Information(ctx, NewPriceInformationRequest().AddInstruments(req.Instrument))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get arrival price: %w", err)
+	}
+	if len(pricing.Prices) == 0 {
+		return nil, fmt.Errorf("no price for %s", req.Instrument)
+	}
+	arrival, err := orderPrice(pricing.Prices[0], nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get arrival price: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	e := &TWAPExecution{
+		client: c,
+		req:    *req,
+		units:  units,
+		cancel: cancel,
+		done:   make(chan struct{}),
+		summary: TWAPSummary{
+			Instrument:   req.Instrument,
+			TargetUnits:  req.Units,
+			ArrivalPrice: arrival,
+		},
+	}
+	go e.run(ctx)
+	return e, nil
+}
+
+func (e *TWAPExecution) run(ctx context.Context) {
+	defer close(e.done)
+	defer e.cancel()
+	var interval time.Duration
+	if len(e.units) > 1 {
+		interval = e.req.Duration / time.Duration(len(e.units)-1)
+	}
+	next := time.Now()
+	for i, units := range e.units {
+		if err := sleepUntil(ctx, next); err != nil {
+			e.stop(err)
+			return
+		}
+		waited, err := e.waitResumed(ctx)
+		if err != nil {
+			e.stop(err)
+			return
+		}
+		if waited {
+			// Continue at the normal pace after a pause instead of catching up.
+			next = time.Now()
+		}
+		e.submit(ctx, i, units)
+		next = next.Add(interval)
+	}
+}
+
+// stop records why the execution stopped early. Cancellations with Cancel are not errors.
+func (e *TWAPExecution) stop(err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.cancelled {
+		e.summary.Cancelled = true
+		return
+	}
+	e.err = err
+}
+
+func sleepUntil(ctx context.Context, t time.Time) error {
+	timer := time.NewTimer(time.Until(t))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// waitResumed blocks while the execution is paused and reports whether it did.
+func (e *TWAPExecution) waitResumed(ctx context.Context) (bool, error) {
+	waited := false
+	for {
+		e.mu.Lock()
+		paused, resumed := e.paused, e.resumed
+		e.mu.Unlock()
+		if !paused {
+			return waited, ctx.Err()
+		}
+		waited = true
+		select {
+		case <-ctx.Done():
+			return waited, ctx.Err()
+		case <-resumed:
+		}
+	}
+}
+
+func (e *TWAPExecution) submit(ctx context.Context, i int, units DecimalNumber) {
+	req := NewMarketOrderRequest(e.req.Instrument, units)
+	if e.req.Customize != nil {
+		e.req.Customize(i, req)
+	}
+	slice := TWAPSlice{Index: i, Time: time.Now(), Units: units}
+	resp, err := e.client.Order.Create(ctx, req)
+	switch {
+	case err != nil:
+		slice.Err = err
+	case resp.OrderFillTransaction != nil:
+		slice.OrderID = resp.OrderFillTransaction.OrderID
+		slice.FilledUnits, slice.Price, slice.Err = fillUnitsAndPrice(resp.OrderFillTransaction)
+	default:
+		if resp.OrderCreateTransaction != nil {
+			slice.OrderID = resp.OrderCreateTransaction.GetID()
+		}
+		slice.Err = errors.New("order was not filled")
+		if reason, ok := resp.WasCancelled(); ok {
+			slice.Err = fmt.Errorf("order was cancelled: %s", reason)
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	s := &e.summary
+	s.Slices = append(s.Slices, slice)
+	if slice.FilledUnits == 0 {
+		return
+	}
+	filled := s.FilledUnits + slice.FilledUnits
+	s.AveragePrice = (s.AveragePrice*s.FilledUnits + slice.Price*slice.FilledUnits) / filled
+	s.FilledUnits = filled
+	s.Slippage = s.AveragePrice - s.ArrivalPrice
+	if e.req.Units != "" && e.req.Units[0] == '-' {
+		s.Slippage = -s.Slippage
+	}
+}
+
+// fillUnitsAndPrice returns the absolute units and the average price of a fill.
+func fillUnitsAndPrice(fill *OrderFillTransaction) (float64, float64, error) {
+	units, err := fill.Units.Float64()
+	if err != nil {
+		return 0, 0, err
+	}
+	price := fill.FullVWAP
+	if price == "" {
+		price = fill.Price
+	}
+	p, err := price.Float64()
+	if err != nil {
+		return 0, 0, err
+	}
+	return math.Abs(units), p, nil
+}
+
+// Pause stops the submission of child Orders until Resume is called. A child Order that is
+// being submitted is not affected. After resuming, the remaining child Orders keep their
+// interval rather than catching up.
+func (e *TWAPExecution) Pause() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.paused {
+		e.paused = true
+		e.resumed = make(chan struct{})
+	}
+}
+
+// Resume resumes a paused execution.
+func (e *TWAPExecution) Resume() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.paused {
+		e.paused = false
+		close(e.resumed)
+	}
+}
+
+// Paused reports whether the execution is paused.
+func (e *TWAPExecution) Paused() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.paused
+}
+
+// Cancel stops the execution. Child Orders already filled are not affected, but a child Order
+// being submitted may be aborted. Cancel does not wait for the execution to stop; use Wait for
+// that.
+func (e *TWAPExecution) Cancel() {
+	e.mu.Lock()
+	e.cancelled = true
+	e.mu.Unlock()
+	e.cancel()
+}
+
+// Done returns a channel that is closed when the execution has stopped.
+func (e *TWAPExecution) Done() <-chan struct{} {
+	return e.done
+}
+
+// Summary returns a snapshot of the execution's progress.
+func (e *TWAPExecution) Summary() TWAPSummary {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	summary := e.summary
+	summary.Slices = append([]TWAPSlice(nil), e.summary.Slices...)
+	return summary
+}
+
+// Wait blocks until the execution has stopped and returns its summary. The error is non-nil
+// only if the execution stopped early because the context passed to [Client.StartTWAP] was
+// done.
+func (e *TWAPExecution) Wait() (TWAPSummary, error) {
+	<-e.done
+	e.mu.Lock()
+	err := e.err
+	e.mu.Unlock()
+	return e.Summary(), err
+}
@@ -0,0 +1,92 @@
+package oanda
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTWAPRequest_SliceUnits(t *testing.T) {
+	units, err := NewTWAPRequest("EUR_USD", "-1000", time.Minute, 3).sliceUnits()
+	if err != nil {
+		t.Fatalf("failed to split units: %v", err)
+	}
+	if want := []DecimalNumber{"-333", "-333", "-334"}; !slices.Equal(units, want) {
+		t.Errorf("got %v, want %v", units, want)
+	}
+	units, err = NewTWAPRequest("BTC_USD", "1", time.Minute, 3).SetUnitsPrecision(2).sliceUnits()
+	if err != nil {
+		t.Fatalf("failed to split units: %v", err)
+	}
+	if want := []DecimalNumber{"0.33", "0.33", "0.34"}; !slices.Equal(units, want) {
+		t.Errorf("got %v, want %v", units, want)
+	}
+	if _, err := NewTWAPRequest("EUR_USD", "2", time.Minute, 3).sliceUnits(); err == nil {
+		t.Error("got no error for units smaller than slices")
+	}
+}
+
+func TestClient_StartTWAP(t *testing.T) {
+	var mu sync.Mutex
+	var units []DecimalNumber
+	fillPrices := []string{"1.10010", "1.10020", "1.10030"}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v3/accounts/1/pricing":
+			_, _ = fmt.Fprint(w, `{"prices":[{"bids":[{"price":"1.09990"}],"asks":[{"price":"1.10010"}]}]}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/v3/accounts/1/orders":
+			var body struct {
+				Order MarketOrderRequest `json:"order"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("failed to decode body: %v", err)
+			}
+			mu.Lock()
+			units = append(units, body.Order.Units)
+			price := fillPrices[(len(units)-1)%len(fillPrices)]
+			mu.Unlock()
+			w.WriteHeader(http.StatusCreated)
+			_, _ = fmt.Fprintf(w, `{"orderCreateTransaction":{"type":"MARKET_ORDER","id":"1"},
+				"orderFillTransaction":{"type":"ORDER_FILL","orderID":"1","units":%q,"fullVWAP":%q}}`,
+				body.Order.Units, price)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client := NewClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+
+	execution, err := client.StartTWAP(t.Context(), NewTWAPRequest("EUR_USD", "300", 20*time.Millisecond, 3))
+	if err != nil {
+		t.Fatalf("failed to start: %v", err)
+	}
+	summary, err := execution.Wait()
+	if err != nil {
+		t.Fatalf("got error %v", err)
+	}
+	if len(summary.Slices) != 3 || summary.FilledUnits != 300 || summary.Cancelled {
+		t.Fatalf("got summary %+v, want 3 filled slices", summary)
+	}
+	if summary.ArrivalPrice != 1.1 || math.Abs(summary.AveragePrice-1.1002) > 1e-9 || math.Abs(summary.Slippage-0.0002) > 1e-9 {
+		t.Errorf("got arrival %v, average %v and slippage %v, want 1.1, 1.1002 and 0.0002",
+			summary.ArrivalPrice, summary.AveragePrice, summary.Slippage)
+	}
+
+	execution, err = client.StartTWAP(t.Context(), NewTWAPRequest("EUR_USD", "300", time.Hour, 3))
+	if err != nil {
+		t.Fatalf("failed to start: %v", err)
+	}
+	execution.Pause()
+	execution.Cancel()
+	summary, err = execution.Wait()
+	if err != nil || !summary.Cancelled {
+		t.Errorf("got summary %+v (%v), want cancelled", summary, err)
+	}
+}
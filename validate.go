@@ -0,0 +1,368 @@
+package oanda
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+)
+
+// errUnknownInstrument is returned by [InstrumentCache.Get] for Instruments the Account cannot trade.
+var errUnknownInstrument = errors.New("unknown instrument")
+
+// InstrumentCache caches the metadata of the Instruments tradeable by the Account configured via
+// [WithAccountID], so that it can be consulted without a request per lookup. The metadata is
+// fetched with [instrumentService.List] on first use. Create one with [NewInstrumentCache], or
+// share the one of the client returned by [Client.Instruments].
+type InstrumentCache struct {
+	client      *Client
+	ttl         time.Duration
+	mu          sync.Mutex
+	instruments map[InstrumentName]Instrument
+	loadedAt    time.Time
+}
+
+// NewInstrumentCache creates a new InstrumentCache that fetches metadata with client. The
+// metadata does not expire unless [InstrumentCache.SetTTL] is called.
+func NewInstrumentCache(client *Client) *InstrumentCache {
+	return &InstrumentCache{client: client}
+}
+
+// SetTTL makes the metadata expire ttl after it was fetched, so that it is fetched again on the
+// next lookup. A zero ttl disables expiry.
+func (c *InstrumentCache) SetTTL(ttl time.Duration) *InstrumentCache {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ttl = ttl
+	return c
+}
+
+// Get returns the metadata of the named Instrument, fetching the metadata of all Instruments if
+// it has not been fetched yet or has expired.
+func (c *InstrumentCache) Get(ctx context.Context, name InstrumentName) (Instrument, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.ensure(ctx); err != nil {
+		return Instrument{}, err
+	}
+	instrument, ok := c.instruments[name]
+	if !ok {
+		return Instrument{}, fmt.Errorf("%w %q", errUnknownInstrument, name)
+	}
+	return instrument, nil
+}
+
+// Refresh fetches the metadata of all Instruments again, replacing the cached metadata.
+func (c *InstrumentCache) Refresh(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.load(ctx)
+}
+
+func (c *InstrumentCache) load(ctx context.Context) error {
+	resp, err := c.client.Instrument.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list instruments: %w", err)
+	}
+	instruments := make(map[InstrumentName]Instrument, len(resp.Instruments))
+	for _, instrument := range resp.Instruments {
+		instruments[instrument.Name] = instrument
+	}
+	c.instruments = instruments
+	c.loadedAt = time.Now()
+	return nil
+}
+
+// ensure loads the metadata if it has not been loaded yet or has expired.
+func (c *InstrumentCache) ensure(ctx context.Context) error {
+	if c.instruments != nil && (c.ttl <= 0 || time.Since(c.loadedAt) < c.ttl) {
+		return nil
+	}
+	return c.load(ctx)
+}
+
+// OrderValidationError is returned by [OrderValidator.Validate] when an Order request would be
+// rejected by OANDA because of the Instrument's precision or limits.
+type OrderValidationError struct {
+	// Reason is the reject reason OANDA would report for the request.
+	Reason TransactionRejectReason
+	// Field is the JSON name of the offending request field, such as "price" or
+	// "takeProfitOnFill.price".
+	Field string
+	// Value is the offending value.
+	Value string
+	// Message describes the violated constraint.
+	Message string
+}
+
+func (e OrderValidationError) Error() string {
+	return fmt.Sprintf("%s: %s %q %s", e.Reason, e.Field, e.Value, e.Message)
+}
+
+// OrderValidator checks Order requests against the precision and limits of their Instrument
+// before they are sent, so that requests OANDA would reject fail fast with an
+// [OrderValidationError]. Create one with [NewOrderValidator].
+//
+// Only Orders that specify an Instrument (Market, Limit, Stop and Market If Touched Orders) are
+// validated; Orders attached to an existing Trade are accepted as they are.
+type OrderValidator struct {
+	instruments *InstrumentCache
+	autoRound   bool
+}
+
+// NewOrderValidator creates a new OrderValidator that looks Instruments up in instruments.
+func NewOrderValidator(instruments *InstrumentCache) *OrderValidator {
+	return &OrderValidator{instruments: instruments}
+}
+
+// SetAutoRound makes the validator fix precision violations instead of reporting them: prices
+// are rounded to the nearest value the Instrument supports and units are truncated toward zero.
+// The request passed to [OrderValidator.Validate] is modified in place. Limit violations are
+// still reported.
+func (v *OrderValidator) SetAutoRound() *OrderValidator {
+	v.autoRound = true
+	return v
+}
+
+// Validate checks req against the precision and limits of its Instrument. It returns an
+// [OrderValidationError] for the first violation found.
+func (v *OrderValidator) Validate(ctx context.Context, req OrderRequest) error {
+	var (
+		instrument InstrumentName
+		units      *DecimalNumber
+		prices     []priceField
+		onFill     onFillDetails
+	)
+	switch r := req.(type) {
+	case *MarketOrderRequest:
+		instrument, units = r.Instrument, &r.Units
+		prices = []priceField{{"priceBound", r.PriceBound}}
+		onFill = onFillDetails{r.TakeProfitOnFill, r.StopLossOnFill, r.GuaranteedStopLossOnFill, r.TrailingStopLossOnFill}
+	case *LimitOrderRequest:
+		instrument, units = r.Instrument, &r.Units
+		prices = []priceField{{"price", &r.Price}}
+		onFill = onFillDetails{r.TakeProfitOnFill, r.StopLossOnFill, r.GuaranteedStopLossOnFill, r.TrailingStopLossOnFill}
+	case *StopOrderRequest:
+		instrument, units = r.Instrument, &r.Units
+		prices = []priceField{{"price", &r.Price}, {"priceBound", r.PriceBound}}
+		onFill = onFillDetails{r.TakeProfitOnFill, r.StopLossOnFill, r.GuaranteedStopLossOnFill, r.TrailingStopLossOnFill}
+	case *MarketIfTouchedOrderRequest:
+		instrument, units = r.Instrument, &r.Units
+		prices = []priceField{{"price", &r.Price}, {"priceBound", r.PriceBound}}
+		onFill = onFillDetails{r.TakeProfitOnFill, r.StopLossOnFill, r.GuaranteedStopLossOnFill, r.TrailingStopLossOnFill}
+	default:
+		return nil
+	}
+
+	meta, err := v.instruments.Get(ctx, instrument)
+	if err != nil && !errors.Is(err, errUnknownInstrument) {
+		return err
+	}
+	if err != nil {
+		return OrderValidationError{
+			Reason:  TransactionRejectReasonInstrumentUnknown,
+			Field:   "instrument",
+			Value:   instrument,
+			Message: "is not tradeable by the account",
+		}
+	}
+	if err := v.validateUnits(meta, units); err != nil {
+		return err
+	}
+	for _, f := range append(prices, onFill.prices()...) {
+		if f.price == nil {
+			continue
+		}
+		if err := v.validatePrice(meta, f.name, f.price); err != nil {
+			return err
+		}
+	}
+	for _, f := range onFill.distances() {
+		if f.distance == nil {
+			continue
+		}
+		if err := v.validateDistance(meta, f.name, f.distance); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// priceField is a price of an Order request together with its JSON name.
+type priceField struct {
+	name  string
+	price *PriceValue
+}
+
+// distanceField is a price distance of an Order request together with its JSON name.
+type distanceField struct {
+	name     string
+	distance *DecimalNumber
+}
+
+// onFillDetails groups the dependent Order details of an Order request.
+type onFillDetails struct {
+	takeProfit         *TakeProfitDetails
+	stopLoss           *StopLossDetails
+	guaranteedStopLoss *GuaranteedStopLossDetails
+	trailingStopLoss   *TrailingStopLossDetails
+}
+
+// validateGTD checks the GTD expiry times of the dependent Order details.
+func (d onFillDetails) validateGTD() error {
+	if d.takeProfit != nil {
+		if err := validateGTD("takeProfitOnFill.gtdTime", d.takeProfit.TimeInForce, d.takeProfit.GtdTime); err != nil {
+			return err
+		}
+	}
+	if d.stopLoss != nil {
+		if err := validateGTD("stopLossOnFill.gtdTime", d.stopLoss.TimeInForce, d.stopLoss.GtdTime); err != nil {
+			return err
+		}
+	}
+	if d.guaranteedStopLoss != nil {
+		if err := validateGTD("guaranteedStopLossOnFill.gtdTime", d.guaranteedStopLoss.TimeInForce, d.guaranteedStopLoss.GtdTime); err != nil {
+			return err
+		}
+	}
+	if d.trailingStopLoss != nil {
+		if err := validateGTD("trailingStopLossOnFill.gtdTime", d.trailingStopLoss.TimeInForce, d.trailingStopLoss.GtdTime); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d onFillDetails) prices() []priceField {
+	var fields []priceField
+	if d.takeProfit != nil {
+		fields = append(fields, priceField{"takeProfitOnFill.price", &d.takeProfit.Price})
+	}
+	if d.stopLoss != nil {
+		fields = append(fields, priceField{"stopLossOnFill.price", d.stopLoss.Price})
+	}
+	if d.guaranteedStopLoss != nil {
+		fields = append(fields, priceField{"guaranteedStopLossOnFill.price", d.guaranteedStopLoss.Price})
+	}
+	return fields
+}
+
+func (d onFillDetails) distances() []distanceField {
+	var fields []distanceField
+	if d.stopLoss != nil {
+		fields = append(fields, distanceField{"stopLossOnFill.distance", d.stopLoss.Distance})
+	}
+	if d.guaranteedStopLoss != nil {
+		fields = append(fields, distanceField{"guaranteedStopLossOnFill.distance", d.guaranteedStopLoss.Distance})
+	}
+	if d.trailingStopLoss != nil {
+		fields = append(fields, distanceField{"trailingStopLossOnFill.distance", &d.trailingStopLoss.Distance})
+	}
+	return fields
+}
+
+func (v *OrderValidator) validateUnits(meta Instrument, units *DecimalNumber) error {
+	u, ok := new(big.Rat).SetString(string(*units))
+	if !ok {
+		return OrderValidationError{
+			Reason:  TransactionRejectReasonUnitsInvalid,
+			Field:   "units",
+			Value:   string(*units),
+			Message: "is not a decimal number",
+		}
+	}
+	if decimalPlaces(string(*units)) > meta.TradeUnitsPrecision {
+		if !v.autoRound {
+			return OrderValidationError{
+				Reason:  TransactionRejectReasonUnitsPrecisionExceeded,
+				Field:   "units",
+				Value:   string(*units),
+				Message: fmt.Sprintf("has more than %d decimal places", meta.TradeUnitsPrecision),
+			}
+		}
+		*units = DecimalNumber(truncateDecimal(u, meta.TradeUnitsPrecision))
+		u.SetString(string(*units))
+	}
+	abs := new(big.Rat).Abs(u)
+	if minimum, ok := new(big.Rat).SetString(string(meta.MinimumTradeSize)); ok && abs.Cmp(minimum) < 0 {
+		return OrderValidationError{
+			Reason:  TransactionRejectReasonUnitsMinimumNotMet,
+			Field:   "units",
+			Value:   string(*units),
+			Message: fmt.Sprintf("is below the minimum trade size of %s", meta.MinimumTradeSize),
+		}
+	}
+	if maximum, ok := new(big.Rat).SetString(string(meta.MaximumOrderUnits)); ok && maximum.Sign() > 0 && abs.Cmp(maximum) > 0 {
+		return OrderValidationError{
+			Reason:  TransactionRejectReasonUnitsLimitExceeded,
+			Field:   "units",
+			Value:   string(*units),
+			Message: fmt.Sprintf("exceeds the maximum order units of %s", meta.MaximumOrderUnits),
+		}
+	}
+	return nil
+}
+
+func (v *OrderValidator) validatePrice(meta Instrument, field string, price *PriceValue) error {
+	reason := TransactionRejectReasonPricePrecisionExceeded
+	if field == "priceBound" {
+		reason = TransactionRejectReasonPriceBoundPrecisionExceeded
+	}
+	rounded, err := v.checkPrecision(meta, reason, field, string(*price))
+	if err != nil {
+		return err
+	}
+	*price = PriceValue(rounded)
+	return nil
+}
+
+func (v *OrderValidator) validateDistance(meta Instrument, field string, distance *DecimalNumber) error {
+	rounded, err := v.checkPrecision(meta, TransactionRejectReasonPriceDistancePrecisionExceeded, field, string(*distance))
+	if err != nil {
+		return err
+	}
+	*distance = DecimalNumber(rounded)
+	return nil
+}
+
+// checkPrecision checks that value has no more decimal places than the Instrument's prices and
+// returns it, rounded if auto-rounding is enabled.
+func (v *OrderValidator) checkPrecision(meta Instrument, reason TransactionRejectReason, field, value string) (string, error) {
+	r, ok := new(big.Rat).SetString(value)
+	if !ok {
+		return "", OrderValidationError{Reason: reason, Field: field, Value: value, Message: "is not a decimal number"}
+	}
+	if decimalPlaces(value) <= meta.DisplayPrecision {
+		return value, nil
+	}
+	if !v.autoRound {
+		return "", OrderValidationError{
+			Reason:  reason,
+			Field:   field,
+			Value:   value,
+			Message: fmt.Sprintf("has more than %d decimal places", meta.DisplayPrecision),
+		}
+	}
+	return r.FloatString(meta.DisplayPrecision), nil
+}
+
+// decimalPlaces returns the number of significant digits after the decimal point of a decimal
+// string.
+func decimalPlaces(s string) int {
+	_, frac, ok := strings.Cut(s, ".")
+	if !ok {
+		return 0
+	}
+	return len(strings.TrimRight(frac, "0"))
+}
+
+// truncateDecimal formats r with n decimal places, truncating toward zero.
+func truncateDecimal(r *big.Rat, n int) string {
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+	scaled := new(big.Int).Mul(r.Num(), scale)
+	scaled.Quo(scaled, r.Denom())
+	return new(big.Rat).SetFrac(scaled, scale).FloatString(n)
+}
@@ -0,0 +1,132 @@
+package oanda
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newInstrumentServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	var calls int
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls > 1 {
+			t.Errorf("instruments fetched %d times, want once", calls)
+		}
+		_, _ = fmt.Fprint(w, `{"instruments":[{"name":"EUR_USD","pipLocation":-4,"displayPrecision":5,
+			"tradeUnitsPrecision":0,"minimumTradeSize":"1","maximumOrderUnits":"100000000"}]}`)
+	}))
+}
+
+func TestOrderValidator_Validate(t *testing.T) {
+	server := newInstrumentServer(t)
+	defer server.Close()
+	client := NewClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+	validator := NewOrderValidator(NewInstrumentCache(client))
+
+	tests := []struct {
+		name       string
+		req        OrderRequest
+		wantReason TransactionRejectReason
+		wantField  string
+	}{
+		{name: "valid", req: NewLimitOrderRequest("EUR_USD", "100", "1.10000")},
+		{name: "trailing zeros", req: NewLimitOrderRequest("EUR_USD", "100.0", "1.1000000")},
+		{name: "price precision", req: NewLimitOrderRequest("EUR_USD", "100", "1.100001"),
+			wantReason: TransactionRejectReasonPricePrecisionExceeded, wantField: "price"},
+		{name: "units precision", req: NewMarketOrderRequest("EUR_USD", "100.5"),
+			wantReason: TransactionRejectReasonUnitsPrecisionExceeded, wantField: "units"},
+		{name: "units limit", req: NewMarketOrderRequest("EUR_USD", "-200000000"),
+			wantReason: TransactionRejectReasonUnitsLimitExceeded, wantField: "units"},
+		{name: "units minimum", req: NewMarketOrderRequest("EUR_USD", "0"),
+			wantReason: TransactionRejectReasonUnitsMinimumNotMet, wantField: "units"},
+		{name: "take profit on fill precision",
+			req:        NewMarketOrderRequest("EUR_USD", "100").SetTakeProfitOnFill(NewTakeProfitDetails("1.2000001")),
+			wantReason: TransactionRejectReasonPricePrecisionExceeded, wantField: "takeProfitOnFill.price"},
+		{name: "unknown instrument", req: NewMarketOrderRequest("XAU_XAG", "100"),
+			wantReason: TransactionRejectReasonInstrumentUnknown, wantField: "instrument"},
+		{name: "dependent order", req: NewTakeProfitOrderRequest("1", "1.2000001")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validator.Validate(t.Context(), tt.req)
+			if tt.wantReason == "" {
+				if err != nil {
+					t.Fatalf("got error: %v", err)
+				}
+				return
+			}
+			var validationErr OrderValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("got error %v, want OrderValidationError", err)
+			}
+			if validationErr.Reason != tt.wantReason || validationErr.Field != tt.wantField {
+				t.Errorf("got %s on %s, want %s on %s", validationErr.Reason, validationErr.Field, tt.wantReason, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestOrderValidator_AutoRound(t *testing.T) {
+	server := newInstrumentServer(t)
+	defer server.Close()
+	client := NewClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+	validator := NewOrderValidator(NewInstrumentCache(client)).SetAutoRound()
+
+	req := NewStopOrderRequest("EUR_USD", "-100.9", "1.123456").
+		SetStopLossOnFill(NewStopLossDetails().SetDistance("0.0012345"))
+	if err := validator.Validate(t.Context(), req); err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	if req.Units != "-100" {
+		t.Errorf("got units %s, want -100", req.Units)
+	}
+	if req.Price != "1.12346" {
+		t.Errorf("got price %s, want 1.12346", req.Price)
+	}
+	if *req.StopLossOnFill.Distance != "0.00123" {
+		t.Errorf("got distance %s, want 0.00123", *req.StopLossOnFill.Distance)
+	}
+
+	err := validator.Validate(t.Context(), NewMarketOrderRequest("EUR_USD", "0.4"))
+	var validationErr OrderValidationError
+	if !errors.As(err, &validationErr) || validationErr.Reason != TransactionRejectReasonUnitsMinimumNotMet {
+		t.Errorf("got error %v, want %s", err, TransactionRejectReasonUnitsMinimumNotMet)
+	}
+}
+
+func TestInstrumentCache_TTL(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		_, _ = fmt.Fprintf(w, `{"instruments":[{"name":"EUR_USD","displayPrecision":%d}]}`, calls)
+	}))
+	defer server.Close()
+
+	client := NewClient("api-key", WithBaseURL(server.URL), WithAccountID("1"), WithInstrumentCacheTTL(time.Hour))
+	if client.Instruments() != client.Instruments() {
+		t.Fatal("got a new cache on each call")
+	}
+	cache := client.Instruments()
+	for range 2 {
+		if i, err := cache.Get(t.Context(), "EUR_USD"); err != nil || i.DisplayPrecision != 1 {
+			t.Fatalf("got %+v, %v", i, err)
+		}
+	}
+	if err := cache.Refresh(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	if i, _ := cache.Get(t.Context(), "EUR_USD"); i.DisplayPrecision != 2 || calls != 2 {
+		t.Errorf("got display precision %d after %d calls, want the refreshed metadata", i.DisplayPrecision, calls)
+	}
+
+	cache.SetTTL(time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	if i, _ := cache.Get(t.Context(), "EUR_USD"); i.DisplayPrecision != 3 {
+		t.Errorf("got display precision %d, want the expired metadata fetched again", i.DisplayPrecision)
+	}
+}
@@ -0,0 +1,437 @@
+package oanda
+
+import "errors"
+
+// TransactionVisitor handles each concrete Transaction type in its own method, as an alternative
+// to a type switch over the [Transaction] interface. Pass one to [DispatchTransaction].
+//
+// A type that implements every method is checked for completeness by the compiler: when a
+// Transaction type is added to this package, so is a method here, and the type no longer
+// compiles until it handles it. Types that handle only some Transactions embed
+// [BaseTransactionVisitor] instead and receive the others in Default.
+type TransactionVisitor interface {
+	VisitCreate(*CreateTransaction) error
+	VisitClose(*CloseTransaction) error
+	VisitReopen(*ReopenTransaction) error
+	VisitClientConfigure(*ClientConfigureTransaction) error
+	VisitClientConfigureReject(*ClientConfigureRejectTransaction) error
+	VisitTransferFunds(*TransferFundsTransaction) error
+	VisitTransferFundsReject(*TransferFundsRejectTransaction) error
+	VisitMarketOrder(*MarketOrderTransaction) error
+	VisitMarketOrderReject(*MarketOrderRejectTransaction) error
+	VisitFixedPriceOrder(*FixedPriceOrderTransaction) error
+	VisitLimitOrder(*LimitOrderTransaction) error
+	VisitLimitOrderReject(*LimitOrderRejectTransaction) error
+	VisitStopOrder(*StopOrderTransaction) error
+	VisitStopOrderReject(*StopOrderRejectTransaction) error
+	VisitMarketIfTouchedOrder(*MarketIfTouchedOrderTransaction) error
+	VisitMarketIfTouchedOrderReject(*MarketIfTouchedOrderRejectTransaction) error
+	VisitTakeProfitOrder(*TakeProfitOrderTransaction) error
+	VisitTakeProfitOrderReject(*TakeProfitOrderRejectTransaction) error
+	VisitStopLossOrder(*StopLossOrderTransaction) error
+	VisitStopLossOrderReject(*StopLossOrderRejectTransaction) error
+	VisitGuaranteedStopLossOrder(*GuaranteedStopLossOrderTransaction) error
+	VisitGuaranteedStopLossOrderReject(*GuaranteedStopLossOrderRejectTransaction) error
+	VisitTrailingStopLossOrder(*TrailingStopLossOrderTransaction) error
+	VisitTrailingStopLossOrderReject(*TrailingStopLossOrderRejectTransaction) error
+	VisitOneCancelsAllOrder(*OneCancelsAllOrderTransaction) error
+	VisitOneCancelsAllOrderReject(*OneCancelsAllOrderRejectTransaction) error
+	VisitOneCancelsAllOrderTriggered(*OneCancelsAllOrderTriggeredTransaction) error
+	VisitOrderFill(*OrderFillTransaction) error
+	VisitOrderCancel(*OrderCancelTransaction) error
+	VisitOrderCancelReject(*OrderCancelRejectTransaction) error
+	VisitOrderClientExtensionsModify(*OrderClientExtensionsModifyTransaction) error
+	VisitOrderClientExtensionsModifyReject(*OrderClientExtensionsModifyRejectTransaction) error
+	VisitTradeClientExtensionsModify(*TradeClientExtensionsModifyTransaction) error
+	VisitTradeClientExtensionsModifyReject(*TradeClientExtensionsModifyRejectTransaction) error
+	VisitMarginCallEnter(*MarginCallEnterTransaction) error
+	VisitMarginCallExtend(*MarginCallExtendTransaction) error
+	VisitMarginCallExit(*MarginCallExitTransaction) error
+	VisitDelayedTradeClosure(*DelayedTradeClosureTransaction) error
+	VisitDailyFinancing(*DailyFinancingTransaction) error
+	VisitDividendAdjustment(*DividendAdjustmentTransaction) error
+	VisitResetResettablePL(*ResetResettablePLTransaction) error
+	VisitHeartbeat(*TransactionHeartbeat) error
+	VisitGapDetected(*TransactionGapDetected) error
+	// Default handles the Transactions the other methods do not: Transactions of types this
+	// package does not know, such as [UnknownTransaction] and those decoded by a decoder
+	// registered with [RegisterTransactionType], and those whose method is inherited from
+	// [BaseTransactionVisitor].
+	Default(Transaction) error
+}
+
+// errNotVisited is returned by the methods of BaseTransactionVisitor to have DispatchTransaction
+// call the visitor's Default method instead.
+var errNotVisited = errors.New("transaction not visited")
+
+// BaseTransactionVisitor implements every Visit method of [TransactionVisitor] by passing the
+// Transaction to the visitor's Default method, and Default by ignoring it. Embed it in a visitor
+// to handle only some Transaction types.
+type BaseTransactionVisitor struct{}
+
+var _ TransactionVisitor = BaseTransactionVisitor{}
+
+func (BaseTransactionVisitor) VisitCreate(*CreateTransaction) error {
+	return errNotVisited
+}
+
+func (BaseTransactionVisitor) VisitClose(*CloseTransaction) error {
+	return errNotVisited
+}
+
+func (BaseTransactionVisitor) VisitReopen(*ReopenTransaction) error {
+	return errNotVisited
+}
+
+func (BaseTransactionVisitor) VisitClientConfigure(*ClientConfigureTransaction) error {
+	return errNotVisited
+}
+
+func (BaseTransactionVisitor) VisitClientConfigureReject(*ClientConfigureRejectTransaction) error {
+	return errNotVisited
+}
+
+func (BaseTransactionVisitor) VisitTransferFunds(*TransferFundsTransaction) error {
+	return errNotVisited
+}
+
+func (BaseTransactionVisitor) VisitTransferFundsReject(*TransferFundsRejectTransaction) error {
+	return errNotVisited
+}
+
+func (BaseTransactionVisitor) VisitMarketOrder(*MarketOrderTransaction) error {
+	return errNotVisited
+}
+
+func (BaseTransactionVisitor) VisitMarketOrderReject(*MarketOrderRejectTransaction) error {
+	return errNotVisited
+}
+
+func (BaseTransactionVisitor) VisitFixedPriceOrder(*FixedPriceOrderTransaction) error {
+	return errNotVisited
+}
+
+func (BaseTransactionVisitor) VisitLimitOrder(*LimitOrderTransaction) error {
+	return errNotVisited
+}
+
+func (BaseTransactionVisitor) VisitLimitOrderReject(*LimitOrderRejectTransaction) error {
+	return errNotVisited
+}
+
+func (BaseTransactionVisitor) VisitStopOrder(*StopOrderTransaction) error {
+	return errNotVisited
+}
+
+func (BaseTransactionVisitor) VisitStopOrderReject(*StopOrderRejectTransaction) error {
+	return errNotVisited
+}
+
+func (BaseTransactionVisitor) VisitMarketIfTouchedOrder(*MarketIfTouchedOrderTransaction) error {
+	return errNotVisited
+}
+
+func (BaseTransactionVisitor) VisitMarketIfTouchedOrderReject(*MarketIfTouchedOrderRejectTransaction) error {
+	return errNotVisited
+}
+
+func (BaseTransactionVisitor) VisitTakeProfitOrder(*TakeProfitOrderTransaction) error {
+	return errNotVisited
+}
+
+func (BaseTransactionVisitor) VisitTakeProfitOrderReject(*TakeProfitOrderRejectTransaction) error {
+	return errNotVisited
+}
+
+func (BaseTransactionVisitor) VisitStopLossOrder(*StopLossOrderTransaction) error {
+	return errNotVisited
+}
+
+func (BaseTransactionVisitor) VisitStopLossOrderReject(*StopLossOrderRejectTransaction) error {
+	return errNotVisited
+}
+
+func (BaseTransactionVisitor) VisitGuaranteedStopLossOrder(*GuaranteedStopLossOrderTransaction) error {
+	return errNotVisited
+}
+
+func (BaseTransactionVisitor) VisitGuaranteedStopLossOrderReject(*GuaranteedStopLossOrderRejectTransaction) error {
+	return errNotVisited
+}
+
+func (BaseTransactionVisitor) VisitTrailingStopLossOrder(*TrailingStopLossOrderTransaction) error {
+	return errNotVisited
+}
+
+func (BaseTransactionVisitor) VisitTrailingStopLossOrderReject(*TrailingStopLossOrderRejectTransaction) error {
+	return errNotVisited
+}
+
+func (BaseTransactionVisitor) VisitOneCancelsAllOrder(*OneCancelsAllOrderTransaction) error {
+	return errNotVisited
+}
+
+func (BaseTransactionVisitor) VisitOneCancelsAllOrderReject(*OneCancelsAllOrderRejectTransaction) error {
+	return errNotVisited
+}
+
+func (BaseTransactionVisitor) VisitOneCancelsAllOrderTriggered(*OneCancelsAllOrderTriggeredTransaction) error {
+	return errNotVisited
+}
+
+func (BaseTransactionVisitor) VisitOrderFill(*OrderFillTransaction) error {
+	return errNotVisited
+}
+
+func (BaseTransactionVisitor) VisitOrderCancel(*OrderCancelTransaction) error {
+	return errNotVisited
+}
+
+func (BaseTransactionVisitor) VisitOrderCancelReject(*OrderCancelRejectTransaction) error {
+	return errNotVisited
+}
+
+func (BaseTransactionVisitor) VisitOrderClientExtensionsModify(*OrderClientExtensionsModifyTransaction) error {
+	return errNotVisited
+}
+
+func (BaseTransactionVisitor) VisitOrderClientExtensionsModifyReject(*OrderClientExtensionsModifyRejectTransaction) error {
+	return errNotVisited
+}
+
+func (BaseTransactionVisitor) VisitTradeClientExtensionsModify(*TradeClientExtensionsModifyTransaction) error {
+	return errNotVisited
+}
+
+func (BaseTransactionVisitor) VisitTradeClientExtensionsModifyReject(*TradeClientExtensionsModifyRejectTransaction) error {
+	return errNotVisited
+}
+
+func (BaseTransactionVisitor) VisitMarginCallEnter(*MarginCallEnterTransaction) error {
+	return errNotVisited
+}
+
+func (BaseTransactionVisitor) VisitMarginCallExtend(*MarginCallExtendTransaction) error {
+	return errNotVisited
+}
+
+func (BaseTransactionVisitor) VisitMarginCallExit(*MarginCallExitTransaction) error {
+	return errNotVisited
+}
+
+func (BaseTransactionVisitor) VisitDelayedTradeClosure(*DelayedTradeClosureTransaction) error {
+	return errNotVisited
+}
+
+func (BaseTransactionVisitor) VisitDailyFinancing(*DailyFinancingTransaction) error {
+	return errNotVisited
+}
+
+func (BaseTransactionVisitor) VisitDividendAdjustment(*DividendAdjustmentTransaction) error {
+	return errNotVisited
+}
+
+func (BaseTransactionVisitor) VisitResetResettablePL(*ResetResettablePLTransaction) error {
+	return errNotVisited
+}
+
+func (BaseTransactionVisitor) VisitHeartbeat(*TransactionHeartbeat) error {
+	return errNotVisited
+}
+
+func (BaseTransactionVisitor) VisitGapDetected(*TransactionGapDetected) error {
+	return errNotVisited
+}
+
+func (BaseTransactionVisitor) Default(Transaction) error {
+	return nil
+}
+
+// DispatchTransaction calls the method of v for the concrete type of transaction and returns its
+// error. Both the pointers returned by the REST endpoints and the values delivered by the
+// Transaction stream are passed to the Visit methods as pointers. A nil transaction is ignored.
+func DispatchTransaction(transaction Transaction, v TransactionVisitor) error {
+	var err error
+	switch t := transaction.(type) {
+	case nil:
+		return nil
+	case *CreateTransaction:
+		err = v.VisitCreate(t)
+	case CreateTransaction:
+		err = v.VisitCreate(&t)
+	case *CloseTransaction:
+		err = v.VisitClose(t)
+	case CloseTransaction:
+		err = v.VisitClose(&t)
+	case *ReopenTransaction:
+		err = v.VisitReopen(t)
+	case ReopenTransaction:
+		err = v.VisitReopen(&t)
+	case *ClientConfigureTransaction:
+		err = v.VisitClientConfigure(t)
+	case ClientConfigureTransaction:
+		err = v.VisitClientConfigure(&t)
+	case *ClientConfigureRejectTransaction:
+		err = v.VisitClientConfigureReject(t)
+	case ClientConfigureRejectTransaction:
+		err = v.VisitClientConfigureReject(&t)
+	case *TransferFundsTransaction:
+		err = v.VisitTransferFunds(t)
+	case TransferFundsTransaction:
+		err = v.VisitTransferFunds(&t)
+	case *TransferFundsRejectTransaction:
+		err = v.VisitTransferFundsReject(t)
+	case TransferFundsRejectTransaction:
+		err = v.VisitTransferFundsReject(&t)
+	case *MarketOrderTransaction:
+		err = v.VisitMarketOrder(t)
+	case MarketOrderTransaction:
+		err = v.VisitMarketOrder(&t)
+	case *MarketOrderRejectTransaction:
+		err = v.VisitMarketOrderReject(t)
+	case MarketOrderRejectTransaction:
+		err = v.VisitMarketOrderReject(&t)
+	case *FixedPriceOrderTransaction:
+		err = v.VisitFixedPriceOrder(t)
+	case FixedPriceOrderTransaction:
+		err = v.VisitFixedPriceOrder(&t)
+	case *LimitOrderTransaction:
+		err = v.VisitLimitOrder(t)
+	case LimitOrderTransaction:
+		err = v.VisitLimitOrder(&t)
+	case *LimitOrderRejectTransaction:
+		err = v.VisitLimitOrderReject(t)
+	case LimitOrderRejectTransaction:
+		err = v.VisitLimitOrderReject(&t)
+	case *StopOrderTransaction:
+		err = v.VisitStopOrder(t)
+	case StopOrderTransaction:
+		err = v.VisitStopOrder(&t)
+	case *StopOrderRejectTransaction:
+		err = v.VisitStopOrderReject(t)
+	case StopOrderRejectTransaction:
+		err = v.VisitStopOrderReject(&t)
+	case *MarketIfTouchedOrderTransaction:
+		err = v.VisitMarketIfTouchedOrder(t)
+	case MarketIfTouchedOrderTransaction:
+		err = v.VisitMarketIfTouchedOrder(&t)
+	case *MarketIfTouchedOrderRejectTransaction:
+		err = v.VisitMarketIfTouchedOrderReject(t)
+	case MarketIfTouchedOrderRejectTransaction:
+		err = v.VisitMarketIfTouchedOrderReject(&t)
+	case *TakeProfitOrderTransaction:
+		err = v.VisitTakeProfitOrder(t)
+	case TakeProfitOrderTransaction:
+		err = v.VisitTakeProfitOrder(&t)
+	case *TakeProfitOrderRejectTransaction:
+		err = v.VisitTakeProfitOrderReject(t)
+	case TakeProfitOrderRejectTransaction:
+		err = v.VisitTakeProfitOrderReject(&t)
+	case *StopLossOrderTransaction:
+		err = v.VisitStopLossOrder(t)
+	case StopLossOrderTransaction:
+		err = v.VisitStopLossOrder(&t)
+	case *StopLossOrderRejectTransaction:
+		err = v.VisitStopLossOrderReject(t)
+	case StopLossOrderRejectTransaction:
+		err = v.VisitStopLossOrderReject(&t)
+	case *GuaranteedStopLossOrderTransaction:
+		err = v.VisitGuaranteedStopLossOrder(t)
+	case GuaranteedStopLossOrderTransaction:
+		err = v.VisitGuaranteedStopLossOrder(&t)
+	case *GuaranteedStopLossOrderRejectTransaction:
+		err = v.VisitGuaranteedStopLossOrderReject(t)
+	case GuaranteedStopLossOrderRejectTransaction:
+		err = v.VisitGuaranteedStopLossOrderReject(&t)
+	case *TrailingStopLossOrderTransaction:
+		err = v.VisitTrailingStopLossOrder(t)
+	case TrailingStopLossOrderTransaction:
+		err = v.VisitTrailingStopLossOrder(&t)
+	case *TrailingStopLossOrderRejectTransaction:
+		err = v.VisitTrailingStopLossOrderReject(t)
+	case TrailingStopLossOrderRejectTransaction:
+		err = v.VisitTrailingStopLossOrderReject(&t)
+	case *OneCancelsAllOrderTransaction:
+		err = v.VisitOneCancelsAllOrder(t)
+	case OneCancelsAllOrderTransaction:
+		err = v.VisitOneCancelsAllOrder(&t)
+	case *OneCancelsAllOrderRejectTransaction:
+		err = v.VisitOneCancelsAllOrderReject(t)
+	case OneCancelsAllOrderRejectTransaction:
+		err = v.VisitOneCancelsAllOrderReject(&t)
+	case *OneCancelsAllOrderTriggeredTransaction:
+		err = v.VisitOneCancelsAllOrderTriggered(t)
+	case OneCancelsAllOrderTriggeredTransaction:
+		err = v.VisitOneCancelsAllOrderTriggered(&t)
+	case *OrderFillTransaction:
+		err = v.VisitOrderFill(t)
+	case OrderFillTransaction:
+		err = v.VisitOrderFill(&t)
+	case *OrderCancelTransaction:
+		err = v.VisitOrderCancel(t)
+	case OrderCancelTransaction:
+		err = v.VisitOrderCancel(&t)
+	case *OrderCancelRejectTransaction:
+		err = v.VisitOrderCancelReject(t)
+	case OrderCancelRejectTransaction:
+		err = v.VisitOrderCancelReject(&t)
+	case *OrderClientExtensionsModifyTransaction:
+		err = v.VisitOrderClientExtensionsModify(t)
+	case OrderClientExtensionsModifyTransaction:
+		err = v.VisitOrderClientExtensionsModify(&t)
+	case *OrderClientExtensionsModifyRejectTransaction:
+		err = v.VisitOrderClientExtensionsModifyReject(t)
+	case OrderClientExtensionsModifyRejectTransaction:
+		err = v.VisitOrderClientExtensionsModifyReject(&t)
+	case *TradeClientExtensionsModifyTransaction:
+		err = v.VisitTradeClientExtensionsModify(t)
+	case TradeClientExtensionsModifyTransaction:
+		err = v.VisitTradeClientExtensionsModify(&t)
+	case *TradeClientExtensionsModifyRejectTransaction:
+		err = v.VisitTradeClientExtensionsModifyReject(t)
+	case TradeClientExtensionsModifyRejectTransaction:
+		err = v.VisitTradeClientExtensionsModifyReject(&t)
+	case *MarginCallEnterTransaction:
+		err = v.VisitMarginCallEnter(t)
+	case MarginCallEnterTransaction:
+		err = v.VisitMarginCallEnter(&t)
+	case *MarginCallExtendTransaction:
+		err = v.VisitMarginCallExtend(t)
+	case MarginCallExtendTransaction:
+		err = v.VisitMarginCallExtend(&t)
+	case *MarginCallExitTransaction:
+		err = v.VisitMarginCallExit(t)
+	case MarginCallExitTransaction:
+		err = v.VisitMarginCallExit(&t)
+	case *DelayedTradeClosureTransaction:
+		err = v.VisitDelayedTradeClosure(t)
+	case DelayedTradeClosureTransaction:
+		err = v.VisitDelayedTradeClosure(&t)
+	case *DailyFinancingTransaction:
+		err = v.VisitDailyFinancing(t)
+	case DailyFinancingTransaction:
+		err = v.VisitDailyFinancing(&t)
+	case *DividendAdjustmentTransaction:
+		err = v.VisitDividendAdjustment(t)
+	case DividendAdjustmentTransaction:
+		err = v.VisitDividendAdjustment(&t)
+	case *ResetResettablePLTransaction:
+		err = v.VisitResetResettablePL(t)
+	case ResetResettablePLTransaction:
+		err = v.VisitResetResettablePL(&t)
+	case *TransactionHeartbeat:
+		err = v.VisitHeartbeat(t)
+	case TransactionHeartbeat:
+		err = v.VisitHeartbeat(&t)
+	case *TransactionGapDetected:
+		err = v.VisitGapDetected(t)
+	case TransactionGapDetected:
+		err = v.VisitGapDetected(&t)
+	default:
+		return v.Default(transaction)
+	}
+	if errors.Is(err, errNotVisited) {
+		return v.Default(transaction)
+	}
+	return err
+}
@@ -0,0 +1,61 @@
+package oanda
+
+import (
+	"errors"
+	"testing"
+)
+
+type fillCounter struct {
+	BaseTransactionVisitor
+	fills    []TransactionID
+	defaults []TransactionType
+}
+
+func (c *fillCounter) VisitOrderFill(t *OrderFillTransaction) error {
+	if t.Instrument == "" {
+		return errors.New("no instrument")
+	}
+	c.fills = append(c.fills, t.ID)
+	return nil
+}
+
+func (c *fillCounter) Default(t Transaction) error {
+	c.defaults = append(c.defaults, t.GetType())
+	return nil
+}
+
+func TestDispatchTransaction(t *testing.T) {
+	var transactions []Transaction
+	for _, raw := range []string{
+		`{"type":"ORDER_FILL","id":"1","instrument":"EUR_USD"}`,
+		`{"type":"DAILY_FINANCING","id":"2"}`,
+		`{"type":"SOME_NEW_TYPE","id":"3"}`,
+	} {
+		transaction, err := unmarshalTransaction([]byte(raw))
+		if err != nil {
+			t.Fatalf("failed to decode transaction: %v", err)
+		}
+		transactions = append(transactions, transaction)
+	}
+	item, _, err := parseTransactionStreamItem([]byte(`{"type":"ORDER_FILL","id":"4","instrument":"EUR_USD"}`))
+	if err != nil {
+		t.Fatalf("failed to parse stream item: %v", err)
+	}
+	transactions = append(transactions, item, nil)
+
+	counter := &fillCounter{}
+	for _, transaction := range transactions {
+		if err := DispatchTransaction(transaction, counter); err != nil {
+			t.Fatalf("failed to dispatch %v: %v", transaction, err)
+		}
+	}
+	if len(counter.fills) != 2 || counter.fills[0] != "1" || counter.fills[1] != "4" {
+		t.Errorf("got fills %v, want 1 and 4", counter.fills)
+	}
+	if len(counter.defaults) != 2 || counter.defaults[0] != TransactionTypeDailyFinancing || counter.defaults[1] != "SOME_NEW_TYPE" {
+		t.Errorf("got defaults %v, want DAILY_FINANCING and SOME_NEW_TYPE", counter.defaults)
+	}
+	if err := DispatchTransaction(&OrderFillTransaction{}, counter); err == nil {
+		t.Error("got no error from visit method")
+	}
+}
@@ -0,0 +1,281 @@
+package oanda
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+)
+
+// VolatilityMethod is the measure of volatility stop distances are derived from.
+type VolatilityMethod string
+
+const (
+	// VolatilityATR is the average true range of the candlesticks: the mean of their ranges
+	// extended to the previous close.
+	VolatilityATR VolatilityMethod = "ATR"
+	// VolatilityRealized is the standard deviation of the changes between the closes of the
+	// candlesticks.
+	VolatilityRealized VolatilityMethod = "REALIZED"
+)
+
+// StopSuggestionRequest describes volatility-based stop loss and take profit distances: the
+// Instrument and granularity of the candlesticks volatility is measured on, the horizon the
+// Trade is expected to be held for, and the multiples of the volatility to place the stops at.
+// Use [NewStopSuggestionRequest] to create one, then chain setters.
+type StopSuggestionRequest struct {
+	instrument         InstrumentName
+	granularity        CandlestickGranularity
+	method             VolatilityMethod
+	period             int
+	horizon            time.Duration
+	stopMultiple       float64
+	takeProfitMultiple float64
+}
+
+// NewStopSuggestionRequest creates a new StopSuggestionRequest measuring the ATR of 14
+// candlesticks of granularity, over a horizon of one candlestick, with the stop loss at 1.5 and
+// the take profit at 3 times the volatility.
+func NewStopSuggestionRequest(instrument InstrumentName, granularity CandlestickGranularity) *StopSuggestionRequest {
+	return &StopSuggestionRequest{
+		instrument:         instrument,
+		granularity:        granularity,
+		method:             VolatilityATR,
+		period:             14,
+		stopMultiple:       1.5,
+		takeProfitMultiple: 3,
+	}
+}
+
+// SetMethod sets the measure of volatility.
+func (r *StopSuggestionRequest) SetMethod(method VolatilityMethod) *StopSuggestionRequest {
+	r.method = method
+	return r
+}
+
+// SetPeriod sets the number of candlesticks volatility is measured over.
+func (r *StopSuggestionRequest) SetPeriod(period int) *StopSuggestionRequest {
+	r.period = period
+	return r
+}
+
+// SetHorizon sets the time the Trade is expected to be held for. The volatility of one
+// candlestick is scaled to the horizon by the square root of the number of candlesticks in it.
+func (r *StopSuggestionRequest) SetHorizon(horizon time.Duration) *StopSuggestionRequest {
+	r.horizon = horizon
+	return r
+}
+
+// SetMultiples sets the distances of the stop loss and the take profit as multiples of the
+// volatility over the horizon.
+func (r *StopSuggestionRequest) SetMultiples(stopLoss, takeProfit float64) *StopSuggestionRequest {
+	r.stopMultiple = stopLoss
+	r.takeProfitMultiple = takeProfit
+	return r
+}
+
+func (r *StopSuggestionRequest) validate() error {
+	if r.instrument == "" {
+		return errors.New("missing instrument")
+	}
+	if err := r.granularity.validate(); err != nil {
+		return err
+	}
+	if r.method != VolatilityATR && r.method != VolatilityRealized {
+		return fmt.Errorf("invalid volatility method %q", r.method)
+	}
+	if r.period < 2 {
+		return errors.New("period must be at least 2")
+	}
+	if r.horizon < 0 {
+		return errors.New("horizon cannot be negative")
+	}
+	if r.stopMultiple <= 0 || r.takeProfitMultiple <= 0 {
+		return errors.New("multiples must be positive")
+	}
+	return nil
+}
+
+// StopSuggestion is a suggested stop loss and take profit distance, returned by [SuggestStops]
+// and [StopSuggester.Suggest].
+type StopSuggestion struct {
+	// Instrument is the Instrument of the suggestion.
+	Instrument InstrumentName
+	// Volatility is the volatility over the horizon, in price units.
+	Volatility float64
+	// StopLossDistance is the distance of the stop loss from the entry price, rounded to the
+	// Instrument's DisplayPrecision.
+	StopLossDistance DecimalNumber
+	// StopLossPips is StopLossDistance in pips.
+	StopLossPips float64
+	// TakeProfitDistance is the distance of the take profit from the entry price, rounded to the
+	// Instrument's DisplayPrecision.
+	TakeProfitDistance DecimalNumber
+	// TakeProfitPips is TakeProfitDistance in pips.
+	TakeProfitPips float64
+}
+
+// SuggestStops suggests stop loss and take profit distances for req from candles, the latest
+// candlesticks of the Instrument in time order with midpoint prices. Incomplete candlesticks are
+// ignored, and only the last candlesticks needed for the period of req are used. It returns an
+// error if there are not enough candlesticks or the distances round to zero.
+func SuggestStops(candles []Candlestick, precision InstrumentPrecision, req *StopSuggestionRequest) (*StopSuggestion, error) {
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+	var bars [][3]float64
+	for _, candle := range candles {
+		if !candle.Complete {
+			continue
+		}
+		if candle.Mid == nil {
+			return nil, fmt.Errorf("candlestick at %v has no midpoint prices", candle.Time)
+		}
+		var bar [3]float64
+		for i, p := range []PriceValue{candle.Mid.H, candle.Mid.L, candle.Mid.C} {
+			f, err := p.Float64()
+			if err != nil {
+				return nil, fmt.Errorf("invalid candlestick at %v: %w", candle.Time, err)
+			}
+			bar[i] = f
+		}
+		bars = append(bars, bar)
+	}
+	// The first candlestick only provides the previous close of the second.
+	if len(bars) < req.period+1 {
+		return nil, fmt.Errorf("got %d complete candlesticks, need %d", len(bars), req.period+1)
+	}
+	bars = bars[len(bars)-req.period-1:]
+	volatility := 0.0
+	switch req.method {
+	case VolatilityATR:
+		for i := 1; i < len(bars); i++ {
+			high, low, prevClose := bars[i][0], bars[i][1], bars[i-1][2]
+			volatility += max(high-low, math.Abs(high-prevClose), math.Abs(low-prevClose)) / float64(req.period)
+		}
+	case VolatilityRealized:
+		changes := make([]float64, len(bars)-1)
+		mean := 0.0
+		for i := range changes {
+			changes[i] = bars[i+1][2] - bars[i][2]
+			mean += changes[i] / float64(len(changes))
+		}
+		for _, change := range changes {
+			volatility += (change - mean) * (change - mean)
+		}
+		volatility = math.Sqrt(volatility / float64(len(changes)-1))
+	}
+	if req.horizon > 0 {
+		d, err := req.granularity.Duration()
+		if err != nil {
+			return nil, err
+		}
+		volatility *= math.Sqrt(float64(req.horizon) / float64(d))
+	}
+	suggestion := &StopSuggestion{Instrument: req.instrument, Volatility: volatility}
+	var err error
+	if suggestion.StopLossDistance, suggestion.StopLossPips, err = stopDistance(volatility*req.stopMultiple, precision); err != nil {
+		return nil, err
+	}
+	if suggestion.TakeProfitDistance, suggestion.TakeProfitPips, err = stopDistance(volatility*req.takeProfitMultiple, precision); err != nil {
+		return nil, err
+	}
+	return suggestion, nil
+}
+
+// stopDistance returns distance rounded to the DisplayPrecision of precision, and in pips.
+func stopDistance(distance float64, precision InstrumentPrecision) (DecimalNumber, float64, error) {
+	r := new(big.Rat).SetFloat64(distance)
+	if r == nil {
+		return "", 0, fmt.Errorf("invalid stop distance %v", distance)
+	}
+	rounded := DecimalNumber(r.FloatString(precision.DisplayPrecision))
+	d, err := rounded.Rat()
+	if err != nil {
+		return "", 0, err
+	}
+	if d.Sign() <= 0 {
+		return "", 0, fmt.Errorf("stop distance %v rounds to zero", distance)
+	}
+	pips, _ := d.Quo(d, precision.pip()).Float64()
+	return rounded, pips, nil
+}
+
+// StopLossOnFill returns the details of a stop loss at StopLossDistance from the price the Trade
+// is opened at, for [MarketOrderRequest.SetStopLossOnFill] and the other Order requests.
+func (s *StopSuggestion) StopLossOnFill() *StopLossDetails {
+	return NewStopLossDetails().SetDistance(s.StopLossDistance)
+}
+
+// TrailingStopLossOnFill returns the details of a trailing stop loss at StopLossDistance, for
+// [MarketOrderRequest.SetTrailingStopLossOnFill] and the other Order requests.
+func (s *StopSuggestion) TrailingStopLossOnFill() *TrailingStopLossDetails {
+	return NewTrailingStopLossDetails(s.StopLossDistance)
+}
+
+// TakeProfitOnFill returns the details of a take profit at TakeProfitDistance from entry, above
+// it if units are positive and below it if they are negative, for
+// [MarketOrderRequest.SetTakeProfitOnFill] and the other Order requests. A take profit takes a
+// price rather than a distance, so entry is the expected fill price, such as the current ask for
+// a buy.
+func (s *StopSuggestion) TakeProfitOnFill(entry PriceValue, units DecimalNumber) (*TakeProfitDetails, error) {
+	price, err := entry.Rat()
+	if err != nil {
+		return nil, err
+	}
+	u, err := units.Rat()
+	if err != nil {
+		return nil, err
+	}
+	if u.Sign() == 0 {
+		return nil, errors.New("units cannot be zero")
+	}
+	distance, err := s.TakeProfitDistance.Rat()
+	if err != nil {
+		return nil, err
+	}
+	if u.Sign() < 0 {
+		distance.Neg(distance)
+	}
+	price.Add(price, distance)
+	digits := max(fractionDigits(string(entry)), fractionDigits(string(s.TakeProfitDistance)))
+	return NewTakeProfitDetails(PriceValue(price.FloatString(digits))), nil
+}
+
+// StopSuggester suggests volatility-based stop distances from the latest candlesticks of an
+// Instrument. Create one with [NewStopSuggester].
+type StopSuggester struct {
+	client      *Client
+	instruments *InstrumentCache
+}
+
+// NewStopSuggester creates a new StopSuggester that fetches candlesticks with client and
+// Instrument precision from instruments.
+func NewStopSuggester(client *Client, instruments *InstrumentCache) *StopSuggester {
+	return &StopSuggester{client: client, instruments: instruments}
+}
+
+// Suggest fetches the latest midpoint candlesticks of the Instrument of req and suggests stop
+// distances from them with [SuggestStops].
+//
+// This corresponds to the OANDA API endpoint: GET /v3/instruments/{instrument}/candles
+//
+// Reference: https://developer.oanda.com/rest-live-v20/instrument-ep/#collapse_endpoint_1
+func (s *StopSuggester) Suggest(ctx context.Context, req *StopSuggestionRequest) (*StopSuggestion, error) {
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+	precision, err := s.instruments.Precision(ctx, req.instrument)
+	if err != nil {
+		return nil, err
+	}
+	// One more candlestick than needed, as the latest one is usually incomplete.
+	candlesReq := NewCandlesticksRequest(req.instrument, req.granularity).SetCount(req.period + 2).Mid()
+	resp, err := s.client.Instrument.Candlesticks(ctx, candlesReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get candlesticks: %w", err)
+	}
+	return SuggestStops(resp.Candles, precision, req)
+}
@@ -0,0 +1,71 @@
+package oanda
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSuggestStops(t *testing.T) {
+	candlesJSON := `{"instrument":"EUR_USD","granularity":"H1","candles":[
+		{"time":"2024-01-02T00:00:00Z","complete":true,"mid":{"o":"1.10000","h":"1.10100","l":"1.09900","c":"1.10000"}},
+		{"time":"2024-01-02T01:00:00Z","complete":true,"mid":{"o":"1.10000","h":"1.10200","l":"1.10000","c":"1.10100"}},
+		{"time":"2024-01-02T02:00:00Z","complete":true,"mid":{"o":"1.10100","h":"1.10150","l":"1.10050","c":"1.10120"}},
+		{"time":"2024-01-02T03:00:00Z","complete":true,"mid":{"o":"1.10120","h":"1.10500","l":"1.10300","c":"1.10400"}},
+		{"time":"2024-01-02T04:00:00Z","complete":false,"mid":{"o":"1.10400","h":"1.20000","l":"1.00000","c":"1.10400"}}]}`
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v3/accounts/1/instruments", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `{"instruments":[{"name":"EUR_USD","pipLocation":-4,"displayPrecision":5}]}`)
+	})
+	mux.HandleFunc("/v3/instruments/EUR_USD/candles", func(w http.ResponseWriter, r *http.Request) {
+		if q := r.URL.Query(); q.Get("count") != "5" || q.Get("price") != "M" || q.Get("granularity") != "H1" {
+			t.Errorf("got query %v", q)
+		}
+		_, _ = fmt.Fprint(w, candlesJSON)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client := NewClient("api-key", WithBaseURL(server.URL), WithAccountID("1"))
+	suggester := NewStopSuggester(client, NewInstrumentCache(client))
+
+	// True ranges of 20, 10 and 38 pips average 22.67 pips.
+	suggestion, err := suggester.Suggest(t.Context(), NewStopSuggestionRequest("EUR_USD", H1).SetPeriod(3))
+	if err != nil {
+		t.Fatalf("failed to suggest stops: %v", err)
+	}
+	if suggestion.StopLossDistance != "0.00340" || suggestion.StopLossPips != 34 ||
+		suggestion.TakeProfitDistance != "0.00680" || suggestion.TakeProfitPips != 68 {
+		t.Errorf("got %+v", suggestion)
+	}
+	if d := suggestion.StopLossOnFill().Distance; d == nil || *d != "0.00340" {
+		t.Errorf("got stop loss distance %v", d)
+	}
+	tp, err := suggestion.TakeProfitOnFill("1.10500", "-1000")
+	if err != nil || tp.Price != "1.09820" {
+		t.Errorf("got take profit %+v (%v), want 1.09820", tp, err)
+	}
+
+	// Four hours are twice the volatility of one.
+	suggestion, err = suggester.Suggest(t.Context(), NewStopSuggestionRequest("EUR_USD", H1).SetPeriod(3).
+		SetHorizon(4*time.Hour).SetMultiples(1, 2))
+	if err != nil || suggestion.StopLossDistance != "0.00453" || suggestion.TakeProfitDistance != "0.00907" {
+		t.Errorf("got %+v (%v)", suggestion, err)
+	}
+
+	var resp CandlestickResponse
+	if err := json.Unmarshal([]byte(candlesJSON), &resp); err != nil {
+		t.Fatal(err)
+	}
+	precision := InstrumentPrecision{PipLocation: -4, DisplayPrecision: 5}
+	suggestion, err = SuggestStops(resp.Candles, precision, NewStopSuggestionRequest("EUR_USD", H1).SetPeriod(3).SetMethod(VolatilityRealized))
+	if err != nil || math.Abs(suggestion.Volatility-0.0013317) > 1e-6 {
+		t.Errorf("got %+v (%v)", suggestion, err)
+	}
+	if _, err := SuggestStops(resp.Candles, precision, NewStopSuggestionRequest("EUR_USD", H1).SetPeriod(4)); err == nil {
+		t.Error("got no error for too few candlesticks")
+	}
+}
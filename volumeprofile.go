@@ -0,0 +1,160 @@
+package oanda
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// VolumeProfileLevel is a price bucket of a [VolumeProfile].
+type VolumeProfileLevel struct {
+	// Price is the lowest price (inclusive) covered by the bucket. The bucket covers the price
+	// range from Price to Price + the profile's BucketWidth.
+	Price PriceValue
+	// Volume is the tick volume traded within the bucket.
+	Volume float64
+}
+
+// VolumeProfile is a histogram of the tick volume of a range of candlesticks by price, built by
+// [VolumeProfileOf].
+type VolumeProfile struct {
+	// BucketWidth is the width of the price range covered by each level.
+	BucketWidth PriceValue
+	// Levels are the buckets from the lowest to the highest price traded, including buckets
+	// without volume between them.
+	Levels []VolumeProfileLevel
+	// TotalVolume is the tick volume of all the candlesticks.
+	TotalVolume float64
+}
+
+// VolumeProfileOf builds the VolumeProfile of candles from the prices of component, one of
+// PriceComponentBid, PriceComponentAsk and PriceComponentMid, with buckets of bucketWidth, such
+// as "0.0005" for 5 pips of EUR_USD. OANDA reports only the tick volume of a candlestick, so its
+// volume is spread evenly across the buckets between its low and high. It returns an error if a
+// candlestick does not have the prices of component or they cannot be parsed.
+func VolumeProfileOf(candles []Candlestick, component PriceComponents, bucketWidth PriceValue) (*VolumeProfile, error) {
+	width, err := bucketWidth.Rat()
+	if err != nil {
+		return nil, fmt.Errorf("invalid bucket width: %w", err)
+	}
+	if width.Sign() <= 0 {
+		return nil, errors.New("bucket width must be positive")
+	}
+	volumes := make(map[int64]float64)
+	var lowest, highest int64
+	profile := &VolumeProfile{BucketWidth: bucketWidth}
+	for _, candle := range candles {
+		if candle.Volume == 0 {
+			continue
+		}
+		data := candle.Data(component)
+		if data == nil {
+			return nil, fmt.Errorf("candlestick at %v has no %s prices", candle.Time, component)
+		}
+		low, err := profileBucket(data.L, width)
+		if err != nil {
+			return nil, fmt.Errorf("invalid candlestick at %v: %w", candle.Time, err)
+		}
+		high, err := profileBucket(data.H, width)
+		if err != nil {
+			return nil, fmt.Errorf("invalid candlestick at %v: %w", candle.Time, err)
+		}
+		if high < low {
+			return nil, fmt.Errorf("invalid candlestick at %v: high %s below low %s", candle.Time, data.H, data.L)
+		}
+		share := float64(candle.Volume) / float64(high-low+1)
+		for i := low; i <= high; i++ {
+			volumes[i] += share
+		}
+		if profile.TotalVolume == 0 || low < lowest {
+			lowest = low
+		}
+		if profile.TotalVolume == 0 || high > highest {
+			highest = high
+		}
+		profile.TotalVolume += float64(candle.Volume)
+	}
+	if profile.TotalVolume == 0 {
+		return profile, nil
+	}
+	digits := fractionDigits(string(bucketWidth))
+	for i := lowest; i <= highest; i++ {
+		price := new(big.Rat).Mul(width, new(big.Rat).SetInt64(i))
+		profile.Levels = append(profile.Levels, VolumeProfileLevel{
+			Price:  PriceValue(price.FloatString(digits)),
+			Volume: volumes[i],
+		})
+	}
+	return profile, nil
+}
+
+// profileBucket returns the index of the bucket of width that covers price.
+func profileBucket(price PriceValue, width *big.Rat) (int64, error) {
+	p, err := price.Rat()
+	if err != nil {
+		return 0, err
+	}
+	q := new(big.Rat).Quo(p, width)
+	// Div rounds towards negative infinity for the positive denominator of a Rat.
+	return new(big.Int).Div(q.Num(), q.Denom()).Int64(), nil
+}
+
+// POC returns the point of control, the level with the most volume, or the lowest of them if
+// several have the same volume. It returns false if the profile has no levels.
+func (p *VolumeProfile) POC() (VolumeProfileLevel, bool) {
+	i := p.poc()
+	if i < 0 {
+		return VolumeProfileLevel{}, false
+	}
+	return p.Levels[i], true
+}
+
+func (p *VolumeProfile) poc() int {
+	poc := -1
+	for i, level := range p.Levels {
+		if poc < 0 || level.Volume > p.Levels[poc].Volume {
+			poc = i
+		}
+	}
+	return poc
+}
+
+// ValueArea returns the price range around the point of control that holds fraction of the
+// volume, commonly 0.7: the lowest price of its lowest level and the highest price of its highest
+// level. Starting from the point of control, the area grows by the level next to it with more
+// volume, the upper one on a tie, until it holds fraction of the total. It returns false if the
+// profile has no levels.
+func (p *VolumeProfile) ValueArea(fraction float64) (low, high PriceValue, ok bool) {
+	poc := p.poc()
+	if poc < 0 {
+		return "", "", false
+	}
+	lo, hi := poc, poc
+	volume := p.Levels[poc].Volume
+	for volume < fraction*p.TotalVolume && (lo > 0 || hi < len(p.Levels)-1) {
+		below, above := -1.0, -1.0
+		if lo > 0 {
+			below = p.Levels[lo-1].Volume
+		}
+		if hi < len(p.Levels)-1 {
+			above = p.Levels[hi+1].Volume
+		}
+		if above >= below {
+			hi++
+			volume += above
+		} else {
+			lo--
+			volume += below
+		}
+	}
+	width, err := p.BucketWidth.Rat()
+	if err != nil {
+		return "", "", false
+	}
+	top, err := p.Levels[hi].Price.Rat()
+	if err != nil {
+		return "", "", false
+	}
+	top.Add(top, width)
+	return p.Levels[lo].Price, PriceValue(top.FloatString(fractionDigits(string(p.BucketWidth)))), true
+}
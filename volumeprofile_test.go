@@ -0,0 +1,48 @@
+package oanda
+
+import "testing"
+
+func TestVolumeProfileOf(t *testing.T) {
+	candle := func(low, high PriceValue, volume int) Candlestick {
+		return Candlestick{Mid: &CandlestickData{O: low, H: high, L: low, C: high}, Volume: volume}
+	}
+	candles := []Candlestick{
+		candle("1.10000", "1.10100", 30),
+		candle("1.10050", "1.10090", 20),
+		candle("1.10200", "1.10240", 5),
+		candle("1.20000", "1.20000", 0),
+	}
+	profile, err := VolumeProfileOf(candles, PriceComponentMid, "0.0005")
+	if err != nil {
+		t.Fatalf("failed to build volume profile: %v", err)
+	}
+	want := []VolumeProfileLevel{{"1.1000", 10}, {"1.1005", 30}, {"1.1010", 10}, {"1.1015", 0}, {"1.1020", 5}}
+	if len(profile.Levels) != len(want) || profile.TotalVolume != 55 {
+		t.Fatalf("got %+v", profile)
+	}
+	for i, level := range profile.Levels {
+		if level != want[i] {
+			t.Errorf("got level %d %+v, want %+v", i, level, want[i])
+		}
+	}
+	if poc, ok := profile.POC(); !ok || poc.Price != "1.1005" {
+		t.Errorf("got point of control %+v", poc)
+	}
+	if low, high, ok := profile.ValueArea(0.7); !ok || low != "1.1005" || high != "1.1015" {
+		t.Errorf("got value area %s-%s, want 1.1005-1.1015", low, high)
+	}
+	if low, high, _ := profile.ValueArea(1); low != "1.1000" || high != "1.1025" {
+		t.Errorf("got full value area %s-%s, want 1.1000-1.1025", low, high)
+	}
+
+	if _, err := VolumeProfileOf(candles, PriceComponentBid, "0.0005"); err == nil {
+		t.Error("got no error for missing bid prices")
+	}
+	if _, err := VolumeProfileOf(candles, PriceComponentMid, "0"); err == nil {
+		t.Error("got no error for a zero bucket width")
+	}
+	empty, err := VolumeProfileOf(nil, PriceComponentMid, "0.0005")
+	if _, ok := empty.POC(); err != nil || ok {
+		t.Errorf("got point of control for no candlesticks (%v)", err)
+	}
+}